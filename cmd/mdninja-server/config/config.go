@@ -23,21 +23,26 @@ import (
 	"github.com/skerkour/stdx-go/yaml"
 	"markdown.ninja/pkg/errs"
 	"markdown.ninja/pkg/kms"
+	"markdown.ninja/pkg/mailer/smtp"
 )
 
 type Config struct {
 	// True if running as Saas and billing is required
-	BlockedCountries   []string `json:"blocked_countries" yaml:"blocked_countries"`
-	BlockSubscriptions bool     `json:"block_subscriptions" yaml:"block_subscriptions"`
-	Saas               bool     `json:"saas" yaml:"saas"`
-	HTTP               Http     `json:"http" yaml:"http"`
-	Database           Database `json:"database" yaml:"database"`
-	Emails             Emails   `json:"emails" yaml:"emails"`
-	Worker             Worker   `json:"worker" yaml:"worker"`
-	Logs               Logs     `json:"logs" yaml:"logs"`
-	S3                 S3       `json:"s3" yaml:"s3"`
-	Jwt                Jwt      `json:"jwt" yaml:"jwt"`
-	Kms                Kms      `json:"kms" yaml:"kms"`
+	BlockedCountries   []string  `json:"blocked_countries" yaml:"blocked_countries"`
+	BlockSubscriptions bool      `json:"block_subscriptions" yaml:"block_subscriptions"`
+	Saas               bool      `json:"saas" yaml:"saas"`
+	HTTP               Http      `json:"http" yaml:"http"`
+	Database           Database  `json:"database" yaml:"database"`
+	Emails             Emails    `json:"emails" yaml:"emails"`
+	Worker             Worker    `json:"worker" yaml:"worker"`
+	Logs               Logs      `json:"logs" yaml:"logs"`
+	S3                 S3        `json:"s3" yaml:"s3"`
+	Jwt                Jwt       `json:"jwt" yaml:"jwt"`
+	Kms                Kms       `json:"kms" yaml:"kms"`
+	Auth               Auth      `json:"auth" yaml:"auth"`
+	Geoip              Geoip     `json:"geoip" yaml:"geoip"`
+	RateLimit          RateLimit `json:"rate_limit" yaml:"rate_limit"`
+	Payments           Payments  `json:"payments" yaml:"payments"`
 
 	// 3rd party providers & services
 	// pingoo.io
@@ -46,6 +51,8 @@ type Config struct {
 	Stripe   *Stripe   `json:"stripe" yaml:"stripe"`
 	Aws      *Aws      `json:"aws" yaml:"aws"`
 	Scaleway *Scaleway `json:"scaleway" yaml:"scaleway"`
+	// Any SMTP server. Only used by the smtp emails provider.
+	Smtp *Smtp `json:"smtp" yaml:"smtp"`
 }
 
 type Http struct {
@@ -92,6 +99,9 @@ type Emails struct {
 	NotifyAddress     mail.Address   `json:"-"`
 	ContactAddressStr string         `json:"contact_address" yaml:"contact_address"`
 	ContactAddress    mail.Address   `json:"-"`
+	// Secret used to authenticate the bounces and complaints sent to the /webhooks/emails/* endpoints.
+	// The endpoints are disabled if empty.
+	FeedbackWebhookSecret string `json:"feedback_webhook_secret" yaml:"feedback_webhook_secret"`
 }
 
 type Kms struct {
@@ -117,6 +127,45 @@ type Aws struct {
 	SecretAccessKey string `json:"secret_access_key" yaml:"secret_access_key"`
 }
 
+type Smtp struct {
+	Host string `json:"host" yaml:"host"`
+	// default: 587 for starttls, 465 for tls and 25 for none
+	Port     uint16 `json:"port" yaml:"port"`
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+	// starttls | tls | none. default: starttls
+	Tls smtp.TlsMode `json:"tls" yaml:"tls"`
+	// The name sent with the EHLO command. default: localhost
+	HeloName string `json:"helo_name" yaml:"helo_name"`
+	// Maximum number of simultaneous connections to the server. default: 4
+	PoolSize int `json:"pool_size" yaml:"pool_size"`
+	// The selector of the DKIM keys generated for the email domains of the websites. default: mdninja
+	DkimSelector string `json:"dkim_selector" yaml:"dkim_selector"`
+}
+
+type Geoip struct {
+	// pingoo | mmdb | csv. default: pingoo
+	Provider GeoipProvider `json:"provider" yaml:"provider"`
+	// Path of the local database file. Required for the mmdb and csv providers.
+	// The file is reloaded when it changes on disk.
+	Path string `json:"path" yaml:"path"`
+}
+
+type RateLimit struct {
+	// memory | postgres. default: memory
+	// Use postgres when running multiple instances of the server so that they share the same quotas.
+	Provider RateLimitProvider `json:"provider" yaml:"provider"`
+}
+
+type Payments struct {
+	// stripe | fake. default: stripe
+	// The payment provider used by the stores of the websites and by the billing of the organizations.
+	// With fake, the prices of the plans are unknown and subscriptions are free.
+	Provider PaymentsProvider `json:"provider" yaml:"provider"`
+}
+
+// Pingoo is only required when it is used to authenticate users (auth.provider) or for the geoip
+// lookups (geoip.provider). Otherwise, emails are validated locally and bots are not verified.
 type Pingoo struct {
 	ApiKey        string  `json:"api_key" yaml:"api_key"`
 	ProjectID     string  `json:"project_id" yaml:"project_id"`
@@ -144,6 +193,25 @@ type Logs struct {
 	LokiEndpoint *string    `json:"loki_endpoint" yaml:"loki_endpoint"`
 }
 
+type Auth struct {
+	// The identity provider used to authenticate users. default: pingoo
+	Provider AuthProvider `json:"provider" yaml:"provider"`
+	// Emails of the users who are administrators of the instance. Only used by the local provider.
+	Admins []string `json:"admins" yaml:"admins"`
+	// If false, only the admins can create an account. Only used by the local provider.
+	AllowSignups bool `json:"allow_signups" yaml:"allow_signups"`
+	// Optional OpenID Connect provider to login with. Only used by the local provider.
+	Oidc *AuthOidc `json:"oidc" yaml:"oidc"`
+}
+
+type AuthOidc struct {
+	// The name displayed on the login button. e.g. Google
+	Name         string `json:"name" yaml:"name"`
+	Issuer       string `json:"issuer" yaml:"issuer"`
+	ClientID     string `json:"client_id" yaml:"client_id"`
+	ClientSecret string `json:"client_secret" yaml:"client_secret"`
+}
+
 type Jwt struct {
 	Issuer string `json:"issuer" yaml:"issuer"`
 }
@@ -225,6 +293,12 @@ func Load(ctx context.Context, configPath string) (config Config, err error) {
 	return
 }
 
+// PingooIsEnabled returns true if Pingoo is used to authenticate users or for the geoip lookups.
+// Must be called after the configuration has been validated.
+func (config *Config) PingooIsEnabled() bool {
+	return config.Auth.Provider == AuthProviderPingoo || config.Geoip.Provider == GeoipProviderPingoo
+}
+
 // TODO
 func (config *Config) validateAndDefaultValues() (err error) {
 	if config.BlockedCountries == nil {
@@ -232,6 +306,28 @@ func (config *Config) validateAndDefaultValues() (err error) {
 	}
 
 	// Geoip Database
+	err = cleanAndValidateGeoip(&config.Geoip)
+	if err != nil {
+		return err
+	}
+
+	// Rate limiting
+	if config.RateLimit.Provider == "" {
+		config.RateLimit.Provider = RateLimitProviderMemory
+	}
+	if config.RateLimit.Provider != RateLimitProviderMemory && config.RateLimit.Provider != RateLimitProviderPostgres {
+		return errs.InvalidArgument(fmt.Sprintf("config: invalid rate_limit.provider. Valid values are: [%s, %s]",
+			RateLimitProviderMemory, RateLimitProviderPostgres))
+	}
+
+	// Payments
+	if config.Payments.Provider == "" {
+		config.Payments.Provider = PaymentsProviderStripe
+	}
+	if config.Payments.Provider != PaymentsProviderStripe && config.Payments.Provider != PaymentsProviderFake {
+		return errs.InvalidArgument(fmt.Sprintf("config: invalid payments.provider. Valid values are: [%s, %s]",
+			PaymentsProviderStripe, PaymentsProviderFake))
+	}
 
 	// HTTP
 	portFromEnvStr := strings.TrimSpace(os.Getenv(envPort))
@@ -247,6 +343,12 @@ func (config *Config) validateAndDefaultValues() (err error) {
 		config.HTTP.Port = defaultHttpPort
 	}
 
+	// Auth
+	err = cleanAndValidateAuth(&config.Auth)
+	if err != nil {
+		return
+	}
+
 	err = cleanAndValidateHttpConfig(config)
 	if err != nil {
 		return
@@ -363,12 +465,56 @@ func (config *Config) validateAndDefaultValues() (err error) {
 	// }
 
 	// Emails
-	if config.Emails.Provider != EmailsProviderConsole && config.Emails.Provider != EmailsProviderSes {
-		return errs.InvalidArgument(fmt.Sprintf("config: emails.provider is not valid. Valid values are [%s, %s]", EmailsProviderConsole, EmailsProviderSes))
+	if config.Emails.Provider != EmailsProviderConsole && config.Emails.Provider != EmailsProviderSes &&
+		config.Emails.Provider != EmailsProviderSmtp {
+		return errs.InvalidArgument(fmt.Sprintf("config: emails.provider is not valid. Valid values are [%s, %s, %s]",
+			EmailsProviderConsole, EmailsProviderSes, EmailsProviderSmtp))
 	}
 	if config.Emails.Provider == EmailsProviderSes && config.Aws == nil {
 		return errs.InvalidArgument("config: aws is null but emails.provider is \"ses\"")
 	}
+	if config.Emails.Provider == EmailsProviderSmtp && config.Smtp == nil {
+		return errs.InvalidArgument("config: smtp is null but emails.provider is \"smtp\"")
+	}
+	if config.Emails.FeedbackWebhookSecret != "" && len(config.Emails.FeedbackWebhookSecret) < 32 {
+		return errs.InvalidArgument("config: emails.feedback_webhook_secret is too short. Min: 32 characters")
+	}
+
+	// Smtp
+	if config.Smtp != nil {
+		config.Smtp.Host = strings.TrimSpace(config.Smtp.Host)
+		if config.Smtp.Host == "" {
+			return errs.InvalidArgument("config: smtp.host is empty")
+		}
+
+		if config.Smtp.Tls == "" {
+			config.Smtp.Tls = smtp.TlsModeStarttls
+		}
+		if config.Smtp.Port == 0 {
+			switch config.Smtp.Tls {
+			case smtp.TlsModeTls:
+				config.Smtp.Port = 465
+			case smtp.TlsModeNone:
+				config.Smtp.Port = 25
+			default:
+				config.Smtp.Port = 587
+			}
+		}
+
+		if config.Smtp.Tls != smtp.TlsModeStarttls && config.Smtp.Tls != smtp.TlsModeTls &&
+			config.Smtp.Tls != smtp.TlsModeNone {
+			return errs.InvalidArgument(fmt.Sprintf("config: smtp.tls is not valid. Valid values are [%s, %s, %s]",
+				smtp.TlsModeStarttls, smtp.TlsModeTls, smtp.TlsModeNone))
+		}
+
+		if config.Smtp.Username != "" && config.Smtp.Password == "" {
+			return errs.InvalidArgument("config: smtp.password is missing")
+		}
+
+		if config.Smtp.PoolSize < 0 {
+			return errs.InvalidArgument("config: smtp.pool_size is not valid")
+		}
+	}
 
 	if config.Emails.NotifyAddressStr == "" {
 		err = errs.InvalidArgument("config: emails.notify_address is empty")
@@ -419,12 +565,15 @@ func (config *Config) validateAndDefaultValues() (err error) {
 	}
 
 	// Stripe
-	if !config.Saas {
+	if !config.Saas && config.Payments.Provider == PaymentsProviderStripe {
 		err = cleanAndValdiateStripeConfig(config.Stripe)
 		if err != nil {
 			return err
 		}
 	}
+	if config.Stripe == nil {
+		config.Stripe = &Stripe{}
+	}
 
 	// Jwt
 	err = cleanAndValidateJwt(&config.Jwt)
@@ -506,9 +655,11 @@ func cleanAndValidateHttpConfig(config *Config) (err error) {
 	}
 
 	// pingoo
-	err = cleanAndValdiatePingooConfig(&config.Pingoo)
-	if err != nil {
-		return err
+	if config.PingooIsEnabled() {
+		err = cleanAndValdiatePingooConfig(&config.Pingoo, config.Auth.Provider)
+		if err != nil {
+			return err
+		}
 	}
 
 	// if config.HTTP.Geoip == "" {
@@ -539,6 +690,73 @@ func cleanAndValidateJwt(jwtConfig *Jwt) (err error) {
 	return nil
 }
 
+func cleanAndValidateGeoip(geoipConfig *Geoip) (err error) {
+	if geoipConfig.Provider == "" {
+		geoipConfig.Provider = GeoipProviderPingoo
+	}
+
+	geoipConfig.Path = strings.TrimSpace(geoipConfig.Path)
+
+	switch geoipConfig.Provider {
+	case GeoipProviderPingoo:
+	case GeoipProviderMmdb, GeoipProviderCsv:
+		if geoipConfig.Path == "" {
+			return fmt.Errorf("config: geoip.path is required when geoip.provider is %s", geoipConfig.Provider)
+		}
+	default:
+		return errs.InvalidArgument(fmt.Sprintf("config: invalid geoip.provider. Valid values are: [%s, %s, %s]",
+			GeoipProviderPingoo, GeoipProviderMmdb, GeoipProviderCsv))
+	}
+
+	return nil
+}
+
+func cleanAndValidateAuth(authConfig *Auth) (err error) {
+	if authConfig.Provider == "" {
+		authConfig.Provider = AuthProviderPingoo
+	}
+	if authConfig.Provider != AuthProviderPingoo && authConfig.Provider != AuthProviderLocal {
+		return errs.InvalidArgument(fmt.Sprintf("config: invalid auth.provider. Valid values are: [%s, %s]", AuthProviderPingoo, AuthProviderLocal))
+	}
+
+	for i, admin := range authConfig.Admins {
+		admin = strings.ToLower(strings.TrimSpace(admin))
+		if _, err = mail.ParseAddress(admin); err != nil {
+			return errs.InvalidArgument(fmt.Sprintf("config: auth.admins: %s is not a valid email address", admin))
+		}
+		authConfig.Admins[i] = admin
+	}
+
+	if authConfig.Provider == AuthProviderLocal && !authConfig.AllowSignups && len(authConfig.Admins) == 0 {
+		return errors.New("config: auth.admins is empty while auth.allow_signups is false. Nobody would be able to create an account")
+	}
+
+	if authConfig.Oidc != nil {
+		authConfig.Oidc.Name = strings.TrimSpace(authConfig.Oidc.Name)
+		if authConfig.Oidc.Name == "" {
+			return errors.New("config: auth.oidc.name is empty")
+		}
+
+		authConfig.Oidc.Issuer = strings.TrimSuffix(strings.TrimSpace(authConfig.Oidc.Issuer), "/")
+		issuerUrl, err := url.Parse(authConfig.Oidc.Issuer)
+		if err != nil || issuerUrl.Scheme != protocolHttps || issuerUrl.Host == "" {
+			return errors.New("config: auth.oidc.issuer must be a valid https URL")
+		}
+
+		authConfig.Oidc.ClientID = strings.TrimSpace(authConfig.Oidc.ClientID)
+		if authConfig.Oidc.ClientID == "" {
+			return errors.New("config: auth.oidc.client_id is empty")
+		}
+
+		authConfig.Oidc.ClientSecret = strings.TrimSpace(authConfig.Oidc.ClientSecret)
+		if authConfig.Oidc.ClientSecret == "" {
+			return errors.New("config: auth.oidc.client_secret is empty")
+		}
+	}
+
+	return nil
+}
+
 func cleanAndValdiateStripeConfig(stripeConfig *Stripe) (err error) {
 	stripeConfig.SecretKey = strings.TrimSpace(stripeConfig.SecretKey)
 	if !strings.HasPrefix(stripeConfig.SecretKey, stripeSecretKeyPrefix) {
@@ -568,7 +786,7 @@ func cleanAndValdiateStripeConfig(stripeConfig *Stripe) (err error) {
 	return
 }
 
-func cleanAndValdiatePingooConfig(pingoo *Pingoo) error {
+func cleanAndValdiatePingooConfig(pingoo *Pingoo, authProvider AuthProvider) error {
 	if pingoo.ProjectID == "" {
 		return errors.New("config: pingoo.project_id is empty")
 	}
@@ -589,7 +807,8 @@ func cleanAndValdiatePingooConfig(pingoo *Pingoo) error {
 		return errors.New("config: pingoo.endpoint must be http or https")
 	}
 
-	if len(pingoo.AppID) < 8 {
+	// the Pingoo app is only used to authenticate users
+	if authProvider == AuthProviderPingoo && len(pingoo.AppID) < 8 {
 		return errors.New("config: pingoo.app_id is not valid")
 	}
 
@@ -5,6 +5,47 @@ type EmailsProvider string
 const (
 	EmailsProviderConsole EmailsProvider = "console"
 	EmailsProviderSes     EmailsProvider = "ses"
+	EmailsProviderSmtp    EmailsProvider = "smtp"
+)
+
+type AuthProvider string
+
+const (
+	// Users are managed by pingoo.io
+	AuthProviderPingoo AuthProvider = "pingoo"
+	// Users are stored in the database of the instance
+	AuthProviderLocal AuthProvider = "local"
+)
+
+type GeoipProvider string
+
+const (
+	// The geoip database is downloaded from pingoo.io
+	GeoipProviderPingoo GeoipProvider = "pingoo"
+	// Local MaxMind-format (MMDB) database file
+	GeoipProviderMmdb GeoipProvider = "mmdb"
+	// Local CSV file of IP ranges: start_ip,end_ip,country[,asn,as_name]
+	GeoipProviderCsv GeoipProvider = "csv"
+)
+
+type RateLimitProvider string
+
+const (
+	// Counters are kept in the memory of each instance
+	RateLimitProviderMemory RateLimitProvider = "memory"
+	// Counters are stored in the database and shared by all the instances
+	RateLimitProviderPostgres RateLimitProvider = "postgres"
+)
+
+type PaymentsProvider string
+
+const (
+	// Payments are processed by stripe.com
+	PaymentsProviderStripe PaymentsProvider = "stripe"
+	// Payments are simulated locally, without any money being charged. Anyone can complete the
+	// checkout of paid products, so it must only be used for development, tests or when all the
+	// products are free.
+	PaymentsProviderFake PaymentsProvider = "fake"
 )
 
 type S3Provider string
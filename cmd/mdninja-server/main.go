@@ -24,7 +24,10 @@ import (
 	"markdown.ninja/migrations"
 	"markdown.ninja/pingoo-go"
 	"markdown.ninja/pkg/buildinfo"
+	"markdown.ninja/pkg/geoip"
 	"markdown.ninja/pkg/jwt"
+	"markdown.ninja/pkg/payments"
+	"markdown.ninja/pkg/ratelimit"
 	"markdown.ninja/pkg/scheduler"
 	"markdown.ninja/pkg/server"
 	contacts "markdown.ninja/pkg/services/contacts/service"
@@ -104,11 +107,6 @@ var rootCmd = &cobra.Command{
 		queue := postgres.NewPostgreSQLQueue(ctx, dbPool, logger)
 
 		// initialize drivers
-		mailer, err := loadMailer(conf)
-		if err != nil {
-			return err
-		}
-
 		s3Client, err := loadS3(conf)
 		if err != nil {
 			return err
@@ -126,20 +124,45 @@ var rootCmd = &cobra.Command{
 			return err
 		}
 
-		pingooClient, err := pingoo.NewClient(ctx, conf.Pingoo.ApiKey, conf.Pingoo.ProjectID, &pingoo.ClientConfig{
-			Url:    conf.Pingoo.Url,
-			Logger: logger,
-			GetLogger: func(ctx context.Context) *slog.Logger {
-				return slogx.FromCtx(ctx)
-			},
-		})
-		if err != nil {
-			return err
+		// pingooClient is nil when Pingoo is not enabled
+		var pingooClient *pingoo.Client
+		if conf.PingooIsEnabled() {
+			pingooClient, err = pingoo.NewClient(ctx, conf.Pingoo.ApiKey, conf.Pingoo.ProjectID, &pingoo.ClientConfig{
+				Url:    conf.Pingoo.Url,
+				Logger: logger,
+				GetLogger: func(ctx context.Context) *slog.Logger {
+					return slogx.FromCtx(ctx)
+				},
+				DisableGeoip: conf.Geoip.Provider != config.GeoipProviderPingoo,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		var geoipProvider geoip.Provider
+		if conf.Geoip.Provider == config.GeoipProviderPingoo {
+			geoipProvider = geoip.NewPingooProvider(pingooClient)
+		} else {
+			geoipProvider, err = geoip.NewFileProvider(ctx, conf.Geoip.Path, geoip.FileFormat(conf.Geoip.Provider), logger)
+			if err != nil {
+				return err
+			}
 		}
 
 		stripe.Key = conf.Stripe.SecretKey
 		stripe.EnableTelemetry = false
 
+		var paymentProvider payments.Provider
+		var fakePaymentProvider *payments.FakeProvider
+		if conf.Payments.Provider == config.PaymentsProviderFake {
+			logger.Warn("payments are simulated: anyone can complete the checkout of paid products without paying")
+			fakePaymentProvider = payments.NewFakeProvider(conf.HTTP.WebappBaseUrl.String(), logger)
+			paymentProvider = fakePaymentProvider
+		} else {
+			paymentProvider = payments.NewStripeProvider(conf.Stripe.WebhookSecret)
+		}
+
 		dnsResolver := &net.Resolver{
 			PreferGo: true,
 			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
@@ -151,10 +174,23 @@ var rootCmd = &cobra.Command{
 			},
 		}
 
+		// the SMTP mailer stores the DKIM keys of the email domains encrypted with the KMS
+		mailer, err := loadMailer(conf, dbPool, kms, dnsResolver)
+		if err != nil {
+			return err
+		}
+
+		var rateLimiter ratelimit.Limiter
+		if conf.RateLimit.Provider == config.RateLimitProviderPostgres {
+			rateLimiter = ratelimit.NewPostgresLimiter(dbPool, logger)
+		} else {
+			rateLimiter = ratelimit.NewMemoryLimiter()
+		}
+
 		// init services
-		kernelService := kernel.NewKernelService(conf, dbPool, queue, mailer, pingooClient)
+		kernelService := kernel.NewKernelService(conf, dbPool, queue, mailer, pingooClient, jwtProvider, kms, rateLimiter)
 
-		organizationsService := organizations.NewOrganizationsService(conf, dbPool, mailer, queue, kernelService, pingooClient)
+		organizationsService := organizations.NewOrganizationsService(conf, dbPool, mailer, queue, paymentProvider, kernelService)
 
 		contentService, err := content.NewContentService(conf, dbPool, queue, s3Client, kernelService, organizationsService)
 		if err != nil {
@@ -170,7 +206,7 @@ var rootCmd = &cobra.Command{
 			eventsService, contentService, organizationsService,
 		)
 
-		websitesService, err := websites.NewWebsitesService(conf, dbPool, queue, mailer, s3Client,
+		websitesService, err := websites.NewWebsitesService(ctx, conf, dbPool, queue, mailer, s3Client,
 			kernelService, emailsService, contentService, eventsService, organizationsService,
 		)
 		if err != nil {
@@ -178,21 +214,22 @@ var rootCmd = &cobra.Command{
 		}
 
 		contactsService, err := contacts.NewContactsService(conf, dbPool, mailer, queue, jwtProvider,
-			kernelService, websitesService, eventsService, emailsService, pingooClient)
+			kernelService, websitesService, eventsService, emailsService, paymentProvider)
 		if err != nil {
 			return err
 		}
 
-		storeService, err := store.NewStoreService(dbPool, queue, conf, mailer,
+		storeService, err := store.NewStoreService(dbPool, queue, conf, mailer, s3Client,
 			kernelService, websitesService, contentService, contactsService, eventsService, emailsService,
-			organizationsService, pingooClient,
+			organizationsService, rateLimiter, paymentProvider, jwtProvider,
 		)
 		if err != nil {
 			return err
 		}
 
 		siteService, err := site.NewSiteService(conf, dbPool, queue, mailer, logger, kernelService, websitesService,
-			contentService, eventsService, contactsService, emailsService, storeService,
+			contentService, eventsService, contactsService, emailsService, storeService, rateLimiter,
+			jwtProvider, pingooClient,
 		)
 		if err != nil {
 			return err
@@ -200,10 +237,13 @@ var rootCmd = &cobra.Command{
 
 		contentService.InjectServices(websitesService, storeService, emailsService)
 		websitesService.InjectServices(storeService, contactsService)
-		emailsService.InjectServices(websitesService, contactsService)
+		emailsService.InjectServices(websitesService, contactsService, storeService)
 		eventsService.InjectServices(websitesService)
 		contactsService.InjectServices(storeService)
 		organizationsService.InjectServices(websitesService, eventsService, contentService, storeService)
+		if fakePaymentProvider != nil {
+			fakePaymentProvider.SetEventHandler(organizationsService.HandlePaymentEvent)
+		}
 
 		var gracefulShutdownWaitGroup sync.WaitGroup
 
@@ -232,7 +272,7 @@ var rootCmd = &cobra.Command{
 			}
 		}()
 
-		err = server.Start(ctx, conf, dbPool, pingooClient, kernelService, websitesService, contactsService, emailsService,
+		err = server.Start(ctx, conf, dbPool, pingooClient, geoipProvider, paymentProvider, kernelService, websitesService, contactsService, emailsService,
 			storeService, eventsService, siteService, contentService, organizationsService, logger, kms)
 		if err != nil {
 			logger.Error("cli.server: error running server", slogx.Err(err))
@@ -280,6 +320,7 @@ func logConfig(logger *slog.Logger, conf config.Config) {
 				slog.String("provider", string(conf.Emails.Provider)),
 				slog.String("notify_address", conf.Emails.NotifyAddressStr),
 				slog.String("contact_address", conf.Emails.ContactAddressStr),
+				slog.Bool("feedback_webhook", conf.Emails.FeedbackWebhookSecret != ""),
 			),
 			slog.Group("s3",
 				slog.String("provider", string(conf.S3.Provider)),
@@ -287,6 +328,7 @@ func logConfig(logger *slog.Logger, conf config.Config) {
 			slog.Bool("aws", conf.Aws != nil),
 			slog.Bool("scaleway", conf.Scaleway != nil),
 			slog.Group("pingoo",
+				slog.Bool("enabled", conf.PingooIsEnabled()),
 				slog.String("project", conf.Pingoo.ProjectID),
 				slog.String("url", formatStringPtr(conf.Pingoo.Url)),
 			),
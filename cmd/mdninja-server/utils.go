@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 
+	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/log/loki"
 	"markdown.ninja/cmd/mdninja-server/config"
 	"markdown.ninja/pkg/buildinfo"
@@ -13,6 +15,7 @@ import (
 	"markdown.ninja/pkg/mailer"
 	"markdown.ninja/pkg/mailer/console"
 	"markdown.ninja/pkg/mailer/ses"
+	"markdown.ninja/pkg/mailer/smtp"
 	"markdown.ninja/pkg/storage/s3"
 )
 
@@ -31,7 +34,7 @@ func newLogger(ctx context.Context) (*slog.Logger, *slog.LevelVar, *loki.Writer)
 	return logger, logLevel, lokiWriter
 }
 
-func loadMailer(conf config.Config) (mailer mailer.Mailer, err error) {
+func loadMailer(conf config.Config, db db.DB, kms *kms.Kms, dnsResolver *net.Resolver) (mailer mailer.Mailer, err error) {
 	switch conf.Emails.Provider {
 	case config.EmailsProviderConsole:
 		mailer = console.NewConsoleMailer()
@@ -45,9 +48,24 @@ func loadMailer(conf config.Config) (mailer mailer.Mailer, err error) {
 			Region:          conf.Aws.Region,
 		}
 		mailer, err = ses.NewSesMailer(sesConf)
+	case config.EmailsProviderSmtp:
+		if conf.Smtp == nil {
+			return nil, errors.New("mailer: config.smtp is null")
+		}
+		smtpConf := smtp.Config{
+			Host:         conf.Smtp.Host,
+			Port:         conf.Smtp.Port,
+			Username:     conf.Smtp.Username,
+			Password:     conf.Smtp.Password,
+			Tls:          conf.Smtp.Tls,
+			HeloName:     conf.Smtp.HeloName,
+			PoolSize:     conf.Smtp.PoolSize,
+			DkimSelector: conf.Smtp.DkimSelector,
+		}
+		mailer, err = smtp.NewSmtpMailer(smtpConf, db, kms, dnsResolver)
 	default:
-		err = fmt.Errorf("mailer: %s is not a valid email provider. Valid values are: [%s, %s]",
-			conf.Emails.Provider, config.EmailsProviderConsole, config.EmailsProviderSes)
+		err = fmt.Errorf("mailer: %s is not a valid email provider. Valid values are: [%s, %s, %s]",
+			conf.Emails.Provider, config.EmailsProviderConsole, config.EmailsProviderSes, config.EmailsProviderSmtp)
 	}
 
 	return
@@ -241,7 +241,23 @@ func (client *Client) readAndParseMarkdownFile(ctx context.Context, filesystem f
 		localPage.SendAsNewsletter = false
 	}
 
-	localPage.MetadataHash = content.HashPageMetadata(localPage.Type, localPage.Url, localPage.Date, localPage.SendAsNewsletter, localPage.Language, localPage.Title, localPage.Description, localPage.Tags)
+	visibilityInterface := frontmatter.Data["visibility"]
+	if visibilityInterface != nil {
+		visibilityStr, visibilityInterfaceIsString := visibilityInterface.(string)
+		if !visibilityInterfaceIsString {
+			err = fmt.Errorf("publish: parsing frontmatter: visibility is not a string (%s)", realPath)
+			return
+		}
+		localPage.Visibility = content.PageVisibility(strings.ToLower(strings.TrimSpace(visibilityStr)))
+		if localPage.Visibility != content.PageVisibilityPublic && localPage.Visibility != content.PageVisibilityMembers {
+			err = fmt.Errorf("publish: parsing frontmatter: visibility must be public or members (%s)", realPath)
+			return
+		}
+	} else {
+		localPage.Visibility = content.PageVisibilityPublic
+	}
+
+	localPage.MetadataHash = content.HashPageMetadata(localPage.Type, localPage.Url, localPage.Date, localPage.SendAsNewsletter, localPage.Language, localPage.Title, localPage.Description, localPage.Tags, localPage.Visibility)
 
 	return
 }
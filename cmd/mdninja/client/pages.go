@@ -32,6 +32,7 @@ type localPage struct {
 	BodyHash          []byte
 	MetadataHash      [32]byte
 	SendAsNewsletter  bool
+	Visibility        content.PageVisibility
 }
 
 func (client *Client) uploadPages(ctx context.Context, websiteID guid.GUID, pageDirs []string) (err error) {
@@ -87,6 +88,7 @@ func (client *Client) uploadPages(ctx context.Context, websiteID guid.GUID, page
 					Language:         localPage.Language,
 					Tags:             localPage.Tags,
 					SendAsNewsletter: localPage.SendAsNewsletter,
+					Visibility:       &localPage.Visibility,
 				}
 				_, err = client.apiClient.UpdatePage(ctx, updatePageInput)
 				if err != nil {
@@ -108,6 +110,7 @@ func (client *Client) uploadPages(ctx context.Context, websiteID guid.GUID, page
 				Tags:             localPage.Tags,
 				Draft:            localPage.Draft,
 				SendAsNewsletter: localPage.SendAsNewsletter,
+				Visibility:       &localPage.Visibility,
 			}
 			_, err = client.apiClient.CreatePage(ctx, createPageInput)
 			if err != nil {
@@ -0,0 +1,17 @@
+CREATE TABLE product_ebooks (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  format TEXT NOT NULL,
+  content_hash BYTEA NOT NULL,
+  size BIGINT NOT NULL,
+
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+
+  UNIQUE(product_id, format)
+);
+CREATE INDEX index_product_ebooks_on_product_id ON product_ebooks (product_id);
+
+ALTER TABLE products ADD COLUMN ebook_watermark BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE products ALTER COLUMN ebook_watermark DROP DEFAULT;
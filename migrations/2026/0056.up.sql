@@ -0,0 +1,53 @@
+-- users of the local identity provider. Not used when authentication is delegated to pingoo.io
+CREATE TABLE users (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  name TEXT NOT NULL,
+  email TEXT NOT NULL,
+  is_admin BOOLEAN NOT NULL,
+  password_hash TEXT,
+  encrypted_totp_secret BYTEA,
+  two_fa_enabled BOOLEAN NOT NULL,
+  oidc_subject TEXT,
+  blocked_at TIMESTAMP WITH TIME ZONE,
+  last_login_at TIMESTAMP WITH TIME ZONE
+);
+CREATE UNIQUE INDEX index_users_on_email ON users (email);
+CREATE UNIQUE INDEX index_users_on_oidc_subject ON users (oidc_subject);
+
+
+CREATE TABLE pending_users (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  name TEXT NOT NULL,
+  email TEXT NOT NULL,
+  password_hash TEXT,
+  code_hash TEXT NOT NULL,
+  failed_attempts BIGINT NOT NULL
+);
+CREATE INDEX index_pending_users_on_created_at ON pending_users (created_at);
+
+
+CREATE TABLE sessions (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  secret_hash BYTEA NOT NULL,
+  code_hash TEXT NOT NULL,
+  failed_attempts BIGINT NOT NULL,
+  two_fa_pending BOOLEAN NOT NULL,
+  verified BOOLEAN NOT NULL,
+  ip TEXT NOT NULL,
+  user_agent TEXT NOT NULL,
+  country_code TEXT NOT NULL,
+
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX index_sessions_on_user_id ON sessions (user_id);
+CREATE INDEX index_sessions_on_verified ON sessions (verified);
+CREATE INDEX index_sessions_on_created_at ON sessions (created_at);
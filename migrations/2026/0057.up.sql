@@ -0,0 +1,14 @@
+-- counters of the Postgres rate limiter. The table is UNLOGGED because counters are short-lived and
+-- can be lost on crash.
+CREATE UNLOGGED TABLE rate_limits (
+  key BYTEA NOT NULL,
+  window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+  count BIGINT NOT NULL,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  PRIMARY KEY (key, window_start)
+);
+CREATE INDEX index_rate_limits_on_expires_at ON rate_limits (expires_at);
+
+ALTER TABLE websites ADD COLUMN rate_limits JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE websites ALTER COLUMN rate_limits DROP DEFAULT;
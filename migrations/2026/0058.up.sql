@@ -0,0 +1,16 @@
+CREATE TABLE firewall_rules (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  position BIGINT NOT NULL,
+  name TEXT NOT NULL,
+  enabled BOOLEAN NOT NULL,
+  conditions JSONB NOT NULL,
+  action JSONB NOT NULL,
+  hits BIGINT NOT NULL,
+  last_hit_at TIMESTAMP WITH TIME ZONE,
+
+  website_id UUID NOT NULL REFERENCES websites(id) ON DELETE CASCADE
+);
+CREATE INDEX index_firewall_rules_on_website_id ON firewall_rules (website_id);
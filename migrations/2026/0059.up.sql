@@ -0,0 +1,6 @@
+ALTER TABLE coupons ADD COLUMN uses BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE orders
+  ADD COLUMN discount_amount BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN coupon_id UUID REFERENCES coupons(id) ON DELETE SET NULL;
+CREATE INDEX index_orders_on_coupon_id ON orders (coupon_id);
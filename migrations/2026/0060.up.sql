@@ -0,0 +1,25 @@
+ALTER TABLE products ADD COLUMN billing_interval TEXT;
+
+CREATE TABLE memberships (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  status TEXT NOT NULL,
+  current_period_end TIMESTAMP WITH TIME ZONE,
+  cancel_at_period_end BOOLEAN NOT NULL,
+  canceled_at TIMESTAMP WITH TIME ZONE,
+  stripe_subscription_id TEXT,
+
+  website_id UUID NOT NULL REFERENCES websites(id) ON DELETE CASCADE,
+  contact_id UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE
+);
+CREATE INDEX index_memberships_on_website_id ON memberships (website_id);
+CREATE INDEX index_memberships_on_contact_id ON memberships (contact_id);
+CREATE INDEX index_memberships_on_product_id ON memberships (product_id);
+CREATE UNIQUE INDEX index_memberships_on_stripe_subscription_id ON memberships (stripe_subscription_id);
+
+ALTER TABLE pages ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public';
+
+ALTER TABLE newsletters ADD COLUMN members_only BOOLEAN NOT NULL DEFAULT false;
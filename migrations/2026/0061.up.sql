@@ -0,0 +1,6 @@
+CREATE TABLE bundles_products (
+  bundle_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  PRIMARY KEY (bundle_id, product_id)
+);
+CREATE INDEX index_bundles_products_on_product_id ON bundles_products (product_id);
@@ -0,0 +1,2 @@
+ALTER TABLE products ADD COLUMN prices JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE products ADD COLUMN pay_what_you_want BOOLEAN NOT NULL DEFAULT false;
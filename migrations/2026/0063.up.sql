@@ -0,0 +1,13 @@
+-- number of days after the purchase before the page of a course is unlocked. 0 = immediately
+ALTER TABLE product_pages ADD COLUMN drip_days BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE product_pages_completions (
+  completed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  contact_id UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+  product_page_id UUID NOT NULL REFERENCES product_pages(id) ON DELETE CASCADE,
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  PRIMARY KEY (contact_id, product_page_id)
+);
+CREATE INDEX index_product_pages_completions_on_product_page_id ON product_pages_completions (product_page_id);
+CREATE INDEX index_product_pages_completions_on_product_id ON product_pages_completions (product_id);
@@ -0,0 +1,35 @@
+-- how license keys are issued to customers: none, generated or pool
+ALTER TABLE products ADD COLUMN license_keys TEXT NOT NULL DEFAULT 'none';
+-- maximum number of activations of each license key. 0 = unlimited
+ALTER TABLE products ADD COLUMN license_key_activations_limit BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE license_keys (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  key TEXT NOT NULL,
+  status TEXT NOT NULL,
+  issued_at TIMESTAMP WITH TIME ZONE,
+  revoked_at TIMESTAMP WITH TIME ZONE,
+
+  website_id UUID NOT NULL REFERENCES websites(id) ON DELETE CASCADE,
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+  contact_id UUID REFERENCES contacts(id) ON DELETE SET NULL
+);
+CREATE INDEX index_license_keys_on_website_id ON license_keys (website_id);
+CREATE UNIQUE INDEX index_license_keys_on_product_id_and_key ON license_keys (product_id, key);
+CREATE INDEX index_license_keys_on_order_id ON license_keys (order_id);
+CREATE INDEX index_license_keys_on_contact_id ON license_keys (contact_id);
+
+CREATE TABLE license_key_activations (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  -- identifier of the activated machine / installation, chosen by the application
+  instance TEXT NOT NULL,
+
+  license_key_id UUID NOT NULL REFERENCES license_keys(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX index_license_key_activations_on_license_key_id_and_instance ON license_key_activations (license_key_id, instance);
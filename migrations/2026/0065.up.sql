@@ -0,0 +1,16 @@
+-- set when the abandoned checkout recovery email of a pending order is sent, so it's sent only once
+ALTER TABLE orders ADD COLUMN recovery_email_sent_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX index_orders_on_status_and_created_at ON orders (status, created_at) WHERE recovery_email_sent_at IS NULL;
+
+CREATE TABLE abandoned_checkouts_settings (
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  enabled BOOLEAN NOT NULL,
+  -- number of hours after an order is placed before the recovery email is sent
+  delay_hours BIGINT NOT NULL,
+  subject TEXT NOT NULL,
+  body_markdown TEXT NOT NULL,
+
+  website_id UUID PRIMARY KEY REFERENCES websites(id) ON DELETE CASCADE
+);
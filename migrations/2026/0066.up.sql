@@ -0,0 +1,59 @@
+CREATE TABLE invoicing_settings (
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  enabled BOOLEAN NOT NULL,
+  seller_name TEXT NOT NULL,
+  seller_address TEXT NOT NULL,
+  seller_vat_number TEXT NOT NULL,
+  -- in basis points (e.g. 2000 = 20%)
+  vat_rate BIGINT NOT NULL,
+  notes TEXT NOT NULL,
+  invoice_number_prefix TEXT NOT NULL,
+  credit_note_number_prefix TEXT NOT NULL,
+  -- counters used to number invoices and credit notes sequentially, without gaps
+  next_invoice_number BIGINT NOT NULL,
+  next_credit_note_number BIGINT NOT NULL,
+
+  website_id UUID PRIMARY KEY REFERENCES websites(id) ON DELETE CASCADE
+);
+
+CREATE TABLE invoices (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  type TEXT NOT NULL,
+  sequence BIGINT NOT NULL,
+  number TEXT NOT NULL,
+  currency TEXT NOT NULL,
+  line_items JSONB NOT NULL,
+  vat_rate BIGINT NOT NULL,
+  -- amounts are in cents
+  net_amount BIGINT NOT NULL,
+  vat_amount BIGINT NOT NULL,
+  total_amount BIGINT NOT NULL,
+  credited_invoice_number TEXT NOT NULL,
+
+  -- snapshot of the seller and buyer details at the time the invoice was issued
+  seller_name TEXT NOT NULL,
+  seller_address TEXT NOT NULL,
+  seller_vat_number TEXT NOT NULL,
+  buyer_email TEXT NOT NULL,
+  buyer_country TEXT NOT NULL,
+  additional_invoice_information TEXT NOT NULL,
+  notes TEXT NOT NULL,
+
+  pdf_size BIGINT NOT NULL,
+  html_size BIGINT NOT NULL,
+
+  website_id UUID NOT NULL REFERENCES websites(id) ON DELETE CASCADE,
+  order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+  refund_id UUID REFERENCES refunds(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX index_invoices_on_website_id_and_type_and_sequence ON invoices (website_id, type, sequence);
+CREATE INDEX index_invoices_on_website_id_and_created_at ON invoices (website_id, created_at);
+CREATE INDEX index_invoices_on_order_id ON invoices (order_id);
+-- an order has at most 1 invoice, and a refund at most 1 credit note
+CREATE UNIQUE INDEX index_invoices_on_order_id_invoice ON invoices (order_id) WHERE type = 'invoice';
+CREATE UNIQUE INDEX index_invoices_on_refund_id ON invoices (refund_id);
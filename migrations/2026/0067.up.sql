@@ -0,0 +1,6 @@
+-- gift purchases: the products of the order are given to the recipient instead of the buyer.
+-- gift_recipient_contact_id is set when the order is completed.
+ALTER TABLE orders ADD COLUMN gift_recipient_email TEXT;
+ALTER TABLE orders ADD COLUMN gift_message TEXT NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN gift_recipient_contact_id UUID REFERENCES contacts(id) ON DELETE SET NULL;
+CREATE INDEX index_orders_on_gift_recipient_contact_id ON orders (gift_recipient_contact_id);
@@ -0,0 +1,17 @@
+-- smtp mailer: DKIM keys of the email domains (encrypted with the KMS) and addresses rejected by the
+-- SMTP server
+CREATE TABLE smtp_dkim_keys (
+    domain TEXT PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    selector TEXT NOT NULL,
+    encrypted_private_key BYTEA NOT NULL
+);
+
+CREATE TABLE smtp_suppressions (
+    email TEXT PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    reason TEXT NOT NULL
+);
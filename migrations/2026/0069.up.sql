@@ -0,0 +1,20 @@
+-- bounces and complaints reported by the email providers for the emails sent to the contacts
+CREATE TABLE contacts_email_feedback (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    -- the identifier of the notification given by the provider, used to ignore duplicates
+    provider_id TEXT NOT NULL,
+    source TEXT NOT NULL,
+    type TEXT NOT NULL,
+    bounce_type TEXT NOT NULL,
+    email TEXT NOT NULL,
+    diagnostic TEXT NOT NULL,
+
+    contact_id UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    website_id UUID NOT NULL REFERENCES websites(id) ON DELETE CASCADE,
+    newsletter_id UUID REFERENCES newsletters(id) ON DELETE SET NULL
+);
+CREATE INDEX index_contacts_email_feedback_on_contact_id ON contacts_email_feedback (contact_id);
+CREATE INDEX index_contacts_email_feedback_on_website_id ON contacts_email_feedback (website_id);
+CREATE UNIQUE INDEX index_contacts_email_feedback_on_contact_id_and_provider_id ON contacts_email_feedback (contact_id, provider_id) WHERE provider_id != '';
@@ -0,0 +1,9 @@
+-- opt-in open and click tracking of the newsletters
+ALTER TABLE emails_website_configuration ADD COLUMN track_clicks BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE emails_website_configuration ADD COLUMN track_opens BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE emails_website_configuration ADD COLUMN track_contacts BOOLEAN NOT NULL DEFAULT false;
+
+-- the key used to sign the tracking links of the newsletter. Generated when the newsletter is sent.
+ALTER TABLE newsletters ADD COLUMN tracking_key BYTEA;
+
+CREATE INDEX index_events_on_newsletter_id ON events (newsletter_id) WHERE newsletter_id IS NOT NULL;
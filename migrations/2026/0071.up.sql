@@ -0,0 +1,45 @@
+-- automated sequences of emails (welcome series, drips...) sent to the contacts after a trigger
+CREATE TABLE emails_sequences (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    name TEXT NOT NULL,
+    trigger TEXT NOT NULL,
+    trigger_product_id UUID REFERENCES products(id) ON DELETE SET NULL,
+    exit_on_purchase BOOLEAN NOT NULL,
+    exit_product_id UUID REFERENCES products(id) ON DELETE SET NULL,
+    paused_at TIMESTAMP WITH TIME ZONE,
+    steps JSONB NOT NULL,
+
+    website_id UUID NOT NULL REFERENCES websites(id) ON DELETE CASCADE
+);
+CREATE INDEX index_emails_sequences_on_website_id ON emails_sequences (website_id);
+
+-- the state of the contacts in the sequences
+CREATE TABLE emails_sequences_contacts (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    status TEXT NOT NULL,
+    next_step BIGINT NOT NULL,
+    next_step_at TIMESTAMP WITH TIME ZONE,
+    exit_reason TEXT NOT NULL,
+
+    sequence_id UUID NOT NULL REFERENCES emails_sequences(id) ON DELETE CASCADE,
+    contact_id UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    website_id UUID NOT NULL REFERENCES websites(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX index_emails_sequences_contacts_on_sequence_id_and_contact_id ON emails_sequences_contacts (sequence_id, contact_id);
+CREATE INDEX index_emails_sequences_contacts_on_contact_id ON emails_sequences_contacts (contact_id);
+CREATE INDEX index_emails_sequences_contacts_on_next_step_at ON emails_sequences_contacts (next_step_at) WHERE status = 'active';
+
+-- number of emails sent for each step of the sequences
+CREATE TABLE emails_sequences_steps_stats (
+    step_id UUID NOT NULL,
+    emails_sent BIGINT NOT NULL,
+
+    sequence_id UUID NOT NULL REFERENCES emails_sequences(id) ON DELETE CASCADE,
+    PRIMARY KEY (sequence_id, step_id)
+);
@@ -0,0 +1,14 @@
+-- posts sent as newsletter can be grouped in a periodic digest instead of being sent individually
+ALTER TABLE emails_website_configuration ADD COLUMN digest_frequency TEXT NOT NULL DEFAULT 'disabled';
+ALTER TABLE emails_website_configuration ADD COLUMN digest_subject TEXT NOT NULL DEFAULT '';
+ALTER TABLE emails_website_configuration ADD COLUMN digest_template TEXT NOT NULL DEFAULT '';
+ALTER TABLE emails_website_configuration ADD COLUMN digest_include_excerpts BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE emails_website_configuration ADD COLUMN digest_period_start TIMESTAMP WITH TIME ZONE;
+ALTER TABLE emails_website_configuration ADD COLUMN digest_next_send_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX index_emails_website_configuration_on_digest_next_send_at ON emails_website_configuration (digest_next_send_at)
+    WHERE digest_next_send_at IS NOT NULL;
+
+ALTER TABLE newsletters ADD COLUMN digest BOOLEAN NOT NULL DEFAULT false;
+
+-- whether the contact wants to receive each post or the digest, when the website sends digests
+ALTER TABLE contacts ADD COLUMN newsletter_delivery TEXT NOT NULL DEFAULT 'digest';
@@ -0,0 +1,2 @@
+-- public archive of the newsletters on the website. Disabled when empty
+ALTER TABLE emails_website_configuration ADD COLUMN archive_path TEXT NOT NULL DEFAULT '';
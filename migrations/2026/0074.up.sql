@@ -0,0 +1,2 @@
+-- subject A/B tests of the newsletters. NULL when the newsletter is not A/B tested
+ALTER TABLE newsletters ADD COLUMN ab_test JSONB;
@@ -0,0 +1,16 @@
+-- custom templates of the emails sent on behalf of the websites. The default template is used when a
+-- website has no template for a type of emails
+CREATE TABLE emails_templates (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    type TEXT NOT NULL,
+    body TEXT NOT NULL,
+
+    website_id UUID NOT NULL REFERENCES websites(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX index_emails_templates_on_website_id_and_type ON emails_templates (website_id, type);
+
+ALTER TABLE emails_website_configuration ADD COLUMN footer_text TEXT NOT NULL DEFAULT '';
+ALTER TABLE emails_website_configuration ADD COLUMN legal_address TEXT NOT NULL DEFAULT '';
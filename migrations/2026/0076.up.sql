@@ -0,0 +1,33 @@
+-- the total number of emails / s sent for the newsletters of the websites of the organization
+ALTER TABLE organizations ADD COLUMN emails_send_rate BIGINT NOT NULL DEFAULT 10;
+ALTER TABLE organizations ALTER COLUMN emails_send_rate DROP DEFAULT;
+
+ALTER TABLE emails_website_configuration ADD COLUMN send_rate BIGINT NOT NULL DEFAULT 5;
+ALTER TABLE emails_website_configuration ALTER COLUMN send_rate DROP DEFAULT;
+-- the domains verified before the warmup was introduced are considered as warmed up
+ALTER TABLE emails_website_configuration ADD COLUMN domain_verified_at TIMESTAMP WITH TIME ZONE;
+UPDATE emails_website_configuration SET domain_verified_at = created_at WHERE domain_verified = true;
+
+ALTER TABLE newsletters ADD COLUMN sending_paused_at TIMESTAMP WITH TIME ZONE;
+
+-- the recipients of the newsletters. The emails are scheduled progressively by
+-- emails.TaskSendNewsletterEmails
+CREATE TABLE newsletters_recipients (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    status TEXT NOT NULL,
+    email TEXT NOT NULL,
+    name TEXT NOT NULL,
+    variant BIGINT,
+    sent_at TIMESTAMP WITH TIME ZONE,
+
+    contact_id UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    newsletter_id UUID NOT NULL REFERENCES newsletters(id) ON DELETE CASCADE,
+    website_id UUID NOT NULL REFERENCES websites(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX index_newsletters_recipients_on_newsletter_id_and_contact_id ON newsletters_recipients (newsletter_id, contact_id);
+CREATE INDEX index_newsletters_recipients_on_website_id_and_sent_at ON newsletters_recipients (website_id, sent_at);
+CREATE INDEX index_newsletters_recipients_on_contact_id ON newsletters_recipients (contact_id);
+CREATE INDEX index_newsletters_recipients_on_pending_newsletter_id ON newsletters_recipients (newsletter_id) WHERE status = 'pending';
@@ -0,0 +1,16 @@
+-- the topics that the contacts subscribed to the newsletter choose to receive in the preference center
+ALTER TABLE contacts ADD COLUMN receive_newsletters BOOLEAN NOT NULL DEFAULT true;
+-- subscribed_to_product_updates_at was not used until now
+UPDATE contacts SET subscribed_to_product_updates_at = created_at WHERE subscribed_to_product_updates_at IS NULL;
+
+-- the tags of the posts that the contacts want to receive. Contacts without tags receive all the posts
+CREATE TABLE contacts_newsletter_tags (
+  contact_id UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+  tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+
+  PRIMARY KEY (contact_id, tag_id)
+);
+CREATE INDEX index_contacts_newsletter_tags_on_tag_id ON contacts_newsletter_tags (tag_id);
+
+-- product updates are only sent to the contacts subscribed to the product updates
+ALTER TABLE newsletters ADD COLUMN product_updates BOOLEAN NOT NULL DEFAULT false;
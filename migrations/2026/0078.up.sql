@@ -0,0 +1,34 @@
+-- the products given access to by the completed orders, including the items of the bundles at the
+-- time of the order, so that the access can be revoked if the order is refunded
+CREATE TABLE orders_granted_products (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  bundle_id UUID REFERENCES products(id) ON DELETE CASCADE,
+  order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  contact_id UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE
+);
+CREATE INDEX index_orders_granted_products_on_order_id ON orders_granted_products (order_id);
+CREATE INDEX index_orders_granted_products_on_contact_id ON orders_granted_products (contact_id);
+CREATE INDEX index_orders_granted_products_on_product_id ON orders_granted_products (product_id);
+CREATE INDEX index_orders_granted_products_on_bundle_id ON orders_granted_products (bundle_id);
+
+-- the completed (status = 1) and non-refunded orders are given the current items of their bundles.
+-- Memberships (type = 3) don't give access to products.
+INSERT INTO orders_granted_products (id, created_at, bundle_id, order_id, product_id, contact_id)
+  SELECT gen_random_uuid(), COALESCE(orders.completed_at, orders.created_at), NULL, orders.id,
+      order_line_items.product_id, COALESCE(orders.gift_recipient_contact_id, orders.contact_id)
+    FROM orders
+      INNER JOIN order_line_items ON order_line_items.order_id = orders.id
+      INNER JOIN products ON products.id = order_line_items.product_id
+    WHERE orders.status = 1 AND products.type != 3
+      AND NOT EXISTS (SELECT 1 FROM refunds WHERE refunds.order_id = orders.id AND refunds.status = 'succeeded')
+  UNION ALL
+  SELECT gen_random_uuid(), COALESCE(orders.completed_at, orders.created_at), bundles_products.bundle_id, orders.id,
+      bundles_products.product_id, COALESCE(orders.gift_recipient_contact_id, orders.contact_id)
+    FROM orders
+      INNER JOIN order_line_items ON order_line_items.order_id = orders.id
+      INNER JOIN bundles_products ON bundles_products.bundle_id = order_line_items.product_id
+    WHERE orders.status = 1
+      AND NOT EXISTS (SELECT 1 FROM refunds WHERE refunds.order_id = orders.id AND refunds.status = 'succeeded');
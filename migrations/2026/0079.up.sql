@@ -0,0 +1,13 @@
+-- the license keys that could not be issued when the orders were completed because the pool of keys
+-- of the product was empty. They are issued when keys are added to the pool.
+CREATE TABLE pending_license_keys (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+  contact_id UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE
+);
+CREATE INDEX index_pending_license_keys_on_product_id ON pending_license_keys (product_id);
+CREATE INDEX index_pending_license_keys_on_order_id ON pending_license_keys (order_id);
+CREATE INDEX index_pending_license_keys_on_contact_id ON pending_license_keys (contact_id);
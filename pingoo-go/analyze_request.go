@@ -3,7 +3,10 @@ package pingoo
 import (
 	"context"
 	"net/http"
+	"net/netip"
 	"strings"
+
+	"markdown.ninja/pingoo-go/rules"
 )
 
 type analyzeRequestInput struct {
@@ -62,6 +65,36 @@ func (client *Client) analyzeRequest(ctx context.Context, input analyzeRequestIn
 	}
 }
 
+// VerifyBot analyzes the request to detect if it comes from a bot, and verifies the identity of the bots
+// claiming to be well-known crawlers (e.g. with reverse DNS lookups).
+func (client *Client) VerifyBot(ctx context.Context, req *http.Request, ip netip.Addr, geoip GeoipRecord) (rules.BotVerification, error) {
+	input := analyzeRequestInput{
+		HttpMethod:       req.Method,
+		Hostname:         req.Host,
+		UserAgent:        req.UserAgent(),
+		Ip:               ip.String(),
+		Asn:              geoip.ASN,
+		Country:          geoip.Country,
+		Path:             req.URL.Path,
+		HttpVersionMajor: int64(req.ProtoMajor),
+		HttpVersionMinor: int64(req.ProtoMinor),
+		Headers:          convertHttpheaders(req.Header),
+	}
+	output, err := client.analyzeRequest(ctx, input)
+	if err != nil {
+		return rules.BotVerificationNone, err
+	}
+
+	switch output.Outcome {
+	case AnalyzeRequestOutcomeVerifiedBot:
+		return rules.BotVerificationVerified, nil
+	case AnalyzeRequestOutcomeBlocked, AnalyzeRequestOutcomeChallenge:
+		return rules.BotVerificationUnverified, nil
+	default:
+		return rules.BotVerificationNone, nil
+	}
+}
+
 type verifyBotInput struct {
 	HttpMethod string `json:"http_method"`
 	UserAgent  string `json:"user_agent"`
@@ -38,6 +38,9 @@ type ClientConfig struct {
 	HttpClient *http.Client
 	Logger     *slog.Logger
 	GetLogger  func(context.Context) *slog.Logger
+	// DisableGeoip disables the download and refresh of the geoip database.
+	// Use it when geoip lookups are performed by another provider (see MiddlewareConfig.GeoipLookup).
+	DisableGeoip bool
 }
 
 type Client struct {
@@ -173,6 +176,10 @@ func NewClient(ctx context.Context, apiKey string, projectID string, config *Cli
 
 	// GeoIP
 
+	if config.DisableGeoip {
+		return client, nil
+	}
+
 	err = retry.Do(func() error {
 		retryErr := client.refreshGeoipDatabase(ctx)
 		return retryErr
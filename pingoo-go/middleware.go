@@ -24,6 +24,9 @@ type MiddlewareConfig struct {
 	// CdnProvider string
 	Logging LoggingConfig
 	Rules   []rules.Rule
+	// GeoipLookup, if not nil, is used instead of Client.GeoipLookup to resolve the geoip information
+	// of the client's IP address.
+	GeoipLookup func(ctx context.Context, ip netip.Addr) (GeoipRecord, error)
 }
 
 type LoggingConfig struct {
@@ -44,6 +47,10 @@ func (client *Client) Middleware(config *MiddlewareConfig) func(next http.Handle
 	if config == nil {
 		config = &MiddlewareConfig{}
 	}
+	geoipLookup := config.GeoipLookup
+	if geoipLookup == nil {
+		geoipLookup = client.GeoipLookup
+	}
 	return func(nextMiddleware http.Handler) http.Handler {
 		fn := func(res http.ResponseWriter, req *http.Request) {
 
@@ -83,7 +90,7 @@ func (client *Client) Middleware(config *MiddlewareConfig) func(next http.Handle
 				clientIp = netip.AddrFrom4([4]byte{0, 0, 0, 0})
 			}
 
-			geoipInfo, err := client.GeoipLookup(ctx, clientIp)
+			geoipInfo, err := geoipLookup(ctx, clientIp)
 			if err != nil {
 				logger.Error(fmt.Sprintf("pingoo.Middleware: looking up geoip information [%s]: %s", clientIpStr, err))
 			}
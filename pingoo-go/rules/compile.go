@@ -0,0 +1,185 @@
+package rules
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Request holds the information about an HTTP request used to match compiled rules
+type Request struct {
+	Method    string
+	Path      string
+	Query     url.Values
+	UserAgent string
+	Country   string
+	ASN       int64
+	// VerifyBot is called at most once, and only if a rule has a bots condition, because verifying bots
+	// may be expensive (e.g. reverse DNS lookups). If nil, BotVerificationNone is assumed.
+	VerifyBot func() BotVerification
+
+	botVerification *BotVerification
+}
+
+func (req *Request) bot() BotVerification {
+	if req.botVerification == nil {
+		bot := BotVerificationNone
+		if req.VerifyBot != nil {
+			bot = req.VerifyBot()
+		}
+		req.botVerification = &bot
+	}
+	return *req.botVerification
+}
+
+// CompiledRule is a declarative rule ready to be matched against requests.
+// It is safe for concurrent use.
+type CompiledRule struct {
+	Action ActionDefinition
+
+	conditions []func(req *Request) bool
+}
+
+// Compile cleans, validates and compiles a declarative rule
+func Compile(definition Definition) (rule CompiledRule, err error) {
+	err = definition.Clean()
+	if err != nil {
+		return
+	}
+
+	conditions := definition.Conditions
+	rule.Action = definition.Action
+	rule.conditions = make([]func(req *Request) bool, 0, 7)
+
+	// conditions are ordered from the cheapest to the most expensive to evaluate
+	if len(conditions.Methods) != 0 {
+		methods := slices.Clone(conditions.Methods)
+		rule.conditions = append(rule.conditions, func(req *Request) bool {
+			return slices.Contains(methods, req.Method)
+		})
+	}
+
+	if len(conditions.Countries) != 0 {
+		countries := slices.Clone(conditions.Countries)
+		rule.conditions = append(rule.conditions, func(req *Request) bool {
+			return slices.Contains(countries, req.Country)
+		})
+	}
+
+	if len(conditions.Asns) != 0 {
+		asns := slices.Clone(conditions.Asns)
+		rule.conditions = append(rule.conditions, func(req *Request) bool {
+			return slices.Contains(asns, req.ASN)
+		})
+	}
+
+	if len(conditions.Paths) != 0 {
+		paths, err := compileGlobs(conditions.Paths, true, false)
+		if err != nil {
+			return rule, err
+		}
+		rule.conditions = append(rule.conditions, func(req *Request) bool {
+			return paths.MatchString(req.Path)
+		})
+	}
+
+	if len(conditions.UserAgents) != 0 {
+		userAgents, err := compileGlobs(conditions.UserAgents, false, true)
+		if err != nil {
+			return rule, err
+		}
+		rule.conditions = append(rule.conditions, func(req *Request) bool {
+			return userAgents.MatchString(req.UserAgent)
+		})
+	}
+
+	for _, queryCondition := range conditions.Query {
+		name := queryCondition.Name
+		var value *regexp.Regexp
+		if queryCondition.Value != "" {
+			value, err = compileGlobs([]string{queryCondition.Value}, false, false)
+			if err != nil {
+				return rule, err
+			}
+		}
+		rule.conditions = append(rule.conditions, func(req *Request) bool {
+			values, exists := req.Query[name]
+			if !exists {
+				return false
+			}
+			if value == nil {
+				return true
+			}
+			return slices.ContainsFunc(values, value.MatchString)
+		})
+	}
+
+	if len(conditions.Bots) != 0 {
+		bots := slices.Clone(conditions.Bots)
+		rule.conditions = append(rule.conditions, func(req *Request) bool {
+			return slices.Contains(bots, req.bot())
+		})
+	}
+
+	return rule, nil
+}
+
+// Match returns true if all the conditions of the rule match the request
+func (rule *CompiledRule) Match(req *Request) bool {
+	for _, condition := range rule.conditions {
+		if !condition(req) {
+			return false
+		}
+	}
+	return true
+}
+
+// compileGlobs compiles a list of glob patterns into a single anchored regexp matching any of the patterns.
+// If isPath is true, * and ? don't match '/' and ** matches any sequence of characters.
+func compileGlobs(patterns []string, isPath bool, caseInsensitive bool) (*regexp.Regexp, error) {
+	var regexpBuilder strings.Builder
+	if caseInsensitive {
+		regexpBuilder.WriteString("(?i)")
+	}
+	regexpBuilder.WriteString("^(?:")
+
+	for i, pattern := range patterns {
+		if i != 0 {
+			regexpBuilder.WriteByte('|')
+		}
+		for j := 0; j < len(pattern); j += 1 {
+			switch {
+			case pattern[j] == '*' && isPath && j+1 < len(pattern) && pattern[j+1] == '*':
+				regexpBuilder.WriteString(".*")
+				j += 1
+			case pattern[j] == '*' && isPath:
+				regexpBuilder.WriteString("[^/]*")
+			case pattern[j] == '*':
+				regexpBuilder.WriteString(".*")
+			case pattern[j] == '?' && isPath:
+				regexpBuilder.WriteString("[^/]")
+			case pattern[j] == '?':
+				regexpBuilder.WriteString(".")
+			default:
+				// find the next wildcard to quote the literal part at once
+				end := strings.IndexAny(pattern[j:], "*?")
+				if end < 0 {
+					end = len(pattern)
+				} else {
+					end += j
+				}
+				regexpBuilder.WriteString(regexp.QuoteMeta(pattern[j:end]))
+				j = end - 1
+			}
+		}
+	}
+	regexpBuilder.WriteString(")$")
+
+	compiledRegexp, err := regexp.Compile(regexpBuilder.String())
+	if err != nil {
+		return nil, fmt.Errorf("rules: error compiling patterns: %w", err)
+	}
+	return compiledRegexp, nil
+}
@@ -0,0 +1,129 @@
+package rules
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestCompileAndMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions Conditions
+		request    Request
+		expected   bool
+	}{
+		{"no conditions", Conditions{}, Request{Path: "/"}, true},
+		{"path exact", Conditions{Paths: []string{"/login"}}, Request{Path: "/login"}, true},
+		{"path exact mismatch", Conditions{Paths: []string{"/login"}}, Request{Path: "/login/x"}, false},
+		{"path star", Conditions{Paths: []string{"/blog/*"}}, Request{Path: "/blog/hello"}, true},
+		{"path star doesn't cross slashes", Conditions{Paths: []string{"/blog/*"}}, Request{Path: "/blog/a/b"}, false},
+		{"path double star", Conditions{Paths: []string{"/blog/**"}}, Request{Path: "/blog/a/b"}, true},
+		{"path regexp characters are literals", Conditions{Paths: []string{"/a.b"}}, Request{Path: "/axb"}, false},
+		{"path any of", Conditions{Paths: []string{"/a", "/b"}}, Request{Path: "/b"}, true},
+		{"method", Conditions{Methods: []string{"post"}}, Request{Method: "POST"}, true},
+		{"method mismatch", Conditions{Methods: []string{"POST"}}, Request{Method: "GET"}, false},
+		{"country", Conditions{Countries: []string{"fr", "DE"}}, Request{Country: "FR"}, true},
+		{"asn", Conditions{Asns: []int64{15169}}, Request{ASN: 13335}, false},
+		{"user agent", Conditions{UserAgents: []string{"*curl*"}}, Request{UserAgent: "CURL/8.0"}, true},
+		{"query present", Conditions{Query: []QueryCondition{{Name: "debug"}}}, Request{Query: url.Values{"debug": {""}}}, true},
+		{"query absent", Conditions{Query: []QueryCondition{{Name: "debug"}}}, Request{Query: url.Values{}}, false},
+		{"query value", Conditions{Query: []QueryCondition{{Name: "ref", Value: "spam*"}}}, Request{Query: url.Values{"ref": {"spammer"}}}, true},
+		{"bot default", Conditions{Bots: []BotVerification{BotVerificationNone}}, Request{}, true},
+		{
+			"all conditions must match",
+			Conditions{Paths: []string{"/api/**"}, Countries: []string{"FR"}},
+			Request{Path: "/api/login", Country: "DE"},
+			false,
+		},
+		{
+			"bot verification",
+			Conditions{Bots: []BotVerification{BotVerificationUnverified}},
+			Request{VerifyBot: func() BotVerification { return BotVerificationUnverified }},
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		rule, err := Compile(Definition{Conditions: test.conditions, Action: ActionDefinition{Type: ActionTypeBlock}})
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if matched := rule.Match(&test.request); matched != test.expected {
+			t.Errorf("%s: got %v, expected %v", test.name, matched, test.expected)
+		}
+	}
+}
+
+func TestVerifyBotIsLazy(t *testing.T) {
+	calls := 0
+	request := Request{
+		Path: "/",
+		VerifyBot: func() BotVerification {
+			calls += 1
+			return BotVerificationVerified
+		},
+	}
+
+	withoutBots, err := Compile(Definition{Action: ActionDefinition{Type: ActionTypeBlock}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withBots, err := Compile(Definition{
+		Conditions: Conditions{Bots: []BotVerification{BotVerificationVerified}},
+		Action:     ActionDefinition{Type: ActionTypeBlock},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withoutBots.Match(&request)
+	if calls != 0 {
+		t.Errorf("VerifyBot should not have been called")
+	}
+
+	withBots.Match(&request)
+	withBots.Match(&request)
+	if calls != 1 {
+		t.Errorf("VerifyBot should have been called once, got %d", calls)
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	invalidDefinitions := []Definition{
+		{Action: ActionDefinition{Type: "unknown"}},
+		{Conditions: Conditions{Paths: []string{"no_slash"}}, Action: ActionDefinition{Type: ActionTypeBlock}},
+		{Conditions: Conditions{Methods: []string{"FETCH"}}, Action: ActionDefinition{Type: ActionTypeBlock}},
+		{Conditions: Conditions{Countries: []string{"FRA"}}, Action: ActionDefinition{Type: ActionTypeBlock}},
+		{Conditions: Conditions{Bots: []BotVerification{"good"}}, Action: ActionDefinition{Type: ActionTypeBlock}},
+		{Action: ActionDefinition{Type: ActionTypeRateLimit}},
+		{Action: ActionDefinition{Type: ActionTypeRateLimit, RateLimit: &RateLimitDefinition{Requests: 10}}},
+		{Action: ActionDefinition{Type: ActionTypeRedirect, RedirectTo: "javascript:alert(1)"}},
+		{Action: ActionDefinition{Type: ActionTypeRedirect, RedirectTo: "//evil.com"}},
+		{Action: ActionDefinition{Type: ActionTypeRedirect, RedirectTo: "/ok", RedirectStatus: 200}},
+		{Action: ActionDefinition{Type: ActionTypeSetHeader}},
+		{Action: ActionDefinition{Type: ActionTypeSetHeader, Headers: []HttpHeader{{Name: "Set-Cookie", Value: "a=b"}}}},
+		{Action: ActionDefinition{Type: ActionTypeSetHeader, Headers: []HttpHeader{{Name: "X-Test", Value: "a\r\nb"}}}},
+	}
+
+	for _, definition := range invalidDefinitions {
+		_, err := Compile(definition)
+		if err == nil {
+			t.Errorf("expected an error for definition: %+v", definition)
+		}
+	}
+}
+
+func TestCompileCleansAction(t *testing.T) {
+	rule, err := Compile(Definition{Action: ActionDefinition{
+		Type:       ActionTypeRedirect,
+		RedirectTo: " https://example.com/new ",
+		RateLimit:  &RateLimitDefinition{Requests: 1, PeriodSeconds: 1},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rule.Action.RedirectTo != "https://example.com/new" || rule.Action.RedirectStatus != 302 || rule.Action.RateLimit != nil {
+		t.Errorf("action was not cleaned: %+v", rule.Action)
+	}
+}
@@ -0,0 +1,289 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Declarative rules are serializable rules that can be stored (e.g. in a database) and edited by
+// end users. They are compiled into a CompiledRule with Compile.
+//
+// A rule matches a request when all of its non-empty conditions match. A condition matches when any of
+// its values matches.
+
+type ActionType string
+
+const (
+	ActionTypeBlock     ActionType = "block"
+	ActionTypeChallenge ActionType = "challenge"
+	ActionTypeRateLimit ActionType = "rate_limit"
+	ActionTypeRedirect  ActionType = "redirect"
+	ActionTypeSetHeader ActionType = "set_header"
+)
+
+type BotVerification string
+
+const (
+	// The request comes from a bot whose identity has been verified (e.g. Googlebot from Google's network)
+	BotVerificationVerified BotVerification = "verified"
+	// The request has been identified as automated, but not as a verified bot
+	BotVerificationUnverified BotVerification = "unverified"
+	// The request has not been identified as coming from a bot
+	BotVerificationNone BotVerification = "none"
+)
+
+const (
+	MaxConditionValues   = 50
+	MaxPatternLength     = 256
+	MaxHeaders           = 20
+	MaxHeaderLength      = 1024
+	MaxRedirectLength    = 2048
+	MaxRateLimitPeriod   = 24 * 3600
+	MaxRateLimitRequests = 1_000_000
+)
+
+type Definition struct {
+	Conditions Conditions       `json:"conditions"`
+	Action     ActionDefinition `json:"action"`
+}
+
+type Conditions struct {
+	// Glob patterns matched against the path of the request.
+	// * matches any sequence of characters except '/', ** matches any sequence of characters and
+	// ? matches any single character except '/'.
+	Paths []string `json:"paths,omitempty"`
+	// HTTP methods, e.g. GET, POST
+	Methods []string `json:"methods,omitempty"`
+	// ISO 3166-1 alpha-2 country codes
+	Countries []string `json:"countries,omitempty"`
+	// Autonomous System Numbers
+	Asns []int64 `json:"asns,omitempty"`
+	// Case-insensitive glob patterns matched against the User-Agent header. * matches any sequence of characters.
+	UserAgents []string          `json:"user_agents,omitempty"`
+	Bots       []BotVerification `json:"bots,omitempty"`
+	// All the query conditions must match
+	Query []QueryCondition `json:"query,omitempty"`
+}
+
+type QueryCondition struct {
+	Name string `json:"name"`
+	// Glob pattern matched against the value of the query parameter. * matches any sequence of characters.
+	// If empty, the parameter only needs to be present.
+	Value string `json:"value"`
+}
+
+type ActionDefinition struct {
+	Type ActionType `json:"type"`
+
+	// set_header: headers set on the response
+	Headers []HttpHeader `json:"headers,omitempty"`
+
+	// redirect
+	RedirectTo     string `json:"redirect_to,omitempty"`
+	RedirectStatus int    `json:"redirect_status,omitempty"`
+
+	// rate_limit: maximum number of matching requests per IP address in a period
+	RateLimit *RateLimitDefinition `json:"rate_limit,omitempty"`
+}
+
+type RateLimitDefinition struct {
+	Requests      uint64 `json:"requests"`
+	PeriodSeconds int64  `json:"period_seconds"`
+}
+
+// IsTerminal returns true if the action ends the processing of the request when applied
+func (action ActionDefinition) IsTerminal() bool {
+	return action.Type != ActionTypeSetHeader
+}
+
+var (
+	httpMethods = []string{
+		http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+	}
+	redirectStatuses = []int{
+		http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect,
+	}
+	// https://www.rfc-editor.org/rfc/rfc9110#name-tokens
+	headerNameRegexp = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
+	// headers that could break responses if modified by rules
+	forbiddenHeaders = []string{
+		"connection", "content-length", "content-encoding", "content-type", "set-cookie", "transfer-encoding",
+		"trailer", "upgrade", "location",
+	}
+)
+
+// Clean normalizes the definition (trims spaces, uppercases methods and countries...) and validates it
+func (definition *Definition) Clean() (err error) {
+	conditions := &definition.Conditions
+
+	err = cleanStrings("paths", conditions.Paths, strings.TrimSpace, func(path string) error {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("path (%s) must start with /", path)
+		}
+		return validatePattern(path)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cleanStrings("methods", conditions.Methods, func(method string) string {
+		return strings.ToUpper(strings.TrimSpace(method))
+	}, func(method string) error {
+		if !slices.Contains(httpMethods, method) {
+			return fmt.Errorf("method (%s) is not valid", method)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cleanStrings("countries", conditions.Countries, func(country string) string {
+		return strings.ToUpper(strings.TrimSpace(country))
+	}, func(country string) error {
+		if len(country) != 2 || strings.Trim(country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return fmt.Errorf("country (%s) is not a valid ISO 3166-1 alpha-2 code", country)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(conditions.Asns) > MaxConditionValues {
+		return fmt.Errorf("too many asns (max: %d)", MaxConditionValues)
+	}
+	for _, asn := range conditions.Asns {
+		if asn <= 0 || asn > 4_294_967_295 {
+			return fmt.Errorf("asn (%d) is not valid", asn)
+		}
+	}
+
+	err = cleanStrings("user_agents", conditions.UserAgents, strings.TrimSpace, validatePattern)
+	if err != nil {
+		return err
+	}
+
+	if len(conditions.Bots) > 3 {
+		return errors.New("too many bots values")
+	}
+	for _, bot := range conditions.Bots {
+		if bot != BotVerificationVerified && bot != BotVerificationUnverified && bot != BotVerificationNone {
+			return fmt.Errorf("bot (%s) is not valid", bot)
+		}
+	}
+
+	if len(conditions.Query) > MaxConditionValues {
+		return fmt.Errorf("too many query conditions (max: %d)", MaxConditionValues)
+	}
+	for i := range conditions.Query {
+		conditions.Query[i].Name = strings.TrimSpace(conditions.Query[i].Name)
+		if conditions.Query[i].Name == "" || len(conditions.Query[i].Name) > MaxPatternLength {
+			return errors.New("query parameter name is not valid")
+		}
+		err = validatePattern(conditions.Query[i].Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return definition.Action.clean()
+}
+
+func (action *ActionDefinition) clean() (err error) {
+	switch action.Type {
+	case ActionTypeBlock, ActionTypeChallenge:
+	case ActionTypeRateLimit:
+		if action.RateLimit == nil {
+			return errors.New("rate_limit is required for rate_limit actions")
+		}
+		if action.RateLimit.Requests < 1 || action.RateLimit.Requests > MaxRateLimitRequests {
+			return fmt.Errorf("rate_limit.requests must be between 1 and %d", MaxRateLimitRequests)
+		}
+		if action.RateLimit.PeriodSeconds < 1 || action.RateLimit.PeriodSeconds > MaxRateLimitPeriod {
+			return fmt.Errorf("rate_limit.period_seconds must be between 1 and %d", MaxRateLimitPeriod)
+		}
+	case ActionTypeRedirect:
+		action.RedirectTo = strings.TrimSpace(action.RedirectTo)
+		if len(action.RedirectTo) > MaxRedirectLength {
+			return errors.New("redirect_to is too long")
+		}
+		if !strings.HasPrefix(action.RedirectTo, "/") || strings.HasPrefix(action.RedirectTo, "//") {
+			redirectUrl, err := url.Parse(action.RedirectTo)
+			if err != nil || (redirectUrl.Scheme != "https" && redirectUrl.Scheme != "http") || redirectUrl.Host == "" {
+				return errors.New("redirect_to must be a path or an http(s) URL")
+			}
+		}
+		if action.RedirectStatus == 0 {
+			action.RedirectStatus = http.StatusFound
+		}
+		if !slices.Contains(redirectStatuses, action.RedirectStatus) {
+			return fmt.Errorf("redirect_status (%d) is not valid", action.RedirectStatus)
+		}
+	case ActionTypeSetHeader:
+		if len(action.Headers) == 0 || len(action.Headers) > MaxHeaders {
+			return fmt.Errorf("set_header actions must have between 1 and %d headers", MaxHeaders)
+		}
+		for i := range action.Headers {
+			header := &action.Headers[i]
+			header.Name = strings.TrimSpace(header.Name)
+			header.Value = strings.TrimSpace(header.Value)
+			if !headerNameRegexp.MatchString(header.Name) || len(header.Name) > MaxHeaderLength {
+				return fmt.Errorf("header name (%s) is not valid", header.Name)
+			}
+			if slices.Contains(forbiddenHeaders, strings.ToLower(header.Name)) {
+				return fmt.Errorf("header (%s) can't be set", header.Name)
+			}
+			if len(header.Value) > MaxHeaderLength || strings.ContainsAny(header.Value, "\r\n\x00") {
+				return fmt.Errorf("value of header (%s) is not valid", header.Name)
+			}
+		}
+	default:
+		return fmt.Errorf("action type (%s) is not valid", action.Type)
+	}
+
+	// remove the parameters that are not used by the action
+	if action.Type != ActionTypeSetHeader {
+		action.Headers = nil
+	}
+	if action.Type != ActionTypeRedirect {
+		action.RedirectTo = ""
+		action.RedirectStatus = 0
+	}
+	if action.Type != ActionTypeRateLimit {
+		action.RateLimit = nil
+	}
+
+	return nil
+}
+
+func cleanStrings(name string, values []string, clean func(string) string, validate func(string) error) error {
+	if len(values) > MaxConditionValues {
+		return fmt.Errorf("too many %s (max: %d)", name, MaxConditionValues)
+	}
+	for i := range values {
+		values[i] = clean(values[i])
+		if values[i] == "" {
+			return fmt.Errorf("%s: empty values are not allowed", name)
+		}
+		err := validate(values[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validatePattern(pattern string) error {
+	if len(pattern) > MaxPatternLength {
+		return fmt.Errorf("pattern (%s) is too long (max: %d characters)", pattern, MaxPatternLength)
+	}
+	return nil
+}
@@ -15,8 +15,8 @@ type Action interface {
 }
 
 type HttpHeader struct {
-	Name  string
-	Value string
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
 type ActionSkipAuth struct{}
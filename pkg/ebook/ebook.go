@@ -0,0 +1,220 @@
+// Package ebook builds downloadable ebooks (EPUB and print-ready HTML) from rendered HTML chapters.
+package ebook
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// WatermarkPlaceholder is inserted at the end of each chapter so that ebooks can be generated and cached
+// once, and then personalized (e.g. with the email of the buyer) at download time.
+const WatermarkPlaceholder = "<!-- markdown-ninja:watermark -->"
+
+const (
+	MediaTypeEpub = "application/epub+zip"
+	MediaTypeHtml = "text/html; charset=utf-8"
+)
+
+type Book struct {
+	// ID is a unique and stable identifier for the book (e.g. the product's ID)
+	ID          string
+	Title       string
+	Description string
+	Author      string
+	// Language is a BCP 47 language tag. Defaults to "en"
+	Language   string
+	ModifiedAt time.Time
+	Chapters   []Chapter
+	Images     []Image
+}
+
+type Chapter struct {
+	Title string
+	// Html is the rendered HTML of the chapter
+	Html string
+}
+
+// Image is an image that may be referenced by the chapters. Only the images that are actually used
+// are opened and embedded in the ebook.
+type Image struct {
+	// Urls are the values of the src attributes of <img> elements that refer to this image
+	Urls      []string
+	Filename  string
+	MediaType string
+	Open      func() (io.ReadCloser, error)
+}
+
+// Ebook is a compiled book, ready to be written in any of the supported formats.
+type Ebook struct {
+	book     Book
+	chapters []compiledChapter
+	images   []compiledImage
+}
+
+type compiledChapter struct {
+	title string
+	// xhtml is the body of the chapter, with images pointing to the local files
+	xhtml string
+}
+
+type compiledImage struct {
+	filename  string
+	mediaType string
+	data      []byte
+}
+
+// Build parses and cleans the chapters of the book, and loads the images that they use.
+func Build(book Book) (ebook *Ebook, err error) {
+	if strings.TrimSpace(book.Language) == "" {
+		book.Language = "en"
+	}
+
+	ebook = &Ebook{
+		book:     book,
+		chapters: make([]compiledChapter, 0, len(book.Chapters)),
+		images:   make([]compiledImage, 0),
+	}
+
+	imagesByUrl := make(map[string]*Image, len(book.Images))
+	for i := range book.Images {
+		for _, url := range book.Images[i].Urls {
+			imagesByUrl[url] = &book.Images[i]
+		}
+	}
+	usedImages := make(map[string]bool, len(book.Images))
+
+	for _, chapter := range book.Chapters {
+		var chapterXhtml string
+		var chapterImages []*Image
+
+		chapterXhtml, chapterImages, err = cleanChapterHtml(chapter.Html, imagesByUrl)
+		if err != nil {
+			return nil, fmt.Errorf("ebook: parsing chapter [%s]: %w", chapter.Title, err)
+		}
+
+		for _, image := range chapterImages {
+			if usedImages[image.Filename] {
+				continue
+			}
+			usedImages[image.Filename] = true
+
+			var imageData []byte
+			imageData, err = readImage(image)
+			if err != nil {
+				return nil, fmt.Errorf("ebook: reading image [%s]: %w", image.Filename, err)
+			}
+			ebook.images = append(ebook.images, compiledImage{
+				filename:  image.Filename,
+				mediaType: image.MediaType,
+				data:      imageData,
+			})
+		}
+
+		ebook.chapters = append(ebook.chapters, compiledChapter{
+			title: chapter.Title,
+			xhtml: chapterXhtml,
+		})
+	}
+
+	return ebook, nil
+}
+
+func readImage(image *Image) (data []byte, err error) {
+	reader, err := image.Open()
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// elements that are not supported by ebook readers and that may break the XHTML documents
+var removedElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"noscript": true,
+	"object":   true,
+	"embed":    true,
+	"form":     true,
+}
+
+// cleanChapterHtml converts the HTML of a chapter to a well-formed XHTML fragment and rewrite the src
+// attributes of images to point to the local files.
+func cleanChapterHtml(input string, imagesByUrl map[string]*Image) (output string, usedImages []*Image, err error) {
+	usedImages = make([]*Image, 0)
+
+	document, err := html.Parse(strings.NewReader(input))
+	if err != nil {
+		return
+	}
+
+	var cleanNode func(node *html.Node)
+	cleanNode = func(node *html.Node) {
+		child := node.FirstChild
+		for child != nil {
+			next := child.NextSibling
+			if child.Type == html.CommentNode ||
+				(child.Type == html.ElementNode && removedElements[child.Data]) {
+				node.RemoveChild(child)
+				child = next
+				continue
+			}
+
+			if child.Type == html.ElementNode && child.Data == "img" {
+				for i := range child.Attr {
+					if child.Attr[i].Key != "src" {
+						continue
+					}
+					if image, exists := imagesByUrl[child.Attr[i].Val]; exists {
+						child.Attr[i].Val = path.Join("images", image.Filename)
+						usedImages = append(usedImages, image)
+					}
+				}
+			}
+
+			cleanNode(child)
+			child = next
+		}
+	}
+	cleanNode(document)
+
+	// the HTML parser wraps the input in <html><head></head><body> tags, so we only render the
+	// children of the body
+	var body *html.Node
+	var findBody func(node *html.Node)
+	findBody = func(node *html.Node) {
+		if node.Type == html.ElementNode && node.Data == "body" {
+			body = node
+			return
+		}
+		for child := node.FirstChild; child != nil && body == nil; child = child.NextSibling {
+			findBody(child)
+		}
+	}
+	findBody(document)
+	if body == nil {
+		return "", usedImages, nil
+	}
+
+	buffer := bytes.NewBuffer(make([]byte, 0, len(input)))
+	for child := body.FirstChild; child != nil; child = child.NextSibling {
+		err = html.Render(buffer, child)
+		if err != nil {
+			return
+		}
+	}
+
+	return buffer.String(), usedImages, nil
+}
+
+func escape(input string) string {
+	return html.EscapeString(input)
+}
@@ -0,0 +1,138 @@
+package ebook
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testBook() Book {
+	return Book{
+		ID:         "0192b4f2-0c8b-7a3f-9d3a-5a5c2b1e0a11",
+		Title:      "Hello <World>",
+		Author:     "Markdown Ninja",
+		ModifiedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Chapters: []Chapter{
+			{
+				Title: "Chapter 1",
+				Html:  `<p>Hello<br>World</p><img src="https://example.com/assets?id=1"><script>alert(1)</script>`,
+			},
+			{
+				Title: "Chapter 2",
+				Html:  `<p>Remote image <img src="https://other.com/image.png"></p>`,
+			},
+		},
+		Images: []Image{
+			{
+				Urls:      []string{"https://example.com/assets?id=1", "/assets?id=1"},
+				Filename:  "1.png",
+				MediaType: "image/png",
+				Open: func() (io.ReadCloser, error) {
+					return io.NopCloser(strings.NewReader("png")), nil
+				},
+			},
+			{
+				Urls:      []string{"https://example.com/assets?id=2"},
+				Filename:  "2.png",
+				MediaType: "image/png",
+				Open: func() (io.ReadCloser, error) {
+					panic("unused images should not be opened")
+				},
+			},
+		},
+	}
+}
+
+func TestCleanChapterHtml(t *testing.T) {
+	book := testBook()
+	ebook, err := Build(book)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<p>Hello<br/>World</p><img src="images/1.png"/>`
+	if ebook.chapters[0].xhtml != expected {
+		t.Errorf("chapter html (%s) != expected (%s)", ebook.chapters[0].xhtml, expected)
+	}
+
+	if len(ebook.images) != 1 {
+		t.Errorf("expected 1 image, got %d", len(ebook.images))
+	}
+}
+
+func TestWriteAndWatermarkEpub(t *testing.T) {
+	ebook, err := Build(testBook())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var epub bytes.Buffer
+	err = ebook.WriteEpub(&epub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var watermarked bytes.Buffer
+	err = WatermarkEpub(&watermarked, bytes.NewReader(epub.Bytes()), int64(epub.Len()), "buyer@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zipReader, err := zip.NewReader(bytes.NewReader(watermarked.Bytes()), int64(watermarked.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if zipReader.File[0].Name != "mimetype" || zipReader.File[0].Method != zip.Store {
+		t.Errorf("mimetype should be the first file of the archive and should not be compressed")
+	}
+
+	chapterFound := false
+	for _, file := range zipReader.File {
+		if file.Name != "OEBPS/chapter-001.xhtml" {
+			continue
+		}
+		chapterFound = true
+
+		data, err := readZipFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(data, []byte(WatermarkPlaceholder)) {
+			t.Errorf("watermark placeholder has not been replaced")
+		}
+		if !bytes.Contains(data, []byte("buyer@example.com")) {
+			t.Errorf("watermark not found in chapter")
+		}
+	}
+	if !chapterFound {
+		t.Errorf("chapter not found in EPUB")
+	}
+}
+
+func TestWriteHtml(t *testing.T) {
+	ebook, err := Build(testBook())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var document bytes.Buffer
+	err = ebook.WriteHtml(&document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := document.String()
+	if !strings.Contains(output, `src="data:image/png;base64,cG5n"`) {
+		t.Errorf("image has not been embedded")
+	}
+	if !strings.Contains(output, "<title>Hello &lt;World&gt;</title>") {
+		t.Errorf("title has not been escaped")
+	}
+	if !strings.Contains(output, `src="https://other.com/image.png"`) {
+		t.Errorf("remote images should not be modified")
+	}
+}
@@ -0,0 +1,179 @@
+package ebook
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+const epubContainerXml = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const epubStylesheet = `body { font-family: serif; line-height: 1.5; }
+h1, h2, h3, h4, h5, h6 { font-family: sans-serif; line-height: 1.2; }
+img { max-width: 100%; height: auto; }
+pre { white-space: pre-wrap; font-size: 0.85em; }
+.mdninja-watermark { margin-top: 2em; font-size: 0.75em; text-align: center; color: #777777; }
+`
+
+func epubChapterFilename(index int) string {
+	return fmt.Sprintf("chapter-%03d.xhtml", index+1)
+}
+
+// WriteEpub writes the book as an EPUB 3 file.
+func (ebook *Ebook) WriteEpub(output io.Writer) (err error) {
+	zipWriter := zip.NewWriter(output)
+
+	// the mimetype file must be the first file of the archive and must not be compressed
+	mimetypeFile, err := zipWriter.CreateHeader(&zip.FileHeader{
+		Name:   "mimetype",
+		Method: zip.Store,
+	})
+	if err != nil {
+		return
+	}
+	_, err = io.WriteString(mimetypeFile, MediaTypeEpub)
+	if err != nil {
+		return
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{name: "META-INF/container.xml", content: epubContainerXml},
+		{name: "OEBPS/content.opf", content: ebook.epubPackageDocument()},
+		{name: "OEBPS/nav.xhtml", content: ebook.epubNavigationDocument()},
+		{name: "OEBPS/styles.css", content: epubStylesheet},
+	}
+	for index, chapter := range ebook.chapters {
+		files = append(files, struct {
+			name    string
+			content string
+		}{
+			name:    path.Join("OEBPS", epubChapterFilename(index)),
+			content: ebook.epubChapterDocument(chapter),
+		})
+	}
+
+	for _, file := range files {
+		err = writeZipFile(zipWriter, file.name, []byte(file.content))
+		if err != nil {
+			return
+		}
+	}
+
+	for _, image := range ebook.images {
+		err = writeZipFile(zipWriter, path.Join("OEBPS", "images", image.filename), image.data)
+		if err != nil {
+			return
+		}
+	}
+
+	return zipWriter.Close()
+}
+
+func writeZipFile(zipWriter *zip.Writer, name string, data []byte) (err error) {
+	fileWriter, err := zipWriter.CreateHeader(&zip.FileHeader{
+		Name:   name,
+		Method: zip.Deflate,
+	})
+	if err != nil {
+		return fmt.Errorf("ebook: creating file [%s]: %w", name, err)
+	}
+
+	_, err = fileWriter.Write(data)
+	if err != nil {
+		return fmt.Errorf("ebook: writing file [%s]: %w", name, err)
+	}
+
+	return nil
+}
+
+func (ebook *Ebook) epubPackageDocument() string {
+	var opf strings.Builder
+
+	opf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+`)
+	fmt.Fprintf(&opf, "    <dc:identifier id=\"book-id\">urn:uuid:%s</dc:identifier>\n", escape(ebook.book.ID))
+	fmt.Fprintf(&opf, "    <dc:title>%s</dc:title>\n", escape(ebook.book.Title))
+	fmt.Fprintf(&opf, "    <dc:language>%s</dc:language>\n", escape(ebook.book.Language))
+	if ebook.book.Author != "" {
+		fmt.Fprintf(&opf, "    <dc:creator>%s</dc:creator>\n", escape(ebook.book.Author))
+	}
+	if ebook.book.Description != "" {
+		fmt.Fprintf(&opf, "    <dc:description>%s</dc:description>\n", escape(ebook.book.Description))
+	}
+	fmt.Fprintf(&opf, "    <meta property=\"dcterms:modified\">%s</meta>\n",
+		ebook.book.ModifiedAt.UTC().Truncate(time.Second).Format(time.RFC3339))
+	opf.WriteString("  </metadata>\n  <manifest>\n")
+
+	opf.WriteString(`    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="styles" href="styles.css" media-type="text/css"/>
+`)
+	for index := range ebook.chapters {
+		fmt.Fprintf(&opf, "    <item id=\"chapter-%d\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n",
+			index+1, epubChapterFilename(index))
+	}
+	for index, image := range ebook.images {
+		fmt.Fprintf(&opf, "    <item id=\"image-%d\" href=\"images/%s\" media-type=\"%s\"/>\n",
+			index+1, escape(image.filename), escape(image.mediaType))
+	}
+	opf.WriteString("  </manifest>\n  <spine>\n")
+
+	for index := range ebook.chapters {
+		fmt.Fprintf(&opf, "    <itemref idref=\"chapter-%d\"/>\n", index+1)
+	}
+	opf.WriteString("  </spine>\n</package>\n")
+
+	return opf.String()
+}
+
+func (ebook *Ebook) epubNavigationDocument() string {
+	var nav strings.Builder
+
+	ebook.writeXhtmlHeader(&nav, ebook.book.Title)
+	nav.WriteString(`  <nav epub:type="toc" id="toc">
+    <h1>Table of Contents</h1>
+    <ol>
+`)
+	for index, chapter := range ebook.chapters {
+		fmt.Fprintf(&nav, "      <li><a href=\"%s\">%s</a></li>\n", epubChapterFilename(index), escape(chapter.title))
+	}
+	nav.WriteString("    </ol>\n  </nav>\n</body>\n</html>\n")
+
+	return nav.String()
+}
+
+func (ebook *Ebook) epubChapterDocument(chapter compiledChapter) string {
+	var document strings.Builder
+
+	ebook.writeXhtmlHeader(&document, chapter.title)
+	fmt.Fprintf(&document, "<section epub:type=\"chapter\">\n<h1>%s</h1>\n", escape(chapter.title))
+	document.WriteString(chapter.xhtml)
+	document.WriteString("\n</section>\n")
+	document.WriteString(WatermarkPlaceholder)
+	document.WriteString("\n</body>\n</html>\n")
+
+	return document.String()
+}
+
+func (ebook *Ebook) writeXhtmlHeader(output *strings.Builder, title string) {
+	output.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+`)
+	fmt.Fprintf(output, "<html xmlns=\"http://www.w3.org/1999/xhtml\" xmlns:epub=\"http://www.idpf.org/2007/ops\" lang=\"%s\" xml:lang=\"%s\">\n",
+		escape(ebook.book.Language), escape(ebook.book.Language))
+	fmt.Fprintf(output, "<head>\n  <meta charset=\"utf-8\"/>\n  <title>%s</title>\n", escape(title))
+	output.WriteString("  <link rel=\"stylesheet\" type=\"text/css\" href=\"styles.css\"/>\n</head>\n<body>\n")
+}
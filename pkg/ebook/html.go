@@ -0,0 +1,73 @@
+package ebook
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// the HTML output is designed to be printed to PDF from any browser: each chapter starts on a new page
+const printStylesheet = `@page { size: A4; margin: 2cm; }
+body { font-family: serif; line-height: 1.5; max-width: 46em; margin: 0 auto; padding: 1em; }
+h1, h2, h3, h4, h5, h6 { font-family: sans-serif; line-height: 1.2; page-break-after: avoid; break-after: avoid; }
+img { max-width: 100%; height: auto; page-break-inside: avoid; break-inside: avoid; }
+pre { white-space: pre-wrap; font-size: 0.85em; page-break-inside: avoid; break-inside: avoid; }
+.mdninja-title-page { text-align: center; padding-top: 30vh; }
+.mdninja-chapter, .mdninja-toc { page-break-before: always; break-before: page; }
+.mdninja-watermark { margin-top: 2em; font-size: 0.75em; text-align: center; color: #777777; }
+@media print { body { max-width: none; padding: 0; } }
+`
+
+// WriteHtml writes the book as a single, self-contained and print-ready HTML document.
+// Images are embedded as data URIs.
+func (ebook *Ebook) WriteHtml(output io.Writer) (err error) {
+	var document strings.Builder
+
+	imagesDataUris := make(map[string]string, len(ebook.images))
+	for _, image := range ebook.images {
+		imagesDataUris[path.Join("images", image.filename)] = "data:" + image.mediaType + ";base64," +
+			base64.StdEncoding.EncodeToString(image.data)
+	}
+
+	document.WriteString("<!DOCTYPE html>\n")
+	fmt.Fprintf(&document, "<html lang=\"%s\">\n<head>\n<meta charset=\"utf-8\">\n", escape(ebook.book.Language))
+	fmt.Fprintf(&document, "<title>%s</title>\n", escape(ebook.book.Title))
+	fmt.Fprintf(&document, "<style>\n%s</style>\n</head>\n<body>\n", printStylesheet)
+
+	document.WriteString("<header class=\"mdninja-title-page\">\n")
+	fmt.Fprintf(&document, "<h1>%s</h1>\n", escape(ebook.book.Title))
+	if ebook.book.Description != "" {
+		fmt.Fprintf(&document, "<p>%s</p>\n", escape(ebook.book.Description))
+	}
+	if ebook.book.Author != "" {
+		fmt.Fprintf(&document, "<p>%s</p>\n", escape(ebook.book.Author))
+	}
+	document.WriteString("</header>\n")
+
+	document.WriteString("<nav class=\"mdninja-toc\">\n<h2>Table of Contents</h2>\n<ol>\n")
+	for index, chapter := range ebook.chapters {
+		fmt.Fprintf(&document, "<li><a href=\"#chapter-%d\">%s</a></li>\n", index+1, escape(chapter.title))
+	}
+	document.WriteString("</ol>\n</nav>\n")
+
+	for index, chapter := range ebook.chapters {
+		chapterHtml := chapter.xhtml
+		for localPath, dataUri := range imagesDataUris {
+			chapterHtml = strings.ReplaceAll(chapterHtml, `src="`+localPath+`"`, `src="`+dataUri+`"`)
+		}
+
+		fmt.Fprintf(&document, "<section class=\"mdninja-chapter\" id=\"chapter-%d\">\n", index+1)
+		fmt.Fprintf(&document, "<h1>%s</h1>\n", escape(chapter.title))
+		document.WriteString(chapterHtml)
+		document.WriteString("\n")
+		document.WriteString(WatermarkPlaceholder)
+		document.WriteString("\n</section>\n")
+	}
+
+	document.WriteString("</body>\n</html>\n")
+
+	_, err = io.WriteString(output, document.String())
+	return err
+}
@@ -0,0 +1,64 @@
+package ebook
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+)
+
+func watermarkHtml(text string) []byte {
+	return []byte(`<p class="mdninja-watermark">` + escape(text) + `</p>`)
+}
+
+// WatermarkHtml replaces the watermark placeholders of an HTML ebook with the given text.
+func WatermarkHtml(output io.Writer, input []byte, text string) (err error) {
+	_, err = output.Write(bytes.ReplaceAll(input, []byte(WatermarkPlaceholder), watermarkHtml(text)))
+	return
+}
+
+// WatermarkEpub replaces the watermark placeholders of the chapters of an EPUB ebook with the given text.
+// Other files are copied without being recompressed.
+func WatermarkEpub(output io.Writer, input io.ReaderAt, size int64, text string) (err error) {
+	zipReader, err := zip.NewReader(input, size)
+	if err != nil {
+		return
+	}
+
+	zipWriter := zip.NewWriter(output)
+	watermark := watermarkHtml(text)
+
+	for _, file := range zipReader.File {
+		if !strings.HasSuffix(file.Name, ".xhtml") {
+			// Copy preserves the order of the files and the Store method of the mimetype file
+			err = zipWriter.Copy(file)
+			if err != nil {
+				return
+			}
+			continue
+		}
+
+		var fileData []byte
+		fileData, err = readZipFile(file)
+		if err != nil {
+			return
+		}
+
+		err = writeZipFile(zipWriter, file.Name, bytes.ReplaceAll(fileData, []byte(WatermarkPlaceholder), watermark))
+		if err != nil {
+			return
+		}
+	}
+
+	return zipWriter.Close()
+}
+
+func readZipFile(file *zip.File) (data []byte, err error) {
+	reader, err := file.Open()
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,157 @@
+package emailfeedback
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// ParseReport parses a delivery status notification (RFC 3464) or a feedback report (RFC 5965) as
+// received by the mailbox of the sender of an email. Messages that are not reports, and DSNs that
+// only report delays or successful deliveries, return no feedback.
+func ParseReport(rawMessage []byte) (feedback []Feedback, err error) {
+	feedback = []Feedback{}
+
+	message, err := mail.ReadMessage(bytes.NewReader(rawMessage))
+	if err != nil {
+		return nil, fmt.Errorf("emailfeedback: parsing message: %w", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" {
+		return feedback, nil
+	}
+	reportType := strings.ToLower(params["report-type"])
+	if reportType != "delivery-status" && reportType != "feedback-report" {
+		return feedback, nil
+	}
+
+	var reportFields []textproto.MIMEHeader
+	originalHeaders := textproto.MIMEHeader{}
+	multipartReader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, partErr := multipartReader.NextPart()
+		if partErr == io.EOF {
+			break
+		} else if partErr != nil {
+			return nil, fmt.Errorf("emailfeedback: reading report: %w", partErr)
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/delivery-status", "message/global-delivery-status", "message/feedback-report":
+			reportFields = readFieldGroups(part)
+		case "text/rfc822-headers", "message/rfc822", "message/global", "message/global-headers":
+			// the body of the original message, if any, is ignored
+			originalHeaders, _ = textproto.NewReader(bufio.NewReader(part)).ReadMIMEHeader()
+		}
+	}
+	if len(reportFields) == 0 {
+		return nil, errors.New("emailfeedback: report has no machine-readable part")
+	}
+
+	websiteID, newsletterID := parseIDsFromHeaders(originalHeaders.Get)
+	reportID := strings.Trim(strings.TrimSpace(message.Header.Get("Message-Id")), "<>")
+
+	if reportType == "feedback-report" {
+		fields := reportFields[0]
+		// other feedback types (e.g. not-spam, virus) don't mean that the recipient doesn't want to
+		// receive the emails
+		feedbackType := strings.ToLower(strings.TrimSpace(fields.Get("Feedback-Type")))
+		if feedbackType != "abuse" && feedbackType != "fraud" {
+			return feedback, nil
+		}
+
+		recipient := fields.Get("Original-Rcpt-To")
+		if recipient == "" {
+			recipient = originalHeaders.Get("To")
+		}
+		email := normalizeEmail(recipient)
+		if email == "" {
+			return nil, errors.New("emailfeedback: feedback report has no recipient")
+		}
+
+		feedback = append(feedback, Feedback{
+			ID:           reportID,
+			Source:       SourceDsn,
+			Type:         TypeComplaint,
+			BounceType:   "",
+			Email:        email,
+			Diagnostic:   feedbackType,
+			WebsiteID:    websiteID,
+			NewsletterID: newsletterID,
+		})
+		return feedback, nil
+	}
+
+	// the first group contains the per-message fields, and the following ones the per-recipient fields
+	for _, fields := range reportFields[1:] {
+		if !strings.EqualFold(strings.TrimSpace(fields.Get("Action")), "failed") {
+			continue
+		}
+
+		recipient := fields.Get("Final-Recipient")
+		if recipient == "" {
+			recipient = fields.Get("Original-Recipient")
+		}
+		// the address is prefixed with its type. e.g. rfc822; name@example.com
+		if _, address, found := strings.Cut(recipient, ";"); found {
+			recipient = address
+		}
+		email := normalizeEmail(recipient)
+		if email == "" {
+			continue
+		}
+
+		status := strings.TrimSpace(fields.Get("Status"))
+		bounceType := BounceTypeTransient
+		if strings.HasPrefix(status, "5") {
+			bounceType = BounceTypePermanent
+		}
+
+		diagnostic := fields.Get("Diagnostic-Code")
+		if _, diagnosticText, found := strings.Cut(diagnostic, ";"); found {
+			diagnostic = diagnosticText
+		}
+		diagnostic = strings.TrimSpace(diagnostic)
+		if diagnostic == "" {
+			diagnostic = status
+		}
+
+		feedback = append(feedback, Feedback{
+			ID:           reportID,
+			Source:       SourceDsn,
+			Type:         TypeBounce,
+			BounceType:   bounceType,
+			Email:        email,
+			Diagnostic:   diagnostic,
+			WebsiteID:    websiteID,
+			NewsletterID: newsletterID,
+		})
+	}
+
+	return feedback, nil
+}
+
+// readFieldGroups reads the groups of header-like fields, separated by blank lines, of the
+// machine-readable part of a report.
+func readFieldGroups(reader io.Reader) []textproto.MIMEHeader {
+	groups := make([]textproto.MIMEHeader, 0, 2)
+	textReader := textproto.NewReader(bufio.NewReader(reader))
+	for {
+		fields, err := textReader.ReadMIMEHeader()
+		if len(fields) != 0 {
+			groups = append(groups, fields)
+		}
+		if err != nil {
+			return groups
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Package emailfeedback parses the bounces and complaints reported by email providers: SES
+// notifications delivered by SNS, a generic signed JSON format, and the delivery status notifications
+// (RFC 3464) and feedback reports (RFC 5965) received by the mailboxes of SMTP senders.
+package emailfeedback
+
+import (
+	"net/mail"
+	"strings"
+
+	"github.com/skerkour/stdx-go/guid"
+)
+
+// Headers added to the emails sent for the websites so that bounces and complaints can be attributed
+// to a website and a newsletter. Providers include them in their notifications (SES when the
+// "include original headers" option is enabled) and DSNs contain the headers of the original message.
+const (
+	HeaderWebsiteID    = "X-Mdninja-Website-Id"
+	HeaderNewsletterID = "X-Mdninja-Newsletter-Id"
+)
+
+type Type string
+
+const (
+	TypeBounce    Type = "bounce"
+	TypeComplaint Type = "complaint"
+)
+
+type BounceType string
+
+const (
+	// The address doesn't exist or will never accept emails
+	BounceTypePermanent BounceType = "permanent"
+	// e.g. the mailbox is full or the server is temporarily unavailable
+	BounceTypeTransient BounceType = "transient"
+)
+
+type Source string
+
+const (
+	SourceSes     Source = "ses"
+	SourceDsn     Source = "dsn"
+	SourceGeneric Source = "generic"
+)
+
+// Feedback is a bounce or a complaint for a single recipient
+type Feedback struct {
+	// ID is the identifier of the notification given by the provider, used to ignore duplicates.
+	// May be empty.
+	ID     string
+	Source Source
+	Type   Type
+	// BounceType is empty for complaints
+	BounceType BounceType
+	Email      string
+	Diagnostic string
+
+	WebsiteID    *guid.GUID
+	NewsletterID *guid.GUID
+}
+
+// parseIDsFromHeaders returns the IDs of the website and the newsletter found in the headers of the
+// original message, if any.
+func parseIDsFromHeaders(getHeader func(key string) string) (websiteID, newsletterID *guid.GUID) {
+	if id, err := guid.Parse(strings.TrimSpace(getHeader(HeaderWebsiteID))); err == nil {
+		websiteID = &id
+	}
+	if id, err := guid.Parse(strings.TrimSpace(getHeader(HeaderNewsletterID))); err == nil {
+		newsletterID = &id
+	}
+	return
+}
+
+// normalizeEmail returns the address of a recipient, which may be formatted as an RFC 5322 address
+// (e.g. "Name <name@example.com>"), in lowercase.
+func normalizeEmail(email string) string {
+	email = strings.TrimSpace(email)
+	if address, err := mail.ParseAddress(email); err == nil {
+		email = address.Address
+	}
+	return strings.ToLower(email)
+}
@@ -0,0 +1,211 @@
+package emailfeedback
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testWebsiteID = "06gn5x3k9hvuz4he6azq9h9k1m"
+const testNewsletterID = "06gn5x3k9hw0hf0pk2y7f3gtz0"
+
+func TestParseReportDsn(t *testing.T) {
+	dsn := strings.ReplaceAll(`From: Mail Delivery System <MAILER-DAEMON@mx.example.com>
+To: newsletter@example.com
+Subject: Undelivered Mail Returned to Sender
+Message-Id: <20260101.ABCDEF@mx.example.com>
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=delivery-status; boundary="BOUNDARY"
+
+--BOUNDARY
+Content-Type: text/plain
+
+This is the mail system. Your message could not be delivered.
+
+--BOUNDARY
+Content-Type: message/delivery-status
+
+Reporting-MTA: dns; mx.example.com
+
+Final-Recipient: rfc822; Unknown@Example.org
+Action: failed
+Status: 5.1.1
+Diagnostic-Code: smtp; 550 5.1.1 <unknown@example.org>: Recipient address rejected
+
+Final-Recipient: rfc822; full@example.org
+Action: failed
+Status: 4.2.2
+Diagnostic-Code: smtp; 452 4.2.2 Mailbox full
+
+Final-Recipient: rfc822; slow@example.org
+Action: delayed
+Status: 4.4.1
+
+--BOUNDARY
+Content-Type: text/rfc822-headers
+
+From: newsletter@example.com
+To: unknown@example.org
+Subject: Hello
+X-Mdninja-Website-Id: `+testWebsiteID+`
+X-Mdninja-Newsletter-Id: `+testNewsletterID+`
+
+--BOUNDARY--
+`, "\n", "\r\n")
+
+	feedback, err := ParseReport([]byte(dsn))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(feedback) != 2 {
+		t.Fatalf("expected 2 bounces, got %d: %+v", len(feedback), feedback)
+	}
+
+	if feedback[0].Email != "unknown@example.org" || feedback[0].Type != TypeBounce ||
+		feedback[0].BounceType != BounceTypePermanent {
+		t.Errorf("first bounce is not valid: %+v", feedback[0])
+	}
+	if !strings.HasPrefix(feedback[0].Diagnostic, "550 5.1.1") {
+		t.Errorf("diagnostic is not valid: %s", feedback[0].Diagnostic)
+	}
+	if feedback[1].Email != "full@example.org" || feedback[1].BounceType != BounceTypeTransient {
+		t.Errorf("second bounce is not valid: %+v", feedback[1])
+	}
+
+	for _, recipientFeedback := range feedback {
+		if recipientFeedback.ID != "20260101.ABCDEF@mx.example.com" {
+			t.Errorf("ID is not valid: %s", recipientFeedback.ID)
+		}
+		if recipientFeedback.WebsiteID == nil || recipientFeedback.WebsiteID.String() != testWebsiteID {
+			t.Errorf("WebsiteID is not valid: %v", recipientFeedback.WebsiteID)
+		}
+		if recipientFeedback.NewsletterID == nil || recipientFeedback.NewsletterID.String() != testNewsletterID {
+			t.Errorf("NewsletterID is not valid: %v", recipientFeedback.NewsletterID)
+		}
+	}
+}
+
+func TestParseReportArf(t *testing.T) {
+	arf := strings.ReplaceAll(`From: abuse@isp.example
+To: newsletter@example.com
+Subject: FW: Hello
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=feedback-report; boundary="BOUNDARY"
+
+--BOUNDARY
+Content-Type: text/plain
+
+This is an email abuse report.
+
+--BOUNDARY
+Content-Type: message/feedback-report
+
+Feedback-Type: abuse
+User-Agent: SomeGenerator/1.0
+Version: 1
+
+--BOUNDARY
+Content-Type: message/rfc822
+
+From: newsletter@example.com
+To: Someone <Someone@isp.example>
+Subject: Hello
+X-Mdninja-Website-Id: `+testWebsiteID+`
+
+Hello
+--BOUNDARY--
+`, "\n", "\r\n")
+
+	feedback, err := ParseReport([]byte(arf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(feedback) != 1 {
+		t.Fatalf("expected 1 complaint, got %d", len(feedback))
+	}
+	if feedback[0].Type != TypeComplaint || feedback[0].Email != "someone@isp.example" ||
+		feedback[0].WebsiteID == nil || feedback[0].NewsletterID != nil {
+		t.Errorf("complaint is not valid: %+v", feedback[0])
+	}
+}
+
+func TestParseReportNotAReport(t *testing.T) {
+	message := "From: someone@example.org\r\nTo: newsletter@example.com\r\nSubject: Re: Hello\r\n\r\nThanks!\r\n"
+
+	feedback, err := ParseReport([]byte(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feedback) != 0 {
+		t.Errorf("expected no feedback, got %+v", feedback)
+	}
+}
+
+func TestParseSesNotification(t *testing.T) {
+	notification := `{
+		"notificationType": "Bounce",
+		"bounce": {
+			"feedbackId": "feedback-1",
+			"bounceType": "Permanent",
+			"bounceSubType": "General",
+			"bouncedRecipients": [{"emailAddress": "Unknown@example.org", "diagnosticCode": "smtp; 550 user unknown"}]
+		},
+		"mail": {
+			"headers": [{"name": "X-Mdninja-Newsletter-Id", "value": "` + testNewsletterID + `"}]
+		}
+	}`
+
+	feedback, err := ParseSesNotification(notification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feedback) != 1 {
+		t.Fatalf("expected 1 bounce, got %d", len(feedback))
+	}
+	if feedback[0].Email != "unknown@example.org" || feedback[0].BounceType != BounceTypePermanent ||
+		feedback[0].ID != "feedback-1" || feedback[0].NewsletterID == nil || feedback[0].WebsiteID != nil {
+		t.Errorf("bounce is not valid: %+v", feedback[0])
+	}
+
+	delivery := `{"notificationType": "Delivery", "mail": {}}`
+	feedback, err = ParseSesNotification(delivery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feedback) != 0 {
+		t.Errorf("deliveries should be ignored, got %+v", feedback)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("a secret that is long enough for the tests")
+	payload := []byte(`{"type": "complaint", "email": "someone@example.org"}`)
+	now := time.Now()
+
+	header := Sign(secret, payload, now)
+	if err := VerifySignature(secret, header, payload, now); err != nil {
+		t.Errorf("valid signature is rejected: %s", err)
+	}
+
+	if err := VerifySignature(secret, header, []byte(`{"type": "bounce"}`), now); err == nil {
+		t.Error("signature of another payload is accepted")
+	}
+
+	if err := VerifySignature([]byte("another secret"), header, payload, now); err == nil {
+		t.Error("signature with another secret is accepted")
+	}
+
+	if err := VerifySignature(secret, header, payload, now.Add(SignatureTolerance+time.Minute)); err == nil {
+		t.Error("expired signature is accepted")
+	}
+
+	feedback, err := ParseGeneric(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feedback.Type != TypeComplaint || feedback.Email != "someone@example.org" || feedback.Source != SourceGeneric {
+		t.Errorf("feedback is not valid: %+v", feedback)
+	}
+}
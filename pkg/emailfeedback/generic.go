@@ -0,0 +1,130 @@
+package emailfeedback
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+)
+
+// SignatureHeader is the header containing the signature of the generic JSON and DSN payloads, in the
+// format: t=<unix timestamp>,v1=<hex encoded HMAC-SHA256 of "<timestamp>.<payload>">
+const SignatureHeader = "X-Mdninja-Signature"
+
+// SignatureTolerance is the maximum age of a signature, to limit replay attacks
+const SignatureTolerance = 5 * time.Minute
+
+var ErrSignatureIsNotValid = errors.New("emailfeedback: signature is not valid")
+
+// GenericFeedback is the generic JSON format that can be used to report bounces and complaints from
+// any email provider.
+type GenericFeedback struct {
+	// Optional, used to ignore duplicates
+	ID   string `json:"id"`
+	Type Type   `json:"type"`
+	// Required for bounces: permanent | transient
+	BounceType BounceType `json:"bounce_type"`
+	Email      string     `json:"email"`
+	Diagnostic string     `json:"diagnostic"`
+	// Optional: the values of the X-Mdninja-Website-Id and X-Mdninja-Newsletter-Id headers of the email
+	WebsiteID    *guid.GUID `json:"website_id"`
+	NewsletterID *guid.GUID `json:"newsletter_id"`
+}
+
+// Sign returns the value of the SignatureHeader for the given payload
+func Sign(secret []byte, payload []byte, now time.Time) string {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(computeSignature(secret, timestamp, payload))
+}
+
+// VerifySignature verifies the value of the SignatureHeader of a payload
+func VerifySignature(secret []byte, header string, payload []byte, now time.Time) error {
+	var timestamp string
+	signatures := make([][]byte, 0, 1)
+	for part := range strings.SplitSeq(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature, err := hex.DecodeString(value)
+			if err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+
+	unixTimestamp, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrSignatureIsNotValid
+	}
+
+	signedAt := time.Unix(unixTimestamp, 0)
+	if now.Sub(signedAt) > SignatureTolerance || signedAt.Sub(now) > SignatureTolerance {
+		return ErrSignatureIsNotValid
+	}
+
+	expectedSignature := computeSignature(secret, timestamp, payload)
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expectedSignature) {
+			return nil
+		}
+	}
+
+	return ErrSignatureIsNotValid
+}
+
+func computeSignature(secret []byte, timestamp string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// ParseGeneric parses a payload in the GenericFeedback format. The signature of the payload must be
+// verified beforehand.
+func ParseGeneric(payload []byte) (feedback Feedback, err error) {
+	var genericFeedback GenericFeedback
+	err = json.Unmarshal(payload, &genericFeedback)
+	if err != nil {
+		return feedback, fmt.Errorf("emailfeedback: parsing JSON: %w", err)
+	}
+
+	switch genericFeedback.Type {
+	case TypeBounce:
+		if genericFeedback.BounceType != BounceTypePermanent && genericFeedback.BounceType != BounceTypeTransient {
+			return feedback, fmt.Errorf("emailfeedback: bounce_type is not valid. Valid values are [%s, %s]",
+				BounceTypePermanent, BounceTypeTransient)
+		}
+	case TypeComplaint:
+		genericFeedback.BounceType = ""
+	default:
+		return feedback, fmt.Errorf("emailfeedback: type is not valid. Valid values are [%s, %s]",
+			TypeBounce, TypeComplaint)
+	}
+
+	email := normalizeEmail(genericFeedback.Email)
+	if !strings.Contains(email, "@") {
+		return feedback, errors.New("emailfeedback: email is not valid")
+	}
+
+	feedback = Feedback{
+		ID:           genericFeedback.ID,
+		Source:       SourceGeneric,
+		Type:         genericFeedback.Type,
+		BounceType:   genericFeedback.BounceType,
+		Email:        email,
+		Diagnostic:   genericFeedback.Diagnostic,
+		WebsiteID:    genericFeedback.WebsiteID,
+		NewsletterID: genericFeedback.NewsletterID,
+	}
+	return feedback, nil
+}
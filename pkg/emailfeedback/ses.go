@@ -0,0 +1,296 @@
+package emailfeedback
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SnsMessageTypeNotification             = "Notification"
+	SnsMessageTypeSubscriptionConfirmation = "SubscriptionConfirmation"
+	SnsMessageTypeUnsubscribeConfirmation  = "UnsubscribeConfirmation"
+)
+
+// the certificates used to sign SNS messages are hosted on sns.<region>.amazonaws.com
+var snsHostRegexp = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// SnsMessage is a message delivered by AWS SNS to an HTTPS endpoint
+// https://docs.aws.amazon.com/sns/latest/dg/sns-message-and-json-formats.html
+type SnsMessage struct {
+	Type             string `json:"Type"`
+	MessageId        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL"`
+}
+
+// SnsVerifier verifies the signatures of SNS messages. The signing certificates are cached.
+type SnsVerifier struct {
+	httpClient   *http.Client
+	certificates sync.Map
+}
+
+func NewSnsVerifier(httpClient *http.Client) *SnsVerifier {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &SnsVerifier{
+		httpClient:   httpClient,
+		certificates: sync.Map{},
+	}
+}
+
+// ParseAndVerifyMessage parses an SNS message and verifies its signature
+// https://docs.aws.amazon.com/sns/latest/dg/sns-verify-signature-of-message.html
+func (verifier *SnsVerifier) ParseAndVerifyMessage(ctx context.Context, payload []byte) (message SnsMessage, err error) {
+	err = json.Unmarshal(payload, &message)
+	if err != nil {
+		return message, fmt.Errorf("emailfeedback: parsing SNS message: %w", err)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(message.Signature)
+	if err != nil {
+		return message, fmt.Errorf("emailfeedback: decoding SNS signature: %w", err)
+	}
+
+	var signatureAlgorithm x509.SignatureAlgorithm
+	switch message.SignatureVersion {
+	case "1":
+		signatureAlgorithm = x509.SHA1WithRSA
+	case "2":
+		signatureAlgorithm = x509.SHA256WithRSA
+	default:
+		return message, fmt.Errorf("emailfeedback: SNS SignatureVersion is not supported: %s", message.SignatureVersion)
+	}
+
+	var signedFields []string
+	switch message.Type {
+	case SnsMessageTypeNotification:
+		signedFields = []string{"Message", message.Message, "MessageId", message.MessageId}
+		if message.Subject != "" {
+			signedFields = append(signedFields, "Subject", message.Subject)
+		}
+		signedFields = append(signedFields, "Timestamp", message.Timestamp, "TopicArn", message.TopicArn,
+			"Type", message.Type)
+	case SnsMessageTypeSubscriptionConfirmation, SnsMessageTypeUnsubscribeConfirmation:
+		signedFields = []string{"Message", message.Message, "MessageId", message.MessageId,
+			"SubscribeURL", message.SubscribeURL, "Timestamp", message.Timestamp, "Token", message.Token,
+			"TopicArn", message.TopicArn, "Type", message.Type}
+	default:
+		return message, fmt.Errorf("emailfeedback: SNS message type is not supported: %s", message.Type)
+	}
+	stringToSign := strings.Join(signedFields, "\n") + "\n"
+
+	certificate, err := verifier.getCertificate(ctx, message.SigningCertURL)
+	if err != nil {
+		return message, err
+	}
+
+	err = certificate.CheckSignature(signatureAlgorithm, []byte(stringToSign), signature)
+	if err != nil {
+		return message, fmt.Errorf("emailfeedback: SNS signature is not valid: %w", err)
+	}
+
+	return message, nil
+}
+
+// ConfirmSubscription confirms the subscription of the endpoint to an SNS topic
+func (verifier *SnsVerifier) ConfirmSubscription(ctx context.Context, message SnsMessage) (err error) {
+	subscribeUrl, err := validateSnsUrl(message.SubscribeURL)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, subscribeUrl.String(), nil)
+	if err != nil {
+		return fmt.Errorf("emailfeedback: creating SNS subscription confirmation request: %w", err)
+	}
+
+	res, err := verifier.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("emailfeedback: confirming SNS subscription: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("emailfeedback: confirming SNS subscription: status code: %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+func (verifier *SnsVerifier) getCertificate(ctx context.Context, certificateUrlStr string) (*x509.Certificate, error) {
+	certificateUrl, err := validateSnsUrl(certificateUrlStr)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(certificateUrl.Path, ".pem") {
+		return nil, errors.New("emailfeedback: SNS SigningCertURL is not valid")
+	}
+
+	if certificate, ok := verifier.certificates.Load(certificateUrl.String()); ok {
+		return certificate.(*x509.Certificate), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, certificateUrl.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("emailfeedback: creating SNS certificate request: %w", err)
+	}
+
+	res, err := verifier.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("emailfeedback: fetching SNS certificate: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("emailfeedback: fetching SNS certificate: status code: %d", res.StatusCode)
+	}
+
+	certificatePem, err := io.ReadAll(io.LimitReader(res.Body, 64_000))
+	if err != nil {
+		return nil, fmt.Errorf("emailfeedback: reading SNS certificate: %w", err)
+	}
+
+	block, _ := pem.Decode(certificatePem)
+	if block == nil {
+		return nil, errors.New("emailfeedback: SNS certificate is not valid PEM")
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("emailfeedback: parsing SNS certificate: %w", err)
+	}
+
+	verifier.certificates.Store(certificateUrl.String(), certificate)
+	return certificate, nil
+}
+
+func validateSnsUrl(urlStr string) (*url.URL, error) {
+	parsedUrl, err := url.Parse(urlStr)
+	if err != nil || parsedUrl.Scheme != "https" || !snsHostRegexp.MatchString(parsedUrl.Hostname()) {
+		return nil, fmt.Errorf("emailfeedback: SNS URL is not valid: %s", urlStr)
+	}
+	return parsedUrl, nil
+}
+
+// sesNotification is a bounce or complaint notification (or event) published by SES to SNS
+// https://docs.aws.amazon.com/ses/latest/dg/notification-contents.html
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	// used instead of notificationType by the event publishing of configuration sets
+	EventType string `json:"eventType"`
+	Mail      struct {
+		Headers []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"headers"`
+	} `json:"mail"`
+	Bounce *struct {
+		FeedbackId        string `json:"feedbackId"`
+		BounceType        string `json:"bounceType"`
+		BounceSubType     string `json:"bounceSubType"`
+		BouncedRecipients []struct {
+			EmailAddress   string `json:"emailAddress"`
+			Status         string `json:"status"`
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint *struct {
+		FeedbackId            string `json:"feedbackId"`
+		ComplaintFeedbackType string `json:"complaintFeedbackType"`
+		ComplainedRecipients  []struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"complainedRecipients"`
+	} `json:"complaint"`
+}
+
+// ParseSesNotification parses the Message of an SNS notification published by SES.
+// Notifications that are neither bounces nor complaints (e.g. deliveries) are ignored.
+func ParseSesNotification(message string) (feedback []Feedback, err error) {
+	var notification sesNotification
+	err = json.Unmarshal([]byte(message), &notification)
+	if err != nil {
+		return nil, fmt.Errorf("emailfeedback: parsing SES notification: %w", err)
+	}
+
+	websiteID, newsletterID := parseIDsFromHeaders(func(key string) string {
+		for _, header := range notification.Mail.Headers {
+			if strings.EqualFold(header.Name, key) {
+				return header.Value
+			}
+		}
+		return ""
+	})
+
+	notificationType := notification.NotificationType
+	if notificationType == "" {
+		notificationType = notification.EventType
+	}
+
+	feedback = make([]Feedback, 0, 1)
+	switch notificationType {
+	case "Bounce":
+		if notification.Bounce == nil {
+			return nil, errors.New("emailfeedback: SES bounce notification has no bounce")
+		}
+		// Undetermined bounces are handled as transient bounces so that a single one doesn't unsubscribe
+		// the contact
+		bounceType := BounceTypeTransient
+		if notification.Bounce.BounceType == "Permanent" {
+			bounceType = BounceTypePermanent
+		}
+		for _, recipient := range notification.Bounce.BouncedRecipients {
+			diagnostic := recipient.DiagnosticCode
+			if diagnostic == "" {
+				diagnostic = strings.TrimSpace(notification.Bounce.BounceType + " " + notification.Bounce.BounceSubType)
+			}
+			feedback = append(feedback, Feedback{
+				ID:           notification.Bounce.FeedbackId,
+				Source:       SourceSes,
+				Type:         TypeBounce,
+				BounceType:   bounceType,
+				Email:        normalizeEmail(recipient.EmailAddress),
+				Diagnostic:   diagnostic,
+				WebsiteID:    websiteID,
+				NewsletterID: newsletterID,
+			})
+		}
+	case "Complaint":
+		if notification.Complaint == nil {
+			return nil, errors.New("emailfeedback: SES complaint notification has no complaint")
+		}
+		for _, recipient := range notification.Complaint.ComplainedRecipients {
+			feedback = append(feedback, Feedback{
+				ID:           notification.Complaint.FeedbackId,
+				Source:       SourceSes,
+				Type:         TypeComplaint,
+				BounceType:   "",
+				Email:        normalizeEmail(recipient.EmailAddress),
+				Diagnostic:   notification.Complaint.ComplaintFeedbackType,
+				WebsiteID:    websiteID,
+				NewsletterID: newsletterID,
+			})
+		}
+	}
+
+	return feedback, nil
+}
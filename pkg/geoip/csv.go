@@ -0,0 +1,131 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// csvDatabase is a sorted list of non-overlapping IP ranges.
+// Each line of the file is: start_ip,end_ip,country[,asn[,as_name]]
+// Lines starting with # and an optional header line are ignored.
+type csvDatabase struct {
+	ranges []csvRange
+}
+
+type csvRange struct {
+	start  netip.Addr
+	end    netip.Addr
+	record Record
+}
+
+func loadCsvDatabase(data []byte) (database, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	ranges := make([]csvRange, 0, bytes.Count(data, []byte{'\n'})+1)
+	for isFirstRecord := true; ; isFirstRecord = false {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("geoip: error reading CSV database: %w", err)
+		}
+
+		ipRange, err := parseCsvRange(fields)
+		if err != nil {
+			// skip the header line
+			if isFirstRecord {
+				continue
+			}
+			lineNumber, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("geoip: CSV database: line %d: %w", lineNumber, err)
+		}
+		ranges = append(ranges, ipRange)
+	}
+
+	if len(ranges) == 0 {
+		return nil, errors.New("geoip: CSV database is empty")
+	}
+
+	slices.SortFunc(ranges, func(a, b csvRange) int {
+		return a.start.Compare(b.start)
+	})
+
+	for i := 1; i < len(ranges); i += 1 {
+		if ranges[i].start.Compare(ranges[i-1].end) <= 0 {
+			return nil, fmt.Errorf("geoip: CSV database: range %s-%s overlaps with %s-%s",
+				ranges[i].start, ranges[i].end, ranges[i-1].start, ranges[i-1].end)
+		}
+	}
+
+	return &csvDatabase{ranges: ranges}, nil
+}
+
+func parseCsvRange(fields []string) (ipRange csvRange, err error) {
+	if len(fields) < 3 {
+		return ipRange, errors.New("at least 3 fields are required: start_ip,end_ip,country")
+	}
+
+	ipRange.start, err = netip.ParseAddr(strings.TrimSpace(fields[0]))
+	if err != nil {
+		return ipRange, fmt.Errorf("start_ip is not valid: %w", err)
+	}
+	ipRange.end, err = netip.ParseAddr(strings.TrimSpace(fields[1]))
+	if err != nil {
+		return ipRange, fmt.Errorf("end_ip is not valid: %w", err)
+	}
+	ipRange.start = ipRange.start.Unmap()
+	ipRange.end = ipRange.end.Unmap()
+	if ipRange.start.Is4() != ipRange.end.Is4() || ipRange.end.Less(ipRange.start) {
+		return ipRange, fmt.Errorf("range %s-%s is not valid", ipRange.start, ipRange.end)
+	}
+
+	ipRange.record.Country = strings.ToUpper(strings.TrimSpace(fields[2]))
+	if len(ipRange.record.Country) != 2 {
+		return ipRange, fmt.Errorf("country (%s) is not a valid ISO 3166-1 alpha-2 code", ipRange.record.Country)
+	}
+
+	if len(fields) > 3 && strings.TrimSpace(fields[3]) != "" {
+		asn := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(fields[3])), "AS")
+		ipRange.record.ASN, err = strconv.ParseInt(asn, 10, 64)
+		if err != nil {
+			return ipRange, fmt.Errorf("asn (%s) is not valid", fields[3])
+		}
+	}
+
+	if len(fields) > 4 {
+		ipRange.record.AsName = strings.TrimSpace(fields[4])
+	}
+
+	return ipRange, nil
+}
+
+func (db *csvDatabase) lookup(ip netip.Addr) (record Record, found bool, err error) {
+	// index of the first range that starts after ip
+	index, _ := slices.BinarySearchFunc(db.ranges, ip, func(ipRange csvRange, target netip.Addr) int {
+		if ipRange.start.Compare(target) <= 0 {
+			return -1
+		}
+		return 1
+	})
+	if index == 0 {
+		return record, false, nil
+	}
+
+	ipRange := db.ranges[index-1]
+	if ip.Compare(ipRange.end) > 0 {
+		return record, false, nil
+	}
+
+	return ipRange.record, true, nil
+}
@@ -0,0 +1,140 @@
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/skerkour/stdx-go/countries"
+	"github.com/skerkour/stdx-go/log/slogx"
+)
+
+type FileFormat string
+
+const (
+	FileFormatMmdb FileFormat = "mmdb"
+	FileFormatCsv  FileFormat = "csv"
+)
+
+// FileReloadInterval is the interval at which the database file is checked for changes
+const FileReloadInterval = 30 * time.Second
+
+// database is an immutable, loaded geoip database
+type database interface {
+	lookup(ip netip.Addr) (record Record, found bool, err error)
+}
+
+type fileProvider struct {
+	path   string
+	format FileFormat
+	logger *slog.Logger
+
+	database atomic.Pointer[database]
+	// modTime and size of the currently loaded file. Only accessed by the reload goroutine.
+	modTime time.Time
+	size    int64
+}
+
+// NewFileProvider returns a Provider that reads the geoip database from a local file.
+// The file is loaded once before returning, and then reloaded in the background whenever its
+// modification time or size changes, until ctx is canceled.
+// If a reload fails, the previously loaded database is kept.
+func NewFileProvider(ctx context.Context, path string, format FileFormat, logger *slog.Logger) (Provider, error) {
+	if format != FileFormatMmdb && format != FileFormatCsv {
+		return nil, fmt.Errorf("geoip: unknown database format: %s", format)
+	}
+
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	provider := &fileProvider{
+		path:     path,
+		format:   format,
+		logger:   logger,
+		database: atomic.Pointer[database]{},
+	}
+
+	_, err := provider.reloadIfModified()
+	if err != nil {
+		return nil, err
+	}
+
+	go provider.reloadInBackground(ctx)
+
+	return provider, nil
+}
+
+func (provider *fileProvider) Lookup(ctx context.Context, ip netip.Addr) (Record, error) {
+	db := *provider.database.Load()
+	record, found, err := db.lookup(ip.Unmap())
+	if err != nil {
+		return Record{Country: countries.CodeUnknown}, fmt.Errorf("geoip: error looking up IP address (%s): %w", ip, err)
+	}
+	if !found {
+		return Record{Country: countries.CodeUnknown}, nil
+	}
+
+	if record.Country == "" {
+		record.Country = countries.CodeUnknown
+	}
+	return record, nil
+}
+
+func (provider *fileProvider) reloadInBackground(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(FileReloadInterval):
+		}
+
+		reloaded, err := provider.reloadIfModified()
+		if err != nil {
+			provider.logger.Warn("geoip: error reloading database", slogx.Err(err), slog.String("path", provider.path))
+			continue
+		}
+		if reloaded {
+			provider.logger.Info("geoip: database successfully reloaded", slog.String("path", provider.path))
+		}
+	}
+}
+
+// reloadIfModified loads the database file if it has changed since the last successful load
+func (provider *fileProvider) reloadIfModified() (reloaded bool, err error) {
+	fileInfo, err := os.Stat(provider.path)
+	if err != nil {
+		return false, fmt.Errorf("geoip: error reading database file: %w", err)
+	}
+
+	if provider.database.Load() != nil &&
+		fileInfo.ModTime().Equal(provider.modTime) && fileInfo.Size() == provider.size {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(provider.path)
+	if err != nil {
+		return false, fmt.Errorf("geoip: error reading database file: %w", err)
+	}
+
+	var db database
+	switch provider.format {
+	case FileFormatMmdb:
+		db, err = loadMmdbDatabase(data)
+	case FileFormatCsv:
+		db, err = loadCsvDatabase(data)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	provider.database.Store(&db)
+	provider.modTime = fileInfo.ModTime()
+	provider.size = fileInfo.Size()
+
+	return true, nil
+}
@@ -0,0 +1,22 @@
+// Package geoip resolves the country and autonomous system of IP addresses.
+//
+// The database can either be managed by pingoo.io or be a local MaxMind-format (MMDB) or CSV file
+// so that analytics and country blocking also work on air-gapped or self-hosted deployments.
+package geoip
+
+import (
+	"context"
+	"net/netip"
+)
+
+// Record is the geoip information of an IP address. Country is countries.CodeUnknown when the
+// IP address is not found in the database.
+type Record struct {
+	Country string
+	ASN     int64
+	AsName  string
+}
+
+type Provider interface {
+	Lookup(ctx context.Context, ip netip.Addr) (Record, error)
+}
@@ -0,0 +1,142 @@
+package geoip
+
+import (
+	"context"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skerkour/stdx-go/countries"
+)
+
+const testCsvDatabase = `start_ip,end_ip,country,asn,as_name
+# comment
+1.0.0.0,1.0.0.255,AU,13335,Cloudflare
+8.8.8.0,8.8.8.255,us,AS15169,Google
+2001:db8::,2001:db8::ffff,fr,,
+`
+
+func TestCsvDatabaseLookup(t *testing.T) {
+	db, err := loadCsvDatabase([]byte(testCsvDatabase))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ip     string
+		found  bool
+		record Record
+	}{
+		{"1.0.0.0", true, Record{Country: "AU", ASN: 13335, AsName: "Cloudflare"}},
+		{"1.0.0.255", true, Record{Country: "AU", ASN: 13335, AsName: "Cloudflare"}},
+		{"1.0.1.0", false, Record{}},
+		{"0.255.255.255", false, Record{}},
+		{"8.8.8.8", true, Record{Country: "US", ASN: 15169, AsName: "Google"}},
+		{"2001:db8::1", true, Record{Country: "FR"}},
+		{"2001:db8::1:0", false, Record{}},
+		{"::1", false, Record{}},
+	}
+
+	for _, test := range tests {
+		record, found, err := db.lookup(netip.MustParseAddr(test.ip))
+		if err != nil {
+			t.Fatalf("%s: %s", test.ip, err)
+		}
+		if found != test.found || record != test.record {
+			t.Errorf("%s: got (%v, %v), expected (%v, %v)", test.ip, record, found, test.record, test.found)
+		}
+	}
+}
+
+func TestCsvDatabaseInvalid(t *testing.T) {
+	invalidDatabases := []string{
+		"1.0.0.0,1.0.0.255,AU\n1.0.0.0,not_an_ip,AU\n",
+		"1.0.0.0,1.0.0.255,AU\n1.0.0.255,1.0.1.0,AU\n",
+		"1.0.0.0,1.0.0.255,AU\n2.0.0.0,1.0.0.0,AU\n",
+		"1.0.0.0,1.0.0.255,AU\n2.0.0.0,2.0.0.255,USA\n",
+	}
+
+	for _, invalidDatabase := range invalidDatabases {
+		_, err := loadCsvDatabase([]byte(invalidDatabase))
+		if err == nil {
+			t.Errorf("expected an error for database: %q", invalidDatabase)
+		}
+	}
+}
+
+func TestFileProviderReload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	databasePath := filepath.Join(t.TempDir(), "geoip.csv")
+	err := os.WriteFile(databasePath, []byte("1.0.0.0,1.0.0.255,AU\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := NewFileProvider(ctx, databasePath, FileFormatCsv, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := provider.Lookup(ctx, netip.MustParseAddr("1.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Country != "AU" {
+		t.Errorf("expected country AU, got %s", record.Country)
+	}
+
+	record, err = provider.Lookup(ctx, netip.MustParseAddr("::ffff:2.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Country != countries.CodeUnknown {
+		t.Errorf("expected country %s, got %s", countries.CodeUnknown, record.Country)
+	}
+
+	err = os.WriteFile(databasePath, []byte("1.0.0.0,1.0.0.255,AU\n2.0.0.0,2.0.0.255,NZ\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chtimes(databasePath, time.Time{}, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := provider.(*fileProvider).reloadIfModified()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded {
+		t.Fatal("expected the database to be reloaded")
+	}
+
+	record, err = provider.Lookup(ctx, netip.MustParseAddr("2.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Country != "NZ" {
+		t.Errorf("expected country NZ, got %s", record.Country)
+	}
+
+	// a broken file must not replace the loaded database
+	err = os.WriteFile(databasePath, []byte("not a database"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = provider.(*fileProvider).reloadIfModified()
+	if err == nil {
+		t.Fatal("expected an error when reloading an invalid database")
+	}
+
+	record, err = provider.Lookup(ctx, netip.MustParseAddr("2.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Country != "NZ" {
+		t.Errorf("expected country NZ, got %s", record.Country)
+	}
+}
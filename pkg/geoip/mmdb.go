@@ -0,0 +1,69 @@
+package geoip
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/skerkour/stdx-go/mmdb"
+)
+
+type mmdbDatabase struct {
+	reader *mmdb.Reader
+}
+
+// mmdbRecord supports both the MaxMind (GeoLite2 / GeoIP2 Country and ASN) and the
+// IPinfo / DB-IP "lite" schemas.
+type mmdbRecord struct {
+	Country struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+	AutonomousSystemNumber       uint32 `maxminddb:"autonomous_system_number"`
+	AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+
+	CountryCode string `maxminddb:"country_code"`
+	Asn         string `maxminddb:"asn"`
+	AsName      string `maxminddb:"as_name"`
+}
+
+func loadMmdbDatabase(data []byte) (database, error) {
+	reader, err := mmdb.FromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: error loading MMDB database: %w", err)
+	}
+
+	return &mmdbDatabase{reader: reader}, nil
+}
+
+func (db *mmdbDatabase) lookup(ip netip.Addr) (record Record, found bool, err error) {
+	var result mmdbRecord
+
+	_, found, err = db.reader.LookupNetwork(ip.AsSlice(), &result)
+	if err != nil || !found {
+		return
+	}
+
+	record.Country = result.Country.IsoCode
+	if record.Country == "" {
+		record.Country = result.RegisteredCountry.IsoCode
+	}
+	if record.Country == "" {
+		record.Country = result.CountryCode
+	}
+	record.Country = strings.ToUpper(record.Country)
+
+	record.ASN = int64(result.AutonomousSystemNumber)
+	record.AsName = result.AutonomousSystemOrganization
+	if record.ASN == 0 && result.Asn != "" {
+		record.ASN, _ = strconv.ParseInt(strings.TrimPrefix(strings.ToUpper(result.Asn), "AS"), 10, 64)
+	}
+	if record.AsName == "" {
+		record.AsName = result.AsName
+	}
+
+	return record, true, nil
+}
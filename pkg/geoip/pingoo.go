@@ -0,0 +1,28 @@
+package geoip
+
+import (
+	"context"
+	"net/netip"
+
+	"markdown.ninja/pingoo-go"
+)
+
+type pingooProvider struct {
+	client *pingoo.Client
+}
+
+// NewPingooProvider returns a Provider that uses the geoip database downloaded from pingoo.io
+func NewPingooProvider(client *pingoo.Client) Provider {
+	return &pingooProvider{
+		client: client,
+	}
+}
+
+func (provider *pingooProvider) Lookup(ctx context.Context, ip netip.Addr) (Record, error) {
+	record, err := provider.client.GeoipLookup(ctx, ip)
+	return Record{
+		Country: record.Country,
+		ASN:     record.ASN,
+		AsName:  record.AsName,
+	}, err
+}
@@ -0,0 +1,109 @@
+package invoicing
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+var htmlTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"amount":  FormatAmount,
+	"vatRate": FormatVatRate,
+	"lines":   func(input string) []string { return strings.Split(strings.TrimSpace(input), "\n") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }} {{ .Number }}</title>
+<style>
+@page { size: A4; margin: 2cm; }
+body { font-family: sans-serif; font-size: 14px; color: #111827; max-width: 50em; margin: 0 auto; padding: 1em; }
+table { width: 100%; border-collapse: collapse; margin-top: 2em; }
+th, td { padding: 0.5em; border-bottom: 1px solid #e5e7eb; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.parties { display: flex; justify-content: space-between; margin-top: 2em; }
+.parties p { margin: 0; }
+.totals { margin-left: auto; width: 50%; }
+.notes { margin-top: 3em; font-size: 12px; color: #4b5563; }
+@media print { body { max-width: none; padding: 0; } }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p>
+{{ .Title }} number: {{ .Number }}<br>
+Date: {{ .Date.Format "2006-01-02" }}
+{{- if .Reference }}<br>
+Credit note for invoice: {{ .Reference }}{{ end }}
+</p>
+<div class="parties">
+<div>
+<p><b>From</b></p>
+{{ range .Seller.Lines }}<p>{{ . }}</p>
+{{ end -}}
+</div>
+<div>
+<p><b>Bill to</b></p>
+{{ range .Buyer.Lines }}<p>{{ . }}</p>
+{{ end -}}
+</div>
+</div>
+<table>
+<thead>
+<tr><th>Description</th><th>Quantity</th><th>Unit price ({{ .Currency }})</th><th>Amount ({{ .Currency }})</th></tr>
+</thead>
+<tbody>
+{{ range .Lines -}}
+<tr><td>{{ .Description }}</td><td>{{ .Quantity }}</td><td>{{ amount .UnitAmount }}</td><td>{{ amount .Amount }}</td></tr>
+{{ end -}}
+</tbody>
+</table>
+<table class="totals">
+<tr><td>Total excluding VAT</td><td>{{ amount .NetAmount }} {{ .Currency }}</td></tr>
+<tr><td>VAT ({{ vatRate .VatRate }})</td><td>{{ amount .VatAmount }} {{ .Currency }}</td></tr>
+<tr><td><b>Total</b></td><td><b>{{ amount .TotalAmount }} {{ .Currency }}</b></td></tr>
+</table>
+{{ if .Notes }}<div class="notes">
+{{ range lines .Notes }}<p>{{ . }}</p>
+{{ end -}}
+</div>
+{{ end -}}
+</body>
+</html>
+`))
+
+type htmlParty struct {
+	Lines []string
+}
+
+// Html renders the document as a self-contained and print-ready HTML page.
+func (doc Document) Html() ([]byte, error) {
+	data := struct {
+		Document
+		Title  string
+		Seller htmlParty
+		Buyer  htmlParty
+	}{
+		Document: doc,
+		Title:    doc.Title(),
+		Seller:   htmlParty{Lines: splitLines(doc.Seller.lines())},
+		Buyer:    htmlParty{Lines: splitLines(doc.Buyer.lines())},
+	}
+
+	var output bytes.Buffer
+	err := htmlTemplate.Execute(&output, data)
+	if err != nil {
+		return nil, fmt.Errorf("invoicing: rendering HTML: %w", err)
+	}
+
+	return output.Bytes(), nil
+}
+
+func splitLines(input []string) []string {
+	lines := make([]string, 0, len(input))
+	for _, line := range input {
+		lines = append(lines, strings.Split(line, "\n")...)
+	}
+	return lines
+}
@@ -0,0 +1,113 @@
+// Package invoicing renders invoices and credit notes as PDF and HTML documents.
+package invoicing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Type string
+
+const (
+	TypeInvoice    Type = "invoice"
+	TypeCreditNote Type = "credit_note"
+)
+
+const (
+	MediaTypePdf  = "application/pdf"
+	MediaTypeHtml = "text/html; charset=utf-8"
+)
+
+// VatRateBasisPoints is the value of a VAT rate of 100%. VAT rates are expressed in basis points to
+// support rates such as 5.5%.
+const VatRateBasisPoints = 10_000
+
+// NumberDigits is the minimum number of digits of the sequence part of invoice numbers.
+const NumberDigits = 6
+
+// Document is an invoice or a credit note. All the amounts are in the smallest unit of the currency
+// (e.g. cents) and are positive, including for credit notes.
+type Document struct {
+	Type   Type
+	Number string
+	Date   time.Time
+	// Reference is the number of the invoice corrected by a credit note
+	Reference string
+	Currency  string
+
+	Seller Party
+	Buyer  Party
+
+	Lines []Line
+	// VatRate is in basis points (e.g. 2000 = 20%)
+	VatRate     int64
+	NetAmount   int64
+	VatAmount   int64
+	TotalAmount int64
+
+	// Notes are printed at the bottom of the document (e.g. legal mentions)
+	Notes string
+}
+
+type Party struct {
+	Name      string
+	Address   string
+	VatNumber string
+	Email     string
+	Country   string
+	// AdditionalInformation is free-form information provided by the buyer (e.g. company name, tax ID...)
+	AdditionalInformation string
+}
+
+type Line struct {
+	Description string
+	Quantity    int64
+	UnitAmount  int64
+	Amount      int64
+}
+
+// Title returns the human-readable type of the document.
+func (doc Document) Title() string {
+	if doc.Type == TypeCreditNote {
+		return "Credit Note"
+	}
+	return "Invoice"
+}
+
+// FormatNumber returns the number of an invoice from its prefix and its position in the sequence of
+// the documents of the seller, e.g. FormatNumber("INV-", 42) = "INV-000042".
+func FormatNumber(prefix string, sequence int64) string {
+	return fmt.Sprintf("%s%0*d", prefix, NumberDigits, sequence)
+}
+
+// FormatAmount formats an amount in the smallest unit of a currency with 2 decimals, e.g. 1250 = "12.50".
+func FormatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// FormatVatRate formats a VAT rate in basis points as a percentage, e.g. 550 = "5.5%".
+func FormatVatRate(rate int64) string {
+	percentage := strconv.FormatFloat(float64(rate)/100, 'f', 2, 64)
+	percentage = strings.TrimRight(strings.TrimRight(percentage, "0"), ".")
+	return percentage + "%"
+}
+
+// SplitVat splits a total amount, VAT included, into its net amount and its VAT amount.
+// The VAT amount is rounded to the nearest unit and net + vat always equals total.
+func SplitVat(total, vatRate int64) (net, vat int64) {
+	if vatRate <= 0 {
+		return total, 0
+	}
+
+	// round half up: net = total / (1 + rate)
+	net = (total*VatRateBasisPoints*2 + (VatRateBasisPoints + vatRate)) / ((VatRateBasisPoints + vatRate) * 2)
+	vat = total - net
+	return net, vat
+}
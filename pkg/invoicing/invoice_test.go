@@ -0,0 +1,195 @@
+package invoicing
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pdfcpuapi "github.com/pdfcpu/pdfcpu/pkg/api"
+	pdfcpumodel "github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+func testDocument() Document {
+	return Document{
+		Type:      TypeCreditNote,
+		Number:    "CN-000001",
+		Date:      time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC),
+		Reference: "INV-000042",
+		Currency:  "EUR",
+		Seller: Party{
+			Name:      "Ninja SAS",
+			Address:   "1 rue de Paris\n75001 Paris\nFrance",
+			VatNumber: "FR12345678901",
+		},
+		Buyer: Party{
+			Email:                 "customer@example.com",
+			Country:               "FR",
+			AdditionalInformation: "ACME <Corp>",
+		},
+		Lines: []Line{
+			{Description: "My ebook", Quantity: 1, UnitAmount: 2000, Amount: 2000},
+			{Description: "My course 🚀", Quantity: 2, UnitAmount: 500, Amount: 1000},
+		},
+		VatRate:     2000,
+		NetAmount:   2500,
+		VatAmount:   500,
+		TotalAmount: 3000,
+		Notes:       "Thank you!",
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		sequence int64
+		expected string
+	}{
+		{"INV-", 1, "INV-000001"},
+		{"CN-", 42, "CN-000042"},
+		{"", 1234567, "1234567"},
+	}
+
+	for _, test := range tests {
+		if number := FormatNumber(test.prefix, test.sequence); number != test.expected {
+			t.Errorf("FormatNumber(%q, %d) = %q, expected: %q", test.prefix, test.sequence, number, test.expected)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := map[int64]string{
+		0:     "0.00",
+		5:     "0.05",
+		1250:  "12.50",
+		-1999: "-19.99",
+	}
+
+	for amount, expected := range tests {
+		if formatted := FormatAmount(amount); formatted != expected {
+			t.Errorf("FormatAmount(%d) = %q, expected: %q", amount, formatted, expected)
+		}
+	}
+}
+
+func TestFormatVatRate(t *testing.T) {
+	tests := map[int64]string{
+		0:     "0%",
+		550:   "5.5%",
+		2000:  "20%",
+		2125:  "21.25%",
+		10000: "100%",
+	}
+
+	for rate, expected := range tests {
+		if formatted := FormatVatRate(rate); formatted != expected {
+			t.Errorf("FormatVatRate(%d) = %q, expected: %q", rate, formatted, expected)
+		}
+	}
+}
+
+func TestSplitVat(t *testing.T) {
+	tests := []struct {
+		total       int64
+		vatRate     int64
+		expectedNet int64
+		expectedVat int64
+	}{
+		{total: 1200, vatRate: 2000, expectedNet: 1000, expectedVat: 200},
+		{total: 1000, vatRate: 0, expectedNet: 1000, expectedVat: 0},
+		// 999 / 1.2 = 832.5
+		{total: 999, vatRate: 2000, expectedNet: 833, expectedVat: 166},
+		// 1000 / 1.055 = 947.87
+		{total: 1000, vatRate: 550, expectedNet: 948, expectedVat: 52},
+		{total: 1, vatRate: 2000, expectedNet: 1, expectedVat: 0},
+	}
+
+	for _, test := range tests {
+		net, vat := SplitVat(test.total, test.vatRate)
+		if net != test.expectedNet || vat != test.expectedVat {
+			t.Errorf("SplitVat(%d, %d) = (%d, %d), expected: (%d, %d)", test.total, test.vatRate, net, vat,
+				test.expectedNet, test.expectedVat)
+		}
+	}
+}
+
+func TestPdf(t *testing.T) {
+	pdf, err := testDocument().Pdf()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		t.Errorf("document is not a PDF")
+	}
+}
+
+func TestPdfPages(t *testing.T) {
+	doc := testDocument()
+	doc.Notes = strings.Repeat("Legal notice.\n", 20)
+	doc.Lines = make([]Line, 100)
+	for i := range doc.Lines {
+		doc.Lines[i] = Line{Description: fmt.Sprintf("Product %d", i), Quantity: 1, UnitAmount: 100, Amount: 100}
+	}
+
+	pages := doc.pdfPages()
+	if len(pages) < 2 {
+		t.Fatalf("expected the line items to continue on the next pages, got %d page", len(pages))
+	}
+
+	rows := 0
+	for i, page := range pages {
+		for _, table := range page.Table {
+			rows += table.Rows
+			if table.Pos[1] > pdfContentBottom {
+				t.Errorf("page %d: table ends at %f, below the content of the page", i+1, table.Pos[1])
+			}
+		}
+		for _, text := range page.Text {
+			if text.Pos[1] > pdfPageHeight-pdfMargin {
+				t.Errorf("page %d: %q is at %f, off the page", i+1, text.Value, text.Pos[1])
+			}
+		}
+	}
+	if rows != len(doc.Lines) {
+		t.Errorf("expected %d rows, got %d", len(doc.Lines), rows)
+	}
+
+	pdf, err := doc.Pdf()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pageCount, err := pdfcpuapi.PageCount(bytes.NewReader(pdf), pdfcpumodel.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("counting pages: %v", err)
+	}
+	if pageCount != len(pages) {
+		t.Errorf("expected %d pages, got %d", len(pages), pageCount)
+	}
+}
+
+func TestHtml(t *testing.T) {
+	html, err := testDocument().Html()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document := string(html)
+	for _, expected := range []string{
+		"<h1>Credit Note</h1>",
+		"CN-000001",
+		"Credit note for invoice: INV-000042",
+		"<p>75001 Paris</p>",
+		"VAT number: FR12345678901",
+		"ACME &lt;Corp&gt;",
+		"<td>My course 🚀</td><td>2</td><td>5.00</td><td>10.00</td>",
+		"<td>VAT (20%)</td><td>5.00 EUR</td>",
+		"<b>30.00 EUR</b>",
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("HTML document doesn't contain %q", expected)
+		}
+	}
+}
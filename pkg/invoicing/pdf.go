@@ -0,0 +1,272 @@
+package invoicing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	pdfcpuapi "github.com/pdfcpu/pdfcpu/pkg/api"
+	pdfcpumodel "github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+func init() {
+	// pdfcpu tries to read and write its configuration in the user's config dir by default
+	pdfcpumodel.ConfigPath = "disable"
+}
+
+// layout of the A4 pages, in points, with the origin in the upper left corner
+const (
+	pdfMargin       = 40
+	pdfPageHeight   = 842
+	pdfContentWidth = 595 - 2*pdfMargin
+	pdfLineHeight   = 22
+	pdfFontSize     = 10
+	pdfTitleSize    = 20
+	pdfPartiesY     = 150
+	pdfTotalsWidth  = 220
+	pdfFont         = "Helvetica"
+	pdfFontBold     = "Helvetica-Bold"
+
+	// pdfContentBottom is the lowest position of the content of the pages, above the page numbers
+	pdfContentBottom = pdfPageHeight - pdfMargin - 20
+)
+
+// the subset of the pdfcpu JSON format used to describe the document
+type pdfDocument struct {
+	Paper  string             `json:"paper"`
+	Origin string             `json:"origin"`
+	Pages  map[string]pdfPage `json:"pages"`
+}
+
+type pdfPage struct {
+	Content pdfContent `json:"content"`
+}
+
+type pdfContent struct {
+	Text  []pdfText  `json:"text"`
+	Table []pdfTable `json:"table"`
+}
+
+type pdfFontDescriptor struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+type pdfText struct {
+	Value string            `json:"value"`
+	Pos   [2]float64        `json:"pos"`
+	Align string            `json:"align,omitempty"`
+	Font  pdfFontDescriptor `json:"font"`
+}
+
+type pdfTableHeader struct {
+	Values []string          `json:"values"`
+	Font   pdfFontDescriptor `json:"font"`
+}
+
+type pdfTable struct {
+	Header     pdfTableHeader    `json:"header"`
+	Values     [][]string        `json:"values"`
+	Rows       int               `json:"rows"`
+	Cols       int               `json:"cols"`
+	Width      float64           `json:"width"`
+	ColWidths  []int             `json:"colWidths"`
+	ColAnchors []string          `json:"colAnchors"`
+	Pos        [2]float64        `json:"pos"`
+	LineHeight int               `json:"lheight"`
+	Font       pdfFontDescriptor `json:"font"`
+}
+
+// Pdf renders the document as an A4 PDF. The line items, totals and notes continue on the next pages
+// when they don't fit on the first one.
+func (doc Document) Pdf() ([]byte, error) {
+	pages := doc.pdfPages()
+	document := pdfDocument{
+		Paper:  "A4P",
+		Origin: "UpperLeft",
+		Pages:  make(map[string]pdfPage, len(pages)),
+	}
+	for i, content := range pages {
+		document.Pages[strconv.Itoa(i+1)] = pdfPage{Content: content}
+	}
+
+	jsonDocument, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("invoicing: encoding PDF document: %w", err)
+	}
+
+	var output bytes.Buffer
+	err = pdfcpuapi.Create(nil, bytes.NewReader(jsonDocument), &output, pdfcpumodel.NewDefaultConfiguration())
+	if err != nil {
+		return nil, fmt.Errorf("invoicing: creating PDF: %w", err)
+	}
+
+	return output.Bytes(), nil
+}
+
+// pdfPages returns the content of each page of the document
+func (doc Document) pdfPages() []pdfContent {
+	regular := pdfFontDescriptor{Name: pdfFont, Size: pdfFontSize}
+	bold := pdfFontDescriptor{Name: pdfFontBold, Size: pdfFontSize}
+	pages := []pdfContent{{}}
+
+	pages[0].Text = append(pages[0].Text,
+		newPdfText(pdfMargin, pdfMargin, doc.Title(), pdfFontDescriptor{Name: pdfFontBold, Size: pdfTitleSize}))
+
+	details := []string{
+		doc.Title() + " number: " + doc.Number,
+		"Date: " + doc.Date.Format("2006-01-02"),
+	}
+	if doc.Reference != "" {
+		details = append(details, "Credit note for invoice: "+doc.Reference)
+	}
+	texts, _ := pdfTextLines(pdfMargin, pdfMargin+40, "", details, regular, bold)
+	pages[0].Text = append(pages[0].Text, texts...)
+
+	texts, sellerEndY := pdfTextLines(pdfMargin, pdfPartiesY, "From", doc.Seller.lines(), regular, bold)
+	pages[0].Text = append(pages[0].Text, texts...)
+	texts, buyerEndY := pdfTextLines(pdfMargin+pdfContentWidth/2, pdfPartiesY, "Bill to", doc.Buyer.lines(), regular, bold)
+	pages[0].Text = append(pages[0].Text, texts...)
+
+	tableValues := make([][]string, len(doc.Lines))
+	for i, line := range doc.Lines {
+		tableValues[i] = []string{
+			pdfString(line.Description),
+			strconv.FormatInt(line.Quantity, 10),
+			FormatAmount(line.UnitAmount),
+			FormatAmount(line.Amount),
+		}
+	}
+	tableHeader := pdfTableHeader{
+		Values: []string{"Description", "Quantity", "Unit price (" + doc.Currency + ")", "Amount (" + doc.Currency + ")"},
+		Font:   bold,
+	}
+
+	// the line items are split in one table per page, each with its own header
+	tableTopY := max(sellerEndY, buyerEndY) + 30
+	var tableBottomY float64
+	for {
+		rows := min(max(int((pdfContentBottom-tableTopY)/pdfLineHeight)-1, 0), len(tableValues))
+		if rows != 0 || len(doc.Lines) == 0 {
+			// the position of tables is their lower left corner
+			tableBottomY = tableTopY + float64((rows+1)*pdfLineHeight)
+			pages[len(pages)-1].Table = append(pages[len(pages)-1].Table, pdfTable{
+				Header:     tableHeader,
+				Values:     tableValues[:rows],
+				Rows:       rows,
+				Cols:       4,
+				Width:      pdfContentWidth,
+				ColWidths:  []int{49, 13, 19, 19},
+				ColAnchors: []string{"Left", "Right", "Right", "Right"},
+				Pos:        [2]float64{pdfMargin, tableBottomY},
+				LineHeight: pdfLineHeight,
+				Font:       regular,
+			})
+			tableValues = tableValues[rows:]
+		}
+		if len(tableValues) == 0 {
+			break
+		}
+		pages = append(pages, pdfContent{})
+		tableTopY = pdfMargin
+	}
+
+	totalsX := float64(pdfMargin + pdfContentWidth - pdfTotalsWidth)
+	totals := [][2]string{
+		{"Total excluding VAT", FormatAmount(doc.NetAmount) + " " + doc.Currency},
+		{"VAT (" + FormatVatRate(doc.VatRate) + ")", FormatAmount(doc.VatAmount) + " " + doc.Currency},
+		{"Total", FormatAmount(doc.TotalAmount) + " " + doc.Currency},
+	}
+	totalsY := tableBottomY + 30
+	if totalsY+float64(len(totals)*15) > pdfContentBottom {
+		pages = append(pages, pdfContent{})
+		totalsY = pdfMargin
+	}
+	for i, total := range totals {
+		font := regular
+		if i == len(totals)-1 {
+			font = bold
+		}
+		y := totalsY + float64(i*15)
+		amount := newPdfText(totalsX+pdfTotalsWidth, y, total[1], font)
+		amount.Align = "Right"
+		pages[len(pages)-1].Text = append(pages[len(pages)-1].Text, newPdfText(totalsX, y, total[0], font), amount)
+	}
+
+	if notes := strings.TrimSpace(doc.Notes); notes != "" {
+		small := pdfFontDescriptor{Name: pdfFont, Size: 8}
+		y := totalsY + 80
+		for line := range strings.SplitSeq(notes, "\n") {
+			if y > pdfContentBottom {
+				pages = append(pages, pdfContent{})
+				y = pdfMargin
+			}
+			pages[len(pages)-1].Text = append(pages[len(pages)-1].Text, newPdfText(pdfMargin, y, line, small))
+			y += float64(small.Size) * 1.5
+		}
+	}
+
+	if len(pages) > 1 {
+		for i := range pages {
+			pageNumber := newPdfText(pdfMargin+pdfContentWidth, pdfPageHeight-pdfMargin,
+				fmt.Sprintf("%s - Page %d / %d", doc.Number, i+1, len(pages)), regular)
+			pageNumber.Align = "Right"
+			pages[i].Text = append(pages[i].Text, pageNumber)
+		}
+	}
+
+	return pages
+}
+
+func (party Party) lines() []string {
+	lines := make([]string, 0, 6)
+	for _, value := range []string{party.Name, party.Address, party.Email, party.Country} {
+		if value = strings.TrimSpace(value); value != "" {
+			lines = append(lines, value)
+		}
+	}
+	if vatNumber := strings.TrimSpace(party.VatNumber); vatNumber != "" {
+		lines = append(lines, "VAT number: "+vatNumber)
+	}
+	if info := strings.TrimSpace(party.AdditionalInformation); info != "" {
+		lines = append(lines, info)
+	}
+	return lines
+}
+
+// pdfTextLines returns one text element per line, starting at (x, y), with an optional bold title.
+// It also returns the position of the line following the last line.
+// Multi-line text elements are not used as their position is computed from their last line.
+func pdfTextLines(x, y float64, title string, lines []string, font, titleFont pdfFontDescriptor) ([]pdfText, float64) {
+	texts := make([]pdfText, 0, len(lines)+1)
+	lineHeight := float64(font.Size) * 1.5
+	if title != "" {
+		texts = append(texts, newPdfText(x, y, title, titleFont))
+		y += lineHeight
+	}
+	for _, line := range lines {
+		for subLine := range strings.SplitSeq(line, "\n") {
+			texts = append(texts, newPdfText(x, y, subLine, font))
+			y += lineHeight
+		}
+	}
+	return texts, y
+}
+
+func newPdfText(x, y float64, value string, font pdfFontDescriptor) pdfText {
+	// % starts the placeholders of pdfcpu (e.g. %p for the page number)
+	return pdfText{Value: strings.ReplaceAll(pdfString(value), "%", "%%"), Pos: [2]float64{x, y}, Font: font}
+}
+
+// pdfString removes the characters that can't be rendered with the standard fonts of PDF readers
+// (e.g. emojis) as well as carriage returns.
+func pdfString(input string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\r' || (r < ' ' && r != '\n') || (r > 0xFF && r != '€') {
+			return -1
+		}
+		return r
+	}, input)
+}
@@ -0,0 +1,194 @@
+package smtp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dkimSignedHeaders are the headers signed when they are present in the message.
+// From is mandatory (RFC 6376 section 5.4).
+var dkimSignedHeaders = []string{
+	"from",
+	"reply-to",
+	"to",
+	"cc",
+	"subject",
+	"date",
+	"message-id",
+	"in-reply-to",
+	"references",
+	"mime-version",
+	"content-type",
+	"content-transfer-encoding",
+	"list-id",
+	"list-unsubscribe",
+	"list-unsubscribe-post",
+}
+
+const dkimKeySize = 2048
+
+func generateDkimKey() (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, dkimKeySize)
+}
+
+// dkimDnsRecordValue returns the value of the TXT record publishing the public key
+func dkimDnsRecordValue(publicKey *rsa.PublicKey) (string, error) {
+	publicKeyDer, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	return "v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(publicKeyDer), nil
+}
+
+// parseDkimDnsRecord returns the tags of a DKIM TXT record
+func parseDkimDnsRecord(record string) map[string]string {
+	tags := make(map[string]string)
+	for tag := range strings.SplitSeq(record, ";") {
+		name, value, found := strings.Cut(tag, "=")
+		if !found {
+			continue
+		}
+		// whitespace is allowed anywhere in the base64 value of the public key
+		tags[strings.TrimSpace(name)] = strings.Join(strings.Fields(value), "")
+	}
+	return tags
+}
+
+// signDkim signs the message with the rsa-sha256 algorithm and the relaxed/relaxed canonicalization,
+// and returns the message prefixed with its DKIM-Signature header.
+// https://datatracker.ietf.org/doc/html/rfc6376
+func signDkim(message []byte, domain, selector string, privateKey *rsa.PrivateKey, now time.Time) ([]byte, error) {
+	headerSection, body, found := bytes.Cut(message, []byte("\r\n\r\n"))
+	if !found {
+		return nil, errors.New("dkim: message has no body")
+	}
+	headers := splitDkimHeaders(headerSection)
+
+	bodyHash := sha256.Sum256(canonicalizeDkimBodyRelaxed(body))
+
+	// headers are signed from the bottom up (RFC 6376 section 5.4.2)
+	signedHeadersNames := make([]string, 0, len(dkimSignedHeaders))
+	signedHeaders := make([]string, 0, len(dkimSignedHeaders))
+	for _, headerName := range dkimSignedHeaders {
+		for i := len(headers) - 1; i >= 0; i -= 1 {
+			name, _, _ := strings.Cut(headers[i], ":")
+			if strings.EqualFold(strings.TrimSpace(name), headerName) {
+				signedHeadersNames = append(signedHeadersNames, headerName)
+				signedHeaders = append(signedHeaders, headers[i])
+				break
+			}
+		}
+	}
+	if len(signedHeaders) == 0 || signedHeadersNames[0] != "from" {
+		return nil, errors.New("dkim: message has no From header")
+	}
+
+	// tags are folded so that the header stays well under the 998 characters limit of a line
+	signature := strings.Join([]string{
+		"v=1",
+		"a=rsa-sha256",
+		"c=relaxed/relaxed",
+		"d=" + domain,
+		"s=" + selector,
+		"t=" + strconv.FormatInt(now.Unix(), 10),
+		"h=" + strings.Join(signedHeadersNames, ":"),
+		"bh=" + base64.StdEncoding.EncodeToString(bodyHash[:]),
+		"b=",
+	}, ";\r\n\t")
+	signatureHeader := "DKIM-Signature: " + signature
+
+	hash := sha256.New()
+	for _, header := range signedHeaders {
+		hash.Write([]byte(canonicalizeDkimHeaderRelaxed(header)))
+		hash.Write([]byte("\r\n"))
+	}
+	// the DKIM-Signature header itself is signed without its trailing CRLF
+	hash.Write([]byte(canonicalizeDkimHeaderRelaxed(signatureHeader)))
+
+	signatureBytes, err := rsa.SignPKCS1v15(nil, privateKey, crypto.SHA256, hash.Sum(nil))
+	if err != nil {
+		return nil, fmt.Errorf("dkim: signing message: %w", err)
+	}
+
+	signedMessage := make([]byte, 0, len(signatureHeader)+512+len(message))
+	signedMessage = append(signedMessage, signatureHeader...)
+	signedMessage = append(signedMessage, base64.StdEncoding.EncodeToString(signatureBytes)...)
+	signedMessage = append(signedMessage, "\r\n"...)
+	signedMessage = append(signedMessage, message...)
+
+	return signedMessage, nil
+}
+
+// splitDkimHeaders splits a header section into its header fields, keeping the folded lines of each field.
+func splitDkimHeaders(headerSection []byte) []string {
+	headers := make([]string, 0, 16)
+	for line := range strings.SplitSeq(string(headerSection), "\r\n") {
+		if len(headers) != 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			headers[len(headers)-1] += "\r\n" + line
+			continue
+		}
+		headers = append(headers, line)
+	}
+	return headers
+}
+
+// canonicalizeDkimHeaderRelaxed implements the "relaxed" header canonicalization algorithm
+// (RFC 6376 section 3.4.2). The returned header doesn't end with CRLF.
+func canonicalizeDkimHeaderRelaxed(header string) string {
+	name, value, _ := strings.Cut(header, ":")
+	name = strings.ToLower(strings.TrimRight(name, " \t"))
+
+	value = strings.ReplaceAll(value, "\r\n", "")
+	value = strings.Join(strings.FieldsFunc(value, isDkimWhitespace), " ")
+
+	return name + ":" + value
+}
+
+// canonicalizeDkimBodyRelaxed implements the "relaxed" body canonicalization algorithm
+// (RFC 6376 section 3.4.4).
+func canonicalizeDkimBodyRelaxed(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		var compactedLine strings.Builder
+		compactedLine.Grow(len(line))
+		previousIsWhitespace := false
+		for _, char := range line {
+			if isDkimWhitespace(char) {
+				if !previousIsWhitespace {
+					compactedLine.WriteByte(' ')
+				}
+				previousIsWhitespace = true
+				continue
+			}
+			previousIsWhitespace = false
+			compactedLine.WriteRune(char)
+		}
+		lines[i] = compactedLine.String()
+	}
+
+	// empty lines at the end of the body are ignored
+	for len(lines) != 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return []byte{}
+	}
+
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+func isDkimWhitespace(char rune) bool {
+	return char == ' ' || char == '\t'
+}
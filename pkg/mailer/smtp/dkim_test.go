@@ -0,0 +1,134 @@
+package smtp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCanonicalizeDkimRelaxed(t *testing.T) {
+	// example of RFC 6376 section 3.4.5
+	headers := splitDkimHeaders([]byte("A: X\r\nB : Y\t\r\n\tZ  "))
+	if len(headers) != 2 {
+		t.Fatalf("splitDkimHeaders: expected 2 headers, got %d", len(headers))
+	}
+
+	expectedHeaders := []string{"a:X", "b:Y Z"}
+	for i, header := range headers {
+		canonicalized := canonicalizeDkimHeaderRelaxed(header)
+		if canonicalized != expectedHeaders[i] {
+			t.Errorf("canonicalizeDkimHeaderRelaxed(%q): expected %q, got %q", header, expectedHeaders[i], canonicalized)
+		}
+	}
+
+	body := canonicalizeDkimBodyRelaxed([]byte(" C \r\nD \t E\r\n\r\n\r\n"))
+	expectedBody := " C\r\nD E\r\n"
+	if string(body) != expectedBody {
+		t.Errorf("canonicalizeDkimBodyRelaxed: expected %q, got %q", expectedBody, string(body))
+	}
+
+	emptyBody := canonicalizeDkimBodyRelaxed([]byte("\r\n\r\n"))
+	if len(emptyBody) != 0 {
+		t.Errorf("canonicalizeDkimBodyRelaxed: expected empty body, got %q", string(emptyBody))
+	}
+}
+
+func TestSignDkim(t *testing.T) {
+	privateKey, err := generateDkimKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte("From: Sylvain <hello@example.com>\r\n" +
+		"To: test@example.org\r\n" +
+		"Subject: Hello\r\n" +
+		"X-Not-Signed: true\r\n" +
+		"\r\n" +
+		"Hello  World\r\n\r\n")
+
+	signedMessage, err := signDkim(message, "example.com", "mdninja", privateKey, time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasSuffix(signedMessage, message) {
+		t.Fatal("the original message should be kept after the DKIM-Signature header")
+	}
+
+	headerSection, _, _ := bytes.Cut(signedMessage, []byte("\r\n\r\n"))
+	signatureHeader := splitDkimHeaders(headerSection)[0]
+	if !strings.HasPrefix(signatureHeader, "DKIM-Signature: ") {
+		t.Fatalf("the first header should be DKIM-Signature, got: %s", signatureHeader)
+	}
+
+	tags := parseDkimDnsRecord(strings.TrimPrefix(canonicalizeDkimHeaderRelaxed(signatureHeader), "dkim-signature:"))
+	expectedTags := map[string]string{
+		"v": "1",
+		"a": "rsa-sha256",
+		"c": "relaxed/relaxed",
+		"d": "example.com",
+		"s": "mdninja",
+		"t": "1700000000",
+		"h": "from:to:subject",
+	}
+	for name, expectedValue := range expectedTags {
+		if tags[name] != expectedValue {
+			t.Errorf("tag %s: expected %q, got %q", name, expectedValue, tags[name])
+		}
+	}
+
+	bodyHash := sha256.Sum256([]byte("Hello World\r\n"))
+	if tags["bh"] != base64.StdEncoding.EncodeToString(bodyHash[:]) {
+		t.Errorf("bh is not valid: %s", tags["bh"])
+	}
+
+	// verify the signature as a receiver would do (RFC 6376 section 6.1.3)
+	signature, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signatureHeaderWithoutB := signatureHeader[:strings.LastIndex(signatureHeader, "b=")+2]
+	hash := sha256.New()
+	hash.Write([]byte("from:Sylvain <hello@example.com>\r\nto:test@example.org\r\nsubject:Hello\r\n"))
+	hash.Write([]byte(canonicalizeDkimHeaderRelaxed(signatureHeaderWithoutB)))
+	err = rsa.VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, hash.Sum(nil), signature)
+	if err != nil {
+		t.Errorf("signature is not valid: %s", err)
+	}
+}
+
+func TestDkimDnsRecordValue(t *testing.T) {
+	privateKey, err := generateDkimKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := dkimDnsRecordValue(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// DNS providers may split long records and add whitespace
+	tags := parseDkimDnsRecord(strings.Replace(record, "p=", "p= ", 1) + " ")
+	if tags["v"] != "DKIM1" || tags["k"] != "rsa" {
+		t.Errorf("record is not valid: %s", record)
+	}
+
+	publicKeyDer, err := base64.StdEncoding.DecodeString(tags["p"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(publicKeyDer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !privateKey.PublicKey.Equal(publicKey) {
+		t.Error("public key of the record doesn't match the private key")
+	}
+}
@@ -0,0 +1,219 @@
+package smtp
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"markdown.ninja/pkg/mailer"
+)
+
+// A DKIM key as stored in DB
+type dkimKey struct {
+	Domain              string    `db:"domain"`
+	CreatedAt           time.Time `db:"created_at"`
+	UpdatedAt           time.Time `db:"updated_at"`
+	Selector            string    `db:"selector"`
+	EncryptedPrivateKey []byte    `db:"encrypted_private_key"`
+}
+
+// cachedDkimKey is nil when the domain has no DKIM key
+type cachedDkimKey struct {
+	selector   string
+	privateKey *rsa.PrivateKey
+}
+
+var errDkimKeyNotFound = errors.New("smtp: DKIM key not found")
+
+// AddDomain generates the DKIM key of the domain. If the domain already has a key, it is kept so
+// that the DNS records that have already been published remain valid.
+func (smtpMailer *SmtpMailer) AddDomain(ctx context.Context, domain string) (ret mailer.Domain, err error) {
+	var privateKey *rsa.PrivateKey
+	key, err := smtpMailer.findDkimKey(ctx, domain)
+	if err == nil {
+		privateKey, err = smtpMailer.decryptDkimKey(ctx, key)
+		if err != nil {
+			return
+		}
+	} else if errors.Is(err, errDkimKeyNotFound) {
+		privateKey, err = generateDkimKey()
+		if err != nil {
+			return ret, fmt.Errorf("smtp: error generating DKIM key: %w", err)
+		}
+
+		var privateKeyDer, encryptedPrivateKey []byte
+		privateKeyDer, err = x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return ret, fmt.Errorf("smtp: error encoding DKIM key: %w", err)
+		}
+
+		encryptedPrivateKey, err = smtpMailer.kms.Encrypt(ctx, privateKeyDer, []byte(domain))
+		if err != nil {
+			return ret, fmt.Errorf("smtp: error encrypting DKIM key: %w", err)
+		}
+
+		now := time.Now().UTC()
+		key = dkimKey{
+			Domain:              domain,
+			CreatedAt:           now,
+			UpdatedAt:           now,
+			Selector:            smtpMailer.dkimSelector,
+			EncryptedPrivateKey: encryptedPrivateKey,
+		}
+		err = smtpMailer.createDkimKey(ctx, key)
+		if err != nil {
+			return ret, err
+		}
+		smtpMailer.dkimKeysCache.Delete(domain)
+	} else {
+		return
+	}
+
+	dnsRecordValue, err := dkimDnsRecordValue(&privateKey.PublicKey)
+	if err != nil {
+		return ret, fmt.Errorf("smtp: error encoding DKIM public key: %w", err)
+	}
+
+	ret = mailer.Domain{
+		Domain: domain,
+		DnsRecords: []mailer.DnsRecord{
+			{
+				Host: fmt.Sprintf("%s._domainkey.%s", key.Selector, domain),
+				Type: "TXT",
+				Val:  dnsRecordValue,
+			},
+		},
+	}
+	return ret, nil
+}
+
+func (smtpMailer *SmtpMailer) RemoveDomain(ctx context.Context, domain string) error {
+	const query = "DELETE FROM smtp_dkim_keys WHERE domain = $1"
+
+	_, err := smtpMailer.db.Exec(ctx, query, domain)
+	if err != nil {
+		return fmt.Errorf("smtp: error deleting DKIM key: %w", err)
+	}
+
+	smtpMailer.dkimKeysCache.Delete(domain)
+	return nil
+}
+
+// VerifyDomain checks that the public DKIM key of the domain is published in its DNS records
+func (smtpMailer *SmtpMailer) VerifyDomain(ctx context.Context, domain string) (verified bool, err error) {
+	key, err := smtpMailer.findDkimKey(ctx, domain)
+	if err != nil {
+		if errors.Is(err, errDkimKeyNotFound) {
+			err = nil
+		}
+		return false, err
+	}
+
+	privateKey, err := smtpMailer.decryptDkimKey(ctx, key)
+	if err != nil {
+		return false, err
+	}
+
+	expectedRecord, err := dkimDnsRecordValue(&privateKey.PublicKey)
+	if err != nil {
+		return false, fmt.Errorf("smtp: error encoding DKIM public key: %w", err)
+	}
+	expectedPublicKey := parseDkimDnsRecord(expectedRecord)["p"]
+
+	// LookupTXT concatenates the strings of records that are longer than 255 characters
+	records, err := smtpMailer.dnsResolver.LookupTXT(ctx, fmt.Sprintf("%s._domainkey.%s", key.Selector, domain))
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("smtp: error resolving DKIM record: %w", err)
+	}
+
+	for _, record := range records {
+		if parseDkimDnsRecord(record)["p"] == expectedPublicKey {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// getDkimKey returns the cached DKIM key of the domain. key is nil if the domain has no key.
+func (smtpMailer *SmtpMailer) getDkimKey(ctx context.Context, domain string) (key *cachedDkimKey, err error) {
+	if cachedKey := smtpMailer.dkimKeysCache.Get(domain); cachedKey != nil {
+		return cachedKey.Value(), nil
+	}
+
+	dbKey, err := smtpMailer.findDkimKey(ctx, domain)
+	if err == nil {
+		var privateKey *rsa.PrivateKey
+		privateKey, err = smtpMailer.decryptDkimKey(ctx, dbKey)
+		if err != nil {
+			return nil, err
+		}
+		key = &cachedDkimKey{
+			selector:   dbKey.Selector,
+			privateKey: privateKey,
+		}
+	} else if !errors.Is(err, errDkimKeyNotFound) {
+		return nil, err
+	}
+
+	// keys are cached for a short time because they may be removed by other instances of the server
+	smtpMailer.dkimKeysCache.Set(domain, key, 5*time.Minute)
+	return key, nil
+}
+
+func (smtpMailer *SmtpMailer) decryptDkimKey(ctx context.Context, key dkimKey) (*rsa.PrivateKey, error) {
+	privateKeyDer, err := smtpMailer.kms.Decrypt(ctx, key.EncryptedPrivateKey, []byte(key.Domain))
+	if err != nil {
+		return nil, fmt.Errorf("smtp: error decrypting DKIM key of %s: %w", key.Domain, err)
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(privateKeyDer)
+	if err != nil {
+		return nil, fmt.Errorf("smtp: error parsing DKIM key of %s: %w", key.Domain, err)
+	}
+
+	rsaPrivateKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("smtp: DKIM key of %s is not a RSA key", key.Domain)
+	}
+
+	return rsaPrivateKey, nil
+}
+
+func (smtpMailer *SmtpMailer) findDkimKey(ctx context.Context, domain string) (key dkimKey, err error) {
+	const query = "SELECT * FROM smtp_dkim_keys WHERE domain = $1"
+
+	err = smtpMailer.db.Get(ctx, &key, query, domain)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errDkimKeyNotFound
+		} else {
+			err = fmt.Errorf("smtp: error finding DKIM key: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (smtpMailer *SmtpMailer) createDkimKey(ctx context.Context, key dkimKey) error {
+	const query = `INSERT INTO smtp_dkim_keys (domain, created_at, updated_at, selector, encrypted_private_key)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := smtpMailer.db.Exec(ctx, query, key.Domain, key.CreatedAt, key.UpdatedAt, key.Selector,
+		key.EncryptedPrivateKey)
+	if err != nil {
+		return fmt.Errorf("smtp: error saving DKIM key: %w", err)
+	}
+
+	return nil
+}
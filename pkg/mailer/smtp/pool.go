@@ -0,0 +1,168 @@
+package smtp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+const (
+	dialTimeout = 10 * time.Second
+	// maximum duration of a SMTP transaction, from MAIL FROM to the end of DATA
+	transactionTimeout = 60 * time.Second
+	// many SMTP servers close idle connections well before the 5 minutes recommended by RFC 5321
+	// (section 4.5.3.2.7) so we don't reuse connections that have been idle for too long.
+	maxIdleDuration = 30 * time.Second
+)
+
+// connectionPool keeps authenticated connections to the SMTP server open so that sending an email
+// doesn't require a new TCP + TLS handshake and authentication.
+type connectionPool struct {
+	config    Config
+	tlsConfig *tls.Config
+	// slots limits the number of connections that are used at the same time
+	slots chan struct{}
+	idle  chan *connection
+}
+
+type connection struct {
+	client     *smtp.Client
+	conn       net.Conn
+	lastUsedAt time.Time
+	// reused is true if the connection has been taken from the idle connections
+	reused bool
+}
+
+func newConnectionPool(config Config) *connectionPool {
+	return &connectionPool{
+		config: config,
+		tlsConfig: &tls.Config{
+			ServerName: config.Host,
+			// many SMTP servers don't support TLS 1.3 yet
+			MinVersion: tls.VersionTLS12,
+		},
+		slots: make(chan struct{}, config.PoolSize),
+		idle:  make(chan *connection, config.PoolSize),
+	}
+}
+
+// get returns an idle connection or opens a new one. The connection must be returned to the pool
+// with put or discard.
+func (pool *connectionPool) get(ctx context.Context) (*connection, error) {
+	select {
+	case pool.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if conn := pool.getIdle(); conn != nil {
+		conn.reused = true
+		conn.conn.SetDeadline(time.Now().Add(transactionTimeout))
+		return conn, nil
+	}
+
+	conn, err := pool.dial(ctx)
+	if err != nil {
+		<-pool.slots
+		return nil, err
+	}
+	return conn, nil
+}
+
+// getIdle returns an idle connection that is still fresh, or nil if there is none
+func (pool *connectionPool) getIdle() *connection {
+	for {
+		select {
+		case conn := <-pool.idle:
+			if time.Since(conn.lastUsedAt) <= maxIdleDuration {
+				return conn
+			}
+			conn.client.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// put returns a connection in a clean state to the pool
+func (pool *connectionPool) put(conn *connection) {
+	conn.lastUsedAt = time.Now()
+	conn.reused = false
+	select {
+	case pool.idle <- conn:
+	default:
+		conn.client.Quit()
+	}
+	<-pool.slots
+}
+
+// discard closes a connection that is in an unknown state (e.g. after a network error)
+func (pool *connectionPool) discard(conn *connection) {
+	conn.client.Close()
+	<-pool.slots
+}
+
+func (pool *connectionPool) dial(ctx context.Context) (ret *connection, err error) {
+	address := net.JoinHostPort(pool.config.Host, strconv.Itoa(int(pool.config.Port)))
+	dialer := &net.Dialer{Timeout: dialTimeout}
+
+	var conn net.Conn
+	if pool.config.Tls == TlsModeTls {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: pool.tlsConfig}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("smtp: error connecting to %s: %w", address, err)
+	}
+	conn.SetDeadline(time.Now().Add(transactionTimeout))
+
+	client, err := smtp.NewClient(conn, pool.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp: error connecting to %s: %w", address, err)
+	}
+
+	if pool.config.HeloName != "" {
+		err = client.Hello(pool.config.HeloName)
+		if err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp: HELO: %w", err)
+		}
+	}
+
+	if pool.config.Tls == TlsModeStarttls {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, errors.New("smtp: server doesn't support STARTTLS")
+		}
+		err = client.StartTLS(pool.tlsConfig)
+		if err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp: STARTTLS: %w", err)
+		}
+	}
+
+	if pool.config.Username != "" {
+		// PlainAuth refuses to send the credentials over unencrypted connections, except to localhost
+		err = client.Auth(smtp.PlainAuth("", pool.config.Username, pool.config.Password, pool.config.Host))
+		if err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp: AUTH: %w", err)
+		}
+	}
+
+	ret = &connection{
+		client:     client,
+		conn:       conn,
+		lastUsedAt: time.Now(),
+		reused:     false,
+	}
+	return ret, nil
+}
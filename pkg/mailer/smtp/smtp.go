@@ -0,0 +1,248 @@
+package smtp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/email"
+	"github.com/skerkour/stdx-go/memorycache"
+	"markdown.ninja/pkg/kms"
+	"markdown.ninja/pkg/mailer"
+)
+
+// SmtpMailer implements the `Mailer` interface to send emails through any SMTP server.
+// Messages are signed with the DKIM keys of the domains added with AddDomain, and the addresses
+// permanently rejected by the server are kept in a local suppression list.
+type SmtpMailer struct {
+	db            db.DB
+	kms           *kms.Kms
+	dnsResolver   *net.Resolver
+	pool          *connectionPool
+	dkimSelector  string
+	dkimKeysCache *memorycache.Cache[string, *cachedDkimKey]
+}
+
+type TlsMode string
+
+const (
+	// The connection is upgraded to TLS with the STARTTLS command. Usually on port 587.
+	TlsModeStarttls TlsMode = "starttls"
+	// Implicit TLS. Usually on port 465.
+	TlsModeTls TlsMode = "tls"
+	// The connection is not encrypted. Should only be used with a relay on the local network.
+	TlsModeNone TlsMode = "none"
+)
+
+const (
+	DefaultPoolSize     = 4
+	DefaultDkimSelector = "mdninja"
+)
+
+type Config struct {
+	Host     string
+	Port     uint16
+	Username string
+	Password string
+	Tls      TlsMode
+	// The name sent with the EHLO command. default: localhost
+	HeloName string
+	// Maximum number of simultaneous connections to the server. default: 4
+	PoolSize int
+	// default: mdninja
+	DkimSelector string
+}
+
+// ensure that SmtpMailer satisfies the Mailer interface
+var _ mailer.Mailer = (*SmtpMailer)(nil)
+
+// NewSmtpMailer returns a new SMTP Mailer. The DKIM keys and the suppressions are stored in db.
+func NewSmtpMailer(config Config, db db.DB, kms *kms.Kms, dnsResolver *net.Resolver) (*SmtpMailer, error) {
+	if config.Host == "" {
+		return nil, errors.New("smtp: host is empty")
+	}
+
+	switch config.Tls {
+	case TlsModeStarttls, TlsModeTls, TlsModeNone:
+	default:
+		return nil, fmt.Errorf("smtp: %s is not a valid TLS mode. Valid values are: [%s, %s, %s]",
+			config.Tls, TlsModeStarttls, TlsModeTls, TlsModeNone)
+	}
+
+	if config.PoolSize <= 0 {
+		config.PoolSize = DefaultPoolSize
+	}
+
+	dkimSelector := config.DkimSelector
+	if dkimSelector == "" {
+		dkimSelector = DefaultDkimSelector
+	}
+
+	if dnsResolver == nil {
+		dnsResolver = net.DefaultResolver
+	}
+
+	return &SmtpMailer{
+		db:           db,
+		kms:          kms,
+		dnsResolver:  dnsResolver,
+		pool:         newConnectionPool(config),
+		dkimSelector: dkimSelector,
+		dkimKeysCache: memorycache.New(
+			memorycache.WithTTL[string, *cachedDkimKey](5*time.Minute),
+			memorycache.WithCapacity[string, *cachedDkimKey](10_000),
+		),
+	}, nil
+}
+
+func (smtpMailer *SmtpMailer) SendTransactionnal(ctx context.Context, email email.Email) error {
+	return smtpMailer.send(ctx, email)
+}
+
+func (smtpMailer *SmtpMailer) SendBroadcast(ctx context.Context, email email.Email) error {
+	return smtpMailer.send(ctx, email)
+}
+
+func (smtpMailer *SmtpMailer) send(ctx context.Context, message email.Email) error {
+	recipients := make([]string, 0, len(message.To)+len(message.Cc)+len(message.Bcc))
+	for _, addresses := range [][]mail.Address{message.To, message.Cc, message.Bcc} {
+		for _, address := range addresses {
+			recipients = append(recipients, address.Address)
+		}
+	}
+	if len(recipients) == 0 {
+		return errors.New("smtp: email has no recipient")
+	}
+
+	suppressed, err := smtpMailer.findSuppressedEmails(ctx, recipients)
+	if err != nil {
+		return err
+	}
+	recipients = slices.DeleteFunc(recipients, func(recipient string) bool {
+		return suppressed[strings.ToLower(recipient)]
+	})
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	rawEmail, err := message.Bytes()
+	if err != nil {
+		return fmt.Errorf("smtp: error getting raw email: %w", err)
+	}
+
+	_, fromDomain, _ := strings.Cut(message.From.Address, "@")
+	fromDomain = strings.ToLower(fromDomain)
+	dkimKey, err := smtpMailer.getDkimKey(ctx, fromDomain)
+	if err != nil {
+		return err
+	}
+	// emails sent from domains without key are sent unsigned: the SMTP server may sign them itself
+	if dkimKey != nil {
+		rawEmail, err = signDkim(rawEmail, fromDomain, dkimKey.selector, dkimKey.privateKey, time.Now())
+		if err != nil {
+			return fmt.Errorf("smtp: %w", err)
+		}
+	}
+
+	conn, err := smtpMailer.pool.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = smtpMailer.sendWithConnection(ctx, conn, message.From.Address, recipients, rawEmail)
+	if err != nil && conn.reused && !isSmtpReply(err) {
+		// the server may have closed the idle connection in the meantime, so we retry once with a new one
+		smtpMailer.pool.discard(conn)
+		conn, err = smtpMailer.pool.get(ctx)
+		if err != nil {
+			return err
+		}
+		err = smtpMailer.sendWithConnection(ctx, conn, message.From.Address, recipients, rawEmail)
+	}
+	if err != nil {
+		if isSmtpReply(err) && conn.client.Reset() == nil {
+			smtpMailer.pool.put(conn)
+		} else {
+			smtpMailer.pool.discard(conn)
+		}
+		return fmt.Errorf("smtp: error sending email: %w", err)
+	}
+
+	smtpMailer.pool.put(conn)
+	return nil
+}
+
+func (smtpMailer *SmtpMailer) sendWithConnection(ctx context.Context, conn *connection, from string, recipients []string, rawEmail []byte) (err error) {
+	err = conn.client.Mail(from)
+	if err != nil {
+		return err
+	}
+
+	acceptedRecipients := 0
+	for _, recipient := range recipients {
+		err = conn.client.Rcpt(recipient)
+		if err != nil {
+			if !isPermanentRecipientFailure(err) {
+				return err
+			}
+			err = smtpMailer.addSuppression(ctx, recipient, err.Error())
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		acceptedRecipients += 1
+	}
+
+	if acceptedRecipients == 0 {
+		return conn.client.Reset()
+	}
+
+	writer, err := conn.client.Data()
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(rawEmail)
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// isSmtpReply returns true if err is a reply of the server, and thus the connection can still be used
+func isSmtpReply(err error) bool {
+	var protocolErr *textproto.Error
+	return errors.As(err, &protocolErr)
+}
+
+// isPermanentRecipientFailure returns true if the server rejected the recipient because the mailbox
+// doesn't exist (RFC 5321 section 4.2.3). Other permanent failures (e.g. the sender being blocked
+// as spam) are not the fault of the recipient, so their address must not be suppressed.
+func isPermanentRecipientFailure(err error) bool {
+	var protocolErr *textproto.Error
+	if !errors.As(err, &protocolErr) {
+		return false
+	}
+
+	// when the server sends enhanced status codes (RFC 3463), only the addressing errors (5.1.X)
+	// are caused by the recipient
+	if strings.HasPrefix(protocolErr.Msg, "5.") {
+		return strings.HasPrefix(protocolErr.Msg, "5.1.")
+	}
+
+	switch protocolErr.Code {
+	case 550, 551, 553:
+		return true
+	default:
+		return false
+	}
+}
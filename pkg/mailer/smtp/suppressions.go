@@ -0,0 +1,85 @@
+package smtp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"markdown.ninja/pkg/mailer"
+)
+
+// A suppressed email address as stored in DB. Emails are not sent to suppressed addresses.
+type suppression struct {
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+	Reason    string    `db:"reason"`
+}
+
+// GetSuppressions returns the addresses that have been suppressed because they were permanently
+// rejected by the SMTP server.
+func (smtpMailer *SmtpMailer) GetSuppressions(ctx context.Context) (suppressions []mailer.Suppression, err error) {
+	const query = "SELECT * FROM smtp_suppressions ORDER BY created_at"
+
+	dbSuppressions := make([]suppression, 0)
+	err = smtpMailer.db.Select(ctx, &dbSuppressions, query)
+	if err != nil {
+		return []mailer.Suppression{}, fmt.Errorf("smtp: error listing suppressions: %w", err)
+	}
+
+	suppressions = make([]mailer.Suppression, len(dbSuppressions))
+	for i, dbSuppression := range dbSuppressions {
+		suppressions[i] = mailer.Suppression{
+			Email: dbSuppression.Email,
+		}
+	}
+
+	return suppressions, nil
+}
+
+func (smtpMailer *SmtpMailer) DeleteSuppression(ctx context.Context, email string) (err error) {
+	const query = "DELETE FROM smtp_suppressions WHERE email = $1"
+
+	_, err = smtpMailer.db.Exec(ctx, query, strings.ToLower(email))
+	if err != nil {
+		return fmt.Errorf("smtp: error deleting suppression (%s): %w", email, err)
+	}
+
+	return nil
+}
+
+func (smtpMailer *SmtpMailer) addSuppression(ctx context.Context, email, reason string) (err error) {
+	const query = `INSERT INTO smtp_suppressions (email, created_at, reason)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (email) DO NOTHING`
+
+	_, err = smtpMailer.db.Exec(ctx, query, strings.ToLower(email), time.Now().UTC(), reason)
+	if err != nil {
+		return fmt.Errorf("smtp: error adding suppression (%s): %w", email, err)
+	}
+
+	return nil
+}
+
+// findSuppressedEmails returns the set of the (lowercased) addresses that are suppressed among emails
+func (smtpMailer *SmtpMailer) findSuppressedEmails(ctx context.Context, emails []string) (suppressed map[string]bool, err error) {
+	const query = "SELECT email FROM smtp_suppressions WHERE email = ANY($1)"
+
+	lowercasedEmails := make([]string, len(emails))
+	for i, email := range emails {
+		lowercasedEmails[i] = strings.ToLower(email)
+	}
+
+	suppressedEmails := make([]string, 0)
+	err = smtpMailer.db.Select(ctx, &suppressedEmails, query, lowercasedEmails)
+	if err != nil {
+		return nil, fmt.Errorf("smtp: error finding suppressions: %w", err)
+	}
+
+	suppressed = make(map[string]bool, len(suppressedEmails))
+	for _, email := range suppressedEmails {
+		suppressed[email] = true
+	}
+
+	return suppressed, nil
+}
@@ -0,0 +1,795 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/log/slogx"
+)
+
+// FakeCheckoutPath is the path of the local checkout page served by FakeProvider.CheckoutHandler
+const FakeCheckoutPath = "/__markdown_ninja/payments/checkout/"
+
+var ErrCheckoutSessionIsNotOpen = errors.New("payments: checkout session is not open")
+
+// FakeProvider is a Provider that processes payments locally, without any payment being made:
+// customers are redirected to a local checkout page where they can either pay or cancel, and the
+// events are dispatched directly to the EventHandler instead of being sent through webhooks.
+//
+// Everything is kept in memory and is lost when the process exits, thus it's only suited to
+// development, integration tests and instances where all the products are free.
+type FakeProvider struct {
+	baseURL      string
+	logger       *slog.Logger
+	eventHandler EventHandler
+
+	mutex            sync.Mutex
+	checkoutSessions map[string]*fakeCheckoutSession
+	payments         map[string]Payment
+	subscriptions    map[string]Subscription
+	refunds          map[string]Refund
+	customers        map[string]*fakeCustomer
+	invoices         map[string]*fakeInvoice
+}
+
+type fakeCustomer struct {
+	customer               Customer
+	defaultPaymentMethodID *string
+}
+
+type fakeInvoice struct {
+	invoice Invoice
+	amount  int64
+}
+
+type fakeCheckoutSession struct {
+	session        CheckoutSession
+	input          CreateCheckoutSessionInput
+	paymentID      *string
+	subscriptionID *string
+}
+
+// NewFakeProvider returns a FakeProvider serving its checkout page under baseURL (e.g. the URL of
+// the webapp).
+func NewFakeProvider(baseURL string, logger *slog.Logger) *FakeProvider {
+	return &FakeProvider{
+		baseURL:          strings.TrimSuffix(baseURL, "/"),
+		logger:           logger,
+		eventHandler:     nil,
+		checkoutSessions: make(map[string]*fakeCheckoutSession),
+		payments:         make(map[string]Payment),
+		subscriptions:    make(map[string]Subscription),
+		refunds:          make(map[string]Refund),
+		customers:        make(map[string]*fakeCustomer),
+		invoices:         make(map[string]*fakeInvoice),
+	}
+}
+
+// SetEventHandler sets the handler that receives the simulated webhook events
+func (provider *FakeProvider) SetEventHandler(handler EventHandler) {
+	provider.mutex.Lock()
+	provider.eventHandler = handler
+	provider.mutex.Unlock()
+}
+
+func (provider *FakeProvider) CreateCustomer(ctx context.Context, input CreateCustomerInput) (Customer, error) {
+	customer := Customer{
+		ID:       fakeID("cus"),
+		Name:     input.Name,
+		Email:    input.Email,
+		TaxIDs:   []TaxID{},
+		Metadata: maps.Clone(input.Metadata),
+	}
+	if input.Address != nil {
+		customer.Address = *input.Address
+	}
+	for _, taxID := range input.TaxIDs {
+		customer.TaxIDs = append(customer.TaxIDs, TaxID{ID: fakeID("txi"), Type: taxID.Type, Value: taxID.Value})
+	}
+
+	provider.mutex.Lock()
+	provider.customers[customer.ID] = &fakeCustomer{customer: customer}
+	provider.mutex.Unlock()
+
+	return customer, nil
+}
+
+func (provider *FakeProvider) GetCustomer(ctx context.Context, customerID string) (Customer, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	customer, exists := provider.customers[customerID]
+	if !exists {
+		return Customer{}, ErrCustomerNotFound
+	}
+
+	ret := customer.customer
+	ret.TaxIDs = append([]TaxID{}, customer.customer.TaxIDs...)
+	ret.Subscriptions = []Subscription{}
+	for _, subscription := range provider.subscriptions {
+		if subscription.CustomerID != nil && *subscription.CustomerID == customerID &&
+			subscription.Status != SubscriptionStatusCanceled {
+			ret.Subscriptions = append(ret.Subscriptions, subscription)
+		}
+	}
+
+	return ret, nil
+}
+
+func (provider *FakeProvider) UpdateCustomer(ctx context.Context, customerID string, input UpdateCustomerInput) error {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	customer, exists := provider.customers[customerID]
+	if !exists {
+		return ErrCustomerNotFound
+	}
+	customer.customer.Name = input.Name
+	customer.customer.Email = input.Email
+	if input.Address != nil {
+		customer.customer.Address = *input.Address
+	} else {
+		customer.customer.Address.Country = input.Country
+	}
+
+	return nil
+}
+
+// GetDefaultPaymentMethod returns the payment method saved when the customer completed a
+// subscription checkout session, or set with SetDefaultPaymentMethod.
+func (provider *FakeProvider) GetDefaultPaymentMethod(ctx context.Context, customerID string) (PaymentMethod, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	customer, exists := provider.customers[customerID]
+	if !exists {
+		return PaymentMethod{}, ErrCustomerNotFound
+	}
+	if customer.defaultPaymentMethodID == nil {
+		return PaymentMethod{}, ErrPaymentMethodNotFound
+	}
+
+	return PaymentMethod{ID: *customer.defaultPaymentMethodID, CustomerID: &customer.customer.ID}, nil
+}
+
+func (provider *FakeProvider) SetDefaultPaymentMethod(ctx context.Context, customerID string, paymentMethodID string) error {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	customer, exists := provider.customers[customerID]
+	if !exists {
+		return ErrCustomerNotFound
+	}
+	customer.defaultPaymentMethodID = &paymentMethodID
+
+	return nil
+}
+
+// CreateCustomerPortalSession returns returnURL, as there is no customer portal in test mode
+func (provider *FakeProvider) CreateCustomerPortalSession(ctx context.Context, customerID string, returnURL string) (string, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	if _, exists := provider.customers[customerID]; !exists {
+		return "", ErrCustomerNotFound
+	}
+
+	return returnURL, nil
+}
+
+func (provider *FakeProvider) ListTaxIDs(ctx context.Context, customerID string) ([]TaxID, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	customer, exists := provider.customers[customerID]
+	if !exists {
+		return nil, ErrCustomerNotFound
+	}
+
+	return append([]TaxID{}, customer.customer.TaxIDs...), nil
+}
+
+func (provider *FakeProvider) CreateTaxID(ctx context.Context, customerID string, input CreateTaxIDInput) (TaxID, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	customer, exists := provider.customers[customerID]
+	if !exists {
+		return TaxID{}, ErrCustomerNotFound
+	}
+	taxID := TaxID{ID: fakeID("txi"), Type: input.Type, Value: input.Value}
+	customer.customer.TaxIDs = append(customer.customer.TaxIDs, taxID)
+
+	return taxID, nil
+}
+
+func (provider *FakeProvider) DeleteTaxID(ctx context.Context, customerID string, taxIDID string) error {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	customer, exists := provider.customers[customerID]
+	if !exists {
+		return ErrCustomerNotFound
+	}
+	customer.customer.TaxIDs = slices.DeleteFunc(customer.customer.TaxIDs, func(taxID TaxID) bool {
+		return taxID.ID == taxIDID
+	})
+
+	return nil
+}
+
+func (provider *FakeProvider) CreateCheckoutSession(ctx context.Context, input CreateCheckoutSessionInput) (CheckoutSession, error) {
+	if len(input.LineItems) == 0 {
+		return CheckoutSession{}, errors.New("payments.fake: line items are empty")
+	}
+
+	checkoutSessionID := fakeID("cs")
+	checkoutSession := &fakeCheckoutSession{
+		session: CheckoutSession{
+			ID:         checkoutSessionID,
+			URL:        provider.baseURL + FakeCheckoutPath + checkoutSessionID,
+			Mode:       input.Mode,
+			Status:     CheckoutSessionStatusOpen,
+			CustomerID: input.CustomerID,
+			LineItems:  []LineItem{},
+			Metadata:   maps.Clone(input.Metadata),
+		},
+		input: input,
+	}
+
+	provider.mutex.Lock()
+	provider.checkoutSessions[checkoutSessionID] = checkoutSession
+	provider.mutex.Unlock()
+
+	return checkoutSession.session, nil
+}
+
+func (provider *FakeProvider) GetCheckoutSession(ctx context.Context, checkoutSessionID string) (CheckoutSession, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	checkoutSession, exists := provider.checkoutSessions[checkoutSessionID]
+	if !exists {
+		return CheckoutSession{}, ErrCheckoutSessionNotFound
+	}
+
+	return provider.hydrateCheckoutSession(checkoutSession), nil
+}
+
+func (provider *FakeProvider) GetPayment(ctx context.Context, paymentID string) (Payment, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	payment, exists := provider.payments[paymentID]
+	if !exists {
+		return Payment{}, ErrPaymentNotFound
+	}
+
+	return payment, nil
+}
+
+// CreateRefund creates a refund that immediately succeeds
+func (provider *FakeProvider) CreateRefund(ctx context.Context, input CreateRefundInput) (Refund, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	payment, exists := provider.payments[input.PaymentID]
+	if !exists {
+		return Refund{}, ErrPaymentNotFound
+	}
+	if input.Amount <= 0 || input.Amount > payment.Amount {
+		return Refund{}, fmt.Errorf("payments.fake: refund amount (%d) is not valid", input.Amount)
+	}
+
+	refund := Refund{
+		ID:     fakeID("re"),
+		Status: RefundStatusSucceeded,
+	}
+	provider.refunds[refund.ID] = refund
+
+	return refund, nil
+}
+
+func (provider *FakeProvider) GetRefund(ctx context.Context, refundID string) (Refund, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	refund, exists := provider.refunds[refundID]
+	if !exists {
+		return Refund{}, ErrRefundNotFound
+	}
+
+	return refund, nil
+}
+
+// CreateSubscription creates an active subscription and asynchronously dispatches a
+// customer.subscription.created event, as the caller may hold locks that the EventHandler needs.
+func (provider *FakeProvider) CreateSubscription(ctx context.Context, input CreateSubscriptionInput) (Subscription, error) {
+	now := time.Now().UTC()
+
+	provider.mutex.Lock()
+	if _, exists := provider.customers[input.CustomerID]; !exists {
+		provider.mutex.Unlock()
+		return Subscription{}, ErrCustomerNotFound
+	}
+	subscription := Subscription{
+		ID:                fakeID("sub"),
+		Status:            SubscriptionStatusActive,
+		CustomerID:        &input.CustomerID,
+		Items:             newFakeSubscriptionItems(input.Items),
+		StartedAt:         &now,
+		CancelAtPeriodEnd: false,
+		CurrentPeriodEnd:  new(now.AddDate(0, 1, 0)),
+		CanceledAt:        nil,
+		Metadata:          maps.Clone(input.Metadata),
+	}
+	provider.subscriptions[subscription.ID] = subscription
+	provider.mutex.Unlock()
+
+	provider.dispatchEventAsync(Event{
+		ID:           fakeID("evt"),
+		Type:         EventTypeSubscriptionCreated,
+		Subscription: &subscription,
+	})
+
+	return subscription, nil
+}
+
+func (provider *FakeProvider) GetSubscription(ctx context.Context, subscriptionID string) (Subscription, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	subscription, exists := provider.subscriptions[subscriptionID]
+	if !exists {
+		return Subscription{}, ErrSubscriptionNotFound
+	}
+
+	return subscription, nil
+}
+
+// UpdateSubscription replaces the items of the subscription and asynchronously dispatches a
+// customer.subscription.updated event. Nothing is invoiced.
+func (provider *FakeProvider) UpdateSubscription(ctx context.Context, subscriptionID string, input UpdateSubscriptionInput) (Subscription, error) {
+	provider.mutex.Lock()
+	subscription, exists := provider.subscriptions[subscriptionID]
+	if !exists {
+		provider.mutex.Unlock()
+		return Subscription{}, ErrSubscriptionNotFound
+	}
+	subscription.Items = newFakeSubscriptionItems(input.Items)
+	subscription.Metadata = maps.Clone(input.Metadata)
+	provider.subscriptions[subscriptionID] = subscription
+	provider.mutex.Unlock()
+
+	provider.dispatchEventAsync(Event{
+		ID:           fakeID("evt"),
+		Type:         EventTypeSubscriptionUpdated,
+		Subscription: &subscription,
+	})
+
+	return subscription, nil
+}
+
+// CancelSubscription cancels the subscription and asynchronously dispatches a
+// customer.subscription.deleted event, as the caller may hold locks that the EventHandler needs.
+func (provider *FakeProvider) CancelSubscription(ctx context.Context, subscriptionID string) (Subscription, error) {
+	now := time.Now().UTC()
+
+	provider.mutex.Lock()
+	subscription, exists := provider.subscriptions[subscriptionID]
+	if !exists {
+		provider.mutex.Unlock()
+		return Subscription{}, ErrSubscriptionNotFound
+	}
+	subscription.Status = SubscriptionStatusCanceled
+	subscription.CanceledAt = &now
+	subscription.CancelAtPeriodEnd = false
+	provider.subscriptions[subscriptionID] = subscription
+	provider.mutex.Unlock()
+
+	provider.dispatchEventAsync(Event{
+		ID:           fakeID("evt"),
+		Type:         EventTypeSubscriptionDeleted,
+		Subscription: &subscription,
+	})
+
+	return subscription, nil
+}
+
+// CreateInvoice creates an invoice that stays open until PayInvoice is called
+func (provider *FakeProvider) CreateInvoice(ctx context.Context, input CreateInvoiceInput) (Invoice, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	if _, exists := provider.customers[input.CustomerID]; !exists {
+		return Invoice{}, ErrCustomerNotFound
+	}
+
+	invoice := &fakeInvoice{
+		invoice: Invoice{
+			ID:         fakeID("in"),
+			URL:        "",
+			CustomerID: &input.CustomerID,
+			Metadata:   maps.Clone(input.Metadata),
+		},
+		amount: input.Amount,
+	}
+	provider.invoices[invoice.invoice.ID] = invoice
+
+	return invoice.invoice, nil
+}
+
+// PayInvoice creates a successful payment for the invoice
+func (provider *FakeProvider) PayInvoice(ctx context.Context, input PayInvoiceInput) error {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	invoice, exists := provider.invoices[input.InvoiceID]
+	if !exists {
+		return ErrInvoiceNotFound
+	}
+	if invoice.invoice.PaymentID != nil {
+		return nil
+	}
+
+	payment := Payment{
+		ID:       fakeID("pi"),
+		Status:   PaymentStatusSucceeded,
+		Amount:   invoice.amount,
+		Invoice:  &invoice.invoice,
+		Metadata: maps.Clone(invoice.invoice.Metadata),
+	}
+	provider.payments[payment.ID] = payment
+	invoice.invoice.PaymentID = &payment.ID
+
+	return nil
+}
+
+// ParseWebhookEvent always returns ErrWebhooksNotSupported: events are directly dispatched to the
+// EventHandler.
+func (provider *FakeProvider) ParseWebhookEvent(payload []byte, headers http.Header) (Event, error) {
+	return Event{}, ErrWebhooksNotSupported
+}
+
+// Pay completes the checkout session as if the customer had successfully paid, and dispatches the
+// corresponding events. It returns the URL where the customer should be redirected.
+func (provider *FakeProvider) Pay(ctx context.Context, checkoutSessionID string) (successURL string, err error) {
+	now := time.Now().UTC()
+
+	provider.mutex.Lock()
+	checkoutSession, exists := provider.checkoutSessions[checkoutSessionID]
+	if !exists {
+		provider.mutex.Unlock()
+		return "", ErrCheckoutSessionNotFound
+	}
+	if checkoutSession.session.Status != CheckoutSessionStatusOpen {
+		provider.mutex.Unlock()
+		return "", ErrCheckoutSessionIsNotOpen
+	}
+
+	if checkoutSession.session.CustomerID == nil {
+		customer := Customer{
+			ID:     fakeID("cus"),
+			Email:  checkoutSession.input.CustomerEmail,
+			TaxIDs: []TaxID{},
+		}
+		provider.customers[customer.ID] = &fakeCustomer{customer: customer}
+		checkoutSession.session.CustomerID = &customer.ID
+	}
+
+	var amountTotal int64
+	for _, item := range checkoutSession.input.LineItems {
+		amountTotal += item.UnitAmount * item.Quantity
+		checkoutSession.session.LineItems = append(checkoutSession.session.LineItems, LineItem{
+			Quantity: item.Quantity,
+			Metadata: maps.Clone(item.Metadata),
+		})
+	}
+
+	checkoutSession.session.Status = CheckoutSessionStatusComplete
+	checkoutSession.session.Paid = true
+	checkoutSession.session.AmountTotal = amountTotal
+	checkoutSession.session.Invoice = &Invoice{
+		ID:       fakeID("in"),
+		URL:      "",
+		Metadata: maps.Clone(checkoutSession.input.Metadata),
+	}
+
+	if checkoutSession.input.Mode == CheckoutSessionModeSubscription {
+		subscription := Subscription{
+			ID:                fakeID("sub"),
+			Status:            SubscriptionStatusActive,
+			CustomerID:        checkoutSession.session.CustomerID,
+			Items:             []SubscriptionItem{},
+			StartedAt:         &now,
+			CancelAtPeriodEnd: false,
+			CurrentPeriodEnd:  new(now.AddDate(0, 1, 0)),
+			CanceledAt:        nil,
+			Metadata:          maps.Clone(checkoutSession.input.SubscriptionMetadata),
+		}
+		for _, item := range checkoutSession.input.LineItems {
+			if item.BillingInterval != nil && *item.BillingInterval == "year" {
+				subscription.CurrentPeriodEnd = new(now.AddDate(1, 0, 0))
+			}
+			subscription.Items = append(subscription.Items, SubscriptionItem{
+				ID:       fakeID("si"),
+				PriceID:  item.PriceID,
+				Quantity: item.Quantity,
+			})
+		}
+		provider.subscriptions[subscription.ID] = subscription
+		checkoutSession.subscriptionID = &subscription.ID
+
+		// the payment method used for a subscription is saved to charge the next invoices
+		customer := provider.customers[*checkoutSession.session.CustomerID]
+		if customer != nil && customer.defaultPaymentMethodID == nil {
+			customer.defaultPaymentMethodID = new(fakeID("pm"))
+		}
+	} else {
+		payment := Payment{
+			ID:       fakeID("pi"),
+			Status:   PaymentStatusSucceeded,
+			Amount:   amountTotal,
+			Invoice:  checkoutSession.session.Invoice,
+			Metadata: maps.Clone(checkoutSession.input.Metadata),
+		}
+		checkoutSession.session.Invoice.PaymentID = &payment.ID
+		provider.payments[payment.ID] = payment
+		checkoutSession.paymentID = &payment.ID
+	}
+
+	events := make([]Event, 0, 2)
+	session := provider.hydrateCheckoutSession(checkoutSession)
+	if session.Subscription != nil {
+		events = append(events, Event{
+			ID:           fakeID("evt"),
+			Type:         EventTypeSubscriptionCreated,
+			Subscription: session.Subscription,
+		})
+	}
+	events = append(events, Event{
+		ID:              fakeID("evt"),
+		Type:            EventTypeCheckoutSessionCompleted,
+		CheckoutSession: &session,
+	})
+	successURL = checkoutSession.input.SuccessURL
+	provider.mutex.Unlock()
+
+	for _, event := range events {
+		err = provider.dispatchEvent(ctx, event)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return successURL, nil
+}
+
+// Expire expires the checkout session as if the customer had canceled the payment, and dispatches
+// the corresponding event. It returns the URL where the customer should be redirected.
+func (provider *FakeProvider) Expire(ctx context.Context, checkoutSessionID string) (cancelURL string, err error) {
+	provider.mutex.Lock()
+	checkoutSession, exists := provider.checkoutSessions[checkoutSessionID]
+	if !exists {
+		provider.mutex.Unlock()
+		return "", ErrCheckoutSessionNotFound
+	}
+	if checkoutSession.session.Status != CheckoutSessionStatusOpen {
+		provider.mutex.Unlock()
+		return "", ErrCheckoutSessionIsNotOpen
+	}
+
+	checkoutSession.session.Status = CheckoutSessionStatusExpired
+	session := provider.hydrateCheckoutSession(checkoutSession)
+	cancelURL = checkoutSession.input.CancelURL
+	provider.mutex.Unlock()
+
+	err = provider.dispatchEvent(ctx, Event{
+		ID:              fakeID("evt"),
+		Type:            EventTypeCheckoutSessionExpired,
+		CheckoutSession: &session,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return cancelURL, nil
+}
+
+// CheckoutHandler serves the local checkout page. It must be mounted at FakeCheckoutPath.
+func (provider *FakeProvider) CheckoutHandler() http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		checkoutSessionID := strings.TrimPrefix(req.URL.Path, FakeCheckoutPath)
+
+		switch req.Method {
+		case http.MethodGet:
+			provider.mutex.Lock()
+			checkoutSession, exists := provider.checkoutSessions[checkoutSessionID]
+			var data fakeCheckoutPageData
+			if exists {
+				data = newFakeCheckoutPageData(checkoutSession)
+			}
+			provider.mutex.Unlock()
+			if !exists {
+				http.Error(res, ErrCheckoutSessionNotFound.Error(), http.StatusNotFound)
+				return
+			}
+
+			res.Header().Set("Content-Type", "text/html; charset=utf-8")
+			err := fakeCheckoutPageTemplate.Execute(res, data)
+			if err != nil {
+				slogx.FromCtx(ctx).Error("payments.fake: error rendering checkout page", slogx.Err(err))
+			}
+
+		case http.MethodPost:
+			var redirectURL string
+			var err error
+			if req.FormValue("action") == "pay" {
+				redirectURL, err = provider.Pay(ctx, checkoutSessionID)
+			} else {
+				redirectURL, err = provider.Expire(ctx, checkoutSessionID)
+			}
+			if err != nil {
+				slogx.FromCtx(ctx).Warn("payments.fake: error processing checkout session", slogx.Err(err),
+					slog.String("checkout_session.id", checkoutSessionID))
+				http.Error(res, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			http.Redirect(res, req, redirectURL, http.StatusSeeOther)
+
+		default:
+			http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// hydrateCheckoutSession returns a copy of the checkout session with its payment and subscription.
+// provider.mutex must be held.
+func (provider *FakeProvider) hydrateCheckoutSession(checkoutSession *fakeCheckoutSession) CheckoutSession {
+	session := checkoutSession.session
+	session.LineItems = append([]LineItem{}, checkoutSession.session.LineItems...)
+	if checkoutSession.paymentID != nil {
+		session.Payment = new(provider.payments[*checkoutSession.paymentID])
+	}
+	if checkoutSession.subscriptionID != nil {
+		session.Subscription = new(provider.subscriptions[*checkoutSession.subscriptionID])
+	}
+	return session
+}
+
+func (provider *FakeProvider) dispatchEvent(ctx context.Context, event Event) error {
+	provider.mutex.Lock()
+	eventHandler := provider.eventHandler
+	provider.mutex.Unlock()
+
+	if eventHandler == nil {
+		provider.logger.Warn("payments.fake: no event handler. Discarding event",
+			slog.String("event.type", string(event.Type)))
+		return nil
+	}
+
+	err := eventHandler(ctx, event)
+	if err != nil {
+		return fmt.Errorf("payments.fake: handling event [%s - %s]: %w", event.ID, event.Type, err)
+	}
+
+	return nil
+}
+
+// dispatchEventAsync dispatches the event in the background, for the methods that may be called
+// while holding locks (e.g. database rows) that the EventHandler needs.
+func (provider *FakeProvider) dispatchEventAsync(event Event) {
+	go func() {
+		err := provider.dispatchEvent(context.Background(), event)
+		if err != nil {
+			provider.logger.Error("payments.fake: error dispatching event", slogx.Err(err),
+				slog.String("event.type", string(event.Type)))
+		}
+	}()
+}
+
+func newFakeSubscriptionItems(items []SubscriptionItemInput) []SubscriptionItem {
+	subscriptionItems := make([]SubscriptionItem, len(items))
+	for i, item := range items {
+		subscriptionItems[i] = SubscriptionItem{
+			ID:       fakeID("si"),
+			PriceID:  item.PriceID,
+			Quantity: item.Quantity,
+		}
+	}
+	return subscriptionItems
+}
+
+func fakeID(prefix string) string {
+	return "fake_" + prefix + "_" + strings.ReplaceAll(guid.NewRandom().String(), "-", "")
+}
+
+type fakeCheckoutPageData struct {
+	Open      bool
+	Status    CheckoutSessionStatus
+	Currency  string
+	Total     string
+	LineItems []fakeCheckoutPageLineItem
+}
+
+type fakeCheckoutPageLineItem struct {
+	Name     string
+	Quantity int64
+	Amount   string
+}
+
+func newFakeCheckoutPageData(checkoutSession *fakeCheckoutSession) fakeCheckoutPageData {
+	data := fakeCheckoutPageData{
+		Open:      checkoutSession.session.Status == CheckoutSessionStatusOpen,
+		Status:    checkoutSession.session.Status,
+		Currency:  checkoutSession.input.Currency,
+		LineItems: make([]fakeCheckoutPageLineItem, 0, len(checkoutSession.input.LineItems)),
+	}
+
+	var total int64
+	for _, item := range checkoutSession.input.LineItems {
+		// prices defined in the provider's dashboard are unknown to the fake provider
+		name := item.Name
+		if item.PriceID != "" {
+			name = item.PriceID
+		}
+		total += item.UnitAmount * item.Quantity
+		data.LineItems = append(data.LineItems, fakeCheckoutPageLineItem{
+			Name:     name,
+			Quantity: item.Quantity,
+			Amount:   formatFakeAmount(item.UnitAmount * item.Quantity),
+		})
+	}
+	data.Total = formatFakeAmount(total)
+
+	return data
+}
+
+func formatFakeAmount(amount int64) string {
+	return fmt.Sprintf("%d.%02d", amount/100, amount%100)
+}
+
+var fakeCheckoutPageTemplate = template.Must(template.New("fake_checkout").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <meta name="robots" content="noindex">
+  <title>Checkout (test mode)</title>
+</head>
+<body>
+  <h1>Checkout</h1>
+  <p><strong>Test mode: no payment will be made.</strong></p>
+  <table>
+    <tbody>
+      {{ range .LineItems }}
+      <tr><td>{{ .Name }}</td><td>x{{ .Quantity }}</td><td>{{ .Amount }} {{ $.Currency }}</td></tr>
+      {{ end }}
+    </tbody>
+    <tfoot>
+      <tr><th colspan="2">Total</th><th>{{ .Total }} {{ .Currency }}</th></tr>
+    </tfoot>
+  </table>
+  {{ if .Open }}
+  <form method="post">
+    <button type="submit" name="action" value="pay">Pay</button>
+    <button type="submit" name="action" value="cancel">Cancel</button>
+  </form>
+  {{ else }}
+  <p>This checkout session is {{ .Status }}.</p>
+  {{ end }}
+</body>
+</html>
+`))
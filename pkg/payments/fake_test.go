@@ -0,0 +1,301 @@
+package payments
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newTestFakeProvider() (provider *FakeProvider, events *[]Event) {
+	provider = NewFakeProvider("http://localhost:8080", slog.New(slog.DiscardHandler))
+	events = &[]Event{}
+	provider.SetEventHandler(func(ctx context.Context, event Event) error {
+		*events = append(*events, event)
+		return nil
+	})
+	return
+}
+
+func TestFakeProviderPay(t *testing.T) {
+	ctx := context.Background()
+	provider, events := newTestFakeProvider()
+
+	checkoutSession, err := provider.CreateCheckoutSession(ctx, CreateCheckoutSessionInput{
+		Mode:     CheckoutSessionModePayment,
+		Currency: "EUR",
+		LineItems: []CheckoutSessionLineItem{
+			{Name: "Ebook", UnitAmount: 1500, Quantity: 2, Metadata: map[string]string{"product": "ebook"}},
+			{Name: "Course", UnitAmount: 4900, Quantity: 1},
+		},
+		CustomerEmail: "customer@example.com",
+		SuccessURL:    "https://example.com/success",
+		CancelURL:     "https://example.com/cancel",
+		Metadata:      map[string]string{"order": "1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(checkoutSession.URL, "http://localhost:8080"+FakeCheckoutPath) {
+		t.Errorf("unexpected checkout URL: %s", checkoutSession.URL)
+	}
+
+	successURL, err := provider.Pay(ctx, checkoutSession.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if successURL != "https://example.com/success" {
+		t.Errorf("successURL = %s", successURL)
+	}
+
+	if len(*events) != 1 || (*events)[0].Type != EventTypeCheckoutSessionCompleted {
+		t.Fatalf("expected a single checkout.session.completed event, got: %+v", *events)
+	}
+	if (*events)[0].CheckoutSession.Metadata["order"] != "1" {
+		t.Errorf("metadata of the checkout session is missing from the event")
+	}
+
+	checkoutSession, err = provider.GetCheckoutSession(ctx, checkoutSession.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkoutSession.Status != CheckoutSessionStatusComplete || !checkoutSession.Paid {
+		t.Errorf("checkout session is not complete and paid: %+v", checkoutSession)
+	}
+	if checkoutSession.Payment == nil || checkoutSession.Payment.Status != PaymentStatusSucceeded ||
+		checkoutSession.Payment.Amount != 7900 {
+		t.Fatalf("unexpected payment: %+v", checkoutSession.Payment)
+	}
+	if checkoutSession.CustomerID == nil || checkoutSession.Invoice == nil {
+		t.Errorf("customer or invoice is missing")
+	}
+	if len(checkoutSession.LineItems) != 2 || checkoutSession.LineItems[0].Metadata["product"] != "ebook" {
+		t.Errorf("unexpected line items: %+v", checkoutSession.LineItems)
+	}
+
+	_, err = provider.Pay(ctx, checkoutSession.ID)
+	if err != ErrCheckoutSessionIsNotOpen {
+		t.Errorf("paying twice: expected ErrCheckoutSessionIsNotOpen, got: %v", err)
+	}
+
+	_, err = provider.CreateRefund(ctx, CreateRefundInput{PaymentID: checkoutSession.Payment.ID, Amount: 8000})
+	if err == nil {
+		t.Errorf("refunding more than the payment should fail")
+	}
+	refund, err := provider.CreateRefund(ctx, CreateRefundInput{PaymentID: checkoutSession.Payment.ID, Amount: 1500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refund, err = provider.GetRefund(ctx, refund.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.Status != RefundStatusSucceeded {
+		t.Errorf("refund.Status = %s", refund.Status)
+	}
+}
+
+func TestFakeProviderSubscription(t *testing.T) {
+	ctx := context.Background()
+	provider, events := newTestFakeProvider()
+
+	checkoutSession, err := provider.CreateCheckoutSession(ctx, CreateCheckoutSessionInput{
+		Mode:     CheckoutSessionModeSubscription,
+		Currency: "USD",
+		LineItems: []CheckoutSessionLineItem{
+			{Name: "Membership", UnitAmount: 900, Quantity: 1, BillingInterval: new("month")},
+		},
+		SubscriptionMetadata: map[string]string{"product": "membership"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = provider.Pay(ctx, checkoutSession.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*events) != 2 || (*events)[0].Type != EventTypeSubscriptionCreated ||
+		(*events)[1].Type != EventTypeCheckoutSessionCompleted {
+		t.Fatalf("unexpected events: %+v", *events)
+	}
+
+	subscription := (*events)[1].CheckoutSession.Subscription
+	if subscription == nil || subscription.Status != SubscriptionStatusActive ||
+		subscription.Metadata["product"] != "membership" || subscription.CurrentPeriodEnd == nil {
+		t.Fatalf("unexpected subscription: %+v", subscription)
+	}
+
+	canceledSubscription, err := provider.CancelSubscription(ctx, subscription.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canceledSubscription.Status != SubscriptionStatusCanceled || canceledSubscription.CanceledAt == nil {
+		t.Errorf("subscription is not canceled: %+v", canceledSubscription)
+	}
+	if subscription.Status != SubscriptionStatusActive {
+		t.Errorf("canceling a subscription should not modify the previously returned subscriptions")
+	}
+}
+
+func TestFakeProviderCheckoutHandler(t *testing.T) {
+	ctx := context.Background()
+	provider, events := newTestFakeProvider()
+	handler := provider.CheckoutHandler()
+
+	checkoutSession, err := provider.CreateCheckoutSession(ctx, CreateCheckoutSessionInput{
+		Mode:       CheckoutSessionModePayment,
+		Currency:   "USD",
+		LineItems:  []CheckoutSessionLineItem{{Name: "<Ebook>", UnitAmount: 1999, Quantity: 1}},
+		SuccessURL: "https://example.com/success",
+		CancelURL:  "https://example.com/cancel",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, FakeCheckoutPath+checkoutSession.ID, nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("GET checkout page: status = %d", res.Code)
+	}
+	body := res.Body.String()
+	if !strings.Contains(body, "&lt;Ebook&gt;") || !strings.Contains(body, "19.99 USD") {
+		t.Errorf("checkout page does not contain the line items: %s", body)
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, FakeCheckoutPath+"fake_cs_unknown", nil))
+	if res.Code != http.StatusNotFound {
+		t.Errorf("GET unknown checkout page: status = %d", res.Code)
+	}
+
+	form := url.Values{"action": {"cancel"}}
+	req := httptest.NewRequest(http.MethodPost, FakeCheckoutPath+checkoutSession.ID, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	if res.Code != http.StatusSeeOther || res.Header().Get("Location") != "https://example.com/cancel" {
+		t.Fatalf("POST cancel: status = %d, location = %s", res.Code, res.Header().Get("Location"))
+	}
+
+	if len(*events) != 1 || (*events)[0].Type != EventTypeCheckoutSessionExpired {
+		t.Fatalf("expected a single checkout.session.expired event, got: %+v", *events)
+	}
+}
+
+func TestFakeProviderBilling(t *testing.T) {
+	ctx := context.Background()
+	provider := NewFakeProvider("http://localhost:8080", slog.New(slog.DiscardHandler))
+	// the events of the subscriptions are dispatched asynchronously
+	provider.SetEventHandler(func(ctx context.Context, event Event) error { return nil })
+
+	customer, err := provider.CreateCustomer(ctx, CreateCustomerInput{
+		Name:    "Acme",
+		Email:   "billing@example.com",
+		Address: &Address{Line1: "1 rue de Rivoli", City: "Paris", Country: "FR"},
+		TaxIDs:  []CreateTaxIDInput{{Type: TaxIDTypeEUVAT, Value: "FR00000000000"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = provider.GetDefaultPaymentMethod(ctx, customer.ID)
+	if err != ErrPaymentMethodNotFound {
+		t.Errorf("GetDefaultPaymentMethod: expected ErrPaymentMethodNotFound, got: %v", err)
+	}
+
+	checkoutSession, err := provider.CreateCheckoutSession(ctx, CreateCheckoutSessionInput{
+		Mode:       CheckoutSessionModeSubscription,
+		Currency:   "EUR",
+		CustomerID: &customer.ID,
+		LineItems: []CheckoutSessionLineItem{
+			{PriceID: "price_pro", Quantity: 1},
+			{PriceID: "price_slots", Quantity: 2},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = provider.Pay(ctx, checkoutSession.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	customer, err = provider.GetCustomer(ctx, customer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if customer.Address.City != "Paris" || len(customer.TaxIDs) != 1 || len(customer.Subscriptions) != 1 {
+		t.Fatalf("unexpected customer: %+v", customer)
+	}
+	subscription := customer.Subscriptions[0]
+	if len(subscription.Items) != 2 || subscription.Items[1].PriceID != "price_slots" ||
+		subscription.Items[1].Quantity != 2 || subscription.StartedAt == nil {
+		t.Errorf("unexpected subscription: %+v", subscription)
+	}
+
+	paymentMethod, err := provider.GetDefaultPaymentMethod(ctx, customer.ID)
+	if err != nil {
+		t.Errorf("the payment method of the checkout session should be saved: %v", err)
+	}
+
+	_, err = provider.UpdateSubscription(ctx, subscription.ID, UpdateSubscriptionInput{
+		Items: []SubscriptionItemInput{{PriceID: "price_pro", Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscription, err = provider.GetSubscription(ctx, subscription.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subscription.Items) != 1 || subscription.Items[0].PriceID != "price_pro" {
+		t.Errorf("the items of the subscription were not replaced: %+v", subscription.Items)
+	}
+
+	err = provider.DeleteTaxID(ctx, customer.ID, customer.TaxIDs[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = provider.CreateTaxID(ctx, customer.ID, CreateTaxIDInput{Type: TaxIDTypeEUVAT, Value: "FR11111111111"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	taxIDs, err := provider.ListTaxIDs(ctx, customer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(taxIDs) != 1 || taxIDs[0].Value != "FR11111111111" {
+		t.Errorf("unexpected tax IDs: %+v", taxIDs)
+	}
+
+	invoice, err := provider.CreateInvoice(ctx, CreateInvoiceInput{CustomerID: customer.ID, Amount: 250, Currency: "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = provider.PayInvoice(ctx, PayInvoiceInput{InvoiceID: invoice.ID, PaymentMethodID: paymentMethod.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = provider.PayInvoice(ctx, PayInvoiceInput{InvoiceID: "fake_in_unknown", PaymentMethodID: paymentMethod.ID})
+	if err != ErrInvoiceNotFound {
+		t.Errorf("PayInvoice: expected ErrInvoiceNotFound, got: %v", err)
+	}
+
+	_, err = provider.CancelSubscription(ctx, subscription.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer, err = provider.GetCustomer(ctx, customer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(customer.Subscriptions) != 0 {
+		t.Errorf("canceled subscriptions should not be returned: %+v", customer.Subscriptions)
+	}
+}
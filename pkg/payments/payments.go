@@ -0,0 +1,385 @@
+// Package payments abstracts the payment provider used by the stores of the websites and by the
+// billing of the organizations: checkout sessions, customers, tax IDs, refunds, subscriptions, invoices,
+// the customer portal and webhooks.
+//
+// Stripe is used in production, while the fake provider processes payments locally (with its own
+// checkout page and simulated webhooks) for development, integration tests and self-hosted
+// instances that don't accept payments.
+//
+// All the amounts are in the smallest unit of the currency (e.g. cents).
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrCheckoutSessionNotFound = errors.New("payments: checkout session not found")
+	ErrPaymentNotFound         = errors.New("payments: payment not found")
+	ErrRefundNotFound          = errors.New("payments: refund not found")
+	ErrSubscriptionNotFound    = errors.New("payments: subscription not found")
+	ErrCustomerNotFound        = errors.New("payments: customer not found")
+	ErrInvoiceNotFound         = errors.New("payments: invoice not found")
+	ErrPaymentMethodNotFound   = errors.New("payments: payment method not found")
+	ErrWebhooksNotSupported    = errors.New("payments: webhooks are not supported by this provider")
+)
+
+type Provider interface {
+	CreateCustomer(ctx context.Context, input CreateCustomerInput) (Customer, error)
+	// GetCustomer returns the customer with its tax IDs and its non-canceled subscriptions
+	GetCustomer(ctx context.Context, customerID string) (Customer, error)
+	UpdateCustomer(ctx context.Context, customerID string, input UpdateCustomerInput) error
+	// GetDefaultPaymentMethod returns the default payment method of the customer, or another one of
+	// its cards if the default one is missing or has expired. It returns ErrPaymentMethodNotFound if the
+	// customer has no valid payment method.
+	GetDefaultPaymentMethod(ctx context.Context, customerID string) (PaymentMethod, error)
+	SetDefaultPaymentMethod(ctx context.Context, customerID string, paymentMethodID string) error
+	// CreateCustomerPortalSession returns the URL of a portal where the customer can manage their
+	// payment methods, billing information and invoices.
+	CreateCustomerPortalSession(ctx context.Context, customerID string, returnURL string) (url string, err error)
+
+	ListTaxIDs(ctx context.Context, customerID string) ([]TaxID, error)
+	CreateTaxID(ctx context.Context, customerID string, input CreateTaxIDInput) (TaxID, error)
+	DeleteTaxID(ctx context.Context, customerID string, taxIDID string) error
+
+	// CreateCheckoutSession creates a checkout session. The customer should be redirected to
+	// CheckoutSession.URL to pay.
+	CreateCheckoutSession(ctx context.Context, input CreateCheckoutSessionInput) (CheckoutSession, error)
+	// GetCheckoutSession returns the checkout session with its payment, subscription, invoice and
+	// line items.
+	GetCheckoutSession(ctx context.Context, checkoutSessionID string) (CheckoutSession, error)
+	GetPayment(ctx context.Context, paymentID string) (Payment, error)
+
+	CreateRefund(ctx context.Context, input CreateRefundInput) (Refund, error)
+	GetRefund(ctx context.Context, refundID string) (Refund, error)
+
+	// CreateSubscription creates a subscription charged to the given payment method of the customer
+	CreateSubscription(ctx context.Context, input CreateSubscriptionInput) (Subscription, error)
+	GetSubscription(ctx context.Context, subscriptionID string) (Subscription, error)
+	// UpdateSubscription replaces the items of the subscription and immediately invoices the prorated
+	// amount.
+	UpdateSubscription(ctx context.Context, subscriptionID string, input UpdateSubscriptionInput) (Subscription, error)
+	// CancelSubscription immediately cancels the subscription and returns its updated state
+	CancelSubscription(ctx context.Context, subscriptionID string) (Subscription, error)
+
+	// CreateInvoice creates and finalizes a one-off invoice for the customer. The invoice is
+	// automatically charged to the default payment method of the customer unless PayInvoice is called.
+	CreateInvoice(ctx context.Context, input CreateInvoiceInput) (Invoice, error)
+	// PayInvoice immediately charges the invoice to the given payment method
+	PayInvoice(ctx context.Context, input PayInvoiceInput) error
+
+	// ParseWebhookEvent verifies and parses the payload of a webhook sent by the provider
+	ParseWebhookEvent(payload []byte, headers http.Header) (Event, error)
+}
+
+type CheckoutSessionMode string
+
+const (
+	CheckoutSessionModePayment      CheckoutSessionMode = "payment"
+	CheckoutSessionModeSubscription CheckoutSessionMode = "subscription"
+)
+
+type CheckoutSessionStatus string
+
+const (
+	CheckoutSessionStatusOpen     CheckoutSessionStatus = "open"
+	CheckoutSessionStatusComplete CheckoutSessionStatus = "complete"
+	CheckoutSessionStatusExpired  CheckoutSessionStatus = "expired"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending   PaymentStatus = "pending"
+	PaymentStatusSucceeded PaymentStatus = "succeeded"
+	PaymentStatusCanceled  PaymentStatus = "canceled"
+)
+
+// SubscriptionStatus uses the same values as Stripe.
+// See https://docs.stripe.com/api/subscriptions/object#subscription_object-status
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusActive   SubscriptionStatus = "active"
+	SubscriptionStatusPastDue  SubscriptionStatus = "past_due"
+	SubscriptionStatusUnpaid   SubscriptionStatus = "unpaid"
+	SubscriptionStatusCanceled SubscriptionStatus = "canceled"
+)
+
+// RefundStatus uses the same values as Stripe.
+// See https://docs.stripe.com/api/refunds/object#refund_object-status
+type RefundStatus string
+
+const (
+	RefundStatusPending   RefundStatus = "pending"
+	RefundStatusSucceeded RefundStatus = "succeeded"
+	RefundStatusFailed    RefundStatus = "failed"
+	RefundStatusCanceled  RefundStatus = "canceled"
+)
+
+type EventType string
+
+const (
+	EventTypeChargeFailed             EventType = "charge.failed"
+	EventTypeCheckoutSessionCompleted EventType = "checkout.session.completed"
+	EventTypeCheckoutSessionExpired   EventType = "checkout.session.expired"
+	EventTypeCustomerUpdated          EventType = "customer.updated"
+	EventTypeInvoicePaid              EventType = "invoice.paid"
+	EventTypePaymentMethodAttached    EventType = "payment_method.attached"
+	EventTypeSubscriptionCreated      EventType = "customer.subscription.created"
+	EventTypeSubscriptionUpdated      EventType = "customer.subscription.updated"
+	EventTypeSubscriptionDeleted      EventType = "customer.subscription.deleted"
+)
+
+// TaxIDType uses the same values as Stripe.
+// See https://docs.stripe.com/api/tax_ids/object#tax_id_object-type
+type TaxIDType string
+
+const (
+	TaxIDTypeEUVAT TaxIDType = "eu_vat"
+)
+
+type Customer struct {
+	ID      string
+	Name    string
+	Email   string
+	Address Address
+	TaxIDs  []TaxID
+	// Subscriptions are the non-canceled subscriptions of the customer. Only returned by GetCustomer.
+	Subscriptions []Subscription
+	Metadata      map[string]string
+}
+
+type Address struct {
+	Line1      string
+	Line2      string
+	City       string
+	PostalCode string
+	State      string
+	// ISO 3166-1 alpha-2 code of the country. e.g. FR
+	Country string
+}
+
+type CreateCustomerInput struct {
+	Name     string
+	Email    string
+	Address  *Address
+	TaxIDs   []CreateTaxIDInput
+	Metadata map[string]string
+}
+
+type UpdateCustomerInput struct {
+	Name    string
+	Email   string
+	Country string
+	// Address replaces the whole address of the customer. If nil, only the country is updated.
+	Address *Address
+}
+
+type TaxID struct {
+	ID    string
+	Type  TaxIDType
+	Value string
+}
+
+type CreateTaxIDInput struct {
+	Type  TaxIDType
+	Value string
+}
+
+type PaymentMethod struct {
+	ID         string
+	CustomerID *string
+	Metadata   map[string]string
+}
+
+type BillingAddressCollection string
+
+const (
+	// BillingAddressCollectionAuto only collects the billing address when the provider needs it
+	BillingAddressCollectionAuto     BillingAddressCollection = "auto"
+	BillingAddressCollectionRequired BillingAddressCollection = "required"
+)
+
+type CreateCheckoutSessionInput struct {
+	Mode CheckoutSessionMode
+	// ISO 4217 code of the currency. e.g. USD
+	Currency  string
+	LineItems []CheckoutSessionLineItem
+	// CustomerID is the ID of an existing customer. If nil, the customer is identified by
+	// CustomerEmail and created by the provider if required.
+	CustomerID    *string
+	CustomerEmail string
+	// BillingAddressCollection defaults to BillingAddressCollectionAuto
+	BillingAddressCollection BillingAddressCollection
+	// If true, the address of the existing customer is not replaced by the one entered on the
+	// checkout page.
+	KeepCustomerAddress bool
+	// If true, the customer can enter their tax ID on the checkout page
+	CollectTaxID bool
+	// PaymentMethodTypes restricts the payment methods accepted by the checkout page (e.g. card).
+	// If empty, the payment methods enabled on the provider's dashboard are accepted.
+	PaymentMethodTypes []string
+	// Description added to the invoice
+	InvoiceDescription string
+	SuccessURL         string
+	CancelURL          string
+	Metadata           map[string]string
+	// Metadata of the subscription created for CheckoutSessionModeSubscription sessions
+	SubscriptionMetadata map[string]string
+}
+
+type CheckoutSessionLineItem struct {
+	// PriceID is the ID of a price defined in the provider's dashboard. If not empty, Name,
+	// UnitAmount and BillingInterval are not used.
+	PriceID    string
+	Name       string
+	UnitAmount int64
+	Quantity   int64
+	// If true the customer can adjust the quantity on the checkout page
+	AdjustableQuantity bool
+	// BillingInterval is the interval of the subscription (month or year) for recurring items
+	BillingInterval *string
+	Metadata        map[string]string
+}
+
+type CheckoutSession struct {
+	ID          string
+	URL         string
+	Mode        CheckoutSessionMode
+	Status      CheckoutSessionStatus
+	Paid        bool
+	AmountTotal int64
+	CustomerID  *string
+	// Country of the billing address of the customer, if collected
+	CustomerCountry string
+	// Payment is nil for subscriptions and for sessions not paid yet
+	Payment      *Payment
+	Subscription *Subscription
+	Invoice      *Invoice
+	LineItems    []LineItem
+	Metadata     map[string]string
+}
+
+// LineItem is a purchased item of a checkout session. Metadata is the metadata of the
+// CheckoutSessionLineItem.
+type LineItem struct {
+	Quantity int64
+	Metadata map[string]string
+}
+
+type Payment struct {
+	ID       string
+	Status   PaymentStatus
+	Amount   int64
+	Invoice  *Invoice
+	Metadata map[string]string
+}
+
+type Invoice struct {
+	ID  string
+	URL string
+	// PaymentID is the ID of the payment of the invoice, if any
+	PaymentID  *string
+	CustomerID *string
+	Metadata   map[string]string
+}
+
+type CreateInvoiceInput struct {
+	CustomerID string
+	Amount     int64
+	// ISO 4217 code of the currency. e.g. EUR
+	Currency    string
+	Description string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Metadata    map[string]string
+	// IdempotencyKey makes it safe to retry the creation of the invoice
+	IdempotencyKey string
+}
+
+type PayInvoiceInput struct {
+	InvoiceID       string
+	PaymentMethodID string
+	IdempotencyKey  string
+}
+
+type Subscription struct {
+	ID                string
+	Status            SubscriptionStatus
+	CustomerID        *string
+	Items             []SubscriptionItem
+	StartedAt         *time.Time
+	CancelAtPeriodEnd bool
+	CurrentPeriodEnd  *time.Time
+	CanceledAt        *time.Time
+	Metadata          map[string]string
+}
+
+type SubscriptionItem struct {
+	ID string
+	// PriceID is the ID of the price defined in the provider's dashboard, if any
+	PriceID  string
+	Quantity int64
+}
+
+type CreateSubscriptionInput struct {
+	CustomerID      string
+	Items           []SubscriptionItemInput
+	PaymentMethodID string
+	Metadata        map[string]string
+}
+
+type UpdateSubscriptionInput struct {
+	// Items replace all the current items of the subscription
+	Items    []SubscriptionItemInput
+	Metadata map[string]string
+}
+
+type SubscriptionItemInput struct {
+	// PriceID is the ID of a price defined in the provider's dashboard
+	PriceID  string
+	Quantity int64
+}
+
+type CreateRefundInput struct {
+	PaymentID string
+	Amount    int64
+	Currency  string
+	Reason    string
+	Metadata  map[string]string
+}
+
+type Refund struct {
+	ID            string
+	Status        RefundStatus
+	FailureReason string
+}
+
+// Charge is an attempt to charge a payment method
+type Charge struct {
+	ID         string
+	CustomerID *string
+	Metadata   map[string]string
+}
+
+// Event is an event received from the provider. Depending on Type, one of Charge, CheckoutSession,
+// Customer, Invoice, PaymentMethod or Subscription is not nil.
+type Event struct {
+	ID   string
+	Type EventType
+	// Account is the connected account that emitted the event, if any
+	Account         string
+	Charge          *Charge
+	CheckoutSession *CheckoutSession
+	Customer        *Customer
+	Invoice         *Invoice
+	PaymentMethod   *PaymentMethod
+	Subscription    *Subscription
+}
+
+// EventHandler processes the events emitted by a Provider
+type EventHandler func(ctx context.Context, event Event) error
@@ -0,0 +1,818 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/stripe/stripe-go/v81"
+	portalsession "github.com/stripe/stripe-go/v81/billingportal/session"
+	"github.com/stripe/stripe-go/v81/checkout/session"
+	stripecustomer "github.com/stripe/stripe-go/v81/customer"
+	"github.com/stripe/stripe-go/v81/invoice"
+	"github.com/stripe/stripe-go/v81/invoiceitem"
+	"github.com/stripe/stripe-go/v81/paymentintent"
+	"github.com/stripe/stripe-go/v81/paymentmethod"
+	striperefund "github.com/stripe/stripe-go/v81/refund"
+	"github.com/stripe/stripe-go/v81/subscription"
+	"github.com/stripe/stripe-go/v81/taxid"
+	"github.com/stripe/stripe-go/v81/webhook"
+)
+
+type stripeProvider struct {
+	webhookSecret string
+}
+
+// NewStripeProvider returns a Provider backed by stripe.com. stripe.Key must be set before using it.
+func NewStripeProvider(webhookSecret string) Provider {
+	return &stripeProvider{
+		webhookSecret: webhookSecret,
+	}
+}
+
+func (provider *stripeProvider) CreateCustomer(ctx context.Context, input CreateCustomerInput) (Customer, error) {
+	params := &stripe.CustomerParams{
+		Email:    stripe.String(input.Email),
+		Metadata: input.Metadata,
+	}
+	params.Context = ctx
+	if input.Name != "" {
+		params.Name = stripe.String(input.Name)
+	}
+	if input.Address != nil {
+		params.Address = convertAddressToStripe(*input.Address)
+	}
+	for _, taxID := range input.TaxIDs {
+		params.TaxIDData = append(params.TaxIDData, &stripe.CustomerTaxIDDataParams{
+			Type:  stripe.String(string(taxID.Type)),
+			Value: stripe.String(taxID.Value),
+		})
+	}
+
+	stripeCustomer, err := stripecustomer.New(params)
+	if err != nil {
+		return Customer{}, fmt.Errorf("payments.stripe: creating customer: %w", err)
+	}
+
+	return convertStripeCustomer(stripeCustomer), nil
+}
+
+func (provider *stripeProvider) GetCustomer(ctx context.Context, customerID string) (Customer, error) {
+	params := &stripe.CustomerParams{}
+	params.Context = ctx
+	params.AddExpand("tax_ids")
+	params.AddExpand("subscriptions")
+	params.AddExpand("subscriptions.data.items")
+
+	stripeCustomer, err := stripecustomer.Get(customerID, params)
+	if err != nil {
+		return Customer{}, fmt.Errorf("payments.stripe: getting customer [%s]: %w", customerID, err)
+	}
+
+	return convertStripeCustomer(stripeCustomer), nil
+}
+
+func (provider *stripeProvider) UpdateCustomer(ctx context.Context, customerID string, input UpdateCustomerInput) error {
+	params := &stripe.CustomerParams{
+		Name:  stripe.String(input.Name),
+		Email: stripe.String(input.Email),
+		Address: &stripe.AddressParams{
+			Country: stripe.String(input.Country),
+		},
+	}
+	params.Context = ctx
+	if input.Address != nil {
+		params.Address = convertAddressToStripe(*input.Address)
+	}
+
+	_, err := stripecustomer.Update(customerID, params)
+	if err != nil {
+		return fmt.Errorf("payments.stripe: updating customer [%s]: %w", customerID, err)
+	}
+
+	return nil
+}
+
+func (provider *stripeProvider) GetDefaultPaymentMethod(ctx context.Context, customerID string) (PaymentMethod, error) {
+	getCustomerParams := &stripe.CustomerParams{}
+	getCustomerParams.Context = ctx
+	getCustomerParams.AddExpand("invoice_settings.default_payment_method")
+	stripeCustomer, err := stripecustomer.Get(customerID, getCustomerParams)
+	if err != nil {
+		return PaymentMethod{}, fmt.Errorf("payments.stripe: getting customer [%s]: %w", customerID, err)
+	}
+
+	// make sure that the default payment method is valid
+	if stripeCustomer.InvoiceSettings != nil {
+		defaultPaymentMethod := stripeCustomer.InvoiceSettings.DefaultPaymentMethod
+		if defaultPaymentMethod != nil &&
+			defaultPaymentMethod.Card != nil &&
+			!hasStripePaymentMethodExpired(defaultPaymentMethod) {
+			return *convertStripePaymentMethod(defaultPaymentMethod), nil
+		}
+	}
+
+	// if the customer has no valid default payment method, we use the first card that is valid
+	listPaymentMethodsParams := &stripe.PaymentMethodListParams{
+		Customer: stripe.String(customerID),
+		Type:     stripe.String(string(stripe.PaymentMethodTypeCard)),
+	}
+	listPaymentMethodsParams.Context = ctx
+	paymentMethodsIterator := paymentmethod.List(listPaymentMethodsParams)
+	for paymentMethodsIterator.Next() {
+		stripePaymentMethod := paymentMethodsIterator.PaymentMethod()
+		if !hasStripePaymentMethodExpired(stripePaymentMethod) {
+			return *convertStripePaymentMethod(stripePaymentMethod), nil
+		}
+	}
+	if err = paymentMethodsIterator.Err(); err != nil {
+		return PaymentMethod{}, fmt.Errorf("payments.stripe: listing payment methods of customer [%s]: %w", customerID, err)
+	}
+
+	return PaymentMethod{}, ErrPaymentMethodNotFound
+}
+
+func (provider *stripeProvider) SetDefaultPaymentMethod(ctx context.Context, customerID string, paymentMethodID string) error {
+	params := &stripe.CustomerParams{
+		InvoiceSettings: &stripe.CustomerInvoiceSettingsParams{
+			DefaultPaymentMethod: stripe.String(paymentMethodID),
+		},
+	}
+	params.Context = ctx
+
+	_, err := stripecustomer.Update(customerID, params)
+	if err != nil {
+		return fmt.Errorf("payments.stripe: setting default payment method of customer [%s]: %w", customerID, err)
+	}
+
+	return nil
+}
+
+func (provider *stripeProvider) CreateCustomerPortalSession(ctx context.Context, customerID string, returnURL string) (string, error) {
+	params := &stripe.BillingPortalSessionParams{
+		Customer:  stripe.String(customerID),
+		ReturnURL: stripe.String(returnURL),
+	}
+	params.Context = ctx
+
+	portalSession, err := portalsession.New(params)
+	if err != nil {
+		return "", fmt.Errorf("payments.stripe: creating customer portal session: %w", err)
+	}
+
+	return portalSession.URL, nil
+}
+
+func (provider *stripeProvider) ListTaxIDs(ctx context.Context, customerID string) ([]TaxID, error) {
+	params := &stripe.TaxIDListParams{Customer: stripe.String(customerID)}
+	params.Context = ctx
+
+	taxIDs := []TaxID{}
+	taxIDsIterator := taxid.List(params)
+	for taxIDsIterator.Next() {
+		taxIDs = append(taxIDs, convertStripeTaxID(taxIDsIterator.TaxID()))
+	}
+	if err := taxIDsIterator.Err(); err != nil {
+		return nil, fmt.Errorf("payments.stripe: listing tax IDs of customer [%s]: %w", customerID, err)
+	}
+
+	return taxIDs, nil
+}
+
+func (provider *stripeProvider) CreateTaxID(ctx context.Context, customerID string, input CreateTaxIDInput) (TaxID, error) {
+	params := &stripe.TaxIDParams{
+		Customer: stripe.String(customerID),
+		Type:     stripe.String(string(input.Type)),
+		Value:    stripe.String(input.Value),
+	}
+	params.Context = ctx
+
+	stripeTaxID, err := taxid.New(params)
+	if err != nil {
+		return TaxID{}, fmt.Errorf("payments.stripe: creating tax ID for customer [%s]: %w", customerID, err)
+	}
+
+	return convertStripeTaxID(stripeTaxID), nil
+}
+
+func (provider *stripeProvider) DeleteTaxID(ctx context.Context, customerID string, taxIDID string) error {
+	params := &stripe.TaxIDParams{Customer: stripe.String(customerID)}
+	params.Context = ctx
+
+	_, err := taxid.Del(taxIDID, params)
+	if err != nil {
+		return fmt.Errorf("payments.stripe: deleting tax ID [%s]: %w", taxIDID, err)
+	}
+
+	return nil
+}
+
+func (provider *stripeProvider) CreateCheckoutSession(ctx context.Context, input CreateCheckoutSessionInput) (CheckoutSession, error) {
+	lineItems := make([]*stripe.CheckoutSessionLineItemParams, len(input.LineItems))
+	for i, item := range input.LineItems {
+		if item.PriceID != "" {
+			lineItems[i] = &stripe.CheckoutSessionLineItemParams{
+				Price:    stripe.String(item.PriceID),
+				Quantity: stripe.Int64(item.Quantity),
+			}
+			continue
+		}
+
+		var recurring *stripe.CheckoutSessionLineItemPriceDataRecurringParams
+		var adjustableQuantity *stripe.CheckoutSessionLineItemAdjustableQuantityParams
+		if item.BillingInterval != nil {
+			recurring = &stripe.CheckoutSessionLineItemPriceDataRecurringParams{
+				Interval: item.BillingInterval,
+			}
+		}
+		if item.AdjustableQuantity {
+			adjustableQuantity = &stripe.CheckoutSessionLineItemAdjustableQuantityParams{
+				Enabled: stripe.Bool(true),
+				Minimum: stripe.Int64(1),
+			}
+		}
+		lineItems[i] = &stripe.CheckoutSessionLineItemParams{
+			PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
+				// Stripe uses lowercase codes for currencies. See stripe.CurrencyUSD for example.
+				Currency: stripe.String(strings.ToLower(input.Currency)),
+				ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
+					Name:     stripe.String(item.Name),
+					Metadata: item.Metadata,
+				},
+				UnitAmount: stripe.Int64(item.UnitAmount),
+				Recurring:  recurring,
+			},
+			Quantity:           stripe.Int64(item.Quantity),
+			AdjustableQuantity: adjustableQuantity,
+		}
+	}
+
+	params := &stripe.CheckoutSessionParams{
+		LineItems:  lineItems,
+		SuccessURL: stripe.String(input.SuccessURL),
+		CancelURL:  stripe.String(input.CancelURL),
+		AutomaticTax: &stripe.CheckoutSessionAutomaticTaxParams{
+			Enabled: stripe.Bool(false),
+		},
+		Metadata: input.Metadata,
+	}
+	params.Context = ctx
+
+	if input.BillingAddressCollection != "" {
+		params.BillingAddressCollection = stripe.String(string(input.BillingAddressCollection))
+	}
+	if input.CollectTaxID {
+		params.TaxIDCollection = &stripe.CheckoutSessionTaxIDCollectionParams{
+			Enabled:  stripe.Bool(true),
+			Required: stripe.String(string(stripe.CheckoutSessionTaxIDCollectionRequiredNever)),
+		}
+	}
+	if len(input.PaymentMethodTypes) != 0 {
+		params.PaymentMethodTypes = stripe.StringSlice(input.PaymentMethodTypes)
+	}
+
+	if input.CustomerID != nil {
+		customerUpdateAddress := "auto"
+		if input.KeepCustomerAddress {
+			customerUpdateAddress = "never"
+		}
+		params.Customer = input.CustomerID
+		params.CustomerUpdate = &stripe.CheckoutSessionCustomerUpdateParams{
+			Name:    stripe.String("auto"),
+			Address: stripe.String(customerUpdateAddress),
+		}
+	} else {
+		params.CustomerEmail = stripe.String(input.CustomerEmail)
+	}
+
+	if input.Mode == CheckoutSessionModeSubscription {
+		// Stripe always creates a customer and invoices for subscriptions
+		params.Mode = stripe.String(string(stripe.CheckoutSessionModeSubscription))
+		params.SubscriptionData = &stripe.CheckoutSessionSubscriptionDataParams{
+			Metadata: input.SubscriptionMetadata,
+		}
+		if input.InvoiceDescription != "" {
+			params.SubscriptionData.Description = stripe.String(input.InvoiceDescription)
+		}
+	} else {
+		params.Mode = stripe.String(string(stripe.CheckoutSessionModePayment))
+		if input.CustomerID == nil {
+			params.CustomerCreation = stripe.String(string(stripe.CheckoutSessionCustomerCreationIfRequired))
+		}
+		params.PaymentIntentData = &stripe.CheckoutSessionPaymentIntentDataParams{
+			Metadata: input.Metadata,
+		}
+		params.InvoiceCreation = &stripe.CheckoutSessionInvoiceCreationParams{
+			Enabled: stripe.Bool(true),
+		}
+		if input.InvoiceDescription != "" {
+			params.InvoiceCreation.InvoiceData = &stripe.CheckoutSessionInvoiceCreationInvoiceDataParams{
+				Description: stripe.String(input.InvoiceDescription),
+			}
+		}
+	}
+
+	stripeCheckoutSession, err := session.New(params)
+	if err != nil {
+		return CheckoutSession{}, fmt.Errorf("payments.stripe: creating checkout session: %w", err)
+	}
+
+	return convertStripeCheckoutSession(stripeCheckoutSession), nil
+}
+
+func (provider *stripeProvider) GetCheckoutSession(ctx context.Context, checkoutSessionID string) (CheckoutSession, error) {
+	params := &stripe.CheckoutSessionParams{}
+	params.Context = ctx
+	params.AddExpand("payment_intent")
+	params.AddExpand("payment_intent.invoice")
+	params.AddExpand("customer")
+	params.AddExpand("line_items")
+	params.AddExpand("line_items.data.price.product")
+	// for subscriptions
+	params.AddExpand("subscription")
+	params.AddExpand("invoice")
+
+	stripeCheckoutSession, err := session.Get(checkoutSessionID, params)
+	if err != nil {
+		return CheckoutSession{}, fmt.Errorf("payments.stripe: getting checkout session [%s]: %w", checkoutSessionID, err)
+	}
+
+	return convertStripeCheckoutSession(stripeCheckoutSession), nil
+}
+
+func (provider *stripeProvider) GetPayment(ctx context.Context, paymentID string) (Payment, error) {
+	params := &stripe.PaymentIntentParams{}
+	params.Context = ctx
+
+	stripePaymentIntent, err := paymentintent.Get(paymentID, params)
+	if err != nil {
+		return Payment{}, fmt.Errorf("payments.stripe: getting payment intent [%s]: %w", paymentID, err)
+	}
+
+	return *convertStripePaymentIntent(stripePaymentIntent), nil
+}
+
+func (provider *stripeProvider) CreateRefund(ctx context.Context, input CreateRefundInput) (Refund, error) {
+	params := &stripe.RefundParams{
+		Amount:        stripe.Int64(input.Amount),
+		Currency:      stripe.String(input.Currency),
+		Reason:        stripe.String(input.Reason),
+		PaymentIntent: stripe.String(input.PaymentID),
+		Metadata:      input.Metadata,
+	}
+	params.Context = ctx
+
+	stripeRefund, err := striperefund.New(params)
+	if err != nil {
+		return Refund{}, fmt.Errorf("payments.stripe: creating refund: %w", err)
+	}
+
+	return convertStripeRefund(stripeRefund), nil
+}
+
+func (provider *stripeProvider) GetRefund(ctx context.Context, refundID string) (Refund, error) {
+	params := &stripe.RefundParams{}
+	params.Context = ctx
+
+	stripeRefund, err := striperefund.Get(refundID, params)
+	if err != nil {
+		return Refund{}, fmt.Errorf("payments.stripe: getting refund [%s]: %w", refundID, err)
+	}
+
+	return convertStripeRefund(stripeRefund), nil
+}
+
+func (provider *stripeProvider) CreateSubscription(ctx context.Context, input CreateSubscriptionInput) (Subscription, error) {
+	params := &stripe.SubscriptionParams{
+		Customer:             stripe.String(input.CustomerID),
+		Items:                convertSubscriptionItemsToStripe(input.Items),
+		DefaultPaymentMethod: stripe.String(input.PaymentMethodID),
+		Metadata:             input.Metadata,
+	}
+	params.Context = ctx
+
+	stripeSubscription, err := subscription.New(params)
+	if err != nil {
+		return Subscription{}, fmt.Errorf("payments.stripe: creating subscription: %w", err)
+	}
+
+	return *convertStripeSubscription(stripeSubscription), nil
+}
+
+func (provider *stripeProvider) GetSubscription(ctx context.Context, subscriptionID string) (Subscription, error) {
+	params := &stripe.SubscriptionParams{}
+	params.Context = ctx
+
+	stripeSubscription, err := subscription.Get(subscriptionID, params)
+	if err != nil {
+		return Subscription{}, fmt.Errorf("payments.stripe: getting subscription [%s]: %w", subscriptionID, err)
+	}
+
+	return *convertStripeSubscription(stripeSubscription), nil
+}
+
+func (provider *stripeProvider) UpdateSubscription(ctx context.Context, subscriptionID string, input UpdateSubscriptionInput) (Subscription, error) {
+	getParams := &stripe.SubscriptionParams{}
+	getParams.Context = ctx
+	stripeSubscription, err := subscription.Get(subscriptionID, getParams)
+	if err != nil {
+		return Subscription{}, fmt.Errorf("payments.stripe: getting subscription [%s]: %w", subscriptionID, err)
+	}
+
+	params := &stripe.SubscriptionParams{
+		ProrationBehavior: stripe.String(string(stripe.SubscriptionSchedulePhaseProrationBehaviorAlwaysInvoice)),
+		Metadata:          input.Metadata,
+	}
+	params.Context = ctx
+
+	// existing subscription items are removed
+	// https://docs.stripe.com/billing/subscriptions/upgrade-downgrade
+	if stripeSubscription.Items != nil {
+		for _, existingSubscriptionItem := range stripeSubscription.Items.Data {
+			params.Items = append(params.Items, &stripe.SubscriptionItemsParams{
+				ID:      stripe.String(existingSubscriptionItem.ID),
+				Deleted: stripe.Bool(true),
+			})
+		}
+	}
+	params.Items = append(params.Items, convertSubscriptionItemsToStripe(input.Items)...)
+
+	stripeSubscription, err = subscription.Update(subscriptionID, params)
+	if err != nil {
+		return Subscription{}, fmt.Errorf("payments.stripe: updating subscription [%s]: %w", subscriptionID, err)
+	}
+
+	return *convertStripeSubscription(stripeSubscription), nil
+}
+
+func (provider *stripeProvider) CancelSubscription(ctx context.Context, subscriptionID string) (Subscription, error) {
+	params := &stripe.SubscriptionCancelParams{}
+	params.Context = ctx
+
+	stripeSubscription, err := subscription.Cancel(subscriptionID, params)
+	if err != nil {
+		return Subscription{}, fmt.Errorf("payments.stripe: canceling subscription [%s]: %w", subscriptionID, err)
+	}
+
+	return *convertStripeSubscription(stripeSubscription), nil
+}
+
+func (provider *stripeProvider) CreateInvoice(ctx context.Context, input CreateInvoiceInput) (Invoice, error) {
+	invoiceItemParams := &stripe.InvoiceItemParams{
+		Customer: stripe.String(input.CustomerID),
+		Amount:   stripe.Int64(input.Amount),
+		// Stripe uses lowercase codes for currencies. See stripe.CurrencyEUR for example.
+		Currency:    stripe.String(strings.ToLower(input.Currency)),
+		Description: stripe.String(input.Description),
+		Period: &stripe.InvoiceItemPeriodParams{
+			Start: stripe.Int64(input.PeriodStart.Unix()),
+			End:   stripe.Int64(input.PeriodEnd.Unix()),
+		},
+		Metadata: input.Metadata,
+	}
+	invoiceItemParams.Context = ctx
+	invoiceItemParams.SetIdempotencyKey("create_invoice_item-" + input.IdempotencyKey)
+	_, err := invoiceitem.New(invoiceItemParams)
+	if err != nil {
+		return Invoice{}, fmt.Errorf("payments.stripe: creating invoice item: %w", err)
+	}
+
+	invoiceParams := &stripe.InvoiceParams{
+		Customer:                    stripe.String(input.CustomerID),
+		AutoAdvance:                 stripe.Bool(true),
+		PendingInvoiceItemsBehavior: stripe.String("include"),
+		Metadata:                    input.Metadata,
+	}
+	invoiceParams.Context = ctx
+	invoiceParams.SetIdempotencyKey("create_invoice-" + input.IdempotencyKey)
+	stripeInvoice, err := invoice.New(invoiceParams)
+	if err != nil {
+		return Invoice{}, fmt.Errorf("payments.stripe: creating invoice: %w", err)
+	}
+
+	finalizeInvoiceParams := &stripe.InvoiceFinalizeInvoiceParams{}
+	finalizeInvoiceParams.Context = ctx
+	finalizeInvoiceParams.SetIdempotencyKey("finalize_invoice-" + input.IdempotencyKey)
+	finalizedStripeInvoice, err := invoice.FinalizeInvoice(stripeInvoice.ID, finalizeInvoiceParams)
+	if err != nil {
+		return Invoice{}, fmt.Errorf("payments.stripe: finalizing invoice [%s]: %w", stripeInvoice.ID, err)
+	}
+
+	return *convertStripeInvoice(finalizedStripeInvoice), nil
+}
+
+func (provider *stripeProvider) PayInvoice(ctx context.Context, input PayInvoiceInput) error {
+	params := &stripe.InvoicePayParams{
+		PaymentMethod: stripe.String(input.PaymentMethodID),
+	}
+	params.Context = ctx
+	params.SetIdempotencyKey("pay_invoice-" + input.IdempotencyKey)
+
+	_, err := invoice.Pay(input.InvoiceID, params)
+	if err != nil {
+		return fmt.Errorf("payments.stripe: paying invoice [%s]: %w", input.InvoiceID, err)
+	}
+
+	return nil
+}
+
+func (provider *stripeProvider) ParseWebhookEvent(payload []byte, headers http.Header) (Event, error) {
+	stripeEvent, err := webhook.ConstructEvent(payload, headers.Get("Stripe-Signature"), provider.webhookSecret)
+	if err != nil {
+		return Event{}, fmt.Errorf("payments.stripe: verifying webhook signature: %w", err)
+	}
+
+	return ConvertStripeEvent(stripeEvent)
+}
+
+// ConvertStripeEvent converts an already verified Stripe event. Events with an unknown type are
+// returned without data.
+func ConvertStripeEvent(stripeEvent stripe.Event) (event Event, err error) {
+	event = Event{
+		ID:      stripeEvent.ID,
+		Type:    EventType(stripeEvent.Type),
+		Account: stripeEvent.Account,
+	}
+
+	switch event.Type {
+	case EventTypeChargeFailed:
+		stripeCharge := &stripe.Charge{}
+		err = json.Unmarshal(stripeEvent.Data.Raw, stripeCharge)
+		if err != nil {
+			return event, fmt.Errorf("payments.ConvertStripeEvent: parsing charge: %w", err)
+		}
+		event.Charge = &Charge{
+			ID:       stripeCharge.ID,
+			Metadata: stripeCharge.Metadata,
+		}
+		if stripeCharge.Customer != nil && stripeCharge.Customer.ID != "" {
+			event.Charge.CustomerID = &stripeCharge.Customer.ID
+		}
+
+	case EventTypeCustomerUpdated:
+		stripeCustomer := &stripe.Customer{}
+		err = json.Unmarshal(stripeEvent.Data.Raw, stripeCustomer)
+		if err != nil {
+			return event, fmt.Errorf("payments.ConvertStripeEvent: parsing customer: %w", err)
+		}
+		event.Customer = new(convertStripeCustomer(stripeCustomer))
+
+	case EventTypePaymentMethodAttached:
+		stripePaymentMethod := &stripe.PaymentMethod{}
+		err = json.Unmarshal(stripeEvent.Data.Raw, stripePaymentMethod)
+		if err != nil {
+			return event, fmt.Errorf("payments.ConvertStripeEvent: parsing payment method: %w", err)
+		}
+		event.PaymentMethod = convertStripePaymentMethod(stripePaymentMethod)
+
+	case EventTypeCheckoutSessionCompleted, EventTypeCheckoutSessionExpired:
+		stripeCheckoutSession := &stripe.CheckoutSession{}
+		err = json.Unmarshal(stripeEvent.Data.Raw, stripeCheckoutSession)
+		if err != nil {
+			return event, fmt.Errorf("payments.ConvertStripeEvent: parsing checkout session: %w", err)
+		}
+		event.CheckoutSession = new(convertStripeCheckoutSession(stripeCheckoutSession))
+
+	case EventTypeInvoicePaid:
+		stripeInvoice := &stripe.Invoice{}
+		err = json.Unmarshal(stripeEvent.Data.Raw, stripeInvoice)
+		if err != nil {
+			return event, fmt.Errorf("payments.ConvertStripeEvent: parsing invoice: %w", err)
+		}
+		event.Invoice = convertStripeInvoice(stripeInvoice)
+
+	case EventTypeSubscriptionCreated, EventTypeSubscriptionUpdated, EventTypeSubscriptionDeleted:
+		stripeSubscription := &stripe.Subscription{}
+		err = json.Unmarshal(stripeEvent.Data.Raw, stripeSubscription)
+		if err != nil {
+			return event, fmt.Errorf("payments.ConvertStripeEvent: parsing subscription: %w", err)
+		}
+		event.Subscription = convertStripeSubscription(stripeSubscription)
+	}
+
+	return event, nil
+}
+
+func convertStripeCheckoutSession(stripeCheckoutSession *stripe.CheckoutSession) CheckoutSession {
+	checkoutSession := CheckoutSession{
+		ID:           stripeCheckoutSession.ID,
+		URL:          stripeCheckoutSession.URL,
+		Mode:         CheckoutSessionMode(stripeCheckoutSession.Mode),
+		Status:       CheckoutSessionStatus(stripeCheckoutSession.Status),
+		Paid:         stripeCheckoutSession.PaymentStatus == stripe.CheckoutSessionPaymentStatusPaid,
+		AmountTotal:  stripeCheckoutSession.AmountTotal,
+		Payment:      convertStripePaymentIntent(stripeCheckoutSession.PaymentIntent),
+		Subscription: convertStripeSubscription(stripeCheckoutSession.Subscription),
+		Invoice:      convertStripeInvoice(stripeCheckoutSession.Invoice),
+		LineItems:    []LineItem{},
+		Metadata:     stripeCheckoutSession.Metadata,
+	}
+
+	if stripeCheckoutSession.Customer != nil {
+		checkoutSession.CustomerID = &stripeCheckoutSession.Customer.ID
+	}
+
+	if stripeCheckoutSession.CustomerDetails != nil && stripeCheckoutSession.CustomerDetails.Address != nil {
+		checkoutSession.CustomerCountry = stripeCheckoutSession.CustomerDetails.Address.Country
+	}
+
+	// the invoice of one-time payments is attached to the payment intent
+	if checkoutSession.Invoice == nil && checkoutSession.Payment != nil {
+		checkoutSession.Invoice = checkoutSession.Payment.Invoice
+	}
+
+	if stripeCheckoutSession.LineItems != nil {
+		for _, lineItem := range stripeCheckoutSession.LineItems.Data {
+			item := LineItem{
+				Quantity: lineItem.Quantity,
+			}
+			if lineItem.Price != nil && lineItem.Price.Product != nil {
+				item.Metadata = lineItem.Price.Product.Metadata
+			}
+			checkoutSession.LineItems = append(checkoutSession.LineItems, item)
+		}
+	}
+
+	return checkoutSession
+}
+
+func convertStripePaymentIntent(stripePaymentIntent *stripe.PaymentIntent) *Payment {
+	if stripePaymentIntent == nil {
+		return nil
+	}
+
+	status := PaymentStatusPending
+	switch stripePaymentIntent.Status {
+	case stripe.PaymentIntentStatusSucceeded:
+		status = PaymentStatusSucceeded
+	case stripe.PaymentIntentStatusCanceled:
+		status = PaymentStatusCanceled
+	}
+
+	return &Payment{
+		ID:       stripePaymentIntent.ID,
+		Status:   status,
+		Amount:   stripePaymentIntent.Amount,
+		Invoice:  convertStripeInvoice(stripePaymentIntent.Invoice),
+		Metadata: stripePaymentIntent.Metadata,
+	}
+}
+
+func convertStripeInvoice(stripeInvoice *stripe.Invoice) *Invoice {
+	if stripeInvoice == nil {
+		return nil
+	}
+
+	invoice := &Invoice{
+		ID:       stripeInvoice.ID,
+		URL:      stripeInvoice.HostedInvoiceURL,
+		Metadata: stripeInvoice.Metadata,
+	}
+	if stripeInvoice.PaymentIntent != nil {
+		invoice.PaymentID = &stripeInvoice.PaymentIntent.ID
+	}
+	if stripeInvoice.Customer != nil && stripeInvoice.Customer.ID != "" {
+		invoice.CustomerID = &stripeInvoice.Customer.ID
+	}
+
+	return invoice
+}
+
+func convertStripeSubscription(stripeSubscription *stripe.Subscription) *Subscription {
+	if stripeSubscription == nil {
+		return nil
+	}
+
+	subscription := &Subscription{
+		ID:                stripeSubscription.ID,
+		Status:            SubscriptionStatus(stripeSubscription.Status),
+		CancelAtPeriodEnd: stripeSubscription.CancelAtPeriodEnd,
+		Items:             []SubscriptionItem{},
+		Metadata:          stripeSubscription.Metadata,
+	}
+	if stripeSubscription.Customer != nil && stripeSubscription.Customer.ID != "" {
+		subscription.CustomerID = &stripeSubscription.Customer.ID
+	}
+	if stripeSubscription.Items != nil {
+		for _, stripeItem := range stripeSubscription.Items.Data {
+			item := SubscriptionItem{
+				ID:       stripeItem.ID,
+				Quantity: stripeItem.Quantity,
+			}
+			if stripeItem.Price != nil {
+				item.PriceID = stripeItem.Price.ID
+			}
+			subscription.Items = append(subscription.Items, item)
+		}
+	}
+	if stripeSubscription.StartDate != 0 {
+		subscription.StartedAt = new(time.Unix(stripeSubscription.StartDate, 0).UTC())
+	}
+	if stripeSubscription.CurrentPeriodEnd != 0 {
+		subscription.CurrentPeriodEnd = new(time.Unix(stripeSubscription.CurrentPeriodEnd, 0).UTC())
+	}
+	if stripeSubscription.CanceledAt != 0 {
+		subscription.CanceledAt = new(time.Unix(stripeSubscription.CanceledAt, 0).UTC())
+	}
+
+	return subscription
+}
+
+func convertStripeRefund(stripeRefund *stripe.Refund) Refund {
+	return Refund{
+		ID:            stripeRefund.ID,
+		Status:        RefundStatus(stripeRefund.Status),
+		FailureReason: string(stripeRefund.FailureReason),
+	}
+}
+
+func convertStripeCustomer(stripeCustomer *stripe.Customer) Customer {
+	customer := Customer{
+		ID:            stripeCustomer.ID,
+		Name:          stripeCustomer.Name,
+		Email:         stripeCustomer.Email,
+		TaxIDs:        []TaxID{},
+		Subscriptions: []Subscription{},
+		Metadata:      stripeCustomer.Metadata,
+	}
+
+	if stripeCustomer.Address != nil {
+		customer.Address = Address{
+			Line1:      stripeCustomer.Address.Line1,
+			Line2:      stripeCustomer.Address.Line2,
+			City:       stripeCustomer.Address.City,
+			PostalCode: stripeCustomer.Address.PostalCode,
+			State:      stripeCustomer.Address.State,
+			Country:    stripeCustomer.Address.Country,
+		}
+	}
+
+	if stripeCustomer.TaxIDs != nil {
+		for _, stripeTaxID := range stripeCustomer.TaxIDs.Data {
+			customer.TaxIDs = append(customer.TaxIDs, convertStripeTaxID(stripeTaxID))
+		}
+	}
+
+	if stripeCustomer.Subscriptions != nil {
+		for _, stripeSubscription := range stripeCustomer.Subscriptions.Data {
+			customer.Subscriptions = append(customer.Subscriptions, *convertStripeSubscription(stripeSubscription))
+		}
+	}
+
+	return customer
+}
+
+func convertAddressToStripe(address Address) *stripe.AddressParams {
+	return &stripe.AddressParams{
+		Line1:      stripe.String(address.Line1),
+		Line2:      stripe.String(address.Line2),
+		City:       stripe.String(address.City),
+		PostalCode: stripe.String(address.PostalCode),
+		State:      stripe.String(address.State),
+		Country:    stripe.String(address.Country),
+	}
+}
+
+func convertStripeTaxID(stripeTaxID *stripe.TaxID) TaxID {
+	return TaxID{
+		ID:    stripeTaxID.ID,
+		Type:  TaxIDType(stripeTaxID.Type),
+		Value: stripeTaxID.Value,
+	}
+}
+
+func convertStripePaymentMethod(stripePaymentMethod *stripe.PaymentMethod) *PaymentMethod {
+	paymentMethod := &PaymentMethod{
+		ID:       stripePaymentMethod.ID,
+		Metadata: stripePaymentMethod.Metadata,
+	}
+	if stripePaymentMethod.Customer != nil && stripePaymentMethod.Customer.ID != "" {
+		paymentMethod.CustomerID = &stripePaymentMethod.Customer.ID
+	}
+	return paymentMethod
+}
+
+func convertSubscriptionItemsToStripe(items []SubscriptionItemInput) []*stripe.SubscriptionItemsParams {
+	stripeItems := make([]*stripe.SubscriptionItemsParams, len(items))
+	for i, item := range items {
+		stripeItems[i] = &stripe.SubscriptionItemsParams{
+			Price:    stripe.String(item.PriceID),
+			Quantity: stripe.Int64(item.Quantity),
+		}
+	}
+	return stripeItems
+}
+
+func hasStripePaymentMethodExpired(paymentMethod *stripe.PaymentMethod) bool {
+	now := time.Now()
+
+	if paymentMethod.Card == nil {
+		return false
+	}
+
+	return paymentMethod.Card.ExpYear < int64(now.Year()) ||
+		(paymentMethod.Card.ExpYear == int64(now.Year()) && paymentMethod.Card.ExpMonth <= int64(now.Month()))
+}
@@ -0,0 +1,55 @@
+package payments
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stripe/stripe-go/v81"
+)
+
+func TestConvertStripeEvent(t *testing.T) {
+	stripeEvent := stripe.Event{
+		ID:   "evt_1",
+		Type: stripe.EventType(EventTypeSubscriptionUpdated),
+		Data: &stripe.EventData{
+			Raw: json.RawMessage(`{
+				"id": "sub_1",
+				"status": "past_due",
+				"customer": "cus_1",
+				"start_date": 1760000000,
+				"metadata": {"markdown_ninja_plan": "pro"},
+				"items": {"data": [{"id": "si_1", "quantity": 3, "price": {"id": "price_slots"}}]}
+			}`),
+		},
+	}
+
+	event, err := ConvertStripeEvent(stripeEvent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscription := event.Subscription
+	if subscription == nil || subscription.Status != SubscriptionStatusPastDue ||
+		subscription.CustomerID == nil || *subscription.CustomerID != "cus_1" ||
+		subscription.StartedAt == nil || subscription.Metadata["markdown_ninja_plan"] != "pro" {
+		t.Fatalf("unexpected subscription: %+v", subscription)
+	}
+	if len(subscription.Items) != 1 || subscription.Items[0].PriceID != "price_slots" || subscription.Items[0].Quantity != 3 {
+		t.Errorf("unexpected subscription items: %+v", subscription.Items)
+	}
+
+	stripeEvent = stripe.Event{
+		ID:   "evt_2",
+		Type: stripe.EventType(EventTypePaymentMethodAttached),
+		Data: &stripe.EventData{
+			Raw: json.RawMessage(`{"id": "pm_1", "customer": "cus_1", "metadata": {}}`),
+		},
+	}
+	event, err = ConvertStripeEvent(stripeEvent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.PaymentMethod == nil || event.PaymentMethod.ID != "pm_1" ||
+		event.PaymentMethod.CustomerID == nil || *event.PaymentMethod.CustomerID != "cus_1" {
+		t.Errorf("unexpected payment method: %+v", event.PaymentMethod)
+	}
+}
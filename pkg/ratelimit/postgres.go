@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/zeebo/blake3"
+)
+
+// PostgresLimiter is a sliding-window Limiter storing its counters in the rate_limits table so that
+// all the instances connected to the same database share the same quotas.
+//
+// It uses the sliding window counter algorithm: the count of the previous window is weighted by the
+// fraction of the previous window still covered by the sliding window, and added to the count of the
+// current window.
+//
+// If the database can't be reached, actions are allowed.
+type PostgresLimiter struct {
+	db     db.DB
+	logger *slog.Logger
+	stop   chan struct{}
+}
+
+// NewPostgresLimiter creates a new Postgres-backed rate limiter with automatic cleanup of expired counters.
+func NewPostgresLimiter(db db.DB, logger *slog.Logger) *PostgresLimiter {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	limiter := &PostgresLimiter{
+		db:     db,
+		logger: logger,
+		stop:   make(chan struct{}),
+	}
+	go limiter.cleanupLoop()
+	return limiter
+}
+
+// IsAllowed implements Limiter
+func (limiter *PostgresLimiter) IsAllowed(ctx context.Context, action string, namespace []byte, actor []byte, timeBucket time.Duration, allowed uint64) bool {
+	now := time.Now().UTC()
+	windowStart := now.Truncate(timeBucket)
+	key := limiter.makeKey(action, namespace, actor, uint64(timeBucket.Nanoseconds()))
+
+	previousCount, err := limiter.getCount(ctx, key, windowStart.Add(-timeBucket))
+	if err != nil {
+		limiter.logError(ctx, err)
+		return true
+	}
+
+	previousWindowWeight := 1 - float64(now.Sub(windowStart))/float64(timeBucket)
+	weightedPreviousCount := uint64(float64(previousCount) * previousWindowWeight)
+	if weightedPreviousCount >= allowed {
+		return false
+	}
+
+	incremented, err := limiter.increment(ctx, key, windowStart, windowStart.Add(timeBucket*2), allowed-weightedPreviousCount)
+	if err != nil {
+		limiter.logError(ctx, err)
+		return true
+	}
+
+	return incremented
+}
+
+// Stop stops the background cleanup goroutine.
+// Call this when the limiter is no longer needed.
+func (limiter *PostgresLimiter) Stop() {
+	close(limiter.stop)
+}
+
+func (limiter *PostgresLimiter) getCount(ctx context.Context, key []byte, windowStart time.Time) (count int64, err error) {
+	const query = "SELECT count FROM rate_limits WHERE key = $1 AND window_start = $2"
+
+	err = limiter.db.Get(ctx, &count, query, key, windowStart)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// increment atomically increments the counter of the current window if it is below maxCount
+func (limiter *PostgresLimiter) increment(ctx context.Context, key []byte, windowStart, expiresAt time.Time, maxCount uint64) (incremented bool, err error) {
+	const query = `INSERT INTO rate_limits (key, window_start, count, expires_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (key, window_start) DO UPDATE SET count = rate_limits.count + 1
+			WHERE rate_limits.count < $4
+		RETURNING count`
+
+	var count int64
+	err = limiter.db.Get(ctx, &count, query, key, windowStart, expiresAt, int64(maxCount))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (limiter *PostgresLimiter) cleanupLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			limiter.cleanup()
+		case <-limiter.stop:
+			return
+		}
+	}
+}
+
+func (limiter *PostgresLimiter) cleanup() {
+	const query = "DELETE FROM rate_limits WHERE expires_at < $1"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := limiter.db.Exec(ctx, query, time.Now().UTC())
+	if err != nil {
+		limiter.logger.Warn("ratelimit: error deleting expired rate limits", slogx.Err(err))
+	}
+}
+
+func (limiter *PostgresLimiter) logError(ctx context.Context, err error) {
+	slogx.FromCtx(ctx).Error("ratelimit: error checking rate limit", slogx.Err(err))
+}
+
+// makeKey returns a stable key, shared by all the instances, by hashing the inputs with BLAKE3.
+// Variable-length inputs are length-prefixed to avoid collisions.
+func (limiter *PostgresLimiter) makeKey(action string, namespace []byte, actor []byte, timeBucketNanos uint64) []byte {
+	hasher := blake3.New()
+	binary.Write(hasher, binary.LittleEndian, uint64(len(action)))
+	hasher.Write([]byte(action))
+	binary.Write(hasher, binary.LittleEndian, uint64(len(namespace)))
+	hasher.Write(namespace)
+	binary.Write(hasher, binary.LittleEndian, uint64(len(actor)))
+	hasher.Write(actor)
+	binary.Write(hasher, binary.LittleEndian, timeBucketNanos)
+
+	return hasher.Sum(nil)
+}
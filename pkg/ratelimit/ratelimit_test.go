@@ -1,13 +1,16 @@
 package ratelimit
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
 )
 
+var ctx = context.Background()
+
 func TestRateLimit_Basic(t *testing.T) {
-	l := New()
+	l := NewMemoryLimiter()
 	defer l.Stop()
 
 	actor := []byte("user123")
@@ -17,24 +20,24 @@ func TestRateLimit_Basic(t *testing.T) {
 
 	// First 3 should be allowed
 	for i := 0; i < 3; i++ {
-		if !l.IsAllowed(action, nil, actor, bucket, allowed) {
+		if !l.IsAllowed(ctx, action, nil, actor, bucket, allowed) {
 			t.Errorf("Request %d should have been allowed", i+1)
 		}
 	}
 
 	// 4th should be rate limited
-	if l.IsAllowed(action, nil, actor, bucket, allowed) {
+	if l.IsAllowed(ctx, action, nil, actor, bucket, allowed) {
 		t.Error("Request 4 should have been rate limited")
 	}
 
 	// 5th should also be rate limited
-	if l.IsAllowed(action, nil, actor, bucket, allowed) {
+	if l.IsAllowed(ctx, action, nil, actor, bucket, allowed) {
 		t.Error("Request 5 should have been rate limited")
 	}
 }
 
 func TestRateLimit_DifferentActors(t *testing.T) {
-	l := New()
+	l := NewMemoryLimiter()
 	defer l.Stop()
 
 	namespace := []byte("namespace1")
@@ -45,22 +48,22 @@ func TestRateLimit_DifferentActors(t *testing.T) {
 	allowed := uint64(2)
 
 	// Actor 1 uses both requests
-	l.IsAllowed(action, namespace, actor1, bucket, allowed)
-	l.IsAllowed(action, namespace, actor1, bucket, allowed)
+	l.IsAllowed(ctx, action, namespace, actor1, bucket, allowed)
+	l.IsAllowed(ctx, action, namespace, actor1, bucket, allowed)
 
 	// Actor 1 should be limited
-	if l.IsAllowed(action, namespace, actor1, bucket, allowed) {
+	if l.IsAllowed(ctx, action, namespace, actor1, bucket, allowed) {
 		t.Error("Actor1 should be rate limited")
 	}
 
 	// Actor 2 should still be allowed
-	if !l.IsAllowed(action, namespace, actor2, bucket, allowed) {
+	if !l.IsAllowed(ctx, action, namespace, actor2, bucket, allowed) {
 		t.Error("Actor2 should be allowed")
 	}
 }
 
 func TestRateLimit_DifferentActions(t *testing.T) {
-	l := New()
+	l := NewMemoryLimiter()
 	defer l.Stop()
 
 	actor := []byte("user123")
@@ -71,21 +74,21 @@ func TestRateLimit_DifferentActions(t *testing.T) {
 	allowed := uint64(1)
 
 	// Use up action1 limit
-	l.IsAllowed(action1, namespace, actor, bucket, allowed)
+	l.IsAllowed(ctx, action1, namespace, actor, bucket, allowed)
 
 	// Action1 should be limited
-	if l.IsAllowed(action1, namespace, actor, bucket, allowed) {
+	if l.IsAllowed(ctx, action1, namespace, actor, bucket, allowed) {
 		t.Error("Action1 should be rate limited")
 	}
 
 	// Action2 should still be allowed
-	if !l.IsAllowed(action2, namespace, actor, bucket, allowed) {
+	if !l.IsAllowed(ctx, action2, namespace, actor, bucket, allowed) {
 		t.Error("Action2 should be allowed")
 	}
 }
 
 func TestRateLimit_BucketReset(t *testing.T) {
-	l := New()
+	l := NewMemoryLimiter()
 	defer l.Stop()
 
 	namespace := []byte("namespace")
@@ -95,10 +98,10 @@ func TestRateLimit_BucketReset(t *testing.T) {
 	allowed := uint64(2)
 
 	// Use up the limit
-	l.IsAllowed(action, namespace, actor, bucket, allowed)
-	l.IsAllowed(action, namespace, actor, bucket, allowed)
+	l.IsAllowed(ctx, action, namespace, actor, bucket, allowed)
+	l.IsAllowed(ctx, action, namespace, actor, bucket, allowed)
 
-	if l.IsAllowed(action, namespace, actor, bucket, allowed) {
+	if l.IsAllowed(ctx, action, namespace, actor, bucket, allowed) {
 		t.Error("Should be rate limited")
 	}
 
@@ -106,13 +109,13 @@ func TestRateLimit_BucketReset(t *testing.T) {
 	time.Sleep(150 * time.Millisecond)
 
 	// Should be allowed again
-	if !l.IsAllowed(action, namespace, actor, bucket, allowed) {
+	if !l.IsAllowed(ctx, action, namespace, actor, bucket, allowed) {
 		t.Error("Should be allowed after bucket reset")
 	}
 }
 
 func TestCount(t *testing.T) {
-	l := New()
+	l := NewMemoryLimiter()
 	defer l.Stop()
 
 	namespace := []byte("namespace")
@@ -124,8 +127,8 @@ func TestCount(t *testing.T) {
 		t.Errorf("Expected count 0, got %d", c)
 	}
 
-	l.IsAllowed(action, namespace, actor, bucket, 10)
-	l.IsAllowed(action, namespace, actor, bucket, 10)
+	l.IsAllowed(ctx, action, namespace, actor, bucket, 10)
+	l.IsAllowed(ctx, action, namespace, actor, bucket, 10)
 
 	if c := l.Count(action, namespace, actor, bucket); c != 2 {
 		t.Errorf("Expected count 2, got %d", c)
@@ -133,7 +136,7 @@ func TestCount(t *testing.T) {
 }
 
 func TestRemaining(t *testing.T) {
-	l := New()
+	l := NewMemoryLimiter()
 	defer l.Stop()
 
 	namespace := []byte("namespace")
@@ -146,8 +149,8 @@ func TestRemaining(t *testing.T) {
 		t.Errorf("Expected 5 remaining, got %d", r)
 	}
 
-	l.IsAllowed(action, namespace, actor, bucket, allowed)
-	l.IsAllowed(action, namespace, actor, bucket, allowed)
+	l.IsAllowed(ctx, action, namespace, actor, bucket, allowed)
+	l.IsAllowed(ctx, action, namespace, actor, bucket, allowed)
 
 	if r := l.Remaining(action, namespace, actor, bucket, allowed); r != 3 {
 		t.Errorf("Expected 3 remaining, got %d", r)
@@ -155,7 +158,7 @@ func TestRemaining(t *testing.T) {
 }
 
 func TestRateLimit_Concurrent(t *testing.T) {
-	l := New()
+	l := NewMemoryLimiter()
 	defer l.Stop()
 
 	namespace := []byte("namespace")
@@ -173,7 +176,7 @@ func TestRateLimit_Concurrent(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if l.IsAllowed(action, namespace, actor, bucket, allowed) {
+			if l.IsAllowed(ctx, action, namespace, actor, bucket, allowed) {
 				mu.Lock()
 				allowedCount++
 				mu.Unlock()
@@ -190,7 +193,7 @@ func TestRateLimit_Concurrent(t *testing.T) {
 }
 
 func TestRateLimit_BinaryActor(t *testing.T) {
-	l := New()
+	l := NewMemoryLimiter()
 	defer l.Stop()
 
 	// Test with binary data containing special characters
@@ -200,10 +203,10 @@ func TestRateLimit_BinaryActor(t *testing.T) {
 	allowed := uint64(1)
 	namespace := []byte("namespace")
 
-	if !l.IsAllowed(action, namespace, actor, bucket, allowed) {
+	if !l.IsAllowed(ctx, action, namespace, actor, bucket, allowed) {
 		t.Error("First request should be allowed")
 	}
-	if l.IsAllowed(action, namespace, actor, bucket, allowed) {
+	if l.IsAllowed(ctx, action, namespace, actor, bucket, allowed) {
 		t.Error("Second request should be rate limited")
 	}
 }
@@ -55,6 +55,24 @@ func Start(ctx context.Context, db db.DB, queue queue.Queue, jwtProvider *jwt.Pr
 		return err
 	}
 
+	// every minutes
+	err = cronScheduler.Schedule("emails.TaskSendSequenceEmails", "00 * * * * *", emailsService.TaskSendSequenceEmails)
+	if err != nil {
+		return err
+	}
+
+	// every minutes
+	err = cronScheduler.Schedule("emails.TaskSendNewsletterEmails", "00 * * * * *", emailsService.TaskSendNewsletterEmails)
+	if err != nil {
+		return err
+	}
+
+	// every 5 minutes
+	err = cronScheduler.Schedule("emails.TaskSendNewsletterDigests", "00 */5 * * * *", emailsService.TaskSendNewsletterDigests)
+	if err != nil {
+		return err
+	}
+
 	// every minutes
 	err = cronScheduler.Schedule("content.PublishPosts", "00 * * * * *", contentService.TaskPublishPages)
 	if err != nil {
@@ -67,6 +85,18 @@ func Start(ctx context.Context, db db.DB, queue queue.Queue, jwtProvider *jwt.Pr
 		return err
 	}
 
+	// every 10 minutes
+	err = cronScheduler.Schedule("store.TaskSendAbandonedCheckoutEmails", "00 */10 * * * *", storeService.TaskSendAbandonedCheckoutEmails)
+	if err != nil {
+		return err
+	}
+
+	// every 6 hours
+	err = cronScheduler.Schedule("kernel.TaskDeleteExpiredAuthData", "00 02 */6 * * *", kernelService.TaskDeleteExpiredAuthData)
+	if err != nil {
+		return err
+	}
+
 	// every 6 hours
 	err = cronScheduler.Schedule("contacts.TaskDeleteOldUnverifiedSessions", "00 01 */6 * * *", contactsService.TaskDeleteOldUnverifiedSessions)
 	if err != nil {
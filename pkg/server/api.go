@@ -69,7 +69,14 @@ func (server *server) Api(apiRouter chi.Router) {
 	// Webhooks
 	////////////////////////////////////////////////////////////////////////////////////////////////
 	apiRouter.Post(api.RouteWebhooksStripe, server.stripeWebhook)
-	apiRouter.Post(api.RouteWebhooksPingoo, server.pingooWebhookHandler)
+	if server.pingooClient != nil {
+		apiRouter.Post(api.RouteWebhooksPingoo, server.pingooWebhookHandler)
+	}
+	if server.emailsConfig.FeedbackWebhookSecret != "" {
+		apiRouter.Post(api.RouteWebhooksEmailsSes, server.sesWebhookHandler)
+		apiRouter.Post(api.RouteWebhooksEmailsFeedback, server.emailFeedbackWebhookHandler)
+		apiRouter.Post(api.RouteWebhooksEmailsDsn, server.emailDsnWebhookHandler)
+	}
 
 	////////////////////////////////////////////////////////////////////////////////////////////////
 	// Kernel
@@ -80,6 +87,23 @@ func (server *server) Api(apiRouter chi.Router) {
 	apiRouter.Post("/list_tls_certificates", apiutil.JsonEndpoint(server.certManager.ListCertificates))
 	apiRouter.Post("/delete_tls_certificate", apiutil.JsonEndpointOk(server.certManager.DeleteTlsCertificate))
 
+	// auth
+	apiRouter.Post(api.RouteMe, apiutil.JsonEndpoint(server.kernelService.GetMe))
+	apiRouter.Post(api.RouteSignup, apiutil.JsonEndpoint(server.kernelService.Signup))
+	apiRouter.Post(api.RouteCompleteSignup, apiutil.JsonEndpoint(server.kernelService.CompleteSignup))
+	apiRouter.Post(api.RouteLogin, apiutil.JsonEndpoint(server.kernelService.Login))
+	apiRouter.Post(api.RouteCompleteLogin, apiutil.JsonEndpoint(server.kernelService.CompleteLogin))
+	apiRouter.Post(api.RouteComplete2faChallenge, apiutil.JsonEndpoint(server.kernelService.Complete2faChallenge))
+	apiRouter.Post(api.RouteLogout, apiutil.JsonEndpointOk(server.kernelService.Logout))
+	apiRouter.Get(api.RouteOidcLogin, server.kernelService.ServeOidcLogin)
+	apiRouter.Get(api.RouteOidcCallback, server.kernelService.ServeOidcCallback)
+	apiRouter.Post(api.RouteUpdateMyPassword, apiutil.JsonEndpointOk(server.kernelService.UpdateMyPassword))
+	apiRouter.Post(api.RouteSetup2fa, apiutil.JsonEndpoint(server.kernelService.SetupTwoFa))
+	apiRouter.Post(api.RouteEnable2fa, apiutil.JsonEndpointOk(server.kernelService.EnableTwoFa))
+	apiRouter.Post(api.RouteDisable2fa, apiutil.JsonEndpointOk(server.kernelService.DisableTwoFa))
+	apiRouter.Post(api.RouteMySessions, apiutil.JsonEndpoint(server.kernelService.ListMySessions))
+	apiRouter.Post(api.RouteRevokeSession, apiutil.JsonEndpointOk(server.kernelService.RevokeSession))
+
 	// jobs queue
 	apiRouter.Post(api.RouteFailedBackgroundJobs, apiutil.JsonEndpoint(server.kernelService.ListFailedBackgroundJobs))
 	apiRouter.Post(api.RouteDeleteBackgroundJob, apiutil.JsonEndpointOk(server.kernelService.DeleteBackgroundJob))
@@ -122,6 +146,8 @@ func (server *server) Api(apiRouter chi.Router) {
 	apiRouter.Post(api.RouteWebsite, apiutil.JsonEndpoint(server.websitesService.GetWebsite))
 	apiRouter.Post(api.RouteUpdateWebsite, apiutil.JsonEndpoint(server.websitesService.UpdateWebsite))
 	apiRouter.Post(api.RouteSaveRedirect, apiutil.JsonEndpoint(server.websitesService.SaveRedirects))
+	apiRouter.Post(api.RouteSaveFirewallRules, apiutil.JsonEndpoint(server.websitesService.SaveFirewallRules))
+	apiRouter.Post(api.RouteFirewallRules, apiutil.JsonEndpoint(server.websitesService.ListFirewallRules))
 	apiRouter.Post(api.RouteAllWebsites, apiutil.JsonEndpoint(server.websitesService.ListWebsites))
 	apiRouter.Post(api.RouteWebsiteUpdateIcon, server.websiteUpdateIcon)
 	apiRouter.Post(api.RouteWebsitesAdminStatistics, apiutil.JsonEndpoint(server.websitesService.GetAdminStatistics))
@@ -173,6 +199,9 @@ func (server *server) Api(apiRouter chi.Router) {
 	apiRouter.Post(api.RouteExportContactsForProduct, apiutil.JsonEndpoint(server.contactsService.ExportContactsForProduct))
 	apiRouter.Post(api.RouteBlockContact, apiutil.JsonEndpoint(server.contactsService.BlockContact))
 	apiRouter.Post(api.RouteUnblockContact, apiutil.JsonEndpoint(server.contactsService.UnblockContact))
+	apiRouter.Post(api.RouteMemberships, apiutil.JsonEndpoint(server.storeService.ListMemberships))
+	apiRouter.Post(api.RouteGrantMembership, apiutil.JsonEndpoint(server.storeService.GrantMembership))
+	apiRouter.Post(api.RouteCancelMembership, apiutil.JsonEndpoint(server.storeService.CancelMembership))
 
 	////////////////////////////////////////////////////////////////////////////////////////////////
 	// Emails
@@ -190,6 +219,22 @@ func (server *server) Api(apiRouter chi.Router) {
 	apiRouter.Post(api.RouteUpdateNewsletter, apiutil.JsonEndpoint(server.emailsService.UpdateNewsletter))
 	apiRouter.Post(api.RouteDeleteNewsletter, apiutil.JsonEndpointOk(server.emailsService.DeleteNewsletter))
 	apiRouter.Post(api.RouteSendNewsletter, apiutil.JsonEndpoint(server.emailsService.SendNewsletter))
+	apiRouter.Post(api.RoutePauseNewsletter, apiutil.JsonEndpoint(server.emailsService.PauseNewsletter))
+	apiRouter.Post(api.RouteResumeNewsletter, apiutil.JsonEndpoint(server.emailsService.ResumeNewsletter))
+	apiRouter.Post(api.RouteNewsletterAnalytics, apiutil.JsonEndpoint(server.emailsService.GetNewsletterAnalytics))
+
+	// email templates
+	apiRouter.Post(api.RouteEmailTemplates, apiutil.JsonEndpoint(server.emailsService.GetEmailTemplates))
+	apiRouter.Post(api.RouteUpdateEmailTemplate, apiutil.JsonEndpoint(server.emailsService.UpdateEmailTemplate))
+	apiRouter.Post(api.RoutePreviewEmailTemplate, apiutil.JsonEndpoint(server.emailsService.PreviewEmailTemplate))
+	apiRouter.Post(api.RouteSendTestEmailTemplate, apiutil.JsonEndpointOk(server.emailsService.SendTestEmailTemplate))
+
+	// email sequences
+	apiRouter.Post(api.RouteEmailSequences, apiutil.JsonEndpoint(server.emailsService.GetSequences))
+	apiRouter.Post(api.RouteEmailSequence, apiutil.JsonEndpoint(server.emailsService.GetSequence))
+	apiRouter.Post(api.RouteCreateEmailSequence, apiutil.JsonEndpoint(server.emailsService.CreateSequence))
+	apiRouter.Post(api.RouteUpdateEmailSequence, apiutil.JsonEndpoint(server.emailsService.UpdateSequence))
+	apiRouter.Post(api.RouteDeleteEmailSequence, apiutil.JsonEndpointOk(server.emailsService.DeleteSequence))
 
 	////////////////////////////////////////////////////////////////////////////////////////////////
 	// Store
@@ -203,11 +248,23 @@ func (server *server) Api(apiRouter chi.Router) {
 	apiRouter.Post(api.RouteGiveContactsAccessToProduct, apiutil.JsonEndpointOk(server.storeService.GiveContactsAccessToProduct))
 	apiRouter.Post(api.RouteDeleteProduct, apiutil.JsonEndpointOk(server.storeService.DeleteProduct))
 	apiRouter.Post(api.RouteRemoveAccessToProduct, apiutil.JsonEndpointOk(server.storeService.RemoveAccessToProduct))
+	apiRouter.Post(api.RouteTransferProductAccess, apiutil.JsonEndpointOk(server.storeService.TransferProductAccess))
 
 	// orders
 	apiRouter.Post(api.RouteOrders, apiutil.JsonEndpoint(server.storeService.ListOrders))
 	apiRouter.Post(api.RouteOrder, apiutil.JsonEndpoint(server.storeService.GetOrder))
 
+	// abandoned checkouts
+	apiRouter.Post(api.RouteAbandonedCheckoutSettings, apiutil.JsonEndpoint(server.storeService.GetAbandonedCheckoutSettings))
+	apiRouter.Post(api.RouteUpdateAbandonedCheckoutSettings, apiutil.JsonEndpoint(server.storeService.UpdateAbandonedCheckoutSettings))
+
+	// invoices
+	apiRouter.Post(api.RouteInvoicingSettings, apiutil.JsonEndpoint(server.storeService.GetInvoicingSettings))
+	apiRouter.Post(api.RouteUpdateInvoicingSettings, apiutil.JsonEndpoint(server.storeService.UpdateInvoicingSettings))
+	apiRouter.Post(api.RouteInvoices, apiutil.JsonEndpoint(server.storeService.ListInvoices))
+	apiRouter.Get(api.RouteInvoiceFile, server.storeService.ServeInvoice)
+	apiRouter.Post(api.RouteExportAccounting, apiutil.JsonEndpoint(server.storeService.ExportAccounting))
+
 	// refunds
 	apiRouter.Post(api.RouteRefunds, apiutil.JsonEndpoint(server.storeService.ListRefunds))
 	apiRouter.Post(api.RouteCreateRefund, apiutil.JsonEndpoint(server.storeService.CreateRefund))
@@ -218,11 +275,17 @@ func (server *server) Api(apiRouter chi.Router) {
 	apiRouter.Post(api.RouteCoupon, apiutil.JsonEndpoint(server.storeService.GetCoupon))
 	apiRouter.Post(api.RouteCoupons, apiutil.JsonEndpoint(server.storeService.ListCoupons))
 
+	// license keys
+	apiRouter.Post(api.RouteLicenseKeys, apiutil.JsonEndpoint(server.storeService.ListLicenseKeys))
+	apiRouter.Post(api.RouteAddLicenseKeysToPool, apiutil.JsonEndpointOk(server.storeService.AddLicenseKeysToPool))
+	apiRouter.Post(api.RouteRevokeLicenseKey, apiutil.JsonEndpoint(server.storeService.RevokeLicenseKey))
+
 	// courses
 	apiRouter.Post(api.RouteCreateProductPage, apiutil.JsonEndpoint(server.storeService.CreateProductPage))
 	apiRouter.Post(api.RouteUpdateProductPage, apiutil.JsonEndpoint(server.storeService.UpdateProductPage))
 	apiRouter.Post(api.RouteDeleteProductPage, apiutil.JsonEndpointOk(server.storeService.DeleteProductPage))
 	apiRouter.Post(api.RouteProductPage, apiutil.JsonEndpoint(server.storeService.GetProductPage))
+	apiRouter.Post(api.RouteCourseAnalytics, apiutil.JsonEndpoint(server.storeService.GetCourseAnalytics))
 
 	////////////////////////////////////////////////////////////////////////////////////////////////
 	// Analytics
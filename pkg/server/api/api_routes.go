@@ -9,6 +9,10 @@ const (
 	// webhooks
 	RouteWebhooksStripe = "/webhooks/stripe"
 	RouteWebhooksPingoo = "/webhooks/pingoo/{secret}"
+	// bounces and complaints reported by the email providers
+	RouteWebhooksEmailsSes      = "/webhooks/emails/ses/{secret}"
+	RouteWebhooksEmailsFeedback = "/webhooks/emails/feedback"
+	RouteWebhooksEmailsDsn      = "/webhooks/emails/dsn"
 
 	// background jobs
 	RouteFailedBackgroundJobs = "/queue/failed_background_jobs"
@@ -16,6 +20,23 @@ const (
 
 	// users
 	RouteInit = "/init"
+	RouteMe   = "/me"
+
+	// auth (local identity provider)
+	RouteSignup               = "/signup"
+	RouteCompleteSignup       = "/complete_signup"
+	RouteLogin                = "/login"
+	RouteCompleteLogin        = "/complete_login"
+	RouteComplete2faChallenge = "/complete_2fa_challenge"
+	RouteLogout               = "/logout"
+	RouteOidcLogin            = "/oidc/login"
+	RouteOidcCallback         = "/oidc/callback"
+	RouteUpdateMyPassword     = "/update_my_password"
+	RouteSetup2fa             = "/setup_2fa"
+	RouteEnable2fa            = "/enable_2fa"
+	RouteDisable2fa           = "/disable_2fa"
+	RouteMySessions           = "/my_sessions"
+	RouteRevokeSession        = "/revoke_session"
 
 	// organizations
 	RouteOrganizationsAdminStatistics     = "/organizations/admin-statistics"
@@ -72,6 +93,10 @@ const (
 	// redirects
 	RouteSaveRedirect = "/save_redirects"
 
+	// firewall
+	RouteSaveFirewallRules = "/save_firewall_rules"
+	RouteFirewallRules     = "/firewall_rules"
+
 	// assets
 	RouteUploadAsset       = "/upload_asset"
 	RouteDeleteAsset       = "/delete_asset"
@@ -101,19 +126,38 @@ const (
 	RouteExportContactsForProduct = "/export_contacts_for_product"
 	RouteBlockContact             = "/block_contact"
 	RouteUnblockContact           = "/unblock_contact"
+	RouteMemberships              = "/memberships"
+	RouteGrantMembership          = "/grant_membership"
+	RouteCancelMembership         = "/cancel_membership"
 
 	// emails configuration
 	RouteEmailsConfiguration          = "/emails_configuration"
 	RouteUpdateEmailsConfiguration    = "/update_emails_configuration"
 	RouteVerifyEmailsDnsConfiguration = "/verify_emails_dns_configuration"
 
+	// email templates
+	RouteEmailTemplates        = "/email_templates"
+	RouteUpdateEmailTemplate   = "/update_email_template"
+	RoutePreviewEmailTemplate  = "/preview_email_template"
+	RouteSendTestEmailTemplate = "/send_test_email_template"
+
 	// newsletters
-	RouteNewsletters      = "/newsletters"
-	RouteNewsletter       = "/newsletter"
-	RouteCreateNewsletter = "/create_newsletter"
-	RouteUpdateNewsletter = "/update_newsletter"
-	RouteDeleteNewsletter = "/delete_newsletter"
-	RouteSendNewsletter   = "/send_newsletter"
+	RouteNewsletters         = "/newsletters"
+	RouteNewsletter          = "/newsletter"
+	RouteCreateNewsletter    = "/create_newsletter"
+	RouteUpdateNewsletter    = "/update_newsletter"
+	RouteDeleteNewsletter    = "/delete_newsletter"
+	RouteSendNewsletter      = "/send_newsletter"
+	RoutePauseNewsletter     = "/pause_newsletter"
+	RouteResumeNewsletter    = "/resume_newsletter"
+	RouteNewsletterAnalytics = "/newsletter_analytics"
+
+	// email sequences
+	RouteEmailSequences      = "/email_sequences"
+	RouteEmailSequence       = "/email_sequence"
+	RouteCreateEmailSequence = "/create_email_sequence"
+	RouteUpdateEmailSequence = "/update_email_sequence"
+	RouteDeleteEmailSequence = "/delete_email_sequence"
 
 	// products
 	RouteProduct                     = "/product"
@@ -123,11 +167,23 @@ const (
 	RouteGiveContactsAccessToProduct = "/give_contacts_access_to_product"
 	RouteDeleteProduct               = "/delete_product"
 	RouteRemoveAccessToProduct       = "/remove_access_to_product"
+	RouteTransferProductAccess       = "/transfer_product_access"
 
 	// orders
 	RouteOrders = "/orders"
 	RouteOrder  = "/order"
 
+	// abandoned checkouts
+	RouteAbandonedCheckoutSettings       = "/abandoned_checkout_settings"
+	RouteUpdateAbandonedCheckoutSettings = "/update_abandoned_checkout_settings"
+
+	// invoices
+	RouteInvoicingSettings       = "/invoicing_settings"
+	RouteUpdateInvoicingSettings = "/update_invoicing_settings"
+	RouteInvoices                = "/invoices"
+	RouteInvoiceFile             = "/invoices/{invoice_id}/{format}"
+	RouteExportAccounting        = "/export_accounting"
+
 	// refunds
 	RouteRefunds      = "/refunds"
 	RouteCreateRefund = "/create_refund"
@@ -137,6 +193,12 @@ const (
 	RouteUpdateProductPage = "/update_product_page"
 	RouteDeleteProductPage = "/delete_product_page"
 	RouteProductPage       = "/product_page"
+	RouteCourseAnalytics   = "/course_analytics"
+
+	// license keys
+	RouteLicenseKeys          = "/license_keys"
+	RouteAddLicenseKeysToPool = "/add_license_keys_to_pool"
+	RouteRevokeLicenseKey     = "/revoke_license_key"
 
 	// coupons
 	RouteCreateCoupon = "/create_coupon"
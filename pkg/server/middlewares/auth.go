@@ -6,10 +6,8 @@ import (
 	"strings"
 
 	"github.com/skerkour/stdx-go/set"
-	"markdown.ninja/pingoo-go"
 	"markdown.ninja/pkg/errs"
 	"markdown.ninja/pkg/server/apiutil"
-	"markdown.ninja/pkg/server/auth"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/contacts"
 	"markdown.ninja/pkg/services/kernel"
@@ -21,7 +19,6 @@ type authMiddleware struct {
 	kernelService        kernel.PrivateService
 	organizationsService organizations.Service
 	contactsService      contacts.Service
-	pingooClient         *pingoo.Client
 }
 
 var allowPathsWithoutAuth = set.NewFromSlice([]string{
@@ -29,24 +26,27 @@ var allowPathsWithoutAuth = set.NewFromSlice([]string{
 	"/api/signup",
 	"/api/complete_signup",
 	"/api/login",
+	"/api/complete_login",
 	"/api/complete_2fa_challenge",
+	"/api/oidc/login",
+	"/api/oidc/callback",
 })
 
 // Auth is an HTTP middleware that checks authentication and return an error code if
 // some credentials are present but not valid.
-// for the webapp domain it checks the users auth cookie and the `Authorization` header (for API keys)
+// for the webapp domain it checks the users auth cookie (local identity provider) and the `Authorization` header
+// (for API keys and access tokens)
 // for the websites domaians it checks the contacts auth cookie
 // if the authentication is successful then the autenticated entity is injected into the request's context
 // no rate-limiting is performed by the Auth middleware. Rate-limiting should be performed by dowstream
 // services.
 func Auth(webappDomain string, kernelService kernel.PrivateService, organizationsService organizations.Service,
-	contactsService contacts.Service, pingooClient *pingoo.Client) func(next http.Handler) http.Handler {
+	contactsService contacts.Service) func(next http.Handler) http.Handler {
 	authMiddleware := &authMiddleware{
 		webappDomain:         webappDomain,
 		kernelService:        kernelService,
 		organizationsService: organizationsService,
 		contactsService:      contactsService,
-		pingooClient:         pingooClient,
 	}
 	return authMiddleware.Middleware
 }
@@ -104,7 +104,7 @@ func (middleware *authMiddleware) handleWebappAuth(ctx context.Context, w http.R
 			httpCtx.ApiKey = &apiKey
 			return nil
 		case "bearer":
-			accessToken, err := pingoo.VerifyJWT[auth.AccessToken](ctx, middleware.pingooClient, token)
+			accessToken, err := middleware.kernelService.VerifyAccessToken(ctx, token)
 			if err != nil {
 				apiutil.SendError(ctx, w, err)
 				return err
@@ -119,6 +119,25 @@ func (middleware *authMiddleware) handleWebappAuth(ctx context.Context, w http.R
 		return nil
 	}
 
+	// session cookie of the local identity provider
+	// we can ignore error as req.Cookie will returns an error only if the cookie is missing
+	authCookie, _ := req.Cookie(kernel.AuthCookie)
+	if authCookie != nil {
+		accessToken, err := middleware.kernelService.VerifyAccessToken(ctx, strings.TrimSpace(authCookie.Value))
+		if err == nil {
+			httpCtx.AccessToken = &accessToken
+			return nil
+		}
+		if errs.IsInternal(err) {
+			apiutil.SendError(ctx, w, err)
+			return err
+		}
+
+		// handle invalid/expired sessions
+		logoutCookie := middleware.kernelService.GenerateLogoutCookie()
+		http.SetCookie(w, &logoutCookie)
+	}
+
 	if allowPathsWithoutAuth.Contains(req.URL.Path) || strings.HasPrefix(req.URL.Path, "/api/webhooks/") {
 		return nil
 	}
@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/skerkour/stdx-go/log/slogx"
+	"markdown.ninja/pingoo-go"
+)
+
+// Geoip replaces the Pingoo middleware when Pingoo is not enabled. It applies the rules of config and
+// injects the geoip record of the client in the context, as expected by SetHTTPContext.
+func Geoip(config *pingoo.MiddlewareConfig) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(res http.ResponseWriter, req *http.Request) {
+			for _, rule := range config.Rules {
+				if rule.Match == nil || rule.Match(req) {
+					for _, action := range rule.Actions {
+						action.Apply(res, req)
+					}
+				}
+			}
+
+			ctx := req.Context()
+			var geoipRecord pingoo.GeoipRecord
+
+			// errors are reported by SetHTTPContext
+			_, clientIp, err := extractClientIpAddress(req)
+			if err == nil {
+				geoipRecord, err = config.GeoipLookup(ctx, clientIp)
+				if err != nil {
+					slogx.FromCtx(ctx).Error("middlewares.Geoip: looking up geoip information", slogx.Err(err))
+				}
+			}
+
+			ctx = context.WithValue(ctx, pingoo.CtxKeyGeoip, geoipRecord)
+			next.ServeHTTP(res, req.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
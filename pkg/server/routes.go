@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/netip"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -15,6 +16,7 @@ import (
 	"markdown.ninja/pingoo-go"
 	pingoomiddleware "markdown.ninja/pingoo-go/middleware"
 	"markdown.ninja/pingoo-go/rules"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/server/apiutil"
 	"markdown.ninja/pkg/server/middlewares"
 	"markdown.ninja/pkg/server/website"
@@ -22,7 +24,8 @@ import (
 
 func (server *server) routes(ctx context.Context) (rootRouter chi.Router, err error) {
 	rootRouter = chi.NewRouter()
-	websiteRoutes := website.Routes(ctx, server.siteService, server.contactsService, server.storeService)
+	websiteRoutes := website.Routes(ctx, server.siteService, server.contactsService, server.storeService,
+		server.emailsService)
 	// api := NewApi(server.webappDomain, server.kernelService, server.websitesService, server.contactsService,
 	// 	server.emailsService, server.storeService, server.eventsService, server.contentService, server.organizationsService)
 
@@ -42,6 +45,14 @@ func (server *server) routes(ctx context.Context) (rootRouter chi.Router, err er
 				return nil
 			},
 		},
+		GeoipLookup: func(ctx context.Context, ip netip.Addr) (pingoo.GeoipRecord, error) {
+			record, err := server.geoipProvider.Lookup(ctx, ip)
+			return pingoo.GeoipRecord{
+				AsName:  record.AsName,
+				ASN:     record.ASN,
+				Country: record.Country,
+			}, err
+		},
 		Rules: []rules.Rule{
 			{
 				Actions: []rules.Action{
@@ -74,7 +85,9 @@ func (server *server) routes(ctx context.Context) (rootRouter chi.Router, err er
 	// All routes
 	rootRouter.Use(middlewares.RequestID(requestIDHeader))
 	rootRouter.Use(middlewares.SetLogger(server.logger))
-	rootRouter.Use(pingoomiddleware.LoggingMiddleware(ctx, server.pingooClient, pingooMiddlewareConfig))
+	if server.pingooClient != nil {
+		rootRouter.Use(pingoomiddleware.LoggingMiddleware(ctx, server.pingooClient, pingooMiddlewareConfig))
+	}
 	// For now we set recover after RequestID and SetLogger because we need the logger to be set
 	// for the log to be sent, but ideally recover would be the first middleware.
 	rootRouter.Use(middlewares.Recoverer)
@@ -89,11 +102,15 @@ func (server *server) routes(ctx context.Context) (rootRouter chi.Router, err er
 	rootRouter.Use(chimiddleware.CleanPath)
 	rootRouter.Use(middlewares.Redirects(server.webappDomain, server.websitesRootDomain))
 	// rootRouter.Use(chimiddleware.RedirectSlashes)
-	rootRouter.Use(server.pingooClient.Middleware(&pingooMiddlewareConfig))
+	if server.pingooClient != nil {
+		rootRouter.Use(server.pingooClient.Middleware(&pingooMiddlewareConfig))
+	} else {
+		rootRouter.Use(middlewares.Geoip(&pingooMiddlewareConfig))
+	}
 	rootRouter.Use(middlewares.SetHTTPContext(server.pingooClient))
 	rootRouter.Use(middlewares.BlockCountries(server.blockedCountries))
 	rootRouter.Use(middlewares.Auth(server.webappDomain, server.kernelService, server.organizationsService,
-		server.contactsService, server.pingooClient))
+		server.contactsService))
 	rootRouter.Use(compressionMiddleware.Handler)
 
 	// if server.env != config.EnvDev {
@@ -113,6 +130,11 @@ func (server *server) routes(ctx context.Context) (rootRouter chi.Router, err er
 	websiteRoutes.Get("/__markdown_ninja/healthcheck", apiutil.GetEndpointOk(server.kernelService.Healthcheck))
 	webappAndApiRouter.Get("/__markdown_ninja/healthcheck", apiutil.GetEndpointOk(server.kernelService.Healthcheck))
 
+	// local checkout page when payments are simulated
+	if fakePaymentProvider, isFakePaymentProvider := server.paymentProvider.(*payments.FakeProvider); isFakePaymentProvider {
+		webappAndApiRouter.Handle(payments.FakeCheckoutPath+"*", fakePaymentProvider.CheckoutHandler())
+	}
+
 	// Host router
 	hostRouter := hostrouter.New()
 	hostRouter.Map(server.webappDomain, webappAndApiRouter)
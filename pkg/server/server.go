@@ -31,7 +31,10 @@ import (
 	"golang.org/x/sync/errgroup"
 	"markdown.ninja/cmd/mdninja-server/config"
 	"markdown.ninja/pingoo-go"
+	"markdown.ninja/pkg/emailfeedback"
+	"markdown.ninja/pkg/geoip"
 	"markdown.ninja/pkg/kms"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/services/certmanager"
 	"markdown.ninja/pkg/services/contacts"
 	"markdown.ninja/pkg/services/content"
@@ -62,15 +65,17 @@ type server struct {
 	// env                config.Env
 	webappDomain            string
 	websitesRootDomain      string
-	stripeWebhookSecret     string
 	stripePublicKey         string
 	httpConfig              config.Http
 	pingooConfig            config.Pingoo
 	pingooClient            *pingoo.Client
+	geoipProvider           geoip.Provider
+	paymentProvider         payments.Provider
 	logger                  *slog.Logger
 	webappIndexHtmlTemplate *template.Template
 	webappIndexHtmlHash     []byte
 	emailsConfig            config.Emails
+	snsVerifier             *emailfeedback.SnsVerifier
 	websitesBaseUrl         *url.URL
 	blockedCountries        []string
 
@@ -81,7 +86,9 @@ type server struct {
 	webappHandler func(res http.ResponseWriter, req *http.Request)
 }
 
-func Start(ctx context.Context, conf config.Config, db db.DB, pingooClient *pingoo.Client, kernelService kernel.Service,
+func Start(ctx context.Context, conf config.Config, db db.DB, pingooClient *pingoo.Client, geoipProvider geoip.Provider,
+	paymentProvider payments.Provider,
+	kernelService kernel.Service,
 	websitesService websites.Service, contactsService contacts.Service, emailsService emails.Service,
 	storeService store.Service, eventsService events.Service, siteService site.Service, contentService content.Service,
 	organizationsService organizations.Service, logger *slog.Logger, kms *kms.Kms,
@@ -135,15 +142,17 @@ func Start(ctx context.Context, conf config.Config, db db.DB, pingooClient *ping
 
 		webappDomain:            conf.HTTP.WebappDomain,
 		websitesRootDomain:      conf.HTTP.WebsitesRootDomain,
-		stripeWebhookSecret:     conf.Stripe.WebhookSecret,
 		stripePublicKey:         conf.Stripe.PublicKey,
 		httpConfig:              conf.HTTP,
 		pingooClient:            pingooClient,
+		geoipProvider:           geoipProvider,
+		paymentProvider:         paymentProvider,
 		pingooConfig:            conf.Pingoo,
 		logger:                  logger,
 		webappIndexHtmlTemplate: webappIndexHtmlTemplate,
 		webappIndexHtmlHash:     webappIndexHtmlHash[:],
 		emailsConfig:            conf.Emails,
+		snsVerifier:             emailfeedback.NewSnsVerifier(nil),
 		websitesBaseUrl:         conf.HTTP.WebsitesBaseUrl,
 		blockedCountries:        conf.BlockedCountries,
 
@@ -8,7 +8,7 @@ import (
 	"testing"
 
 	"github.com/klauspost/compress/zstd"
-	"markdown.ninja/pkg/services/site/templates"
+	"markdown.ninja/pkg/services/emails/templates"
 )
 
 func BenchmarkCompressHtml(b *testing.B) {
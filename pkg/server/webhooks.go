@@ -10,7 +10,6 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/skerkour/stdx-go/crypto"
 	"github.com/skerkour/stdx-go/log/slogx"
-	"github.com/stripe/stripe-go/v81/webhook"
 	"markdown.ninja/pingoo-go"
 	"markdown.ninja/pkg/errs"
 	"markdown.ninja/pkg/server/apiutil"
@@ -29,21 +28,23 @@ func (server *server) stripeWebhook(res http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	stripeEvent, err := webhook.ConstructEvent(payload, req.Header.Get("Stripe-Signature"), server.stripeWebhookSecret)
+	event, err := server.paymentProvider.ParseWebhookEvent(payload, req.Header)
 	if err != nil {
-		logger.Warn("server.stripeWebhook: error verifying webhook signature", slogx.Err(err))
-		apiutil.SendError(ctx, res, errs.InvalidArgument(fmt.Sprintf("Error verifying webhook signature: %v", err)))
+		logger.Warn("server.stripeWebhook: error parsing payment event", slogx.Err(err))
+		apiutil.SendError(ctx, res, errs.InvalidArgument(fmt.Sprintf("Error parsing payment event: %v", err)))
 		return
 	}
 
-	// if the event comes from a connected account (via Stripe Connect), then Account will not be empty
-	if stripeEvent.Account != "" {
-		err = server.storeService.HandleStripeEvent(ctx, stripeEvent)
+	// if the event comes from a connected account (via Stripe Connect), then Account will not be empty.
+	// The events of the platform account are handled by the organizations service, which forwards the
+	// ones of the stores to the store service.
+	if event.Account != "" {
+		err = server.storeService.HandlePaymentEvent(ctx, event)
 	} else {
-		err = server.organizationsService.HandleStripeEvent(ctx, stripeEvent)
+		err = server.organizationsService.HandlePaymentEvent(ctx, event)
 	}
 	if err != nil {
-		err = fmt.Errorf("server.stripeWebhook: processing event [%s - %s]: %w", stripeEvent.ID, stripeEvent.Type, err)
+		err = fmt.Errorf("server.stripeWebhook: processing event [%s - %s]: %w", event.ID, event.Type, err)
 		apiutil.SendError(ctx, res, err)
 		return
 	}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/skerkour/stdx-go/crypto"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"markdown.ninja/pkg/emailfeedback"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/server/apiutil"
+)
+
+// sesWebhookHandler receives the bounce and complaint notifications published by SES to an SNS topic.
+// The endpoint is authenticated both by the secret in the URL and by the signature of the SNS message.
+func (server *server) sesWebhookHandler(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := slogx.FromCtx(ctx)
+	webhookSecret := chi.URLParam(req, "secret")
+
+	if !crypto.ConstantTimeCompare([]byte(webhookSecret), []byte(server.emailsConfig.FeedbackWebhookSecret)) {
+		server.kernelService.SleepAuthFailure()
+		err := errs.PermissionDenied("webhook secret is not valid")
+		apiutil.SendError(ctx, res, err)
+		return
+	}
+
+	// the maximum size of an SNS message is 256 KB
+	const MaxBodyBytes = int64(300_000)
+	req.Body = http.MaxBytesReader(res, req.Body, MaxBodyBytes)
+	payload, err := io.ReadAll(req.Body)
+	if err != nil {
+		err = fmt.Errorf("server.sesWebhookHandler: reading body: %w", err)
+		apiutil.SendError(ctx, res, err)
+		return
+	}
+
+	snsMessage, err := server.snsVerifier.ParseAndVerifyMessage(ctx, payload)
+	if err != nil {
+		logger.Warn("server.sesWebhookHandler: error verifying SNS message", slogx.Err(err))
+		apiutil.SendError(ctx, res, errs.InvalidArgument(fmt.Sprintf("Error verifying SNS message: %v", err)))
+		return
+	}
+
+	switch snsMessage.Type {
+	case emailfeedback.SnsMessageTypeSubscriptionConfirmation:
+		err = server.snsVerifier.ConfirmSubscription(ctx, snsMessage)
+		if err != nil {
+			err = fmt.Errorf("server.sesWebhookHandler: %w", err)
+			apiutil.SendError(ctx, res, err)
+			return
+		}
+		logger.Info("server.sesWebhookHandler: SNS subscription confirmed", "topic_arn", snsMessage.TopicArn)
+	case emailfeedback.SnsMessageTypeNotification:
+		var feedback []emailfeedback.Feedback
+		feedback, err = emailfeedback.ParseSesNotification(snsMessage.Message)
+		if err != nil {
+			apiutil.SendError(ctx, res, errs.InvalidArgument(err.Error()))
+			return
+		}
+
+		err = server.recordEmailFeedback(ctx, feedback)
+		if err != nil {
+			err = fmt.Errorf("server.sesWebhookHandler: processing notification [%s]: %w", snsMessage.MessageId, err)
+			apiutil.SendError(ctx, res, err)
+			return
+		}
+	}
+
+	res.WriteHeader(http.StatusOK)
+}
+
+// emailFeedbackWebhookHandler receives a bounce or a complaint in the generic JSON format, signed
+// with the feedback webhook secret.
+func (server *server) emailFeedbackWebhookHandler(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	const MaxBodyBytes = int64(65536)
+	payload, err := server.readSignedEmailFeedbackPayload(res, req, MaxBodyBytes)
+	if err != nil {
+		apiutil.SendError(ctx, res, err)
+		return
+	}
+
+	feedback, err := emailfeedback.ParseGeneric(payload)
+	if err != nil {
+		apiutil.SendError(ctx, res, errs.InvalidArgument(err.Error()))
+		return
+	}
+
+	err = server.recordEmailFeedback(ctx, []emailfeedback.Feedback{feedback})
+	if err != nil {
+		err = fmt.Errorf("server.emailFeedbackWebhookHandler: %w", err)
+		apiutil.SendError(ctx, res, err)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+}
+
+// emailDsnWebhookHandler receives the raw delivery status notifications (RFC 3464) and feedback
+// reports (RFC 5965) received by the mailbox of the sender, signed with the feedback webhook secret.
+// It's intended to be used with the smtp emails provider, e.g. by piping the bounce mailbox to a script.
+func (server *server) emailDsnWebhookHandler(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	// the reports may contain the full original message
+	const MaxBodyBytes = int64(2_000_000)
+	payload, err := server.readSignedEmailFeedbackPayload(res, req, MaxBodyBytes)
+	if err != nil {
+		apiutil.SendError(ctx, res, err)
+		return
+	}
+
+	feedback, err := emailfeedback.ParseReport(payload)
+	if err != nil {
+		apiutil.SendError(ctx, res, errs.InvalidArgument(err.Error()))
+		return
+	}
+
+	err = server.recordEmailFeedback(ctx, feedback)
+	if err != nil {
+		err = fmt.Errorf("server.emailDsnWebhookHandler: %w", err)
+		apiutil.SendError(ctx, res, err)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+}
+
+func (server *server) readSignedEmailFeedbackPayload(res http.ResponseWriter, req *http.Request, maxBodyBytes int64) (payload []byte, err error) {
+	req.Body = http.MaxBytesReader(res, req.Body, maxBodyBytes)
+	payload, err = io.ReadAll(req.Body)
+	if err != nil {
+		err = fmt.Errorf("server.readSignedEmailFeedbackPayload: reading body: %w", err)
+		return
+	}
+
+	err = emailfeedback.VerifySignature([]byte(server.emailsConfig.FeedbackWebhookSecret),
+		req.Header.Get(emailfeedback.SignatureHeader), payload, time.Now())
+	if err != nil {
+		server.kernelService.SleepAuthFailure()
+		if errors.Is(err, emailfeedback.ErrSignatureIsNotValid) {
+			err = errs.PermissionDenied("Webhook signature is not valid.")
+		}
+		return
+	}
+
+	return payload, nil
+}
+
+func (server *server) recordEmailFeedback(ctx context.Context, feedback []emailfeedback.Feedback) (err error) {
+	for _, recipientFeedback := range feedback {
+		if recipientFeedback.Email == "" {
+			continue
+		}
+
+		err = server.contactsService.RecordEmailFeedback(ctx, recipientFeedback)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
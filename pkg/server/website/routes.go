@@ -9,12 +9,14 @@ import (
 	"github.com/skerkour/stdx-go/httpx/cors"
 	"markdown.ninja/pkg/server/apiutil"
 	"markdown.ninja/pkg/services/contacts"
+	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/site"
 	"markdown.ninja/pkg/services/store"
 	"markdown.ninja/pkg/services/websites"
 )
 
-func Routes(ctx context.Context, siteService site.Service, contactsService contacts.Service, storeService store.Service) (router chi.Router) {
+func Routes(ctx context.Context, siteService site.Service, contactsService contacts.Service, storeService store.Service,
+	emailsService emails.Service) (router chi.Router) {
 	router = chi.NewRouter()
 	// server := websitesServer{
 	// 	siteService,
@@ -33,13 +35,19 @@ func Routes(ctx context.Context, siteService site.Service, contactsService conta
 		MaxAge:           3600,
 	})
 	router.Use(cors.Handler)
+	router.Use(firewall(siteService))
 
 	router.Route(websites.MarkdownNinjaPathPrefix, func(mdninjaRouter chi.Router) {
 		// mdninjaRouter.Get("/videos/{asset_id}/iframe", siteService.ServeVideoIframe)
 		mdninjaRouter.Get("/preview/{page_id}", siteService.ServePreview)
+		mdninjaRouter.Get("/products/{product_id}/ebooks/{format}", siteService.ServeProductEbook)
+		mdninjaRouter.Get("/invoices/{invoice_id}/{format}", storeService.ServeInvoice)
+		mdninjaRouter.Get("/emails/click", emailsService.ServeNewsletterClick)
+		mdninjaRouter.Get("/emails/open", emailsService.ServeNewsletterOpen)
 
 		mdninjaRouter.Route("/api", func(apiRouter chi.Router) {
 			apiRouter.Use(middleware.NoCache)
+			apiRouter.Use(rateLimitApi(siteService))
 
 			apiRouter.Get("/", apiutil.IndexHandler)
 			// CMS
@@ -56,27 +64,81 @@ func Routes(ctx context.Context, siteService site.Service, contactsService conta
 			apiRouter.Post("/subscribe", apiutil.JsonEndpoint(siteService.Subscribe))
 			apiRouter.Post("/complete_subscription", apiutil.JsonEndpoint(siteService.CompleteSubscription))
 			apiRouter.Post("/unsubscribe", apiutil.JsonEndpointOk(siteService.Unsubscribe))
+			apiRouter.Get("/newsletter_preferences", apiutil.GetEndpoint(siteService.GetNewsletterPreferences))
+			apiRouter.Post("/update_newsletter_preferences", apiutil.JsonEndpoint(siteService.UpdateNewsletterPreferences))
 			apiRouter.Post("/update_my_account", apiutil.JsonEndpoint(siteService.UpdateMyAccount))
 			apiRouter.Post("/verify_email", apiutil.JsonEndpointOk(contactsService.VerifyEmail))
 			apiRouter.Post("/delete_my_account", apiutil.JsonEndpointOk(siteService.DeleteMyAccount))
 
 			// store
 			apiRouter.Post("/place_order", apiutil.JsonEndpoint(storeService.PlaceOrder))
+			apiRouter.Post("/preview_order", apiutil.JsonEndpoint(storeService.PreviewOrder))
 			apiRouter.Post("/complete_order", apiutil.JsonEndpointOk(storeService.CompleteOrder))
 			apiRouter.Post("/cancel_order", apiutil.JsonEndpointOk(storeService.CancelOrder))
+			apiRouter.Post("/resume_order", apiutil.JsonEndpoint(storeService.ResumeOrder))
 			apiRouter.Get("/my_orders", apiutil.GetEndpoint(siteService.ListMyOrders))
 			apiRouter.Get("/my_products", apiutil.GetEndpoint(siteService.ListMyProducts))
 			// TODO: productID as URL param instead of query param?
 			apiRouter.Get("/product", apiutil.GetEndpoint(siteService.GetProduct))
+			apiRouter.Post("/update_lesson_progress", apiutil.JsonEndpointOk(siteService.UpdateLessonProgress))
+
+			// license keys
+			apiRouter.Post("/license_keys/verify", apiutil.JsonEndpoint(storeService.VerifyLicenseKey))
+			apiRouter.Post("/license_keys/activate", apiutil.JsonEndpoint(storeService.ActivateLicenseKey))
+			apiRouter.Post("/license_keys/deactivate", apiutil.JsonEndpoint(storeService.DeactivateLicenseKey))
 
 			// events
 			apiRouter.Post("/events/page_view", apiutil.JsonEndpointOk(siteService.TrackEventPageView))
+
+			// firewall
+			apiRouter.Post("/firewall/challenge", apiutil.JsonEndpointOk(siteService.CompleteFirewallChallenge))
 		})
 
 		mdninjaRouter.NotFound(apiutil.NotFoundHandler)
 	})
 
+	// the email clients POST the one-click unsubscriptions (RFC 8058) to the unsubscribe page
+	router.HandleFunc("/unsubscribe", func(res http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			siteService.ServeOneClickUnsubscribe(res, req)
+			return
+		}
+		siteService.ServeContent(res, req)
+	})
+
 	router.NotFound(siteService.ServeContent)
 
 	return
 }
+
+// rateLimitApi enforces the per-website API rate limit (see websites.WebsiteRateLimits)
+func rateLimitApi(siteService site.Service) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(res http.ResponseWriter, req *http.Request) {
+			ctx := req.Context()
+
+			err := siteService.CheckApiRateLimit(ctx)
+			if err != nil {
+				apiutil.SendError(ctx, res, err)
+				return
+			}
+
+			next.ServeHTTP(res, req)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// firewall applies the firewall rules of the website (see websites.FirewallRule)
+func firewall(siteService site.Service) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(res http.ResponseWriter, req *http.Request) {
+			if siteService.ApplyFirewall(res, req) {
+				return
+			}
+
+			next.ServeHTTP(res, req)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
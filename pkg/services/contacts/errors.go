@@ -21,6 +21,8 @@ var (
 	ErrContactIsNotBlocked          = errs.InvalidArgument("Contact is not blocked")
 	ErrUnsubscribeLinkIsNotValid    = errs.InvalidArgument("The link is no longer valid. Please login into your account to unsubscibe.")
 	ErrContactNameIsNotValid        = errs.InvalidArgument("Contact name is not valid")
+	ErrNewsletterDeliveryIsNotValid = errs.InvalidArgument("Newsletter delivery is not valid")
+	ErrTooManyNewsletterTags        = errs.InvalidArgument(fmt.Sprintf("Too many tags (max: %d)", ContactNewsletterTagsMax))
 
 	// Sessions
 	ErrSessionNotFound = errs.NotFound("Session not found.")
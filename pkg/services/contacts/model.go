@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/emailfeedback"
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/store"
 )
@@ -25,8 +26,35 @@ const (
 	AuthCookieTimeout = 30 * 24 * time.Hour // 30 days
 
 	ContactNameMaxLength = 80
+
+	// The unsubscribe links are also used by the email clients for the one-click unsubscriptions
+	// (RFC 8058) and to open the preference center, so they must remain valid long after the emails are sent
+	UnsubscribeLinkTimeout = 90 * 24 * time.Hour // 90 days
+)
+
+// Thresholds after which a contact is automatically unsubscribed from the newsletter. Only the feedback
+// received since the contact (re)subscribed is taken into account.
+const (
+	EmailComplaintsUnsubscribeThreshold  = 1
+	EmailHardBouncesUnsubscribeThreshold = 1
+	EmailSoftBouncesUnsubscribeThreshold = 5
+	EmailSoftBouncesPeriod               = 30 * 24 * time.Hour
+)
+
+// NewsletterDelivery is how a contact wants to receive the posts sent as newsletter. Digests only
+// matter when the website sends digests: otherwise the posts are sent individually.
+type NewsletterDelivery string
+
+const (
+	NewsletterDeliveryPosts  NewsletterDelivery = "posts"
+	NewsletterDeliveryDigest NewsletterDelivery = "digest"
+	// the contact receives neither the posts nor the digests
+	NewsletterDeliveryNone NewsletterDelivery = "none"
 )
 
+// The maximum number of tags of posts that a contact can choose to receive
+const ContactNewsletterTagsMax = 50
+
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 // Entities
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -40,20 +68,55 @@ type Contact struct {
 	Name                         string     `db:"name" json:"name"`
 	Email                        string     `db:"email" json:"email"`
 	SubscribedToNewsletterAt     *time.Time `db:"subscribed_to_newsletter_at" json:"subscribed_to_newsletter_at"`
-	SubscribedToProductUpdatesAt *time.Time `db:"subscribed_to_product_updates_at" json:"-"`
-	Verified                     bool       `db:"verified" json:"-"`
+	SubscribedToProductUpdatesAt *time.Time `db:"subscribed_to_product_updates_at" json:"subscribed_to_product_updates_at"`
+	// ReceiveNewsletters is false if the contact doesn't want to receive the newsletters which are
+	// neither posts, digests nor product updates
+	ReceiveNewsletters bool `db:"receive_newsletters" json:"receive_newsletters"`
+	Verified           bool `db:"verified" json:"-"`
 	// 2-letter code of the country
-	Country              string     `db:"country" json:"country"`
-	FailedSignupAttempts int64      `db:"failed_signup_attempts" json:"-"`
-	SignupCodeHash       string     `db:"signup_code_hash" json:"-"`
-	BlockedAt            *time.Time `db:"blocked_at" json:"blocked_at"`
+	Country              string             `db:"country" json:"country"`
+	FailedSignupAttempts int64              `db:"failed_signup_attempts" json:"-"`
+	SignupCodeHash       string             `db:"signup_code_hash" json:"-"`
+	BlockedAt            *time.Time         `db:"blocked_at" json:"blocked_at"`
+	NewsletterDelivery   NewsletterDelivery `db:"newsletter_delivery" json:"newsletter_delivery"`
 
 	StripeCustomerID *string `db:"stripe_customer_id" json:"stripe_customer_id"`
 
 	WebsiteID guid.GUID `db:"website_id" json:"-"`
 
-	Products []store.Product `db:"-" json:"products"`
-	Orders   []store.Order   `db:"-" json:"orders"`
+	// NewsletterTagIDs are the tags of the posts that the contact wants to receive. Empty to receive all
+	// the posts. Only loaded by FindVerifiedAndSubscribedToNewsletterContacts
+	NewsletterTagIDs []guid.GUID `db:"-" json:"-"`
+
+	Products    []store.Product    `db:"-" json:"products"`
+	Orders      []store.Order      `db:"-" json:"orders"`
+	Memberships []store.Membership `db:"-" json:"memberships"`
+
+	EmailFeedback []EmailFeedback `db:"-" json:"email_feedback"`
+}
+
+// EmailFeedback is a bounce or a complaint reported by the email provider for an email sent to a
+// contact
+type EmailFeedback struct {
+	ID        guid.GUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+
+	ProviderID string                   `db:"provider_id" json:"-"`
+	Source     emailfeedback.Source     `db:"source" json:"source"`
+	Type       emailfeedback.Type       `db:"type" json:"type"`
+	BounceType emailfeedback.BounceType `db:"bounce_type" json:"bounce_type"`
+	Email      string                   `db:"email" json:"email"`
+	Diagnostic string                   `db:"diagnostic" json:"diagnostic"`
+
+	ContactID    guid.GUID  `db:"contact_id" json:"-"`
+	WebsiteID    guid.GUID  `db:"website_id" json:"-"`
+	NewsletterID *guid.GUID `db:"newsletter_id" json:"newsletter_id"`
+}
+
+// ContactNewsletterTag is a tag of the posts that the contact wants to receive
+type ContactNewsletterTag struct {
+	ContactID guid.GUID `db:"contact_id"`
+	TagID     guid.GUID `db:"tag_id"`
 }
 
 // UpdatedAt is the last time a session has been refreshed
@@ -148,10 +211,16 @@ type CreateContactInternalInput struct {
 }
 
 type UpdateContactInput struct {
-	ID                     guid.GUID `json:"id"`
-	Email                  *string   `json:"email"`
-	Name                   *string   `json:"name"`
-	SubscribedToNewsletter *bool     `json:"subscribed_to_newsletter"`
+	ID                         guid.GUID           `json:"id"`
+	Email                      *string             `json:"email"`
+	Name                       *string             `json:"name"`
+	SubscribedToNewsletter     *bool               `json:"subscribed_to_newsletter"`
+	NewsletterDelivery         *NewsletterDelivery `json:"newsletter_delivery"`
+	ReceiveNewsletters         *bool               `json:"receive_newsletters"`
+	SubscribedToProductUpdates *bool               `json:"subscribed_to_product_updates"`
+	// NewsletterTagIDs replaces the tags of the posts that the contact wants to receive. The caller is
+	// responsible for checking that the tags belong to the website of the contact
+	NewsletterTagIDs *[]guid.GUID `json:"-"`
 
 	BillingAddress *kernel.Address `json:"billing_address"`
 
@@ -31,15 +31,15 @@ func (repo *ContactsRepository) CreateContact(ctx context.Context, db db.Queryer
 	const query = `INSERT INTO contacts
 				(id, created_at, updated_at, email, subscribed_to_newsletter_at, subscribed_to_product_updates_at,
 					verified, name, country, failed_signup_attempts, signup_code_hash,
-					stripe_customer_id, blocked_at,
+					stripe_customer_id, blocked_at, newsletter_delivery, receive_newsletters,
 					website_id)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
 
 	_, err = db.Exec(ctx, query, contact.ID, contact.CreatedAt, contact.UpdatedAt, contact.Email,
 		contact.SubscribedToNewsletterAt, contact.SubscribedToProductUpdatesAt, contact.Verified,
 		contact.Name, contact.Country, contact.FailedSignupAttempts, contact.SignupCodeHash,
 		contact.StripeCustomerID,
-		contact.BlockedAt,
+		contact.BlockedAt, contact.NewsletterDelivery, contact.ReceiveNewsletters,
 		contact.WebsiteID)
 	if err != nil {
 		err = fmt.Errorf("contacts.CreateContact: %w", err)
@@ -69,13 +69,13 @@ func (repo *ContactsRepository) UpdateContact(ctx context.Context, db db.Queryer
 	const query = `UPDATE contacts
 		SET updated_at = $1, email = $2, subscribed_to_newsletter_at = $3, subscribed_to_product_updates_at = $4,
 			verified = $5, name = $6, country = $7, failed_signup_attempts = $8, signup_code_hash = $9,
-			stripe_customer_id = $10, blocked_at = $11
-		WHERE id = $12`
+			stripe_customer_id = $10, blocked_at = $11, newsletter_delivery = $12, receive_newsletters = $13
+		WHERE id = $14`
 
 	_, err = db.Exec(ctx, query, contact.UpdatedAt, contact.Email, contact.SubscribedToNewsletterAt,
 		contact.SubscribedToProductUpdatesAt, contact.Verified, contact.Name, contact.Country,
 		contact.FailedSignupAttempts, contact.SignupCodeHash,
-		contact.StripeCustomerID, contact.BlockedAt,
+		contact.StripeCustomerID, contact.BlockedAt, contact.NewsletterDelivery, contact.ReceiveNewsletters,
 		contact.ID)
 	if err != nil {
 		err = fmt.Errorf("contacts.UpdateContact: %w", err)
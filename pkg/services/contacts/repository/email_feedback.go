@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/emailfeedback"
+	"markdown.ninja/pkg/services/contacts"
+)
+
+// CreateEmailFeedback returns false if the feedback has already been recorded for the contact
+func (repo *ContactsRepository) CreateEmailFeedback(ctx context.Context, db db.Queryer, feedback contacts.EmailFeedback) (created bool, err error) {
+	const query = `INSERT INTO contacts_email_feedback
+				(id, created_at, provider_id, source, type, bounce_type, email, diagnostic,
+					contact_id, website_id, newsletter_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (contact_id, provider_id) WHERE provider_id != '' DO NOTHING`
+
+	res, err := db.Exec(ctx, query, feedback.ID, feedback.CreatedAt, feedback.ProviderID, feedback.Source,
+		feedback.Type, feedback.BounceType, feedback.Email, feedback.Diagnostic,
+		feedback.ContactID, feedback.WebsiteID, feedback.NewsletterID)
+	if err != nil {
+		err = fmt.Errorf("contacts.CreateEmailFeedback: %w", err)
+		return
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("contacts.CreateEmailFeedback: getting rows affected: %w", err)
+		return
+	}
+
+	return rowsAffected != 0, nil
+}
+
+func (repo *ContactsRepository) GetEmailFeedbackCountForContact(ctx context.Context, db db.Queryer, contactID guid.GUID,
+	feedbackType emailfeedback.Type, bounceType emailfeedback.BounceType, since time.Time) (count int64, err error) {
+	const query = `SELECT COUNT(*) FROM contacts_email_feedback
+		WHERE contact_id = $1 AND type = $2 AND bounce_type = $3 AND created_at >= $4`
+
+	err = db.Get(ctx, &count, query, contactID, feedbackType, bounceType, since)
+	if err != nil {
+		err = fmt.Errorf("contacts.GetEmailFeedbackCountForContact: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *ContactsRepository) FindEmailFeedbackForContact(ctx context.Context, db db.Queryer, contactID guid.GUID, limit int64) (ret []contacts.EmailFeedback, err error) {
+	ret = make([]contacts.EmailFeedback, 0)
+	const query = `SELECT * FROM contacts_email_feedback
+		WHERE contact_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	err = db.Select(ctx, &ret, query, contactID, limit)
+	if err != nil {
+		err = fmt.Errorf("contacts.FindEmailFeedbackForContact: %w", err)
+		return
+	}
+
+	return
+}
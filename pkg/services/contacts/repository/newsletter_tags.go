@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/contacts"
+)
+
+func (repo *ContactsRepository) FindContactNewsletterTagIDs(ctx context.Context, db db.Queryer, contactID guid.GUID) (tagIDs []guid.GUID, err error) {
+	tagIDs = make([]guid.GUID, 0)
+	const query = `SELECT tag_id FROM contacts_newsletter_tags WHERE contact_id = $1`
+
+	err = db.Select(ctx, &tagIDs, query, contactID)
+	if err != nil {
+		err = fmt.Errorf("contacts.FindContactNewsletterTagIDs: %w", err)
+		return
+	}
+
+	return
+}
+
+// FindNewsletterTagsForWebsite returns the tags of the posts chosen by all the contacts of the website
+func (repo *ContactsRepository) FindNewsletterTagsForWebsite(ctx context.Context, db db.Queryer, websiteID guid.GUID) (tags []contacts.ContactNewsletterTag, err error) {
+	tags = make([]contacts.ContactNewsletterTag, 0)
+	const query = `SELECT * FROM contacts_newsletter_tags
+		WHERE contact_id = ANY(SELECT id FROM contacts WHERE website_id = $1)`
+
+	err = db.Select(ctx, &tags, query, websiteID)
+	if err != nil {
+		err = fmt.Errorf("contacts.FindNewsletterTagsForWebsite: %w", err)
+		return
+	}
+
+	return
+}
+
+// ReplaceContactNewsletterTags removes the tags of the contact which are not in tagIDs and then adds
+// the missing ones
+func (repo *ContactsRepository) ReplaceContactNewsletterTags(ctx context.Context, db db.Queryer, contactID guid.GUID, tagIDs []guid.GUID) (err error) {
+	const deleteQuery = `DELETE FROM contacts_newsletter_tags
+		WHERE contact_id = $1 AND tag_id <> ALL($2::UUID[])`
+	const insertQuery = `INSERT INTO contacts_newsletter_tags (contact_id, tag_id)
+		SELECT $1, UNNEST($2::UUID[])
+		ON CONFLICT DO NOTHING`
+
+	_, err = db.Exec(ctx, deleteQuery, contactID, tagIDs)
+	if err != nil {
+		err = fmt.Errorf("contacts.ReplaceContactNewsletterTags: deleting tags: %w", err)
+		return
+	}
+
+	if len(tagIDs) == 0 {
+		return
+	}
+
+	_, err = db.Exec(ctx, insertQuery, contactID, tagIDs)
+	if err != nil {
+		err = fmt.Errorf("contacts.ReplaceContactNewsletterTags: inserting tags: %w", err)
+		return
+	}
+
+	return
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/emailfeedback"
 	"markdown.ninja/pkg/services/kernel"
 )
 
@@ -21,6 +22,8 @@ type Service interface {
 	GetContact(ctx context.Context, input GetContactInput) (contact Contact, err error)
 	ImportContacts(ctx context.Context, input ImportContactsInput) (contacts []Contact, err error)
 	FindVerifiedAndSubscribedToNewsletterContacts(ctx context.Context, db db.Queryer, websiteID guid.GUID) (contacts []Contact, err error)
+	// FindContactNewsletterTagIDs returns the tags of the posts that the contact wants to receive
+	FindContactNewsletterTagIDs(ctx context.Context, db db.Queryer, contactID guid.GUID) (tagIDs []guid.GUID, err error)
 	GetVerifiedAndSubscribedToNewsletterContactsCount(ctx context.Context, db db.Queryer, websiteID guid.GUID) (count int64, err error)
 	FindContactByEmail(ctx context.Context, db db.Queryer, websiteID guid.GUID, email string) (contact Contact, err error)
 	FindContact(ctx context.Context, db db.Queryer, contactID guid.GUID) (contact Contact, err error)
@@ -38,6 +41,9 @@ type Service interface {
 	UnblockContact(ctx context.Context, input UnblockContactInput) (contact Contact, err error)
 	ParseAndVerifyUnsubscribeToken(token string) (contactID guid.GUID, err error)
 	DeleteContactInternal(ctx context.Context, db db.Queryer, contactID, websiteID guid.GUID) (err error)
+	// RecordEmailFeedback records a bounce or a complaint reported by the email provider and unsubscribes
+	// the contacts from the newsletter when the thresholds are reached
+	RecordEmailFeedback(ctx context.Context, feedback emailfeedback.Feedback) (err error)
 
 	// Sessions
 	VerifySessionToken(ctx context.Context, token string) (contactAndSession ContactAndSession, err error)
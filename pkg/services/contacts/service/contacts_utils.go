@@ -33,7 +33,7 @@ func (service *ContactsService) GenerateUnsubscribeLink(websiteDomain string, co
 		Action:    jwtActionUnsubscribe,
 		ContactID: contactID,
 	}
-	expiresAt := time.Now().UTC().Add(time.Hour * 96)
+	expiresAt := time.Now().UTC().Add(contacts.UnsubscribeLinkTimeout)
 	jwt, err := service.jwtProvider.NewSignedToken(jwtClaims, &jwt.TokenOptions{
 		ExpirationTime: &expiresAt,
 	})
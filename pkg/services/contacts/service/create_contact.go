@@ -101,6 +101,8 @@ func (service *ContactsService) CreateContactInternal(ctx context.Context, db db
 		FailedSignupAttempts:         0,
 		SignupCodeHash:               input.SignupCodeHash,
 		StripeCustomerID:             nil,
+		NewsletterDelivery:           contacts.NewsletterDeliveryDigest,
+		ReceiveNewsletters:           true,
 		WebsiteID:                    input.WebsiteID,
 	}
 	err = service.repo.CreateContact(ctx, db, contact)
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+)
+
+func (service *ContactsService) FindContactNewsletterTagIDs(ctx context.Context, db db.Queryer, contactID guid.GUID) (tagIDs []guid.GUID, err error) {
+	return service.repo.FindContactNewsletterTagIDs(ctx, db, contactID)
+}
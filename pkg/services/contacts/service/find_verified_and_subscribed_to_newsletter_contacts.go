@@ -8,7 +8,30 @@ import (
 	"markdown.ninja/pkg/services/contacts"
 )
 
-func (service *ContactsService) FindVerifiedAndSubscribedToNewsletterContacts(ctx context.Context, db db.Queryer, websiteID guid.GUID) (contacts []contacts.Contact, err error) {
-	contacts, err = service.repo.FindVerifiedAndSubscribedToNewsletterContacts(ctx, db, websiteID)
+// FindVerifiedAndSubscribedToNewsletterContacts returns the contacts with their NewsletterTagIDs
+func (service *ContactsService) FindVerifiedAndSubscribedToNewsletterContacts(ctx context.Context, db db.Queryer, websiteID guid.GUID) (ret []contacts.Contact, err error) {
+	ret, err = service.repo.FindVerifiedAndSubscribedToNewsletterContacts(ctx, db, websiteID)
+	if err != nil {
+		return
+	}
+
+	newsletterTags, err := service.repo.FindNewsletterTagsForWebsite(ctx, db, websiteID)
+	if err != nil {
+		return
+	}
+
+	if len(newsletterTags) == 0 {
+		return
+	}
+
+	contactsTagIDs := make(map[guid.GUID][]guid.GUID, len(newsletterTags))
+	for _, newsletterTag := range newsletterTags {
+		contactsTagIDs[newsletterTag.ContactID] = append(contactsTagIDs[newsletterTag.ContactID], newsletterTag.TagID)
+	}
+
+	for i := range ret {
+		ret[i].NewsletterTagIDs = contactsTagIDs[ret[i].ID]
+	}
+
 	return
 }
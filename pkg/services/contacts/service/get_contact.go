@@ -32,5 +32,15 @@ func (service *ContactsService) GetContact(ctx context.Context, input contacts.G
 		return
 	}
 
+	contact.Memberships, err = service.storeService.FindMembershipsForContact(ctx, service.db, contact.ID)
+	if err != nil {
+		return
+	}
+
+	contact.EmailFeedback, err = service.repo.FindEmailFeedbackForContact(ctx, service.db, contact.ID, 100)
+	if err != nil {
+		return
+	}
+
 	return
 }
@@ -114,12 +114,14 @@ func (service *ContactsService) ImportContacts(ctx context.Context, input contac
 			FailedSignupAttempts:         0,
 			SignupCodeHash:               "",
 			StripeCustomerID:             nil,
+			NewsletterDelivery:           contacts.NewsletterDeliveryDigest,
+			ReceiveNewsletters:           true,
 			WebsiteID:                    input.WebsiteID,
 		}
 		importedContacts = append(importedContacts, importedContact)
 	}
 
-	validateEmailsRes, err := service.pingoo.LookupEmails(ctx, pingoo.LookupEmailsInput{
+	validateEmailsRes, err := service.kernel.LookupEmails(ctx, pingoo.LookupEmailsInput{
 		Emails: emails,
 	})
 	if err != nil {
@@ -1,9 +1,7 @@
 package service
 
 import (
-	"bytes"
 	"context"
-	"fmt"
 	"html/template"
 	"log/slog"
 	"net/mail"
@@ -12,19 +10,23 @@ import (
 	"github.com/skerkour/stdx-go/log/slogx"
 	"markdown.ninja/pkg/errs"
 	"markdown.ninja/pkg/services/contacts"
-	"markdown.ninja/pkg/services/contacts/templates"
+	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/events"
 )
 
 func (service *ContactsService) JobSendVerifyEmailEmail(ctx context.Context, input contacts.JobSendVerifyEmailEmail) (err error) {
 	logger := slogx.FromCtx(ctx)
-	var htmlContent bytes.Buffer
 
 	emailConfig, err := service.emailsService.FindWebsiteConfiguration(ctx, service.db, input.WebsiteID)
 	if err != nil {
 		return
 	}
 
+	website, err := service.websitesService.FindWebsiteByID(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
 	var from mail.Address
 	if emailConfig.DomainVerified {
 		from = mail.Address{
@@ -32,10 +34,6 @@ func (service *ContactsService) JobSendVerifyEmailEmail(ctx context.Context, inp
 			Address: emailConfig.FromAddress,
 		}
 	} else {
-		website, err := service.websitesService.FindWebsiteByID(ctx, service.db, input.WebsiteID)
-		if err != nil {
-			return err
-		}
 		from = service.emailsService.GetDefaultFromAddressForWebsite(website)
 	}
 
@@ -45,14 +43,13 @@ func (service *ContactsService) JobSendVerifyEmailEmail(ctx context.Context, inp
 	}
 	subject := "Confirm your email address"
 
-	textContent := fmt.Sprintf("Use the following link to confirm your new email address: %s", input.VerifyEmailLink)
-
-	emailData := templates.VerifyEmailEmailData{
-		Link: template.URL(input.VerifyEmailLink),
+	emailData := emails.EmailTemplateData{
+		Subject: subject,
+		Link:    template.URL(input.VerifyEmailLink),
 	}
-	err = service.verifyEmailEmailTemplate.Execute(&htmlContent, emailData)
+	renderedEmail, err := service.emailsService.RenderWebsiteEmail(ctx, service.db, website, emails.EmailTemplateTypeVerifyEmail, emailData)
 	if err != nil {
-		errMessage := "contacts.JobSendVerifyEmailEmail: Executing email template"
+		errMessage := "contacts.JobSendVerifyEmailEmail: Rendering email"
 		logger.Error(errMessage, slogx.Err(err))
 		err = errs.Internal(errMessage, err)
 		return
@@ -62,8 +59,8 @@ func (service *ContactsService) JobSendVerifyEmailEmail(ctx context.Context, inp
 		From:    from,
 		To:      []mail.Address{to},
 		Subject: subject,
-		HTML:    htmlContent.Bytes(),
-		Text:    []byte(textContent),
+		HTML:    []byte(renderedEmail.Html),
+		Text:    []byte(renderedEmail.Text),
 	}
 	err = service.mailer.SendTransactionnal(ctx, message)
 	if err != nil {
@@ -5,9 +5,8 @@ import (
 	"log/slog"
 
 	"github.com/skerkour/stdx-go/log/slogx"
-	"github.com/stripe/stripe-go/v81"
-	stripecustomer "github.com/stripe/stripe-go/v81/customer"
 	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/services/contacts"
 )
 
@@ -22,19 +21,14 @@ func (service *ContactsService) JobUpdateStripeContact(ctx context.Context, data
 	}
 
 	if contact.StripeCustomerID != nil {
-		params := &stripe.CustomerParams{
-			Name:  stripe.String(contact.Name),
-			Email: stripe.String(contact.Email),
-			Address: &stripe.AddressParams{
-				Country: stripe.String(contact.Country),
-			},
+		input := payments.UpdateCustomerInput{
+			Name:    contact.Name,
+			Email:   contact.Email,
+			Country: contact.Country,
 		}
-		_, err = stripecustomer.Update(
-			*contact.StripeCustomerID,
-			params,
-		)
+		err = service.paymentProvider.UpdateCustomer(ctx, *contact.StripeCustomerID, input)
 		if err != nil {
-			errMessage := "contacts.JobUpdateStripeContact: Error updating stripe customer"
+			errMessage := "contacts.JobUpdateStripeContact: Error updating customer"
 			logger.Error(errMessage, slogx.Err(err))
 		}
 	}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/emailfeedback"
+	"markdown.ninja/pkg/services/contacts"
+	"markdown.ninja/pkg/services/events"
+)
+
+// RecordEmailFeedback records a bounce or a complaint reported by the email provider on the contacts
+// with the given email address, and unsubscribes them from the newsletter when the thresholds are
+// reached.
+// If the feedback can't be attributed to a website, it is recorded for the contacts of ALL the websites
+// with this email address.
+func (service *ContactsService) RecordEmailFeedback(ctx context.Context, feedback emailfeedback.Feedback) (err error) {
+	now := time.Now().UTC()
+	recordedFeedback := make([]contacts.EmailFeedback, 0, 1)
+	unsubscribedContacts := make([]contacts.Contact, 0, 1)
+
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		contactsWithEmail, txErr := service.repo.FindContactsByEmail(ctx, tx, feedback.Email, true)
+		if txErr != nil {
+			return txErr
+		}
+
+		for _, contact := range contactsWithEmail {
+			if feedback.WebsiteID != nil && !feedback.WebsiteID.Equal(contact.WebsiteID) {
+				continue
+			}
+
+			// the newsletter is trusted only if the feedback has been attributed to the website of the contact
+			var newsletterID *guid.GUID
+			if feedback.WebsiteID != nil {
+				newsletterID = feedback.NewsletterID
+			}
+
+			contactFeedback := contacts.EmailFeedback{
+				ID:           guid.NewTimeBased(),
+				CreatedAt:    now,
+				ProviderID:   feedback.ID,
+				Source:       feedback.Source,
+				Type:         feedback.Type,
+				BounceType:   feedback.BounceType,
+				Email:        feedback.Email,
+				Diagnostic:   feedback.Diagnostic,
+				ContactID:    contact.ID,
+				WebsiteID:    contact.WebsiteID,
+				NewsletterID: newsletterID,
+			}
+			created, txErr := service.repo.CreateEmailFeedback(ctx, tx, contactFeedback)
+			if txErr != nil {
+				return txErr
+			}
+			if !created {
+				continue
+			}
+			recordedFeedback = append(recordedFeedback, contactFeedback)
+
+			if contact.SubscribedToNewsletterAt == nil {
+				continue
+			}
+
+			thresholdReached, txErr := service.emailFeedbackThresholdReached(ctx, tx, contact, feedback, now)
+			if txErr != nil {
+				return txErr
+			}
+			if !thresholdReached {
+				continue
+			}
+
+			contact.SubscribedToNewsletterAt = nil
+			contact.UpdatedAt = now
+			txErr = service.repo.UpdateContact(ctx, tx, contact)
+			if txErr != nil {
+				return fmt.Errorf("error updating contact: %w", txErr)
+			}
+			unsubscribedContacts = append(unsubscribedContacts, contact)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	for _, contactFeedback := range recordedFeedback {
+		if contactFeedback.Type == emailfeedback.TypeComplaint {
+			service.eventsService.TrackEmailComplained(ctx, events.TrackEmailComplainedInput{
+				WebsiteID:    contactFeedback.WebsiteID,
+				NewsletterID: contactFeedback.NewsletterID,
+			})
+		} else {
+			service.eventsService.TrackEmailBounced(ctx, events.TrackEmailBouncedInput{
+				BounceType:   string(contactFeedback.BounceType),
+				WebsiteID:    contactFeedback.WebsiteID,
+				NewsletterID: contactFeedback.NewsletterID,
+			})
+		}
+	}
+
+	for _, contact := range unsubscribedContacts {
+		service.eventsService.TrackUnsubscribedFromNewsletter(ctx, events.TrackUnsubscribedFromNewsletterInput{
+			WebsiteID: contact.WebsiteID,
+		})
+	}
+
+	return nil
+}
+
+// emailFeedbackThresholdReached returns true if the contact has received enough bounces or complaints
+// of the same kind as feedback, since they subscribed to the newsletter, to be unsubscribed.
+func (service *ContactsService) emailFeedbackThresholdReached(ctx context.Context, db db.Queryer, contact contacts.Contact,
+	feedback emailfeedback.Feedback, now time.Time) (reached bool, err error) {
+	since := *contact.SubscribedToNewsletterAt
+	var threshold int64
+
+	switch {
+	case feedback.Type == emailfeedback.TypeComplaint:
+		threshold = contacts.EmailComplaintsUnsubscribeThreshold
+	case feedback.BounceType == emailfeedback.BounceTypePermanent:
+		threshold = contacts.EmailHardBouncesUnsubscribeThreshold
+	default:
+		threshold = contacts.EmailSoftBouncesUnsubscribeThreshold
+		if periodStart := now.Add(-contacts.EmailSoftBouncesPeriod); periodStart.After(since) {
+			since = periodStart
+		}
+	}
+
+	count, err := service.repo.GetEmailFeedbackCountForContact(ctx, db, contact.ID, feedback.Type, feedback.BounceType, since)
+	if err != nil {
+		return false, err
+	}
+
+	return count >= threshold, nil
+}
@@ -1,17 +1,13 @@
 package service
 
 import (
-	"fmt"
-	"html/template"
-
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/queue"
 	"markdown.ninja/cmd/mdninja-server/config"
-	"markdown.ninja/pingoo-go"
 	"markdown.ninja/pkg/jwt"
 	"markdown.ninja/pkg/mailer"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/services/contacts/repository"
-	"markdown.ninja/pkg/services/contacts/templates"
 	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/events"
 	"markdown.ninja/pkg/services/kernel"
@@ -26,7 +22,8 @@ type ContactsService struct {
 	// env    config.Env
 	mailer      mailer.Mailer
 	jwtProvider *jwt.Provider
-	pingoo      *pingoo.Client
+	// used to keep the customers of the payment provider in sync with the contacts
+	paymentProvider payments.Provider
 
 	kernel          kernel.PrivateService
 	websitesService websites.Service
@@ -34,22 +31,15 @@ type ContactsService struct {
 	eventsService   events.Service
 	emailsService   emails.Service
 
-	httpConfig               config.Http
-	verifyEmailEmailTemplate *template.Template
+	httpConfig config.Http
 }
 
 func NewContactsService(conf config.Config, db db.DB, mailer mailer.Mailer, queue queue.Queue,
 	jwtProvider *jwt.Provider, kernel kernel.PrivateService,
 	websitesService websites.Service, eventsService events.Service,
-	emailsService emails.Service, pingoo *pingoo.Client) (service *ContactsService, err error) {
+	emailsService emails.Service, paymentProvider payments.Provider) (service *ContactsService, err error) {
 	repo := repository.NewContactsRepository()
 
-	verifyEmailEmailTemplate, err := template.New("contacts.verifyEmailEmailTemplate").Parse(templates.VerifyEmailEmailTemplate)
-	if err != nil {
-		err = fmt.Errorf("contacts.NewService: Parsing verifyEmailEmailTemplate: %w", err)
-		return
-	}
-
 	service = &ContactsService{
 		repo:        repo,
 		db:          db,
@@ -62,10 +52,9 @@ func NewContactsService(conf config.Config, db db.DB, mailer mailer.Mailer, queu
 		storeService:    nil,
 		eventsService:   eventsService,
 		emailsService:   emailsService,
-		pingoo:          pingoo,
+		paymentProvider: paymentProvider,
 
-		httpConfig:               conf.HTTP,
-		verifyEmailEmailTemplate: verifyEmailEmailTemplate,
+		httpConfig: conf.HTTP,
 	}
 	return
 }
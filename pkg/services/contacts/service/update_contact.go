@@ -57,6 +57,32 @@ func (service *ContactsService) UpdateContactInternal(ctx context.Context, db db
 		}
 	}
 
+	if input.NewsletterDelivery != nil {
+		err = service.validateNewsletterDelivery(*input.NewsletterDelivery)
+		if err != nil {
+			return err
+		}
+		contact.NewsletterDelivery = *input.NewsletterDelivery
+	}
+
+	if input.ReceiveNewsletters != nil {
+		contact.ReceiveNewsletters = *input.ReceiveNewsletters
+	}
+
+	if input.SubscribedToProductUpdates != nil {
+		if *input.SubscribedToProductUpdates == false && contact.SubscribedToProductUpdatesAt != nil {
+			contact.SubscribedToProductUpdatesAt = nil
+		} else if *input.SubscribedToProductUpdates && contact.SubscribedToProductUpdatesAt == nil {
+			contact.SubscribedToProductUpdatesAt = &now
+		}
+	}
+
+	if input.NewsletterTagIDs != nil {
+		if len(*input.NewsletterTagIDs) > contacts.ContactNewsletterTagsMax {
+			return contacts.ErrTooManyNewsletterTags
+		}
+	}
+
 	if input.Verified != nil {
 		contact.Verified = *input.Verified
 	}
@@ -114,6 +140,14 @@ func (service *ContactsService) UpdateContactInternal(ctx context.Context, db db
 		return err
 	}
 
+	if input.NewsletterTagIDs != nil {
+		err = service.repo.ReplaceContactNewsletterTags(ctx, db, contact.ID, *input.NewsletterTagIDs)
+		if err != nil {
+			return err
+		}
+		contact.NewsletterTagIDs = *input.NewsletterTagIDs
+	}
+
 	if updateStripeContact && contact.StripeCustomerID != nil {
 		job := queue.NewJobInput{
 			Data: contacts.JobUpdateStripeContact{
@@ -27,3 +27,12 @@ func (service *ContactsService) ValidateContactName(name string) error {
 
 	return nil
 }
+
+func (service *ContactsService) validateNewsletterDelivery(delivery contacts.NewsletterDelivery) error {
+	switch delivery {
+	case contacts.NewsletterDeliveryPosts, contacts.NewsletterDeliveryDigest, contacts.NewsletterDeliveryNone:
+		return nil
+	default:
+		return contacts.ErrNewsletterDeliveryIsNotValid
+	}
+}
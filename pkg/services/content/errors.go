@@ -37,6 +37,7 @@ var (
 
 	// pages
 	ErrPageTypeIsNotValid                          = errs.InvalidArgument("Page type is not valid.")
+	ErrPageVisibilityIsNotValid                    = errs.InvalidArgument("Page visibility is not valid (must be public or members).")
 	ErrContentTypeIsNotValid                       = errs.InvalidArgument("Content type is not valid.")
 	ErrPageWithPathAlreadyExists                   = errs.InvalidArgument("Page with the same URL already exists.")
 	ErrPageCantBeUpdated                           = errs.InvalidArgument("Page can't be updated.")
@@ -56,6 +56,18 @@ const (
 	PageStatusScheduled PageStatus = "scheduled"
 )
 
+// PageVisibility controls who can read a page. The teaser of members-only pages is shown to
+// everybody else.
+type PageVisibility string
+
+const (
+	PageVisibilityPublic  PageVisibility = "public"
+	PageVisibilityMembers PageVisibility = "members"
+)
+
+// PageTeaserSeparator can be used in the markdown of members-only pages to delimit their teaser
+const PageTeaserSeparator = "<!--more-->"
+
 func (status PageStatus) IsDraft() bool {
 	return status == PageStatusDraft
 }
@@ -229,6 +241,7 @@ type Page struct {
 	MetadataHash     kernel.BytesHex `db:"metadata_hash" json:"metadata_hash"`
 	SendAsNewsletter bool            `db:"send_as_newsletter" json:"send_as_newsletter"`
 	NewsletterSentAt *time.Time      `db:"newsletter_sent_at" json:"newsletter_sent_at"`
+	Visibility       PageVisibility  `db:"visibility" json:"visibility"`
 
 	// TitleDraft  string            `db:"title_draft" json:"title_draft"`
 
@@ -306,6 +319,8 @@ type CreatePageInput struct {
 	Draft            bool      `json:"draft"`
 	BodyMarkdown     string    `json:"body_markdown"`
 	SendAsNewsletter bool      `json:"send_as_newsletter"`
+	// Default: public
+	Visibility *PageVisibility `json:"visibility"`
 }
 
 type UpdatePageInput struct {
@@ -320,6 +335,8 @@ type UpdatePageInput struct {
 	Tags             []string   `json:"tags"`
 	BodyMarkdown     *string    `json:"body_markdown"`
 	SendAsNewsletter bool       `json:"send_as_newsletter"`
+	// Default: public
+	Visibility *PageVisibility `json:"visibility"`
 }
 
 type DeletePageInput struct {
@@ -346,6 +363,7 @@ type PageMetadata struct {
 	Language         string          `db:"language" json:"lang"`
 	SendAsNewsletter bool            `db:"send_as_newsletter" json:"send_as_newsletter"`
 	NewsletterSentAt *time.Time      `db:"newsletter_sent_at" json:"newsletter_sent_at"`
+	Visibility       PageVisibility  `db:"visibility" json:"visibility"`
 }
 
 func (page *PageMetadata) ModifiedAt() time.Time {
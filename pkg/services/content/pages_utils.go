@@ -2,12 +2,13 @@ package content
 
 import (
 	"encoding/binary"
+	"strings"
 	"time"
 
 	"github.com/zeebo/blake3"
 )
 
-func HashPageMetadata(pageType PageType, path string, date time.Time, sendAsNewsletter bool, language string, title string, description string, tags []string) [32]byte {
+func HashPageMetadata(pageType PageType, path string, date time.Time, sendAsNewsletter bool, language string, title string, description string, tags []string, visibility PageVisibility) [32]byte {
 	var hash [32]byte
 
 	hasher := blake3.New()
@@ -21,8 +22,24 @@ func HashPageMetadata(pageType PageType, path string, date time.Time, sendAsNews
 	for _, tag := range tags {
 		hasher.Write([]byte(tag))
 	}
+	// public pages keep the same hash as before visibility was introduced
+	if visibility != PageVisibilityPublic && visibility != "" {
+		hasher.Write([]byte(visibility))
+	}
 
 	hasher.Sum(hash[:0])
 
 	return hash
 }
+
+// PageTeaser returns the markdown of the teaser of a page: everything before PageTeaserSeparator if
+// present, otherwise the first paragraph.
+func PageTeaser(bodyMarkdown string) string {
+	if teaser, _, found := strings.Cut(bodyMarkdown, PageTeaserSeparator); found {
+		return strings.TrimSpace(teaser)
+	}
+
+	bodyMarkdown = strings.TrimSpace(strings.ReplaceAll(bodyMarkdown, "\r\n", "\n"))
+	teaser, _, _ := strings.Cut(bodyMarkdown, "\n\n")
+	return teaser
+}
@@ -0,0 +1,25 @@
+package content
+
+import "testing"
+
+func TestPageTeaser(t *testing.T) {
+	tests := []struct {
+		bodyMarkdown string
+		expected     string
+	}{
+		{"", ""},
+		{"Hello World", "Hello World"},
+		{"First paragraph.\n\nSecond paragraph.", "First paragraph."},
+		{"\n\nFirst paragraph\non two lines.\n\nSecond paragraph.", "First paragraph\non two lines."},
+		{"First paragraph.\r\n\r\nSecond paragraph.", "First paragraph."},
+		{"First paragraph.\n\nSecond paragraph.\n<!--more-->\nMembers only.", "First paragraph.\n\nSecond paragraph."},
+		{"<!--more-->Members only.", ""},
+	}
+
+	for _, test := range tests {
+		teaser := PageTeaser(test.bodyMarkdown)
+		if teaser != test.expected {
+			t.Errorf("PageTeaser(%q): got %q, expected %q", test.bodyMarkdown, teaser, test.expected)
+		}
+	}
+}
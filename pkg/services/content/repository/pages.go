@@ -15,13 +15,13 @@ func (repo *ContentRepository) CreatePage(ctx context.Context, db db.Queryer, pa
 	const query = `INSERT INTO pages
 			(id, created_at, updated_at, date, type, title, path,
 			description, language, size, body_hash, metadata_hash, status, send_as_newsletter,
-			newsletter_sent_at, body_markdown, website_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
+			newsletter_sent_at, body_markdown, visibility, website_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`
 
 	_, err = db.Exec(ctx, query, page.ID, page.CreatedAt, page.UpdatedAt, page.Date,
 		page.Type, page.Title, page.Path,
 		page.Description, page.Language, page.Size, page.BodyHash, page.MetadataHash, page.Status,
-		page.SendAsNewsletter, page.NewsletterSentAt, page.BodyMarkdown,
+		page.SendAsNewsletter, page.NewsletterSentAt, page.BodyMarkdown, page.Visibility,
 		page.WebsiteID)
 	if err != nil {
 		err = fmt.Errorf("content.CreatePage: %w", err)
@@ -35,13 +35,14 @@ func (repo *ContentRepository) UpdatePage(ctx context.Context, db db.Queryer, pa
 	const query = `UPDATE pages
 		SET updated_at = $1, date = $2, type = $3, title = $4, path = $5,
 			description = $6, language = $7, size = $8, body_hash = $9, status = $10,
-			send_as_newsletter = $11, newsletter_sent_at = $12, body_markdown = $13, metadata_hash = $14
-		WHERE id = $15`
+			send_as_newsletter = $11, newsletter_sent_at = $12, body_markdown = $13, metadata_hash = $14,
+			visibility = $15
+		WHERE id = $16`
 
 	_, err = db.Exec(ctx, query, page.UpdatedAt, page.Date, page.Type, page.Title, page.Path,
 		page.Description, page.Language,
 		page.Size, page.BodyHash, page.Status, page.SendAsNewsletter,
-		page.NewsletterSentAt, page.BodyMarkdown, page.MetadataHash,
+		page.NewsletterSentAt, page.BodyMarkdown, page.MetadataHash, page.Visibility,
 		page.ID)
 	if err != nil {
 		err = fmt.Errorf("content.UpdatePage: %w", err)
@@ -100,7 +101,7 @@ func (repo *ContentRepository) FindPagesMetadataByTypeForWebsite(ctx context.Con
 	pages = make([]content.PageMetadata, 0)
 	const query = `SELECT pages.id, pages.created_at, pages.updated_at, pages.date, pages.type, pages.title,
 				pages.description, pages.path, pages.size, pages.body_hash, pages.metadata_hash,
-				pages.status, pages.language, pages.send_as_newsletter, pages.newsletter_sent_at
+				pages.status, pages.language, pages.send_as_newsletter, pages.newsletter_sent_at, pages.visibility
 		FROM pages
 		WHERE website_id = $1 AND type = $2
 		ORDER BY date DESC
@@ -164,7 +165,7 @@ func (repo *ContentRepository) FindPublishedPagesMetadataForTag(ctx context.Cont
 	pages = make([]content.PageMetadata, 0, 10)
 	const query = `SELECT pages.id, pages.created_at, pages.updated_at, pages.date, pages.type, pages.title,
 				pages.description, pages.path, pages.size, pages.body_hash, pages.metadata_hash,
-				pages.status, pages.language, pages.send_as_newsletter, pages.newsletter_sent_at
+				pages.status, pages.language, pages.send_as_newsletter, pages.newsletter_sent_at, pages.visibility
 				FROM pages
 			INNER JOIN pages_tags ON pages_tags.page_id = pages.id
 			WHERE pages_tags.tag_id = $1
@@ -185,7 +186,7 @@ func (repo *ContentRepository) FindPublishedPagesMetadataForWebsite(ctx context.
 	websiteID guid.GUID, pageTypes []content.PageType, limit int64) (pages []content.PageMetadata, err error) {
 	pages = make([]content.PageMetadata, 0, 25)
 	const query = `SELECT id, created_at, updated_at, date, type, title, description, path, size,
-			body_hash, metadata_hash, status, language, send_as_newsletter, newsletter_sent_at
+			body_hash, metadata_hash, status, language, send_as_newsletter, newsletter_sent_at, visibility
 		FROM pages
 		WHERE website_id = $1
 			AND type = ANY($2)
@@ -202,6 +203,26 @@ func (repo *ContentRepository) FindPublishedPagesMetadataForWebsite(ctx context.
 	return
 }
 
+func (repo *ContentRepository) FindPostsSentAsNewsletter(ctx context.Context, db db.Queryer, websiteID guid.GUID,
+	from, to time.Time, limit int64) (posts []content.Page, err error) {
+	posts = make([]content.Page, 0)
+	const query = `SELECT * FROM pages
+		WHERE website_id = $1
+			AND type = $2
+			AND status = $3
+			AND newsletter_sent_at > $4 AND newsletter_sent_at <= $5
+		ORDER BY newsletter_sent_at DESC
+		LIMIT $6`
+
+	err = db.Select(ctx, &posts, query, websiteID, content.PageTypePost, content.PageStatusPublished, from, to, limit)
+	if err != nil {
+		err = fmt.Errorf("content.FindPostsSentAsNewsletter: %w", err)
+		return
+	}
+
+	return
+}
+
 func (repo *ContentRepository) FindScheduledPagesToPublish(ctx context.Context, db db.Queryer, forUpdate bool) (pages []content.Page, err error) {
 	pages = make([]content.Page, 0, 5)
 	now := time.Now().UTC()
@@ -3,6 +3,7 @@ package content
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/guid"
@@ -49,11 +50,13 @@ type Service interface {
 	FindPageByID(ctx context.Context, db db.Queryer, pageID guid.GUID) (page Page, err error)
 	FindLastPublishedPageOrPost(ctx context.Context, db db.Queryer, websiteID guid.GUID) (page Page, err error)
 	FindLastPublishedPost(ctx context.Context, db db.Queryer, websiteID guid.GUID) (page Page, err error)
+	FindPostsSentAsNewsletter(ctx context.Context, db db.Queryer, websiteID guid.GUID, from, to time.Time, limit int64) (posts []Page, err error)
 	ListPages(ctx context.Context, input ListPagesInput) (pages kernel.PaginatedResult[PageMetadata], err error)
 	ListPosts(ctx context.Context, input ListPagesInput) (posts kernel.PaginatedResult[PageMetadata], err error)
 	ValidatePageBodyMarkdown(body string) (err error)
 	GetPagesCountForWebsite(ctx context.Context, db db.Queryer, websiteID guid.GUID) (count int64, err error)
 	ValidatePageTitle(titel string) error
+	ValidatePagePath(path string) error
 
 	// Tags
 	CreateTag(ctx context.Context, input CreateTagInput) (tag Tag, err error)
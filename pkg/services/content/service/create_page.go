@@ -117,6 +117,15 @@ func (service *ContentService) CreatePage(ctx context.Context, input content.Cre
 	if err != nil {
 		return
 	}
+
+	visibility := content.PageVisibilityPublic
+	if input.Visibility != nil {
+		visibility = *input.Visibility
+		err = service.validatePageVisibility(visibility)
+		if err != nil {
+			return
+		}
+	}
 	if sendAsNewsletter {
 		emailsConfig, err := service.emailsService.FindWebsiteConfiguration(ctx, service.db, input.WebsiteID)
 		if err != nil {
@@ -129,7 +138,7 @@ func (service *ContentService) CreatePage(ctx context.Context, input content.Cre
 
 	// check if path is not already in use
 	path := strings.TrimSpace(input.Path)
-	err = service.validatePagePath(path)
+	err = service.ValidatePagePath(path)
 	if err != nil {
 		return
 	}
@@ -163,7 +172,7 @@ func (service *ContentService) CreatePage(ctx context.Context, input content.Cre
 		return
 	}
 
-	metadataHash := content.HashPageMetadata(pageType, path, date, sendAsNewsletter, language, title, description, input.Tags)
+	metadataHash := content.HashPageMetadata(pageType, path, date, sendAsNewsletter, language, title, description, input.Tags, visibility)
 
 	page = content.Page{
 		ID:               guid.NewTimeBased(),
@@ -182,6 +191,7 @@ func (service *ContentService) CreatePage(ctx context.Context, input content.Cre
 		BodyMarkdown:     bodyMarkdown,
 		SendAsNewsletter: sendAsNewsletter,
 		NewsletterSentAt: newsletterSentAt,
+		Visibility:       visibility,
 		WebsiteID:        website.ID,
 	}
 
@@ -49,7 +49,18 @@ func (service *ContentService) DeleteAsset(ctx context.Context, input content.De
 		txErr = service.deleteAssetInternal(ctx, tx, assetToDelete)
 		return txErr
 	})
-	return err
+	if err != nil {
+		return
+	}
+
+	if assetToDelete.ProductID != nil {
+		err = service.storeService.ScheduleProductEbooksGeneration(ctx, service.db, *assetToDelete.ProductID)
+		if err != nil {
+			return
+		}
+	}
+
+	return nil
 }
 
 func (service *ContentService) DeleteAssetInternal(ctx context.Context, tx db.Tx, assetID guid.GUID) (err error) {
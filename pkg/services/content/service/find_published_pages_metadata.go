@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 	"unicode/utf8"
 
 	"github.com/skerkour/stdx-go/db"
@@ -14,6 +15,13 @@ func (service *ContentService) FindPublishedPagesMetadata(ctx context.Context, d
 	return pages, err
 }
 
+// FindPostsSentAsNewsletter returns the published posts which have been sent as newsletter between from
+// (excluded) and to (included), the most recent first
+func (service *ContentService) FindPostsSentAsNewsletter(ctx context.Context, db db.Queryer, websiteID guid.GUID, from, to time.Time, limit int64) (posts []content.Page, err error) {
+	posts, err = service.repo.FindPostsSentAsNewsletter(ctx, db, websiteID, from, to, limit)
+	return posts, err
+}
+
 func (service *ContentService) FindPublishedPagesMetadataForTag(ctx context.Context, db db.Queryer, websiteID guid.GUID, pageTypes []content.PageType, tagName string) (pages []content.PageMetadata, err error) {
 	if !utf8.ValidString(tagName) {
 		err = content.ErrTagNotFound
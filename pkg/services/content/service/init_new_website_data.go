@@ -33,9 +33,10 @@ func (service *ContentService) InitNewWebsiteData(ctx context.Context, tx db.Que
 		MetadataHash: []byte{},
 		Status:       content.PageStatusPublished,
 		BodyMarkdown: bodyMarkdown,
+		Visibility:   content.PageVisibilityPublic,
 		WebsiteID:    website.ID,
 	}
-	metadataHash := content.HashPageMetadata(homePage.Type, homePage.Path, homePage.Date, homePage.SendAsNewsletter, homePage.Language, homePage.Title, homePage.Description, []string{})
+	metadataHash := content.HashPageMetadata(homePage.Type, homePage.Path, homePage.Date, homePage.SendAsNewsletter, homePage.Language, homePage.Title, homePage.Description, []string{}, homePage.Visibility)
 	homePage.MetadataHash = metadataHash[:]
 
 	err = service.repo.CreatePage(ctx, tx, homePage)
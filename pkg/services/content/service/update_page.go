@@ -82,7 +82,7 @@ func (service *ContentService) UpdatePage(ctx context.Context, input content.Upd
 	}
 
 	page.Path = strings.TrimSpace(input.Path)
-	err = service.validatePagePath(page.Path)
+	err = service.ValidatePagePath(page.Path)
 	if err != nil {
 		return
 	}
@@ -147,6 +147,15 @@ func (service *ContentService) UpdatePage(ctx context.Context, input content.Upd
 		return
 	}
 
+	page.Visibility = content.PageVisibilityPublic
+	if input.Visibility != nil {
+		page.Visibility = *input.Visibility
+		err = service.validatePageVisibility(page.Visibility)
+		if err != nil {
+			return
+		}
+	}
+
 	page.UpdatedAt = now
 	if input.UpdatedAt != nil {
 		page.UpdatedAt = input.UpdatedAt.UTC().Truncate(time.Second)
@@ -200,7 +209,7 @@ func (service *ContentService) UpdatePage(ctx context.Context, input content.Upd
 		return
 	}
 
-	metadataHash := content.HashPageMetadata(page.Type, page.Path, page.Date, page.SendAsNewsletter, page.Language, page.Title, page.Description, input.Tags)
+	metadataHash := content.HashPageMetadata(page.Type, page.Path, page.Date, page.SendAsNewsletter, page.Language, page.Title, page.Description, input.Tags, page.Visibility)
 	page.MetadataHash = metadataHash[:]
 
 	var newsletter emails.Newsletter
@@ -224,5 +224,13 @@ func (service *ContentService) UploadAsset(ctx context.Context, input content.Up
 		return
 	}
 
+	if asset.ProductID != nil {
+		// product images are embedded in the ebooks
+		err = service.storeService.ScheduleProductEbooksGeneration(ctx, service.db, *asset.ProductID)
+		if err != nil {
+			return
+		}
+	}
+
 	return
 }
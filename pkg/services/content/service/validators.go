@@ -107,7 +107,7 @@ func (service *ContentService) ValidatePageTitle(title string) error {
 }
 
 // TODO
-func (service *ContentService) validatePagePath(path string) error {
+func (service *ContentService) ValidatePagePath(path string) error {
 	if len(path) == 0 || path[0] != '/' ||
 		// disallow trailing slashes
 		(len(path) > 1 && strings.HasSuffix(path, "/")) {
@@ -286,6 +286,14 @@ func (service *ContentService) ValidatePageBodyMarkdown(body string) error {
 	return nil
 }
 
+func (service *ContentService) validatePageVisibility(visibility content.PageVisibility) error {
+	if visibility != content.PageVisibilityPublic && visibility != content.PageVisibilityMembers {
+		return content.ErrPageVisibilityIsNotValid
+	}
+
+	return nil
+}
+
 func (service *ContentService) validatePageSendAsNewsletter(sendAsNewsletter bool, pageType content.PageType) error {
 	if sendAsNewsletter && pageType != content.PageTypePost {
 		return content.ErrOnlyPostsCanBeSentAsNewsletter
@@ -14,6 +14,9 @@ var (
 	}
 	ErrDomainAlreadyInUse            = errs.AlreadyExists("This email domain is already in use by another website. Please change and try again or contact support.")
 	ErrNoCustomEmailDomainConfigured = errs.InvalidArgument("No custom email domain set up")
+	ErrSendRateIsNotValid            = func(max int64) error {
+		return errs.InvalidArgument(fmt.Sprintf("Send rate must be between 1 and %d emails per second", max))
+	}
 
 	// Newsletter
 	ErrNewsletterScheduledForIsInThePast = errs.InvalidArgument("You can't schedule a newsletter in the past")
@@ -24,4 +27,49 @@ var (
 	ErrNewsletterBodyIsTooLarge          = errs.InvalidArgument(fmt.Sprintf("Newsletter is too large (max: %d characters)", NewsletterContentMarkdownMaxSize))
 	ErrNewsletterSubjectIsNotValid       = errs.InvalidArgument("Newsletter subject is not valid")
 	ErrNewsletterBodyIsNotValid          = errs.InvalidArgument("Newsletter body is not valid")
+	ErrNewsletterIsNotBeingSent          = errs.InvalidArgument("Newsletter is not being sent")
+	ErrNewsletterSendingIsPaused         = errs.InvalidArgument("Sending of the newsletter is already paused")
+	ErrNewsletterSendingIsNotPaused      = errs.InvalidArgument("Sending of the newsletter is not paused")
+
+	// A/B tests
+	ErrABTestTooManyVariants         = errs.InvalidArgument(fmt.Sprintf("An A/B test can't have more than %d subjects", ABTestMaxVariants))
+	ErrABTestNotEnoughSubjects       = errs.InvalidArgument("An A/B test needs at least 2 different subjects")
+	ErrABTestSubjectsAreNotUnique    = errs.InvalidArgument("The subjects of an A/B test must be different")
+	ErrABTestSamplePercentIsNotValid = errs.InvalidArgument(fmt.Sprintf("The sample of an A/B test must be between %d%% and %d%% of the recipients", ABTestMinSamplePercent, ABTestMaxSamplePercent))
+	ErrABTestDurationIsNotValid      = errs.InvalidArgument("The duration of an A/B test must be between 1 hour and 7 days")
+	ErrABTestWinningMetricIsNotValid = errs.InvalidArgument("The winning metric of an A/B test is not valid")
+	ErrABTestInProgress              = errs.InvalidArgument("The newsletter can't be edited while its A/B test is in progress")
+	ErrABTestRequiresTracking        = errs.InvalidArgument("The winning metric of the A/B test must be tracked: please enable opens or clicks tracking")
+
+	// Digests
+	ErrDigestFrequencyIsNotValid = errs.InvalidArgument("Digest frequency is not valid")
+	ErrDigestTemplateIsTooLarge  = errs.InvalidArgument(fmt.Sprintf("Digest template is too large (max: %d characters)", DigestTemplateMaxSize))
+	ErrDigestTemplateIsNotValid  = func(err error) error {
+		return errs.InvalidArgument(fmt.Sprintf("Digest template is not valid: %s", err))
+	}
+
+	// Archive
+	ErrArchivePathIsNotValid = errs.InvalidArgument("Archive path is not valid")
+	ErrArchivePathIsUsed     = errs.InvalidArgument("A page already exists at the path of the archive")
+
+	// Sequences
+	ErrSequenceNotFound            = errs.NotFound("Sequence not found.")
+	ErrSequenceNameIsNotValid      = errs.InvalidArgument(fmt.Sprintf("Name must be between %d and %d characters", SequenceNameMinSize, SequenceNameMaxSize))
+	ErrSequenceTriggerIsNotValid   = errs.InvalidArgument("Trigger is not valid")
+	ErrSequenceTooManySteps        = errs.InvalidArgument(fmt.Sprintf("A sequence can't have more than %d steps", SequenceMaxSteps))
+	ErrSequenceStepDelayIsNotValid = errs.InvalidArgument("Delay of the step is not valid")
+	ErrSequenceStepNotFound        = errs.NotFound("Step not found.")
+
+	// Templates
+	ErrEmailTemplateNotFound       = errs.NotFound("Email template not found.")
+	ErrEmailTemplateTypeIsNotValid = errs.InvalidArgument("Email template type is not valid")
+	ErrEmailTemplateIsTooLarge     = errs.InvalidArgument(fmt.Sprintf("Email template is too large (max: %d characters)", EmailTemplateMaxSize))
+	ErrEmailTemplateIsNotValid     = func(err error) error {
+		return errs.InvalidArgument(fmt.Sprintf("Email template is not valid: %s", err))
+	}
+	ErrEmailTemplateMissingVariable = func(variable string) error {
+		return errs.InvalidArgument(fmt.Sprintf("Email template must use {{ .%s }}", variable))
+	}
+	ErrEmailFooterTextIsTooLong   = errs.InvalidArgument(fmt.Sprintf("Footer text is too long (max: %d characters)", EmailFooterTextMaxSize))
+	ErrEmailLegalAddressIsTooLong = errs.InvalidArgument(fmt.Sprintf("Legal address is too long (max: %d characters)", EmailLegalAddressMaxSize))
 )
@@ -19,6 +19,8 @@ type JobSendNewsletter struct {
 	Test         bool      `json:"test"`
 	TestEmails   []string  `json:"test_emails"`
 	SentAt       time.Time `json:"sent_at"`
+	// ABTestStage is only set for A/B tested newsletters
+	ABTestStage ABTestStage `json:"ab_test_stage,omitempty"`
 }
 
 func (JobSendNewsletter) JobType() string {
@@ -34,6 +36,27 @@ func (JobSendPostAsNewsletter) JobType() string {
 	return "emails.send_post_as_newsletter"
 }
 
+// JobSendNewsletterDigest sends the digest of the posts sent as newsletter between PeriodStart
+// and PeriodEnd
+type JobSendNewsletterDigest struct {
+	WebsiteID   guid.GUID `json:"website_id"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+}
+
+func (JobSendNewsletterDigest) JobType() string {
+	return "emails.send_newsletter_digest"
+}
+
+type JobSendSequenceEmail struct {
+	SequenceContactID guid.GUID `json:"sequence_contact_id"`
+	Step              int64     `json:"step"`
+}
+
+func (JobSendSequenceEmail) JobType() string {
+	return "emails.send_sequence_email"
+}
+
 type JobSendEmail struct {
 	Type EmailType `json:"type"`
 	// FromAddress and FromName are ignored when Type == transactional
@@ -51,6 +74,9 @@ type JobSendEmail struct {
 	ContactID      *guid.GUID `json:"contact_id"`
 	NewsletterID   *guid.GUID `json:"newsletter_id"`
 	OrganizationID *guid.GUID `json:"organization_id"`
+	// NewsletterRecipientID is set for the emails of newsletters, to record the recipients rejected by the
+	// email provider
+	NewsletterRecipientID *guid.GUID `json:"newsletter_recipient_id,omitempty"`
 }
 
 func (JobSendEmail) JobType() string {
@@ -1,11 +1,16 @@
 package emails
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"html/template"
 	"time"
 
 	"github.com/skerkour/stdx-go/guid"
 	"markdown.ninja/pkg/mailer"
 	"markdown.ninja/pkg/services/kernel"
+	"markdown.ninja/pkg/services/websites"
 )
 
 const (
@@ -15,8 +20,72 @@ const (
 	NewsletterSubjectMaxSize         = 200
 	NewsletterSubjectMinSize         = 1
 
-	// the number of emails / s to send for a single newsletter
-	NewsletterRateLimit = 5
+	// the number of emails / s sent for the newsletters of a website, unless configured otherwise.
+	// The total rate of the websites of an organization is limited by Organization.EmailsSendRate
+	DefaultNewsletterSendRate = 5
+	NewsletterSendRateMax     = 100
+	// the emails of the newsletters are scheduled by batches, every NewsletterSendInterval
+	NewsletterSendInterval = time.Minute
+
+	// the links of the newsletters are rewritten to go through NewsletterClickPath when click tracking is
+	// enabled, and NewsletterOpenPath serves the tracking pixel
+	NewsletterClickPath = websites.MarkdownNinjaPathPrefix + "/emails/click"
+	NewsletterOpenPath  = websites.MarkdownNinjaPathPrefix + "/emails/open"
+)
+
+const (
+	// the maximum number of subjects of an A/B test, including the subject of the newsletter
+	ABTestMaxVariants = 5
+	// the percentage of the recipients who receive one of the variants
+	ABTestMinSamplePercent = 10
+	ABTestMaxSamplePercent = 50
+	// the delay after which the winning subject is sent to the remaining recipients
+	ABTestMinDuration = time.Hour
+	ABTestMaxDuration = 7 * 24 * time.Hour
+)
+
+// NewsletterWarmupSchedule is the maximum number of emails of newsletters sent per day by a website
+// during the first days after the verification of its sending domain, so that the reputation of the
+// domain is built progressively. There is no daily limit after the last day of the schedule.
+var NewsletterWarmupSchedule = []int64{200, 500, 1_000, 2_000, 5_000, 10_000, 20_000, 50_000, 100_000}
+
+const (
+	SequenceNameMinSize = 1
+	SequenceNameMaxSize = 100
+	SequenceMaxSteps    = 20
+	// the maximum delay between 2 steps of a sequence
+	SequenceStepMaxDelay = 365 * 24 * time.Hour
+)
+
+const (
+	DigestTemplateMaxSize = 20_000
+	// the excerpts of the posts are truncated to DigestExcerptMaxLength characters
+	DigestExcerptMaxLength = 300
+	// the maximum number of posts in a digest. Older posts are ignored
+	DigestMaxPosts = 50
+
+	// the maximum number of newsletters listed in the archive. Older newsletters are not listed
+	ArchiveMaxNewsletters = 1_000
+
+	// DefaultDigestTemplate is used when the website has not customized the template of its digests.
+	// The template is a Go text/template which produces the markdown of the newsletter.
+	DefaultDigestTemplate = `{{- range .Posts }}
+## [{{ .Title }}]({{ .Url }})
+{{ if .Description }}
+{{ .Description }}
+{{ end }}{{ if .Excerpt }}
+{{ .Excerpt }}
+{{ end }}
+[Read more]({{ .Url }})
+
+{{ end -}}
+`
+)
+
+const (
+	EmailTemplateMaxSize     = 100_000
+	EmailFooterTextMaxSize   = 1_000
+	EmailLegalAddressMaxSize = 500
 )
 
 type EmailType string
@@ -26,6 +95,89 @@ const (
 	EmailTypeBroadcast     EmailType = "broadcast"
 )
 
+// EmailTemplateType is the kind of emails sent on behalf of a website which use a template
+type EmailTemplateType string
+
+const (
+	EmailTemplateTypeNewsletter        EmailTemplateType = "newsletter"
+	EmailTemplateTypeLogin             EmailTemplateType = "login"
+	EmailTemplateTypeSubscribe         EmailTemplateType = "subscribe"
+	EmailTemplateTypeVerifyEmail       EmailTemplateType = "verify_email"
+	EmailTemplateTypeOrderConfirmation EmailTemplateType = "order_confirmation"
+)
+
+var EmailTemplateTypes = []EmailTemplateType{
+	EmailTemplateTypeNewsletter,
+	EmailTemplateTypeLogin,
+	EmailTemplateTypeSubscribe,
+	EmailTemplateTypeVerifyEmail,
+	EmailTemplateTypeOrderConfirmation,
+}
+
+// DigestFrequency is how often the digest of the posts sent as newsletter is sent to the contacts.
+// Digests are sent at 09:00 UTC, on Mondays for weekly digests and on the first day of the month for
+// monthly digests.
+type DigestFrequency string
+
+const (
+	DigestFrequencyDisabled DigestFrequency = "disabled"
+	DigestFrequencyWeekly   DigestFrequency = "weekly"
+	DigestFrequencyMonthly  DigestFrequency = "monthly"
+)
+
+type ABTestMetric string
+
+const (
+	ABTestMetricClicks ABTestMetric = "clicks"
+	ABTestMetricOpens  ABTestMetric = "opens"
+)
+
+// ABTestStage is the part of the recipients of an A/B tested newsletter to send the newsletter to
+type ABTestStage string
+
+const (
+	// the newsletter is not A/B tested
+	ABTestStageNone ABTestStage = ""
+	// the variants are sent to a random sample of the recipients
+	ABTestStageSample ABTestStage = "sample"
+	// the winning subject is sent to the recipients who were not in the sample
+	ABTestStageWinner ABTestStage = "winner"
+)
+
+type NewsletterRecipientStatus string
+
+const (
+	NewsletterRecipientStatusPending NewsletterRecipientStatus = "pending"
+	// the email has been handed to the email provider
+	NewsletterRecipientStatusSent NewsletterRecipientStatus = "sent"
+	// the email could not be rendered, or the email provider rejected the recipient
+	NewsletterRecipientStatusFailed NewsletterRecipientStatus = "failed"
+)
+
+type SequenceTrigger string
+
+const (
+	SequenceTriggerSubscribedToNewsletter SequenceTrigger = "subscribed_to_newsletter"
+	// TriggerProductID may be set to only trigger the sequence for a specific product
+	SequenceTriggerProductPurchased SequenceTrigger = "product_purchased"
+)
+
+type SequenceContactStatus string
+
+const (
+	SequenceContactStatusActive    SequenceContactStatus = "active"
+	SequenceContactStatusCompleted SequenceContactStatus = "completed"
+	SequenceContactStatusExited    SequenceContactStatus = "exited"
+)
+
+type SequenceExitReason string
+
+const (
+	SequenceExitReasonNone         SequenceExitReason = ""
+	SequenceExitReasonUnsubscribed SequenceExitReason = "unsubscribed"
+	SequenceExitReasonPurchase     SequenceExitReason = "purchase"
+)
+
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 // Entities
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -40,8 +192,104 @@ type WebsiteConfiguration struct {
 	FromDomain     string            `db:"from_domain" json:"-"`
 	DomainVerified bool              `db:"domain_verified" json:"domain_verified"`
 	DnsRecords     mailer.DnsRecords `db:"dns_records" json:"dns_records"`
+	// DomainVerifiedAt is the start of the warmup of the domain. See NewsletterWarmupSchedule
+	DomainVerifiedAt *time.Time `db:"domain_verified_at" json:"domain_verified_at"`
+	// SendRate is the number of emails / s sent for the newsletters of the website
+	SendRate int64 `db:"send_rate" json:"send_rate"`
+
+	// Opens and clicks of the newsletters are only tracked if enabled, and are not tied to the contacts
+	// unless TrackContacts is true
+	TrackClicks   bool `db:"track_clicks" json:"track_clicks"`
+	TrackOpens    bool `db:"track_opens" json:"track_opens"`
+	TrackContacts bool `db:"track_contacts" json:"track_contacts"`
+
+	// When digests are enabled, the posts sent as newsletter are only sent individually to the contacts
+	// who prefer it, and the other contacts receive them grouped in a periodic digest.
+	// An empty DigestSubject or DigestTemplate means that the default is used.
+	DigestFrequency       DigestFrequency `db:"digest_frequency" json:"digest_frequency"`
+	DigestSubject         string          `db:"digest_subject" json:"digest_subject"`
+	DigestTemplate        string          `db:"digest_template" json:"digest_template"`
+	DigestIncludeExcerpts bool            `db:"digest_include_excerpts" json:"digest_include_excerpts"`
+	// the posts sent after DigestPeriodStart are included in the next digest
+	DigestPeriodStart *time.Time `db:"digest_period_start" json:"-"`
+	DigestNextSendAt  *time.Time `db:"digest_next_send_at" json:"digest_next_send_at"`
+
+	// ArchivePath is the path of the public archive of the newsletters on the website. The archive is
+	// disabled when empty
+	ArchivePath string `db:"archive_path" json:"archive_path"`
+
+	// FooterText and LegalAddress are available to the templates of the emails
+	FooterText   string `db:"footer_text" json:"footer_text"`
+	LegalAddress string `db:"legal_address" json:"legal_address"`
+
+	WebsiteID guid.GUID `db:"website_id" json:"-"`
+}
+
+// EmailTemplate overrides the default template of a type of emails for a website.
+// Templates are Go html/template templates executed with EmailTemplateData.
+type EmailTemplate struct {
+	ID        guid.GUID `db:"id" json:"-"`
+	CreatedAt time.Time `db:"created_at" json:"-"`
+	UpdatedAt time.Time `db:"updated_at" json:"-"`
+
+	Type EmailTemplateType `db:"type" json:"type"`
+	Body string            `db:"body" json:"body"`
 
 	WebsiteID guid.GUID `db:"website_id" json:"-"`
+
+	// Customized is false when the website uses the default template, in which case Body is the default
+	// template
+	Customized        bool     `db:"-" json:"customized"`
+	DefaultBody       string   `db:"-" json:"default_body"`
+	RequiredVariables []string `db:"-" json:"required_variables"`
+}
+
+// EmailTemplateData is the data available to the templates of the emails. Only the fields relevant to
+// the type of the template are set.
+type EmailTemplateData struct {
+	Subject      string
+	Website      EmailTemplateWebsite
+	Colors       websites.ThemeColors
+	FooterText   string
+	LegalAddress string
+
+	// newsletter
+	Content         template.HTML
+	UnsubscribeLink template.URL
+	// empty if open tracking is disabled
+	OpenTrackingPixel template.URL
+
+	// login and subscribe
+	Code string
+	// login, subscribe and verify_email
+	Link template.URL
+
+	// order_confirmation
+	OrderID    string
+	AccountURL template.URL
+	// empty for gifts, the license keys are sent to the recipient
+	LicenseKeys []EmailTemplateLicenseKey
+	// set when the order is a gift
+	GiftRecipientEmail string
+}
+
+type EmailTemplateWebsite struct {
+	Name string
+	Url  string
+	// empty if the website has no logo
+	Logo string
+}
+
+type EmailTemplateLicenseKey struct {
+	ProductName string
+	Key         string
+}
+
+type RenderedEmail struct {
+	Subject string `json:"subject"`
+	Html    string `json:"html"`
+	// Text is the plain text alternative, generated from the HTML
+	Text string `json:"text"`
 }
 
 type Newsletter struct {
@@ -57,9 +305,96 @@ type Newsletter struct {
 	SentAt         *time.Time      `db:"sent_at" json:"sent_at"`
 	LastTestSentAt *time.Time      `db:"last_test_sent_at" json:"last_test_sent_at"`
 	BodyMarkdown   string          `db:"body_markdown" json:"body_markdown"`
+	// if true, the newsletter is only sent to the active members of the website
+	MembersOnly bool `db:"members_only" json:"members_only"`
+	// if true, the newsletter is only sent to the contacts subscribed to the product updates instead of
+	// the contacts who receive the newsletters
+	ProductUpdates bool `db:"product_updates" json:"product_updates"`
+	// HMAC-SHA256 key used to sign the tracking links. Generated when the newsletter is sent
+	TrackingKey []byte `db:"tracking_key" json:"-"`
+	// Digest is true if the newsletter is a digest of the posts. Digests are only sent to the contacts
+	// who receive the posts as digest
+	Digest bool `db:"digest" json:"digest"`
+	// ABTest is nil if the newsletter is not A/B tested
+	ABTest *ABTest `db:"ab_test" json:"ab_test"`
+	// no more emails are scheduled while the sending of the newsletter is paused
+	SendingPausedAt *time.Time `db:"sending_paused_at" json:"sending_paused_at"`
 
 	PostID    *guid.GUID `db:"post_id" json:"post_id"`
 	WebsiteID guid.GUID  `db:"website_id" json:"website_id"`
+
+	ABTestResults []ABTestVariantResult `db:"-" json:"ab_test_results,omitempty"`
+	Progress      *NewsletterProgress   `db:"-" json:"progress,omitempty"`
+}
+
+// NewsletterRecipient is a recipient of a newsletter. The emails are sent progressively, according to
+// the send rate of the website and of its organization.
+type NewsletterRecipient struct {
+	ID        guid.GUID `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+
+	Status NewsletterRecipientStatus `db:"status"`
+	Email  string                    `db:"email"`
+	Name   string                    `db:"name"`
+	// the variant of the subject for the recipients of the sample of an A/B test
+	Variant *int64 `db:"variant"`
+	// SentAt is the time the email is scheduled to be sent at
+	SentAt *time.Time `db:"sent_at"`
+
+	ContactID    guid.GUID `db:"contact_id"`
+	NewsletterID guid.GUID `db:"newsletter_id"`
+	WebsiteID    guid.GUID `db:"website_id"`
+}
+
+type NewsletterProgress struct {
+	Sent      int64 `db:"sent" json:"sent"`
+	Remaining int64 `db:"remaining" json:"remaining"`
+	Failed    int64 `db:"failed" json:"failed"`
+}
+
+// ABTest sends several subjects to a random sample of the recipients. After Duration, the subject with
+// the best rate for WinningMetric is sent to the remaining recipients.
+// The first variant is the subject of the newsletter when the test is created.
+type ABTest struct {
+	Variants      []ABTestVariant `json:"variants"`
+	SamplePercent int64           `json:"sample_percent"`
+	// Duration (in seconds) between the sending of the sample and the sending of the winner
+	Duration      int64        `json:"duration"`
+	WinningMetric ABTestMetric `json:"winning_metric"`
+	// Winner is the index of the winning variant. nil until the test is completed
+	Winner *int64 `json:"winner"`
+}
+
+type ABTestVariant struct {
+	Subject string `json:"subject"`
+	// the number of recipients of the sample who received the variant
+	Recipients int64 `json:"recipients"`
+}
+
+func (abTest *ABTest) Scan(val any) error {
+	switch v := val.(type) {
+	case []byte:
+		return json.Unmarshal(v, abTest)
+	case string:
+		return json.Unmarshal([]byte(v), abTest)
+	default:
+		return fmt.Errorf("ABTest.Scan: Unsupported type: %T", v)
+	}
+}
+
+func (abTest ABTest) Value() (driver.Value, error) {
+	return json.Marshal(abTest)
+}
+
+type ABTestVariantResult struct {
+	Subject      string  `json:"subject"`
+	Recipients   int64   `json:"recipients"`
+	UniqueOpens  int64   `json:"unique_opens"`
+	UniqueClicks int64   `json:"unique_clicks"`
+	OpenRate     float64 `json:"open_rate"`
+	ClickRate    float64 `json:"click_rate"`
+	Winner       bool    `json:"winner"`
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -71,15 +406,110 @@ type GetWebsiteConfigurationInput struct {
 }
 
 type UpdateWebsiteConfigurationInput struct {
-	WebsiteID   guid.GUID `json:"website_id"`
-	FromName    string    `json:"from_name"`
-	FromAddress string    `json:"from_address"`
+	WebsiteID     guid.GUID `json:"website_id"`
+	FromName      string    `json:"from_name"`
+	FromAddress   string    `json:"from_address"`
+	TrackClicks   *bool     `json:"track_clicks"`
+	TrackOpens    *bool     `json:"track_opens"`
+	TrackContacts *bool     `json:"track_contacts"`
+
+	DigestFrequency       *DigestFrequency `json:"digest_frequency"`
+	DigestSubject         *string          `json:"digest_subject"`
+	DigestTemplate        *string          `json:"digest_template"`
+	DigestIncludeExcerpts *bool            `json:"digest_include_excerpts"`
+
+	ArchivePath *string `json:"archive_path"`
+
+	FooterText   *string `json:"footer_text"`
+	LegalAddress *string `json:"legal_address"`
+
+	SendRate *int64 `json:"send_rate"`
 }
 
 type VerifyDnsConfigurationInput struct {
 	WebsiteID guid.GUID `json:"website_id"`
 }
 
+// Sequence is an automated series of emails sent to the contacts after a trigger, such as a welcome
+// series when a contact subscribes to the newsletter.
+// Contacts who unsubscribe from the newsletter always exit the sequence.
+type Sequence struct {
+	ID        guid.GUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	Name             string          `db:"name" json:"name"`
+	Trigger          SequenceTrigger `db:"trigger" json:"trigger"`
+	TriggerProductID *guid.GUID      `db:"trigger_product_id" json:"trigger_product_id"`
+	// ExitOnPurchase makes the contacts exit the sequence when they purchase ExitProductID, or any
+	// product if ExitProductID is nil
+	ExitOnPurchase bool          `db:"exit_on_purchase" json:"exit_on_purchase"`
+	ExitProductID  *guid.GUID    `db:"exit_product_id" json:"exit_product_id"`
+	PausedAt       *time.Time    `db:"paused_at" json:"paused_at"`
+	Steps          SequenceSteps `db:"steps" json:"steps"`
+
+	WebsiteID guid.GUID `db:"website_id" json:"-"`
+
+	Stats *SequenceStats `db:"-" json:"stats,omitempty"`
+}
+
+type SequenceStep struct {
+	ID guid.GUID `json:"id"`
+	// Delay (in seconds) after the previous step, or after the contact entered the sequence for the first step
+	Delay        int64  `json:"delay"`
+	Subject      string `json:"subject"`
+	BodyMarkdown string `json:"body_markdown"`
+}
+
+type SequenceSteps []SequenceStep
+
+func (steps *SequenceSteps) Scan(val any) error {
+	switch v := val.(type) {
+	case []byte:
+		return json.Unmarshal(v, steps)
+	case string:
+		return json.Unmarshal([]byte(v), steps)
+	default:
+		return fmt.Errorf("SequenceSteps.Scan: Unsupported type: %T", v)
+	}
+}
+
+func (steps SequenceSteps) Value() (driver.Value, error) {
+	if steps == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(steps)
+}
+
+// SequenceContact is the state of a contact in a sequence. A contact can only enter a sequence once.
+type SequenceContact struct {
+	ID        guid.GUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	Status SequenceContactStatus `db:"status" json:"status"`
+	// NextStep is the index of the next step to send
+	NextStep   int64              `db:"next_step" json:"next_step"`
+	NextStepAt *time.Time         `db:"next_step_at" json:"next_step_at"`
+	ExitReason SequenceExitReason `db:"exit_reason" json:"exit_reason"`
+
+	SequenceID guid.GUID `db:"sequence_id" json:"sequence_id"`
+	ContactID  guid.GUID `db:"contact_id" json:"contact_id"`
+	WebsiteID  guid.GUID `db:"website_id" json:"-"`
+}
+
+type SequenceStats struct {
+	ActiveContacts    int64               `db:"active_contacts" json:"active_contacts"`
+	CompletedContacts int64               `db:"completed_contacts" json:"completed_contacts"`
+	ExitedContacts    int64               `db:"exited_contacts" json:"exited_contacts"`
+	Steps             []SequenceStepStats `db:"-" json:"steps"`
+}
+
+type SequenceStepStats struct {
+	StepID     guid.GUID `db:"step_id" json:"step_id"`
+	EmailsSent int64     `db:"emails_sent" json:"emails_sent"`
+}
+
 type GetNewslettersInput struct {
 	WebsiteID guid.GUID `json:"website_id"`
 }
@@ -88,6 +518,10 @@ type GetNewsletterInput struct {
 	ID guid.GUID `json:"id"`
 }
 
+type GetNewsletterAnalyticsInput struct {
+	ID guid.GUID `json:"id"`
+}
+
 type DeleteNewsletterInput struct {
 	ID guid.GUID `json:"id"`
 }
@@ -97,18 +531,42 @@ type SendNewsletterInput struct {
 	Test bool      `json:"test"`
 }
 
+type PauseNewsletterInput struct {
+	ID guid.GUID `json:"id"`
+}
+
+type ResumeNewsletterInput struct {
+	ID guid.GUID `json:"id"`
+}
+
 type CreateNewsletterInput struct {
-	WebsiteID    guid.GUID  `json:"website_id"`
-	ScheduledFor *time.Time `json:"scheduled_for"`
-	Subject      string     `json:"subject"`
-	BodyMarkdown string     `json:"body_markdown"`
+	WebsiteID      guid.GUID    `json:"website_id"`
+	ScheduledFor   *time.Time   `json:"scheduled_for"`
+	Subject        string       `json:"subject"`
+	BodyMarkdown   string       `json:"body_markdown"`
+	MembersOnly    bool         `json:"members_only"`
+	ProductUpdates bool         `json:"product_updates"`
+	ABTest         *ABTestInput `json:"ab_test"`
 }
 
+// UpdateNewsletterInput replaces the A/B test of the newsletter. A nil ABTest removes it
 type UpdateNewsletterInput struct {
-	ID           guid.GUID  `json:"id"`
-	ScheduledFor *time.Time `json:"scheduled_for"`
-	Subject      string     `json:"subject"`
-	BodyMarkdown *string    `json:"body_markdown"`
+	ID             guid.GUID    `json:"id"`
+	ScheduledFor   *time.Time   `json:"scheduled_for"`
+	Subject        string       `json:"subject"`
+	BodyMarkdown   *string      `json:"body_markdown"`
+	MembersOnly    *bool        `json:"members_only"`
+	ProductUpdates *bool        `json:"product_updates"`
+	ABTest         *ABTestInput `json:"ab_test"`
+}
+
+type ABTestInput struct {
+	// the subjects tested in addition to the subject of the newsletter
+	Subjects      []string `json:"subjects"`
+	SamplePercent int64    `json:"sample_percent"`
+	// in seconds
+	Duration      int64        `json:"duration"`
+	WinningMetric ABTestMetric `json:"winning_metric"`
 }
 
 type NewsletterMetadata struct {
@@ -122,4 +580,86 @@ type NewsletterMetadata struct {
 	Hash           kernel.BytesHex `json:"hash"`
 	SentAt         *time.Time      `json:"sent_at"`
 	LastTestSentAt *time.Time      `json:"last_test_sent_at"`
+	MembersOnly    bool            `json:"members_only"`
+	ProductUpdates bool            `json:"product_updates"`
+	Digest         bool            `json:"digest"`
+	ABTest         *ABTest         `json:"ab_test"`
+
+	SendingPausedAt *time.Time `json:"sending_paused_at"`
+}
+
+type CreateSequenceInput struct {
+	WebsiteID        guid.GUID           `json:"website_id"`
+	Name             string              `json:"name"`
+	Trigger          SequenceTrigger     `json:"trigger"`
+	TriggerProductID *guid.GUID          `json:"trigger_product_id"`
+	ExitOnPurchase   bool                `json:"exit_on_purchase"`
+	ExitProductID    *guid.GUID          `json:"exit_product_id"`
+	Steps            []SequenceStepInput `json:"steps"`
+}
+
+// SequenceStepInput.ID should be set for existing steps in order to keep their stats
+type SequenceStepInput struct {
+	ID           *guid.GUID `json:"id"`
+	Delay        int64      `json:"delay"`
+	Subject      string     `json:"subject"`
+	BodyMarkdown string     `json:"body_markdown"`
+}
+
+type UpdateSequenceInput struct {
+	ID               guid.GUID            `json:"id"`
+	Name             *string              `json:"name"`
+	TriggerProductID *guid.GUID           `json:"trigger_product_id"`
+	ExitOnPurchase   *bool                `json:"exit_on_purchase"`
+	ExitProductID    *guid.GUID           `json:"exit_product_id"`
+	Paused           *bool                `json:"paused"`
+	Steps            *[]SequenceStepInput `json:"steps"`
+}
+
+type GetSequenceInput struct {
+	ID guid.GUID `json:"id"`
+}
+
+type GetSequencesInput struct {
+	WebsiteID guid.GUID `json:"website_id"`
+}
+
+type DeleteSequenceInput struct {
+	ID guid.GUID `json:"id"`
+}
+
+// TriggerSequencesInput is used by the other services to make a contact enter the sequences of
+// the website matching the trigger.
+type TriggerSequencesInput struct {
+	WebsiteID guid.GUID
+	ContactID guid.GUID
+	Trigger   SequenceTrigger
+	// the purchased products for SequenceTriggerProductPurchased
+	ProductIDs []guid.GUID
+}
+
+type GetEmailTemplatesInput struct {
+	WebsiteID guid.GUID `json:"website_id"`
+}
+
+// UpdateEmailTemplateInput replaces the template of the given type. An empty Body restores the default
+// template
+type UpdateEmailTemplateInput struct {
+	WebsiteID guid.GUID         `json:"website_id"`
+	Type      EmailTemplateType `json:"type"`
+	Body      string            `json:"body"`
+}
+
+// PreviewEmailTemplateInput renders Body with sample data, without saving it
+type PreviewEmailTemplateInput struct {
+	WebsiteID guid.GUID         `json:"website_id"`
+	Type      EmailTemplateType `json:"type"`
+	Body      string            `json:"body"`
+}
+
+// SendTestEmailTemplateInput sends the preview of Body to the current user
+type SendTestEmailTemplateInput struct {
+	WebsiteID guid.GUID         `json:"website_id"`
+	Type      EmailTemplateType `json:"type"`
+	Body      string            `json:"body"`
 }
@@ -0,0 +1,22 @@
+package emails
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// the "Read online" link prepended to the posts sent as newsletter
+	readOnlineLinkRegexp = regexp.MustCompile(`^\[Read online\]\([^)]*\)\s*<br />\s*`)
+	// markdown links to the unsubscribe page, which is specific to each recipient
+	unsubscribeLinkRegexp = regexp.MustCompile(`\[[^\]]*\]\([^)\s]*/unsubscribe[^)]*\)`)
+)
+
+// NewsletterWebVersion returns the markdown of the newsletter as published in the public archive of the
+// website, without the content which only makes sense in an email.
+func NewsletterWebVersion(bodyMarkdown string) string {
+	bodyMarkdown = strings.TrimSpace(strings.ReplaceAll(bodyMarkdown, "\r\n", "\n"))
+	bodyMarkdown = readOnlineLinkRegexp.ReplaceAllString(bodyMarkdown, "")
+	bodyMarkdown = unsubscribeLinkRegexp.ReplaceAllString(bodyMarkdown, "")
+	return strings.TrimSpace(bodyMarkdown)
+}
@@ -0,0 +1,24 @@
+package emails
+
+import "testing"
+
+func TestNewsletterWebVersion(t *testing.T) {
+	tests := []struct {
+		bodyMarkdown string
+		expected     string
+	}{
+		{"", ""},
+		{"Hello World", "Hello World"},
+		{"[Read online](https://example.com/blog/hello)\n\t<br />\n\tHello World", "Hello World"},
+		{"Hello [Read online](https://example.com) World", "Hello [Read online](https://example.com) World"},
+		{"Hello World\n\n[Unsubscribe](https://example.com/unsubscribe?token=abc)", "Hello World"},
+		{"[Subscribe](https://example.com/subscribe)", "[Subscribe](https://example.com/subscribe)"},
+	}
+
+	for _, test := range tests {
+		webVersion := NewsletterWebVersion(test.bodyMarkdown)
+		if webVersion != test.expected {
+			t.Errorf("NewsletterWebVersion(%q): got %q, expected %q", test.bodyMarkdown, webVersion, test.expected)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/emails"
+)
+
+// UpsertEmailTemplate creates the template or replaces the existing template of the same type
+func (repo *EmailsRepository) UpsertEmailTemplate(ctx context.Context, db db.Queryer, emailTemplate emails.EmailTemplate) (err error) {
+	const query = `INSERT INTO emails_templates
+			(id, created_at, updated_at, type, body, website_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (website_id, type) DO UPDATE
+			SET updated_at = EXCLUDED.updated_at, body = EXCLUDED.body`
+
+	_, err = db.Exec(ctx, query, emailTemplate.ID, emailTemplate.CreatedAt, emailTemplate.UpdatedAt,
+		emailTemplate.Type, emailTemplate.Body, emailTemplate.WebsiteID)
+	if err != nil {
+		err = fmt.Errorf("emails.UpsertEmailTemplate: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *EmailsRepository) FindEmailTemplate(ctx context.Context, db db.Queryer, websiteID guid.GUID, templateType emails.EmailTemplateType) (emailTemplate emails.EmailTemplate, err error) {
+	const query = "SELECT * FROM emails_templates WHERE website_id = $1 AND type = $2"
+
+	err = db.Get(ctx, &emailTemplate, query, websiteID, templateType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = emails.ErrEmailTemplateNotFound
+		} else {
+			err = fmt.Errorf("emails.FindEmailTemplate: %w", err)
+		}
+		return
+	}
+	return
+}
+
+func (repo *EmailsRepository) FindEmailTemplatesByWebsiteID(ctx context.Context, db db.Queryer, websiteID guid.GUID) (emailTemplates []emails.EmailTemplate, err error) {
+	emailTemplates = make([]emails.EmailTemplate, 0)
+	const query = "SELECT * FROM emails_templates WHERE website_id = $1"
+
+	err = db.Select(ctx, &emailTemplates, query, websiteID)
+	if err != nil {
+		err = fmt.Errorf("emails.FindEmailTemplatesByWebsiteID: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *EmailsRepository) DeleteEmailTemplate(ctx context.Context, db db.Queryer, websiteID guid.GUID, templateType emails.EmailTemplateType) (err error) {
+	const query = "DELETE FROM emails_templates WHERE website_id = $1 AND type = $2"
+
+	_, err = db.Exec(ctx, query, websiteID, templateType)
+	if err != nil {
+		err = fmt.Errorf("emails.DeleteEmailTemplate: %w", err)
+		return
+	}
+
+	return
+}
@@ -14,13 +14,14 @@ import (
 func (repo *EmailsRepository) CreateNewsletter(ctx context.Context, db db.Queryer, newsletter emails.Newsletter) (err error) {
 	const query = `INSERT INTO newsletters
 			(id, created_at, updated_at, scheduled_for, subject, size,
-				hash, sent_at, last_test_sent_at, body_markdown, post_id, website_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+				hash, sent_at, last_test_sent_at, body_markdown, members_only, product_updates, digest, ab_test,
+				post_id, website_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
 
 	_, err = db.Exec(ctx, query, newsletter.ID, newsletter.CreatedAt, newsletter.UpdatedAt,
 		newsletter.ScheduledFor, newsletter.Subject, newsletter.Size,
 		newsletter.Hash, newsletter.SentAt, newsletter.LastTestSentAt,
-		newsletter.BodyMarkdown,
+		newsletter.BodyMarkdown, newsletter.MembersOnly, newsletter.ProductUpdates, newsletter.Digest, newsletter.ABTest,
 		newsletter.PostID, newsletter.WebsiteID)
 	if err != nil {
 		err = fmt.Errorf("emails.CreateNewsletter: %w", err)
@@ -76,12 +77,14 @@ func (repo *EmailsRepository) FindNewslettersByWebsiteID(ctx context.Context, db
 func (repo *EmailsRepository) UpdateNewsletter(ctx context.Context, db db.Queryer, newsletter emails.Newsletter) (err error) {
 	const query = `UPDATE newsletters
 		SET updated_at = $1, scheduled_for = $2, subject = $3, size = $4,
-			hash = $5, sent_at = $6, last_test_sent_at = $7, body_markdown = $8
-		WHERE id = $9`
+			hash = $5, sent_at = $6, last_test_sent_at = $7, body_markdown = $8, members_only = $9,
+			tracking_key = $10, ab_test = $11, product_updates = $12
+		WHERE id = $13`
 
 	_, err = db.Exec(ctx, query, newsletter.UpdatedAt, newsletter.ScheduledFor, newsletter.Subject,
 		newsletter.Size, newsletter.Hash, newsletter.SentAt,
-		newsletter.LastTestSentAt, newsletter.BodyMarkdown,
+		newsletter.LastTestSentAt, newsletter.BodyMarkdown, newsletter.MembersOnly,
+		newsletter.TrackingKey, newsletter.ABTest, newsletter.ProductUpdates,
 		newsletter.ID)
 	if err != nil {
 		err = fmt.Errorf("emails.UpdateNewsletter: %w", err)
@@ -93,10 +96,11 @@ func (repo *EmailsRepository) UpdateNewsletter(ctx context.Context, db db.Querye
 
 func (repo *EmailsRepository) FindScheduledNewsletters(ctx context.Context, db db.Queryer, now time.Time) (newsletters []emails.Newsletter, err error) {
 	newsletters = make([]emails.Newsletter, 0)
+	// the newsletters with an A/B test in progress are scheduled for when the winner should be picked
 	const query = `SELECT * FROM newsletters
-		WHERE sent_at IS NULL
-			AND scheduled_for IS NOT NULL
+		WHERE scheduled_for IS NOT NULL
 			AND scheduled_for <= $1
+			AND (sent_at IS NULL OR (ab_test IS NOT NULL AND ab_test->>'winner' IS NULL))
 	`
 
 	err = db.Select(ctx, &newsletters, query, now)
@@ -107,3 +111,59 @@ func (repo *EmailsRepository) FindScheduledNewsletters(ctx context.Context, db d
 
 	return
 }
+
+// FindSendingNewsletters returns the newsletters whose emails are not all sent yet, and whose sending is
+// not paused
+func (repo *EmailsRepository) FindSendingNewsletters(ctx context.Context, db db.Queryer) (newsletters []emails.Newsletter, err error) {
+	newsletters = make([]emails.Newsletter, 0)
+	const query = `SELECT * FROM newsletters
+		WHERE sending_paused_at IS NULL
+			AND EXISTS (
+				SELECT 1 FROM newsletters_recipients
+				WHERE newsletters_recipients.newsletter_id = newsletters.id AND newsletters_recipients.status = $1
+			)
+		ORDER BY sent_at`
+
+	err = db.Select(ctx, &newsletters, query, emails.NewsletterRecipientStatusPending)
+	if err != nil {
+		err = fmt.Errorf("emails.FindSendingNewsletters: %w", err)
+		return
+	}
+
+	return
+}
+
+// UpdateNewsletterSendingPausedAt only updates the pause of the sending to not overwrite the changes made
+// concurrently to the newsletter
+func (repo *EmailsRepository) UpdateNewsletterSendingPausedAt(ctx context.Context, db db.Queryer, newsletterID guid.GUID, pausedAt *time.Time, updatedAt time.Time) (err error) {
+	const query = `UPDATE newsletters
+		SET sending_paused_at = $1, updated_at = $2
+		WHERE id = $3`
+
+	_, err = db.Exec(ctx, query, pausedAt, updatedAt, newsletterID)
+	if err != nil {
+		err = fmt.Errorf("emails.UpdateNewsletterSendingPausedAt: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *EmailsRepository) FindArchivedNewsletters(ctx context.Context, db db.Queryer, websiteID guid.GUID, limit int64) (newsletters []emails.Newsletter, err error) {
+	newsletters = make([]emails.Newsletter, 0)
+	const query = `SELECT * FROM newsletters
+		WHERE website_id = $1
+			AND sent_at IS NOT NULL
+			AND members_only = false
+		ORDER BY sent_at DESC
+		LIMIT $2
+	`
+
+	err = db.Select(ctx, &newsletters, query, websiteID, limit)
+	if err != nil {
+		err = fmt.Errorf("emails.FindArchivedNewsletters: %w", err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/emails"
+)
+
+// CreateNewsletterRecipients inserts the recipients by batches. The recipients who already exist for the
+// newsletter are ignored so the sending of a newsletter can safely be retried.
+func (repo *EmailsRepository) CreateNewsletterRecipients(ctx context.Context, db db.Queryer, recipients []emails.NewsletterRecipient) (err error) {
+	const columns = 11
+	const batchSize = 500
+
+	for batch := range slices.Chunk(recipients, batchSize) {
+		values := make([]string, 0, len(batch))
+		args := make([]any, 0, len(batch)*columns)
+		for i, recipient := range batch {
+			placeholders := make([]string, columns)
+			for column := range columns {
+				placeholders[column] = fmt.Sprintf("$%d", i*columns+column+1)
+			}
+			values = append(values, "("+strings.Join(placeholders, ", ")+")")
+			args = append(args, recipient.ID, recipient.CreatedAt, recipient.UpdatedAt, recipient.Status,
+				recipient.Email, recipient.Name, recipient.Variant, recipient.SentAt,
+				recipient.ContactID, recipient.NewsletterID, recipient.WebsiteID)
+		}
+
+		query := `INSERT INTO newsletters_recipients
+				(id, created_at, updated_at, status, email, name, variant, sent_at,
+					contact_id, newsletter_id, website_id)
+			VALUES ` + strings.Join(values, ", ") + `
+			ON CONFLICT (newsletter_id, contact_id) DO NOTHING`
+
+		_, err = db.Exec(ctx, query, args...)
+		if err != nil {
+			err = fmt.Errorf("emails.CreateNewsletterRecipients: %w", err)
+			return
+		}
+	}
+
+	return
+}
+
+// FindPendingNewsletterRecipients returns and locks the next recipients whose email has not been sent yet
+func (repo *EmailsRepository) FindPendingNewsletterRecipients(ctx context.Context, db db.Queryer, newsletterID guid.GUID, limit int64) (recipients []emails.NewsletterRecipient, err error) {
+	recipients = make([]emails.NewsletterRecipient, 0)
+	const query = `SELECT * FROM newsletters_recipients
+		WHERE newsletter_id = $1 AND status = $2
+		ORDER BY created_at, id
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED`
+
+	err = db.Select(ctx, &recipients, query, newsletterID, emails.NewsletterRecipientStatusPending, limit)
+	if err != nil {
+		err = fmt.Errorf("emails.FindPendingNewsletterRecipients: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *EmailsRepository) UpdateNewsletterRecipient(ctx context.Context, db db.Queryer, recipient emails.NewsletterRecipient) (err error) {
+	const query = `UPDATE newsletters_recipients
+		SET updated_at = $1, status = $2, sent_at = $3
+		WHERE id = $4`
+
+	_, err = db.Exec(ctx, query, recipient.UpdatedAt, recipient.Status, recipient.SentAt, recipient.ID)
+	if err != nil {
+		err = fmt.Errorf("emails.UpdateNewsletterRecipient: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *EmailsRepository) UpdateNewsletterRecipientStatus(ctx context.Context, db db.Queryer, recipientID guid.GUID, status emails.NewsletterRecipientStatus, now time.Time) (err error) {
+	const query = `UPDATE newsletters_recipients
+		SET updated_at = $1, status = $2
+		WHERE id = $3`
+
+	_, err = db.Exec(ctx, query, now, status, recipientID)
+	if err != nil {
+		err = fmt.Errorf("emails.UpdateNewsletterRecipientStatus: %w", err)
+		return
+	}
+
+	return
+}
+
+// CountNewsletterRecipientsSentSince returns the number of emails of newsletters sent by the website
+// since the given date, including the emails scheduled in the future
+func (repo *EmailsRepository) CountNewsletterRecipientsSentSince(ctx context.Context, db db.Queryer, websiteID guid.GUID, since time.Time) (count int64, err error) {
+	const query = `SELECT COUNT(*) FROM newsletters_recipients
+		WHERE website_id = $1 AND sent_at > $2`
+
+	err = db.Get(ctx, &count, query, websiteID, since)
+	if err != nil {
+		err = fmt.Errorf("emails.CountNewsletterRecipientsSentSince: %w", err)
+		return
+	}
+
+	return
+}
+
+// CountOrganizationNewsletterRecipientsSentSince returns the number of emails of newsletters sent by all
+// the websites of the organization since the given date, including the emails scheduled in the future
+func (repo *EmailsRepository) CountOrganizationNewsletterRecipientsSentSince(ctx context.Context, db db.Queryer, organizationID guid.GUID, since time.Time) (count int64, err error) {
+	const query = `SELECT COUNT(*) FROM newsletters_recipients
+		WHERE website_id = ANY(SELECT id FROM websites WHERE organization_id = $1)
+			AND sent_at > $2`
+
+	err = db.Get(ctx, &count, query, organizationID, since)
+	if err != nil {
+		err = fmt.Errorf("emails.CountOrganizationNewsletterRecipientsSentSince: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *EmailsRepository) GetNewsletterProgress(ctx context.Context, db db.Queryer, newsletterID guid.GUID) (progress emails.NewsletterProgress, err error) {
+	const query = `SELECT
+			COUNT(*) FILTER (WHERE status = $2) AS sent,
+			COUNT(*) FILTER (WHERE status = $3) AS remaining,
+			COUNT(*) FILTER (WHERE status = $4) AS failed
+		FROM newsletters_recipients
+		WHERE newsletter_id = $1`
+
+	err = db.Get(ctx, &progress, query, newsletterID, emails.NewsletterRecipientStatusSent,
+		emails.NewsletterRecipientStatusPending, emails.NewsletterRecipientStatusFailed)
+	if err != nil {
+		err = fmt.Errorf("emails.GetNewsletterProgress: %w", err)
+		return
+	}
+
+	return
+}
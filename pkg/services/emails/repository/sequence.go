@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/emails"
+)
+
+func (repo *EmailsRepository) CreateSequence(ctx context.Context, db db.Queryer, sequence emails.Sequence) (err error) {
+	const query = `INSERT INTO emails_sequences
+			(id, created_at, updated_at, name, trigger, trigger_product_id, exit_on_purchase, exit_product_id,
+				paused_at, steps, website_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err = db.Exec(ctx, query, sequence.ID, sequence.CreatedAt, sequence.UpdatedAt,
+		sequence.Name, sequence.Trigger, sequence.TriggerProductID, sequence.ExitOnPurchase, sequence.ExitProductID,
+		sequence.PausedAt, sequence.Steps, sequence.WebsiteID)
+	if err != nil {
+		err = fmt.Errorf("emails.CreateSequence: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *EmailsRepository) UpdateSequence(ctx context.Context, db db.Queryer, sequence emails.Sequence) (err error) {
+	const query = `UPDATE emails_sequences
+		SET updated_at = $1, name = $2, trigger_product_id = $3, exit_on_purchase = $4, exit_product_id = $5,
+			paused_at = $6, steps = $7
+		WHERE id = $8`
+
+	_, err = db.Exec(ctx, query, sequence.UpdatedAt, sequence.Name, sequence.TriggerProductID,
+		sequence.ExitOnPurchase, sequence.ExitProductID, sequence.PausedAt, sequence.Steps,
+		sequence.ID)
+	if err != nil {
+		err = fmt.Errorf("emails.UpdateSequence: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *EmailsRepository) FindSequenceByID(ctx context.Context, db db.Queryer, sequenceID guid.GUID) (sequence emails.Sequence, err error) {
+	const query = "SELECT * FROM emails_sequences WHERE id = $1"
+
+	err = db.Get(ctx, &sequence, query, sequenceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = emails.ErrSequenceNotFound
+		} else {
+			err = fmt.Errorf("emails.FindSequenceByID: %w", err)
+		}
+		return
+	}
+	return
+}
+
+func (repo *EmailsRepository) FindSequencesByWebsiteID(ctx context.Context, db db.Queryer, websiteID guid.GUID) (sequences []emails.Sequence, err error) {
+	sequences = make([]emails.Sequence, 0)
+	const query = `SELECT * FROM emails_sequences
+		WHERE website_id = $1
+		ORDER BY created_at DESC
+	`
+
+	err = db.Select(ctx, &sequences, query, websiteID)
+	if err != nil {
+		err = fmt.Errorf("emails.FindSequencesByWebsiteID: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *EmailsRepository) FindSequencesByTrigger(ctx context.Context, db db.Queryer, websiteID guid.GUID, trigger emails.SequenceTrigger) (sequences []emails.Sequence, err error) {
+	sequences = make([]emails.Sequence, 0)
+	const query = `SELECT * FROM emails_sequences
+		WHERE website_id = $1 AND trigger = $2
+	`
+
+	err = db.Select(ctx, &sequences, query, websiteID, trigger)
+	if err != nil {
+		err = fmt.Errorf("emails.FindSequencesByTrigger: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *EmailsRepository) DeleteSequence(ctx context.Context, db db.Queryer, sequenceID guid.GUID) (err error) {
+	const query = `DELETE FROM emails_sequences WHERE id = $1`
+
+	_, err = db.Exec(ctx, query, sequenceID)
+	if err != nil {
+		err = fmt.Errorf("emails.DeleteSequence: %w", err)
+		return
+	}
+
+	return
+}
+
+// CreateSequenceContact returns created == false if the contact has already entered the sequence
+func (repo *EmailsRepository) CreateSequenceContact(ctx context.Context, db db.Queryer, sequenceContact emails.SequenceContact) (created bool, err error) {
+	const query = `INSERT INTO emails_sequences_contacts
+			(id, created_at, updated_at, status, next_step, next_step_at, exit_reason, sequence_id, contact_id, website_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (sequence_id, contact_id) DO NOTHING`
+
+	res, err := db.Exec(ctx, query, sequenceContact.ID, sequenceContact.CreatedAt, sequenceContact.UpdatedAt,
+		sequenceContact.Status, sequenceContact.NextStep, sequenceContact.NextStepAt, sequenceContact.ExitReason,
+		sequenceContact.SequenceID, sequenceContact.ContactID, sequenceContact.WebsiteID)
+	if err != nil {
+		err = fmt.Errorf("emails.CreateSequenceContact: %w", err)
+		return
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("emails.CreateSequenceContact: getting affected rows: %w", err)
+		return
+	}
+
+	created = rowsAffected == 1
+	return
+}
+
+func (repo *EmailsRepository) UpdateSequenceContact(ctx context.Context, db db.Queryer, sequenceContact emails.SequenceContact) (err error) {
+	const query = `UPDATE emails_sequences_contacts
+		SET updated_at = $1, status = $2, next_step = $3, next_step_at = $4, exit_reason = $5
+		WHERE id = $6`
+
+	_, err = db.Exec(ctx, query, sequenceContact.UpdatedAt, sequenceContact.Status, sequenceContact.NextStep,
+		sequenceContact.NextStepAt, sequenceContact.ExitReason,
+		sequenceContact.ID)
+	if err != nil {
+		err = fmt.Errorf("emails.UpdateSequenceContact: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *EmailsRepository) FindSequenceContactByID(ctx context.Context, db db.Queryer, sequenceContactID guid.GUID) (sequenceContact emails.SequenceContact, err error) {
+	const query = "SELECT * FROM emails_sequences_contacts WHERE id = $1"
+
+	err = db.Get(ctx, &sequenceContact, query, sequenceContactID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = emails.ErrSequenceNotFound
+		} else {
+			err = fmt.Errorf("emails.FindSequenceContactByID: %w", err)
+		}
+		return
+	}
+	return
+}
+
+// FindDueSequenceContacts returns the active contacts of the sequences which are not paused and whose
+// next step is due
+func (repo *EmailsRepository) FindDueSequenceContacts(ctx context.Context, db db.Queryer, now time.Time, limit int64) (sequenceContacts []emails.SequenceContact, err error) {
+	sequenceContacts = make([]emails.SequenceContact, 0, limit)
+	const query = `SELECT emails_sequences_contacts.* FROM emails_sequences_contacts
+			INNER JOIN emails_sequences ON emails_sequences.id = emails_sequences_contacts.sequence_id
+		WHERE emails_sequences_contacts.status = $1
+			AND emails_sequences_contacts.next_step_at <= $2
+			AND emails_sequences.paused_at IS NULL
+		ORDER BY emails_sequences_contacts.next_step_at
+		LIMIT $3
+		FOR UPDATE OF emails_sequences_contacts SKIP LOCKED
+	`
+
+	err = db.Select(ctx, &sequenceContacts, query, emails.SequenceContactStatusActive, now, limit)
+	if err != nil {
+		err = fmt.Errorf("emails.FindDueSequenceContacts: %w", err)
+		return
+	}
+
+	return
+}
+
+// ExitSequencesOnPurchase makes the contact exit the active sequences that are configured to stop when
+// one of the given products is purchased
+func (repo *EmailsRepository) ExitSequencesOnPurchase(ctx context.Context, db db.Queryer, now time.Time, contactID guid.GUID, productIDs []guid.GUID) (err error) {
+	const query = `UPDATE emails_sequences_contacts
+		SET updated_at = $1, status = $2, exit_reason = $3, next_step_at = NULL
+		WHERE contact_id = $4 AND status = $5
+			AND sequence_id IN (
+				SELECT id FROM emails_sequences
+				WHERE exit_on_purchase = true AND (exit_product_id IS NULL OR exit_product_id = ANY($6))
+			)`
+
+	_, err = db.Exec(ctx, query, now, emails.SequenceContactStatusExited, emails.SequenceExitReasonPurchase,
+		contactID, emails.SequenceContactStatusActive, productIDs)
+	if err != nil {
+		err = fmt.Errorf("emails.ExitSequencesOnPurchase: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *EmailsRepository) IncrementSequenceStepEmailsSent(ctx context.Context, db db.Queryer, sequenceID, stepID guid.GUID) (err error) {
+	const query = `INSERT INTO emails_sequences_steps_stats (step_id, emails_sent, sequence_id)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (sequence_id, step_id) DO UPDATE
+			SET emails_sent = emails_sequences_steps_stats.emails_sent + 1`
+
+	_, err = db.Exec(ctx, query, stepID, sequenceID)
+	if err != nil {
+		err = fmt.Errorf("emails.IncrementSequenceStepEmailsSent: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *EmailsRepository) GetSequenceStats(ctx context.Context, db db.Queryer, sequenceID guid.GUID) (stats emails.SequenceStats, err error) {
+	const query = `SELECT
+			COUNT(*) FILTER (WHERE status = $2) AS active_contacts,
+			COUNT(*) FILTER (WHERE status = $3) AS completed_contacts,
+			COUNT(*) FILTER (WHERE status = $4) AS exited_contacts
+		FROM emails_sequences_contacts
+		WHERE sequence_id = $1`
+
+	err = db.Get(ctx, &stats, query, sequenceID, emails.SequenceContactStatusActive,
+		emails.SequenceContactStatusCompleted, emails.SequenceContactStatusExited)
+	if err != nil {
+		err = fmt.Errorf("emails.GetSequenceStats: %w", err)
+		return
+	}
+
+	stats.Steps = make([]emails.SequenceStepStats, 0)
+	const stepsQuery = `SELECT step_id, emails_sent FROM emails_sequences_steps_stats WHERE sequence_id = $1`
+	err = db.Select(ctx, &stats.Steps, stepsQuery, sequenceID)
+	if err != nil {
+		err = fmt.Errorf("emails.GetSequenceStats: steps: %w", err)
+		return
+	}
+
+	return
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/guid"
@@ -12,11 +13,13 @@ import (
 
 func (repo *EmailsRepository) CreateWebsiteConfiguration(ctx context.Context, db db.Queryer, config emails.WebsiteConfiguration) (err error) {
 	const query = `INSERT INTO emails_website_configuration
-			(created_at, updated_at, from_name, from_address, from_domain, domain_verified, dns_records, website_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+			(created_at, updated_at, from_name, from_address, from_domain, domain_verified, dns_records,
+				digest_frequency, send_rate, website_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
 
 	_, err = db.Exec(ctx, query, config.CreatedAt, config.UpdatedAt, config.FromName, config.FromAddress,
-		config.FromDomain, config.DomainVerified, config.DnsRecords, config.WebsiteID)
+		config.FromDomain, config.DomainVerified, config.DnsRecords, config.DigestFrequency, config.SendRate,
+		config.WebsiteID)
 	if err != nil {
 		err = fmt.Errorf("emails.CreateWebsiteConfiguration: %w", err)
 		return
@@ -60,12 +63,17 @@ func (repo *EmailsRepository) FindWebsiteConfigurationByDomain(ctx context.Conte
 func (repo *EmailsRepository) UpdateWebsiteConfiguration(ctx context.Context, db db.Queryer, config emails.WebsiteConfiguration) (err error) {
 	const query = `UPDATE emails_website_configuration
 		SET from_address = $1, from_domain = $2, domain_verified = $3, dns_records = $4,
-			updated_at = $5, from_name = $6
-		WHERE website_id = $7`
+			updated_at = $5, from_name = $6, track_clicks = $7, track_opens = $8, track_contacts = $9,
+			digest_frequency = $10, digest_subject = $11, digest_template = $12, digest_include_excerpts = $13,
+			digest_period_start = $14, digest_next_send_at = $15, archive_path = $16, footer_text = $17,
+			legal_address = $18, domain_verified_at = $19, send_rate = $20
+		WHERE website_id = $21`
 
 	_, err = db.Exec(ctx, query, config.FromAddress, config.FromDomain, config.DomainVerified, config.DnsRecords,
-		config.UpdatedAt, config.FromName,
-		config.WebsiteID)
+		config.UpdatedAt, config.FromName, config.TrackClicks, config.TrackOpens, config.TrackContacts,
+		config.DigestFrequency, config.DigestSubject, config.DigestTemplate, config.DigestIncludeExcerpts,
+		config.DigestPeriodStart, config.DigestNextSendAt, config.ArchivePath, config.FooterText,
+		config.LegalAddress, config.DomainVerifiedAt, config.SendRate, config.WebsiteID)
 	if err != nil {
 		err = fmt.Errorf("emails.UpdateWebsiteConfiguration: %w", err)
 		return
@@ -73,3 +81,37 @@ func (repo *EmailsRepository) UpdateWebsiteConfiguration(ctx context.Context, db
 
 	return
 }
+
+// FindDueDigestWebsiteConfigurations returns the configurations of the websites whose next digest is due
+func (repo *EmailsRepository) FindDueDigestWebsiteConfigurations(ctx context.Context, db db.Queryer, now time.Time, limit int64) (configurations []emails.WebsiteConfiguration, err error) {
+	configurations = make([]emails.WebsiteConfiguration, 0)
+	const query = `SELECT * FROM emails_website_configuration
+		WHERE digest_next_send_at <= $1 AND digest_frequency != $2
+		ORDER BY digest_next_send_at
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED`
+
+	err = db.Select(ctx, &configurations, query, now, emails.DigestFrequencyDisabled, limit)
+	if err != nil {
+		err = fmt.Errorf("emails.FindDueDigestWebsiteConfigurations: %w", err)
+		return
+	}
+
+	return
+}
+
+// UpdateWebsiteConfigurationDigestSchedule only updates the schedule of the digests to not overwrite
+// the changes made concurrently to the rest of the configuration
+func (repo *EmailsRepository) UpdateWebsiteConfigurationDigestSchedule(ctx context.Context, db db.Queryer, websiteID guid.GUID, periodStart, nextSendAt *time.Time) (err error) {
+	const query = `UPDATE emails_website_configuration
+		SET digest_period_start = $1, digest_next_send_at = $2
+		WHERE website_id = $3`
+
+	_, err = db.Exec(ctx, query, periodStart, nextSendAt, websiteID)
+	if err != nil {
+		err = fmt.Errorf("emails.UpdateWebsiteConfigurationDigestSchedule: %w", err)
+		return
+	}
+
+	return
+}
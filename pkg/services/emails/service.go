@@ -2,10 +2,12 @@ package emails
 
 import (
 	"context"
+	"net/http"
 	"net/mail"
 
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/events"
 	"markdown.ninja/pkg/services/websites"
 )
 
@@ -28,13 +30,50 @@ type Service interface {
 	DeleteNewsletter(ctx context.Context, input DeleteNewsletterInput) (err error)
 	UpdateNewsletter(ctx context.Context, input UpdateNewsletterInput) (newsletter Newsletter, err error)
 	SendNewsletter(ctx context.Context, input SendNewsletterInput) (newsletter Newsletter, err error)
+	PauseNewsletter(ctx context.Context, input PauseNewsletterInput) (newsletter Newsletter, err error)
+	ResumeNewsletter(ctx context.Context, input ResumeNewsletterInput) (newsletter Newsletter, err error)
+	GetNewsletterAnalytics(ctx context.Context, input GetNewsletterAnalyticsInput) (analytics events.NewsletterAnalytics, err error)
+
+	// Archive
+	// FindArchivedNewsletters returns the sent newsletters which can be published in the public archive,
+	// the most recent first
+	FindArchivedNewsletters(ctx context.Context, db db.Queryer, websiteID guid.GUID, limit int64) (newsletters []Newsletter, err error)
+	FindArchivedNewsletter(ctx context.Context, db db.Queryer, websiteID, newsletterID guid.GUID) (newsletter Newsletter, err error)
+
+	// Sequences
+	CreateSequence(ctx context.Context, input CreateSequenceInput) (sequence Sequence, err error)
+	GetSequence(ctx context.Context, input GetSequenceInput) (sequence Sequence, err error)
+	GetSequences(ctx context.Context, input GetSequencesInput) (sequences []Sequence, err error)
+	UpdateSequence(ctx context.Context, input UpdateSequenceInput) (sequence Sequence, err error)
+	DeleteSequence(ctx context.Context, input DeleteSequenceInput) (err error)
+	// TriggerSequences makes the contact enter the sequences matching the trigger, and exit the sequences
+	// with an exit condition matching the trigger
+	TriggerSequences(ctx context.Context, db db.Queryer, input TriggerSequencesInput) (err error)
+
+	// Templates
+	GetEmailTemplates(ctx context.Context, input GetEmailTemplatesInput) (templates []EmailTemplate, err error)
+	UpdateEmailTemplate(ctx context.Context, input UpdateEmailTemplateInput) (template EmailTemplate, err error)
+	PreviewEmailTemplate(ctx context.Context, input PreviewEmailTemplateInput) (preview RenderedEmail, err error)
+	SendTestEmailTemplate(ctx context.Context, input SendTestEmailTemplateInput) (err error)
+	// RenderWebsiteEmail renders an email sent on behalf of the website with the custom template of the
+	// website for templateType if any, or with the default template otherwise
+	RenderWebsiteEmail(ctx context.Context, db db.Queryer, website websites.Website, templateType EmailTemplateType, data EmailTemplateData) (email RenderedEmail, err error)
+
+	// Tracking
+	ServeNewsletterClick(res http.ResponseWriter, req *http.Request)
+	ServeNewsletterOpen(res http.ResponseWriter, req *http.Request)
 
 	// Jobs
 	JobDeleteWebsiteConfigurationData(ctx context.Context, input JobDeleteWebsiteConfigurationData) (err error)
 	JobSendNewsletter(ctx context.Context, input JobSendNewsletter) (err error)
 	JobSendPostAsNewsletter(ctx context.Context, input JobSendPostAsNewsletter) (err error)
 	JobSendEmail(ctx context.Context, input JobSendEmail) (err error)
+	JobSendSequenceEmail(ctx context.Context, input JobSendSequenceEmail) (err error)
+	JobSendNewsletterDigest(ctx context.Context, input JobSendNewsletterDigest) (err error)
 
 	// Tasks
 	TaskSendScheduledNewsletters(ctx context.Context)
+	TaskSendSequenceEmails(ctx context.Context)
+	TaskSendNewsletterDigests(ctx context.Context)
+	TaskSendNewsletterEmails(ctx context.Context)
 }
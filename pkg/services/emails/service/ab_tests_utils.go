@@ -0,0 +1,189 @@
+package service
+
+import (
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/zeebo/blake3"
+	"markdown.ninja/pkg/services/contacts"
+	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/events"
+)
+
+// buildABTest validates the A/B test of a newsletter. subject is the subject of the newsletter, which is
+// always the first variant.
+func (service *EmailsService) buildABTest(subject string, input *emails.ABTestInput) (abTest *emails.ABTest, err error) {
+	if input == nil {
+		return nil, nil
+	}
+
+	if len(input.Subjects)+1 > emails.ABTestMaxVariants {
+		err = emails.ErrABTestTooManyVariants
+		return
+	}
+
+	variants := make([]emails.ABTestVariant, 0, len(input.Subjects)+1)
+	variants = append(variants, emails.ABTestVariant{Subject: subject})
+	for _, variantSubject := range input.Subjects {
+		variantSubject = strings.TrimSpace(variantSubject)
+		err = service.validateNewsletterSubject(variantSubject)
+		if err != nil {
+			return
+		}
+
+		for _, variant := range variants {
+			if variant.Subject == variantSubject {
+				err = emails.ErrABTestSubjectsAreNotUnique
+				return
+			}
+		}
+		variants = append(variants, emails.ABTestVariant{Subject: variantSubject})
+	}
+	if len(variants) < 2 {
+		err = emails.ErrABTestNotEnoughSubjects
+		return
+	}
+
+	if input.SamplePercent < emails.ABTestMinSamplePercent || input.SamplePercent > emails.ABTestMaxSamplePercent {
+		err = emails.ErrABTestSamplePercentIsNotValid
+		return
+	}
+
+	if input.Duration < int64(emails.ABTestMinDuration.Seconds()) || input.Duration > int64(emails.ABTestMaxDuration.Seconds()) {
+		err = emails.ErrABTestDurationIsNotValid
+		return
+	}
+
+	switch input.WinningMetric {
+	case emails.ABTestMetricClicks, emails.ABTestMetricOpens:
+	default:
+		err = emails.ErrABTestWinningMetricIsNotValid
+		return
+	}
+
+	abTest = &emails.ABTest{
+		Variants:      variants,
+		SamplePercent: input.SamplePercent,
+		Duration:      input.Duration,
+		WinningMetric: input.WinningMetric,
+		Winner:        nil,
+	}
+	return
+}
+
+// checkABTestTracking returns an error if the winner of the A/B test can't be picked because its winning
+// metric is not tracked by the website
+func checkABTestTracking(config emails.WebsiteConfiguration, abTest *emails.ABTest) error {
+	if abTest == nil {
+		return nil
+	}
+
+	if (abTest.WinningMetric == emails.ABTestMetricClicks && !config.TrackClicks) ||
+		(abTest.WinningMetric == emails.ABTestMetricOpens && !config.TrackOpens) {
+		return emails.ErrABTestRequiresTracking
+	}
+
+	return nil
+}
+
+// startABTest marks the newsletter as sent to the sample and schedules the pick of the winner
+func startABTest(newsletter *emails.Newsletter, now time.Time) {
+	pickWinnerAt := now.Add(time.Duration(newsletter.ABTest.Duration) * time.Second)
+	newsletter.SentAt = &now
+	newsletter.ScheduledFor = &pickWinnerAt
+}
+
+// abTestVariantForContact randomly assigns the contacts to the sample of the A/B test and to a variant.
+// The assignment is derived from the IDs of the newsletter and the contact so it doesn't need to be stored
+// between the sending of the sample and the sending of the winner.
+func abTestVariantForContact(newsletterID, contactID guid.GUID, abTest *emails.ABTest) (variant int64, inSample bool) {
+	hasher := blake3.New()
+	hasher.Write(newsletterID.Bytes())
+	hasher.Write(contactID.Bytes())
+	hash := hasher.Sum(nil)
+	random := binary.LittleEndian.Uint64(hash[:8])
+
+	inSample = random%100 < uint64(abTest.SamplePercent)
+	variant = int64((random / 100) % uint64(len(abTest.Variants)))
+	return
+}
+
+// pickABTestWinner returns the variant with the best rate for the winning metric. In case of a tie, the
+// first variant wins.
+func pickABTestWinner(abTest *emails.ABTest, stats []events.NewsletterVariantStats) int64 {
+	results := computeABTestResults(abTest, stats)
+	winner := 0
+
+	for i, result := range results {
+		rate, winnerRate := result.ClickRate, results[winner].ClickRate
+		if abTest.WinningMetric == emails.ABTestMetricOpens {
+			rate, winnerRate = result.OpenRate, results[winner].OpenRate
+		}
+		if rate > winnerRate {
+			winner = i
+		}
+	}
+
+	return int64(winner)
+}
+
+func computeABTestResults(abTest *emails.ABTest, stats []events.NewsletterVariantStats) []emails.ABTestVariantResult {
+	results := make([]emails.ABTestVariantResult, len(abTest.Variants))
+
+	for i, variant := range abTest.Variants {
+		results[i] = emails.ABTestVariantResult{
+			Subject:    variant.Subject,
+			Recipients: variant.Recipients,
+			Winner:     abTest.Winner != nil && *abTest.Winner == int64(i),
+		}
+	}
+
+	for _, variantStats := range stats {
+		if variantStats.Variant < 0 || variantStats.Variant >= int64(len(results)) {
+			continue
+		}
+
+		result := &results[variantStats.Variant]
+		result.UniqueOpens = variantStats.UniqueOpens
+		result.UniqueClicks = variantStats.UniqueClicks
+		if result.Recipients != 0 {
+			result.OpenRate = float64(result.UniqueOpens) / float64(result.Recipients)
+			result.ClickRate = float64(result.UniqueClicks) / float64(result.Recipients)
+		}
+	}
+
+	return results
+}
+
+// filterABTestContacts returns the contacts who receive the given stage of the A/B test of the newsletter
+// and, for the sample, the variant of each contact.
+// The contacts who subscribed after the sample was sent receive the winner.
+func filterABTestContacts(newsletter emails.Newsletter, stage emails.ABTestStage, recipients []contacts.Contact) (filtered []contacts.Contact, variants map[guid.GUID]int64) {
+	if newsletter.ABTest == nil || stage == emails.ABTestStageNone {
+		return recipients, nil
+	}
+
+	filtered = make([]contacts.Contact, 0, len(recipients))
+	variants = make(map[guid.GUID]int64)
+	for _, contact := range recipients {
+		variant, inSample := abTestVariantForContact(newsletter.ID, contact.ID, newsletter.ABTest)
+		subscribedAfterSample := newsletter.SentAt != nil && contact.SubscribedToNewsletterAt != nil &&
+			contact.SubscribedToNewsletterAt.After(*newsletter.SentAt)
+
+		switch stage {
+		case emails.ABTestStageSample:
+			if inSample {
+				filtered = append(filtered, contact)
+				variants[contact.ID] = variant
+			}
+		case emails.ABTestStageWinner:
+			if !inSample || subscribedAfterSample {
+				filtered = append(filtered, contact)
+			}
+		}
+	}
+
+	return
+}
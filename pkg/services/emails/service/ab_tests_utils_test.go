@@ -0,0 +1,125 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/contacts"
+	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/events"
+)
+
+func TestABTestVariantForContact(t *testing.T) {
+	newsletterID := guid.NewTimeBased()
+	abTest := &emails.ABTest{
+		Variants:      []emails.ABTestVariant{{Subject: "A"}, {Subject: "B"}},
+		SamplePercent: 20,
+	}
+
+	const contactsCount = 10_000
+	inSampleCount := 0
+	variantsCount := make([]int, len(abTest.Variants))
+	for range contactsCount {
+		contactID := guid.NewRandom()
+		variant, inSample := abTestVariantForContact(newsletterID, contactID, abTest)
+
+		// the assignment must not change between the sample and the winner
+		sameVariant, sameInSample := abTestVariantForContact(newsletterID, contactID, abTest)
+		if variant != sameVariant || inSample != sameInSample {
+			t.Fatal("the assignment of a contact is not deterministic")
+		}
+
+		if inSample {
+			inSampleCount += 1
+			variantsCount[variant] += 1
+		}
+	}
+
+	if inSampleCount < contactsCount*15/100 || inSampleCount > contactsCount*25/100 {
+		t.Errorf("expected about 20%% of the contacts in the sample, got %d / %d", inSampleCount, contactsCount)
+	}
+	for variant, count := range variantsCount {
+		if count < inSampleCount*40/100 || count > inSampleCount*60/100 {
+			t.Errorf("variant %d: expected about half of the sample, got %d / %d", variant, count, inSampleCount)
+		}
+	}
+}
+
+func TestPickABTestWinner(t *testing.T) {
+	abTest := &emails.ABTest{
+		Variants:      []emails.ABTestVariant{{Subject: "A", Recipients: 100}, {Subject: "B", Recipients: 50}},
+		WinningMetric: emails.ABTestMetricClicks,
+	}
+	stats := []events.NewsletterVariantStats{
+		{Variant: 0, UniqueOpens: 40, UniqueClicks: 10},
+		{Variant: 1, UniqueOpens: 10, UniqueClicks: 10},
+	}
+
+	// rates are compared, not counts
+	if winner := pickABTestWinner(abTest, stats); winner != 1 {
+		t.Errorf("clicks: expected variant 1 to win, got %d", winner)
+	}
+
+	abTest.WinningMetric = emails.ABTestMetricOpens
+	if winner := pickABTestWinner(abTest, stats); winner != 0 {
+		t.Errorf("opens: expected variant 0 to win, got %d", winner)
+	}
+
+	// the first variant wins ties, and when no data is available
+	if winner := pickABTestWinner(abTest, nil); winner != 0 {
+		t.Errorf("no stats: expected variant 0 to win, got %d", winner)
+	}
+
+	// unknown variants are ignored
+	results := computeABTestResults(abTest, []events.NewsletterVariantStats{{Variant: 5, UniqueOpens: 1}})
+	if len(results) != 2 || results[0].UniqueOpens != 0 || results[1].UniqueOpens != 0 {
+		t.Errorf("unexpected results: %#v", results)
+	}
+}
+
+func TestFilterABTestContacts(t *testing.T) {
+	sentAt := time.Now().UTC().Add(-time.Hour)
+	newsletter := emails.Newsletter{
+		ID:     guid.NewTimeBased(),
+		SentAt: &sentAt,
+		ABTest: &emails.ABTest{
+			Variants:      []emails.ABTestVariant{{Subject: "A"}, {Subject: "B"}, {Subject: "C"}},
+			SamplePercent: 30,
+		},
+	}
+
+	recipients := make([]contacts.Contact, 1_000)
+	for i := range recipients {
+		recipients[i] = contacts.Contact{ID: guid.NewRandom(), SubscribedToNewsletterAt: new(sentAt.Add(-time.Hour))}
+	}
+	// subscribed after the sample has been sent
+	recipients[0].SubscribedToNewsletterAt = new(sentAt.Add(time.Minute))
+
+	sample, variants := filterABTestContacts(newsletter, emails.ABTestStageSample, recipients)
+	winner, _ := filterABTestContacts(newsletter, emails.ABTestStageWinner, recipients)
+
+	if len(sample) != len(variants) {
+		t.Errorf("all the contacts of the sample should have a variant")
+	}
+
+	received := make(map[guid.GUID]int, len(recipients))
+	for _, contact := range append(sample, winner...) {
+		received[contact.ID] += 1
+	}
+	for i, contact := range recipients {
+		count := received[contact.ID]
+		if count == 0 {
+			t.Errorf("contact %d didn't receive the newsletter", i)
+		}
+		_, inSample := variants[contact.ID]
+		if count > 1 && (i != 0 || !inSample) {
+			t.Errorf("contact %d received the newsletter %d times", i, count)
+		}
+	}
+
+	all, variants := filterABTestContacts(emails.Newsletter{ID: newsletter.ID}, emails.ABTestStageNone, recipients)
+	if len(all) != len(recipients) || variants != nil {
+		t.Error("newsletters without A/B test should be sent to all the contacts")
+	}
+}
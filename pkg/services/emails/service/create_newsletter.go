@@ -54,6 +54,24 @@ func (service *EmailsService) CreateNewsletter(ctx context.Context, input emails
 		return
 	}
 
+	abTest, err := service.buildABTest(subject, input.ABTest)
+	if err != nil {
+		return
+	}
+
+	if abTest != nil {
+		var emailConfig emails.WebsiteConfiguration
+		emailConfig, err = service.repo.FindWebsiteConfiguration(ctx, service.db, website.ID)
+		if err != nil {
+			return
+		}
+
+		err = checkABTestTracking(emailConfig, abTest)
+		if err != nil {
+			return
+		}
+	}
+
 	newsletter = emails.Newsletter{
 		ID:             guid.NewTimeBased(),
 		CreatedAt:      now,
@@ -65,6 +83,9 @@ func (service *EmailsService) CreateNewsletter(ctx context.Context, input emails
 		SentAt:         nil,
 		LastTestSentAt: nil,
 		BodyMarkdown:   bodyMarkdown,
+		MembersOnly:    input.MembersOnly,
+		ProductUpdates: input.ProductUpdates,
+		ABTest:         abTest,
 		WebsiteID:      website.ID,
 		PostID:         nil,
 	}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/emails"
+)
+
+func (service *EmailsService) CreateSequence(ctx context.Context, input emails.CreateSequenceInput) (sequence emails.Sequence, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	name := strings.TrimSpace(input.Name)
+	err = validateSequenceName(name)
+	if err != nil {
+		return
+	}
+
+	err = validateSequenceTrigger(input.Trigger)
+	if err != nil {
+		return
+	}
+
+	triggerProductID := input.TriggerProductID
+	if input.Trigger != emails.SequenceTriggerProductPurchased {
+		triggerProductID = nil
+	}
+	err = service.validateSequenceProduct(ctx, input.WebsiteID, triggerProductID)
+	if err != nil {
+		return
+	}
+
+	err = service.validateSequenceProduct(ctx, input.WebsiteID, input.ExitProductID)
+	if err != nil {
+		return
+	}
+
+	steps, err := service.buildSequenceSteps(input.Steps, nil)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	sequence = emails.Sequence{
+		ID:               guid.NewTimeBased(),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+		Name:             name,
+		Trigger:          input.Trigger,
+		TriggerProductID: triggerProductID,
+		ExitOnPurchase:   input.ExitOnPurchase,
+		ExitProductID:    input.ExitProductID,
+		PausedAt:         nil,
+		Steps:            steps,
+		WebsiteID:        input.WebsiteID,
+	}
+	err = service.repo.CreateSequence(ctx, service.db, sequence)
+	if err != nil {
+		return
+	}
+
+	return
+}
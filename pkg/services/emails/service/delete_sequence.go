@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/services/emails"
+)
+
+func (service *EmailsService) DeleteSequence(ctx context.Context, input emails.DeleteSequenceInput) (err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	sequence, err := service.repo.FindSequenceByID(ctx, service.db, input.ID)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, sequence.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	err = service.repo.DeleteSequence(ctx, service.db, sequence.ID)
+	if err != nil {
+		return
+	}
+
+	return
+}
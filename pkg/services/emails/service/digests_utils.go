@@ -0,0 +1,241 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/skerkour/stdx-go/cron"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/contacts"
+	"markdown.ninja/pkg/services/emails"
+)
+
+// the cron schedules of the digests. Times are in UTC
+var digestSchedules = map[emails.DigestFrequency]string{
+	emails.DigestFrequencyWeekly:  "0 9 * * 1",
+	emails.DigestFrequencyMonthly: "0 9 1 * *",
+}
+
+// newsletterDigestData is the data passed to the template of the digests
+type newsletterDigestData struct {
+	WebsiteName string
+	WebsiteUrl  string
+	Posts       []newsletterDigestPost
+}
+
+type newsletterDigestPost struct {
+	Title       string
+	Description string
+	// Excerpt is empty if the website has not enabled excerpts
+	Excerpt string
+	Url     string
+	Date    time.Time
+}
+
+func validateDigestFrequency(frequency emails.DigestFrequency) (err error) {
+	if frequency == emails.DigestFrequencyDisabled {
+		return nil
+	}
+
+	if _, scheduleExists := digestSchedules[frequency]; !scheduleExists {
+		return emails.ErrDigestFrequencyIsNotValid
+	}
+
+	return nil
+}
+
+// validateDigestTemplate checks that the template can be parsed and executed
+func validateDigestTemplate(digestTemplate string) (err error) {
+	if len(digestTemplate) > emails.DigestTemplateMaxSize {
+		return emails.ErrDigestTemplateIsTooLarge
+	}
+
+	if !utf8.ValidString(digestTemplate) {
+		return emails.ErrDigestTemplateIsNotValid(fmt.Errorf("not valid UTF-8"))
+	}
+
+	sampleData := newsletterDigestData{
+		WebsiteName: "Markdown Ninja",
+		WebsiteUrl:  "https://markdown.ninja",
+		Posts: []newsletterDigestPost{
+			{
+				Title:       "Hello World",
+				Description: "My first post",
+				Excerpt:     "Hello",
+				Url:         "https://markdown.ninja/blog/hello-world",
+				Date:        time.Now().UTC(),
+			},
+		},
+	}
+	_, err = renderDigestMarkdown(digestTemplate, sampleData)
+	if err != nil {
+		return emails.ErrDigestTemplateIsNotValid(err)
+	}
+
+	return nil
+}
+
+// nextDigestSendAt returns the next time, strictly after now, at which the digest should be sent
+func nextDigestSendAt(frequency emails.DigestFrequency, now time.Time) (nextSendAt time.Time, err error) {
+	spec, scheduleExists := digestSchedules[frequency]
+	if !scheduleExists {
+		err = emails.ErrDigestFrequencyIsNotValid
+		return
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		err = fmt.Errorf("emails.nextDigestSendAt: parsing cron schedule: %w", err)
+		return
+	}
+
+	nextSendAt = schedule.Next(now.UTC())
+	return
+}
+
+// updateDigestFrequency schedules the next digest. When digests are enabled, the first digest only
+// includes the posts sent from now on, as the previous posts have already been sent individually.
+func updateDigestFrequency(configuration *emails.WebsiteConfiguration, frequency emails.DigestFrequency, now time.Time) (err error) {
+	err = validateDigestFrequency(frequency)
+	if err != nil {
+		return
+	}
+
+	if frequency == emails.DigestFrequencyDisabled {
+		configuration.DigestPeriodStart = nil
+		configuration.DigestNextSendAt = nil
+	} else {
+		var nextSendAt time.Time
+		nextSendAt, err = nextDigestSendAt(frequency, now)
+		if err != nil {
+			return
+		}
+
+		if configuration.DigestFrequency == emails.DigestFrequencyDisabled || configuration.DigestPeriodStart == nil {
+			configuration.DigestPeriodStart = &now
+		}
+		configuration.DigestNextSendAt = &nextSendAt
+	}
+
+	configuration.DigestFrequency = frequency
+	return
+}
+
+func renderDigestMarkdown(digestTemplate string, data newsletterDigestData) (markdown string, err error) {
+	if digestTemplate == "" {
+		digestTemplate = emails.DefaultDigestTemplate
+	}
+
+	tmpl, err := template.New("digest").Option("missingkey=error").Parse(digestTemplate)
+	if err != nil {
+		return
+	}
+
+	// digest templates are sandboxed like the email templates
+	if len(tmpl.Templates()) > 1 {
+		err = errors.New("defining templates is not allowed")
+		return
+	}
+	err = walkEmailTemplate(tmpl.Tree.Root, 0, make(map[string]bool))
+	if err != nil {
+		return
+	}
+
+	return executeTemplateWithLimits(tmpl, data)
+}
+
+func defaultDigestSubject(websiteName string, frequency emails.DigestFrequency) string {
+	switch frequency {
+	case emails.DigestFrequencyMonthly:
+		return websiteName + " - Monthly digest"
+	default:
+		return websiteName + " - Weekly digest"
+	}
+}
+
+// extractPostExcerpt returns the first paragraph of text of the post, truncated to
+// emails.DigestExcerptMaxLength characters. Headings, images, code blocks, quotes, lists and HTML
+// are skipped.
+func extractPostExcerpt(bodyMarkdown string) string {
+	inCodeBlock := false
+
+	for paragraph := range strings.SplitSeq(strings.ReplaceAll(bodyMarkdown, "\r\n", "\n"), "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		// code blocks may contain blank lines, so we need to track where they end
+		if inCodeBlock || strings.HasPrefix(paragraph, "```") {
+			if strings.Count(paragraph, "```")%2 == 1 {
+				inCodeBlock = !inCodeBlock
+			}
+			continue
+		}
+
+		if paragraph == "" || strings.HasPrefix(paragraph, "#") || strings.HasPrefix(paragraph, "![") ||
+			strings.HasPrefix(paragraph, "<") || strings.HasPrefix(paragraph, ">") ||
+			strings.HasPrefix(paragraph, "- ") || strings.HasPrefix(paragraph, "* ") ||
+			strings.HasPrefix(paragraph, "|") || strings.HasPrefix(paragraph, "{{") {
+			continue
+		}
+
+		excerpt := strings.Join(strings.Fields(paragraph), " ")
+		if utf8.RuneCountInString(excerpt) <= emails.DigestExcerptMaxLength {
+			return excerpt
+		}
+
+		// truncate on a word boundary
+		runes := []rune(excerpt)[:emails.DigestExcerptMaxLength]
+		truncated := string(runes)
+		if lastSpace := strings.LastIndexByte(truncated, ' '); lastSpace > 0 {
+			truncated = truncated[:lastSpace]
+		}
+		return strings.TrimRight(truncated, " ,.;:") + "…"
+	}
+
+	return ""
+}
+
+// contactReceivesNewsletter returns false if the contact should not receive the newsletter because of
+// its preferences: the topics it receives, how it wants to receive the posts and their tags.
+// postTagIDs are the tags of the post if the newsletter is a post.
+func contactReceivesNewsletter(config emails.WebsiteConfiguration, newsletter emails.Newsletter, postTagIDs []guid.GUID,
+	contact contacts.Contact) bool {
+	digestsEnabled := config.DigestFrequency != emails.DigestFrequencyDisabled
+	receivesDigests := digestsEnabled && contact.NewsletterDelivery == contacts.NewsletterDeliveryDigest
+
+	switch {
+	case newsletter.Digest:
+		return receivesDigests
+	case newsletter.PostID != nil:
+		if contact.NewsletterDelivery == contacts.NewsletterDeliveryNone {
+			return false
+		}
+		// members-only posts are not included in the digests, so they are always sent individually
+		if receivesDigests && !newsletter.MembersOnly {
+			return false
+		}
+		return contactFollowsPostTags(contact.NewsletterTagIDs, postTagIDs)
+	case newsletter.ProductUpdates:
+		return contact.SubscribedToProductUpdatesAt != nil
+	default:
+		return contact.ReceiveNewsletters
+	}
+}
+
+// contactFollowsPostTags returns true if the contact has not chosen any tag or if the post has at least
+// one of its tags
+func contactFollowsPostTags(contactTagIDs, postTagIDs []guid.GUID) bool {
+	if len(contactTagIDs) == 0 {
+		return true
+	}
+
+	for _, tagID := range contactTagIDs {
+		if slices.Contains(postTagIDs, tagID) {
+			return true
+		}
+	}
+	return false
+}
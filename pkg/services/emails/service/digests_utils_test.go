@@ -0,0 +1,191 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/contacts"
+	"markdown.ninja/pkg/services/emails"
+)
+
+func TestNextDigestSendAt(t *testing.T) {
+	// Wednesday
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	weekly, err := nextDigestSendAt(emails.DigestFrequencyWeekly, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC); !weekly.Equal(expected) {
+		t.Errorf("weekly: expected %s, got %s", expected, weekly)
+	}
+
+	monthly, err := nextDigestSendAt(emails.DigestFrequencyMonthly, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC); !monthly.Equal(expected) {
+		t.Errorf("monthly: expected %s, got %s", expected, monthly)
+	}
+
+	_, err = nextDigestSendAt(emails.DigestFrequencyDisabled, now)
+	if err != emails.ErrDigestFrequencyIsNotValid {
+		t.Errorf("expected ErrDigestFrequencyIsNotValid, got: %v", err)
+	}
+}
+
+func TestUpdateDigestFrequency(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	configuration := emails.WebsiteConfiguration{DigestFrequency: emails.DigestFrequencyDisabled}
+
+	err := updateDigestFrequency(&configuration, emails.DigestFrequencyWeekly, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configuration.DigestPeriodStart == nil || !configuration.DigestPeriodStart.Equal(now) {
+		t.Error("the period of the first digest should start when digests are enabled")
+	}
+	if configuration.DigestNextSendAt == nil {
+		t.Error("the next digest should be scheduled")
+	}
+
+	// changing the frequency should not lose the posts of the current period
+	err = updateDigestFrequency(&configuration, emails.DigestFrequencyMonthly, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !configuration.DigestPeriodStart.Equal(now) {
+		t.Error("the period start should not change when the frequency changes")
+	}
+
+	err = updateDigestFrequency(&configuration, emails.DigestFrequencyDisabled, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configuration.DigestPeriodStart != nil || configuration.DigestNextSendAt != nil {
+		t.Error("the schedule should be reset when digests are disabled")
+	}
+
+	err = updateDigestFrequency(&configuration, "daily", now)
+	if err != emails.ErrDigestFrequencyIsNotValid {
+		t.Errorf("expected ErrDigestFrequencyIsNotValid, got: %v", err)
+	}
+}
+
+func TestRenderDigestMarkdown(t *testing.T) {
+	data := newsletterDigestData{
+		WebsiteName: "Example",
+		WebsiteUrl:  "https://example.com",
+		Posts: []newsletterDigestPost{
+			{Title: "First", Description: "The first post", Url: "https://example.com/first"},
+			{Title: "Second", Excerpt: "Hello world", Url: "https://example.com/second"},
+		},
+	}
+
+	markdown, err := renderDigestMarkdown("", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"## [First](https://example.com/first)", "The first post",
+		"## [Second](https://example.com/second)", "Hello world"} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("%q not found in digest: %s", expected, markdown)
+		}
+	}
+
+	err = validateDigestTemplate("{{ range .Posts }}{{ .Unknown }}{{ end }}")
+	if err == nil {
+		t.Error("template with an unknown field should not be valid")
+	}
+	err = validateDigestTemplate("{{ range .Posts }")
+	if err == nil {
+		t.Error("template with a syntax error should not be valid")
+	}
+	err = validateDigestTemplate("{{ range 100000 }}{{ range 100000 }}{{ end }}{{ end }}")
+	if err == nil {
+		t.Error("template with a range over an integer should not be valid")
+	}
+	err = validateDigestTemplate("# {{ .WebsiteName }}\n{{ range .Posts }}- [{{ .Title }}]({{ .Url }})\n{{ end }}")
+	if err != nil {
+		t.Errorf("valid template rejected: %v", err)
+	}
+}
+
+func TestExtractPostExcerpt(t *testing.T) {
+	tests := []struct {
+		body     string
+		expected string
+	}{
+		{"# Title\n\n![image](/image.png)\n\nFirst\nparagraph.\n\nSecond paragraph.", "First paragraph."},
+		{"```\ncode\n\nmore code\n```\n\nAfter the code.", "After the code."},
+		{"## Only a heading", ""},
+		{strings.Repeat("abcdefg ", 100), strings.TrimSpace(strings.Repeat("abcdefg ", 37)) + "…"},
+	}
+
+	for _, test := range tests {
+		excerpt := extractPostExcerpt(test.body)
+		if excerpt != test.expected {
+			t.Errorf("expected excerpt %q, got %q", test.expected, excerpt)
+		}
+	}
+}
+
+func TestContactReceivesNewsletter(t *testing.T) {
+	postID := guid.NewTimeBased()
+	tagID := guid.NewTimeBased()
+	otherTagID := guid.NewTimeBased()
+	now := time.Now().UTC()
+
+	postNewsletter := emails.Newsletter{PostID: &postID}
+	membersPostNewsletter := emails.Newsletter{PostID: &postID, MembersOnly: true}
+	digestNewsletter := emails.Newsletter{Digest: true}
+	newsletter := emails.Newsletter{}
+	productUpdatesNewsletter := emails.Newsletter{ProductUpdates: true}
+
+	digestContact := contacts.Contact{NewsletterDelivery: contacts.NewsletterDeliveryDigest, ReceiveNewsletters: true}
+	postsContact := contacts.Contact{NewsletterDelivery: contacts.NewsletterDeliveryPosts, ReceiveNewsletters: true,
+		SubscribedToProductUpdatesAt: &now}
+	noPostsContact := contacts.Contact{NewsletterDelivery: contacts.NewsletterDeliveryNone}
+	tagContact := contacts.Contact{NewsletterDelivery: contacts.NewsletterDeliveryPosts, NewsletterTagIDs: []guid.GUID{tagID}}
+
+	disabled := emails.WebsiteConfiguration{DigestFrequency: emails.DigestFrequencyDisabled}
+	weekly := emails.WebsiteConfiguration{DigestFrequency: emails.DigestFrequencyWeekly}
+
+	tests := []struct {
+		config     emails.WebsiteConfiguration
+		newsletter emails.Newsletter
+		postTagIDs []guid.GUID
+		contact    contacts.Contact
+		expected   bool
+	}{
+		{disabled, postNewsletter, nil, digestContact, true},
+		{disabled, digestNewsletter, nil, digestContact, false},
+		{weekly, postNewsletter, nil, digestContact, false},
+		{weekly, postNewsletter, nil, postsContact, true},
+		{weekly, digestNewsletter, nil, digestContact, true},
+		{weekly, digestNewsletter, nil, postsContact, false},
+		{weekly, membersPostNewsletter, nil, digestContact, true},
+		{weekly, newsletter, nil, digestContact, true},
+		{weekly, newsletter, nil, postsContact, true},
+		// topics
+		{disabled, postNewsletter, nil, noPostsContact, false},
+		{weekly, digestNewsletter, nil, noPostsContact, false},
+		{disabled, newsletter, nil, noPostsContact, false},
+		{disabled, productUpdatesNewsletter, nil, postsContact, true},
+		{disabled, productUpdatesNewsletter, nil, digestContact, false},
+		// tags
+		{disabled, postNewsletter, []guid.GUID{tagID}, tagContact, true},
+		{disabled, postNewsletter, []guid.GUID{otherTagID, tagID}, tagContact, true},
+		{disabled, postNewsletter, []guid.GUID{otherTagID}, tagContact, false},
+		{disabled, postNewsletter, nil, tagContact, false},
+		{disabled, postNewsletter, []guid.GUID{otherTagID}, postsContact, true},
+	}
+
+	for i, test := range tests {
+		if receives := contactReceivesNewsletter(test.config, test.newsletter, test.postTagIDs, test.contact); receives != test.expected {
+			t.Errorf("test %d: expected %v, got %v", i, test.expected, receives)
+		}
+	}
+}
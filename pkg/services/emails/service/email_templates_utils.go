@@ -0,0 +1,405 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"text/template/parse"
+	"time"
+	"unicode/utf8"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/emails/templates"
+	"markdown.ninja/pkg/services/websites"
+)
+
+const (
+	// the maximum size of a rendered email. Bigger emails are clipped by most email clients anyway.
+	emailTemplateMaxOutputSize = 2_000_000
+	// nested ranges are limited to avoid templates which take forever to execute
+	emailTemplateMaxRangeDepth = 2
+	// templates are validated to only loop over the data, but we never want a template to block the
+	// sending of emails
+	emailTemplateExecutionTimeout = 2 * time.Second
+)
+
+var defaultEmailTemplates = map[emails.EmailTemplateType]string{
+	emails.EmailTemplateTypeNewsletter:        templates.NewsletterEmailTemplate,
+	emails.EmailTemplateTypeLogin:             templates.LoginEmailTemplate,
+	emails.EmailTemplateTypeSubscribe:         templates.SubscribeEmailTemplate,
+	emails.EmailTemplateTypeVerifyEmail:       templates.VerifyEmailEmailTemplate,
+	emails.EmailTemplateTypeOrderConfirmation: templates.OrderConfirmationEmailTemplate,
+}
+
+// the fields of EmailTemplateData that the custom templates must use, so that the emails remain
+// useful (and legal) whatever the template
+var emailTemplatesRequiredVariables = map[emails.EmailTemplateType][]string{
+	emails.EmailTemplateTypeNewsletter:        {"Content", "UnsubscribeLink"},
+	emails.EmailTemplateTypeLogin:             {"Code", "Link"},
+	emails.EmailTemplateTypeSubscribe:         {"Code", "Link"},
+	emails.EmailTemplateTypeVerifyEmail:       {"Link"},
+	emails.EmailTemplateTypeOrderConfirmation: {"OrderID", "AccountURL", "LicenseKeys"},
+}
+
+var (
+	errEmailTemplateOutputTooLarge    = errors.New("rendered email is too large")
+	errEmailTemplateExecutionTimedOut = errors.New("rendering the email took too long")
+)
+
+type emailTemplate struct {
+	template *template.Template
+	// the open tracking pixel is appended to the templates which don't use it so that the tracking
+	// doesn't depend on the template
+	usesOpenTrackingPixel bool
+}
+
+func validateEmailTemplateType(templateType emails.EmailTemplateType) error {
+	if !slices.Contains(emails.EmailTemplateTypes, templateType) {
+		return emails.ErrEmailTemplateTypeIsNotValid
+	}
+	return nil
+}
+
+func parseDefaultEmailTemplates() (parsedTemplates map[emails.EmailTemplateType]emailTemplate, err error) {
+	parsedTemplates = make(map[emails.EmailTemplateType]emailTemplate, len(defaultEmailTemplates))
+	for templateType, body := range defaultEmailTemplates {
+		parsedTemplates[templateType], err = parseEmailTemplate(templateType, body)
+		if err != nil {
+			return nil, fmt.Errorf("parsing default %s template: %w", templateType, err)
+		}
+	}
+	return
+}
+
+// parseEmailTemplate parses and validates a template. Templates can only use the data they are executed
+// with: defining or including other templates is not allowed.
+func parseEmailTemplate(templateType emails.EmailTemplateType, body string) (parsedTemplate emailTemplate, err error) {
+	err = validateEmailTemplateType(templateType)
+	if err != nil {
+		return
+	}
+
+	if len(body) > emails.EmailTemplateMaxSize {
+		err = emails.ErrEmailTemplateIsTooLarge
+		return
+	}
+
+	if !utf8.ValidString(body) {
+		err = emails.ErrEmailTemplateIsNotValid(errors.New("not valid UTF-8"))
+		return
+	}
+
+	tmpl, err := template.New("emails." + string(templateType)).Option("missingkey=error").Parse(body)
+	if err != nil {
+		err = emails.ErrEmailTemplateIsNotValid(err)
+		return
+	}
+
+	if len(tmpl.Templates()) > 1 {
+		err = emails.ErrEmailTemplateIsNotValid(errors.New("defining templates is not allowed"))
+		return
+	}
+
+	usedFields := make(map[string]bool)
+	err = walkEmailTemplate(tmpl.Tree.Root, 0, usedFields)
+	if err != nil {
+		err = emails.ErrEmailTemplateIsNotValid(err)
+		return
+	}
+
+	for _, variable := range emailTemplatesRequiredVariables[templateType] {
+		if !usedFields[variable] {
+			err = emails.ErrEmailTemplateMissingVariable(variable)
+			return
+		}
+	}
+
+	parsedTemplate = emailTemplate{
+		template:              tmpl,
+		usesOpenTrackingPixel: usedFields["OpenTrackingPixel"],
+	}
+	return
+}
+
+// walkEmailTemplate collects the top-level fields of the data used by the template, and rejects the
+// constructs which are not allowed
+func walkEmailTemplate(node parse.Node, rangeDepth int, usedFields map[string]bool) error {
+	switch node := node.(type) {
+	case nil:
+		return nil
+	case *parse.ListNode:
+		if node == nil {
+			return nil
+		}
+		for _, child := range node.Nodes {
+			if err := walkEmailTemplate(child, rangeDepth, usedFields); err != nil {
+				return err
+			}
+		}
+	case *parse.TemplateNode:
+		return errors.New("including templates is not allowed")
+	case *parse.ActionNode:
+		return walkEmailTemplate(node.Pipe, rangeDepth, usedFields)
+	case *parse.PipeNode:
+		if node == nil {
+			return nil
+		}
+		for _, command := range node.Cmds {
+			if err := walkEmailTemplate(command, rangeDepth, usedFields); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range node.Args {
+			if err := walkEmailTemplate(arg, rangeDepth, usedFields); err != nil {
+				return err
+			}
+		}
+	case *parse.ChainNode:
+		return walkEmailTemplate(node.Node, rangeDepth, usedFields)
+	case *parse.FieldNode:
+		usedFields[node.Ident[0]] = true
+	case *parse.VariableNode:
+		// $.Field
+		if len(node.Ident) > 1 && node.Ident[0] == "$" {
+			usedFields[node.Ident[1]] = true
+		}
+	case *parse.IfNode:
+		return walkEmailTemplateBranch(&node.BranchNode, rangeDepth, usedFields)
+	case *parse.WithNode:
+		return walkEmailTemplateBranch(&node.BranchNode, rangeDepth, usedFields)
+	case *parse.RangeNode:
+		if rangeDepth >= emailTemplateMaxRangeDepth {
+			return fmt.Errorf("ranges can't be nested more than %d times", emailTemplateMaxRangeDepth)
+		}
+		if !isEmailTemplateDataPipe(node.Pipe) {
+			return errors.New("range can only be used with a field of the data")
+		}
+		return walkEmailTemplateBranch(&node.BranchNode, rangeDepth+1, usedFields)
+	}
+
+	return nil
+}
+
+// isEmailTemplateDataPipe returns true if pipe is only a field of the data (.Field, $.Field or .).
+// Since Go 1.22, range can iterate over integers, so ranging over anything else, such as {{ range 100000 }},
+// would allow templates which never end.
+func isEmailTemplateDataPipe(pipe *parse.PipeNode) bool {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode, *parse.DotNode:
+		return true
+	case *parse.VariableNode:
+		return len(arg.Ident) > 1 && arg.Ident[0] == "$"
+	default:
+		return false
+	}
+}
+
+func walkEmailTemplateBranch(node *parse.BranchNode, rangeDepth int, usedFields map[string]bool) error {
+	if err := walkEmailTemplate(node.Pipe, rangeDepth, usedFields); err != nil {
+		return err
+	}
+	if err := walkEmailTemplate(node.List, rangeDepth, usedFields); err != nil {
+		return err
+	}
+	return walkEmailTemplate(node.ElseList, rangeDepth, usedFields)
+}
+
+// findEmailTemplate returns the custom template of the website for templateType, or the default
+// template if the website has not customized it
+func (service *EmailsService) findEmailTemplate(ctx context.Context, db db.Queryer, websiteID guid.GUID, templateType emails.EmailTemplateType) (parsedTemplate emailTemplate, err error) {
+	customTemplate, err := service.repo.FindEmailTemplate(ctx, db, websiteID, templateType)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			err = nil
+			parsedTemplate = service.defaultEmailTemplates[templateType]
+		}
+		return
+	}
+
+	parsedTemplate, err = parseEmailTemplate(templateType, customTemplate.Body)
+	if err != nil {
+		// templates are validated when saved, but we never want to stop sending emails because a template
+		// is no longer valid
+		slogx.FromCtx(ctx).Warn("emails.findEmailTemplate: custom template is not valid, using default template",
+			slogx.Err(err), slog.String("website.id", websiteID.String()), slog.String("type", string(templateType)))
+		err = nil
+		parsedTemplate = service.defaultEmailTemplates[templateType]
+	}
+
+	return
+}
+
+// fillEmailTemplateData sets the fields of data that are common to all the emails of the website
+func (service *EmailsService) fillEmailTemplateData(data *emails.EmailTemplateData, website websites.Website, config emails.WebsiteConfiguration) {
+	data.Website = emails.EmailTemplateWebsite{
+		Name: website.Name,
+		Url:  service.httpConfig.WebsitesBaseUrl.Scheme + "://" + website.PrimaryDomain + service.httpConfig.WebsitesPort,
+	}
+	if website.Logo != nil {
+		data.Website.Logo = *website.Logo
+	}
+	data.Colors = website.Colors
+	data.FooterText = config.FooterText
+	data.LegalAddress = config.LegalAddress
+}
+
+// render renders the HTML of an email
+func (emailTemplate emailTemplate) render(data emails.EmailTemplateData) (html string, err error) {
+	html, err = executeTemplateWithLimits(emailTemplate.template, data)
+	if err != nil {
+		return
+	}
+
+	if data.OpenTrackingPixel != "" && !emailTemplate.usesOpenTrackingPixel {
+		pixel := `<img src="` + template.HTMLEscapeString(string(data.OpenTrackingPixel)) +
+			`" width="1" height="1" alt="" style="display:block;width:1px;height:1px;border:0;" />`
+		if bodyEnd := strings.LastIndex(html, "</body>"); bodyEnd != -1 {
+			html = html[:bodyEnd] + pixel + html[bodyEnd:]
+		} else {
+			html += pixel
+		}
+	}
+
+	return
+}
+
+// fillEmailTemplateSampleData sets the fields specific to templateType with the data used to preview the
+// templates. The common fields must already be set.
+func fillEmailTemplateSampleData(data *emails.EmailTemplateData, templateType emails.EmailTemplateType) {
+	website := data.Website
+	switch templateType {
+	case emails.EmailTemplateTypeNewsletter:
+		data.Subject = "My first newsletter"
+		data.Content = template.HTML("<h2>Hello World</h2>\n<p>This is the content of your newsletter, with <a href=\"" +
+			template.HTMLEscapeString(website.Url) + "\">a link</a>.</p>")
+		data.UnsubscribeLink = template.URL(website.Url + "/unsubscribe")
+	case emails.EmailTemplateTypeLogin:
+		data.Subject = "Your Login code: 123456"
+		data.Code = "123456"
+		data.Link = template.URL(website.Url + "/login")
+	case emails.EmailTemplateTypeSubscribe:
+		data.Subject = "Your confirmation code: 123456"
+		data.Code = "123456"
+		data.Link = template.URL(website.Url + "/subscribe")
+	case emails.EmailTemplateTypeVerifyEmail:
+		data.Subject = "Confirm your email address"
+		data.Link = template.URL(website.Url + "/account")
+	case emails.EmailTemplateTypeOrderConfirmation:
+		orderID := guid.NewTimeBased().String()
+		data.Subject = "Order #" + orderID + " confirmed"
+		data.OrderID = orderID
+		data.AccountURL = template.URL(website.Url + "/account")
+		data.LicenseKeys = []emails.EmailTemplateLicenseKey{
+			{ProductName: "My Software", Key: "ABCD-EFGH-1234-5678"},
+		}
+	}
+}
+
+// renderEmailTemplatePreview validates body and renders it with sample data. An empty body previews the
+// default template.
+func (service *EmailsService) renderEmailTemplatePreview(ctx context.Context, websiteID guid.GUID, templateType emails.EmailTemplateType, body string) (preview emails.RenderedEmail, err error) {
+	err = validateEmailTemplateType(templateType)
+	if err != nil {
+		return
+	}
+
+	parsedTemplate := service.defaultEmailTemplates[templateType]
+	if strings.TrimSpace(body) != "" {
+		parsedTemplate, err = parseEmailTemplate(templateType, body)
+		if err != nil {
+			return
+		}
+	}
+
+	website, err := service.websitesService.FindWebsiteByID(ctx, service.db, websiteID)
+	if err != nil {
+		return
+	}
+
+	config, err := service.repo.FindWebsiteConfiguration(ctx, service.db, websiteID)
+	if err != nil {
+		return
+	}
+
+	var data emails.EmailTemplateData
+	service.fillEmailTemplateData(&data, website, config)
+	fillEmailTemplateSampleData(&data, templateType)
+
+	preview.Subject = data.Subject
+	preview.Html, err = parsedTemplate.render(data)
+	if err != nil {
+		err = emails.ErrEmailTemplateIsNotValid(err)
+		return
+	}
+	preview.Text = htmlToText(preview.Html)
+
+	return
+}
+
+// executableTemplate is implemented by both html/template and text/template
+type executableTemplate interface {
+	Execute(output io.Writer, data any) error
+}
+
+// executeTemplateWithLimits executes a template written by a website owner, limiting the size of the
+// output and the execution time.
+func executeTemplateWithLimits(tmpl executableTemplate, data any) (output string, err error) {
+	buffer := &limitedBuffer{
+		limit:    emailTemplateMaxOutputSize,
+		deadline: time.Now().Add(emailTemplateExecutionTimeout),
+	}
+
+	// templates can't be canceled, so they are executed in their own goroutine to not block the caller
+	// if they never end. The output buffer stops them at their next write.
+	done := make(chan error, 1)
+	go func() {
+		done <- tmpl.Execute(buffer, data)
+	}()
+
+	timer := time.NewTimer(emailTemplateExecutionTimeout)
+	defer timer.Stop()
+	select {
+	case err = <-done:
+	case <-timer.C:
+		err = errEmailTemplateExecutionTimedOut
+	}
+	if err != nil {
+		return
+	}
+
+	output = buffer.buffer.String()
+	return
+}
+
+// limitedBuffer is an io.Writer which returns an error once more than limit bytes are written, or
+// once deadline is passed
+type limitedBuffer struct {
+	buffer   bytes.Buffer
+	limit    int
+	deadline time.Time
+}
+
+func (limited *limitedBuffer) Write(data []byte) (int, error) {
+	if !limited.deadline.IsZero() && time.Now().After(limited.deadline) {
+		return 0, errEmailTemplateExecutionTimedOut
+	}
+	if limited.buffer.Len()+len(data) > limited.limit {
+		return 0, errEmailTemplateOutputTooLarge
+	}
+	return limited.buffer.Write(data)
+}
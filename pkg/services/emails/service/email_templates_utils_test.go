@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+
+	"markdown.ninja/pkg/services/emails"
+)
+
+func TestParseEmailTemplate(t *testing.T) {
+	for templateType, body := range defaultEmailTemplates {
+		if _, err := parseEmailTemplate(templateType, body); err != nil {
+			t.Errorf("default %s template is not valid: %v", templateType, err)
+		}
+	}
+
+	invalidTemplates := []struct {
+		name         string
+		templateType emails.EmailTemplateType
+		body         string
+	}{
+		{"missing unsubscribe link", emails.EmailTemplateTypeNewsletter, `<html><body>{{ .Content }}</body></html>`},
+		{"missing code", emails.EmailTemplateTypeLogin, `<a href="{{ .Link }}">Log in</a>`},
+		{"syntax error", emails.EmailTemplateTypeVerifyEmail, `<a href="{{ .Link }">Confirm</a>`},
+		{"define", emails.EmailTemplateTypeVerifyEmail, `{{ define "x" }}{{ .Link }}{{ end }}{{ .Link }}`},
+		{"block", emails.EmailTemplateTypeVerifyEmail, `{{ block "x" . }}{{ .Link }}{{ end }}`},
+		{"nested ranges", emails.EmailTemplateTypeOrderConfirmation,
+			`{{ .OrderID }} {{ .AccountURL }} {{ range .LicenseKeys }}{{ range $.LicenseKeys }}{{ range $.LicenseKeys }}{{ end }}{{ end }}{{ end }}`},
+		{"range over integer", emails.EmailTemplateTypeVerifyEmail,
+			`{{ .Link }}{{ range 100000 }}{{ range 100000 }}{{ end }}{{ end }}`},
+		{"range over variable", emails.EmailTemplateTypeVerifyEmail,
+			`{{ .Link }}{{ $n := 100000 }}{{ range $n }}{{ end }}`},
+		{"range over function", emails.EmailTemplateTypeOrderConfirmation,
+			`{{ .OrderID }} {{ .AccountURL }} {{ range len .LicenseKeys }}{{ end }}`},
+		{"unknown type", emails.EmailTemplateType("unknown"), `{{ .Link }}`},
+	}
+	for _, test := range invalidTemplates {
+		if _, err := parseEmailTemplate(test.templateType, test.body); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+
+	// variables can be accessed with $ and inside with blocks
+	_, err := parseEmailTemplate(emails.EmailTemplateTypeLogin, `{{ with .Link }}<a href="{{ . }}">{{ $.Code }}</a>{{ end }}`)
+	if err != nil {
+		t.Errorf("valid template: %v", err)
+	}
+
+	_, err = parseEmailTemplate(emails.EmailTemplateTypeOrderConfirmation,
+		`{{ .OrderID }} {{ .AccountURL }} {{ range $i, $key := .LicenseKeys }}{{ $key.Key }}{{ end }}{{ with .LicenseKeys }}{{ range . }}{{ .Key }}{{ end }}{{ end }}`)
+	if err != nil {
+		t.Errorf("valid range: %v", err)
+	}
+}
+
+func TestRenderEmailTemplate(t *testing.T) {
+	const pixelUrl = "https://example.com/open?n=1&c=2"
+
+	customTemplate, err := parseEmailTemplate(emails.EmailTemplateTypeNewsletter,
+		`<html><body style="color: {{ .Colors.Text }}"><h1>{{ .Subject }}</h1>{{ .Content }}<a href="{{ .UnsubscribeLink }}">Unsubscribe</a></body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultTemplate, err := parseEmailTemplate(emails.EmailTemplateTypeNewsletter, defaultEmailTemplates[emails.EmailTemplateTypeNewsletter])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := emails.EmailTemplateData{
+		Subject:           "<script>alert(1)</script>",
+		Content:           template.HTML("<p>Hello</p>"),
+		UnsubscribeLink:   template.URL("https://example.com/unsubscribe"),
+		OpenTrackingPixel: template.URL(pixelUrl),
+	}
+	data.Colors.Text = "#000000"
+
+	html, err := customTemplate.render(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("subject is not escaped")
+	}
+	if !strings.Contains(html, "<p>Hello</p>") {
+		t.Error("content is missing")
+	}
+	if !strings.Contains(html, `color: #000000`) {
+		t.Error("color is missing")
+	}
+	pixel := `<img src="` + template.HTMLEscapeString(pixelUrl) + `"`
+	if strings.Count(html, pixel) != 1 || !strings.HasSuffix(html, "</body></html>") {
+		t.Error("the open tracking pixel should be appended to the body of templates which don't use it")
+	}
+
+	html, err = defaultTemplate.render(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(html, "/open?n=1") != 1 {
+		t.Error("the open tracking pixel should not be added to templates which use it")
+	}
+}
+
+func TestRenderEmailTemplateTimeout(t *testing.T) {
+	// bypasses the validation of parseEmailTemplate, which rejects this template
+	neverEndingTemplate := emailTemplate{
+		template: template.Must(template.New("test").Parse(`{{ range 100000 }}{{ range 100000 }}{{ end }}{{ end }}`)),
+	}
+
+	start := time.Now()
+	_, err := neverEndingTemplate.render(emails.EmailTemplateData{})
+	if !errors.Is(err, errEmailTemplateExecutionTimedOut) {
+		t.Errorf("expected errEmailTemplateExecutionTimedOut, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*emailTemplateExecutionTimeout {
+		t.Errorf("render took %s", elapsed)
+	}
+}
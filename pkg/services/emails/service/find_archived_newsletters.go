@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/emails"
+)
+
+func (service *EmailsService) FindArchivedNewsletters(ctx context.Context, db db.Queryer, websiteID guid.GUID, limit int64) (newsletters []emails.Newsletter, err error) {
+	newsletters, err = service.repo.FindArchivedNewsletters(ctx, db, websiteID, limit)
+	return
+}
+
+// FindArchivedNewsletter returns emails.ErrNewsletterNotFound if the newsletter can't be published in the
+// public archive of the website
+func (service *EmailsService) FindArchivedNewsletter(ctx context.Context, db db.Queryer, websiteID, newsletterID guid.GUID) (newsletter emails.Newsletter, err error) {
+	newsletter, err = service.repo.FindNewsletterByID(ctx, db, newsletterID)
+	if err != nil {
+		return
+	}
+
+	if !newsletter.WebsiteID.Equal(websiteID) || newsletter.SentAt == nil || newsletter.MembersOnly {
+		err = emails.ErrNewsletterNotFound
+		return
+	}
+
+	return
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/services/emails"
+)
+
+// GetEmailTemplates returns the templates of all the types of emails, with the default template for the
+// types that the website has not customized
+func (service *EmailsService) GetEmailTemplates(ctx context.Context, input emails.GetEmailTemplatesInput) (emailTemplates []emails.EmailTemplate, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	customTemplates, err := service.repo.FindEmailTemplatesByWebsiteID(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	emailTemplates = make([]emails.EmailTemplate, 0, len(emails.EmailTemplateTypes))
+	for _, templateType := range emails.EmailTemplateTypes {
+		emailTemplate := emails.EmailTemplate{
+			Type:      templateType,
+			Body:      defaultEmailTemplates[templateType],
+			WebsiteID: input.WebsiteID,
+		}
+		for _, customTemplate := range customTemplates {
+			if customTemplate.Type == templateType {
+				emailTemplate = customTemplate
+				emailTemplate.Customized = true
+				break
+			}
+		}
+		emailTemplate.DefaultBody = defaultEmailTemplates[templateType]
+		emailTemplate.RequiredVariables = emailTemplatesRequiredVariables[templateType]
+		emailTemplates = append(emailTemplates, emailTemplate)
+	}
+
+	return
+}
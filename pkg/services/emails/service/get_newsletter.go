@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/events"
 )
 
 func (service *EmailsService) GetNewsletter(ctx context.Context, input emails.GetNewsletterInput) (newsletter emails.Newsletter, err error) {
@@ -22,5 +23,19 @@ func (service *EmailsService) GetNewsletter(ctx context.Context, input emails.Ge
 		return
 	}
 
+	if newsletter.ABTest != nil && newsletter.SentAt != nil {
+		var variantsStats []events.NewsletterVariantStats
+		variantsStats, err = service.eventsService.GetNewsletterVariantsStats(ctx, newsletter.WebsiteID, newsletter.ID)
+		if err != nil {
+			return
+		}
+		newsletter.ABTestResults = computeABTestResults(newsletter.ABTest, variantsStats)
+	}
+
+	err = service.fillNewsletterProgress(ctx, &newsletter)
+	if err != nil {
+		return
+	}
+
 	return
 }
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/events"
+)
+
+func (service *EmailsService) GetNewsletterAnalytics(ctx context.Context, input emails.GetNewsletterAnalyticsInput) (analytics events.NewsletterAnalytics, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	newsletter, err := service.repo.FindNewsletterByID(ctx, service.db, input.ID)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, newsletter.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	analytics, err = service.eventsService.GetNewsletterAnalytics(ctx, newsletter.WebsiteID, newsletter.ID)
+	if err != nil {
+		return
+	}
+
+	return
+}
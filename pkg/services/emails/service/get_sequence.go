@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/services/emails"
+)
+
+func (service *EmailsService) GetSequence(ctx context.Context, input emails.GetSequenceInput) (sequence emails.Sequence, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	sequence, err = service.repo.FindSequenceByID(ctx, service.db, input.ID)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, sequence.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	stats, err := service.repo.GetSequenceStats(ctx, service.db, sequence.ID)
+	if err != nil {
+		return
+	}
+	sequence.Stats = &stats
+
+	return
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/services/emails"
+)
+
+func (service *EmailsService) GetSequences(ctx context.Context, input emails.GetSequencesInput) (sequences []emails.Sequence, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	sequences, err = service.repo.FindSequencesByWebsiteID(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	return
+}
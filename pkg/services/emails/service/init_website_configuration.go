@@ -13,14 +13,16 @@ import (
 func (service *EmailsService) InitWebsiteConfiguration(ctx context.Context, db db.Queryer, websiteID guid.GUID, name string) (configuration emails.WebsiteConfiguration, err error) {
 	now := time.Now().UTC()
 	configuration = emails.WebsiteConfiguration{
-		CreatedAt:      now,
-		UpdatedAt:      now,
-		FromName:       name,
-		FromAddress:    "",
-		FromDomain:     "",
-		DnsRecords:     []mailer.DnsRecord{},
-		DomainVerified: false,
-		WebsiteID:      websiteID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		FromName:        name,
+		FromAddress:     "",
+		FromDomain:      "",
+		DnsRecords:      []mailer.DnsRecord{},
+		DomainVerified:  false,
+		DigestFrequency: emails.DigestFrequencyDisabled,
+		SendRate:        emails.DefaultNewsletterSendRate,
+		WebsiteID:       websiteID,
 	}
 
 	err = service.repo.CreateWebsiteConfiguration(ctx, db, configuration)
@@ -3,10 +3,14 @@ package service
 import (
 	"context"
 	"fmt"
+	"maps"
 	"net/mail"
 	"strings"
+	"time"
 
 	"github.com/skerkour/stdx-go/email"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"markdown.ninja/pkg/emailfeedback"
 	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/events"
 )
@@ -17,6 +21,8 @@ func (service *EmailsService) JobSendEmail(ctx context.Context, input emails.Job
 	var bodyText []byte
 	if input.BodyText != nil {
 		bodyText = []byte(*input.BodyText)
+	} else if input.BodyHtml != "" {
+		bodyText = []byte(htmlToText(input.BodyHtml))
 	}
 
 	var from mail.Address
@@ -29,6 +35,20 @@ func (service *EmailsService) JobSendEmail(ctx context.Context, input emails.Job
 		}
 	}
 
+	// the IDs of the website and the newsletter are added to the headers so that the bounces and complaints
+	// reported by the email provider can be attributed to them
+	headers := input.Headers
+	if input.WebsiteID != nil {
+		headers = maps.Clone(input.Headers)
+		if headers == nil {
+			headers = make(map[string][]string, 2)
+		}
+		headers[emailfeedback.HeaderWebsiteID] = []string{input.WebsiteID.String()}
+		if input.NewsletterID != nil {
+			headers[emailfeedback.HeaderNewsletterID] = []string{input.NewsletterID.String()}
+		}
+	}
+
 	to := mail.Address{
 		Name:    input.ToName,
 		Address: input.ToAddress,
@@ -39,7 +59,7 @@ func (service *EmailsService) JobSendEmail(ctx context.Context, input emails.Job
 		Subject: input.Subject,
 		HTML:    []byte(input.BodyHtml),
 		Text:    bodyText,
-		Headers: input.Headers,
+		Headers: headers,
 	}
 
 	// we don't use retry here and prefer to instead rely on the retry mechanism of the queue because we don't
@@ -54,6 +74,13 @@ func (service *EmailsService) JobSendEmail(ctx context.Context, input emails.Job
 	}
 	// if the email provider returns an error that the contact have been "suppressed" (marked as spam, complaint...)
 	if err != nil && strings.Contains(err.Error(), "that have been marked as inactive") {
+		if input.NewsletterRecipientID != nil {
+			err = service.repo.UpdateNewsletterRecipientStatus(ctx, service.db, *input.NewsletterRecipientID,
+				emails.NewsletterRecipientStatusFailed, time.Now().UTC())
+			if err != nil {
+				slogx.FromCtx(ctx).Error("emails.JobSendEmail: error updating the status of the newsletter recipient", slogx.Err(err))
+			}
+		}
 		return nil
 	} else if err != nil {
 		return fmt.Errorf("emails.JobSendEmail: sending email: %w", err)
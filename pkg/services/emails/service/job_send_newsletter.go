@@ -1,14 +1,10 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"html/template"
-	"net/mail"
 	"slices"
-	"strings"
 	"time"
 
 	"log/slog"
@@ -16,22 +12,14 @@ import (
 	"github.com/skerkour/stdx-go/guid"
 	"github.com/skerkour/stdx-go/log/slogx"
 	"github.com/skerkour/stdx-go/queue"
+	"github.com/skerkour/stdx-go/set"
 	"markdown.ninja/pkg/errs"
-	"markdown.ninja/pkg/markdown"
 	"markdown.ninja/pkg/services/contacts"
 	"markdown.ninja/pkg/services/content"
 	"markdown.ninja/pkg/services/emails"
-	"markdown.ninja/pkg/services/emails/templates"
-	"markdown.ninja/pkg/services/organizations"
+	"markdown.ninja/pkg/services/websites"
 )
 
-type newsletterRecipient struct {
-	Name            string
-	Email           string
-	ContactID       *guid.GUID
-	UnsubscribeLink string
-}
-
 func (service *EmailsService) JobSendNewsletter(ctx context.Context, input emails.JobSendNewsletter) error {
 	logger := slogx.FromCtx(ctx).With(slog.String("newsletter.id", input.NewsletterID.String()))
 	newsletter, err := service.repo.FindNewsletterByID(ctx, service.db, input.NewsletterID)
@@ -73,65 +61,76 @@ func (service *EmailsService) JobSendNewsletter(ctx context.Context, input email
 		return errors.New("emails.JobSendNewsletter: No custom domain configured")
 	}
 
-	var recipients []newsletterRecipient
-	from := mail.Address{
-		Name:    emailConfig.FromName,
-		Address: emailConfig.FromAddress,
+	if input.Test {
+		return service.sendTestNewsletter(ctx, newsletter, website, emailConfig, input.TestEmails)
 	}
 
-	if input.Test {
-		recipients = make([]newsletterRecipient, len(input.TestEmails))
-		for i, testEmail := range input.TestEmails {
-			recipients[i] = newsletterRecipient{
-				Name:            testEmail,
-				Email:           testEmail,
-				ContactID:       nil,
-				UnsubscribeLink: "https://placeholder_unsubscribe_link", // TODO: dummy link?
-			}
-		}
-	} else {
-		var recipientsContacts []contacts.Contact
-		recipientsContacts, err = service.contactsService.FindVerifiedAndSubscribedToNewsletterContacts(ctx, service.db, website.ID)
+	recipientsContacts, err := service.contactsService.FindVerifiedAndSubscribedToNewsletterContacts(ctx, service.db, website.ID)
+	if err != nil {
+		return err
+	}
+
+	var postTagIDs []guid.GUID
+	if newsletter.PostID != nil {
+		var postTags []content.Tag
+		postTags, err = service.contentService.FindTagsForPage(ctx, service.db, *newsletter.PostID)
 		if err != nil {
 			return err
 		}
+		postTagIDs = make([]guid.GUID, len(postTags))
+		for i, tag := range postTags {
+			postTagIDs[i] = tag.ID
+		}
+	}
 
-		recipients = make([]newsletterRecipient, len(recipientsContacts))
-		for i, contact := range recipientsContacts {
-			unsubscribeLink, unsubscribeLinkErr := service.contactsService.GenerateUnsubscribeLink(website.PrimaryDomain, contact.ID)
-			if unsubscribeLinkErr != nil {
-				logger.Error("email.JobSendNewsletter: error generating unsubscribeLink", slogx.Err(err),
-					slog.String("contact.id", contact.ID.String()))
-				continue
-			}
-			recipients[i] = newsletterRecipient{
-				Name:            contact.Name,
-				Email:           contact.Email,
-				ContactID:       &contact.ID,
-				UnsubscribeLink: unsubscribeLink,
-			}
+	// the contacts choose the topics they receive in the preference center
+	recipientsContacts = slices.DeleteFunc(recipientsContacts, func(contact contacts.Contact) bool {
+		return !contactReceivesNewsletter(emailConfig, newsletter, postTagIDs, contact)
+	})
+
+	if newsletter.MembersOnly {
+		var membersContactIDs []guid.GUID
+		membersContactIDs, err = service.storeService.FindActiveMembersContactIDs(ctx, service.db, website.ID)
+		if err != nil {
+			return err
 		}
+
+		members := set.NewFromSlice(membersContactIDs)
+		recipientsContacts = slices.DeleteFunc(recipientsContacts, func(contact contacts.Contact) bool {
+			return !members.Contains(contact.ID)
+		})
 	}
 
-	contentMarkdown := newsletter.BodyMarkdown
-	contentHtml, err := markdown.ToHtmlEmail(
-		service.httpConfig.WebsitesBaseUrl.Scheme+"://"+website.PrimaryDomain+service.httpConfig.WebsitesPort,
-		contentMarkdown,
-	)
-	if err != nil {
-		return fmt.Errorf("emails.JobSendNewsletter: error converting markdown to HTML: %w", err)
+	var abTestVariants map[guid.GUID]int64
+	recipientsContacts, abTestVariants = filterABTestContacts(newsletter, input.ABTestStage, recipientsContacts)
+	if input.ABTestStage == emails.ABTestStageSample && newsletter.ABTest != nil {
+		// the job may be retried
+		for i := range newsletter.ABTest.Variants {
+			newsletter.ABTest.Variants[i].Recipients = 0
+		}
 	}
 
-	// TODO: do we really want to render all the snippets?
-	if strings.Contains(contentHtml, "{{<") {
-		var snippets []content.Snippet
-		snippets, err = service.contentService.FindSnippets(ctx, service.db, newsletter.WebsiteID)
-		if err != nil {
-			return fmt.Errorf("emails.JobSendNewsletter: Finding snippets: %w", err)
+	// the emails are not sent directly: they are scheduled progressively by TaskSendNewsletterEmails
+	// according to the send rates and the warmup of the website
+	now := time.Now().UTC()
+	recipients := make([]emails.NewsletterRecipient, len(recipientsContacts))
+	for i, contact := range recipientsContacts {
+		recipients[i] = emails.NewsletterRecipient{
+			ID:           guid.NewTimeBased(),
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			Status:       emails.NewsletterRecipientStatusPending,
+			Email:        contact.Email,
+			Name:         contact.Name,
+			Variant:      nil,
+			SentAt:       nil,
+			ContactID:    contact.ID,
+			NewsletterID: newsletter.ID,
+			WebsiteID:    website.ID,
 		}
-		if len(snippets) != 0 {
-			contentHtml = service.contentService.RenderSnippets(contentHtml, snippets, true)
-			// contentMarkdown = service.contentService.RenderSnippets(contentMarkdown, snippets, true)
+		if variant, isInSample := abTestVariants[contact.ID]; isInSample {
+			recipients[i].Variant = &variant
+			newsletter.ABTest.Variants[variant].Recipients += 1
 		}
 	}
 
@@ -141,84 +140,59 @@ func (service *EmailsService) JobSendNewsletter(ctx context.Context, input email
 	}
 	defer tx.Rollback()
 
-	scheduledFor := time.Now().UTC()
-
-	// emails are pushed to the queue by batches of 256
-	// we do that to limit the amount of RAM used both by the app servers, and by the database
-	for recipientsChunk := range slices.Chunk(recipients, 256) {
-		jobs := make([]queue.NewJobInput, 0, len(recipientsChunk))
-
-		// for each recipient we generate an email
-		for i, recipient := range recipientsChunk {
-			emailBodyBuffer := bytes.NewBuffer(make([]byte, 0, len(contentHtml)))
-
-			subject := newsletter.Subject
-			if input.Test {
-				subject = "[Test] " + subject
-			}
-
-			emailData := templates.NewsletterEmailData{
-				Subject:         newsletter.Subject,
-				Content:         template.HTML(contentHtml),
-				UnsubscribeLink: template.URL(recipient.UnsubscribeLink),
-			}
-			err = service.newsletterEmailTemplate.Execute(emailBodyBuffer, emailData)
-			if err != nil {
-				logger.Error("emails.JobSendNewsletter: error executing email template", slogx.Err(err))
-				err = nil
-				continue
-			}
-
-			// bodyText := contentMarkdown + "\n\n\n" + "Unsubscribe: " + recipient.UnsubscribeLink
-
-			// don't send all the emails at the same time
-			if i != 0 && (i%emails.NewsletterRateLimit == 0) {
-				scheduledFor = scheduledFor.Add(time.Second)
-			}
-
-			sendEmailJob := queue.NewJobInput{
-				ScheduledFor: &scheduledFor,
-				Data: emails.JobSendEmail{
-					Type:        emails.EmailTypeBroadcast,
-					FromAddress: from.Address,
-					FromName:    from.Name,
-					ToAddress:   recipient.Email,
-					ToName:      recipient.Name,
-					Subject:     subject,
-					BodyHtml:    emailBodyBuffer.String(),
-					// BodyText:    &bodyText,
-					Headers: map[string][]string{
-						// See here for more information: https://mailtrap.io/blog/list-unsubscribe-header
-						// https://sendgrid.com/blog/list-unsubscribe
-						// https://www.gmass.co/blog/list-unsubscribe-header/
-						// TODO: mailto:
-						"List-Unsubscribe": {"<" + recipient.UnsubscribeLink + ">"},
-					},
-					WebsiteID:      &website.ID,
-					ContactID:      recipient.ContactID,
-					NewsletterID:   &newsletter.ID,
-					OrganizationID: nil,
-				},
-			}
-			jobs = append(jobs, sendEmailJob)
+	err = service.repo.CreateNewsletterRecipients(ctx, tx, recipients)
+	if err != nil {
+		return err
+	}
+
+	// the size of the sample is needed to compute the rates of the variants
+	if input.ABTestStage == emails.ABTestStageSample && newsletter.ABTest != nil {
+		err = service.repo.UpdateNewsletter(ctx, tx, newsletter)
+		if err != nil {
+			return err
 		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("emails.JobSendNewsletter: Comitting DB transaction: %w", err)
+	}
+
+	return nil
+}
+
+// sendTestNewsletter sends the newsletter immediately to the test emails
+func (service *EmailsService) sendTestNewsletter(ctx context.Context, newsletter emails.Newsletter, website websites.Website,
+	emailConfig emails.WebsiteConfiguration, testEmails []string) (err error) {
+	renderer, err := service.newNewsletterEmailRenderer(ctx, newsletter, website, emailConfig, true)
+	if err != nil {
+		return fmt.Errorf("emails.JobSendNewsletter: %w", err)
+	}
 
-		err = service.queue.PushMany(ctx, tx, jobs)
+	jobs := make([]queue.NewJobInput, 0, len(testEmails))
+	for _, testEmail := range testEmails {
+		var email emails.JobSendEmail
+		email, err = renderer.render(newsletterRecipient{
+			Name:            testEmail,
+			Email:           testEmail,
+			ContactID:       nil,
+			UnsubscribeLink: "https://placeholder_unsubscribe_link", // TODO: dummy link?
+		})
 		if err != nil {
-			return fmt.Errorf("emails.JobSendNewsletter: pushing JobSendEmail jobs to queue: %w", err)
+			return fmt.Errorf("emails.JobSendNewsletter: %w", err)
 		}
+		jobs = append(jobs, queue.NewJobInput{Data: email})
 	}
 
-	// we report data usage 1 minute after all the emails have been sent
-	sendUsageDataJob := queue.NewJobInput{
-		ScheduledFor: new(scheduledFor.Add(time.Minute)),
-		Data: organizations.JobSendUsageData{
-			OrganizationID: website.OrganizationID,
-		},
+	tx, err := service.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("emails.JobSendNewsletter: Starting DB transaction: %w", err)
 	}
-	err = service.queue.Push(ctx, tx, sendUsageDataJob)
+	defer tx.Rollback()
+
+	err = service.queue.PushMany(ctx, tx, jobs)
 	if err != nil {
-		return fmt.Errorf("emails.JobSendNewsletter: pushing JobSendUsageData to queue: %w", err)
+		return fmt.Errorf("emails.JobSendNewsletter: pushing JobSendEmail jobs to queue: %w", err)
 	}
 
 	err = tx.Commit()
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/queue"
+	"github.com/zeebo/blake3"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/content"
+	"markdown.ninja/pkg/services/emails"
+)
+
+// JobSendNewsletterDigest composes the digest of the public posts sent as newsletter during the period
+// and sends it. Nothing is sent if no post has been published during the period.
+func (service *EmailsService) JobSendNewsletterDigest(ctx context.Context, input emails.JobSendNewsletterDigest) error {
+	logger := slogx.FromCtx(ctx).With(slog.String("website.id", input.WebsiteID.String()))
+
+	emailConfig, err := service.repo.FindWebsiteConfiguration(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		if !errs.IsNotFound(err) {
+			return err
+		}
+
+		// website has been deleted...
+		logger.Debug("emails.JobSendNewsletterDigest: website configuration not found")
+		return nil
+	}
+
+	// digests may have been disabled after the job was pushed
+	if emailConfig.DigestFrequency == emails.DigestFrequencyDisabled {
+		return nil
+	}
+
+	website, err := service.websitesService.FindWebsiteByID(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		return err
+	}
+
+	posts, err := service.contentService.FindPostsSentAsNewsletter(ctx, service.db, website.ID,
+		input.PeriodStart, input.PeriodEnd, emails.DigestMaxPosts)
+	if err != nil {
+		return err
+	}
+
+	websiteUrl := service.httpConfig.WebsitesBaseUrl.Scheme + "://" + website.PrimaryDomain + service.httpConfig.WebsitesPort
+	digestData := newsletterDigestData{
+		WebsiteName: website.Name,
+		WebsiteUrl:  websiteUrl,
+		Posts:       make([]newsletterDigestPost, 0, len(posts)),
+	}
+	for _, post := range posts {
+		// members-only posts are sent individually to the members
+		if post.Visibility == content.PageVisibilityMembers {
+			continue
+		}
+
+		digestPost := newsletterDigestPost{
+			Title:       post.Title,
+			Description: post.Description,
+			Url:         websiteUrl + post.Path,
+			Date:        post.Date,
+		}
+		if emailConfig.DigestIncludeExcerpts {
+			digestPost.Excerpt = extractPostExcerpt(post.BodyMarkdown)
+		}
+		digestData.Posts = append(digestData.Posts, digestPost)
+	}
+
+	if len(digestData.Posts) == 0 {
+		logger.Debug("emails.JobSendNewsletterDigest: no new post, skipping digest")
+		return nil
+	}
+
+	bodyMarkdown, err := renderDigestMarkdown(emailConfig.DigestTemplate, digestData)
+	if err != nil {
+		return fmt.Errorf("emails.JobSendNewsletterDigest: rendering digest template: %w", err)
+	}
+
+	subject := emailConfig.DigestSubject
+	if subject == "" {
+		subject = defaultDigestSubject(website.Name, emailConfig.DigestFrequency)
+	}
+
+	now := time.Now().UTC()
+	bodyHash := blake3.Sum256([]byte(bodyMarkdown))
+	newsletter := emails.Newsletter{
+		ID:             guid.NewTimeBased(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		ScheduledFor:   &now,
+		Subject:        subject,
+		Size:           int64(len(bodyMarkdown)),
+		Hash:           bodyHash[:],
+		SentAt:         &now,
+		LastTestSentAt: nil,
+		BodyMarkdown:   bodyMarkdown,
+		MembersOnly:    false,
+		ProductUpdates: false,
+		Digest:         true,
+		WebsiteID:      website.ID,
+		PostID:         nil,
+	}
+
+	tx, err := service.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("emails.JobSendNewsletterDigest: Starting DB transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = service.repo.CreateNewsletter(ctx, tx, newsletter)
+	if err != nil {
+		return err
+	}
+
+	job := queue.NewJobInput{
+		Data: emails.JobSendNewsletter{
+			NewsletterID: newsletter.ID,
+			Test:         false,
+			SentAt:       now,
+		},
+		Timeout: new(int64(600)),
+	}
+	err = service.queue.Push(ctx, tx, job)
+	if err != nil {
+		return fmt.Errorf("emails.JobSendNewsletterDigest: pushing SendNewsletter job to queue: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("emails.JobSendNewsletterDigest: Comitting DB transaction: %w", err)
+	}
+
+	return nil
+}
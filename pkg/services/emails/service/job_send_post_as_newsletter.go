@@ -8,6 +8,7 @@ import (
 	"github.com/skerkour/stdx-go/guid"
 	"github.com/skerkour/stdx-go/queue"
 	"github.com/zeebo/blake3"
+	"markdown.ninja/pkg/services/content"
 	"markdown.ninja/pkg/services/emails"
 )
 
@@ -38,6 +39,8 @@ func (service *EmailsService) JobSendPostAsNewsletter(ctx context.Context, input
 		SentAt:         &now,
 		LastTestSentAt: nil,
 		BodyMarkdown:   bodyMarkdown,
+		MembersOnly:    post.Visibility == content.PageVisibilityMembers,
+		ProductUpdates: false,
 		WebsiteID:      post.WebsiteID,
 		PostID:         &post.ID,
 	}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"time"
+
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/queue"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/emails"
+)
+
+func (service *EmailsService) JobSendSequenceEmail(ctx context.Context, input emails.JobSendSequenceEmail) error {
+	logger := slogx.FromCtx(ctx).With(slog.String("sequence_contact.id", input.SequenceContactID.String()))
+
+	sequenceContact, err := service.repo.FindSequenceContactByID(ctx, service.db, input.SequenceContactID)
+	if err != nil {
+		if !errs.IsNotFound(err) {
+			return err
+		}
+
+		// the sequence or the contact has been deleted
+		logger.Debug("emails.JobSendSequenceEmail: contact in sequence not found")
+		return nil
+	}
+
+	// the contact may have exited the sequence after the job was pushed
+	if sequenceContact.Status == emails.SequenceContactStatusExited {
+		return nil
+	}
+
+	sequence, err := service.repo.FindSequenceByID(ctx, service.db, sequenceContact.SequenceID)
+	if err != nil {
+		if !errs.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	if input.Step >= int64(len(sequence.Steps)) {
+		return nil
+	}
+	step := sequence.Steps[input.Step]
+
+	contact, err := service.contactsService.FindContact(ctx, service.db, sequenceContact.ContactID)
+	if err != nil {
+		if !errs.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	if contact.SubscribedToNewsletterAt == nil || !contact.Verified || contact.BlockedAt != nil {
+		sequenceContact.UpdatedAt = time.Now().UTC()
+		sequenceContact.Status = emails.SequenceContactStatusExited
+		sequenceContact.ExitReason = emails.SequenceExitReasonUnsubscribed
+		sequenceContact.NextStepAt = nil
+		return service.repo.UpdateSequenceContact(ctx, service.db, sequenceContact)
+	}
+
+	emailConfig, err := service.repo.FindWebsiteConfiguration(ctx, service.db, sequence.WebsiteID)
+	if err != nil {
+		return err
+	}
+
+	// TODO: improve error
+	if !emailConfig.DomainVerified {
+		return errors.New("emails.JobSendSequenceEmail: No custom domain configured")
+	}
+
+	website, err := service.websitesService.FindWebsiteByID(ctx, service.db, sequence.WebsiteID)
+	if err != nil {
+		return err
+	}
+
+	contentHtml, err := service.renderEmailContentHtml(ctx, website, step.BodyMarkdown)
+	if err != nil {
+		return fmt.Errorf("emails.JobSendSequenceEmail: %w", err)
+	}
+
+	unsubscribeLink, err := service.contactsService.GenerateUnsubscribeLink(website.PrimaryDomain, contact.ID)
+	if err != nil {
+		return fmt.Errorf("emails.JobSendSequenceEmail: generating unsubscribe link: %w", err)
+	}
+
+	newsletterTemplate, err := service.findEmailTemplate(ctx, service.db, website.ID, emails.EmailTemplateTypeNewsletter)
+	if err != nil {
+		return fmt.Errorf("emails.JobSendSequenceEmail: %w", err)
+	}
+
+	emailData := emails.EmailTemplateData{
+		Subject:         step.Subject,
+		Content:         template.HTML(contentHtml),
+		UnsubscribeLink: template.URL(unsubscribeLink),
+	}
+	service.fillEmailTemplateData(&emailData, website, emailConfig)
+	emailBody, err := newsletterTemplate.render(emailData)
+	if err != nil {
+		return fmt.Errorf("emails.JobSendSequenceEmail: executing email template: %w", err)
+	}
+
+	tx, err := service.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("emails.JobSendSequenceEmail: Starting DB transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sendEmailJob := queue.NewJobInput{
+		Data: emails.JobSendEmail{
+			Type:           emails.EmailTypeBroadcast,
+			FromAddress:    emailConfig.FromAddress,
+			FromName:       emailConfig.FromName,
+			ToAddress:      contact.Email,
+			ToName:         contact.Name,
+			Subject:        step.Subject,
+			BodyHtml:       emailBody,
+			Headers:        unsubscribeHeaders(unsubscribeLink, true),
+			WebsiteID:      &website.ID,
+			ContactID:      &contact.ID,
+			NewsletterID:   nil,
+			OrganizationID: nil,
+		},
+	}
+	err = service.queue.Push(ctx, tx, sendEmailJob)
+	if err != nil {
+		return fmt.Errorf("emails.JobSendSequenceEmail: pushing JobSendEmail to queue: %w", err)
+	}
+
+	err = service.repo.IncrementSequenceStepEmailsSent(ctx, tx, sequence.ID, step.ID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("emails.JobSendSequenceEmail: Comitting DB transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/mail"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/websites"
+)
+
+type newsletterRecipient struct {
+	Name            string
+	Email           string
+	ContactID       *guid.GUID
+	UnsubscribeLink string
+	// random identifier used to count unique opens and clicks without tracking the contact. It is never
+	// stored with the recipient, so the events can't be tied to the contact unless trackContacts is enabled.
+	TrackingID guid.GUID
+	// the variant of the subject for the recipients of the sample of an A/B test
+	Variant *int64
+	// nil for the test emails
+	NewsletterRecipientID *guid.GUID
+}
+
+// newsletterEmailRenderer renders the emails of a newsletter for its recipients. The content of the
+// newsletter and its template are only rendered and parsed once.
+type newsletterEmailRenderer struct {
+	newsletter       emails.Newsletter
+	websiteID        guid.GUID
+	from             mail.Address
+	test             bool
+	trackClicks      bool
+	trackOpens       bool
+	trackContacts    bool
+	trackingBaseUrl  string
+	contentHtml      string
+	trackedContent   newsletterTrackedContent
+	template         emailTemplate
+	websiteEmailData emails.EmailTemplateData
+}
+
+// newNewsletterEmailRenderer prepares the rendering of the emails of the newsletter. Test emails are
+// never tracked.
+func (service *EmailsService) newNewsletterEmailRenderer(ctx context.Context, newsletter emails.Newsletter, website websites.Website,
+	emailConfig emails.WebsiteConfiguration, test bool) (renderer newsletterEmailRenderer, err error) {
+	renderer = newsletterEmailRenderer{
+		newsletter: newsletter,
+		websiteID:  website.ID,
+		from: mail.Address{
+			Name:    emailConfig.FromName,
+			Address: emailConfig.FromAddress,
+		},
+		test:            test,
+		trackClicks:     emailConfig.TrackClicks && !test,
+		trackOpens:      emailConfig.TrackOpens && !test,
+		trackContacts:   emailConfig.TrackContacts,
+		trackingBaseUrl: service.newsletterTrackingBaseUrl(website.PrimaryDomain),
+	}
+
+	renderer.contentHtml, err = service.renderEmailContentHtml(ctx, website, newsletter.BodyMarkdown)
+	if err != nil {
+		return
+	}
+
+	renderer.template, err = service.findEmailTemplate(ctx, service.db, website.ID, emails.EmailTemplateTypeNewsletter)
+	if err != nil {
+		return
+	}
+	service.fillEmailTemplateData(&renderer.websiteEmailData, website, emailConfig)
+
+	if renderer.trackClicks {
+		renderer.trackedContent, err = prepareNewsletterTrackedLinks(renderer.contentHtml)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (renderer *newsletterEmailRenderer) render(recipient newsletterRecipient) (email emails.JobSendEmail, err error) {
+	newsletter := renderer.newsletter
+
+	subject := newsletter.Subject
+	if recipient.Variant != nil {
+		subject = newsletter.ABTest.Variants[*recipient.Variant].Subject
+	}
+	emailSubject := subject
+	if renderer.test {
+		emailSubject = "[Test] " + subject
+	}
+
+	// contacts are only tied to the opens and clicks if explicitly enabled by the website
+	var trackingContactID *guid.GUID
+	if renderer.trackContacts {
+		trackingContactID = recipient.ContactID
+	}
+
+	contentHtml := renderer.contentHtml
+	if renderer.trackClicks {
+		contentHtml = renderer.trackedContent.render(func(link string) string {
+			return generateNewsletterClickUrl(renderer.trackingBaseUrl, newsletter.TrackingKey, newsletter.ID,
+				recipient.TrackingID, trackingContactID, recipient.Variant, link)
+		})
+	}
+
+	emailData := renderer.websiteEmailData
+	emailData.Subject = subject
+	emailData.Content = template.HTML(contentHtml)
+	emailData.UnsubscribeLink = template.URL(recipient.UnsubscribeLink)
+	if renderer.trackOpens {
+		emailData.OpenTrackingPixel = template.URL(generateNewsletterOpenUrl(renderer.trackingBaseUrl, newsletter.TrackingKey,
+			newsletter.ID, recipient.TrackingID, trackingContactID, recipient.Variant))
+	}
+	emailBody, err := renderer.template.render(emailData)
+	if err != nil {
+		err = fmt.Errorf("executing email template: %w", err)
+		return
+	}
+
+	email = emails.JobSendEmail{
+		Type:        emails.EmailTypeBroadcast,
+		FromAddress: renderer.from.Address,
+		FromName:    renderer.from.Name,
+		ToAddress:   recipient.Email,
+		ToName:      recipient.Name,
+		Subject:     emailSubject,
+		// the plain text alternative is generated by JobSendEmail
+		BodyHtml:              emailBody,
+		Headers:               unsubscribeHeaders(recipient.UnsubscribeLink, !renderer.test),
+		WebsiteID:             &renderer.websiteID,
+		ContactID:             recipient.ContactID,
+		NewsletterID:          &newsletter.ID,
+		OrganizationID:        nil,
+		NewsletterRecipientID: recipient.NewsletterRecipientID,
+	}
+	return
+}
+
+// unsubscribeHeaders returns the List-Unsubscribe headers of the emails sent to the contacts. With oneClick,
+// email clients can unsubscribe the contacts by POSTing to the link, as defined by RFC 8058.
+// See here for more information: https://mailtrap.io/blog/list-unsubscribe-header
+// https://sendgrid.com/blog/list-unsubscribe
+// https://www.rfc-editor.org/rfc/rfc8058
+func unsubscribeHeaders(unsubscribeLink string, oneClick bool) map[string][]string {
+	// TODO: mailto:
+	headers := map[string][]string{
+		"List-Unsubscribe": {"<" + unsubscribeLink + ">"},
+	}
+	if oneClick {
+		headers["List-Unsubscribe-Post"] = []string{"List-Unsubscribe=One-Click"}
+	}
+	return headers
+}
+
+// newsletterSendLimits are the limits which apply to the next batch of emails of a newsletter.
+// The Sent counts include the emails of all the newsletters, already scheduled during the last
+// NewsletterSendInterval.
+type newsletterSendLimits struct {
+	WebsiteSendRate       int64
+	WebsiteSent           int64
+	OrganizationSendRate  int64
+	OrganizationSent      int64
+	WarmupDailyLimit      *int64
+	SentDuringLast24Hours int64
+}
+
+// newsletterBatch returns the number of emails which can be scheduled for the next NewsletterSendInterval,
+// and the rate (emails / s) at which they should be sent
+func newsletterBatch(limits newsletterSendLimits) (size int64, rate int64) {
+	rate = max(min(limits.WebsiteSendRate, limits.OrganizationSendRate), 1)
+	intervalSeconds := int64(emails.NewsletterSendInterval / time.Second)
+
+	size = min(
+		limits.WebsiteSendRate*intervalSeconds-limits.WebsiteSent,
+		limits.OrganizationSendRate*intervalSeconds-limits.OrganizationSent,
+	)
+	if limits.WarmupDailyLimit != nil {
+		size = min(size, *limits.WarmupDailyLimit-limits.SentDuringLast24Hours)
+	}
+
+	return max(size, 0), rate
+}
+
+// newsletterWarmupDailyLimit returns the maximum number of emails of newsletters that the website can
+// send per day, or nil if the warmup of its sending domain is completed.
+func newsletterWarmupDailyLimit(config emails.WebsiteConfiguration, now time.Time) *int64 {
+	// the warmup starts when the domain is verified
+	verifiedAt := now
+	if config.DomainVerifiedAt != nil {
+		verifiedAt = *config.DomainVerifiedAt
+	}
+
+	day := max(int(now.Sub(verifiedAt)/(24*time.Hour)), 0)
+	if day >= len(emails.NewsletterWarmupSchedule) {
+		return nil
+	}
+
+	return new(emails.NewsletterWarmupSchedule[day])
+}
+
+// fillNewsletterProgress sets the progress of the sending of the newsletter. The progress is not available
+// for the newsletters which are not sent, or which were sent before the recipients were recorded.
+func (service *EmailsService) fillNewsletterProgress(ctx context.Context, newsletter *emails.Newsletter) (err error) {
+	if newsletter.SentAt == nil {
+		return nil
+	}
+
+	progress, err := service.repo.GetNewsletterProgress(ctx, service.db, newsletter.ID)
+	if err != nil {
+		return
+	}
+
+	if progress.Sent+progress.Remaining+progress.Failed != 0 {
+		newsletter.Progress = &progress
+	}
+	return nil
+}
+
+// newsletterIsBeingSent returns true if some emails of the newsletter remain to be sent, including the
+// winner of an A/B test in progress
+func newsletterIsBeingSent(newsletter emails.Newsletter) bool {
+	if newsletter.SentAt == nil {
+		return false
+	}
+
+	abTestInProgress := newsletter.ABTest != nil && newsletter.ABTest.Winner == nil
+	return abTestInProgress || (newsletter.Progress != nil && newsletter.Progress.Remaining != 0)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"markdown.ninja/pkg/services/emails"
+)
+
+func TestNewsletterBatch(t *testing.T) {
+	intervalSeconds := int64(emails.NewsletterSendInterval / time.Second)
+
+	tests := []struct {
+		name         string
+		limits       newsletterSendLimits
+		expectedSize int64
+		expectedRate int64
+	}{
+		{
+			name:         "website rate",
+			limits:       newsletterSendLimits{WebsiteSendRate: 5, OrganizationSendRate: 10},
+			expectedSize: 5 * intervalSeconds,
+			expectedRate: 5,
+		},
+		{
+			name:         "organization rate",
+			limits:       newsletterSendLimits{WebsiteSendRate: 50, OrganizationSendRate: 10},
+			expectedSize: 10 * intervalSeconds,
+			expectedRate: 10,
+		},
+		{
+			// the other websites of the organization are sending newsletters
+			name:         "organization emails already scheduled",
+			limits:       newsletterSendLimits{WebsiteSendRate: 5, OrganizationSendRate: 10, OrganizationSent: 10*intervalSeconds - 20},
+			expectedSize: 20,
+			expectedRate: 5,
+		},
+		{
+			name:         "website emails already scheduled",
+			limits:       newsletterSendLimits{WebsiteSendRate: 5, OrganizationSendRate: 10, WebsiteSent: 6 * intervalSeconds},
+			expectedSize: 0,
+			expectedRate: 5,
+		},
+		{
+			name: "warmup",
+			limits: newsletterSendLimits{WebsiteSendRate: 5, OrganizationSendRate: 10, WarmupDailyLimit: new(int64(200)),
+				SentDuringLast24Hours: 150},
+			expectedSize: 50,
+			expectedRate: 5,
+		},
+		{
+			name: "warmup limit reached",
+			limits: newsletterSendLimits{WebsiteSendRate: 5, OrganizationSendRate: 10, WarmupDailyLimit: new(int64(200)),
+				SentDuringLast24Hours: 200},
+			expectedSize: 0,
+			expectedRate: 5,
+		},
+	}
+
+	for _, test := range tests {
+		size, rate := newsletterBatch(test.limits)
+		if size != test.expectedSize || rate != test.expectedRate {
+			t.Errorf("%s: expected (%d, %d), got (%d, %d)", test.name, test.expectedSize, test.expectedRate, size, rate)
+		}
+	}
+}
+
+func TestNewsletterWarmupDailyLimit(t *testing.T) {
+	now := time.Now().UTC()
+
+	// domains which are not verified yet start the warmup
+	limit := newsletterWarmupDailyLimit(emails.WebsiteConfiguration{}, now)
+	if limit == nil || *limit != emails.NewsletterWarmupSchedule[0] {
+		t.Errorf("not verified: expected %d, got %v", emails.NewsletterWarmupSchedule[0], limit)
+	}
+
+	for day, expected := range emails.NewsletterWarmupSchedule {
+		verifiedAt := now.Add(-time.Duration(day)*24*time.Hour - time.Hour)
+		limit = newsletterWarmupDailyLimit(emails.WebsiteConfiguration{DomainVerifiedAt: &verifiedAt}, now)
+		if limit == nil || *limit != expected {
+			t.Errorf("day %d: expected %d, got %v", day, expected, limit)
+		}
+	}
+
+	verifiedAt := now.Add(-time.Duration(len(emails.NewsletterWarmupSchedule)) * 24 * time.Hour)
+	limit = newsletterWarmupDailyLimit(emails.WebsiteConfiguration{DomainVerifiedAt: &verifiedAt}, now)
+	if limit != nil {
+		t.Errorf("warmed up: expected no limit, got %d", *limit)
+	}
+}
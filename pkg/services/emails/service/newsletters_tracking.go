@@ -0,0 +1,349 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/httpx"
+	"golang.org/x/net/html"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/server/apiutil"
+	"markdown.ninja/pkg/server/cachecontrol"
+	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/events"
+)
+
+const (
+	newsletterTrackingKeySize         = 32
+	newsletterTrackedLinkPlaceholder  = "mdninja-tracked-link-%d-"
+	newsletterTrackingSignatureClick  = "click"
+	newsletterTrackingSignatureOpen   = "open"
+	newsletterTrackingCacheKeyPrefix  = "NewsletterTracking:"
+	newsletterTrackingCacheTTL        = 10 * time.Minute
+	newsletterTrackingQueryNewsletter = "n"
+	newsletterTrackingQueryRecipient  = "r"
+	newsletterTrackingQueryContact    = "c"
+	newsletterTrackingQueryUrl        = "u"
+	newsletterTrackingQueryVariant    = "v"
+	newsletterTrackingQuerySignature  = "s"
+)
+
+// 1x1 transparent GIF
+var newsletterOpenTrackingPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// newsletterTracking contains what is needed to verify the tracking links of a sent newsletter
+type newsletterTracking struct {
+	WebsiteID guid.GUID
+	Key       []byte
+}
+
+// newsletterTrackedContent is the HTML content of a newsletter where the links to track have been
+// replaced by placeholders, so that we only parse the HTML once and not once per recipient.
+type newsletterTrackedContent struct {
+	html  string
+	links []string
+}
+
+func generateNewsletterTrackingKey() ([]byte, error) {
+	key := make([]byte, newsletterTrackingKeySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("emails: generating newsletter tracking key: %w", err)
+	}
+	return key, nil
+}
+
+// variant is only signed when not empty so the links of the newsletters sent before A/B tests were
+// introduced remain valid
+func signNewsletterTracking(key []byte, kind string, newsletterID, recipientID, contactID, link, variant string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(kind + "\n" + newsletterID + "\n" + recipientID + "\n" + contactID + "\n" + link))
+	if variant != "" {
+		mac.Write([]byte("\n" + variant))
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func verifyNewsletterTrackingSignature(key []byte, signature, kind, newsletterID, recipientID, contactID, link, variant string) bool {
+	expected := signNewsletterTracking(key, kind, newsletterID, recipientID, contactID, link, variant)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func (service *EmailsService) newsletterTrackingBaseUrl(primaryDomain string) string {
+	return service.httpConfig.WebsitesBaseUrl.Scheme + "://" + primaryDomain + service.httpConfig.WebsitesPort
+}
+
+func generateNewsletterClickUrl(baseUrl string, key []byte, newsletterID, recipientID guid.GUID, contactID *guid.GUID, variant *int64, link string) string {
+	contactIDStr := ""
+	if contactID != nil {
+		contactIDStr = contactID.String()
+	}
+	variantStr := ""
+	if variant != nil {
+		variantStr = strconv.FormatInt(*variant, 10)
+	}
+
+	query := url.Values{}
+	query.Set(newsletterTrackingQueryNewsletter, newsletterID.String())
+	query.Set(newsletterTrackingQueryRecipient, recipientID.String())
+	if contactIDStr != "" {
+		query.Set(newsletterTrackingQueryContact, contactIDStr)
+	}
+	if variantStr != "" {
+		query.Set(newsletterTrackingQueryVariant, variantStr)
+	}
+	query.Set(newsletterTrackingQueryUrl, link)
+	query.Set(newsletterTrackingQuerySignature, signNewsletterTracking(key, newsletterTrackingSignatureClick,
+		newsletterID.String(), recipientID.String(), contactIDStr, link, variantStr))
+
+	return baseUrl + emails.NewsletterClickPath + "?" + query.Encode()
+}
+
+func generateNewsletterOpenUrl(baseUrl string, key []byte, newsletterID, recipientID guid.GUID, contactID *guid.GUID, variant *int64) string {
+	contactIDStr := ""
+	if contactID != nil {
+		contactIDStr = contactID.String()
+	}
+	variantStr := ""
+	if variant != nil {
+		variantStr = strconv.FormatInt(*variant, 10)
+	}
+
+	query := url.Values{}
+	query.Set(newsletterTrackingQueryNewsletter, newsletterID.String())
+	query.Set(newsletterTrackingQueryRecipient, recipientID.String())
+	if contactIDStr != "" {
+		query.Set(newsletterTrackingQueryContact, contactIDStr)
+	}
+	if variantStr != "" {
+		query.Set(newsletterTrackingQueryVariant, variantStr)
+	}
+	query.Set(newsletterTrackingQuerySignature, signNewsletterTracking(key, newsletterTrackingSignatureOpen,
+		newsletterID.String(), recipientID.String(), contactIDStr, "", variantStr))
+
+	return baseUrl + emails.NewsletterOpenPath + "?" + query.Encode()
+}
+
+// prepareNewsletterTrackedLinks replaces the http(s) links of the newsletter's HTML by placeholders.
+func prepareNewsletterTrackedLinks(contentHtml string) (content newsletterTrackedContent, err error) {
+	content.links = []string{}
+
+	var replaceLinks func(node *html.Node)
+	replaceLinks = func(node *html.Node) {
+		if node.Type == html.ElementNode && node.Data == "a" {
+			for i := range node.Attr {
+				if node.Attr[i].Key != "href" || !isTrackableNewsletterLink(node.Attr[i].Val) {
+					continue
+				}
+				content.links = append(content.links, node.Attr[i].Val)
+				node.Attr[i].Val = fmt.Sprintf(newsletterTrackedLinkPlaceholder, len(content.links)-1)
+			}
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			replaceLinks(child)
+		}
+	}
+
+	htmlNodes, err := html.Parse(strings.NewReader(contentHtml))
+	if err != nil {
+		err = fmt.Errorf("emails: parsing newsletter HTML: %w", err)
+		return
+	}
+
+	replaceLinks(htmlNodes)
+
+	htmlOut := bytes.NewBuffer(make([]byte, 0, len(contentHtml)))
+
+	// the parser wraps the fragment in <html><head></head><body>, so we only render the children of <body>
+	var renderHtml func(node *html.Node) error
+	renderHtml = func(node *html.Node) error {
+		if node.Type == html.ElementNode && node.Data == "body" {
+			for child := node.FirstChild; child != nil; child = child.NextSibling {
+				if err := html.Render(htmlOut, child); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if err := renderHtml(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = renderHtml(htmlNodes)
+	if err != nil {
+		err = fmt.Errorf("emails: rendering newsletter HTML: %w", err)
+		return
+	}
+
+	content.html = htmlOut.String()
+	return
+}
+
+// render returns the HTML of the newsletter with the tracked links of the given recipient
+func (content newsletterTrackedContent) render(generateUrl func(link string) string) string {
+	if len(content.links) == 0 {
+		return content.html
+	}
+
+	// placeholders end with '-' so "...-1-" can't match the prefix of "...-10-"
+	oldnew := make([]string, 0, len(content.links)*2)
+	for i := range content.links {
+		oldnew = append(oldnew, fmt.Sprintf(newsletterTrackedLinkPlaceholder, i), html.EscapeString(generateUrl(content.links[i])))
+	}
+	return strings.NewReplacer(oldnew...).Replace(content.html)
+}
+
+func isTrackableNewsletterLink(link string) bool {
+	parsedUrl, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	return (parsedUrl.Scheme == "http" || parsedUrl.Scheme == "https") && parsedUrl.Host != ""
+}
+
+func (service *EmailsService) findNewsletterTracking(ctx context.Context, newsletterID guid.GUID) (tracking newsletterTracking, err error) {
+	cacheKey := newsletterTrackingCacheKeyPrefix + newsletterID.String()
+	if cacheRes := service.sendEmailCache.Get(cacheKey); cacheRes != nil {
+		return cacheRes.Value().(newsletterTracking), nil
+	}
+
+	// many recipients may open a newsletter at the same time so we avoid the thundering herd problem
+	singleflightRes, err, _ := service.sendEmailSingleflightGroup.Do(cacheKey, func() (any, error) {
+		newsletter, err := service.repo.FindNewsletterByID(ctx, service.db, newsletterID)
+		if err != nil {
+			return nil, err
+		}
+
+		tracking := newsletterTracking{
+			WebsiteID: newsletter.WebsiteID,
+			Key:       newsletter.TrackingKey,
+		}
+		service.sendEmailCache.Set(cacheKey, tracking, newsletterTrackingCacheTTL)
+		return tracking, nil
+	})
+	if err != nil {
+		return
+	}
+
+	tracking = singleflightRes.(newsletterTracking)
+	return
+}
+
+// verifyNewsletterTrackingRequest returns the verified IDs of a tracking request. ok is false if the
+// request is not valid.
+func (service *EmailsService) verifyNewsletterTrackingRequest(ctx context.Context, query url.Values, kind, link string) (tracking newsletterTracking, newsletterID, recipientID guid.GUID, contactID *guid.GUID, variant *int64, ok bool) {
+	newsletterID, err := guid.Parse(query.Get(newsletterTrackingQueryNewsletter))
+	if err != nil {
+		return
+	}
+
+	recipientID, err = guid.Parse(query.Get(newsletterTrackingQueryRecipient))
+	if err != nil {
+		return
+	}
+
+	contactIDStr := query.Get(newsletterTrackingQueryContact)
+	if contactIDStr != "" {
+		var parsedContactID guid.GUID
+		parsedContactID, err = guid.Parse(contactIDStr)
+		if err != nil {
+			return
+		}
+		contactID = &parsedContactID
+	}
+
+	variantStr := query.Get(newsletterTrackingQueryVariant)
+	if variantStr != "" {
+		var parsedVariant int64
+		parsedVariant, err = strconv.ParseInt(variantStr, 10, 64)
+		if err != nil {
+			return
+		}
+		variant = &parsedVariant
+	}
+
+	tracking, err = service.findNewsletterTracking(ctx, newsletterID)
+	if err != nil || len(tracking.Key) == 0 {
+		return
+	}
+
+	ok = verifyNewsletterTrackingSignature(tracking.Key, query.Get(newsletterTrackingQuerySignature), kind,
+		query.Get(newsletterTrackingQueryNewsletter), query.Get(newsletterTrackingQueryRecipient), contactIDStr, link, variantStr)
+	return
+}
+
+// ServeNewsletterClick records the click on a link of a newsletter and redirects to the link
+func (service *EmailsService) ServeNewsletterClick(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	query := req.URL.Query()
+
+	link := query.Get(newsletterTrackingQueryUrl)
+	if !isTrackableNewsletterLink(link) {
+		apiutil.SendError(ctx, res, errs.NotFound("Link not found"))
+		return
+	}
+
+	// we never redirect to a link that has not been signed to avoid open redirects
+	tracking, newsletterID, recipientID, contactID, variant, ok := service.verifyNewsletterTrackingRequest(ctx, query,
+		newsletterTrackingSignatureClick, link)
+	if !ok {
+		apiutil.SendError(ctx, res, errs.NotFound("Link not found"))
+		return
+	}
+
+	service.eventsService.TrackNewsletterLinkClicked(ctx, events.TrackNewsletterLinkClickedInput{
+		RecipientID:  recipientID,
+		ContactID:    contactID,
+		Variant:      variant,
+		WebsiteID:    tracking.WebsiteID,
+		NewsletterID: newsletterID,
+		Url:          link,
+	})
+
+	res.Header().Set(httpx.HeaderCacheControl, cachecontrol.NoCache)
+	http.Redirect(res, req, link, http.StatusFound)
+}
+
+// ServeNewsletterOpen records the opening of a newsletter. The pixel is always served, even if
+// the request is not valid.
+func (service *EmailsService) ServeNewsletterOpen(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	tracking, newsletterID, recipientID, contactID, variant, ok := service.verifyNewsletterTrackingRequest(ctx, req.URL.Query(),
+		newsletterTrackingSignatureOpen, "")
+	if ok {
+		service.eventsService.TrackNewsletterOpened(ctx, events.TrackNewsletterOpenedInput{
+			RecipientID:  recipientID,
+			ContactID:    contactID,
+			Variant:      variant,
+			WebsiteID:    tracking.WebsiteID,
+			NewsletterID: newsletterID,
+		})
+	}
+
+	res.Header().Set(httpx.HeaderCacheControl, cachecontrol.NoCache)
+	res.Header().Set(httpx.HeaderContentType, "image/gif")
+	res.Header().Set(httpx.HeaderContentLength, strconv.Itoa(len(newsletterOpenTrackingPixel)))
+	res.WriteHeader(http.StatusOK)
+	res.Write(newsletterOpenTrackingPixel)
+}
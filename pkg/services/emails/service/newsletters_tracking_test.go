@@ -0,0 +1,82 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/skerkour/stdx-go/guid"
+)
+
+func TestPrepareNewsletterTrackedLinks(t *testing.T) {
+	contentHtml := `<p><a href="https://example.com/a?b=c&amp;d=e">a</a> <a href="mailto:hello@example.com">mail</a> <a href="#top">top</a></p>`
+
+	content, err := prepareNewsletterTrackedLinks(contentHtml)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(content.links) != 1 || content.links[0] != "https://example.com/a?b=c&d=e" {
+		t.Fatalf("unexpected tracked links: %v", content.links)
+	}
+
+	rendered := content.render(func(link string) string {
+		return "https://tracked.example.com/?u=" + url.QueryEscape(link) + "&x=y"
+	})
+	if !strings.Contains(rendered, `href="https://tracked.example.com/?u=https%3A%2F%2Fexample.com%2Fa%3Fb%3Dc%26d%3De&amp;x=y"`) {
+		t.Errorf("tracked link not rendered: %s", rendered)
+	}
+	if !strings.Contains(rendered, `href="mailto:hello@example.com"`) || !strings.Contains(rendered, `href="#top"`) {
+		t.Errorf("non http(s) links should not be tracked: %s", rendered)
+	}
+}
+
+func TestNewsletterTrackingSignature(t *testing.T) {
+	key, err := generateNewsletterTrackingKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newsletterID := guid.NewTimeBased()
+	recipientID := guid.NewRandom()
+	link := "https://example.com"
+
+	clickUrl, err := url.Parse(generateNewsletterClickUrl("https://example.com", key, newsletterID, recipientID, nil, nil, link))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := clickUrl.Query()
+	if query.Has(newsletterTrackingQueryContact) {
+		t.Error("contact ID should not be in the URL")
+	}
+
+	signature := query.Get(newsletterTrackingQuerySignature)
+	if !verifyNewsletterTrackingSignature(key, signature, newsletterTrackingSignatureClick,
+		newsletterID.String(), recipientID.String(), "", link, "") {
+		t.Error("valid signature rejected")
+	}
+	if verifyNewsletterTrackingSignature(key, signature, newsletterTrackingSignatureClick,
+		newsletterID.String(), recipientID.String(), "", "https://evil.example.com", "") {
+		t.Error("signature accepted for another URL")
+	}
+	// a click signature can't be used as an open signature
+	if verifyNewsletterTrackingSignature(key, signature, newsletterTrackingSignatureOpen,
+		newsletterID.String(), recipientID.String(), "", "", "") {
+		t.Error("click signature accepted for an open")
+	}
+	// the variant of an A/B test can't be tampered with
+	variant := int64(1)
+	openUrl, err := url.Parse(generateNewsletterOpenUrl("https://example.com", key, newsletterID, recipientID, nil, &variant))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query = openUrl.Query()
+	signature = query.Get(newsletterTrackingQuerySignature)
+	if !verifyNewsletterTrackingSignature(key, signature, newsletterTrackingSignatureOpen,
+		newsletterID.String(), recipientID.String(), "", "", query.Get(newsletterTrackingQueryVariant)) {
+		t.Error("valid signature with variant rejected")
+	}
+	if verifyNewsletterTrackingSignature(key, signature, newsletterTrackingSignatureOpen,
+		newsletterID.String(), recipientID.String(), "", "", "0") {
+		t.Error("signature accepted for another variant")
+	}
+}
@@ -1,10 +1,15 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/markdown"
+	"markdown.ninja/pkg/services/content"
 	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/websites"
 )
 
 func convertNewsletterMetadata(input []emails.Newsletter) (output []emails.NewsletterMetadata) {
@@ -21,6 +26,12 @@ func convertNewsletterMetadata(input []emails.Newsletter) (output []emails.Newsl
 			Hash:           item.Hash,
 			SentAt:         item.SentAt,
 			LastTestSentAt: item.LastTestSentAt,
+			MembersOnly:    item.MembersOnly,
+			ProductUpdates: item.ProductUpdates,
+			Digest:         item.Digest,
+			ABTest:         item.ABTest,
+
+			SendingPausedAt: item.SendingPausedAt,
 		}
 	}
 
@@ -34,3 +45,34 @@ func getWebsiteConfigurationCacheKey(websiteID guid.GUID) string {
 func getSenderApiTokenCacheKey(websiteID guid.GUID) string {
 	return fmt.Sprintf("SenderApiToken:%s", websiteID.String())
 }
+
+func getTestEmailTemplateCacheKey(websiteID guid.GUID) string {
+	return fmt.Sprintf("TestEmailTemplate:%s", websiteID.String())
+}
+
+// renderEmailContentHtml converts the markdown body of a newsletter or a sequence email to HTML
+func (service *EmailsService) renderEmailContentHtml(ctx context.Context, website websites.Website, bodyMarkdown string) (contentHtml string, err error) {
+	contentHtml, err = markdown.ToHtmlEmail(
+		service.httpConfig.WebsitesBaseUrl.Scheme+"://"+website.PrimaryDomain+service.httpConfig.WebsitesPort,
+		bodyMarkdown,
+	)
+	if err != nil {
+		err = fmt.Errorf("error converting markdown to HTML: %w", err)
+		return
+	}
+
+	// TODO: do we really want to render all the snippets?
+	if strings.Contains(contentHtml, "{{<") {
+		var snippets []content.Snippet
+		snippets, err = service.contentService.FindSnippets(ctx, service.db, website.ID)
+		if err != nil {
+			err = fmt.Errorf("finding snippets: %w", err)
+			return
+		}
+		if len(snippets) != 0 {
+			contentHtml = service.contentService.RenderSnippets(contentHtml, snippets, true)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"markdown.ninja/pkg/services/emails"
+)
+
+// PauseNewsletter stops scheduling the emails of a newsletter being sent. The emails already scheduled
+// during the current NewsletterSendInterval are still sent.
+func (service *EmailsService) PauseNewsletter(ctx context.Context, input emails.PauseNewsletterInput) (newsletter emails.Newsletter, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	newsletter, err = service.repo.FindNewsletterByID(ctx, service.db, input.ID)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, newsletter.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	if newsletter.SendingPausedAt != nil {
+		err = emails.ErrNewsletterSendingIsPaused
+		return
+	}
+
+	err = service.fillNewsletterProgress(ctx, &newsletter)
+	if err != nil {
+		return
+	}
+
+	if !newsletterIsBeingSent(newsletter) {
+		err = emails.ErrNewsletterIsNotBeingSent
+		return
+	}
+
+	now := time.Now().UTC()
+	newsletter.SendingPausedAt = &now
+	newsletter.UpdatedAt = now
+	err = service.repo.UpdateNewsletterSendingPausedAt(ctx, service.db, newsletter.ID, newsletter.SendingPausedAt, now)
+	if err != nil {
+		return
+	}
+
+	return
+}
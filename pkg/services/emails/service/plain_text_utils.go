@@ -0,0 +1,176 @@
+package service
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// htmlToText generates the plain text alternative of an HTML email. Links are written as
+// "text (url)", and the content of <head>, <style> and <script> is skipped.
+func htmlToText(htmlInput string) string {
+	document, err := html.Parse(strings.NewReader(htmlInput))
+	if err != nil {
+		return ""
+	}
+
+	converter := htmlToTextConverter{}
+	converter.convert(document)
+	return converter.text()
+}
+
+type htmlToTextConverter struct {
+	builder strings.Builder
+	inPre   int
+}
+
+func (converter *htmlToTextConverter) convert(node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		converter.writeText(node.Data)
+		return
+	case html.CommentNode:
+		return
+	case html.ElementNode:
+	default:
+		converter.convertChildren(node)
+		return
+	}
+
+	switch node.Data {
+	case "head", "style", "script", "title":
+		return
+	case "br":
+		converter.builder.WriteString("\n")
+		return
+	case "hr":
+		converter.newlines(2)
+		converter.builder.WriteString("---")
+		converter.newlines(2)
+		return
+	case "img":
+		converter.writeText(htmlAttribute(node, "alt"))
+		return
+	case "a":
+		start := converter.builder.Len()
+		converter.convertChildren(node)
+		linkText := strings.TrimSpace(converter.builder.String()[start:])
+		href := strings.TrimSpace(htmlAttribute(node, "href"))
+		if href != "" && href != linkText && !strings.HasPrefix(href, "mailto:") {
+			if linkText == "" {
+				converter.writeText(href)
+			} else {
+				converter.builder.WriteString(" (" + href + ")")
+			}
+		}
+		return
+	case "li":
+		converter.newlines(1)
+		converter.builder.WriteString("- ")
+		converter.convertChildren(node)
+		converter.newlines(1)
+		return
+	case "pre":
+		converter.newlines(2)
+		converter.inPre += 1
+		converter.convertChildren(node)
+		converter.inPre -= 1
+		converter.newlines(2)
+		return
+	case "p", "h1", "h2", "h3", "h4", "h5", "h6", "table", "ul", "ol", "blockquote":
+		converter.newlines(2)
+		converter.convertChildren(node)
+		converter.newlines(2)
+		return
+	case "div", "tr":
+		converter.newlines(1)
+		converter.convertChildren(node)
+		converter.newlines(1)
+		return
+	case "td", "th":
+		converter.convertChildren(node)
+		converter.writeText(" ")
+		return
+	}
+
+	converter.convertChildren(node)
+}
+
+func (converter *htmlToTextConverter) convertChildren(node *html.Node) {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		converter.convert(child)
+	}
+}
+
+func (converter *htmlToTextConverter) writeText(text string) {
+	if converter.inPre > 0 {
+		converter.builder.WriteString(text)
+		return
+	}
+
+	// whitespace is collapsed like browsers do
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		if text != "" {
+			converter.space()
+		}
+		return
+	}
+	if strings.TrimLeft(text, " \t\r\n") != text {
+		converter.space()
+	}
+	converter.builder.WriteString(strings.Join(words, " "))
+	if strings.TrimRight(text, " \t\r\n") != text {
+		converter.space()
+	}
+}
+
+func (converter *htmlToTextConverter) space() {
+	current := converter.builder.String()
+	if current != "" && !strings.HasSuffix(current, " ") && !strings.HasSuffix(current, "\n") {
+		converter.builder.WriteString(" ")
+	}
+}
+
+// newlines ensures that the text ends with at least count line breaks
+func (converter *htmlToTextConverter) newlines(count int) {
+	current := converter.builder.String()
+	if strings.TrimSpace(current) == "" {
+		return
+	}
+
+	existing := len(current) - len(strings.TrimRight(current, "\n"))
+	for range count - existing {
+		converter.builder.WriteString("\n")
+	}
+}
+
+func (converter *htmlToTextConverter) text() string {
+	lines := strings.Split(converter.builder.String(), "\n")
+	output := make([]string, 0, len(lines))
+	emptyLines := 0
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			emptyLines += 1
+			// at most 1 empty line between paragraphs
+			if emptyLines > 1 {
+				continue
+			}
+		} else {
+			emptyLines = 0
+		}
+		output = append(output, line)
+	}
+
+	return strings.TrimSpace(strings.Join(output, "\n"))
+}
+
+func htmlAttribute(node *html.Node, key string) string {
+	for _, attribute := range node.Attr {
+		if attribute.Key == key {
+			return attribute.Val
+		}
+	}
+	return ""
+}
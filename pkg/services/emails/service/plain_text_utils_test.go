@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestHtmlToText(t *testing.T) {
+	tests := []struct {
+		html     string
+		expected string
+	}{
+		{
+			html:     `<html><head><title>Title</title><style>p { color: red; }</style></head><body><p>Hello   <b>World</b></p><p>Second paragraph</p></body></html>`,
+			expected: "Hello World\n\nSecond paragraph",
+		},
+		{
+			html:     `<p>Read <a href="https://example.com/post">my post</a> or <a href="https://example.com">https://example.com</a></p>`,
+			expected: "Read my post (https://example.com/post) or https://example.com",
+		},
+		{
+			html:     `<ul><li>One</li><li>Two</li></ul><p>Line<br>break</p>`,
+			expected: "- One\n- Two\n\nLine\nbreak",
+		},
+		{
+			html: `<div>Code:</div><pre>func main() {
+	return
+}</pre>`,
+			expected: "Code:\n\nfunc main() {\n\treturn\n}",
+		},
+		{
+			html:     `<div><!--[if mso]><table><tr><td>outlook</td></tr></table><![endif]--><img src="https://example.com/pixel" alt="" />Content</div>`,
+			expected: "Content",
+		},
+	}
+
+	for _, test := range tests {
+		text := htmlToText(test.html)
+		if text != test.expected {
+			t.Errorf("htmlToText(%q): expected %q, got %q", test.html, test.expected, text)
+		}
+	}
+}
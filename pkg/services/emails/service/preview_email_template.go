@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/services/emails"
+)
+
+func (service *EmailsService) PreviewEmailTemplate(ctx context.Context, input emails.PreviewEmailTemplateInput) (preview emails.RenderedEmail, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	preview, err = service.renderEmailTemplatePreview(ctx, input.WebsiteID, input.Type, input.Body)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/skerkour/stdx-go/db"
+	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/websites"
+)
+
+func (service *EmailsService) RenderWebsiteEmail(ctx context.Context, db db.Queryer, website websites.Website, templateType emails.EmailTemplateType, data emails.EmailTemplateData) (email emails.RenderedEmail, err error) {
+	err = validateEmailTemplateType(templateType)
+	if err != nil {
+		return
+	}
+
+	emailConfig, err := service.repo.FindWebsiteConfiguration(ctx, db, website.ID)
+	if err != nil {
+		return
+	}
+
+	emailTemplate, err := service.findEmailTemplate(ctx, db, website.ID, templateType)
+	if err != nil {
+		return
+	}
+
+	service.fillEmailTemplateData(&data, website, emailConfig)
+	email.Subject = data.Subject
+	email.Html, err = emailTemplate.render(data)
+	if err != nil {
+		err = fmt.Errorf("emails.RenderWebsiteEmail: executing %s template: %w", templateType, err)
+		return
+	}
+	email.Text = htmlToText(email.Html)
+
+	return
+}
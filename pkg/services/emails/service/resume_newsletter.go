@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"markdown.ninja/pkg/services/emails"
+)
+
+// ResumeNewsletter resumes the sending of a paused newsletter. The remaining emails are scheduled the next
+// time TaskSendNewsletterEmails runs.
+func (service *EmailsService) ResumeNewsletter(ctx context.Context, input emails.ResumeNewsletterInput) (newsletter emails.Newsletter, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	newsletter, err = service.repo.FindNewsletterByID(ctx, service.db, input.ID)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, newsletter.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	if newsletter.SendingPausedAt == nil {
+		err = emails.ErrNewsletterSendingIsNotPaused
+		return
+	}
+
+	now := time.Now().UTC()
+	newsletter.SendingPausedAt = nil
+	newsletter.UpdatedAt = now
+	err = service.repo.UpdateNewsletterSendingPausedAt(ctx, service.db, newsletter.ID, nil, now)
+	if err != nil {
+		return
+	}
+
+	err = service.fillNewsletterProgress(ctx, &newsletter)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -52,6 +52,11 @@ func (service *EmailsService) SendNewsletter(ctx context.Context, input emails.S
 	}
 
 	if !input.Test {
+		err = checkABTestTracking(emailConfig, newsletter.ABTest)
+		if err != nil {
+			return
+		}
+
 		err = service.organizationsService.CheckBillingGatedAction(ctx, service.db, website.OrganizationID, organizations.BillingGatedActionSendNewsletter{})
 		if err != nil {
 			return
@@ -67,11 +72,18 @@ func (service *EmailsService) SendNewsletter(ctx context.Context, input emails.S
 		}
 
 		newsletter.LastTestSentAt = &now
+	} else if newsletter.ABTest != nil {
+		startABTest(&newsletter, now)
 	} else {
 		newsletter.SentAt = &now
 		newsletter.ScheduledFor = nil // TODO: or &now?
 	}
 
+	abTestStage := emails.ABTestStageNone
+	if newsletter.ABTest != nil && !input.Test {
+		abTestStage = emails.ABTestStageSample
+	}
+
 	testEmails := make([]string, 0, 1)
 	if input.Test {
 		testEmails = append(testEmails, httpCtx.AccessToken.Email)
@@ -82,6 +94,7 @@ func (service *EmailsService) SendNewsletter(ctx context.Context, input emails.S
 			Test:         input.Test,
 			TestEmails:   testEmails,
 			SentAt:       now,
+			ABTestStage:  abTestStage,
 		},
 		Timeout: new(int64(600)),
 	}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"net/mail"
+	"time"
+
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/queue"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/emails"
+)
+
+// SendTestEmailTemplate sends the preview of the template to the current user. The template doesn't
+// need to be saved.
+func (service *EmailsService) SendTestEmailTemplate(ctx context.Context, input emails.SendTestEmailTemplateInput) (err error) {
+	logger := slogx.FromCtx(ctx)
+	httpCtx := httpctx.FromCtx(ctx)
+
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	rateLimitCacheKey := getTestEmailTemplateCacheKey(input.WebsiteID)
+	if service.sendEmailCache.Get(rateLimitCacheKey) != nil {
+		err = errs.InvalidArgument("Please wait a minute before sending a new test email.")
+		return
+	}
+
+	preview, err := service.renderEmailTemplatePreview(ctx, input.WebsiteID, input.Type, input.Body)
+	if err != nil {
+		return
+	}
+
+	website, err := service.websitesService.FindWebsiteByID(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	emailConfig, err := service.repo.FindWebsiteConfiguration(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	var from mail.Address
+	if emailConfig.DomainVerified {
+		from = mail.Address{
+			Name:    emailConfig.FromName,
+			Address: emailConfig.FromAddress,
+		}
+	} else {
+		from = service.GetDefaultFromAddressForWebsite(website)
+	}
+
+	job := queue.NewJobInput{
+		Data: emails.JobSendEmail{
+			Type:        emails.EmailTypeBroadcast,
+			FromAddress: from.Address,
+			FromName:    from.Name,
+			ToAddress:   httpCtx.AccessToken.Email,
+			ToName:      "",
+			Subject:     "[Test] " + preview.Subject,
+			BodyHtml:    preview.Html,
+			BodyText:    &preview.Text,
+			Headers:     nil,
+			WebsiteID:   &website.ID,
+		},
+	}
+	err = service.queue.Push(ctx, nil, job)
+	if err != nil {
+		errMessage := "emails.SendTestEmailTemplate: Pushing SendEmail job to queue"
+		logger.Error(errMessage, slogx.Err(err))
+		err = errs.Internal(errMessage, err)
+		return
+	}
+
+	service.sendEmailCache.Set(rateLimitCacheKey, true, time.Minute)
+
+	return
+}
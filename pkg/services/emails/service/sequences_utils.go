@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/store"
+)
+
+func validateSequenceName(name string) (err error) {
+	if utf8.RuneCountInString(name) < emails.SequenceNameMinSize ||
+		utf8.RuneCountInString(name) > emails.SequenceNameMaxSize || !utf8.ValidString(name) {
+		return emails.ErrSequenceNameIsNotValid
+	}
+
+	return nil
+}
+
+func validateSequenceTrigger(trigger emails.SequenceTrigger) (err error) {
+	switch trigger {
+	case emails.SequenceTriggerSubscribedToNewsletter, emails.SequenceTriggerProductPurchased:
+		return nil
+	default:
+		return emails.ErrSequenceTriggerIsNotValid
+	}
+}
+
+// validateSequenceProduct checks that the product exists and belongs to the website
+func (service *EmailsService) validateSequenceProduct(ctx context.Context, websiteID guid.GUID, productID *guid.GUID) (err error) {
+	if productID == nil {
+		return nil
+	}
+
+	product, err := service.storeService.FindProduct(ctx, service.db, *productID)
+	if err != nil {
+		return err
+	}
+
+	if !product.WebsiteID.Equal(websiteID) {
+		return store.ErrProductNotFound
+	}
+
+	return nil
+}
+
+// buildSequenceSteps validates the steps of a sequence. The IDs of the existing steps are kept so their
+// stats are not lost when a sequence is edited.
+func (service *EmailsService) buildSequenceSteps(input []emails.SequenceStepInput, existingSteps emails.SequenceSteps) (steps emails.SequenceSteps, err error) {
+	if len(input) > emails.SequenceMaxSteps {
+		err = emails.ErrSequenceTooManySteps
+		return
+	}
+
+	existingStepIDs := make(map[guid.GUID]bool, len(existingSteps))
+	for _, step := range existingSteps {
+		existingStepIDs[step.ID] = true
+	}
+
+	steps = make(emails.SequenceSteps, 0, len(input))
+	for _, stepInput := range input {
+		step := emails.SequenceStep{
+			ID:           guid.NewTimeBased(),
+			Delay:        stepInput.Delay,
+			Subject:      strings.TrimSpace(stepInput.Subject),
+			BodyMarkdown: stepInput.BodyMarkdown,
+		}
+
+		if stepInput.ID != nil {
+			if !existingStepIDs[*stepInput.ID] {
+				err = emails.ErrSequenceStepNotFound
+				return
+			}
+			step.ID = *stepInput.ID
+		}
+
+		if step.Delay < 0 || step.Delay > int64(emails.SequenceStepMaxDelay.Seconds()) {
+			err = emails.ErrSequenceStepDelayIsNotValid
+			return
+		}
+
+		err = service.validateNewsletterSubject(step.Subject)
+		if err != nil {
+			return
+		}
+
+		err = service.validateNewsletterBodyMarkdown(step.BodyMarkdown)
+		if err != nil {
+			return
+		}
+
+		steps = append(steps, step)
+	}
+
+	return
+}
+
+func sequenceStepDelay(step emails.SequenceStep) time.Duration {
+	return time.Duration(step.Delay) * time.Second
+}
+
+func sequenceTriggerMatchesProducts(sequence emails.Sequence, productIDs []guid.GUID) bool {
+	if sequence.TriggerProductID == nil {
+		return true
+	}
+
+	for _, productID := range productIDs {
+		if productID.Equal(*sequence.TriggerProductID) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/emails"
+)
+
+func TestBuildSequenceSteps(t *testing.T) {
+	service := &EmailsService{}
+	existingStep := emails.SequenceStep{ID: guid.NewTimeBased(), Subject: "Welcome"}
+
+	steps, err := service.buildSequenceSteps([]emails.SequenceStepInput{
+		{ID: &existingStep.ID, Delay: 0, Subject: " Welcome! ", BodyMarkdown: "Hello"},
+		{Delay: 86400, Subject: "Day 1", BodyMarkdown: "Hello again"},
+	}, emails.SequenceSteps{existingStep})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !steps[0].ID.Equal(existingStep.ID) {
+		t.Error("the ID of an existing step should be kept")
+	}
+	if steps[0].Subject != "Welcome!" {
+		t.Errorf("subject should be trimmed, got: %q", steps[0].Subject)
+	}
+	if steps[1].ID.Equal(existingStep.ID) || steps[1].ID.Equal(guid.Empty) {
+		t.Error("a new step should get a new ID")
+	}
+
+	_, err = service.buildSequenceSteps([]emails.SequenceStepInput{
+		{ID: new(guid.NewTimeBased()), Subject: "Unknown", BodyMarkdown: ""},
+	}, emails.SequenceSteps{existingStep})
+	if err != emails.ErrSequenceStepNotFound {
+		t.Errorf("expected ErrSequenceStepNotFound, got: %v", err)
+	}
+
+	_, err = service.buildSequenceSteps([]emails.SequenceStepInput{
+		{Delay: -1, Subject: "Negative", BodyMarkdown: ""},
+	}, nil)
+	if err != emails.ErrSequenceStepDelayIsNotValid {
+		t.Errorf("expected ErrSequenceStepDelayIsNotValid, got: %v", err)
+	}
+}
+
+func TestSequenceTriggerMatchesProducts(t *testing.T) {
+	productID := guid.NewTimeBased()
+	otherProductID := guid.NewTimeBased()
+
+	sequence := emails.Sequence{Trigger: emails.SequenceTriggerProductPurchased}
+	if !sequenceTriggerMatchesProducts(sequence, []guid.GUID{otherProductID}) {
+		t.Error("a sequence without product should be triggered by any product")
+	}
+
+	sequence.TriggerProductID = &productID
+	if sequenceTriggerMatchesProducts(sequence, []guid.GUID{otherProductID}) {
+		t.Error("the sequence should not be triggered by another product")
+	}
+	if !sequenceTriggerMatchesProducts(sequence, []guid.GUID{otherProductID, productID}) {
+		t.Error("the sequence should be triggered by its product")
+	}
+}
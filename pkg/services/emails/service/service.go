@@ -1,7 +1,7 @@
 package service
 
 import (
-	"html/template"
+	"fmt"
 	"net"
 	"time"
 
@@ -13,11 +13,12 @@ import (
 	"markdown.ninja/pkg/mailer"
 	"markdown.ninja/pkg/services/contacts"
 	"markdown.ninja/pkg/services/content"
+	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/emails/repository"
-	"markdown.ninja/pkg/services/emails/templates"
 	"markdown.ninja/pkg/services/events"
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/organizations"
+	"markdown.ninja/pkg/services/store"
 	"markdown.ninja/pkg/services/websites"
 )
 
@@ -35,8 +36,9 @@ type EmailsService struct {
 	eventsService        events.Service
 	contentService       content.Service
 	organizationsService organizations.Service
+	storeService         store.Service
 
-	newsletterEmailTemplate    *template.Template
+	defaultEmailTemplates      map[emails.EmailTemplateType]emailTemplate
 	dnsResolver                *net.Resolver
 	sendEmailCache             *memorycache.Cache[string, any]
 	sendEmailSingleflightGroup singleflight.Group
@@ -46,7 +48,10 @@ func NewEmailsService(conf config.Config, db db.DB, queue queue.Queue, mailer ma
 	eventsService events.Service, contentService content.Service,
 	organizationsService organizations.Service) *EmailsService {
 	repo := repository.NewEmailsRepository()
-	newsletterEmailTemplate := template.Must(template.New("emails.newsletterEmailTemplate").Parse(templates.NewsletterEmailTemplate))
+	defaultEmailTemplates, err := parseDefaultEmailTemplates()
+	if err != nil {
+		panic(fmt.Errorf("emails.NewEmailsService: %w", err))
+	}
 
 	sendEmailCache := memorycache.New(
 		memorycache.WithTTL[string, any](time.Minute),
@@ -63,18 +68,20 @@ func NewEmailsService(conf config.Config, db db.DB, queue queue.Queue, mailer ma
 		kernel:               kernel,
 		websitesService:      nil,
 		contactsService:      nil,
+		storeService:         nil,
 		eventsService:        eventsService,
 		contentService:       contentService,
 		organizationsService: organizationsService,
 
-		newsletterEmailTemplate:    newsletterEmailTemplate,
+		defaultEmailTemplates:      defaultEmailTemplates,
 		dnsResolver:                dnsResolver,
 		sendEmailCache:             sendEmailCache,
 		sendEmailSingleflightGroup: singleflight.Group{},
 	}
 }
 
-func (service *EmailsService) InjectServices(websitesService websites.Service, contactsService contacts.Service) {
+func (service *EmailsService) InjectServices(websitesService websites.Service, contactsService contacts.Service, storeService store.Service) {
 	service.websitesService = websitesService
 	service.contactsService = contactsService
+	service.storeService = storeService
 }
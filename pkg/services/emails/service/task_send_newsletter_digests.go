@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"log/slog"
+
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/queue"
+	"markdown.ninja/pkg/services/emails"
+)
+
+// TaskSendNewsletterDigests pushes the jobs to send the digests which are due and schedules the
+// next digests
+func (service *EmailsService) TaskSendNewsletterDigests(ctx context.Context) {
+	logger := slogx.FromCtx(ctx)
+	now := time.Now().UTC()
+
+	tx, err := service.db.Begin(ctx)
+	if err != nil {
+		logger.Error("emails.TaskSendNewsletterDigests: error starting DB transaction", slogx.Err(err))
+		return
+	}
+	defer tx.Rollback()
+
+	// the remaining digests are processed the next time the task runs
+	configurations, err := service.repo.FindDueDigestWebsiteConfigurations(ctx, tx, now, 500)
+	if err != nil {
+		logger.Error("emails.TaskSendNewsletterDigests: error finding due digests", slogx.Err(err))
+		return
+	}
+
+	jobs := make([]queue.NewJobInput, 0, len(configurations))
+	for _, configuration := range configurations {
+		periodStart := now
+		if configuration.DigestPeriodStart != nil {
+			periodStart = *configuration.DigestPeriodStart
+		}
+
+		jobs = append(jobs, queue.NewJobInput{
+			Data: emails.JobSendNewsletterDigest{
+				WebsiteID:   configuration.WebsiteID,
+				PeriodStart: periodStart,
+				PeriodEnd:   now,
+			},
+			Timeout: new(int64(600)),
+		})
+
+		nextSendAt, err := nextDigestSendAt(configuration.DigestFrequency, now)
+		if err != nil {
+			logger.Error("emails.TaskSendNewsletterDigests: error computing next send date", slogx.Err(err),
+				slog.String("website.id", configuration.WebsiteID.String()))
+			return
+		}
+
+		err = service.repo.UpdateWebsiteConfigurationDigestSchedule(ctx, tx, configuration.WebsiteID, &now, &nextSendAt)
+		if err != nil {
+			logger.Error("emails.TaskSendNewsletterDigests: error updating digest schedule", slogx.Err(err),
+				slog.String("website.id", configuration.WebsiteID.String()))
+			return
+		}
+	}
+
+	if len(jobs) == 0 {
+		return
+	}
+
+	err = service.queue.PushMany(ctx, tx, jobs)
+	if err != nil {
+		logger.Error("emails.TaskSendNewsletterDigests: error pushing jobs to queue", slogx.Err(err))
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("emails.TaskSendNewsletterDigests: error committing DB transaction", slogx.Err(err))
+		return
+	}
+}
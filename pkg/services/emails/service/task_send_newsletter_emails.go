@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"log/slog"
+
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/queue"
+	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/organizations"
+)
+
+// TaskSendNewsletterEmails schedules the next batch of emails of the newsletters being sent, according to
+// the send rates of the websites and of their organizations, and to the warmup of the sending domains
+func (service *EmailsService) TaskSendNewsletterEmails(ctx context.Context) {
+	logger := slogx.FromCtx(ctx)
+	now := time.Now().UTC()
+
+	newsletters, err := service.repo.FindSendingNewsletters(ctx, service.db)
+	if err != nil {
+		logger.Error("emails.TaskSendNewsletterEmails: error finding newsletters being sent", slogx.Err(err))
+		return
+	}
+
+	for _, newsletter := range newsletters {
+		err = service.sendNewsletterEmailsBatch(ctx, newsletter, now)
+		if err != nil {
+			logger.Error("emails.TaskSendNewsletterEmails: error sending emails", slogx.Err(err),
+				slog.String("newsletter.id", newsletter.ID.String()))
+			continue
+		}
+	}
+}
+
+func (service *EmailsService) sendNewsletterEmailsBatch(ctx context.Context, newsletter emails.Newsletter, now time.Time) (err error) {
+	logger := slogx.FromCtx(ctx).With(slog.String("newsletter.id", newsletter.ID.String()))
+
+	emailConfig, err := service.repo.FindWebsiteConfiguration(ctx, service.db, newsletter.WebsiteID)
+	if err != nil {
+		return err
+	}
+
+	// TODO: improve error
+	if !emailConfig.DomainVerified {
+		return errors.New("No custom domain configured")
+	}
+
+	website, err := service.websitesService.FindWebsiteByID(ctx, service.db, newsletter.WebsiteID)
+	if err != nil {
+		return err
+	}
+
+	organization, err := service.organizationsService.FindOrganizationByID(ctx, service.db, website.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	if (emailConfig.TrackClicks || emailConfig.TrackOpens) && len(newsletter.TrackingKey) == 0 {
+		newsletter.TrackingKey, err = generateNewsletterTrackingKey()
+		if err != nil {
+			return err
+		}
+		err = service.repo.UpdateNewsletter(ctx, service.db, newsletter)
+		if err != nil {
+			return err
+		}
+		service.sendEmailCache.Delete(newsletterTrackingCacheKeyPrefix + newsletter.ID.String())
+	}
+
+	tx, err := service.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("starting DB transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	limits := newsletterSendLimits{
+		WebsiteSendRate:      emailConfig.SendRate,
+		OrganizationSendRate: organization.EmailsSendRate,
+		WarmupDailyLimit:     newsletterWarmupDailyLimit(emailConfig, now),
+	}
+	limits.WebsiteSent, err = service.repo.CountNewsletterRecipientsSentSince(ctx, tx, website.ID, now.Add(-emails.NewsletterSendInterval))
+	if err != nil {
+		return err
+	}
+	limits.OrganizationSent, err = service.repo.CountOrganizationNewsletterRecipientsSentSince(ctx, tx, organization.ID, now.Add(-emails.NewsletterSendInterval))
+	if err != nil {
+		return err
+	}
+	if limits.WarmupDailyLimit != nil {
+		limits.SentDuringLast24Hours, err = service.repo.CountNewsletterRecipientsSentSince(ctx, tx, website.ID, now.Add(-24*time.Hour))
+		if err != nil {
+			return err
+		}
+	}
+
+	batchSize, sendRate := newsletterBatch(limits)
+	if batchSize == 0 {
+		logger.Debug("emails.TaskSendNewsletterEmails: send rate limit reached")
+		return nil
+	}
+
+	recipients, err := service.repo.FindPendingNewsletterRecipients(ctx, tx, newsletter.ID, batchSize)
+	if err != nil {
+		return err
+	}
+
+	renderer, err := service.newNewsletterEmailRenderer(ctx, newsletter, website, emailConfig, false)
+	if err != nil {
+		return err
+	}
+
+	jobs := make([]queue.NewJobInput, 0, len(recipients))
+	lastScheduledFor := now
+	for _, recipient := range recipients {
+		recipient.UpdatedAt = now
+
+		email, renderErr := service.renderNewsletterRecipientEmail(&renderer, website.PrimaryDomain, recipient)
+		if renderErr != nil {
+			logger.Error("emails.TaskSendNewsletterEmails: error rendering email", slogx.Err(renderErr),
+				slog.String("newsletter_recipient.id", recipient.ID.String()))
+			recipient.Status = emails.NewsletterRecipientStatusFailed
+		} else {
+			// don't send all the emails at the same time
+			scheduledFor := now.Add(time.Duration(len(jobs)) * time.Second / time.Duration(sendRate))
+			lastScheduledFor = scheduledFor
+			recipient.Status = emails.NewsletterRecipientStatusSent
+			recipient.SentAt = &scheduledFor
+			jobs = append(jobs, queue.NewJobInput{
+				ScheduledFor: &scheduledFor,
+				Data:         email,
+			})
+		}
+
+		err = service.repo.UpdateNewsletterRecipient(ctx, tx, recipient)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(jobs) != 0 {
+		err = service.queue.PushMany(ctx, tx, jobs)
+		if err != nil {
+			return fmt.Errorf("pushing JobSendEmail jobs to queue: %w", err)
+		}
+
+		// we report data usage 1 minute after all the emails of the batch have been sent
+		sendUsageDataJob := queue.NewJobInput{
+			ScheduledFor: new(lastScheduledFor.Add(time.Minute)),
+			Data: organizations.JobSendUsageData{
+				OrganizationID: website.OrganizationID,
+			},
+		}
+		err = service.queue.Push(ctx, tx, sendUsageDataJob)
+		if err != nil {
+			return fmt.Errorf("pushing JobSendUsageData to queue: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("committing DB transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (service *EmailsService) renderNewsletterRecipientEmail(renderer *newsletterEmailRenderer, primaryDomain string,
+	recipient emails.NewsletterRecipient) (email emails.JobSendEmail, err error) {
+	unsubscribeLink, err := service.contactsService.GenerateUnsubscribeLink(primaryDomain, recipient.ContactID)
+	if err != nil {
+		return
+	}
+
+	return renderer.render(newsletterRecipient{
+		Name:                  recipient.Name,
+		Email:                 recipient.Email,
+		ContactID:             &recipient.ContactID,
+		UnsubscribeLink:       unsubscribeLink,
+		TrackingID:            guid.NewRandom(),
+		Variant:               recipient.Variant,
+		NewsletterRecipientID: &recipient.ID,
+	})
+}
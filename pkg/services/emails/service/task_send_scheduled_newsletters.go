@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"log/slog"
@@ -11,6 +12,8 @@ import (
 	"markdown.ninja/pkg/services/emails"
 )
 
+// TaskSendScheduledNewsletters sends the scheduled newsletters, and the winning subject of the A/B tests
+// which are completed
 func (service *EmailsService) TaskSendScheduledNewsletters(ctx context.Context) {
 	logger := slogx.FromCtx(ctx)
 	now := time.Now().UTC()
@@ -21,28 +24,72 @@ func (service *EmailsService) TaskSendScheduledNewsletters(ctx context.Context)
 	}
 
 	for _, newsletter := range newsletters {
+		abTestStage := emails.ABTestStageNone
 		newsletter.UpdatedAt = now
-		newsletter.SentAt = &now
+
+		switch {
+		case newsletter.ABTest == nil:
+			newsletter.SentAt = &now
+		case newsletter.SentAt == nil:
+			abTestStage = emails.ABTestStageSample
+			startABTest(&newsletter, now)
+		default:
+			abTestStage = emails.ABTestStageWinner
+			variantsStats, err := service.eventsService.GetNewsletterVariantsStats(ctx, newsletter.WebsiteID, newsletter.ID)
+			if err != nil {
+				errMessage := "emails.TaskSendScheduledNewsletters: error getting A/B test stats"
+				logger.Error(errMessage, slogx.Err(err), slog.String("newsletter.id", newsletter.ID.String()))
+				continue
+			}
+
+			winner := pickABTestWinner(newsletter.ABTest, variantsStats)
+			newsletter.ABTest.Winner = &winner
+			newsletter.Subject = newsletter.ABTest.Variants[winner].Subject
+			newsletter.ScheduledFor = nil
+		}
 
 		job := queue.NewJobInput{
 			Data: emails.JobSendNewsletter{
 				NewsletterID: newsletter.ID,
 				Test:         false,
 				SentAt:       now,
+				ABTestStage:  abTestStage,
 			},
 			Timeout: new(int64(600)),
 		}
 
-		err = service.queue.Push(ctx, nil, job)
+		// the newsletter is updated in the same transaction as the job is pushed so the job always sees
+		// the winner of the A/B test
+		err = service.pushScheduledNewsletterJob(ctx, newsletter, job)
 		if err != nil {
 			errMessage := "emails.TaskSendScheduledNewsletters: error pushing SendNewsletter job to queue"
 			logger.Error(errMessage, slogx.Err(err), slog.String("newsletter.id", newsletter.ID.String()))
 			continue
 		}
+	}
+}
 
-		err = service.repo.UpdateNewsletter(ctx, service.db, newsletter)
-		if err != nil {
-			continue
-		}
+func (service *EmailsService) pushScheduledNewsletterJob(ctx context.Context, newsletter emails.Newsletter, job queue.NewJobInput) (err error) {
+	tx, err := service.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("starting DB transaction: %w", err)
 	}
+	defer tx.Rollback()
+
+	err = service.repo.UpdateNewsletter(ctx, tx, newsletter)
+	if err != nil {
+		return err
+	}
+
+	err = service.queue.Push(ctx, tx, job)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("committing DB transaction: %w", err)
+	}
+
+	return nil
 }
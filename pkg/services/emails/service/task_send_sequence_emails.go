@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"log/slog"
+
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/queue"
+	"markdown.ninja/pkg/services/emails"
+)
+
+// TaskSendSequenceEmails advances the contacts whose next step is due and pushes the jobs to send
+// the emails of the steps
+func (service *EmailsService) TaskSendSequenceEmails(ctx context.Context) {
+	logger := slogx.FromCtx(ctx)
+	now := time.Now().UTC()
+
+	tx, err := service.db.Begin(ctx)
+	if err != nil {
+		logger.Error("emails.TaskSendSequenceEmails: error starting DB transaction", slogx.Err(err))
+		return
+	}
+	defer tx.Rollback()
+
+	// the remaining contacts are processed the next time the task runs
+	sequenceContacts, err := service.repo.FindDueSequenceContacts(ctx, tx, now, 1000)
+	if err != nil {
+		logger.Error("emails.TaskSendSequenceEmails: error finding due contacts", slogx.Err(err))
+		return
+	}
+
+	sequences := make(map[guid.GUID]emails.Sequence)
+	jobs := make([]queue.NewJobInput, 0, len(sequenceContacts))
+
+	for _, sequenceContact := range sequenceContacts {
+		sequence, sequenceFound := sequences[sequenceContact.SequenceID]
+		if !sequenceFound {
+			sequence, err = service.repo.FindSequenceByID(ctx, tx, sequenceContact.SequenceID)
+			if err != nil {
+				logger.Error("emails.TaskSendSequenceEmails: error finding sequence", slogx.Err(err),
+					slog.String("sequence.id", sequenceContact.SequenceID.String()))
+				return
+			}
+			sequences[sequence.ID] = sequence
+		}
+
+		step := sequenceContact.NextStep
+		sequenceContact.UpdatedAt = now
+
+		// steps may have been removed since the contact entered the sequence
+		if step < int64(len(sequence.Steps)) {
+			jobs = append(jobs, queue.NewJobInput{
+				Data: emails.JobSendSequenceEmail{
+					SequenceContactID: sequenceContact.ID,
+					Step:              step,
+				},
+			})
+		}
+
+		if step+1 < int64(len(sequence.Steps)) {
+			nextStepAt := now.Add(sequenceStepDelay(sequence.Steps[step+1]))
+			sequenceContact.NextStep = step + 1
+			sequenceContact.NextStepAt = &nextStepAt
+		} else {
+			sequenceContact.NextStep = int64(len(sequence.Steps))
+			sequenceContact.NextStepAt = nil
+			sequenceContact.Status = emails.SequenceContactStatusCompleted
+		}
+
+		err = service.repo.UpdateSequenceContact(ctx, tx, sequenceContact)
+		if err != nil {
+			logger.Error("emails.TaskSendSequenceEmails: error updating contact", slogx.Err(err),
+				slog.String("sequence_contact.id", sequenceContact.ID.String()))
+			return
+		}
+	}
+
+	if len(jobs) != 0 {
+		err = service.queue.PushMany(ctx, tx, jobs)
+		if err != nil {
+			logger.Error("emails.TaskSendSequenceEmails: error pushing JobSendSequenceEmail jobs to queue", slogx.Err(err))
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("emails.TaskSendSequenceEmails: error committing DB transaction", slogx.Err(err))
+		return
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/emails"
+)
+
+func (service *EmailsService) TriggerSequences(ctx context.Context, db db.Queryer, input emails.TriggerSequencesInput) (err error) {
+	now := time.Now().UTC()
+
+	// exit conditions are applied before entering the new sequences, so a purchase can't make a contact
+	// exit the sequence it has just triggered
+	if input.Trigger == emails.SequenceTriggerProductPurchased {
+		err = service.repo.ExitSequencesOnPurchase(ctx, db, now, input.ContactID, input.ProductIDs)
+		if err != nil {
+			return
+		}
+	}
+
+	sequences, err := service.repo.FindSequencesByTrigger(ctx, db, input.WebsiteID, input.Trigger)
+	if err != nil {
+		return
+	}
+
+	for _, sequence := range sequences {
+		if len(sequence.Steps) == 0 {
+			continue
+		}
+
+		if input.Trigger == emails.SequenceTriggerProductPurchased && !sequenceTriggerMatchesProducts(sequence, input.ProductIDs) {
+			continue
+		}
+
+		nextStepAt := now.Add(sequenceStepDelay(sequence.Steps[0]))
+		sequenceContact := emails.SequenceContact{
+			ID:         guid.NewTimeBased(),
+			CreatedAt:  now,
+			UpdatedAt:  now,
+			Status:     emails.SequenceContactStatusActive,
+			NextStep:   0,
+			NextStepAt: &nextStepAt,
+			ExitReason: emails.SequenceExitReasonNone,
+			SequenceID: sequence.ID,
+			ContactID:  input.ContactID,
+			WebsiteID:  input.WebsiteID,
+		}
+		_, err = service.repo.CreateSequenceContact(ctx, db, sequenceContact)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
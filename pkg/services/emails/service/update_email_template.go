@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/emails"
+)
+
+func (service *EmailsService) UpdateEmailTemplate(ctx context.Context, input emails.UpdateEmailTemplateInput) (emailTemplate emails.EmailTemplate, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	err = validateEmailTemplateType(input.Type)
+	if err != nil {
+		return
+	}
+
+	emailTemplate = emails.EmailTemplate{
+		Type:              input.Type,
+		Body:              defaultEmailTemplates[input.Type],
+		WebsiteID:         input.WebsiteID,
+		Customized:        false,
+		DefaultBody:       defaultEmailTemplates[input.Type],
+		RequiredVariables: emailTemplatesRequiredVariables[input.Type],
+	}
+
+	body := strings.TrimSpace(input.Body)
+	if body == "" || body == strings.TrimSpace(defaultEmailTemplates[input.Type]) {
+		err = service.repo.DeleteEmailTemplate(ctx, service.db, input.WebsiteID, input.Type)
+		return
+	}
+
+	// the template is rendered with sample data to detect the errors which only happen during execution
+	_, err = service.renderEmailTemplatePreview(ctx, input.WebsiteID, input.Type, body)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	emailTemplate.ID = guid.NewTimeBased()
+	emailTemplate.CreatedAt = now
+	emailTemplate.UpdatedAt = now
+	emailTemplate.Body = body
+	emailTemplate.Customized = true
+	err = service.repo.UpsertEmailTemplate(ctx, service.db, emailTemplate)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -26,10 +26,22 @@ func (service *EmailsService) UpdateNewsletter(ctx context.Context, input emails
 		return
 	}
 
+	// the subject and the schedule of the winner must not change while the A/B test is in progress
+	if newsletter.ABTest != nil && newsletter.SentAt != nil && newsletter.ABTest.Winner == nil {
+		err = emails.ErrABTestInProgress
+		return
+	}
+
 	now := time.Now().UTC()
 	newsletter.UpdatedAt = now
 	newsletter.Subject = strings.TrimSpace(input.Subject)
 	newsletter.ScheduledFor = input.ScheduledFor
+	if input.MembersOnly != nil {
+		newsletter.MembersOnly = *input.MembersOnly
+	}
+	if input.ProductUpdates != nil {
+		newsletter.ProductUpdates = *input.ProductUpdates
+	}
 
 	if input.BodyMarkdown != nil {
 		newsletter.BodyMarkdown = *input.BodyMarkdown
@@ -57,6 +69,27 @@ func (service *EmailsService) UpdateNewsletter(ctx context.Context, input emails
 		return
 	}
 
+	// the A/B test can't be changed once the sample has been sent
+	if newsletter.SentAt == nil {
+		newsletter.ABTest, err = service.buildABTest(newsletter.Subject, input.ABTest)
+		if err != nil {
+			return
+		}
+
+		if newsletter.ABTest != nil {
+			var emailConfig emails.WebsiteConfiguration
+			emailConfig, err = service.repo.FindWebsiteConfiguration(ctx, service.db, newsletter.WebsiteID)
+			if err != nil {
+				return
+			}
+
+			err = checkABTestTracking(emailConfig, newsletter.ABTest)
+			if err != nil {
+				return
+			}
+		}
+	}
+
 	err = service.repo.UpdateNewsletter(ctx, service.db, newsletter)
 	if err != nil {
 		return
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"markdown.ninja/pkg/services/emails"
+)
+
+func (service *EmailsService) UpdateSequence(ctx context.Context, input emails.UpdateSequenceInput) (sequence emails.Sequence, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	sequence, err = service.repo.FindSequenceByID(ctx, service.db, input.ID)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, sequence.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	sequence.UpdatedAt = now
+
+	if input.Name != nil {
+		sequence.Name = strings.TrimSpace(*input.Name)
+		err = validateSequenceName(sequence.Name)
+		if err != nil {
+			return
+		}
+	}
+
+	if sequence.Trigger == emails.SequenceTriggerProductPurchased {
+		err = service.validateSequenceProduct(ctx, sequence.WebsiteID, input.TriggerProductID)
+		if err != nil {
+			return
+		}
+		sequence.TriggerProductID = input.TriggerProductID
+	}
+
+	if input.ExitOnPurchase != nil {
+		sequence.ExitOnPurchase = *input.ExitOnPurchase
+	}
+
+	err = service.validateSequenceProduct(ctx, sequence.WebsiteID, input.ExitProductID)
+	if err != nil {
+		return
+	}
+	sequence.ExitProductID = input.ExitProductID
+
+	if input.Paused != nil {
+		if *input.Paused && sequence.PausedAt == nil {
+			sequence.PausedAt = &now
+		} else if !*input.Paused {
+			sequence.PausedAt = nil
+		}
+	}
+
+	// the contacts in the sequence keep the index of their next step, so removing or reordering steps
+	// changes the next email they will receive
+	if input.Steps != nil {
+		sequence.Steps, err = service.buildSequenceSteps(*input.Steps, sequence.Steps)
+		if err != nil {
+			return
+		}
+	}
+
+	err = service.repo.UpdateSequence(ctx, service.db, sequence)
+	if err != nil {
+		return
+	}
+
+	stats, err := service.repo.GetSequenceStats(ctx, service.db, sequence.ID)
+	if err != nil {
+		return
+	}
+	sequence.Stats = &stats
+
+	return
+}
@@ -48,6 +48,77 @@ func (service *EmailsService) UpdateWebsiteConfiguration(ctx context.Context, in
 
 	configuration.FromName = fromnName
 
+	if input.TrackClicks != nil {
+		configuration.TrackClicks = *input.TrackClicks
+	}
+	if input.TrackOpens != nil {
+		configuration.TrackOpens = *input.TrackOpens
+	}
+	if input.TrackContacts != nil {
+		configuration.TrackContacts = *input.TrackContacts
+	}
+	if input.DigestSubject != nil {
+		digestSubject := strings.TrimSpace(*input.DigestSubject)
+		if digestSubject != "" {
+			err = service.validateNewsletterSubject(digestSubject)
+			if err != nil {
+				return
+			}
+		}
+		configuration.DigestSubject = digestSubject
+	}
+	if input.DigestTemplate != nil {
+		digestTemplate := strings.TrimSpace(*input.DigestTemplate)
+		err = validateDigestTemplate(digestTemplate)
+		if err != nil {
+			return
+		}
+		configuration.DigestTemplate = digestTemplate
+	}
+	if input.DigestIncludeExcerpts != nil {
+		configuration.DigestIncludeExcerpts = *input.DigestIncludeExcerpts
+	}
+	if input.DigestFrequency != nil && *input.DigestFrequency != configuration.DigestFrequency {
+		err = updateDigestFrequency(&configuration, *input.DigestFrequency, time.Now().UTC())
+		if err != nil {
+			return
+		}
+	}
+	if input.ArchivePath != nil {
+		archivePath := strings.TrimSpace(*input.ArchivePath)
+		if archivePath != "" && archivePath != configuration.ArchivePath {
+			err = service.validateArchivePath(ctx, website.ID, archivePath)
+			if err != nil {
+				return
+			}
+		}
+		configuration.ArchivePath = archivePath
+	}
+	if input.FooterText != nil {
+		footerText := strings.TrimSpace(*input.FooterText)
+		if len(footerText) > emails.EmailFooterTextMaxSize {
+			err = emails.ErrEmailFooterTextIsTooLong
+			return
+		}
+		configuration.FooterText = footerText
+	}
+	if input.LegalAddress != nil {
+		legalAddress := strings.TrimSpace(*input.LegalAddress)
+		if len(legalAddress) > emails.EmailLegalAddressMaxSize {
+			err = emails.ErrEmailLegalAddressIsTooLong
+			return
+		}
+		configuration.LegalAddress = legalAddress
+	}
+	if input.SendRate != nil {
+		if *input.SendRate < 1 || *input.SendRate > emails.NewsletterSendRateMax {
+			err = emails.ErrSendRateIsNotValid(emails.NewsletterSendRateMax)
+			return
+		}
+		configuration.SendRate = *input.SendRate
+	}
+	configuration.UpdatedAt = time.Now().UTC()
+
 	if fromAddress == "" {
 		if configuration.FromDomain != "" {
 			err = service.mailer.RemoveDomain(ctx, configuration.FromDomain)
@@ -61,6 +132,7 @@ func (service *EmailsService) UpdateWebsiteConfiguration(ctx context.Context, in
 		configuration.FromDomain = ""
 		configuration.DnsRecords = []mailer.DnsRecord{}
 		configuration.DomainVerified = false
+		configuration.DomainVerifiedAt = nil
 		configuration.FromAddress = ""
 	} else if fromAddress == configuration.FromAddress {
 		// Do nothing
@@ -106,6 +178,9 @@ func (service *EmailsService) UpdateWebsiteConfiguration(ctx context.Context, in
 			configuration.UpdatedAt = time.Now().UTC()
 			configuration.FromDomain = fromDomain
 			configuration.DnsRecords = emailDomain.DnsRecords
+			// a new domain needs to be verified and warmed up
+			configuration.DomainVerified = false
+			configuration.DomainVerifiedAt = nil
 			configuration.FromAddress = fromAddress
 		}
 	}
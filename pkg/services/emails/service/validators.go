@@ -6,6 +6,8 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/errs"
 	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/kernel"
 )
@@ -72,3 +74,20 @@ func (service *EmailsService) validateSenderEmailAddress(ctx context.Context, em
 
 	return nil
 }
+
+// validateArchivePath checks that the archive can be served at the given path without hiding a page
+// of the website
+func (service *EmailsService) validateArchivePath(ctx context.Context, websiteID guid.GUID, archivePath string) (err error) {
+	if archivePath == "/" || service.contentService.ValidatePagePath(archivePath) != nil {
+		return emails.ErrArchivePathIsNotValid
+	}
+
+	_, err = service.contentService.FindPageByPath(ctx, service.db, websiteID, archivePath)
+	if err == nil {
+		return emails.ErrArchivePathIsUsed
+	} else if !errs.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
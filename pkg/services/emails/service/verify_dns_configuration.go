@@ -39,7 +39,15 @@ func (service *EmailsService) VerifyDnsConfiguration(ctx context.Context, input
 		return
 	}
 
-	configuration.UpdatedAt = time.Now().UTC()
+	now := time.Now().UTC()
+	// the warmup of the domain starts when it's verified for the first time
+	if !configuration.DomainVerified {
+		configuration.DomainVerifiedAt = nil
+	} else if configuration.DomainVerifiedAt == nil {
+		configuration.DomainVerifiedAt = &now
+	}
+
+	configuration.UpdatedAt = now
 	err = service.repo.UpdateWebsiteConfiguration(ctx, service.db, configuration)
 	if err != nil {
 		return
@@ -2,18 +2,14 @@ package templates
 
 import (
 	_ "embed"
-	"html/template"
 )
 
+// The default templates of the emails sent on behalf of the websites. They are executed with
+// emails.EmailTemplateData and can be overridden by each website.
+
 //go:embed newsletter_email.html
 var NewsletterEmailTemplate string
 
-type NewsletterEmailData struct {
-	Subject         string
-	Content         template.HTML
-	UnsubscribeLink template.URL
-}
-
 // <mjml>
 //   <mj-head>
 //     <mj-style>
@@ -76,6 +72,12 @@ type NewsletterEmailData struct {
 //     <mj-section padding-top="30px">
 //       <mj-column>
 //       	<mj-divider border-color="#dddddd" border-width="1px"></mj-divider>
+//         {{ if .FooterText }}
+//         <mj-text align="center" font-size="12px" color="#424242" font-family="helvetica" line-height="1.5">{{ .FooterText }}</mj-text>
+//         {{ end }}
+//         {{ if .LegalAddress }}
+//         <mj-text align="center" font-size="12px" color="#424242" font-family="helvetica" line-height="1.5">{{ .LegalAddress }}</mj-text>
+//         {{ end }}
 //         <mj-text align="center" font-size="12px" color="#424242" font-family="helvetica">
 //           <a href="{{ .UnsubscribeLink }}">Unsubscribe</a>
 //         </mj-text>
@@ -83,3 +85,77 @@ type NewsletterEmailData struct {
 //     </mj-section>
 //   </mj-body>
 // </mjml>
+
+//go:embed login_email.html
+var LoginEmailTemplate string
+
+// <mjml>
+//   <mj-body>
+//     <mj-section>
+//       <mj-column>
+//         <mj-text align="center" font-size="22px" color="#424242" font-family="helvetica" font-weight="700">Your Login Code</mj-text>
+//         <mj-divider border-color="#dddddd" border-width="2px"></mj-divider>
+//         <mj-text font-size="20px" color="#424242" font-family="helvetica" align="center" padding-top="30px">{{ .Code }}</mj-text>
+//         <mj-text font-size="15px" padding-top="40px">or click the following URL: <a href="{{ .Link }}">{{ .Link }}</a></mj-text>
+//       </mj-column>
+//     </mj-section>
+//   </mj-body>
+// </mjml>
+
+//go:embed subscribe_email.html
+var SubscribeEmailTemplate string
+
+// <mjml>
+//   <mj-body>
+//     <mj-section>
+//       <mj-column>
+//         <mj-text align="center" font-size="22px" color="#424242" font-family="helvetica" font-weight="700">Your Confirmation Code</mj-text>
+//         <mj-divider border-color="#dddddd" border-width="2px"></mj-divider>
+//         <mj-text font-size="20px" color="#424242" font-family="helvetica" align="center" padding-top="30px">{{ .Code }}</mj-text>
+//         <mj-text font-size="15px" padding-top="40px">or click the following URL: <a href="{{ .Link }}">{{ .Link }}</a></mj-text>
+//       </mj-column>
+//     </mj-section>
+//   </mj-body>
+// </mjml>
+
+//go:embed verify_email_email.html
+var VerifyEmailEmailTemplate string
+
+// <mjml>
+//   <mj-body>
+//     <mj-section>
+//       <mj-column>
+//         <mj-text align="center" font-size="22px" color="#424242" font-family="helvetica" font-weight="700">Confirm Your Email</mj-text>
+//         <mj-divider border-color="#dddddd" border-width="2px"></mj-divider>
+//         <mj-text font-size="20px" color="#424242" font-family="helvetica" align="center" padding-top="30px">Please click the following link to confirm your new Email: <br />
+//           <a href="{{ .Link }}">{{ .Link }}</a> </mj-text>
+//       </mj-column>
+//     </mj-section>
+//   </mj-body>
+// </mjml>
+
+//go:embed order_confirmation_email.html
+var OrderConfirmationEmailTemplate string
+
+// <mjml>
+//   <mj-body>
+//     <mj-section>
+//       <mj-column>
+//         <mj-text align="center" font-size="22px" color="#424242" font-family="helvetica" font-weight="700">Order #{{ .OrderID }} confirmed</mj-text>
+//         <mj-divider border-color="#dddddd" border-width="2px"></mj-divider>
+//         <mj-text font-size="20px" color="#424242" font-family="helvetica" align="center" padding-top="30px">Thank you for your order! You can now access your products, receipt and invoice in your account: <br />
+//           <a href="{{ .AccountURL }}">{{ .AccountURL }}</a> </mj-text>
+//         {{ if .GiftRecipientEmail }}
+//         <mj-text font-size="20px" color="#424242" font-family="helvetica" align="center" padding-top="30px" line-height="1.5">Your gift has been sent to {{ .GiftRecipientEmail }}.</mj-text>
+//         {{ end }}
+//         {{ if .LicenseKeys }}
+//         <mj-text font-size="20px" color="#424242" font-family="helvetica" align="center" padding-top="30px" line-height="1.5">Your license keys:<br />
+//           {{ range .LicenseKeys }}
+//           {{ .ProductName }}: <code>{{ .Key }}</code><br />
+//           {{ end }}
+//         </mj-text>
+//         {{ end }}
+//       </mj-column>
+//     </mj-section>
+//   </mj-body>
+// </mjml>
@@ -5,8 +5,18 @@ import (
 	"testing"
 )
 
-func TestNewsletterEmailTemplate(t *testing.T) {
-	if strings.TrimSpace(NewsletterEmailTemplate) == "" {
-		t.Error("NewsletterEmailTemplate is empty")
+func TestEmailTemplates(t *testing.T) {
+	emailTemplates := map[string]string{
+		"NewsletterEmailTemplate":        NewsletterEmailTemplate,
+		"LoginEmailTemplate":             LoginEmailTemplate,
+		"SubscribeEmailTemplate":         SubscribeEmailTemplate,
+		"VerifyEmailEmailTemplate":       VerifyEmailEmailTemplate,
+		"OrderConfirmationEmailTemplate": OrderConfirmationEmailTemplate,
+	}
+
+	for name, emailTemplate := range emailTemplates {
+		if strings.TrimSpace(emailTemplate) == "" {
+			t.Errorf("%s is empty", name)
+		}
 	}
 }
@@ -2,6 +2,8 @@ package events
 
 import (
 	"fmt"
+
+	"github.com/skerkour/stdx-go/guid"
 )
 
 type EventType int64
@@ -25,6 +27,17 @@ const (
 	EventTypeOrderPlaced
 	EventTypeOrderCanceled
 	EventTypeOrderCompleted
+	// EventTypeOrderRecovered is tracked, in addition to EventTypeOrderCompleted, when an order is
+	// completed after an abandoned checkout recovery email has been sent
+	EventTypeOrderRecovered
+	// EventTypeEmailBounced and EventTypeEmailComplained are tracked when the email provider reports a
+	// bounce or a spam complaint for an email sent to a contact
+	EventTypeEmailBounced
+	EventTypeEmailComplained
+	// EventTypeNewsletterOpened and EventTypeNewsletterLinkClicked are only tracked for the websites which
+	// have enabled open and click tracking. AnonymousID is a random identifier of the recipient of the email
+	EventTypeNewsletterOpened
+	EventTypeNewsletterLinkClicked
 )
 
 // MarshalText implements encoding.TextMarshaler.
@@ -46,6 +59,16 @@ func (eventType EventType) MarshalText() (ret []byte, err error) {
 		ret = []byte("order_completed")
 	case EventTypeOrderCanceled:
 		ret = []byte("order_canceled")
+	case EventTypeOrderRecovered:
+		ret = []byte("order_recovered")
+	case EventTypeEmailBounced:
+		ret = []byte("email_bounced")
+	case EventTypeEmailComplained:
+		ret = []byte("email_complained")
+	case EventTypeNewsletterOpened:
+		ret = []byte("newsletter_opened")
+	case EventTypeNewsletterLinkClicked:
+		ret = []byte("newsletter_link_clicked")
 	default:
 		err = fmt.Errorf("Unknown EventType: %d", eventType)
 	}
@@ -76,6 +99,16 @@ func (eventType *EventType) UnmarshalText(data []byte) (err error) {
 		*eventType = EventTypeOrderCompleted
 	case "order_canceled":
 		*eventType = EventTypeOrderCanceled
+	case "order_recovered":
+		*eventType = EventTypeOrderRecovered
+	case "email_bounced":
+		*eventType = EventTypeEmailBounced
+	case "email_complained":
+		*eventType = EventTypeEmailComplained
+	case "newsletter_opened":
+		*eventType = EventTypeNewsletterOpened
+	case "newsletter_link_clicked":
+		*eventType = EventTypeNewsletterLinkClicked
 	default:
 		err = fmt.Errorf("Unknown EventType: %s", string(data))
 	}
@@ -109,6 +142,33 @@ type EventDataOrderCompleted struct {
 	TotalAmount int64 `json:"total_amount"`
 }
 
+type EventDataOrderRecovered struct {
+	TotalAmount int64 `json:"total_amount"`
+}
+
+type EventDataEmailBounced struct {
+	// permanent | transient
+	BounceType string `json:"bounce_type"`
+}
+
+type EventDataEmailComplained struct {
+}
+
+type EventDataNewsletterOpened struct {
+	// only set if the website has enabled contacts tracking
+	ContactID *guid.GUID `json:"contact_id,omitempty"`
+	// only set for the emails sent to the sample of an A/B test
+	Variant *int64 `json:"variant,omitempty"`
+}
+
+type EventDataNewsletterLinkClicked struct {
+	Url string `json:"url"`
+	// only set if the website has enabled contacts tracking
+	ContactID *guid.GUID `json:"contact_id,omitempty"`
+	// only set for the emails sent to the sample of an A/B test
+	Variant *int64 `json:"variant,omitempty"`
+}
+
 type EventData interface {
 	EventType() string
 }
@@ -262,6 +262,43 @@ type TrackOrderCompletedInput struct {
 	TotalAmount int64
 }
 
+type TrackOrderRecoveredInput struct {
+	OrderID     guid.GUID
+	WebsiteID   guid.GUID
+	TotalAmount int64
+}
+
+type TrackEmailBouncedInput struct {
+	BounceType string
+
+	WebsiteID    guid.GUID
+	NewsletterID *guid.GUID
+}
+
+type TrackEmailComplainedInput struct {
+	WebsiteID    guid.GUID
+	NewsletterID *guid.GUID
+}
+
+type TrackNewsletterOpenedInput struct {
+	RecipientID guid.GUID
+	ContactID   *guid.GUID
+	Variant     *int64
+
+	WebsiteID    guid.GUID
+	NewsletterID guid.GUID
+}
+
+type TrackNewsletterLinkClickedInput struct {
+	Url         string
+	RecipientID guid.GUID
+	ContactID   *guid.GUID
+	Variant     *int64
+
+	WebsiteID    guid.GUID
+	NewsletterID guid.GUID
+}
+
 type TrackOrderCanceledInput struct {
 	OrderID     guid.GUID
 	WebsiteID   guid.GUID
@@ -297,6 +334,35 @@ type AnalyticsData struct {
 	Browsers       []CounterBrowser         `json:"browsers"`
 	OSes           []CounterOperatingSystem `json:"oses"`
 	NewSubscribers int64                    `json:"new_subscribers"`
+	Newsletters    []NewsletterEmailsStats  `json:"newsletters"`
+}
+
+// NewsletterEmailsStats are the deliverability and engagement statistics of the emails of a newsletter.
+// Opens and clicks are only available if tracking is enabled for the website.
+type NewsletterEmailsStats struct {
+	NewsletterID  guid.GUID `db:"newsletter_id" json:"newsletter_id"`
+	EmailsSent    int64     `db:"emails_sent" json:"emails_sent"`
+	Delivered     int64     `db:"-" json:"delivered"`
+	Bounces       int64     `db:"bounces" json:"bounces"`
+	Complaints    int64     `db:"complaints" json:"complaints"`
+	BounceRate    float64   `db:"-" json:"bounce_rate"`
+	ComplaintRate float64   `db:"-" json:"complaint_rate"`
+	UniqueOpens   int64     `db:"unique_opens" json:"unique_opens"`
+	UniqueClicks  int64     `db:"unique_clicks" json:"unique_clicks"`
+}
+
+// NewsletterAnalytics are the statistics of a single newsletter since it has been sent
+type NewsletterAnalytics struct {
+	NewsletterEmailsStats
+	Clicks   int64     `json:"clicks"`
+	TopLinks []Counter `json:"top_links"`
+}
+
+// NewsletterVariantStats are the engagement statistics of a subject variant of a newsletter A/B test
+type NewsletterVariantStats struct {
+	Variant      int64 `db:"variant" json:"variant"`
+	UniqueOpens  int64 `db:"unique_opens" json:"unique_opens"`
+	UniqueClicks int64 `db:"unique_clicks" json:"unique_clicks"`
 }
 
 type Counter struct {
@@ -435,3 +435,144 @@ func (repo *EventsRepository) GetNewSubscribersCount(ctx context.Context, db db.
 
 	return
 }
+
+func (repo *EventsRepository) GetNewslettersEmailsStats(ctx context.Context, db db.Queryer, websiteID guid.GUID,
+	from, to time.Time) (ret []events.NewsletterEmailsStats, err error) {
+	ret = []events.NewsletterEmailsStats{}
+
+	cacheKey := fmt.Sprintf("NewslettersEmailsStats-%s-%d-%d", websiteID.String(), from.Unix(), to.Unix())
+	if cacheRes := repo.cache.Get(cacheKey); cacheRes != nil {
+		return cacheRes.Value().([]events.NewsletterEmailsStats), nil
+	}
+
+	// anonymous_id is a random identifier of the recipient for the opens and clicks
+	const query = `SELECT newsletter_id,
+			COUNT(*) FILTER (WHERE type = $4) AS emails_sent,
+			COUNT(*) FILTER (WHERE type = $5) AS bounces,
+			COUNT(*) FILTER (WHERE type = $6) AS complaints,
+			COUNT(DISTINCT anonymous_id) FILTER (WHERE type = $7) AS unique_opens,
+			COUNT(DISTINCT anonymous_id) FILTER (WHERE type = $8) AS unique_clicks
+		FROM events
+		WHERE website_id = $1 AND time >= $2 AND time <= $3 AND newsletter_id IS NOT NULL
+			AND type IN ($4, $5, $6, $7, $8)
+		GROUP BY newsletter_id
+		HAVING COUNT(*) FILTER (WHERE type = $4) > 0
+		ORDER BY MAX(time) DESC
+	`
+
+	err = db.Select(ctx, &ret, query, websiteID, from, to,
+		events.EventTypeEmailSent, events.EventTypeEmailBounced, events.EventTypeEmailComplained,
+		events.EventTypeNewsletterOpened, events.EventTypeNewsletterLinkClicked)
+	if err != nil {
+		err = fmt.Errorf("events.GetNewslettersEmailsStats: %w", err)
+		return
+	}
+
+	repo.cache.Set(cacheKey, ret, 2*time.Minute)
+
+	return
+}
+
+func (repo *EventsRepository) GetNewsletterEmailsStats(ctx context.Context, db db.Queryer, websiteID, newsletterID guid.GUID) (ret events.NewsletterEmailsStats, err error) {
+	cacheKey := fmt.Sprintf("NewsletterEmailsStats-%s-%s", websiteID.String(), newsletterID.String())
+	if cacheRes := repo.cache.Get(cacheKey); cacheRes != nil {
+		return cacheRes.Value().(events.NewsletterEmailsStats), nil
+	}
+
+	const query = `SELECT $2::UUID AS newsletter_id,
+			COUNT(*) FILTER (WHERE type = $3) AS emails_sent,
+			COUNT(*) FILTER (WHERE type = $4) AS bounces,
+			COUNT(*) FILTER (WHERE type = $5) AS complaints,
+			COUNT(DISTINCT anonymous_id) FILTER (WHERE type = $6) AS unique_opens,
+			COUNT(DISTINCT anonymous_id) FILTER (WHERE type = $7) AS unique_clicks
+		FROM events
+		WHERE website_id = $1 AND newsletter_id = $2
+	`
+
+	err = db.Get(ctx, &ret, query, websiteID, newsletterID,
+		events.EventTypeEmailSent, events.EventTypeEmailBounced, events.EventTypeEmailComplained,
+		events.EventTypeNewsletterOpened, events.EventTypeNewsletterLinkClicked)
+	if err != nil {
+		err = fmt.Errorf("events.GetNewsletterEmailsStats: %w", err)
+		return
+	}
+
+	repo.cache.Set(cacheKey, ret, 2*time.Minute)
+
+	return
+}
+
+// GetNewsletterTopLinks returns the total number of clicks on the links of the newsletter and the links
+// with the most unique clicks
+func (repo *EventsRepository) GetNewsletterTopLinks(ctx context.Context, db db.Queryer, websiteID, newsletterID guid.GUID,
+	limit int64) (clicks int64, ret []events.Counter, err error) {
+	ret = make([]events.Counter, 0, limit)
+
+	cacheKey := fmt.Sprintf("NewsletterTopLinks-%s-%s-%d", websiteID.String(), newsletterID.String(), limit)
+	if cacheRes := repo.cache.Get(cacheKey); cacheRes != nil {
+		cached := cacheRes.Value().(newsletterTopLinks)
+		return cached.clicks, cached.links, nil
+	}
+
+	const clicksQuery = `SELECT COUNT(*) FROM events
+		WHERE website_id = $1 AND newsletter_id = $2 AND type = $3`
+	err = db.Get(ctx, &clicks, clicksQuery, websiteID, newsletterID, events.EventTypeNewsletterLinkClicked)
+	if err != nil {
+		err = fmt.Errorf("events.GetNewsletterTopLinks: counting clicks: %w", err)
+		return
+	}
+
+	const query = `SELECT data->>'url' AS label, COUNT(DISTINCT anonymous_id) AS count
+		FROM events
+		WHERE website_id = $1 AND newsletter_id = $2 AND type = $3
+		GROUP BY label
+		ORDER BY count DESC
+		LIMIT $4
+	`
+	err = db.Select(ctx, &ret, query, websiteID, newsletterID, events.EventTypeNewsletterLinkClicked, limit)
+	if err != nil {
+		err = fmt.Errorf("events.GetNewsletterTopLinks: %w", err)
+		return
+	}
+
+	repo.cache.Set(cacheKey, newsletterTopLinks{clicks: clicks, links: ret}, 2*time.Minute)
+
+	return
+}
+
+type newsletterTopLinks struct {
+	clicks int64
+	links  []events.Counter
+}
+
+// GetNewsletterVariantsStats returns the unique opens and clicks of each subject variant of a newsletter.
+// Only the events of the sample of the A/B test have a variant.
+func (repo *EventsRepository) GetNewsletterVariantsStats(ctx context.Context, db db.Queryer, websiteID, newsletterID guid.GUID) (ret []events.NewsletterVariantStats, err error) {
+	ret = []events.NewsletterVariantStats{}
+
+	cacheKey := fmt.Sprintf("NewsletterVariantsStats-%s-%s", websiteID.String(), newsletterID.String())
+	if cacheRes := repo.cache.Get(cacheKey); cacheRes != nil {
+		return cacheRes.Value().([]events.NewsletterVariantStats), nil
+	}
+
+	const query = `SELECT (data->>'variant')::BIGINT AS variant,
+			COUNT(DISTINCT anonymous_id) FILTER (WHERE type = $3) AS unique_opens,
+			COUNT(DISTINCT anonymous_id) FILTER (WHERE type = $4) AS unique_clicks
+		FROM events
+		WHERE website_id = $1 AND newsletter_id = $2 AND type IN ($3, $4)
+			AND data->>'variant' IS NOT NULL
+		GROUP BY variant
+		ORDER BY variant
+	`
+
+	err = db.Select(ctx, &ret, query, websiteID, newsletterID,
+		events.EventTypeNewsletterOpened, events.EventTypeNewsletterLinkClicked)
+	if err != nil {
+		err = fmt.Errorf("events.GetNewsletterVariantsStats: %w", err)
+		return
+	}
+
+	repo.cache.Set(cacheKey, ret, 2*time.Minute)
+
+	return
+}
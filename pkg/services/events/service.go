@@ -17,6 +17,11 @@ type Service interface {
 	TrackOrderPlaced(ctx context.Context, input TrackOrderPlacedInput)
 	TrackOrderCompleted(ctx context.Context, input TrackOrderCompletedInput)
 	TrackOrderCanceled(ctx context.Context, input TrackOrderCanceledInput)
+	TrackOrderRecovered(ctx context.Context, input TrackOrderRecoveredInput)
+	TrackEmailBounced(ctx context.Context, input TrackEmailBouncedInput)
+	TrackEmailComplained(ctx context.Context, input TrackEmailComplainedInput)
+	TrackNewsletterOpened(ctx context.Context, input TrackNewsletterOpenedInput)
+	TrackNewsletterLinkClicked(ctx context.Context, input TrackNewsletterLinkClickedInput)
 
 	// TrackEventInBackground calls TrackEvent in a new goroutine which allow to avoid blocking when tracking
 	// an event
@@ -26,6 +31,11 @@ type Service interface {
 	ScheduleDeletionOfWebsiteData(ctx context.Context, db db.Queryer, websiteID guid.GUID) (err error)
 	ScheduleDeletionOfOrganizationData(ctx context.Context, db db.Queryer, organizationID guid.GUID) (err error)
 	GetEmailsSentCountForOrganization(ctx context.Context, db db.Queryer, organizationID guid.GUID, from, to time.Time) (count int64, err error)
+	// GetNewsletterAnalytics doesn't check that the actor is allowed to access the newsletter
+	GetNewsletterAnalytics(ctx context.Context, websiteID, newsletterID guid.GUID) (analytics NewsletterAnalytics, err error)
+	// GetNewsletterVariantsStats returns the statistics of the subject variants of an A/B tested newsletter.
+	// It doesn't check that the actor is allowed to access the newsletter
+	GetNewsletterVariantsStats(ctx context.Context, websiteID, newsletterID guid.GUID) (stats []NewsletterVariantStats, err error)
 
 	// Jobs
 	JobDeleteWebsiteEvents(ctx context.Context, input JobDeleteWebsiteEvents) (err error)
@@ -154,3 +154,12 @@ func (service *Service) cleanupReferrer(websitePrimaryDomain, referrerHeader, re
 
 	return
 }
+
+func computeNewsletterEmailsRates(stats events.NewsletterEmailsStats) events.NewsletterEmailsStats {
+	stats.Delivered = max(stats.EmailsSent-stats.Bounces, 0)
+	if stats.EmailsSent != 0 {
+		stats.BounceRate = float64(stats.Bounces) / float64(stats.EmailsSent)
+		stats.ComplaintRate = float64(stats.Complaints) / float64(stats.EmailsSent)
+	}
+	return stats
+}
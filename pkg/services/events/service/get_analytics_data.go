@@ -37,6 +37,7 @@ func (service *Service) GetAnalyticsData(ctx context.Context, input events.GetAn
 		Browsers:       []events.CounterBrowser{},
 		OSes:           []events.CounterOperatingSystem{},
 		NewSubscribers: 0,
+		Newsletters:    []events.NewsletterEmailsStats{},
 	}
 
 	for _, dailyData := range pageViewsAndVisitors {
@@ -102,6 +103,19 @@ func (service *Service) GetAnalyticsData(ctx context.Context, input events.GetAn
 		return taskErr
 	})
 
+	errGroup.Go(func() error {
+		var taskErr error
+		ret.Newsletters, taskErr = service.repo.GetNewslettersEmailsStats(ctx, service.eventsDb, input.WebsiteID, from, to)
+		if taskErr != nil {
+			return taskErr
+		}
+
+		for i, stats := range ret.Newsletters {
+			ret.Newsletters[i] = computeNewsletterEmailsRates(stats)
+		}
+		return nil
+	})
+
 	err = errGroup.Wait()
 	if err != nil {
 		return
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/events"
+)
+
+func (service *Service) GetNewsletterAnalytics(ctx context.Context, websiteID, newsletterID guid.GUID) (analytics events.NewsletterAnalytics, err error) {
+	stats, err := service.repo.GetNewsletterEmailsStats(ctx, service.eventsDb, websiteID, newsletterID)
+	if err != nil {
+		return
+	}
+
+	clicks, topLinks, err := service.repo.GetNewsletterTopLinks(ctx, service.eventsDb, websiteID, newsletterID, 10)
+	if err != nil {
+		return
+	}
+
+	analytics = events.NewsletterAnalytics{
+		NewsletterEmailsStats: computeNewsletterEmailsRates(stats),
+		Clicks:                clicks,
+		TopLinks:              topLinks,
+	}
+	return
+}
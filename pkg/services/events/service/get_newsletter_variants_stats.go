@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/events"
+)
+
+func (service *Service) GetNewsletterVariantsStats(ctx context.Context, websiteID, newsletterID guid.GUID) (stats []events.NewsletterVariantStats, err error) {
+	stats, err = service.repo.GetNewsletterVariantsStats(ctx, service.eventsDb, websiteID, newsletterID)
+	return
+}
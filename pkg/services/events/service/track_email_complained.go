@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"markdown.ninja/pkg/services/events"
+)
+
+func (service *Service) TrackEmailComplained(ctx context.Context, input events.TrackEmailComplainedInput) {
+	go service.trackEmailComplainedInBackground(ctx, input)
+}
+
+func (service *Service) trackEmailComplainedInBackground(ctx context.Context, input events.TrackEmailComplainedInput) {
+	now := time.Now().UTC()
+	event := events.Event{
+		Time:         now,
+		Type:         events.EventTypeEmailComplained,
+		Data:         events.EventDataEmailComplained{},
+		WebsiteID:    input.WebsiteID,
+		NewsletterID: input.NewsletterID,
+	}
+
+	service.eventsBuffer.Push(event)
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"markdown.ninja/pkg/services/events"
+)
+
+func (service *Service) TrackNewsletterLinkClicked(ctx context.Context, input events.TrackNewsletterLinkClickedInput) {
+	go service.trackNewsletterLinkClickedInBackground(ctx, input)
+}
+
+func (service *Service) trackNewsletterLinkClickedInBackground(ctx context.Context, input events.TrackNewsletterLinkClickedInput) {
+	now := time.Now().UTC()
+	event := events.Event{
+		Time: now,
+		Type: events.EventTypeNewsletterLinkClicked,
+		Data: events.EventDataNewsletterLinkClicked{
+			Url:       input.Url,
+			ContactID: input.ContactID,
+			Variant:   input.Variant,
+		},
+		WebsiteID:    input.WebsiteID,
+		AnonymousID:  &input.RecipientID,
+		NewsletterID: &input.NewsletterID,
+	}
+
+	service.eventsBuffer.Push(event)
+}
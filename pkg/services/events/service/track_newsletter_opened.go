@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"markdown.ninja/pkg/services/events"
+)
+
+func (service *Service) TrackNewsletterOpened(ctx context.Context, input events.TrackNewsletterOpenedInput) {
+	go service.trackNewsletterOpenedInBackground(ctx, input)
+}
+
+func (service *Service) trackNewsletterOpenedInBackground(ctx context.Context, input events.TrackNewsletterOpenedInput) {
+	now := time.Now().UTC()
+	event := events.Event{
+		Time: now,
+		Type: events.EventTypeNewsletterOpened,
+		Data: events.EventDataNewsletterOpened{
+			ContactID: input.ContactID,
+			Variant:   input.Variant,
+		},
+		WebsiteID:    input.WebsiteID,
+		AnonymousID:  &input.RecipientID,
+		NewsletterID: &input.NewsletterID,
+	}
+
+	service.eventsBuffer.Push(event)
+}
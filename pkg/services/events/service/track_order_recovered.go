@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"markdown.ninja/pkg/services/events"
+)
+
+func (service *Service) TrackOrderRecovered(ctx context.Context, input events.TrackOrderRecoveredInput) {
+	go service.trackOrderRecoveredInBackground(ctx, input)
+}
+
+func (service *Service) trackOrderRecoveredInBackground(ctx context.Context, input events.TrackOrderRecoveredInput) {
+	now := time.Now().UTC()
+	event := events.Event{
+		Time: now,
+		Type: events.EventTypeOrderRecovered,
+		Data: events.EventDataOrderRecovered{
+			TotalAmount: input.TotalAmount,
+		},
+		WebsiteID: input.WebsiteID,
+		OrderID:   &input.OrderID,
+	}
+
+	service.eventsBuffer.Push(event)
+}
@@ -1,6 +1,8 @@
 package kernel
 
 import (
+	"fmt"
+
 	"markdown.ninja/pkg/errs"
 )
 
@@ -25,6 +27,18 @@ var (
 	ErrTwoFaAlreadyEnabled = errs.InvalidArgument("2FA Already enabled.")
 	ErrTwoFaIsNotEnabled   = errs.InvalidArgument("2FA Is not enabled.")
 	ErrTwoFaCodeIsNotValid = errs.InvalidArgument("2Fa Code is not valid.")
+	ErrTwoFaIsNotSetup     = errs.InvalidArgument("Please setup 2FA before enabling it.")
+
+	ErrLocalAuthIsNotEnabled     = errs.InvalidArgument("Local accounts are not enabled on this instance.")
+	ErrOidcIsNotEnabled          = errs.InvalidArgument("OpenID Connect is not enabled on this instance.")
+	ErrOidcLoginFailed           = errs.PermissionDenied("Login with OpenID Connect failed. Please try again.")
+	ErrOidcEmailIsNotVerified    = errs.PermissionDenied("Your email address is not verified by your identity provider.")
+	ErrOidcAccountAlreadyExists  = errs.PermissionDenied("An account protected by a password or 2FA already exists with this email address. Please log in with your password.")
+	ErrPasswordIsTooShort        = errs.InvalidArgument(fmt.Sprintf("Password must be at least %d characters long", PasswordMinLength))
+	ErrPasswordIsTooLong         = errs.InvalidArgument(fmt.Sprintf("Password must be at most %d characters long", PasswordMaxLength))
+	ErrCurrentPasswordIsNotValid = errs.InvalidArgument("Current password is not valid")
+	ErrUserNameIsNotValid        = errs.InvalidArgument(fmt.Sprintf("Name is not valid. It must be between 1 and %d characters", UserNameMaxLength))
+	ErrSessionNotFound           = errs.NotFound("Session not found.")
 
 	// Users
 	ErrRegistrationBlockedInLocation = errs.PermissionDenied("Registrations are disabled in your location.")
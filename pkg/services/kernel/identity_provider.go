@@ -0,0 +1,21 @@
+package kernel
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/uuid"
+	"markdown.ninja/pkg/server/auth"
+)
+
+// IdentityProvider is responsible for authenticating the users of the webapp and for storing their
+// identity (name, email...).
+// Markdown Ninja can either delegate authentication to pingoo.io or manage users locally, in its own
+// database, which is useful for self-hosted instances.
+type IdentityProvider interface {
+	// VerifyAccessToken verifies the given access token (or session token) and returns its claims
+	VerifyAccessToken(ctx context.Context, token string) (accessToken auth.AccessToken, err error)
+	// FindUser returns ErrUserNotFound if the user doesn't exist
+	FindUser(ctx context.Context, userID uuid.UUID) (user User, err error)
+	// FindUsers returns the users found. Users that don't exist are ignored.
+	FindUsers(ctx context.Context, userIDs []uuid.UUID) (users []User, err error)
+}
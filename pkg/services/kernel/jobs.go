@@ -5,3 +5,9 @@ type JobRefreshGeoipDatabase struct{}
 func (JobRefreshGeoipDatabase) JobType() string {
 	return "kernel.refresh_geoip_database"
 }
+
+type JobDeleteExpiredAuthData struct{}
+
+func (JobDeleteExpiredAuthData) JobType() string {
+	return "kernel.delete_expired_auth_data"
+}
@@ -4,7 +4,9 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/skerkour/stdx-go/crypto"
 	"github.com/skerkour/stdx-go/uuid"
 )
 
@@ -17,6 +19,41 @@ const (
 	TotpQrCodeJPEGQuality int = 90
 
 	MaxAssetSize = 200_000_000 // 200MB
+
+	// AuthCookie is the cookie holding the session token of the users when using the local identity provider
+	AuthCookie        = "mdninja_session"
+	AuthCookieTimeout = 30 * 24 * time.Hour
+	// OidcStateCookie holds the signed state and nonce of an OpenID Connect authorization request
+	OidcStateCookie        = "mdninja_oidc_state"
+	OidcStateCookieTimeout = 15 * time.Minute
+
+	// We use only digits because a significant amount of people in the world don't have latin keyboards
+	AuthCodeAlphabet = "0123456789"
+	// AuthCodeLength is the length in characters of the code sent by email during signup or login
+	AuthCodeLength = 8
+	// AuthCodeTimeout is the duration during which a signup or login code is valid
+	AuthCodeTimeout    = time.Hour
+	AuthMaxAttempts    = 5
+	UserNameMaxLength  = 64
+	PasswordMinLength  = 10
+	PasswordMaxLength  = 512
+	MaxSessionsPerUser = 25
+)
+
+var (
+	AuthCodeHashParams = crypto.DefaultHashPasswordParams
+	PasswordHashParams = crypto.DefaultHashPasswordParams
+)
+
+type AuthStep string
+
+const (
+	// AuthStepCompleted means that the user is authenticated and the session cookie has been set
+	AuthStepCompleted AuthStep = "completed"
+	// AuthStepEmailCode means that a code has been sent by email and must be submitted with CompleteLogin
+	AuthStepEmailCode AuthStep = "email_code"
+	// AuthStepTwoFa means that a TOTP code must be submitted with Complete2faChallenge
+	AuthStepTwoFa AuthStep = "two_fa"
 )
 
 type EmptyInput struct{}
@@ -62,6 +99,63 @@ func (address *Address) Value() (driver.Value, error) {
 	return json.Marshal(address)
 }
 
+// User is a user of the webapp, independently of the identity provider used to authenticate it.
+// Some fields are only populated by the local identity provider.
+type User struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	Name    string `db:"name" json:"name"`
+	Email   string `db:"email" json:"email"`
+	IsAdmin bool   `db:"is_admin" json:"is_admin"`
+	// Argon2id hash of the password. nil if the user only logs in with email codes or OIDC
+	PasswordHash *string `db:"password_hash" json:"-"`
+	// TOTP secret encrypted with the KMS. Set when 2FA is being setup or is enabled
+	EncryptedTotpSecret []byte     `db:"encrypted_totp_secret" json:"-"`
+	TwoFaEnabled        bool       `db:"two_fa_enabled" json:"two_fa_enabled"`
+	OidcSubject         *string    `db:"oidc_subject" json:"-"`
+	BlockedAt           *time.Time `db:"blocked_at" json:"-"`
+	LastLoginAt         *time.Time `db:"last_login_at" json:"-"`
+}
+
+type PendingUser struct {
+	ID        uuid.UUID `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+
+	Name         string  `db:"name"`
+	Email        string  `db:"email"`
+	PasswordHash *string `db:"password_hash"`
+	// Argon2id hash of the signup code
+	CodeHash       string `db:"code_hash"`
+	FailedAttempts int64  `db:"failed_attempts"`
+}
+
+type Session struct {
+	ID        uuid.UUID `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+
+	// BLAKE3
+	SecretHash []byte `db:"secret_hash"`
+	// Argon2id hash of the login code sent by email. Empty if the user logged in with a password or OIDC
+	CodeHash       string `db:"code_hash"`
+	FailedAttempts int64  `db:"failed_attempts"`
+	TwoFaPending   bool   `db:"two_fa_pending"`
+	Verified       bool   `db:"verified"`
+	Ip             string `db:"ip"`
+	UserAgent      string `db:"user_agent"`
+	CountryCode    string `db:"country_code"`
+
+	UserID uuid.UUID `db:"user_id"`
+}
+
+type UserAndSession struct {
+	User    User    `db:""`
+	Session Session `db:"session"`
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 // Service
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -73,9 +167,20 @@ type InitData struct {
 	Pricing         []Plan `json:"pricing"`
 	// ChallengeSiteKey *string `json:"challenge_site_key"`
 	Pingoo          InitDataPingoo `json:"pingoo"`
+	Auth            InitDataAuth   `json:"auth"`
 	WebsitesBaseUrl string         `json:"websites_base_url"`
 }
 
+type InitDataAuth struct {
+	Provider     string            `json:"provider"`
+	AllowSignups bool              `json:"allow_signups"`
+	Oidc         *InitDataAuthOidc `json:"oidc"`
+}
+
+type InitDataAuthOidc struct {
+	Name string `json:"name"`
+}
+
 type InitDataPingoo struct {
 	AppID    string `json:"app_id"`
 	Endpoint string `json:"endpoint"`
@@ -88,3 +193,72 @@ type DeleteBackgroundJobInput struct {
 type ChallengeSiteKey struct {
 	SiteKey *string `json:"site_key"`
 }
+
+type SignupInput struct {
+	Name     string  `json:"name"`
+	Email    string  `json:"email"`
+	Password *string `json:"password"`
+}
+
+type SignupOutput struct {
+	PendingUserID uuid.UUID `json:"pending_user_id"`
+}
+
+type CompleteSignupInput struct {
+	PendingUserID uuid.UUID `json:"pending_user_id"`
+	Code          string    `json:"code"`
+}
+
+type LoginInput struct {
+	Email string `json:"email"`
+	// if Password is nil, a login code is sent by email
+	Password *string `json:"password"`
+}
+
+type LoginOutput struct {
+	SessionID uuid.UUID `json:"session_id"`
+	Step      AuthStep  `json:"step"`
+}
+
+type CompleteLoginInput struct {
+	SessionID uuid.UUID `json:"session_id"`
+	Code      string    `json:"code"`
+}
+
+type Complete2faChallengeInput struct {
+	SessionID uuid.UUID `json:"session_id"`
+	Code      string    `json:"code"`
+}
+
+type SetupTwoFaOutput struct {
+	Secret string `json:"secret"`
+	// base64 encoded JPEG image
+	QrCode string `json:"qr_code"`
+}
+
+type EnableTwoFaInput struct {
+	Code string `json:"code"`
+}
+
+type DisableTwoFaInput struct {
+	Code string `json:"code"`
+}
+
+type UpdateMyPasswordInput struct {
+	// CurrentPassword is required only if the user already has a password
+	CurrentPassword *string `json:"current_password"`
+	NewPassword     string  `json:"new_password"`
+}
+
+type UserSession struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Ip          string    `json:"ip"`
+	UserAgent   string    `json:"user_agent"`
+	CountryCode string    `json:"country_code"`
+	Current     bool      `json:"current"`
+}
+
+type RevokeSessionInput struct {
+	ID uuid.UUID `json:"id"`
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/uuid"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+func (repo *KernelRepository) CreatePendingUser(ctx context.Context, db db.Queryer, pendingUser kernel.PendingUser) (err error) {
+	const query = `INSERT INTO pending_users
+	(id, created_at, updated_at, name, email, password_hash, code_hash, failed_attempts)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err = db.Exec(ctx, query, pendingUser.ID, pendingUser.CreatedAt, pendingUser.UpdatedAt, pendingUser.Name,
+		pendingUser.Email, pendingUser.PasswordHash, pendingUser.CodeHash, pendingUser.FailedAttempts)
+	if err != nil {
+		err = fmt.Errorf("kernel.CreatePendingUser: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) UpdatePendingUser(ctx context.Context, db db.Queryer, pendingUser kernel.PendingUser) (err error) {
+	const query = `UPDATE pending_users
+		SET updated_at = $1, failed_attempts = $2
+		WHERE id = $3`
+
+	_, err = db.Exec(ctx, query, pendingUser.UpdatedAt, pendingUser.FailedAttempts, pendingUser.ID)
+	if err != nil {
+		err = fmt.Errorf("kernel.UpdatePendingUser: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) FindPendingUserByID(ctx context.Context, db db.Queryer, pendingUserID uuid.UUID) (pendingUser kernel.PendingUser, err error) {
+	const query = "SELECT * FROM pending_users WHERE id = $1"
+
+	err = db.Get(ctx, &pendingUser, query, pendingUserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = kernel.ErrPendingEmailNotFound
+		} else {
+			err = fmt.Errorf("kernel.FindPendingUserByID: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) DeletePendingUser(ctx context.Context, db db.Queryer, pendingUserID uuid.UUID) (err error) {
+	const query = `DELETE FROM pending_users WHERE id = $1`
+
+	_, err = db.Exec(ctx, query, pendingUserID)
+	if err != nil {
+		err = fmt.Errorf("kernel.DeletePendingUser: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) DeleteOldPendingUsers(ctx context.Context, db db.Queryer, before time.Time) (err error) {
+	const query = `DELETE FROM pending_users WHERE created_at <= $1`
+
+	_, err = db.Exec(ctx, query, before)
+	if err != nil {
+		err = fmt.Errorf("kernel.DeleteOldPendingUsers: %w", err)
+		return
+	}
+
+	return
+}
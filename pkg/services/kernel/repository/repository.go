@@ -0,0 +1,7 @@
+package repository
+
+type KernelRepository struct{}
+
+func NewKernelRepository() KernelRepository {
+	return KernelRepository{}
+}
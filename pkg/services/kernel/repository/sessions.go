@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/uuid"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+func (repo *KernelRepository) CreateSession(ctx context.Context, db db.Queryer, session kernel.Session) (err error) {
+	const query = `INSERT INTO sessions
+	(id, created_at, updated_at, secret_hash, code_hash, failed_attempts, two_fa_pending, verified, ip,
+		user_agent, country_code, user_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	_, err = db.Exec(ctx, query, session.ID, session.CreatedAt, session.UpdatedAt, session.SecretHash,
+		session.CodeHash, session.FailedAttempts, session.TwoFaPending, session.Verified, session.Ip,
+		session.UserAgent, session.CountryCode, session.UserID)
+	if err != nil {
+		err = fmt.Errorf("kernel.CreateSession: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) UpdateSession(ctx context.Context, db db.Queryer, session kernel.Session) (err error) {
+	const query = `UPDATE sessions
+		SET updated_at = $1, secret_hash = $2, code_hash = $3, failed_attempts = $4, two_fa_pending = $5,
+			verified = $6
+		WHERE id = $7`
+
+	_, err = db.Exec(ctx, query, session.UpdatedAt, session.SecretHash, session.CodeHash, session.FailedAttempts,
+		session.TwoFaPending, session.Verified,
+		session.ID,
+	)
+	if err != nil {
+		err = fmt.Errorf("kernel.UpdateSession: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) FindSessionByID(ctx context.Context, db db.Queryer, sessionID uuid.UUID) (session kernel.Session, err error) {
+	const query = "SELECT * FROM sessions WHERE id = $1"
+
+	err = db.Get(ctx, &session, query, sessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = kernel.ErrSessionNotFound
+		} else {
+			err = fmt.Errorf("kernel.FindSessionByID: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) FindVerifiedSessionsForUser(ctx context.Context, db db.Queryer, userID uuid.UUID) (sessions []kernel.Session, err error) {
+	sessions = []kernel.Session{}
+	const query = `SELECT * FROM sessions
+		WHERE user_id = $1 AND verified = $2
+		ORDER BY id DESC`
+
+	err = db.Select(ctx, &sessions, query, userID, true)
+	if err != nil {
+		err = fmt.Errorf("kernel.FindVerifiedSessionsForUser: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) FindUserWithSession(ctx context.Context, db db.Queryer, sessionID uuid.UUID) (userAndSession kernel.UserAndSession, err error) {
+	const query = `SELECT users.*,
+			sessions.id AS "session.id",
+			sessions.created_at AS "session.created_at",
+			sessions.updated_at AS "session.updated_at",
+			sessions.secret_hash AS "session.secret_hash",
+			sessions.code_hash AS "session.code_hash",
+			sessions.failed_attempts AS "session.failed_attempts",
+			sessions.two_fa_pending AS "session.two_fa_pending",
+			sessions.verified AS "session.verified",
+			sessions.ip AS "session.ip",
+			sessions.user_agent AS "session.user_agent",
+			sessions.country_code AS "session.country_code",
+			sessions.user_id AS "session.user_id"
+		FROM sessions
+		INNER JOIN users ON sessions.user_id = users.id
+		WHERE sessions.id = $1
+		`
+
+	err = db.Get(ctx, &userAndSession, query, sessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = kernel.ErrSessionNotFound
+		} else {
+			err = fmt.Errorf("kernel.FindUserWithSession: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) DeleteSession(ctx context.Context, db db.Queryer, sessionID uuid.UUID) (err error) {
+	const query = `DELETE FROM sessions WHERE id = $1`
+
+	_, err = db.Exec(ctx, query, sessionID)
+	if err != nil {
+		err = fmt.Errorf("kernel.DeleteSession: %w", err)
+		return
+	}
+
+	return
+}
+
+// DeleteOlderVerifiedSessionsForUser deletes the oldest sessions of the given user to keep at most
+// `keep` active sessions
+func (repo *KernelRepository) DeleteOlderVerifiedSessionsForUser(ctx context.Context, db db.Queryer, userID uuid.UUID, keep int64) (err error) {
+	const query = `DELETE FROM sessions
+		WHERE id = ANY (
+			SELECT id FROM sessions
+				WHERE user_id = $1 AND verified = $2
+				ORDER BY id DESC OFFSET $3
+		)
+		`
+
+	_, err = db.Exec(ctx, query, userID, true, keep)
+	if err != nil {
+		err = fmt.Errorf("kernel.DeleteOlderVerifiedSessionsForUser: %w", err)
+		return
+	}
+
+	return
+}
+
+// DeleteOtherSessionsForUser deletes all the sessions of the user except the given one.
+// Used when the password or the 2FA settings of an account change.
+func (repo *KernelRepository) DeleteOtherSessionsForUser(ctx context.Context, db db.Queryer, userID, currentSessionID uuid.UUID) (err error) {
+	const query = `DELETE FROM sessions WHERE user_id = $1 AND id != $2`
+
+	_, err = db.Exec(ctx, query, userID, currentSessionID)
+	if err != nil {
+		err = fmt.Errorf("kernel.DeleteOtherSessionsForUser: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) DeleteExpiredSessions(ctx context.Context, db db.Queryer, unverifiedBefore, verifiedBefore time.Time) (err error) {
+	const query = `DELETE FROM sessions
+		WHERE (verified = false AND created_at <= $1) OR created_at <= $2`
+
+	_, err = db.Exec(ctx, query, unverifiedBefore, verifiedBefore)
+	if err != nil {
+		err = fmt.Errorf("kernel.DeleteExpiredSessions: %w", err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/uuid"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+func (repo *KernelRepository) CreateUser(ctx context.Context, db db.Queryer, user kernel.User) (err error) {
+	const query = `INSERT INTO users
+	(id, created_at, updated_at, name, email, is_admin, password_hash, encrypted_totp_secret, two_fa_enabled,
+		oidc_subject, blocked_at, last_login_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	_, err = db.Exec(ctx, query, user.ID, user.CreatedAt, user.UpdatedAt, user.Name, user.Email, user.IsAdmin,
+		user.PasswordHash, user.EncryptedTotpSecret, user.TwoFaEnabled, user.OidcSubject, user.BlockedAt,
+		user.LastLoginAt)
+	if err != nil {
+		err = fmt.Errorf("kernel.CreateUser: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) UpdateUser(ctx context.Context, db db.Queryer, user kernel.User) (err error) {
+	const query = `UPDATE users
+		SET updated_at = $1, name = $2, email = $3, is_admin = $4, password_hash = $5, encrypted_totp_secret = $6,
+			two_fa_enabled = $7, oidc_subject = $8, blocked_at = $9, last_login_at = $10
+		WHERE id = $11`
+
+	_, err = db.Exec(ctx, query, user.UpdatedAt, user.Name, user.Email, user.IsAdmin, user.PasswordHash,
+		user.EncryptedTotpSecret, user.TwoFaEnabled, user.OidcSubject, user.BlockedAt, user.LastLoginAt,
+		user.ID,
+	)
+	if err != nil {
+		err = fmt.Errorf("kernel.UpdateUser: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) FindUserByID(ctx context.Context, db db.Queryer, userID uuid.UUID) (user kernel.User, err error) {
+	const query = "SELECT * FROM users WHERE id = $1"
+
+	err = db.Get(ctx, &user, query, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = kernel.ErrUserNotFound
+		} else {
+			err = fmt.Errorf("kernel.FindUserByID: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) FindUserByEmail(ctx context.Context, db db.Queryer, email string) (user kernel.User, err error) {
+	const query = "SELECT * FROM users WHERE email = $1"
+
+	err = db.Get(ctx, &user, query, email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = kernel.ErrUserNotFound
+		} else {
+			err = fmt.Errorf("kernel.FindUserByEmail: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) FindUserByOidcSubject(ctx context.Context, db db.Queryer, subject string) (user kernel.User, err error) {
+	const query = "SELECT * FROM users WHERE oidc_subject = $1"
+
+	err = db.Get(ctx, &user, query, subject)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = kernel.ErrUserNotFound
+		} else {
+			err = fmt.Errorf("kernel.FindUserByOidcSubject: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (repo *KernelRepository) FindUsersByIDs(ctx context.Context, db db.Queryer, userIDs []uuid.UUID) (users []kernel.User, err error) {
+	users = []kernel.User{}
+	const query = "SELECT * FROM users WHERE id = ANY($1)"
+
+	err = db.Select(ctx, &users, query, userIDs)
+	if err != nil {
+		err = fmt.Errorf("kernel.FindUsersByIDs: %w", err)
+		return
+	}
+
+	return
+}
@@ -2,10 +2,12 @@ package kernel
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/skerkour/stdx-go/queue"
 	"github.com/skerkour/stdx-go/uuid"
 	"markdown.ninja/pingoo-go"
+	"markdown.ninja/pkg/server/auth"
 )
 
 type Service interface {
@@ -17,6 +19,26 @@ type PublicService interface {
 	// The Init function is called by the frontend on load to load the data it needs
 	Init(ctx context.Context, input EmptyInput) (ret InitData, err error)
 
+	// Auth
+	// The following functions are only available when using the local identity provider
+	Signup(ctx context.Context, input SignupInput) (ret SignupOutput, err error)
+	CompleteSignup(ctx context.Context, input CompleteSignupInput) (ret User, err error)
+	Login(ctx context.Context, input LoginInput) (ret LoginOutput, err error)
+	CompleteLogin(ctx context.Context, input CompleteLoginInput) (ret LoginOutput, err error)
+	Complete2faChallenge(ctx context.Context, input Complete2faChallengeInput) (ret LoginOutput, err error)
+	Logout(ctx context.Context, input EmptyInput) (err error)
+	ServeOidcLogin(res http.ResponseWriter, req *http.Request)
+	ServeOidcCallback(res http.ResponseWriter, req *http.Request)
+
+	// Users
+	GetMe(ctx context.Context, input EmptyInput) (ret User, err error)
+	UpdateMyPassword(ctx context.Context, input UpdateMyPasswordInput) (err error)
+	SetupTwoFa(ctx context.Context, input EmptyInput) (ret SetupTwoFaOutput, err error)
+	EnableTwoFa(ctx context.Context, input EnableTwoFaInput) (err error)
+	DisableTwoFa(ctx context.Context, input DisableTwoFaInput) (err error)
+	ListMySessions(ctx context.Context, input EmptyInput) (ret PaginatedResult[UserSession], err error)
+	RevokeSession(ctx context.Context, input RevokeSessionInput) (err error)
+
 	// Handlers
 	Healthcheck(ctx context.Context, input EmptyInput) (err error)
 	HandlePingooWebhook(ctx context.Context, event pingoo.Event) (err error)
@@ -30,6 +52,9 @@ type PrivateService interface {
 	// utils
 	CurrentUserID(ctx context.Context) (userID uuid.UUID, err error)
 	ValidateEmail(ctx context.Context, emailAddress string, rejectBlockedDomains bool) (err error)
+	// LookupEmails looks up the emails with Pingoo if enabled. Otherwise only the syntax and the MX records
+	// of the emails are checked.
+	LookupEmails(ctx context.Context, input pingoo.LookupEmailsInput) (ret []pingoo.EmailInfo, err error)
 	// SleepAuth sleeps for a small random amount of time to prevent timing attacks
 	// and bruteforce
 	SleepAuth()
@@ -38,6 +63,13 @@ type PrivateService interface {
 	SleepAuthFailure()
 	ValidateColor(color string) (err error)
 
+	// Users
+	// VerifyAccessToken verifies the access token of a user with the configured identity provider
+	VerifyAccessToken(ctx context.Context, token string) (accessToken auth.AccessToken, err error)
+	FindUser(ctx context.Context, userID uuid.UUID) (user User, err error)
+	FindUsers(ctx context.Context, userIDs []uuid.UUID) (users []User, err error)
+	GenerateLogoutCookie() (cookie http.Cookie)
+
 	// Admin
 	// AdminGetAllUsers(ctx context.Context) (users []User, err error)
 	// AdminBlockUser(ctx context.Context, input AdminBlockUserInput) (err error)
@@ -45,6 +77,8 @@ type PrivateService interface {
 	// AdminFindUser(ctx context.Context, userID guid.GUID) (user User, err error)
 
 	// Jobs
+	JobDeleteExpiredAuthData(ctx context.Context, input JobDeleteExpiredAuthData) (err error)
 
 	// Tasks
+	TaskDeleteExpiredAuthData(ctx context.Context)
 }
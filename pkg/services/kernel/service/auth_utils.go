@@ -1,10 +1,50 @@
 package service
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"html/template"
 	mathrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/skerkour/stdx-go/crypto"
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/queue"
+	"github.com/skerkour/stdx-go/randutil"
+	"github.com/skerkour/stdx-go/uuid"
+	"github.com/zeebo/blake3"
+	"markdown.ninja/cmd/mdninja-server/config"
+	"markdown.ninja/pkg/jwt"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/kernel"
+	"markdown.ninja/pkg/services/kernel/templates"
+)
+
+const (
+	sessionSecretSize = 32
+	sessionHashSize   = 32
 )
 
+type sessionJwtClaims struct {
+	UserID    uuid.UUID `json:"user_id"`
+	SessionID uuid.UUID `json:"session_id"`
+	Secret    []byte    `json:"secret"`
+}
+
+type newSessionToken struct {
+	token string
+	hash  [sessionHashSize]byte
+}
+
 // func (service *KernelService) FormatCodeHyphen(code string) (ret string) {
 // 	codeLength := len(code)
 // 	for i := 0; i < codeLength; i += 1 {
@@ -27,3 +67,262 @@ func (service *KernelService) SleepAuth() {
 func (service *KernelService) SleepAuthFailure() {
 	time.Sleep(time.Duration(mathrand.Int64N(400)+400) * time.Millisecond)
 }
+
+func (service *KernelService) checkLocalAuthIsEnabled() error {
+	if service.authConfig.Provider != config.AuthProviderLocal {
+		return kernel.ErrLocalAuthIsNotEnabled
+	}
+	return nil
+}
+
+// currentSessionID returns the ID of the session of the user authenticated with the local identity provider
+func (service *KernelService) currentSessionID(ctx context.Context) (sessionID uuid.UUID, err error) {
+	httpCtx := httpctx.FromCtx(ctx)
+	if httpCtx == nil || httpCtx.AccessToken == nil {
+		err = kernel.ErrAuthenticationRequired
+		return
+	}
+
+	sessionID, err = uuid.Parse(httpCtx.AccessToken.JwtID)
+	if err != nil {
+		err = kernel.ErrSessionIsNotValid
+		return
+	}
+
+	return sessionID, nil
+}
+
+func (service *KernelService) isAdminEmail(email string) bool {
+	return slices.Contains(service.authConfig.Admins, email)
+}
+
+func (service *KernelService) generateAuthCode() (code string, codeHash string) {
+	randomGenerator := crypto.NewRandomGenerator()
+	codeBytes := randutil.RandAlphabet(randomGenerator, []byte(kernel.AuthCodeAlphabet), kernel.AuthCodeLength)
+	codeHash = crypto.HashPassword(codeBytes, kernel.AuthCodeHashParams)
+	return string(codeBytes), codeHash
+}
+
+func validatePassword(password string) error {
+	passwordLength := utf8.RuneCountInString(password)
+	if passwordLength < kernel.PasswordMinLength {
+		return kernel.ErrPasswordIsTooShort
+	}
+	if passwordLength > kernel.PasswordMaxLength {
+		return kernel.ErrPasswordIsTooLong
+	}
+	return nil
+}
+
+func validateUserName(name string) error {
+	nameLength := utf8.RuneCountInString(name)
+	if nameLength == 0 || nameLength > kernel.UserNameMaxLength || !utf8.ValidString(name) ||
+		strings.ContainsAny(name, "\n\r\t<>") {
+		return kernel.ErrUserNameIsNotValid
+	}
+	return nil
+}
+
+// newUnverifiedSession returns a new session that is not yet usable to authenticate requests.
+// It needs to be completed with completeSession.
+func (service *KernelService) newUnverifiedSession(ctx context.Context, userID uuid.UUID, codeHash string, twoFaPending bool) kernel.Session {
+	httpCtx := httpctx.FromCtx(ctx)
+	now := time.Now().UTC()
+
+	return kernel.Session{
+		ID:             uuid.NewV7(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		SecretHash:     []byte{},
+		CodeHash:       codeHash,
+		FailedAttempts: 0,
+		TwoFaPending:   twoFaPending,
+		Verified:       false,
+		Ip:             httpCtx.Client.IPStr,
+		UserAgent:      httpCtx.Client.UserAgent,
+		CountryCode:    httpCtx.Client.CountryCode,
+		UserID:         userID,
+	}
+}
+
+// completeSession marks the session as verified, updates the user's last login time and sets the
+// session cookie in the response.
+func (service *KernelService) completeSession(ctx context.Context, tx db.Queryer, user *kernel.User, session *kernel.Session) (err error) {
+	httpCtx := httpctx.FromCtx(ctx)
+	now := time.Now().UTC()
+	isFirstLogin := user.LastLoginAt == nil
+
+	sessionToken, err := service.generateSessionToken(user.ID, session.ID)
+	if err != nil {
+		return
+	}
+
+	session.UpdatedAt = now
+	session.SecretHash = sessionToken.hash[:]
+	session.CodeHash = ""
+	session.TwoFaPending = false
+	session.Verified = true
+	err = service.repo.UpdateSession(ctx, tx, *session)
+	if err != nil {
+		return
+	}
+
+	user.LastLoginAt = &now
+	user.UpdatedAt = now
+	err = service.repo.UpdateUser(ctx, tx, *user)
+	if err != nil {
+		return
+	}
+
+	err = service.repo.DeleteOlderVerifiedSessionsForUser(ctx, tx, user.ID, kernel.MaxSessionsPerUser)
+	if err != nil {
+		return
+	}
+
+	httpCtx.Response.Cookies = append(httpCtx.Response.Cookies, service.generateSessionCookie(sessionToken.token))
+
+	if !isFirstLogin {
+		var htmlContent bytes.Buffer
+		err = service.loginAlertEmailTemplate.Execute(&htmlContent, templates.LoginAlertEmailData{
+			Name: user.Name,
+			Time: now,
+		})
+		if err != nil {
+			return fmt.Errorf("kernel: executing login alert email template: %w", err)
+		}
+		service.sendEmail(ctx, user.Name, user.Email, "New login to your Markdown Ninja account", htmlContent.String(), nil)
+	}
+
+	return nil
+}
+
+func (service *KernelService) failSessionAttempt(ctx context.Context, session kernel.Session) {
+	logger := slogx.FromCtx(ctx)
+
+	session.FailedAttempts += 1
+	session.UpdatedAt = time.Now().UTC()
+	err := service.repo.UpdateSession(ctx, service.db, session)
+	if err != nil {
+		logger.Error("kernel.failSessionAttempt: error updating session", slogx.Err(err))
+	}
+}
+
+// sendEmail pushes a job to send a transactional email. Errors are only logged.
+func (service *KernelService) sendEmail(ctx context.Context, toName, toAddress, subject, bodyHtml string, bodyText *string) {
+	logger := slogx.FromCtx(ctx)
+
+	job := queue.NewJobInput{
+		Data: emails.JobSendEmail{
+			Type: emails.EmailTypeTransactional,
+			// left empty because it's a transactional email
+			FromAddress: "",
+			FromName:    "",
+			ToAddress:   toAddress,
+			ToName:      toName,
+			Subject:     subject,
+			BodyHtml:    bodyHtml,
+			BodyText:    bodyText,
+		},
+	}
+	err := service.queue.Push(ctx, nil, job)
+	if err != nil {
+		logger.Error("kernel.sendEmail: Pushing job to queue", slogx.Err(err))
+	}
+}
+
+func (service *KernelService) sendCodeEmail(ctx context.Context, emailTemplate *template.Template, toName, toAddress, subject, code, link string) (err error) {
+	var htmlContent bytes.Buffer
+
+	// SignupEmailData and LoginEmailData have the same fields
+	err = emailTemplate.Execute(&htmlContent, templates.SignupEmailData{
+		Code: template.HTML(code),
+		Link: template.URL(link),
+	})
+	if err != nil {
+		return fmt.Errorf("kernel: executing email template: %w", err)
+	}
+
+	textContent := fmt.Sprintf(`
+%s
+
+or
+
+%s`, code, link)
+	service.sendEmail(ctx, toName, toAddress, subject, htmlContent.String(), &textContent)
+	return nil
+}
+
+func (service *KernelService) generateWebappLink(path string, query url.Values) string {
+	link := *service.config.HTTP.WebappBaseUrl
+	link.Path = path
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+func (service *KernelService) generateSessionCookie(sessionToken string) (cookie http.Cookie) {
+	cookie = http.Cookie{
+		Name:     kernel.AuthCookie,
+		Value:    sessionToken,
+		Expires:  time.Now().Add(kernel.AuthCookieTimeout),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+		Path:     "/",
+	}
+	return
+}
+
+func (service *KernelService) GenerateLogoutCookie() (cookie http.Cookie) {
+	cookie = http.Cookie{
+		Name:     kernel.AuthCookie,
+		Value:    "",
+		Expires:  time.Unix(0, 0),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+		Path:     "/",
+	}
+	return
+}
+
+func (service *KernelService) generateSessionToken(userID, sessionID uuid.UUID) (token newSessionToken, err error) {
+	// Add one hour to account for clock drift
+	sessionJwtExpiresAt := time.Now().UTC().Add(kernel.AuthCookieTimeout).Add(1 * time.Hour)
+
+	// secret
+	var secret [sessionSecretSize]byte
+
+	_, err = rand.Read(secret[:])
+	if err != nil {
+		err = fmt.Errorf("error generating new session secret: %w", err)
+		return
+	}
+
+	// token
+	jwtClaims := sessionJwtClaims{
+		UserID:    userID,
+		SessionID: sessionID,
+		Secret:    secret[:],
+	}
+	token.token, err = service.jwtProvider.NewSignedToken(jwtClaims, &jwt.TokenOptions{
+		ExpirationTime: &sessionJwtExpiresAt,
+	})
+	if err != nil {
+		err = fmt.Errorf("generating session JWT: %w", err)
+		return
+	}
+
+	// hash
+	token.hash = generateSessionHash(userID, sessionID, secret[:])
+
+	return token, nil
+}
+
+func generateSessionHash(userID, sessionID uuid.UUID, secret []byte) (out [sessionHashSize]byte) {
+	hasher := blake3.New()
+	hasher.Write(userID.Bytes())
+	hasher.Write(sessionID.Bytes())
+	hasher.Write(secret)
+	hasher.Sum(out[:0])
+	return
+}
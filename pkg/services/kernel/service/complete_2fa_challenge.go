@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+func (service *KernelService) Complete2faChallenge(ctx context.Context, input kernel.Complete2faChallengeInput) (ret kernel.LoginOutput, err error) {
+	err = service.checkLocalAuthIsEnabled()
+	if err != nil {
+		return
+	}
+
+	if _, userErr := service.CurrentUserID(ctx); userErr == nil {
+		err = kernel.ErrMustNotBeAuthenticated
+		return
+	}
+
+	service.SleepAuth()
+
+	now := time.Now().UTC()
+
+	userAndSession, err := service.repo.FindUserWithSession(ctx, service.db, input.SessionID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			service.SleepAuthFailure()
+			err = kernel.ErrSessionIsNotValid
+		}
+		return
+	}
+	user := userAndSession.User
+	session := userAndSession.Session
+
+	if session.Verified || !session.TwoFaPending || !user.TwoFaEnabled {
+		service.SleepAuthFailure()
+		err = kernel.ErrSessionIsNotValid
+		return
+	}
+
+	if session.FailedAttempts >= kernel.AuthMaxAttempts {
+		service.SleepAuthFailure()
+		err = kernel.ErrMaxLoginAttempsReached
+		return
+	}
+
+	if now.Sub(session.CreatedAt) >= kernel.AuthCodeTimeout {
+		service.SleepAuthFailure()
+		err = kernel.ErrLoginCodeExpired
+		return
+	}
+
+	codeIsValid, err := service.verifyTotpCode(ctx, user, input.Code)
+	if err != nil {
+		return
+	}
+	if !codeIsValid {
+		service.failSessionAttempt(ctx, session)
+		service.SleepAuthFailure()
+		err = kernel.ErrTwoFaCodeIsNotValid
+		return
+	}
+
+	if user.BlockedAt != nil {
+		err = kernel.ErrUserIsBlocked
+		return
+	}
+
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		return service.completeSession(ctx, tx, &user, &session)
+	})
+	if err != nil {
+		return
+	}
+
+	ret = kernel.LoginOutput{
+		SessionID: session.ID,
+		Step:      kernel.AuthStepCompleted,
+	}
+	return ret, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/crypto"
+	"github.com/skerkour/stdx-go/db"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+// CompleteLogin verifies the code sent by email by Login
+func (service *KernelService) CompleteLogin(ctx context.Context, input kernel.CompleteLoginInput) (ret kernel.LoginOutput, err error) {
+	err = service.checkLocalAuthIsEnabled()
+	if err != nil {
+		return
+	}
+
+	if _, userErr := service.CurrentUserID(ctx); userErr == nil {
+		err = kernel.ErrMustNotBeAuthenticated
+		return
+	}
+
+	service.SleepAuth()
+
+	code := strings.TrimSpace(input.Code)
+	now := time.Now().UTC()
+
+	userAndSession, err := service.repo.FindUserWithSession(ctx, service.db, input.SessionID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			service.SleepAuthFailure()
+			err = kernel.ErrLoginCodeExpired
+		}
+		return
+	}
+	user := userAndSession.User
+	session := userAndSession.Session
+
+	if session.Verified || session.TwoFaPending || session.CodeHash == "" {
+		service.SleepAuthFailure()
+		err = kernel.ErrLoginCodeExpired
+		return
+	}
+
+	if session.FailedAttempts >= kernel.AuthMaxAttempts {
+		service.SleepAuthFailure()
+		err = kernel.ErrMaxLoginAttempsReached
+		return
+	}
+
+	if now.Sub(session.CreatedAt) >= kernel.AuthCodeTimeout {
+		service.SleepAuthFailure()
+		err = kernel.ErrLoginCodeExpired
+		return
+	}
+
+	if !crypto.VerifyPasswordHash([]byte(code), session.CodeHash) {
+		service.failSessionAttempt(ctx, session)
+		service.SleepAuthFailure()
+		err = kernel.ErrAuthCodeIsNotValid
+		return
+	}
+
+	if user.BlockedAt != nil {
+		err = kernel.ErrUserIsBlocked
+		return
+	}
+
+	ret = kernel.LoginOutput{
+		SessionID: session.ID,
+		Step:      kernel.AuthStepTwoFa,
+	}
+
+	if user.TwoFaEnabled {
+		// the code can't be reused and the attempts are reset for the 2FA challenge
+		session.CodeHash = ""
+		session.FailedAttempts = 0
+		session.TwoFaPending = true
+		session.UpdatedAt = now
+		err = service.repo.UpdateSession(ctx, service.db, session)
+		if err != nil {
+			return
+		}
+		return ret, nil
+	}
+
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		return service.completeSession(ctx, tx, &user, &session)
+	})
+	if err != nil {
+		return
+	}
+
+	ret.Step = kernel.AuthStepCompleted
+	return ret, nil
+}
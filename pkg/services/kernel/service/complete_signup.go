@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/crypto"
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/uuid"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+func (service *KernelService) CompleteSignup(ctx context.Context, input kernel.CompleteSignupInput) (user kernel.User, err error) {
+	err = service.checkLocalAuthIsEnabled()
+	if err != nil {
+		return
+	}
+
+	if _, userErr := service.CurrentUserID(ctx); userErr == nil {
+		err = kernel.ErrMustNotBeAuthenticated
+		return
+	}
+
+	service.SleepAuth()
+
+	code := strings.TrimSpace(input.Code)
+	now := time.Now().UTC()
+
+	pendingUser, err := service.repo.FindPendingUserByID(ctx, service.db, input.PendingUserID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			service.SleepAuthFailure()
+			err = kernel.ErrSignupCodeExpired
+		}
+		return
+	}
+
+	if pendingUser.FailedAttempts >= kernel.AuthMaxAttempts {
+		service.SleepAuthFailure()
+		err = kernel.ErrMaxSignupAttempsReached
+		return
+	}
+
+	if now.Sub(pendingUser.CreatedAt) >= kernel.AuthCodeTimeout {
+		service.SleepAuthFailure()
+		err = kernel.ErrSignupCodeExpired
+		return
+	}
+
+	if !crypto.VerifyPasswordHash([]byte(code), pendingUser.CodeHash) {
+		pendingUser.FailedAttempts += 1
+		pendingUser.UpdatedAt = now
+		_ = service.repo.UpdatePendingUser(ctx, service.db, pendingUser)
+		service.SleepAuthFailure()
+		err = kernel.ErrAuthCodeIsNotValid
+		return
+	}
+
+	user = kernel.User{
+		ID:                  uuid.NewV7(),
+		CreatedAt:           now,
+		UpdatedAt:           now,
+		Name:                pendingUser.Name,
+		Email:               pendingUser.Email,
+		IsAdmin:             service.isAdminEmail(pendingUser.Email),
+		PasswordHash:        pendingUser.PasswordHash,
+		EncryptedTotpSecret: nil,
+		TwoFaEnabled:        false,
+		OidcSubject:         nil,
+		BlockedAt:           nil,
+		LastLoginAt:         nil,
+	}
+	session := service.newUnverifiedSession(ctx, user.ID, "", false)
+
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		// the email may have been registered in the meantime
+		_, txErr = service.repo.FindUserByEmail(ctx, tx, user.Email)
+		if txErr == nil {
+			return kernel.ErrEmailAlreadyInUse
+		} else if !errs.IsNotFound(txErr) {
+			return txErr
+		}
+
+		txErr = service.repo.CreateUser(ctx, tx, user)
+		if txErr != nil {
+			return txErr
+		}
+
+		txErr = service.repo.DeletePendingUser(ctx, tx, pendingUser.ID)
+		if txErr != nil {
+			return txErr
+		}
+
+		txErr = service.repo.CreateSession(ctx, tx, session)
+		if txErr != nil {
+			return txErr
+		}
+
+		return service.completeSession(ctx, tx, &user, &session)
+	})
+	if err != nil {
+		return
+	}
+
+	return user, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"markdown.ninja/pkg/services/kernel"
+	"markdown.ninja/pkg/services/kernel/templates"
+)
+
+func (service *KernelService) DisableTwoFa(ctx context.Context, input kernel.DisableTwoFaInput) (err error) {
+	user, err := service.currentLocalUser(ctx)
+	if err != nil {
+		return
+	}
+
+	if !user.TwoFaEnabled {
+		err = kernel.ErrTwoFaIsNotEnabled
+		return
+	}
+
+	codeIsValid, err := service.verifyTotpCode(ctx, user, input.Code)
+	if err != nil {
+		return
+	}
+	if !codeIsValid {
+		service.SleepAuthFailure()
+		err = kernel.ErrTwoFaCodeIsNotValid
+		return
+	}
+
+	user.TwoFaEnabled = false
+	user.EncryptedTotpSecret = nil
+	user.UpdatedAt = time.Now().UTC()
+	err = service.repo.UpdateUser(ctx, service.db, user)
+	if err != nil {
+		return
+	}
+
+	var htmlContent bytes.Buffer
+	err = service.twoFaDisabledAlertEmailTemplate.Execute(&htmlContent, templates.TwoFaDisabledEmailData{
+		Name: user.Name,
+	})
+	if err != nil {
+		return fmt.Errorf("kernel.DisableTwoFa: executing email template: %w", err)
+	}
+	service.sendEmail(ctx, user.Name, user.Email, "2FA has been disabled for your Markdown Ninja account", htmlContent.String(), nil)
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+func (service *KernelService) EnableTwoFa(ctx context.Context, input kernel.EnableTwoFaInput) (err error) {
+	user, err := service.currentLocalUser(ctx)
+	if err != nil {
+		return
+	}
+
+	sessionID, err := service.currentSessionID(ctx)
+	if err != nil {
+		return
+	}
+
+	if user.TwoFaEnabled {
+		err = kernel.ErrTwoFaAlreadyEnabled
+		return
+	}
+
+	codeIsValid, err := service.verifyTotpCode(ctx, user, input.Code)
+	if err != nil {
+		return
+	}
+	if !codeIsValid {
+		service.SleepAuthFailure()
+		err = kernel.ErrTwoFaCodeIsNotValid
+		return
+	}
+
+	user.TwoFaEnabled = true
+	user.UpdatedAt = time.Now().UTC()
+
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		txErr = service.repo.UpdateUser(ctx, tx, user)
+		if txErr != nil {
+			return txErr
+		}
+
+		return service.repo.DeleteOtherSessionsForUser(ctx, tx, user.ID, sessionID)
+	})
+	if err != nil {
+		return
+	}
+
+	return nil
+}
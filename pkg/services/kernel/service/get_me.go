@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/cmd/mdninja-server/config"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+// GetMe returns the currently authenticated user. It is mostly useful with the local identity provider
+// as the session cookie can't be read by the webapp.
+func (service *KernelService) GetMe(ctx context.Context, input kernel.EmptyInput) (user kernel.User, err error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	if service.authConfig.Provider == config.AuthProviderLocal {
+		return service.repo.FindUserByID(ctx, service.db, userID)
+	}
+
+	// with Pingoo, the claims of the access token are enough
+	accessToken := httpctx.FromCtx(ctx).AccessToken
+	user = kernel.User{
+		ID:      accessToken.UserID,
+		Name:    accessToken.Name,
+		Email:   accessToken.Email,
+		IsAdmin: accessToken.IsAdmin,
+	}
+	return user, nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/skerkour/stdx-go/crypto"
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/uuid"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/jwt"
+	"markdown.ninja/pkg/server/auth"
+	"markdown.ninja/pkg/services/kernel"
+	"markdown.ninja/pkg/services/kernel/repository"
+)
+
+// localIdentityProvider stores the users and their sessions in the database of the instance.
+// The "access token" is the session token stored in the kernel.AuthCookie cookie.
+type localIdentityProvider struct {
+	db          db.DB
+	repo        repository.KernelRepository
+	jwtProvider *jwt.Provider
+}
+
+func newLocalIdentityProvider(db db.DB, repo repository.KernelRepository, jwtProvider *jwt.Provider) *localIdentityProvider {
+	return &localIdentityProvider{
+		db:          db,
+		repo:        repo,
+		jwtProvider: jwtProvider,
+	}
+}
+
+func (provider *localIdentityProvider) VerifyAccessToken(ctx context.Context, token string) (accessToken auth.AccessToken, err error) {
+	var jwtClaims sessionJwtClaims
+
+	err = provider.jwtProvider.ParseAndVerifyToken(token, &jwtClaims)
+	if err != nil {
+		err = kernel.ErrSessionIsNotValid
+		return
+	}
+
+	userAndSession, err := provider.repo.FindUserWithSession(ctx, provider.db, jwtClaims.SessionID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			err = kernel.ErrSessionIsNotValid
+		}
+		return
+	}
+	user := userAndSession.User
+	session := userAndSession.Session
+
+	if !session.Verified || !session.UserID.Equal(jwtClaims.UserID) {
+		err = kernel.ErrSessionIsNotValid
+		return
+	}
+
+	sessionHash := generateSessionHash(session.UserID, session.ID, jwtClaims.Secret)
+	if !crypto.ConstantTimeCompare(sessionHash[:], session.SecretHash) {
+		err = kernel.ErrSessionIsNotValid
+		return
+	}
+
+	if user.BlockedAt != nil {
+		err = kernel.ErrUserIsBlocked
+		return
+	}
+
+	accessToken = auth.AccessToken{
+		UserID:  user.ID,
+		Name:    user.Name,
+		Email:   user.Email,
+		IsAdmin: user.IsAdmin,
+		RegisteredClaims: jwt.RegisteredClaims{
+			// the JwtID is used to identify the current session
+			JwtID:          session.ID.String(),
+			ExpirationTime: jwt.Time(session.CreatedAt.Add(kernel.AuthCookieTimeout)),
+			NotBefore:      jwt.Time(session.CreatedAt.Add(-time.Minute)),
+		},
+	}
+	return accessToken, nil
+}
+
+func (provider *localIdentityProvider) FindUser(ctx context.Context, userID uuid.UUID) (user kernel.User, err error) {
+	return provider.repo.FindUserByID(ctx, provider.db, userID)
+}
+
+func (provider *localIdentityProvider) FindUsers(ctx context.Context, userIDs []uuid.UUID) (users []kernel.User, err error) {
+	return provider.repo.FindUsersByIDs(ctx, provider.db, userIDs)
+}
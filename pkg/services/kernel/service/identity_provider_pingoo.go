@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/uuid"
+	"markdown.ninja/pingoo-go"
+	"markdown.ninja/pkg/server/auth"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+// pingooIdentityProvider delegates authentication and users management to pingoo.io
+type pingooIdentityProvider struct {
+	client *pingoo.Client
+}
+
+func newPingooIdentityProvider(client *pingoo.Client) *pingooIdentityProvider {
+	return &pingooIdentityProvider{
+		client: client,
+	}
+}
+
+func (provider *pingooIdentityProvider) VerifyAccessToken(ctx context.Context, token string) (accessToken auth.AccessToken, err error) {
+	return pingoo.VerifyJWT[auth.AccessToken](ctx, provider.client, token)
+}
+
+func (provider *pingooIdentityProvider) FindUser(ctx context.Context, userID uuid.UUID) (user kernel.User, err error) {
+	pingooUser, err := provider.client.GetUser(ctx, pingoo.GetUserInput{ID: userID})
+	if err != nil {
+		return
+	}
+
+	return convertPingooUser(pingooUser), nil
+}
+
+func (provider *pingooIdentityProvider) FindUsers(ctx context.Context, userIDs []uuid.UUID) (users []kernel.User, err error) {
+	users = make([]kernel.User, 0, len(userIDs))
+	if len(userIDs) == 0 {
+		return users, nil
+	}
+
+	pingooUsers, err := provider.client.ListUsers(ctx, pingoo.ListUsersInput{IDs: userIDs})
+	if err != nil {
+		return
+	}
+
+	for _, pingooUser := range pingooUsers.Data {
+		users = append(users, convertPingooUser(pingooUser))
+	}
+	return users, nil
+}
+
+func convertPingooUser(user pingoo.User) kernel.User {
+	return kernel.User{
+		ID:        user.ID,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+		Name:      user.Name,
+		Email:     user.Email,
+		IsAdmin:   user.IsAdmin,
+	}
+}
@@ -23,7 +23,17 @@ func (service *KernelService) Init(ctx context.Context, input kernel.EmptyInput)
 			AppID:    service.pingooConfig.AppID,
 			Endpoint: service.pingooConfig.Endpoint,
 		},
+		Auth: kernel.InitDataAuth{
+			Provider:     string(service.authConfig.Provider),
+			AllowSignups: service.authConfig.AllowSignups,
+			Oidc:         nil,
+		},
 		WebsitesBaseUrl: service.config.HTTP.WebsitesBaseUrl.String(),
 	}
+	if service.authConfig.Oidc != nil {
+		ret.Auth.Oidc = &kernel.InitDataAuthOidc{
+			Name: service.authConfig.Oidc.Name,
+		}
+	}
 	return ret, nil
 }
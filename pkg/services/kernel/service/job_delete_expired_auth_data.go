@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"markdown.ninja/pkg/services/kernel"
+)
+
+func (service *KernelService) JobDeleteExpiredAuthData(ctx context.Context, input kernel.JobDeleteExpiredAuthData) (err error) {
+	now := time.Now().UTC()
+	// Keep the unverified data a little longer than the codes' lifetime
+	unverifiedBefore := now.Add(-2 * kernel.AuthCodeTimeout)
+
+	err = service.repo.DeleteOldPendingUsers(ctx, service.db, unverifiedBefore)
+	if err != nil {
+		return
+	}
+
+	err = service.repo.DeleteExpiredSessions(ctx, service.db, unverifiedBefore, now.Add(-kernel.AuthCookieTimeout))
+	if err != nil {
+		return
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/services/kernel"
+)
+
+func (service *KernelService) ListMySessions(ctx context.Context, input kernel.EmptyInput) (ret kernel.PaginatedResult[kernel.UserSession], err error) {
+	user, err := service.currentLocalUser(ctx)
+	if err != nil {
+		return
+	}
+
+	currentSessionID, err := service.currentSessionID(ctx)
+	if err != nil {
+		return
+	}
+
+	sessions, err := service.repo.FindVerifiedSessionsForUser(ctx, service.db, user.ID)
+	if err != nil {
+		return
+	}
+
+	ret.Data = make([]kernel.UserSession, 0, len(sessions))
+	for _, session := range sessions {
+		ret.Data = append(ret.Data, kernel.UserSession{
+			ID:          session.ID,
+			CreatedAt:   session.CreatedAt,
+			Ip:          session.Ip,
+			UserAgent:   session.UserAgent,
+			CountryCode: session.CountryCode,
+			Current:     session.ID.Equal(currentSessionID),
+		})
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/crypto"
+	"github.com/skerkour/stdx-go/db"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+// Login starts a new session. If a password is provided, it is verified, otherwise a login code is sent
+// by email. If the user has enabled 2FA, the session then needs to be completed with Complete2faChallenge.
+func (service *KernelService) Login(ctx context.Context, input kernel.LoginInput) (ret kernel.LoginOutput, err error) {
+	err = service.checkLocalAuthIsEnabled()
+	if err != nil {
+		return
+	}
+
+	if _, userErr := service.CurrentUserID(ctx); userErr == nil {
+		err = kernel.ErrMustNotBeAuthenticated
+		return
+	}
+
+	service.SleepAuth()
+
+	httpCtx := httpctx.FromCtx(ctx)
+	if !service.rateLimiter.IsAllowed(ctx, "kernel.Login", nil, httpCtx.Client.IP.AsSlice(), time.Hour, 30) {
+		err = errs.TooManyRequests()
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(input.Email))
+
+	user, err := service.repo.FindUserByEmail(ctx, service.db, email)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			service.SleepAuthFailure()
+			err = kernel.ErrInvalidEmailPassword
+		}
+		return
+	}
+
+	if user.BlockedAt != nil {
+		err = kernel.ErrUserIsBlocked
+		return
+	}
+
+	// login with email code
+	if input.Password == nil {
+		code, codeHash := service.generateAuthCode()
+		session := service.newUnverifiedSession(ctx, user.ID, codeHash, false)
+		err = service.repo.CreateSession(ctx, service.db, session)
+		if err != nil {
+			return
+		}
+
+		link := service.generateWebappLink("/login/complete", url.Values{
+			"session_id": {session.ID.String()},
+			"code":       {code},
+		})
+		err = service.sendCodeEmail(ctx, service.loginEmailTemplate, user.Name, user.Email,
+			"Your Markdown Ninja login code: "+code, code, link)
+		if err != nil {
+			return
+		}
+
+		ret = kernel.LoginOutput{
+			SessionID: session.ID,
+			Step:      kernel.AuthStepEmailCode,
+		}
+		return ret, nil
+	}
+
+	// login with password
+	if user.PasswordHash == nil || !crypto.VerifyPasswordHash([]byte(*input.Password), *user.PasswordHash) {
+		service.SleepAuthFailure()
+		err = kernel.ErrInvalidEmailPassword
+		return
+	}
+
+	session := service.newUnverifiedSession(ctx, user.ID, "", user.TwoFaEnabled)
+	ret = kernel.LoginOutput{
+		SessionID: session.ID,
+		Step:      kernel.AuthStepTwoFa,
+	}
+
+	if user.TwoFaEnabled {
+		err = service.repo.CreateSession(ctx, service.db, session)
+		if err != nil {
+			return
+		}
+		return ret, nil
+	}
+
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		txErr = service.repo.CreateSession(ctx, tx, session)
+		if txErr != nil {
+			return txErr
+		}
+
+		return service.completeSession(ctx, tx, &user, &session)
+	})
+	if err != nil {
+		return
+	}
+
+	ret.Step = kernel.AuthStepCompleted
+	return ret, nil
+}
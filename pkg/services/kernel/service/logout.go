@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+func (service *KernelService) Logout(ctx context.Context, input kernel.EmptyInput) (err error) {
+	err = service.checkLocalAuthIsEnabled()
+	if err != nil {
+		return
+	}
+
+	sessionID, err := service.currentSessionID(ctx)
+	if err != nil {
+		return
+	}
+
+	err = service.repo.DeleteSession(ctx, service.db, sessionID)
+	if err != nil {
+		return
+	}
+
+	httpCtx := httpctx.FromCtx(ctx)
+	httpCtx.Response.Cookies = append(httpCtx.Response.Cookies, service.GenerateLogoutCookie())
+
+	return nil
+}
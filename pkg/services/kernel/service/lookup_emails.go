@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"strings"
+
+	"markdown.ninja/pingoo-go"
+)
+
+func (service *KernelService) LookupEmails(ctx context.Context, input pingoo.LookupEmailsInput) (ret []pingoo.EmailInfo, err error) {
+	if service.pingooClient != nil {
+		return service.pingooClient.LookupEmails(ctx, input)
+	}
+
+	lookupMxRecords := input.MxRecords != nil && *input.MxRecords
+	ret = make([]pingoo.EmailInfo, 0, len(input.Emails))
+	for _, email := range input.Emails {
+		var emailInfo pingoo.EmailInfo
+		emailInfo, err = lookupEmailLocally(ctx, email, lookupMxRecords)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, emailInfo)
+	}
+
+	return ret, nil
+}
+
+// lookupEmailLocally is used when Pingoo is not enabled. Disposable email addresses can't be detected.
+func lookupEmailLocally(ctx context.Context, email string, lookupMxRecords bool) (emailInfo pingoo.EmailInfo, err error) {
+	emailInfo = pingoo.EmailInfo{
+		Email:      email,
+		Disposable: false,
+		MxRecords:  []string{},
+		Valid:      false,
+	}
+
+	address, parseErr := mail.ParseAddress(email)
+	if parseErr != nil || address.Address != email {
+		return emailInfo, nil
+	}
+	atIndex := strings.LastIndexByte(email, '@')
+	if atIndex < 1 || atIndex == len(email)-1 {
+		return emailInfo, nil
+	}
+	emailInfo.Valid = true
+
+	if lookupMxRecords {
+		domain := email[atIndex+1:]
+		var mxRecords []*net.MX
+		mxRecords, err = net.DefaultResolver.LookupMX(ctx, domain)
+		if err != nil {
+			var dnsErr *net.DNSError
+			if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+				return emailInfo, nil
+			}
+			err = fmt.Errorf("kernel.lookupEmailLocally: looking up MX records of %s: %w", domain, err)
+			return
+		}
+
+		for _, mxRecord := range mxRecords {
+			emailInfo.MxRecords = append(emailInfo.MxRecords, strings.TrimSuffix(mxRecord.Host, "."))
+		}
+	}
+
+	return emailInfo, nil
+}
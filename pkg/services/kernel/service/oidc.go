@@ -0,0 +1,444 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/crypto"
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/httpx"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/uuid"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/jwt"
+	"markdown.ninja/pkg/server/apiutil"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+const (
+	oidcCallbackPath      = "/api/oidc/callback"
+	oidcMaxResponseSize   = 1_000_000
+	oidcRandomValuesBytes = 32
+)
+
+// https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata
+type oidcDiscoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+}
+
+type oidcStateJwtClaims struct {
+	State string `json:"state"`
+	Nonce string `json:"nonce"`
+}
+
+type oidcTokenResponse struct {
+	IDToken string `json:"id_token"`
+}
+
+// https://openid.net/specs/openid-connect-core-1_0.html#IDToken
+type oidcIDTokenClaims struct {
+	Issuer   string          `json:"iss"`
+	Subject  string          `json:"sub"`
+	Audience json.RawMessage `json:"aud"`
+	// Expiration time as a unix timestamp
+	ExpiresAt     int64  `json:"exp"`
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// ServeOidcLogin redirects the user to the authorization endpoint of the configured OpenID Connect provider
+func (service *KernelService) ServeOidcLogin(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	err := service.checkOidcIsEnabled()
+	if err != nil {
+		apiutil.SendError(ctx, res, err)
+		return
+	}
+
+	discoveryDocument, err := service.getOidcDiscoveryDocument(ctx)
+	if err != nil {
+		apiutil.SendError(ctx, res, err)
+		return
+	}
+
+	stateClaims := oidcStateJwtClaims{
+		State: generateOidcRandomValue(),
+		Nonce: generateOidcRandomValue(),
+	}
+	stateExpiresAt := time.Now().UTC().Add(kernel.OidcStateCookieTimeout)
+	stateToken, err := service.jwtProvider.NewSignedToken(stateClaims, &jwt.TokenOptions{
+		ExpirationTime: &stateExpiresAt,
+	})
+	if err != nil {
+		apiutil.SendError(ctx, res, fmt.Errorf("kernel.ServeOidcLogin: signing state: %w", err))
+		return
+	}
+
+	authorizationUrl, err := url.Parse(discoveryDocument.AuthorizationEndpoint)
+	if err != nil {
+		apiutil.SendError(ctx, res, fmt.Errorf("kernel.ServeOidcLogin: parsing authorization_endpoint: %w", err))
+		return
+	}
+	query := authorizationUrl.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", service.authConfig.Oidc.ClientID)
+	query.Set("redirect_uri", service.generateWebappLink(oidcCallbackPath, nil))
+	query.Set("scope", "openid email profile")
+	query.Set("state", stateClaims.State)
+	query.Set("nonce", stateClaims.Nonce)
+	authorizationUrl.RawQuery = query.Encode()
+
+	// The cookie needs to be Lax to be sent with the redirection from the identity provider
+	http.SetCookie(res, &http.Cookie{
+		Name:     kernel.OidcStateCookie,
+		Value:    stateToken,
+		Expires:  stateExpiresAt,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Path:     oidcCallbackPath,
+	})
+	http.Redirect(res, req, authorizationUrl.String(), http.StatusFound)
+}
+
+// ServeOidcCallback handles the redirection from the OpenID Connect provider, creates a session for the
+// user and redirects to the webapp.
+func (service *KernelService) ServeOidcCallback(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := slogx.FromCtx(ctx)
+	httpCtx := httpctx.FromCtx(ctx)
+
+	err := service.checkOidcIsEnabled()
+	if err != nil {
+		apiutil.SendError(ctx, res, err)
+		return
+	}
+
+	// the state cookie can only be used once
+	http.SetCookie(res, &http.Cookie{
+		Name:     kernel.OidcStateCookie,
+		Value:    "",
+		Expires:  time.Unix(0, 0),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Path:     oidcCallbackPath,
+	})
+
+	query := req.URL.Query()
+	if oidcErr := query.Get("error"); oidcErr != "" {
+		logger.Info("kernel.ServeOidcCallback: identity provider returned an error", slogx.Err(errors.New(oidcErr)))
+		apiutil.SendError(ctx, res, kernel.ErrOidcLoginFailed)
+		return
+	}
+
+	stateCookie, _ := req.Cookie(kernel.OidcStateCookie)
+	if stateCookie == nil {
+		apiutil.SendError(ctx, res, kernel.ErrOidcLoginFailed)
+		return
+	}
+
+	var stateClaims oidcStateJwtClaims
+	err = service.jwtProvider.ParseAndVerifyToken(stateCookie.Value, &stateClaims)
+	if err != nil || !crypto.ConstantTimeCompare([]byte(stateClaims.State), []byte(query.Get("state"))) {
+		service.SleepAuthFailure()
+		apiutil.SendError(ctx, res, kernel.ErrOidcLoginFailed)
+		return
+	}
+
+	idTokenClaims, err := service.exchangeOidcCode(ctx, query.Get("code"), stateClaims.Nonce)
+	if err != nil {
+		apiutil.SendError(ctx, res, err)
+		return
+	}
+
+	loginOutput, err := service.loginWithOidc(ctx, idTokenClaims)
+	if err != nil {
+		apiutil.SendError(ctx, res, err)
+		return
+	}
+
+	if loginOutput.Step == kernel.AuthStepTwoFa {
+		http.Redirect(res, req, service.generateWebappLink("/login/2fa", url.Values{
+			"session_id": {loginOutput.SessionID.String()},
+		}), http.StatusFound)
+		return
+	}
+
+	for _, cookie := range httpCtx.Response.Cookies {
+		http.SetCookie(res, &cookie)
+	}
+	http.Redirect(res, req, service.generateWebappLink("/", nil), http.StatusFound)
+}
+
+func (service *KernelService) checkOidcIsEnabled() error {
+	err := service.checkLocalAuthIsEnabled()
+	if err != nil {
+		return err
+	}
+
+	if service.authConfig.Oidc == nil {
+		return kernel.ErrOidcIsNotEnabled
+	}
+
+	return nil
+}
+
+// loginWithOidc finds or creates the user of the OIDC identity and starts a new session. If the user has
+// enabled 2FA, the session then needs to be completed with Complete2faChallenge, as with Login.
+func (service *KernelService) loginWithOidc(ctx context.Context, claims oidcIDTokenClaims) (ret kernel.LoginOutput, err error) {
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" || !claims.EmailVerified {
+		err = kernel.ErrOidcEmailIsNotVerified
+		return
+	}
+
+	// the subject is only unique for a given issuer
+	oidcSubject := service.authConfig.Oidc.Issuer + "#" + claims.Subject
+	now := time.Now().UTC()
+
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		user, txErr := service.repo.FindUserByOidcSubject(ctx, tx, oidcSubject)
+		if txErr != nil && !errs.IsNotFound(txErr) {
+			return txErr
+		}
+
+		if errs.IsNotFound(txErr) {
+			user, txErr = service.repo.FindUserByEmail(ctx, tx, email)
+			if txErr == nil {
+				// Only accounts that can already be accessed with a login code sent by email are linked
+				// to the OIDC identity, as the email has been verified by the provider. Otherwise, controlling
+				// the email at the provider would be enough to take over an account protected by a password or 2FA.
+				if user.PasswordHash != nil || user.TwoFaEnabled {
+					return kernel.ErrOidcAccountAlreadyExists
+				}
+
+				user.OidcSubject = &oidcSubject
+				user.UpdatedAt = now
+				txErr = service.repo.UpdateUser(ctx, tx, user)
+				if txErr != nil {
+					return txErr
+				}
+			} else if errs.IsNotFound(txErr) {
+				if !service.authConfig.AllowSignups && !service.isAdminEmail(email) {
+					return kernel.ErrSignupsAreClosed
+				}
+
+				name := strings.TrimSpace(claims.Name)
+				if validateUserName(name) != nil {
+					name, _, _ = strings.Cut(email, "@")
+				}
+
+				user = kernel.User{
+					ID:                  uuid.NewV7(),
+					CreatedAt:           now,
+					UpdatedAt:           now,
+					Name:                name,
+					Email:               email,
+					IsAdmin:             service.isAdminEmail(email),
+					PasswordHash:        nil,
+					EncryptedTotpSecret: nil,
+					TwoFaEnabled:        false,
+					OidcSubject:         &oidcSubject,
+					BlockedAt:           nil,
+					LastLoginAt:         nil,
+				}
+				txErr = service.repo.CreateUser(ctx, tx, user)
+				if txErr != nil {
+					return txErr
+				}
+			} else {
+				return txErr
+			}
+		}
+
+		if user.BlockedAt != nil {
+			return kernel.ErrUserIsBlocked
+		}
+
+		session := service.newUnverifiedSession(ctx, user.ID, "", user.TwoFaEnabled)
+		txErr = service.repo.CreateSession(ctx, tx, session)
+		if txErr != nil {
+			return txErr
+		}
+
+		ret = kernel.LoginOutput{
+			SessionID: session.ID,
+			Step:      kernel.AuthStepTwoFa,
+		}
+		if user.TwoFaEnabled {
+			return nil
+		}
+
+		ret.Step = kernel.AuthStepCompleted
+		return service.completeSession(ctx, tx, &user, &session)
+	})
+	if err != nil {
+		return
+	}
+
+	return ret, nil
+}
+
+// exchangeOidcCode exchanges the authorization code for an ID token and validates it.
+func (service *KernelService) exchangeOidcCode(ctx context.Context, code, nonce string) (claims oidcIDTokenClaims, err error) {
+	logger := slogx.FromCtx(ctx)
+
+	if code == "" {
+		err = kernel.ErrOidcLoginFailed
+		return
+	}
+
+	discoveryDocument, err := service.getOidcDiscoveryDocument(ctx)
+	if err != nil {
+		return
+	}
+
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {service.generateWebappLink(oidcCallbackPath, nil)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discoveryDocument.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		err = fmt.Errorf("kernel.exchangeOidcCode: creating request: %w", err)
+		return
+	}
+	req.Header.Set(httpx.HeaderContentType, "application/x-www-form-urlencoded")
+	req.Header.Set(httpx.HeaderAccept, httpx.MediaTypeJson)
+	req.SetBasicAuth(url.QueryEscape(service.authConfig.Oidc.ClientID), url.QueryEscape(service.authConfig.Oidc.ClientSecret))
+
+	var tokenResponse oidcTokenResponse
+	err = service.doOidcRequest(req, &tokenResponse)
+	if err != nil {
+		logger.Warn("kernel.exchangeOidcCode: error exchanging code", slogx.Err(err))
+		err = kernel.ErrOidcLoginFailed
+		return
+	}
+
+	// The ID token has been received directly from the token endpoint over TLS so, as allowed by
+	// OpenID Connect Core 1.0 section 3.1.3.7, the TLS server validation is used to validate the issuer
+	// in place of checking the token's signature.
+	tokenParts := strings.Split(tokenResponse.IDToken, ".")
+	if len(tokenParts) != 3 {
+		err = kernel.ErrOidcLoginFailed
+		return
+	}
+	claimsJson, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(tokenParts[1], "="))
+	if err != nil {
+		err = kernel.ErrOidcLoginFailed
+		return
+	}
+	err = json.Unmarshal(claimsJson, &claims)
+	if err != nil {
+		err = kernel.ErrOidcLoginFailed
+		return
+	}
+
+	var audiences []string
+	var singleAudience string
+	if json.Unmarshal(claims.Audience, &singleAudience) == nil {
+		audiences = []string{singleAudience}
+	} else if json.Unmarshal(claims.Audience, &audiences) != nil {
+		err = kernel.ErrOidcLoginFailed
+		return
+	}
+
+	if claims.Issuer != discoveryDocument.Issuer ||
+		!slices.Contains(audiences, service.authConfig.Oidc.ClientID) ||
+		time.Now().Unix() >= claims.ExpiresAt ||
+		claims.Subject == "" ||
+		!crypto.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) {
+		service.SleepAuthFailure()
+		err = kernel.ErrOidcLoginFailed
+		return
+	}
+
+	return claims, nil
+}
+
+func (service *KernelService) getOidcDiscoveryDocument(ctx context.Context) (document oidcDiscoveryDocument, err error) {
+	service.oidcDiscoveryMutex.Lock()
+	defer service.oidcDiscoveryMutex.Unlock()
+
+	if service.oidcDiscoveryDocument != nil {
+		return *service.oidcDiscoveryDocument, nil
+	}
+
+	issuer := service.authConfig.Oidc.Issuer
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		err = fmt.Errorf("kernel.getOidcDiscoveryDocument: creating request: %w", err)
+		return
+	}
+
+	err = service.doOidcRequest(req, &document)
+	if err != nil {
+		err = errs.Internal("error fetching the OpenID Connect discovery document", err)
+		return
+	}
+
+	if document.Issuer != issuer {
+		err = errs.Internal("OpenID Connect issuer mismatch",
+			fmt.Errorf("kernel.getOidcDiscoveryDocument: expected issuer %s, got %s", issuer, document.Issuer))
+		return
+	}
+
+	for _, endpoint := range []string{document.AuthorizationEndpoint, document.TokenEndpoint} {
+		if !strings.HasPrefix(endpoint, "https://") {
+			err = errs.Internal("OpenID Connect provider is not valid",
+				fmt.Errorf("kernel.getOidcDiscoveryDocument: endpoint is not a valid https URL: %s", endpoint))
+			return
+		}
+	}
+
+	service.oidcDiscoveryDocument = &document
+	return document, nil
+}
+
+func (service *KernelService) doOidcRequest(req *http.Request, out any) (err error) {
+	res, err := service.oidcHttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, oidcMaxResponseSize))
+	if err != nil {
+		return fmt.Errorf("reading response body: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("status code: %d, body: %s", res.StatusCode, string(body))
+	}
+
+	err = json.Unmarshal(body, out)
+	if err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+
+	return nil
+}
+
+func generateOidcRandomValue() string {
+	var value [oidcRandomValuesBytes]byte
+	// crypto/rand.Read never returns an error
+	rand.Read(value[:])
+	return base64.RawURLEncoding.EncodeToString(value[:])
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+func (service *KernelService) RevokeSession(ctx context.Context, input kernel.RevokeSessionInput) (err error) {
+	user, err := service.currentLocalUser(ctx)
+	if err != nil {
+		return
+	}
+
+	currentSessionID, err := service.currentSessionID(ctx)
+	if err != nil {
+		return
+	}
+
+	session, err := service.repo.FindSessionByID(ctx, service.db, input.ID)
+	if err != nil {
+		return
+	}
+
+	if !session.UserID.Equal(user.ID) {
+		err = kernel.ErrSessionNotFound
+		return
+	}
+
+	err = service.repo.DeleteSession(ctx, service.db, session.ID)
+	if err != nil {
+		return
+	}
+
+	if session.ID.Equal(currentSessionID) {
+		httpCtx := httpctx.FromCtx(ctx)
+		httpCtx.Response.Cookies = append(httpCtx.Response.Cookies, service.GenerateLogoutCookie())
+	}
+
+	return nil
+}
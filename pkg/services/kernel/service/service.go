@@ -2,21 +2,33 @@ package service
 
 import (
 	"html/template"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/queue"
 	"markdown.ninja/cmd/mdninja-server/config"
 	"markdown.ninja/pingoo-go"
+	"markdown.ninja/pkg/jwt"
+	"markdown.ninja/pkg/kms"
 	"markdown.ninja/pkg/mailer"
+	"markdown.ninja/pkg/ratelimit"
+	"markdown.ninja/pkg/services/kernel"
+	"markdown.ninja/pkg/services/kernel/repository"
 	"markdown.ninja/pkg/services/kernel/templates"
 	"markdown.ninja/pkg/services/organizations"
 )
 
 type KernelService struct {
-	config config.Config
-	db     db.DB
-	queue  queue.Queue
-	mailer mailer.Mailer
+	config      config.Config
+	db          db.DB
+	queue       queue.Queue
+	mailer      mailer.Mailer
+	repo        repository.KernelRepository
+	jwtProvider *jwt.Provider
+	kms         *kms.Kms
+	rateLimiter ratelimit.Limiter
 
 	pingooClient    *pingoo.Client
 	stripePublicKey string
@@ -25,23 +37,46 @@ type KernelService struct {
 	// pingooEndpoint               string
 	organizationsService organizations.Service
 	pingooConfig         config.Pingoo
+	authConfig           config.Auth
+	identityProvider     kernel.IdentityProvider
+
+	// OpenID Connect
+	oidcHttpClient        *http.Client
+	oidcDiscoveryMutex    sync.Mutex
+	oidcDiscoveryDocument *oidcDiscoveryDocument
 
 	signupEmailTemplate             *template.Template
+	loginEmailTemplate              *template.Template
 	loginAlertEmailTemplate         *template.Template
 	twoFaDisabledAlertEmailTemplate *template.Template
 }
 
 func NewKernelService(conf config.Config, db db.DB, queue queue.Queue, mailer mailer.Mailer,
-	pingooClient *pingoo.Client) *KernelService {
+	pingooClient *pingoo.Client, jwtProvider *jwt.Provider, kms *kms.Kms, rateLimiter ratelimit.Limiter) *KernelService {
 	signupEmailTemplate := template.Must(template.New("kernel.signupEmailTemplate").Parse(templates.SignupEmailTemplate))
+	loginEmailTemplate := template.Must(template.New("kernel.loginEmailTemplate").Parse(templates.LoginEmailTemplate))
 	loginAlertEmailTemplate := template.Must(template.New("kernel.loginAlertEmailTemplate").Parse(templates.LoginAlertEmailTemplate))
 	twoFaDisabledAlertEmailTemplate := template.Must(template.New("kernel.twoFaDisabledAlertEmailTemplate").Parse(templates.TwoFaDisabledEmailTemplate))
 
+	repo := repository.NewKernelRepository()
+
+	var identityProvider kernel.IdentityProvider
+	switch conf.Auth.Provider {
+	case config.AuthProviderLocal:
+		identityProvider = newLocalIdentityProvider(db, repo, jwtProvider)
+	default:
+		identityProvider = newPingooIdentityProvider(pingooClient)
+	}
+
 	return &KernelService{
-		config: conf,
-		db:     db,
-		queue:  queue,
-		mailer: mailer,
+		config:      conf,
+		db:          db,
+		queue:       queue,
+		mailer:      mailer,
+		repo:        repo,
+		jwtProvider: jwtProvider,
+		kms:         kms,
+		rateLimiter: rateLimiter,
 
 		pingooClient:    pingooClient,
 		stripePublicKey: conf.Stripe.PublicKey,
@@ -50,8 +85,17 @@ func NewKernelService(conf config.Config, db db.DB, queue queue.Queue, mailer ma
 		// pingooEndpoint:               conf.Pingoo.Endpoint,
 		organizationsService: nil,
 		pingooConfig:         conf.Pingoo,
+		authConfig:           conf.Auth,
+		identityProvider:     identityProvider,
+
+		oidcHttpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		oidcDiscoveryMutex:    sync.Mutex{},
+		oidcDiscoveryDocument: nil,
 
 		signupEmailTemplate:             signupEmailTemplate,
+		loginEmailTemplate:              loginEmailTemplate,
 		loginAlertEmailTemplate:         loginAlertEmailTemplate,
 		twoFaDisabledAlertEmailTemplate: twoFaDisabledAlertEmailTemplate,
 	}
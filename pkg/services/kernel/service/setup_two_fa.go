@@ -0,0 +1,67 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image/jpeg"
+	"time"
+
+	"github.com/skerkour/stdx-go/otp/totp"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+// SetupTwoFa generates a new TOTP secret for the current user. 2FA is enabled only once a valid code
+// has been submitted with EnableTwoFa.
+func (service *KernelService) SetupTwoFa(ctx context.Context, input kernel.EmptyInput) (ret kernel.SetupTwoFaOutput, err error) {
+	user, err := service.currentLocalUser(ctx)
+	if err != nil {
+		return
+	}
+
+	if user.TwoFaEnabled {
+		err = kernel.ErrTwoFaAlreadyEnabled
+		return
+	}
+
+	totpKey, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      kernel.TotpIssuer,
+		AccountName: user.Email,
+	})
+	if err != nil {
+		err = fmt.Errorf("kernel.SetupTwoFa: generating TOTP key: %w", err)
+		return
+	}
+
+	qrCodeImage, err := totpKey.QrCode(kernel.TotpQrCodeSize, kernel.TotpQrCodeSize)
+	if err != nil {
+		err = fmt.Errorf("kernel.SetupTwoFa: generating QR code: %w", err)
+		return
+	}
+
+	var qrCodeJpeg bytes.Buffer
+	err = jpeg.Encode(&qrCodeJpeg, qrCodeImage, &jpeg.Options{Quality: kernel.TotpQrCodeJPEGQuality})
+	if err != nil {
+		err = fmt.Errorf("kernel.SetupTwoFa: encoding QR code: %w", err)
+		return
+	}
+
+	user.EncryptedTotpSecret, err = service.kms.Encrypt(ctx, []byte(totpKey.Secret()), user.ID.Bytes())
+	if err != nil {
+		err = fmt.Errorf("kernel.SetupTwoFa: encrypting TOTP secret: %w", err)
+		return
+	}
+	user.UpdatedAt = time.Now().UTC()
+
+	err = service.repo.UpdateUser(ctx, service.db, user)
+	if err != nil {
+		return
+	}
+
+	ret = kernel.SetupTwoFaOutput{
+		Secret: totpKey.Secret(),
+		QrCode: base64.StdEncoding.EncodeToString(qrCodeJpeg.Bytes()),
+	}
+	return ret, nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/crypto"
+	"github.com/skerkour/stdx-go/uuid"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+func (service *KernelService) Signup(ctx context.Context, input kernel.SignupInput) (ret kernel.SignupOutput, err error) {
+	err = service.checkLocalAuthIsEnabled()
+	if err != nil {
+		return
+	}
+
+	if _, userErr := service.CurrentUserID(ctx); userErr == nil {
+		err = kernel.ErrMustNotBeAuthenticated
+		return
+	}
+
+	service.SleepAuth()
+
+	httpCtx := httpctx.FromCtx(ctx)
+	if !service.rateLimiter.IsAllowed(ctx, "kernel.Signup", nil, httpCtx.Client.IP.AsSlice(), time.Hour, 20) {
+		err = errs.TooManyRequests()
+		return
+	}
+
+	name := strings.TrimSpace(input.Name)
+	email := strings.ToLower(strings.TrimSpace(input.Email))
+
+	if !service.authConfig.AllowSignups && !service.isAdminEmail(email) {
+		err = kernel.ErrSignupsAreClosed
+		return
+	}
+
+	err = validateUserName(name)
+	if err != nil {
+		return
+	}
+
+	err = service.ValidateEmail(ctx, email, true)
+	if err != nil {
+		return
+	}
+
+	var passwordHash *string
+	if input.Password != nil {
+		err = validatePassword(*input.Password)
+		if err != nil {
+			return
+		}
+		passwordHash = new(crypto.HashPassword([]byte(*input.Password), kernel.PasswordHashParams))
+	}
+
+	_, err = service.repo.FindUserByEmail(ctx, service.db, email)
+	if err == nil {
+		err = kernel.ErrEmailAlreadyInUse
+		return
+	} else if !errs.IsNotFound(err) {
+		return
+	}
+
+	code, codeHash := service.generateAuthCode()
+	now := time.Now().UTC()
+	pendingUser := kernel.PendingUser{
+		ID:             uuid.NewV7(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Name:           name,
+		Email:          email,
+		PasswordHash:   passwordHash,
+		CodeHash:       codeHash,
+		FailedAttempts: 0,
+	}
+	err = service.repo.CreatePendingUser(ctx, service.db, pendingUser)
+	if err != nil {
+		return
+	}
+
+	link := service.generateWebappLink("/signup/complete", url.Values{
+		"pending_user_id": {pendingUser.ID.String()},
+		"code":            {code},
+	})
+	err = service.sendCodeEmail(ctx, service.signupEmailTemplate, name, email,
+		"Your Markdown Ninja registration code: "+code, code, link)
+	if err != nil {
+		return
+	}
+
+	ret = kernel.SignupOutput{
+		PendingUserID: pendingUser.ID,
+	}
+	return ret, nil
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/queue"
+	"markdown.ninja/cmd/mdninja-server/config"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+func (service *KernelService) TaskDeleteExpiredAuthData(ctx context.Context) {
+	logger := slogx.FromCtx(ctx)
+
+	if service.authConfig.Provider != config.AuthProviderLocal {
+		return
+	}
+
+	job := queue.NewJobInput{
+		Data: kernel.JobDeleteExpiredAuthData{},
+	}
+	err := service.queue.Push(ctx, nil, job)
+	if err != nil {
+		logger.Error("kernel.TaskDeleteExpiredAuthData: Pushing job to queue", slogx.Err(err))
+		return
+	}
+}
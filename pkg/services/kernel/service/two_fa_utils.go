@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/skerkour/stdx-go/otp/totp"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+// verifyTotpCode decrypts the TOTP secret of the user and validates the given code against it
+func (service *KernelService) verifyTotpCode(ctx context.Context, user kernel.User, code string) (valid bool, err error) {
+	if len(user.EncryptedTotpSecret) == 0 {
+		return false, kernel.ErrTwoFaIsNotSetup
+	}
+
+	// the ID of the user is used as additional data to bind the encrypted secret to the user
+	secret, err := service.kms.Decrypt(ctx, user.EncryptedTotpSecret, user.ID.Bytes())
+	if err != nil {
+		return false, fmt.Errorf("kernel: decrypting TOTP secret: %w", err)
+	}
+
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	return totp.Validate(code, string(secret)), nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/skerkour/stdx-go/crypto"
+	"github.com/skerkour/stdx-go/db"
+	"markdown.ninja/pkg/services/kernel"
+)
+
+// UpdateMyPassword sets or updates the password of the current user. All the other sessions of the user
+// are revoked.
+func (service *KernelService) UpdateMyPassword(ctx context.Context, input kernel.UpdateMyPasswordInput) (err error) {
+	user, err := service.currentLocalUser(ctx)
+	if err != nil {
+		return
+	}
+
+	sessionID, err := service.currentSessionID(ctx)
+	if err != nil {
+		return
+	}
+
+	service.SleepAuth()
+
+	if user.PasswordHash != nil {
+		if input.CurrentPassword == nil || !crypto.VerifyPasswordHash([]byte(*input.CurrentPassword), *user.PasswordHash) {
+			service.SleepAuthFailure()
+			err = kernel.ErrCurrentPasswordIsNotValid
+			return
+		}
+	}
+
+	err = validatePassword(input.NewPassword)
+	if err != nil {
+		return
+	}
+
+	user.PasswordHash = new(crypto.HashPassword([]byte(input.NewPassword), kernel.PasswordHashParams))
+	user.UpdatedAt = time.Now().UTC()
+
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		txErr = service.repo.UpdateUser(ctx, tx, user)
+		if txErr != nil {
+			return txErr
+		}
+
+		return service.repo.DeleteOtherSessionsForUser(ctx, tx, user.ID, sessionID)
+	})
+	if err != nil {
+		return
+	}
+
+	return nil
+}
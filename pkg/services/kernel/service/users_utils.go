@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/skerkour/stdx-go/uuid"
+	"markdown.ninja/pkg/server/auth"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/kernel"
 )
@@ -18,3 +19,30 @@ func (service *KernelService) CurrentUserID(ctx context.Context) (userID uuid.UU
 
 	return httpCtx.AccessToken.UserID, nil
 }
+
+func (service *KernelService) VerifyAccessToken(ctx context.Context, token string) (accessToken auth.AccessToken, err error) {
+	return service.identityProvider.VerifyAccessToken(ctx, token)
+}
+
+func (service *KernelService) FindUser(ctx context.Context, userID uuid.UUID) (user kernel.User, err error) {
+	return service.identityProvider.FindUser(ctx, userID)
+}
+
+func (service *KernelService) FindUsers(ctx context.Context, userIDs []uuid.UUID) (users []kernel.User, err error) {
+	return service.identityProvider.FindUsers(ctx, userIDs)
+}
+
+// currentLocalUser returns the authenticated user when using the local identity provider
+func (service *KernelService) currentLocalUser(ctx context.Context) (user kernel.User, err error) {
+	err = service.checkLocalAuthIsEnabled()
+	if err != nil {
+		return
+	}
+
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	return service.repo.FindUserByID(ctx, service.db, userID)
+}
@@ -22,12 +22,16 @@ func (service *KernelService) ValidateEmail(ctx context.Context, emailAddress st
 	}
 
 	var pingooRes pingoo.EmailInfo
-	err = retry.Do(func() (retryErr error) {
-		pingooRes, retryErr = service.pingooClient.LookupEmail(ctx, emailAddress)
-		return retryErr
-	}, retry.Context(ctx), retry.Attempts(3), retry.Delay(100*time.Millisecond))
+	if service.pingooClient == nil {
+		pingooRes, err = lookupEmailLocally(ctx, emailAddress, true)
+	} else {
+		err = retry.Do(func() (retryErr error) {
+			pingooRes, retryErr = service.pingooClient.LookupEmail(ctx, emailAddress)
+			return retryErr
+		}, retry.Context(ctx), retry.Attempts(3), retry.Delay(100*time.Millisecond))
+	}
 	if err != nil {
-		return errs.Internal("error checking email address with Pingoo", err)
+		return errs.Internal("error checking email address", err)
 	} else {
 		if !pingooRes.Valid || len(pingooRes.MxRecords) == 0 || (rejectBlockedDomains && pingooRes.Disposable) {
 			return kernel.ErrEmailIsNotValid
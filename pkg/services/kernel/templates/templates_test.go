@@ -11,6 +11,12 @@ func TestSignupEmailTemplate(t *testing.T) {
 	}
 }
 
+func TestLoginEmailTemplate(t *testing.T) {
+	if strings.TrimSpace(LoginEmailTemplate) == "" {
+		t.Error("LoginEmailTemplate is empty")
+	}
+}
+
 func TestLoginAlertEmailTemplate(t *testing.T) {
 	if strings.TrimSpace(LoginAlertEmailTemplate) == "" {
 		t.Error("LoginAlertEmailTemplate is empty")
@@ -23,6 +23,9 @@ const (
 
 	// TestTaxID is not verified when used by an administrator. Use it for devlopment purpose only
 	TestTaxID = "FRXXX"
+
+	DefaultEmailsSendRate = 10
+	EmailsSendRateMax     = 1_000
 )
 
 type StaffRole int64
@@ -82,6 +85,9 @@ type Organization struct {
 	// the time at when the last invoiced usage period ends
 	UsageLastInvoicedAt *time.Time `db:"usage_last_invoiced_at" json:"-"`
 	ExtraSlots          int64      `db:"extra_slots" json:"extra_slots"`
+	// EmailsSendRate is the total number of emails / s sent for the newsletters of the websites of the
+	// organization
+	EmailsSendRate int64 `db:"emails_send_rate" json:"emails_send_rate"`
 
 	ApiKeys                  []ApiKey           `db:"-" json:"api_keys"`
 	Staffs                   []StaffWithDetails `db:"-" json:"staffs"`
@@ -214,8 +220,9 @@ type UpdateOrganizationInput struct {
 	BillingInformation *BillingInformation `json:"billing_information"`
 
 	// These fields can only be updated by a Markdown Ninja admin
-	Plan       *kernel.PlanID `json:"plan"`
-	ExtraSlots *int64         `json:"extra_slots"`
+	Plan           *kernel.PlanID `json:"plan"`
+	ExtraSlots     *int64         `json:"extra_slots"`
+	EmailsSendRate *int64         `json:"emails_send_rate"`
 }
 
 type GetOrganizationsForUserInput struct {
@@ -6,7 +6,7 @@ import (
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/guid"
 	"github.com/skerkour/stdx-go/uuid"
-	"github.com/stripe/stripe-go/v81"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/services/kernel"
 )
 
@@ -21,6 +21,7 @@ type Service interface {
 	ListOrganizations(ctx context.Context, input ListOrganizationsInput) (orgs kernel.PaginatedResult[Organization], err error)
 	UpdateOrganization(ctx context.Context, input UpdateOrganizationInput) (org Organization, err error)
 	ListOrganizationsForUser(ctx context.Context, db db.Queryer, userID uuid.UUID) (orgs []Organization, err error)
+	FindOrganizationByID(ctx context.Context, db db.Queryer, organizationID guid.GUID) (org Organization, err error)
 
 	// Staffs
 	CheckUserIsStaff(ctx context.Context, db db.Queryer, userID uuid.UUID, organizationID guid.GUID) (staff Staff, err error)
@@ -41,7 +42,9 @@ type Service interface {
 	UpdateApiKey(ctx context.Context, input UpdateApiKeyInput) (apiKey ApiKey, err error)
 
 	// Billing
-	HandleStripeEvent(ctx context.Context, stripeEvent stripe.Event) (err error)
+	// HandlePaymentEvent processes the events of the payment provider, and forwards the ones of the
+	// stores to the store service.
+	HandlePaymentEvent(ctx context.Context, event payments.Event) (err error)
 	UpdateSubscription(ctx context.Context, input UpdateSubscriptionInput) (ret UpdateSubscriptionOutput, err error)
 	// CancelSubscription(ctx context.Context, input UpdateSubscriptionInput) (err error)
 	GetStripeCustomerPortalUrl(ctx context.Context, input GetStripeCustomerPortalUrlInput) (ret GetStripeCustomerPortalUrlOutput, err error)
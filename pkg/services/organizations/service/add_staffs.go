@@ -8,7 +8,6 @@ import (
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/set"
 	"github.com/skerkour/stdx-go/uuid"
-	"markdown.ninja/pingoo-go"
 	"markdown.ninja/pkg/errs"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/kernel"
@@ -32,13 +31,13 @@ func (service *OrganizationsService) AddStaffs(ctx context.Context, input organi
 	uniqueUserIDs := set.NewFromSlice(input.UserIDs)
 
 	// find users and make sure they exist
-	users, err := service.pingoo.ListUsers(ctx, pingoo.ListUsersInput{IDs: uniqueUserIDs.ToSlice()})
+	users, err := service.kernel.FindUsers(ctx, uniqueUserIDs.ToSlice())
 	if err != nil {
 		return ret, err
 	}
 
-	if len(users.Data) != len(uniqueUserIDs) {
-		for _, user := range users.Data {
+	if len(users) != len(uniqueUserIDs) {
+		for _, user := range users {
 			if !uniqueUserIDs.Contains(user.ID) {
 				return ret, errs.InvalidArgument(fmt.Sprintf("user not found %s", user.ID.String()))
 			}
@@ -46,8 +45,8 @@ func (service *OrganizationsService) AddStaffs(ctx context.Context, input organi
 		return ret, errs.InvalidArgument("user not found")
 	}
 
-	usersByID := make(map[uuid.UUID]pingoo.User, len(users.Data))
-	for _, user := range users.Data {
+	usersByID := make(map[uuid.UUID]kernel.User, len(users))
+	for _, user := range users {
 		usersByID[user.ID] = user
 	}
 
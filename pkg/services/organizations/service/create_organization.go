@@ -8,10 +8,8 @@ import (
 
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/guid"
-	"github.com/stripe/stripe-go/v81"
-	"github.com/stripe/stripe-go/v81/checkout/session"
-	stripecustomer "github.com/stripe/stripe-go/v81/customer"
 	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/organizations"
@@ -90,6 +88,7 @@ func (service *OrganizationsService) CreateOrganization(ctx context.Context, inp
 		StripeSubscriptionID:  nil,
 		SubscriptionStartedAt: nil,
 		ExtraSlots:            0,
+		EmailsSendRate:        organizations.DefaultEmailsSendRate,
 		PaymentDueSince:       nil,
 		UsageLastInvoicedAt:   nil,
 	}
@@ -103,34 +102,32 @@ func (service *OrganizationsService) CreateOrganization(ctx context.Context, inp
 	}
 
 	if planID != kernel.PlanFree.ID {
-		// create stripe customer
-		var stripeCustomer *stripe.Customer
-		stripeCustomerParams := service.generateStripeCustomerParams(organization)
-		stripeCustomer, err = stripecustomer.New(stripeCustomerParams)
+		// create the customer
+		var customer payments.Customer
+		customer, err = service.paymentProvider.CreateCustomer(ctx, generateCreateCustomerInput(organization))
 		if err != nil {
-			err = fmt.Errorf("organizations.CreateOrganization: creating Stripe customer: %w", err)
+			err = fmt.Errorf("organizations.CreateOrganization: creating customer: %w", err)
 			return
 		}
 
-		organization.StripeCustomerID = &stripeCustomer.ID
+		organization.StripeCustomerID = &customer.ID
 
-		var stripeCheckoutSession *stripe.CheckoutSession
-		var stripeCheckoutSessionLineItems []*stripe.CheckoutSessionLineItemParams
-		stripeCheckoutSessionLineItems, err = service.getStripeCheckoutSessionLineItemsForPlan(planID, organization.ExtraSlots)
+		var checkoutSession payments.CheckoutSession
+		var checkoutSessionLineItems []payments.CheckoutSessionLineItem
+		checkoutSessionLineItems, err = service.getCheckoutSessionLineItemsForPlan(planID, organization.ExtraSlots)
 		if err != nil {
 			err = fmt.Errorf("organizations.CreateOrganization: %w", err)
 			return
 		}
 
-		// billingAnchor := timeutil.GetFirstDayOfNextMonth(time.Now().UTC()).UTC()
-		checkoutParams := service.generateStripeCheckoutSessionParams(organization, planID, stripeCheckoutSessionLineItems)
-		stripeCheckoutSession, err = session.New(checkoutParams)
+		checkoutSessionInput := service.generateCheckoutSessionInput(organization, planID, checkoutSessionLineItems)
+		checkoutSession, err = service.paymentProvider.CreateCheckoutSession(ctx, checkoutSessionInput)
 		if err != nil {
-			err = fmt.Errorf("organizations.CreateOrganization: error creating stripe checkout session: %w", err)
+			err = fmt.Errorf("organizations.CreateOrganization: error creating checkout session: %w", err)
 			return
 		}
 
-		ret.StripeCheckoutSessionUrl = &stripeCheckoutSession.URL
+		ret.StripeCheckoutSessionUrl = &checkoutSession.URL
 	}
 
 	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
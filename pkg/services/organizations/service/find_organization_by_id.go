@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/organizations"
+)
+
+func (service *OrganizationsService) FindOrganizationByID(ctx context.Context, db db.Queryer, organizationID guid.GUID) (org organizations.Organization, err error) {
+	return service.repo.FindOrganizationByID(ctx, db, organizationID, false)
+}
@@ -6,7 +6,7 @@ import (
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/guid"
 	"github.com/skerkour/stdx-go/uuid"
-	"markdown.ninja/pingoo-go"
+	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/organizations"
 )
 
@@ -25,12 +25,12 @@ func (service *OrganizationsService) getStaffsWithDetails(ctx context.Context, d
 		userIDs = append(userIDs, uuid.UUID(staff.UserID))
 	}
 
-	staffUsers, err := service.pingoo.ListUsers(ctx, pingoo.ListUsersInput{IDs: userIDs})
+	staffUsers, err := service.kernel.FindUsers(ctx, userIDs)
 	if err != nil {
 		return staffsWithDetails, err
 	}
-	staffUsersById := make(map[uuid.UUID]pingoo.User, len(staffUsers.Data))
-	for _, user := range staffUsers.Data {
+	staffUsersById := make(map[uuid.UUID]kernel.User, len(staffUsers))
+	for _, user := range staffUsers {
 		staffUsersById[user.ID] = user
 	}
 
@@ -5,8 +5,6 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/stripe/stripe-go/v81"
-	portalsession "github.com/stripe/stripe-go/v81/billingportal/session"
 	"markdown.ninja/pkg/errs"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/kernel"
@@ -49,16 +47,13 @@ func (service *OrganizationsService) GetStripeCustomerPortalUrl(ctx context.Cont
 		return
 	}
 
-	params := &stripe.BillingPortalSessionParams{
-		Customer:  organization.StripeCustomerID,
-		ReturnURL: stripe.String(service.generateOrganizationBillingUrl(organization.ID)),
-	}
-	customerPortalSession, err := portalsession.New(params)
+	returnURL := service.generateOrganizationBillingUrl(organization.ID)
+	customerPortalUrl, err := service.paymentProvider.CreateCustomerPortalSession(ctx, *organization.StripeCustomerID, returnURL)
 	if err != nil {
 		err = fmt.Errorf("organizations.GetStripeCustomerPortalUrl: create customer portal session: %w", err)
 		return
 	}
 
-	ret.StripeCustomerPortalUrl = customerPortalSession.URL
+	ret.StripeCustomerPortalUrl = customerPortalUrl
 	return
 }
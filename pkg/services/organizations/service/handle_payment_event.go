@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/retry"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/payments"
+)
+
+func (service *OrganizationsService) HandlePaymentEvent(ctx context.Context, event payments.Event) error {
+	logger := slogx.FromCtx(ctx)
+
+	logger.Debug("organizations: payment event received", slog.String("event.type", string(event.Type)))
+
+	// List of all Stripe events:
+	// https://docs.stripe.com/api/events/types
+
+	switch event.Type {
+	case payments.EventTypeChargeFailed:
+		return service.handlePaymentEventCharge(ctx, event)
+
+	case payments.EventTypeCheckoutSessionCompleted, payments.EventTypeCheckoutSessionExpired:
+		return service.handlePaymentEventCheckoutSession(ctx, event)
+
+	case payments.EventTypeCustomerUpdated:
+		return service.handlePaymentEventCustomer(ctx, event)
+
+	case payments.EventTypeSubscriptionCreated,
+		payments.EventTypeSubscriptionUpdated,
+		payments.EventTypeSubscriptionDeleted:
+		return service.handlePaymentEventSubscription(ctx, event)
+
+	case payments.EventTypeInvoicePaid:
+		return service.handlePaymentEventInvoice(ctx, event)
+
+	case payments.EventTypePaymentMethodAttached:
+		return service.handlePaymentEventPaymentMethodAttached(ctx, event)
+	}
+
+	return nil
+}
+
+// handlePaymentEventPaymentMethodAttached is used to set newly attached payment methods to customers
+// as their default payment method
+func (service *OrganizationsService) handlePaymentEventPaymentMethodAttached(ctx context.Context, event payments.Event) error {
+	logger := slogx.FromCtx(ctx)
+	paymentMethod := event.PaymentMethod
+
+	if _, isWebsiteEvent := paymentMethod.Metadata["markdown_ninja_website_id"]; isWebsiteEvent {
+		return service.storeService.HandlePaymentEvent(ctx, event)
+	}
+
+	if paymentMethod.CustomerID == nil {
+		logger.Error("organizations.handlePaymentEventPaymentMethodAttached: customer is null",
+			slog.String("payment_method.id", paymentMethod.ID))
+		return nil
+	}
+
+	err := service.paymentProvider.SetDefaultPaymentMethod(ctx, *paymentMethod.CustomerID, paymentMethod.ID)
+	if err != nil {
+		return fmt.Errorf("organizations.handlePaymentEventPaymentMethodAttached: %w", err)
+	}
+
+	return nil
+}
+
+func (service *OrganizationsService) handlePaymentEventCustomer(ctx context.Context, event payments.Event) error {
+	logger := slogx.FromCtx(ctx)
+	customer := event.Customer
+
+	if _, isWebsiteEvent := customer.Metadata["markdown_ninja_website_id"]; isWebsiteEvent {
+		return service.storeService.HandlePaymentEvent(ctx, event)
+	}
+
+	organization, err := service.repo.FindOrganizationByStripeCustomerID(ctx, service.db, customer.ID, false)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			logger.Error("organizations.handlePaymentEventCustomer: organization not found for customer",
+				slog.String("customer.id", customer.ID))
+			return nil
+		}
+		return err
+	}
+
+	_, err = service.syncOrganizationWithStripeCustomer(ctx, organization.ID)
+	return err
+}
+
+func (service *OrganizationsService) handlePaymentEventSubscription(ctx context.Context, event payments.Event) error {
+	logger := slogx.FromCtx(ctx)
+	subscription := event.Subscription
+
+	if _, isWebsiteEvent := subscription.Metadata["markdown_ninja_website_id"]; isWebsiteEvent {
+		return service.storeService.HandlePaymentEvent(ctx, event)
+	}
+
+	if subscription.CustomerID == nil {
+		logger.Error("organizations.handlePaymentEventSubscription: customer is null",
+			slog.String("subscription.id", subscription.ID))
+		return nil
+	}
+
+	organization, err := service.repo.FindOrganizationByStripeCustomerID(ctx, service.db, *subscription.CustomerID, false)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			logger.Error("organizations.handlePaymentEventSubscription: organization not found for customer",
+				slog.String("subscription.id", subscription.ID), slog.String("customer.id", *subscription.CustomerID))
+			return nil
+		}
+		return err
+	}
+
+	_, err = service.syncOrganizationWithStripeCustomer(ctx, organization.ID)
+	return err
+}
+
+func (service *OrganizationsService) handlePaymentEventInvoice(ctx context.Context, event payments.Event) error {
+	logger := slogx.FromCtx(ctx)
+	invoice := event.Invoice
+
+	if _, isWebsiteEvent := invoice.Metadata["markdown_ninja_website_id"]; isWebsiteEvent {
+		return service.storeService.HandlePaymentEvent(ctx, event)
+	}
+
+	if invoice.PaymentID == nil {
+		logger.Error("organizations.handlePaymentEventInvoice: invoice.payment_id is null",
+			slog.String("invoice.id", invoice.ID),
+			slog.Group("event", slog.String("id", event.ID), slog.String("type", string(event.Type))),
+		)
+		return nil
+	}
+
+	// it's not possible to set metdata to invoices with stripe checkout sessions, so we need to fetch the payment
+	// to get the metadata
+	var payment payments.Payment
+	err := retry.Do(func() (retryErr error) {
+		payment, retryErr = service.paymentProvider.GetPayment(ctx, *invoice.PaymentID)
+		return retryErr
+	}, retry.Context(ctx), retry.Attempts(3), retry.Delay(20*time.Millisecond))
+	if err != nil {
+		return fmt.Errorf("organizations.handlePaymentEventInvoice: error getting payment for invoice [%s]: %w", invoice.ID, err)
+	}
+
+	if _, isWebsiteEvent := payment.Metadata["markdown_ninja_website_id"]; isWebsiteEvent {
+		return service.storeService.HandlePaymentEvent(ctx, event)
+	}
+
+	if invoice.CustomerID == nil {
+		logger.Error("organizations.handlePaymentEventInvoice: customer is null",
+			slog.String("invoice.id", invoice.ID))
+		return nil
+	}
+
+	organization, err := service.repo.FindOrganizationByStripeCustomerID(ctx, service.db, *invoice.CustomerID, false)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			logger.Error("organizations.handlePaymentEventInvoice: organization not found for customer",
+				slog.String("invoice.id", invoice.ID), slog.String("customer.id", *invoice.CustomerID))
+			return nil
+		}
+		return err
+	}
+
+	_, err = service.syncOrganizationWithStripeCustomer(ctx, organization.ID)
+	return err
+}
+
+func (service *OrganizationsService) handlePaymentEventCharge(ctx context.Context, event payments.Event) error {
+	logger := slogx.FromCtx(ctx)
+	charge := event.Charge
+
+	if _, isWebsiteEvent := charge.Metadata["markdown_ninja_website_id"]; isWebsiteEvent {
+		return service.storeService.HandlePaymentEvent(ctx, event)
+	}
+
+	if charge.CustomerID == nil {
+		logger.Warn("organizations.handlePaymentEventCharge: customer is null for charge",
+			slog.String("charge.id", charge.ID))
+		return nil
+	}
+
+	organization, err := service.repo.FindOrganizationByStripeCustomerID(ctx, service.db, *charge.CustomerID, false)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			logger.Error("organizations.handlePaymentEventCharge: organization not found for customer",
+				slog.String("charge.id", charge.ID), slog.String("customer.id", *charge.CustomerID))
+			return nil
+		}
+		return err
+	}
+
+	_, err = service.syncOrganizationWithStripeCustomer(ctx, organization.ID)
+	return err
+}
+
+func (service *OrganizationsService) handlePaymentEventCheckoutSession(ctx context.Context, event payments.Event) error {
+	if _, isWebsiteEvent := event.CheckoutSession.Metadata["markdown_ninja_website_id"]; isWebsiteEvent {
+		return service.storeService.HandlePaymentEvent(ctx, event)
+	}
+
+	return nil
+}
+
+// func (service *OrganizationsService) handleStripeEventSubscriptionCreated(ctx context.Context, stripeEvent stripe.Event) (err error) {
+// 	logger := slogx.FromCtx(ctx)
+
+// 	stripeSubscription := &stripe.Subscription{}
+// 	err = json.Unmarshal(stripeEvent.Data.Raw, stripeSubscription)
+// 	if err != nil {
+// 		return fmt.Errorf("organizations.handleStripeEventSubscriptionCreated: error parsing event JSON: %w", err)
+// 	}
+
+// 	now := time.Now().UTC()
+// 	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+// 		var organization organizations.Organization
+// 		organization, txErr = service.repo.FindOrganizationByStripeCustomerID(ctx, tx, stripeSubscription.Customer.ID, true)
+// 		if txErr != nil {
+// 			if errs.IsNotFound(txErr) {
+// 				logger.Error("organizations.handleStripeEventSubscriptionCreated: organization not found for stripe customer",
+// 					slog.String("stripe.customer.id", stripeSubscription.Customer.ID))
+// 				return nil
+// 			}
+// 			return txErr
+// 		}
+
+// 		organization.StripeSubscriptionID = &stripeSubscription.ID
+// 		subscriptionStartedAt := time.Unix(stripeSubscription.StartDate, 0).UTC()
+// 		organization.SubscriptionStartedAt = &subscriptionStartedAt
+// 		organization.UpdatedAt = now
+// 		txErr = service.repo.UpdateOrganization(ctx, tx, organization)
+// 		return txErr
+// 	})
+// 	if err != nil {
+// 		return
+// 	}
+
+// 	return
+// }
+
+// func (service *OrganizationsService) handleStripeEventSubscriptionDeleted(ctx context.Context, stripeEvent stripe.Event) (err error) {
+// 	logger := slogx.FromCtx(ctx)
+
+// 	stripeSubscription := &stripe.Subscription{}
+// 	err = json.Unmarshal(stripeEvent.Data.Raw, stripeSubscription)
+// 	if err != nil {
+// 		return fmt.Errorf("organizations.handleStripeEventSubscriptionDeleted: error parsing event JSON: %w", err)
+// 	}
+
+// 	now := time.Now().UTC()
+// 	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+// 		var organization organizations.Organization
+// 		organization, txErr = service.repo.FindOrganizationByStripeCustomerID(ctx, tx, stripeSubscription.Customer.ID, true)
+// 		if txErr != nil {
+// 			if errs.IsNotFound(txErr) {
+// 				logger.Error("organizations.handleStripeEventSubscriptionDeleted: organization not found for stripe customer",
+// 					slog.String("stripe.customer.id", stripeSubscription.Customer.ID))
+// 				return nil
+// 			}
+// 			return txErr
+// 		}
+
+// 		organization.StripeSubscriptionID = nil
+// 		organization.SubscriptionStartedAt = nil
+// 		organization.Plan = organizations.PlanFree
+// 		organization.ExtraStaffs = 0
+// 		organization.ExtraWebsites = 0
+// 		organization.ExtraStorage = 0
+
+// 		organization.UpdatedAt = now
+// 		txErr = service.repo.UpdateOrganization(ctx, tx, organization)
+// 		return txErr
+// 	})
+// 	if err != nil {
+// 		return
+// 	}
+
+// 	return nil
+// }
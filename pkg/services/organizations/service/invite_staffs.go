@@ -63,7 +63,7 @@ func (service *OrganizationsService) InviteStaffs(ctx context.Context, input org
 		return
 	}
 
-	validateEmailsRes, err := service.pingoo.LookupEmails(ctx, pingoo.LookupEmailsInput{
+	validateEmailsRes, err := service.kernel.LookupEmails(ctx, pingoo.LookupEmailsInput{
 		Emails:    emails,
 		MxRecords: new(true),
 	})
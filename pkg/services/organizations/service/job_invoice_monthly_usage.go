@@ -10,10 +10,8 @@ import (
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/log/slogx"
 	"github.com/skerkour/stdx-go/retry"
-	"github.com/stripe/stripe-go/v81"
-	"github.com/stripe/stripe-go/v81/invoice"
-	"github.com/stripe/stripe-go/v81/invoiceitem"
 	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/services/organizations"
 	"markdown.ninja/pkg/timeutil"
 )
@@ -92,48 +90,23 @@ func (service *OrganizationsService) invoiceForUsageData(ctx context.Context, db
 		return nil
 	}
 
-	// Create an invoice item for the usage
-	invoiceItemParams := &stripe.InvoiceItemParams{
-		Customer: stripe.String(*organization.StripeCustomerID),
-		Amount:   stripe.Int64(usageBasedAmountToPayInCents),
-		// Quantity:    stripe.Int64(billedEmailsSent),
-		Currency:    stripe.String(string(stripe.CurrencyEUR)),
-		Description: stripe.String(fmt.Sprintf("Emails: %d", emailsSent)),
-		Period: &stripe.InvoiceItemPeriodParams{
-			Start: stripe.Int64(invoicePeriodStart.Unix()),
-			End:   stripe.Int64(invoicePeriodEnd.Unix()),
-		},
-		Metadata: map[string]string{
-			"markdown_ninja_organization_id": organization.ID.String(),
-			"markdown_ninja_emails_sent":     strconv.Itoa(int(emailsSent)),
-		},
-	}
-	invoiceItemParams.SetIdempotencyKey("create_invoice_item-" + idempotencyKey)
-	err = retry.Do(func() error {
-		_, retryErr := invoiceitem.New(invoiceItemParams)
-		return retryErr
-	}, retry.Context(ctx), retry.Attempts(15), retry.Delay(time.Second), retry.DelayType(retry.FixedDelay))
-	if err != nil {
-		err = fmt.Errorf("organizations.invoiceForUsageData: error creating monthly usage invoice item for organization [%s]: %w", organization.ID.String(), err)
-		logger.Error(err.Error(), slog.String("organization_id", organization.ID.String()))
-		return err
-	}
-
-	// Create and finalize the invoice
-	var newStripeInvoice *stripe.Invoice
-	newInvoiceParams := &stripe.InvoiceParams{
-		Customer:                    stripe.String(*organization.StripeCustomerID),
-		AutoAdvance:                 stripe.Bool(true),
-		PendingInvoiceItemsBehavior: stripe.String("include"),
-		// PendingInvoiceItemsBehavior: stripe.String("include"),
+	// Create and finalize the invoice for the usage
+	var newInvoice payments.Invoice
+	createInvoiceInput := payments.CreateInvoiceInput{
+		CustomerID:  *organization.StripeCustomerID,
+		Amount:      usageBasedAmountToPayInCents,
+		Currency:    "EUR",
+		Description: fmt.Sprintf("Emails: %d", emailsSent),
+		PeriodStart: invoicePeriodStart,
+		PeriodEnd:   invoicePeriodEnd,
 		Metadata: map[string]string{
 			"markdown_ninja_organization_id": organization.ID.String(),
 			"markdown_ninja_emails_sent":     strconv.Itoa(int(emailsSent)),
 		},
+		IdempotencyKey: idempotencyKey,
 	}
-	newInvoiceParams.SetIdempotencyKey("create_invoice-" + idempotencyKey)
 	err = retry.Do(func() (retryErr error) {
-		newStripeInvoice, retryErr = invoice.New(newInvoiceParams)
+		newInvoice, retryErr = service.paymentProvider.CreateInvoice(ctx, createInvoiceInput)
 		return retryErr
 	}, retry.Context(ctx), retry.Attempts(15), retry.Delay(time.Second), retry.DelayType(retry.FixedDelay))
 	if err != nil {
@@ -142,34 +115,22 @@ func (service *OrganizationsService) invoiceForUsageData(ctx context.Context, db
 		return err
 	}
 
-	finalizeInvoiceParams := &stripe.InvoiceFinalizeInvoiceParams{}
-	finalizeInvoiceParams.SetIdempotencyKey("finalize_invoice-" + idempotencyKey)
-	err = retry.Do(func() error {
-		_, retryErr := invoice.FinalizeInvoice(newStripeInvoice.ID, finalizeInvoiceParams)
-		return retryErr
-	}, retry.Context(ctx), retry.Attempts(15), retry.Delay(time.Second), retry.DelayType(retry.FixedDelay))
-	if err != nil {
-		err = fmt.Errorf("organizations.invoiceForUsageData: error finalizing the invoice [%s]: %w", organization.ID.String(), err)
-		logger.Error(err.Error(), slog.String("organization_id", organization.ID.String()))
-		return err
-	}
-
 	if cancelingSubscription {
-		var paymentMethodToCharge *stripe.PaymentMethod
-		paymentMethodToCharge, err = service.getDefaultPaymentMethodForStripeCustomer(ctx, *organization.StripeCustomerID)
+		var paymentMethodToCharge *payments.PaymentMethod
+		paymentMethodToCharge, err = service.getDefaultPaymentMethod(ctx, *organization.StripeCustomerID)
 		if err != nil || paymentMethodToCharge == nil {
 			err = errs.InvalidArgument("Please make sure that a valid payment method is attached to your account before canceling your subscription")
 			return
 		}
 
 		// if the user is canceling their subscription, then we immediately pay the invoice
-		payInvoiceParams := &stripe.InvoicePayParams{
-			PaymentMethod: stripe.String(paymentMethodToCharge.ID),
+		payInvoiceInput := payments.PayInvoiceInput{
+			InvoiceID:       newInvoice.ID,
+			PaymentMethodID: paymentMethodToCharge.ID,
+			IdempotencyKey:  idempotencyKey,
 		}
-		payInvoiceParams.SetIdempotencyKey("pay_invoice-" + idempotencyKey)
 		err = retry.Do(func() error {
-			_, retryErr := invoice.Pay(newStripeInvoice.ID, payInvoiceParams)
-			return retryErr
+			return service.paymentProvider.PayInvoice(ctx, payInvoiceInput)
 		}, retry.Context(ctx), retry.Attempts(15), retry.Delay(time.Second), retry.DelayType(retry.FixedDelay))
 		if err != nil {
 			err = fmt.Errorf("organizations.invoiceForUsageData: error paying the invoice [%s]: %w", organization.ID.String(), err)
@@ -6,7 +6,6 @@ import (
 	"fmt"
 
 	"github.com/skerkour/stdx-go/queue"
-	"markdown.ninja/pingoo-go"
 	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/organizations"
 	"markdown.ninja/pkg/services/organizations/templates"
@@ -22,7 +21,7 @@ func (service *OrganizationsService) JobSendStaffInvitations(ctx context.Context
 	jobs := make([]queue.NewJobInput, 0, len(invitations))
 
 	for _, invitation := range invitations {
-		inviter, err := service.pingoo.GetUser(ctx, pingoo.GetUserInput{ID: invitation.Invitation.InviterID})
+		inviter, err := service.kernel.FindUser(ctx, invitation.Invitation.InviterID)
 		if err != nil {
 			return err
 		}
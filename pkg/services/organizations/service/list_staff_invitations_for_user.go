@@ -7,7 +7,6 @@ import (
 	"github.com/skerkour/stdx-go/iterx"
 	"github.com/skerkour/stdx-go/set"
 	"github.com/skerkour/stdx-go/uuid"
-	"markdown.ninja/pingoo-go"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/organizations"
@@ -29,13 +28,13 @@ func (service *OrganizationsService) ListUserInvitations(ctx context.Context, _
 	uniqueInvitersIds := set.NewFromIter(iterx.Map(slices.Values(invitations), func(invit organizations.StaffInvitationWithOrganizationDetails) uuid.UUID {
 		return invit.Invitation.InviterID
 	}))
-	inviters, err := service.pingoo.ListUsers(ctx, pingoo.ListUsersInput{IDs: uniqueInvitersIds.ToSlice()})
+	inviters, err := service.kernel.FindUsers(ctx, uniqueInvitersIds.ToSlice())
 	if err != nil {
 		return
 	}
 
-	invitersByID := make(map[uuid.UUID]pingoo.User, len(inviters.Data))
-	for _, inviter := range inviters.Data {
+	invitersByID := make(map[uuid.UUID]kernel.User, len(inviters))
+	for _, inviter := range inviters {
 		invitersByID[inviter.ID] = inviter
 	}
 
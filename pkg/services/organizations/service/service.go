@@ -6,8 +6,8 @@ import (
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/queue"
 	"markdown.ninja/cmd/mdninja-server/config"
-	"markdown.ninja/pingoo-go"
 	"markdown.ninja/pkg/mailer"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/services/content"
 	"markdown.ninja/pkg/services/events"
 	"markdown.ninja/pkg/services/kernel"
@@ -22,6 +22,7 @@ type OrganizationsService struct {
 	db                 db.DB
 	mailer             mailer.Mailer
 	queue              queue.Queue
+	paymentProvider    payments.Provider
 	stripeConfig       config.Stripe
 	httpConfig         config.Http
 	isSelfHosted       bool
@@ -32,13 +33,12 @@ type OrganizationsService struct {
 	eventsService   events.Service
 	contentService  content.Service
 	storeService    store.Service
-	pingoo          *pingoo.Client
 
 	staffInvitationEmailTemplate *template.Template
 }
 
 func NewOrganizationsService(conf config.Config, db db.DB, mailer mailer.Mailer, queue queue.Queue,
-	kernel kernel.PrivateService, pingoo *pingoo.Client) *OrganizationsService {
+	paymentProvider payments.Provider, kernel kernel.PrivateService) *OrganizationsService {
 	repo := repository.NewOrganizationsRepository()
 
 	staffInvitationEmailTemplate := template.Must(template.New("organizations.StaffInvitationEmailTemplate").Parse(templates.StaffInvitationEmailTemplate))
@@ -48,6 +48,7 @@ func NewOrganizationsService(conf config.Config, db db.DB, mailer mailer.Mailer,
 		db:                 db,
 		mailer:             mailer,
 		queue:              queue,
+		paymentProvider:    paymentProvider,
 		stripeConfig:       *conf.Stripe,
 		httpConfig:         conf.HTTP,
 		isSelfHosted:       !conf.Saas,
@@ -59,8 +60,6 @@ func NewOrganizationsService(conf config.Config, db db.DB, mailer mailer.Mailer,
 		contentService:  nil,
 		storeService:    nil,
 
-		pingoo: pingoo,
-
 		staffInvitationEmailTemplate: staffInvitationEmailTemplate,
 	}
 }
@@ -8,8 +8,7 @@ import (
 
 	"github.com/skerkour/stdx-go/guid"
 	"github.com/skerkour/stdx-go/log/slogx"
-	"github.com/stripe/stripe-go/v81"
-	stripecustomer "github.com/stripe/stripe-go/v81/customer"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/organizations"
@@ -62,96 +61,83 @@ func (service *OrganizationsService) syncOrganizationWithStripeCustomer(ctx cont
 		return
 	}
 
-	logger = logger.With(slog.String("organization.id", organization.ID.String()), slog.String("stripe.customer.id", *organization.StripeCustomerID))
+	logger = logger.With(slog.String("organization.id", organization.ID.String()), slog.String("customer.id", *organization.StripeCustomerID))
 
-	getCustomerParams := &stripe.CustomerParams{}
-	getCustomerParams.AddExpand("tax_ids")
-	getCustomerParams.AddExpand("subscriptions")
-	getCustomerParams.AddExpand("subscriptions.data.items")
-	stripeCustomer, err := stripecustomer.Get(*organization.StripeCustomerID, getCustomerParams)
+	customer, err := service.paymentProvider.GetCustomer(ctx, *organization.StripeCustomerID)
 	if err != nil {
-		err = fmt.Errorf("organizations.syncOrganizationWithStripeCustomer: fetching stripe customer: %w", err)
+		err = fmt.Errorf("organizations.syncOrganizationWithStripeCustomer: fetching customer: %w", err)
 		return
 	}
 
 	// Update organization from customer
 
-	organization.BillingInformation.Name = stripeCustomer.Name
-	organization.BillingInformation.Email = stripeCustomer.Email
-	organization.BillingInformation.AddressLine1 = stripeCustomer.Address.Line1
-	organization.BillingInformation.AddressLine2 = stripeCustomer.Address.Line2
-	organization.BillingInformation.PostalCode = stripeCustomer.Address.PostalCode
-	organization.BillingInformation.City = stripeCustomer.Address.City
-	organization.BillingInformation.State = stripeCustomer.Address.State
-	organization.BillingInformation.CountryCode = stripeCustomer.Address.Country
-
-	if stripeCustomer.TaxIDs != nil && len(stripeCustomer.TaxIDs.Data) != 0 {
-		for _, taxID := range stripeCustomer.TaxIDs.Data {
-			if taxID.Type == stripe.TaxIDTypeEUVAT {
-				organization.BillingInformation.TaxID = &taxID.Value
-				break
-			}
+	organization.BillingInformation.Name = customer.Name
+	organization.BillingInformation.Email = customer.Email
+	organization.BillingInformation.AddressLine1 = customer.Address.Line1
+	organization.BillingInformation.AddressLine2 = customer.Address.Line2
+	organization.BillingInformation.PostalCode = customer.Address.PostalCode
+	organization.BillingInformation.City = customer.Address.City
+	organization.BillingInformation.State = customer.Address.State
+	organization.BillingInformation.CountryCode = customer.Address.Country
+
+	for _, taxID := range customer.TaxIDs {
+		if taxID.Type == payments.TaxIDTypeEUVAT {
+			organization.BillingInformation.TaxID = &taxID.Value
+			break
 		}
 	}
 
 	// Update subscription if needed
-	if stripeCustomer.Subscriptions != nil {
-		nonCanceledSubscriptionsCount := 0
-
-		if len(stripeCustomer.Subscriptions.Data) != 0 {
-			for _, stripeSubscription := range stripeCustomer.Subscriptions.Data {
-				// ignore canceled subscriptions
-				if stripeSubscription.Status == stripe.SubscriptionStatusCanceled {
-					continue
-				}
-				nonCanceledSubscriptionsCount += 1
-
-				organization.StripeSubscriptionID = &stripeSubscription.ID
-
-				if stripeSubscription.Items != nil && stripeSubscription.Items.Data != nil {
-					for _, item := range stripeSubscription.Items.Data {
-						if item.Price.ID == service.stripeConfig.Prices.Slots {
-							organization.ExtraSlots = item.Quantity
-						}
-					}
-				}
-
-				planStr, planOk := stripeSubscription.Metadata["markdown_ninja_plan"]
-				if planOk {
-					organization.Plan = kernel.PlanID(planStr)
-				} else {
-					logger.Error("organizations.syncOrganizationWithStripeCustomer: markdown_ninja_plan metadata is missing for subscription")
-				}
-
-				stripeSubscriptionStartDate := time.Unix(stripeSubscription.StartDate, 0).UTC()
-				if organization.SubscriptionStartedAt == nil ||
-					!organization.SubscriptionStartedAt.Equal(stripeSubscriptionStartDate) {
-					organization.SubscriptionStartedAt = &stripeSubscriptionStartDate
-				}
-
-				if stripeSubscription.Status == stripe.SubscriptionStatusActive {
-					organization.PaymentDueSince = nil
-				} else if organization.PaymentDueSince == nil &&
-					(stripeSubscription.Status == stripe.SubscriptionStatusPastDue ||
-						stripeSubscription.Status == stripe.SubscriptionStatusUnpaid) {
-					organization.PaymentDueSince = &now
-				}
+	nonCanceledSubscriptionsCount := 0
+
+	for _, subscription := range customer.Subscriptions {
+		// ignore canceled subscriptions
+		if subscription.Status == payments.SubscriptionStatusCanceled {
+			continue
+		}
+		nonCanceledSubscriptionsCount += 1
+
+		organization.StripeSubscriptionID = &subscription.ID
+
+		for _, item := range subscription.Items {
+			if item.PriceID == service.stripeConfig.Prices.Slots {
+				organization.ExtraSlots = item.Quantity
 			}
 		}
 
-		// if no active subscription
-		if len(stripeCustomer.Subscriptions.Data) == 0 || nonCanceledSubscriptionsCount == 0 {
-			organization.StripeSubscriptionID = nil
-			organization.SubscriptionStartedAt = nil
-			organization.Plan = kernel.PlanFree.ID
-			organization.ExtraSlots = 0
-			organization.UsageLastInvoicedAt = nil
+		planStr, planOk := subscription.Metadata["markdown_ninja_plan"]
+		if planOk {
+			organization.Plan = kernel.PlanID(planStr)
+		} else {
+			logger.Error("organizations.syncOrganizationWithStripeCustomer: markdown_ninja_plan metadata is missing for subscription")
+		}
+
+		if subscription.StartedAt != nil &&
+			(organization.SubscriptionStartedAt == nil || !organization.SubscriptionStartedAt.Equal(*subscription.StartedAt)) {
+			organization.SubscriptionStartedAt = subscription.StartedAt
+		}
+
+		if subscription.Status == payments.SubscriptionStatusActive {
 			organization.PaymentDueSince = nil
-		} else if nonCanceledSubscriptionsCount > 1 {
-			logger.Error("organizations.syncOrganizationWithStripeCustomer: Organization has more than 1 non-canceled subscription")
+		} else if organization.PaymentDueSince == nil &&
+			(subscription.Status == payments.SubscriptionStatusPastDue ||
+				subscription.Status == payments.SubscriptionStatusUnpaid) {
+			organization.PaymentDueSince = &now
 		}
 	}
 
+	// if no active subscription
+	if nonCanceledSubscriptionsCount == 0 {
+		organization.StripeSubscriptionID = nil
+		organization.SubscriptionStartedAt = nil
+		organization.Plan = kernel.PlanFree.ID
+		organization.ExtraSlots = 0
+		organization.UsageLastInvoicedAt = nil
+		organization.PaymentDueSince = nil
+	} else if nonCanceledSubscriptionsCount > 1 {
+		logger.Error("organizations.syncOrganizationWithStripeCustomer: Organization has more than 1 non-canceled subscription")
+	}
+
 	organization.UpdatedAt = now
 	err = service.repo.UpdateOrganization(ctx, tx, organization)
 	if err != nil {
@@ -6,9 +6,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/stripe/stripe-go/v81"
-	stripecustomer "github.com/stripe/stripe-go/v81/customer"
 	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/organizations"
@@ -35,8 +34,8 @@ func (service *OrganizationsService) UpdateOrganization(ctx context.Context, inp
 			return
 		}
 
-		// plan and extra slots can only be updated by admins
-		if input.Plan != nil || input.ExtraSlots != nil {
+		// plan, extra slots and send rate can only be updated by admins
+		if input.Plan != nil || input.ExtraSlots != nil || input.EmailsSendRate != nil {
 			err = kernel.ErrPermissionDenied
 			return
 		}
@@ -75,34 +74,19 @@ func (service *OrganizationsService) UpdateOrganization(ctx context.Context, inp
 		}
 		org.BillingInformation = billingInfo
 		if org.StripeCustomerID != nil {
-			updateStripeCustomerParams := &stripe.CustomerParams{
-				Name:  stripe.String(org.BillingInformation.Name),
-				Email: stripe.String(org.BillingInformation.Email),
-				Address: &stripe.AddressParams{
-					Line1:      stripe.String(org.BillingInformation.AddressLine1),
-					Line2:      stripe.String(org.BillingInformation.AddressLine2),
-					City:       stripe.String(org.BillingInformation.City),
-					PostalCode: stripe.String(org.BillingInformation.PostalCode),
-					State:      stripe.String(org.BillingInformation.State),
-					Country:    stripe.String(org.BillingInformation.CountryCode),
-				},
-			}
-			_, err = stripecustomer.Update(*org.StripeCustomerID, updateStripeCustomerParams)
-			if err != nil {
-				err = fmt.Errorf("organizations.UpdateOrganization: error updating stripe customer for organization %s: %w", org.ID.String(), err)
-				return
-			}
-
-			var stripeCustomerTaxIds []*stripe.TaxID
-			stripeCustomerTaxIds, err = service.fetchStripeTaxIDsForCustomer(*org.StripeCustomerID)
+			err = service.paymentProvider.UpdateCustomer(ctx, *org.StripeCustomerID, payments.UpdateCustomerInput{
+				Name:    org.BillingInformation.Name,
+				Email:   org.BillingInformation.Email,
+				Address: new(billingInformationToAddress(org.BillingInformation)),
+			})
 			if err != nil {
-				err = fmt.Errorf("organizations.UpdateOrganization: fetching taxIDs for organization [%s]: %w", org.ID, err)
+				err = fmt.Errorf("organizations.UpdateOrganization: error updating customer for organization %s: %w", org.ID.String(), err)
 				return
 			}
 
-			err = service.updateStripeTaxIDIfNeeded(ctx, org, stripeCustomerTaxIds)
+			err = service.updateTaxIDIfNeeded(ctx, org)
 			if err != nil {
-				err = fmt.Errorf("organizations.UpdateOrganization: updating stripe tax ID for organization [%s]: %w", org.ID, err)
+				err = fmt.Errorf("organizations.UpdateOrganization: updating tax ID for organization [%s]: %w", org.ID, err)
 				return
 			}
 		}
@@ -124,6 +108,14 @@ func (service *OrganizationsService) UpdateOrganization(ctx context.Context, inp
 		org.ExtraSlots = *input.ExtraSlots
 	}
 
+	if input.EmailsSendRate != nil {
+		err = validateEmailsSendRate(*input.EmailsSendRate)
+		if err != nil {
+			return
+		}
+		org.EmailsSendRate = *input.EmailsSendRate
+	}
+
 	org.UpdatedAt = time.Now().UTC()
 	err = service.repo.UpdateOrganization(ctx, tx, org)
 	if err != nil {
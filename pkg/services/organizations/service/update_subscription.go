@@ -6,11 +6,8 @@ import (
 	"time"
 
 	"github.com/skerkour/stdx-go/log/slogx"
-	"github.com/stripe/stripe-go/v81"
-	"github.com/stripe/stripe-go/v81/checkout/session"
-	stripecustomer "github.com/stripe/stripe-go/v81/customer"
-	stripesubscription "github.com/stripe/stripe-go/v81/subscription"
 	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/organizations"
@@ -102,12 +99,9 @@ func (service *OrganizationsService) UpdateSubscription(ctx context.Context, inp
 				return
 			}
 
-			params := &stripe.SubscriptionCancelParams{
-				// InvoiceNow: stripe.Bool(true),
-			}
-			_, err = stripesubscription.Cancel(*organization.StripeSubscriptionID, params)
+			_, err = service.paymentProvider.CancelSubscription(ctx, *organization.StripeSubscriptionID)
 			if err != nil {
-				err = fmt.Errorf("organizations.UpdateSubscription: error cancelling stripe subscription for organization [%s]: %w", organization.ID, err)
+				err = fmt.Errorf("organizations.UpdateSubscription: error cancelling subscription for organization [%s]: %w", organization.ID, err)
 				return
 			}
 		}
@@ -136,28 +130,17 @@ func (service *OrganizationsService) UpdateSubscription(ctx context.Context, inp
 
 	organization.ExtraSlots = input.ExtraSlots
 
-	// if the organization has no stripe customer, then we create one
+	// if the organization has no customer, then we create one
 	if organization.StripeCustomerID == nil {
-		var stripeCustomer *stripe.Customer
-		createStripeCustomerParams := service.generateStripeCustomerParams(organization)
-		createStripeCustomerParams.AddExpand("invoice_settings.default_payment_method")
-		stripeCustomer, err = stripecustomer.New(createStripeCustomerParams)
+		var customer payments.Customer
+		customer, err = service.paymentProvider.CreateCustomer(ctx, generateCreateCustomerInput(organization))
 		if err != nil {
-			err = fmt.Errorf("organizations.UpdateSubscription: creating Stripe customer: %w", err)
+			err = fmt.Errorf("organizations.UpdateSubscription: creating customer: %w", err)
 			return
 		}
 
-		organization.StripeCustomerID = &stripeCustomer.ID
+		organization.StripeCustomerID = &customer.ID
 	}
-	// else {
-	// 	getStripeCustomerParams := &stripe.CustomerParams{}
-	// 	getStripeCustomerParams.AddExpand("invoice_settings.default_payment_method")
-	// 	stripeCustomer, err = stripecustomer.Get(*organization.StripeCustomerID, getStripeCustomerParams)
-	// 	if err != nil {
-	// 		err = fmt.Errorf("organizations.UpdateSubscription: fetching Stripe customer: %w", err)
-	// 		return
-	// 	}
-	// }
 
 	// TODO: if the customer already has a valid (non-expired) payment method attached. Do we really want to
 	// create a stripe checkout session?
@@ -165,99 +148,73 @@ func (service *OrganizationsService) UpdateSubscription(ctx context.Context, inp
 
 	// if there is no active subscription, we create one
 	if organization.StripeSubscriptionID == nil {
-		paymentMethodToUse, _ := service.getDefaultPaymentMethodForStripeCustomer(ctx, *organization.StripeCustomerID)
+		paymentMethodToUse, _ := service.getDefaultPaymentMethod(ctx, *organization.StripeCustomerID)
 		if paymentMethodToUse != nil {
 			// if a payment method is available, try to charge directly
-			var newSubscriptionNewLineItems []*stripe.SubscriptionItemsParams
-			newSubscriptionNewLineItems, err = service.getStripeSubscriptionLineItemsForPlan(input.Plan, organization.ExtraSlots)
+			var newSubscriptionItems []payments.SubscriptionItemInput
+			newSubscriptionItems, err = service.getSubscriptionItemsForPlan(input.Plan, organization.ExtraSlots)
 			if err != nil {
 				err = fmt.Errorf("organizations.UpdateSubscription: %w", err)
 				return
 			}
 
-			// var newSubscription *stripe.Subscription
-			createSubscriptionParams := &stripe.SubscriptionParams{
-				Customer:             stripe.String(*organization.StripeCustomerID),
-				Items:                newSubscriptionNewLineItems,
-				DefaultPaymentMethod: stripe.String(paymentMethodToUse.ID),
+			_, err = service.paymentProvider.CreateSubscription(ctx, payments.CreateSubscriptionInput{
+				CustomerID:      *organization.StripeCustomerID,
+				Items:           newSubscriptionItems,
+				PaymentMethodID: paymentMethodToUse.ID,
 				Metadata: map[string]string{
 					"markdown_ninja_organization_id": organization.ID.String(),
 					"markdown_ninja_plan":            string(input.Plan),
 				},
-			}
-			_, err = stripesubscription.New(createSubscriptionParams)
+			})
 			if err != nil {
+				err = fmt.Errorf("organizations.UpdateSubscription: %w", err)
 				return
 			}
-			// the subscription id will be updated when receiving the webhooks / syncing Stripe
-			// organization.StripeSubscriptionID = &newSubscription.ID
+			// the subscription id will be updated when receiving the webhooks / syncing with the payment provider
 		} else {
-			// otherwise redirecto to stripe Checkout
-			var stripeCheckoutSession *stripe.CheckoutSession
-			var stripeCheckoutSessionLineItems []*stripe.CheckoutSessionLineItemParams
-			stripeCheckoutSessionLineItems, err = service.getStripeCheckoutSessionLineItemsForPlan(input.Plan, organization.ExtraSlots)
+			// otherwise redirect to the checkout page
+			var checkoutSession payments.CheckoutSession
+			var checkoutSessionLineItems []payments.CheckoutSessionLineItem
+			checkoutSessionLineItems, err = service.getCheckoutSessionLineItemsForPlan(input.Plan, organization.ExtraSlots)
 			if err != nil {
 				err = fmt.Errorf("organizations.UpdateSubscription: %w", err)
 				return
 			}
 
-			// billingAnchor := timeutil.GetFirstDayOfNextMonth(time.Now().UTC()).UTC()
-			checkoutParams := service.generateStripeCheckoutSessionParams(organization, input.Plan, stripeCheckoutSessionLineItems)
-			stripeCheckoutSession, err = session.New(checkoutParams)
+			checkoutSessionInput := service.generateCheckoutSessionInput(organization, input.Plan, checkoutSessionLineItems)
+			checkoutSession, err = service.paymentProvider.CreateCheckoutSession(ctx, checkoutSessionInput)
 			if err != nil {
-				err = fmt.Errorf("organizations.UpdateSubscription: error creating stripe checkout session: %w", err)
+				err = fmt.Errorf("organizations.UpdateSubscription: error creating checkout session: %w", err)
 				return
 			}
 
-			ret.StripeCheckoutSessionUrl = &stripeCheckoutSession.URL
+			ret.StripeCheckoutSessionUrl = &checkoutSession.URL
 		}
 
 	} else {
-		// otherwise, we update the subscription
-		var stripeSubscription *stripe.Subscription
-		getStripeSubscriptionParams := &stripe.SubscriptionParams{}
-		stripeSubscription, err = stripesubscription.Get(*organization.StripeSubscriptionID, getStripeSubscriptionParams)
+		// otherwise, we replace the items of the subscription
+		var subscriptionItems []payments.SubscriptionItemInput
+		subscriptionItems, err = service.getSubscriptionItemsForPlan(input.Plan, organization.ExtraSlots)
 		if err != nil {
-			err = fmt.Errorf("organizations.UpdateSubscription: fetching stripe subscription for organization [%s]: %w", organization.ID, err)
+			err = fmt.Errorf("organizations.UpdateSubscription: %w", err)
 			return
 		}
 
-		// we first delete the existing subscription items
-		updateStripeSubscriptionParams := &stripe.SubscriptionParams{
-			ProrationBehavior: stripe.String(string(stripe.SubscriptionSchedulePhaseProrationBehaviorAlwaysInvoice)),
+		_, err = service.paymentProvider.UpdateSubscription(ctx, *organization.StripeSubscriptionID, payments.UpdateSubscriptionInput{
+			Items: subscriptionItems,
 			Metadata: map[string]string{
 				"markdown_ninja_organization_id": organization.ID.String(),
 				"markdown_ninja_plan":            string(input.Plan),
 			},
-		}
-
-		// existing subscription items are removed
-		// https://docs.stripe.com/billing/subscriptions/upgrade-downgrad
-		for _, existingSubscriptionItem := range stripeSubscription.Items.Data {
-			item := &stripe.SubscriptionItemsParams{
-				ID:      stripe.String(existingSubscriptionItem.ID),
-				Deleted: stripe.Bool(true),
-			}
-			updateStripeSubscriptionParams.Items = append(updateStripeSubscriptionParams.Items, item)
-		}
-
-		var subscriptionNewLineItems []*stripe.SubscriptionItemsParams
-		subscriptionNewLineItems, err = service.getStripeSubscriptionLineItemsForPlan(input.Plan, organization.ExtraSlots)
-		if err != nil {
-			err = fmt.Errorf("organizations.UpdateSubscription: %w", err)
-			return
-		}
-
-		updateStripeSubscriptionParams.Items = append(updateStripeSubscriptionParams.Items, subscriptionNewLineItems...)
-
-		_, err = stripesubscription.Update(*organization.StripeSubscriptionID, updateStripeSubscriptionParams)
+		})
 		if err != nil {
-			err = fmt.Errorf("organizations.UpdateSubscription: error updating stripe subscription: %w", err)
+			err = fmt.Errorf("organizations.UpdateSubscription: error updating subscription for organization [%s]: %w", organization.ID, err)
 			return
 		}
 	}
 
-	// The plan will be updated when receiving Stripe webhook / syncing the organization with Stripe
+	// The plan will be updated when receiving the webhooks / syncing the organization with the payment provider
 	// organization.Plan = input.Plan
 	err = service.repo.UpdateOrganization(ctx, tx, organization)
 	if err != nil {
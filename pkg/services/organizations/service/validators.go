@@ -180,3 +180,11 @@ func validateExtraSlots(extraSlots int64, planID kernel.PlanID) error {
 
 	return nil
 }
+
+func validateEmailsSendRate(sendRate int64) error {
+	if sendRate < 1 || sendRate > organizations.EmailsSendRateMax {
+		return errs.InvalidArgument(fmt.Sprintf("Emails send rate must be between 1 and %d", organizations.EmailsSendRateMax))
+	}
+
+	return nil
+}
@@ -71,12 +71,17 @@ type PageMetadata struct {
 	Language     string           `json:"language"`
 	BodyHash     kernel.BytesHex  `json:"body_hash"`
 	MetadataHash kernel.BytesHex  `json:"metadata_hash"`
+	// only active members can read members-only pages
+	Visibility content.PageVisibility `json:"visibility"`
 }
 
 type Page struct {
 	PageMetadata
 	Tags []Tag  `json:"tags"`
 	Body string `json:"body"`
+	// Locked is true when the page is members-only and the visitor is not an active member.
+	// In that case, Body only contains the teaser of the page.
+	Locked bool `json:"locked"`
 }
 
 type Tag struct {
@@ -85,9 +90,23 @@ type Tag struct {
 }
 
 type Contact struct {
-	Name                   string `json:"name"`
-	Email                  string `json:"email"`
-	SubscribedToNewsletter bool   `json:"subscribed_to_newsletter"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	NewsletterPreferences
+}
+
+// NewsletterPreferences are the topics that the contact receives while subscribed to the newsletter.
+// They are managed from the account of the contact, or from the preference center linked in the emails.
+type NewsletterPreferences struct {
+	SubscribedToNewsletter     bool                        `json:"subscribed_to_newsletter"`
+	ReceiveNewsletters         bool                        `json:"receive_newsletters"`
+	SubscribedToProductUpdates bool                        `json:"subscribed_to_product_updates"`
+	NewsletterDelivery         contacts.NewsletterDelivery `json:"newsletter_delivery"`
+	// the names of the tags of the posts that the contact wants to receive. Empty to receive all the posts
+	NewsletterTags []string `json:"newsletter_tags"`
+	// NewsletterDigests is true if the website sends digests, and thus if the contact can choose how
+	// to receive the posts
+	NewsletterDigests bool `json:"newsletter_digests"`
 }
 
 // type ServeContentOutput struct {
@@ -108,6 +127,24 @@ type Order struct {
 	Currency    websites.Currency `json:"currency"`
 	Status      store.OrderStatus `json:"status"`
 	InvoiceUrl  *string           `json:"invoice_url"`
+	// set when the order is a gift
+	GiftRecipientEmail *string      `json:"gift_recipient_email"`
+	LicenseKeys        []LicenseKey `json:"license_keys"`
+	// invoices and credit notes issued by the website for this order
+	Invoices []OrderInvoice `json:"invoices"`
+}
+
+type OrderInvoice struct {
+	Type   store.InvoiceType `json:"type"`
+	Number string            `json:"number"`
+	Url    string            `json:"url"`
+}
+
+type LicenseKey struct {
+	Key         string                 `json:"key"`
+	Status      store.LicenseKeyStatus `json:"status"`
+	ProductID   guid.GUID              `json:"product_id"`
+	ProductName string                 `json:"product_name"`
 }
 
 type Product struct {
@@ -117,14 +154,40 @@ type Product struct {
 	Description string            `json:"description"`
 	Type        store.ProductType `json:"type"`
 
-	Content []ProductPage `json:"content"`
+	Content []ProductPage  `json:"content"`
+	Ebooks  []ProductEbook `json:"ebooks"`
+	// only for bundles: the products included in the bundle
+	BundleItems []ProductBundleItem `json:"bundle_items"`
+	// Price is the price of the product for the current visitor. See store.ResolveCurrency for how
+	// the currency is chosen. Only returned by GetProduct.
+	Price *store.ProductPrice `json:"price"`
+	// only for courses
+	Progress *store.CourseProgress `json:"progress"`
+}
+
+type ProductBundleItem struct {
+	ID   guid.GUID         `json:"id"`
+	Name string            `json:"name"`
+	Type store.ProductType `json:"type"`
+}
+
+type ProductEbook struct {
+	Format store.ProductEbookFormat `json:"format"`
+	Url    string                   `json:"url"`
+	Size   int64                    `json:"size"`
 }
 
 type ProductPage struct {
 	ID       guid.GUID `json:"id"`
 	Position int64     `json:"position"`
 	Title    string    `json:"title"`
-	Body     string    `json:"body"`
+	// Body is empty if the page is locked
+	Body string `json:"body"`
+	// only for courses
+	Completed bool `json:"completed"`
+	// Locked is true if the page is drip content that is not available yet. See AvailableAt.
+	Locked      bool       `json:"locked"`
+	AvailableAt *time.Time `json:"available_at"`
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -174,6 +237,20 @@ type UnsubscribeInput struct {
 	Email string `json:"email"`
 }
 
+type GetNewsletterPreferencesInput struct {
+	// the token of the unsubscribe link
+	Token string `schema:"token"`
+}
+
+type UpdateNewsletterPreferencesInput struct {
+	// the token of the unsubscribe link
+	Token                      string                       `json:"token"`
+	ReceiveNewsletters         *bool                        `json:"receive_newsletters"`
+	SubscribedToProductUpdates *bool                        `json:"subscribed_to_product_updates"`
+	NewsletterDelivery         *contacts.NewsletterDelivery `json:"newsletter_delivery"`
+	NewsletterTags             *[]string                    `json:"newsletter_tags"`
+}
+
 type LoginOutput struct {
 	SessionID guid.GUID `json:"session_id"`
 }
@@ -183,9 +260,13 @@ type SubscribeOutput struct {
 }
 
 type UpdateMyAccount struct {
-	Email                  *string `json:"email"`
-	Name                   *string `json:"name"`
-	SubscribedToNewsletter *bool   `json:"subscribed_to_newsletter"`
+	Email                      *string                      `json:"email"`
+	Name                       *string                      `json:"name"`
+	SubscribedToNewsletter     *bool                        `json:"subscribed_to_newsletter"`
+	NewsletterDelivery         *contacts.NewsletterDelivery `json:"newsletter_delivery"`
+	ReceiveNewsletters         *bool                        `json:"receive_newsletters"`
+	SubscribedToProductUpdates *bool                        `json:"subscribed_to_product_updates"`
+	NewsletterTags             *[]string                    `json:"newsletter_tags"`
 
 	BillingAddress *kernel.Address `json:"billing_address"`
 }
@@ -202,6 +283,11 @@ type GetProductInput struct {
 	ProductID guid.GUID `schema:"id"`
 }
 
+type UpdateLessonProgressInput struct {
+	ProductPageID guid.GUID `json:"product_page_id"`
+	Completed     bool      `json:"completed"`
+}
+
 type SearchInput struct {
 	Query string `json:"query"`
 }
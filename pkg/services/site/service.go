@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"markdown.ninja/pkg/services/kernel"
+	"markdown.ninja/pkg/services/websites"
 )
 
 type Service interface {
@@ -16,6 +17,10 @@ type Service interface {
 	CompleteSubscription(ctx context.Context, input CompleteSubscriptionInput) (contact Contact, err error)
 	Logout(ctx context.Context, input kernel.EmptyInput) (err error)
 	Unsubscribe(ctx context.Context, input UnsubscribeInput) (err error)
+	// ServeOneClickUnsubscribe handles the RFC 8058 one-click unsubscriptions POSTed by the email clients
+	ServeOneClickUnsubscribe(res http.ResponseWriter, req *http.Request)
+	GetNewsletterPreferences(ctx context.Context, input GetNewsletterPreferencesInput) (ret NewsletterPreferences, err error)
+	UpdateNewsletterPreferences(ctx context.Context, input UpdateNewsletterPreferencesInput) (ret NewsletterPreferences, err error)
 	UpdateMyAccount(ctx context.Context, input UpdateMyAccount) (contact Contact, err error)
 	DeleteMyAccount(ctx context.Context, _ kernel.EmptyInput) (err error)
 
@@ -23,6 +28,8 @@ type Service interface {
 	ListMyOrders(ctx context.Context, input kernel.EmptyInput) (ret kernel.PaginatedResult[Order], err error)
 	ListMyProducts(ctx context.Context, input kernel.EmptyInput) (ret kernel.PaginatedResult[Product], err error)
 	GetProduct(ctx context.Context, input GetProductInput) (ret Product, err error)
+	UpdateLessonProgress(ctx context.Context, input UpdateLessonProgressInput) (err error)
+	ServeProductEbook(res http.ResponseWriter, req *http.Request)
 
 	// website
 	GetWebsite(ctx context.Context, input kernel.EmptyInput) (ret Website, err error)
@@ -38,6 +45,12 @@ type Service interface {
 	// TrackEventPageView is needed by special pages (ex: /blog) that don't require a headless API
 	// call
 	TrackEventPageView(ctx context.Context, input TrackEventPageViewInput) (err error)
+	// CheckApiRateLimit returns an error if the client has exceeded the API rate limit of the website
+	CheckApiRateLimit(ctx context.Context) (err error)
+	// ApplyFirewall evaluates the firewall rules of the website and returns true if the request
+	// has been handled by a rule
+	ApplyFirewall(res http.ResponseWriter, req *http.Request) (handled bool)
+	CompleteFirewallChallenge(ctx context.Context, input websites.CompleteFirewallChallengeInput) (err error)
 
 	// Jobs
 	JobSendLoginEmail(ctx context.Context, data JobSendLoginEmail) (err error)
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/skerkour/stdx-go/httpx"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"markdown.ninja/pingoo-go"
+	"markdown.ninja/pingoo-go/rules"
+	"markdown.ninja/pkg/jwt"
+	"markdown.ninja/pkg/server/cachecontrol"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/site/templates"
+	"markdown.ninja/pkg/services/websites"
+)
+
+// ApplyFirewall evaluates the firewall rules of the website against the request.
+// It returns true if a rule has handled the request (e.g. blocked or redirected), in which case
+// the request should not be processed further.
+// Errors are logged and the request is let through: the firewall fails open.
+func (service *SiteService) ApplyFirewall(res http.ResponseWriter, req *http.Request) (handled bool) {
+	ctx := req.Context()
+	httpCtx := httpctx.FromCtx(ctx)
+	logger := slogx.FromCtx(ctx)
+
+	if req.URL.Path == websites.FirewallChallengePath {
+		return false
+	}
+
+	website, err := service.websitesService.FindWebsiteByDomain(ctx, service.db, httpCtx.Hostname)
+	if err != nil {
+		// unknown websites are handled by the next handlers
+		return false
+	}
+
+	firewall, err := service.websitesService.FindFirewall(ctx, website)
+	if err != nil {
+		logger.Error("site.ApplyFirewall: error finding firewall", slogx.Err(err),
+			slog.String("website.id", website.ID.String()))
+		return false
+	}
+	if len(firewall.Rules) == 0 {
+		return false
+	}
+
+	firewallRequest := &rules.Request{
+		Method:    req.Method,
+		Path:      req.URL.Path,
+		Query:     httpCtx.Url.Query(),
+		UserAgent: httpCtx.Client.UserAgent,
+		Country:   httpCtx.Client.CountryCode,
+		ASN:       httpCtx.Client.ASN,
+		VerifyBot: func() rules.BotVerification {
+			// bots can only be verified with Pingoo
+			if service.pingooClient == nil {
+				return rules.BotVerificationNone
+			}
+
+			bot, err := service.pingooClient.VerifyBot(ctx, req, httpCtx.Client.IP, pingoo.GeoipRecord{
+				ASN:     httpCtx.Client.ASN,
+				Country: httpCtx.Client.CountryCode,
+			})
+			if err != nil {
+				logger.Warn("site.ApplyFirewall: error verifying bot", slogx.Err(err))
+				return rules.BotVerificationNone
+			}
+			return bot
+		},
+	}
+
+	for _, rule := range firewall.Rules {
+		if !rule.Match(firewallRequest) {
+			continue
+		}
+
+		switch rule.Action.Type {
+		case rules.ActionTypeSetHeader:
+			for _, header := range rule.Action.Headers {
+				res.Header().Set(header.Name, header.Value)
+			}
+			service.websitesService.RecordFirewallRuleHit(rule.ID)
+
+		case rules.ActionTypeRateLimit:
+			rateLimit := rule.Action.RateLimit
+			if service.rateLimiter.IsAllowed(ctx, "SiteService.Firewall", rule.ID.Bytes(), httpCtx.Client.IP.AsSlice(),
+				time.Duration(rateLimit.PeriodSeconds)*time.Second, rateLimit.Requests) {
+				continue
+			}
+			service.websitesService.RecordFirewallRuleHit(rule.ID)
+			res.Header().Set("Retry-After", strconv.FormatInt(rateLimit.PeriodSeconds, 10))
+			service.serveError(ctx, res, []byte("Too Many Requests\n"), http.StatusTooManyRequests)
+			return true
+
+		case rules.ActionTypeBlock:
+			service.websitesService.RecordFirewallRuleHit(rule.ID)
+			service.serveError(ctx, res, []byte("Access Denied\n"), http.StatusForbidden)
+			return true
+
+		case rules.ActionTypeRedirect:
+			service.websitesService.RecordFirewallRuleHit(rule.ID)
+			res.Header().Set(httpx.HeaderCacheControl, cachecontrol.NoCache)
+			http.Redirect(res, req, rule.Action.RedirectTo, rule.Action.RedirectStatus)
+			return true
+
+		case rules.ActionTypeChallenge:
+			if service.hasValidFirewallClearance(req, website, httpCtx) {
+				continue
+			}
+			service.websitesService.RecordFirewallRuleHit(rule.ID)
+			service.serveFirewallChallenge(ctx, res, website, httpCtx)
+			return true
+		}
+	}
+
+	return false
+}
+
+const (
+	jwtTypeFirewallChallenge = "firewall_challenge"
+	jwtTypeFirewallClearance = "firewall_clearance"
+)
+
+type jwtClaimsFirewall struct {
+	Type       string `json:"type"`
+	WebsiteID  string `json:"website_id"`
+	IP         string `json:"ip"`
+	Difficulty int64  `json:"difficulty,omitempty"`
+}
+
+func (service *SiteService) hasValidFirewallClearance(req *http.Request, website websites.Website, httpCtx *httpctx.Context) bool {
+	cookie, err := req.Cookie(websites.FirewallClearanceCookie)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+
+	var claims jwtClaimsFirewall
+	err = service.jwtProvider.ParseAndVerifyToken(cookie.Value, &claims)
+	if err != nil {
+		return false
+	}
+
+	return claims.Type == jwtTypeFirewallClearance &&
+		claims.WebsiteID == website.ID.String() &&
+		claims.IP == httpCtx.Client.IPStr
+}
+
+func (service *SiteService) serveFirewallChallenge(ctx context.Context, res http.ResponseWriter, website websites.Website,
+	httpCtx *httpctx.Context) {
+	expiresAt := time.Now().UTC().Add(websites.FirewallChallengeTimeout)
+	challenge, err := service.jwtProvider.NewSignedToken(jwtClaimsFirewall{
+		Type:       jwtTypeFirewallChallenge,
+		WebsiteID:  website.ID.String(),
+		IP:         httpCtx.Client.IPStr,
+		Difficulty: websites.FirewallChallengeDifficulty,
+	}, &jwt.TokenOptions{
+		ExpirationTime: &expiresAt,
+	})
+	if err != nil {
+		service.serveInternalError(ctx, res, fmt.Errorf("site: generating firewall challenge: %w", err),
+			httpCtx.Hostname, httpCtx.Url.Path)
+		return
+	}
+
+	var page bytes.Buffer
+	err = service.firewallChallengeTemplate.Execute(&page, templates.FirewallChallengeData{
+		Challenge:  challenge,
+		Difficulty: websites.FirewallChallengeDifficulty,
+		Endpoint:   websites.FirewallChallengePath,
+	})
+	if err != nil {
+		service.serveInternalError(ctx, res, fmt.Errorf("site: rendering firewall challenge: %w", err),
+			httpCtx.Hostname, httpCtx.Url.Path)
+		return
+	}
+
+	service.serveError(ctx, res, page.Bytes(), http.StatusForbidden)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/zeebo/blake3"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/content"
+	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/websites"
+)
+
+// findArchivePath returns the path of the public archive of the newsletters of the website, or an
+// empty string if the archive is disabled
+func (service *SiteService) findArchivePath(ctx context.Context, db db.Queryer, websiteID guid.GUID) (archivePath string, err error) {
+	emailsConfig, err := service.emailsService.FindWebsiteConfiguration(ctx, db, websiteID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			err = nil
+		}
+		return
+	}
+
+	archivePath = emailsConfig.ArchivePath
+	return
+}
+
+// findArchivePage returns the page of the newsletters archive served at path: either the index of the
+// archive or the web version of a newsletter. content.ErrPageNotFound is returned if path is not part of
+// the archive.
+func (service *SiteService) findArchivePage(ctx context.Context, website websites.Website, archivePath, path string) (page content.Page, err error) {
+	if archivePath == "" || !strings.HasPrefix(path, archivePath) {
+		err = content.ErrPageNotFound
+		return
+	}
+
+	if path == archivePath {
+		var newsletters []emails.Newsletter
+		newsletters, err = service.emailsService.FindArchivedNewsletters(ctx, service.db, website.ID, emails.ArchiveMaxNewsletters)
+		if err != nil {
+			return
+		}
+
+		page = service.archiveIndexPage(website, archivePath, newsletters)
+		return
+	}
+
+	newsletterID, err := guid.Parse(strings.TrimPrefix(path, archivePath+"/"))
+	if err != nil {
+		err = content.ErrPageNotFound
+		return
+	}
+
+	newsletter, err := service.emailsService.FindArchivedNewsletter(ctx, service.db, website.ID, newsletterID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			err = content.ErrPageNotFound
+		}
+		return
+	}
+
+	page = archivedNewsletterPage(website, archivePath, newsletter)
+	return
+}
+
+// archiveIndexPage lists the archived newsletters, the most recent first
+func (service *SiteService) archiveIndexPage(website websites.Website, archivePath string, newsletters []emails.Newsletter) content.Page {
+	var bodyMarkdown strings.Builder
+	updatedAt := website.CreatedAt
+
+	if len(newsletters) == 0 {
+		bodyMarkdown.WriteString("No newsletter has been sent yet.\n")
+	} else {
+		updatedAt = *newsletters[0].SentAt
+		bodyMarkdown.WriteString(fmt.Sprintf("[RSS feed](%s/feed.xml)\n\n", archivePath))
+		for _, newsletter := range newsletters {
+			bodyMarkdown.WriteString(fmt.Sprintf("- %s: [%s](%s/%s)\n", newsletter.SentAt.UTC().Format("January 2, 2006"),
+				escapeMarkdownLinkText(newsletter.Subject), archivePath, newsletter.ID.String()))
+		}
+	}
+
+	return archivePage(website, content.PageTypePage, archivePath, "Newsletter archive", updatedAt, bodyMarkdown.String(), website.ID)
+}
+
+func archivedNewsletterPage(website websites.Website, archivePath string, newsletter emails.Newsletter) content.Page {
+	page := archivePage(website, content.PageTypePost, archivePath+"/"+newsletter.ID.String(), newsletter.Subject,
+		newsletter.UpdatedAt, emails.NewsletterWebVersion(newsletter.BodyMarkdown), newsletter.ID)
+	page.Date = *newsletter.SentAt
+	page.CreatedAt = *newsletter.SentAt
+	return page
+}
+
+// archivePage builds a virtual page which is not stored in the database, so the pages of the archive
+// can be rendered by the themes like any other page
+func archivePage(website websites.Website, pageType content.PageType, path, title string, updatedAt time.Time,
+	bodyMarkdown string, id guid.GUID) content.Page {
+	bodyHash := blake3.Sum256([]byte(bodyMarkdown))
+	metadataHash := content.HashPageMetadata(pageType, path, updatedAt, false, website.Language, title, "",
+		nil, content.PageVisibilityPublic)
+
+	return content.Page{
+		ID:           id,
+		CreatedAt:    updatedAt,
+		UpdatedAt:    updatedAt,
+		Date:         updatedAt,
+		Type:         pageType,
+		Title:        title,
+		Path:         path,
+		Language:     website.Language,
+		Status:       content.PageStatusPublished,
+		BodyMarkdown: bodyMarkdown,
+		Size:         int64(len(bodyMarkdown)),
+		BodyHash:     bodyHash[:],
+		MetadataHash: metadataHash[:],
+		Visibility:   content.PageVisibilityPublic,
+		WebsiteID:    website.ID,
+		Tags:         []content.Tag{},
+	}
+}
+
+func escapeMarkdownLinkText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(text)
+}
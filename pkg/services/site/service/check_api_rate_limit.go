@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/server/httpctx"
+)
+
+func (service *SiteService) CheckApiRateLimit(ctx context.Context) (err error) {
+	httpCtx := httpctx.FromCtx(ctx)
+
+	website, err := service.websitesService.FindWebsiteByDomain(ctx, service.db, httpCtx.Hostname)
+	if err != nil {
+		return
+	}
+
+	if !service.rateLimiter.IsAllowed(ctx, "SiteService.Api", website.ID.Bytes(), httpCtx.Client.IP.AsSlice(), time.Minute,
+		uint64(website.RateLimits.WithDefaults().Api)) {
+		err = errs.TooManyRequests()
+		return
+	}
+
+	return
+}
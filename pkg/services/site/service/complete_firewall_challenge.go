@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"math/bits"
+	"net/http"
+	"time"
+
+	"markdown.ninja/pkg/jwt"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/websites"
+)
+
+const firewallChallengeNonceMaxLength = 64
+
+func (service *SiteService) CompleteFirewallChallenge(ctx context.Context, input websites.CompleteFirewallChallengeInput) (err error) {
+	httpCtx := httpctx.FromCtx(ctx)
+
+	website, err := service.websitesService.FindWebsiteByDomain(ctx, service.db, httpCtx.Hostname)
+	if err != nil {
+		return
+	}
+
+	if len(input.Nonce) > firewallChallengeNonceMaxLength {
+		err = websites.ErrFirewallChallengeIsNotValid
+		return
+	}
+
+	var claims jwtClaimsFirewall
+	err = service.jwtProvider.ParseAndVerifyToken(input.Challenge, &claims)
+	if err != nil {
+		err = websites.ErrFirewallChallengeIsNotValid
+		return
+	}
+
+	if claims.Type != jwtTypeFirewallChallenge ||
+		claims.WebsiteID != website.ID.String() ||
+		claims.IP != httpCtx.Client.IPStr ||
+		!hasLeadingZeroBits(sha256.Sum256([]byte(input.Challenge+input.Nonce)), claims.Difficulty) {
+		err = websites.ErrFirewallChallengeIsNotValid
+		return
+	}
+
+	expiresAt := time.Now().UTC().Add(websites.FirewallClearanceCookieMaxAge)
+	clearance, err := service.jwtProvider.NewSignedToken(jwtClaimsFirewall{
+		Type:      jwtTypeFirewallClearance,
+		WebsiteID: website.ID.String(),
+		IP:        httpCtx.Client.IPStr,
+	}, &jwt.TokenOptions{
+		ExpirationTime: &expiresAt,
+	})
+	if err != nil {
+		return
+	}
+
+	httpCtx.Response.Cookies = append(httpCtx.Response.Cookies, http.Cookie{
+		Name:     websites.FirewallClearanceCookie,
+		Value:    clearance,
+		Expires:  expiresAt,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
+	})
+
+	return nil
+}
+
+// hasLeadingZeroBits returns true if the hash starts with at least n zero bits
+func hasLeadingZeroBits(hash [sha256.Size]byte, n int64) bool {
+	var zeroBits int64
+	for _, b := range hash {
+		if b != 0 {
+			zeroBits += int64(bits.LeadingZeros8(b))
+			break
+		}
+		zeroBits += 8
+	}
+	return zeroBits >= n
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/skerkour/stdx-go/db"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/contacts"
+	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/events"
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/site"
@@ -107,7 +108,15 @@ func (service *SiteService) CompleteSubscription(ctx context.Context, input site
 			WebsiteID: website.ID,
 		}
 		_, sessionCookie, txErr = service.contactsService.CreateSession(ctx, tx, createSessionInput)
-		return txErr
+		if txErr != nil {
+			return txErr
+		}
+
+		return service.emailsService.TriggerSequences(ctx, tx, emails.TriggerSequencesInput{
+			WebsiteID: website.ID,
+			ContactID: contact.ID,
+			Trigger:   emails.SequenceTriggerSubscribedToNewsletter,
+		})
 	})
 	if err != nil {
 		return
@@ -13,15 +13,23 @@ import (
 )
 
 func (service *SiteService) convertContact(input contacts.Contact) site.Contact {
-	subscribedToNewsletter := false
-	if input.SubscribedToNewsletterAt != nil {
-		subscribedToNewsletter = true
+	return site.Contact{
+		Name:                  input.Name,
+		Email:                 input.Email,
+		NewsletterPreferences: service.convertNewsletterPreferences(input),
 	}
+}
 
-	return site.Contact{
-		Name:                   input.Name,
-		Email:                  input.Email,
-		SubscribedToNewsletter: subscribedToNewsletter,
+// convertNewsletterPreferences doesn't set NewsletterTags and NewsletterDigests.
+// See fillNewsletterPreferences
+func (service *SiteService) convertNewsletterPreferences(input contacts.Contact) site.NewsletterPreferences {
+	return site.NewsletterPreferences{
+		SubscribedToNewsletter:     input.SubscribedToNewsletterAt != nil,
+		ReceiveNewsletters:         input.ReceiveNewsletters,
+		SubscribedToProductUpdates: input.SubscribedToProductUpdatesAt != nil,
+		NewsletterDelivery:         input.NewsletterDelivery,
+		NewsletterTags:             []string{},
+		NewsletterDigests:          false,
 	}
 }
 
@@ -33,6 +41,27 @@ func (service *SiteService) convertOrder(input store.Order) site.Order {
 		Currency:    input.Currency,
 		Status:      input.Status,
 		InvoiceUrl:  input.StripeInvoiceUrl,
+
+		GiftRecipientEmail: input.GiftRecipientEmail,
+		LicenseKeys:        []site.LicenseKey{},
+		Invoices:           []site.OrderInvoice{},
+	}
+}
+
+func (service *SiteService) convertOrderInvoice(input store.Invoice) site.OrderInvoice {
+	return site.OrderInvoice{
+		Type:   input.Type,
+		Number: input.Number,
+		Url:    websites.MarkdownNinjaPathPrefix + "/invoices/" + input.ID.String() + "/" + string(store.InvoiceFormatPdf),
+	}
+}
+
+func (service *SiteService) convertLicenseKey(input store.LicenseKey) site.LicenseKey {
+	return site.LicenseKey{
+		Key:         input.Key,
+		Status:      input.Status,
+		ProductID:   input.ProductID,
+		ProductName: input.ProductName,
 	}
 }
 
@@ -55,10 +84,25 @@ func (service *SiteService) convertProduct(website websites.Website, input store
 		Type:        input.Type,
 
 		Content: pages,
+		Ebooks:  service.convertProductEbooks(input),
 	}
 	return ret
 }
 
+func (service *SiteService) convertProductEbooks(product store.Product) (ret []site.ProductEbook) {
+	ret = make([]site.ProductEbook, len(product.Ebooks))
+
+	for i, ebook := range product.Ebooks {
+		ret[i] = site.ProductEbook{
+			Format: ebook.Format,
+			Url:    websites.ProductsPrefix + product.ID.String() + "/ebooks/" + string(ebook.Format),
+			Size:   ebook.Size,
+		}
+	}
+
+	return ret
+}
+
 func (service *SiteService) convertProducts(website websites.Website, input []store.Product) (ret []site.Product) {
 	ret = make([]site.Product, len(input))
 
@@ -111,17 +155,23 @@ func (service *SiteService) convertWebsite(input websites.Website) site.Website
 	}
 }
 
-func (service *SiteService) convertPage(_ context.Context, website websites.Website, input content.Page, tags []content.Tag, snippets []content.Snippet) (ret site.Page) {
+// if locked is true, only the teaser of the page is rendered
+func (service *SiteService) convertPage(_ context.Context, website websites.Website, input content.Page, tags []content.Tag, snippets []content.Snippet, locked bool) (ret site.Page) {
 	if tags == nil {
 		tags = []content.Tag{}
 	}
 
-	bodyHtml := service.contentService.RenderMarkdown(website, input.BodyMarkdown, snippets, false)
+	bodyMarkdown := input.BodyMarkdown
+	if locked {
+		bodyMarkdown = content.PageTeaser(bodyMarkdown)
+	}
+	bodyHtml := service.contentService.RenderMarkdown(website, bodyMarkdown, snippets, false)
 
 	ret = site.Page{
 		PageMetadata: service.convertPageToMetadata(website, input),
 		Tags:         service.convertTags(tags),
 		Body:         bodyHtml,
+		Locked:       locked,
 	}
 	return ret
 }
@@ -153,6 +203,7 @@ func (service *SiteService) convertPageToMetadata(website websites.Website, page
 		Url:          template.URL(url),
 		BodyHash:     page.BodyHash,
 		MetadataHash: page.MetadataHash,
+		Visibility:   page.Visibility,
 	}
 }
 
@@ -170,6 +221,7 @@ func (service *SiteService) convertPageMetadata(website websites.Website, page c
 		Url:          template.URL(url),
 		BodyHash:     page.BodyHash,
 		MetadataHash: page.MetadataHash,
+		Visibility:   page.Visibility,
 	}
 }
 
@@ -3,6 +3,9 @@ package service
 import (
 	"context"
 
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/site"
 )
@@ -15,7 +18,22 @@ func (service *SiteService) GetMe(ctx context.Context, input kernel.EmptyInput)
 	}
 
 	websiteContact := service.convertContact(*contact)
+	err = service.fillNewsletterPreferences(ctx, *contact, &websiteContact.NewsletterPreferences)
+	if err != nil {
+		return
+	}
 	ret = &websiteContact
 
 	return ret, nil
 }
+
+func (service *SiteService) newsletterDigestsEnabled(ctx context.Context, websiteID guid.GUID) bool {
+	emailsConfig, err := service.emailsService.FindWebsiteConfiguration(ctx, service.db, websiteID)
+	if err != nil {
+		logger := slogx.FromCtx(ctx)
+		logger.Error("site.newsletterDigestsEnabled: finding emails configuration", slogx.Err(err))
+		return false
+	}
+
+	return emailsConfig.DigestFrequency != emails.DigestFrequencyDisabled
+}
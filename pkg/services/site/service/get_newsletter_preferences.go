@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/site"
+)
+
+// GetNewsletterPreferences returns the preferences of the contact of the unsubscribe link, for the
+// preference center
+func (service *SiteService) GetNewsletterPreferences(ctx context.Context, input site.GetNewsletterPreferencesInput) (ret site.NewsletterPreferences, err error) {
+	httpCtx := httpctx.FromCtx(ctx)
+
+	website, err := service.websitesService.FindWebsiteByDomain(ctx, service.db, httpCtx.Hostname)
+	if err != nil {
+		return
+	}
+
+	contact, err := service.findContactByUnsubscribeToken(ctx, website, input.Token)
+	if err != nil {
+		return
+	}
+
+	ret = service.convertNewsletterPreferences(contact)
+	err = service.fillNewsletterPreferences(ctx, contact, &ret)
+	if err != nil {
+		return
+	}
+
+	return ret, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/skerkour/stdx-go/httpx"
 	"github.com/skerkour/stdx-go/log/slogx"
 	"github.com/skerkour/stdx-go/memorycache"
+	"markdown.ninja/pkg/errs"
 	"markdown.ninja/pkg/server/cachecontrol"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/content"
@@ -35,7 +36,21 @@ func (service *SiteService) GetPage(ctx context.Context, input site.GetPageInput
 
 	page, err = service.contentService.FindPageByPath(ctx, service.db, website.ID, *input.Slug)
 	if err != nil {
-		return
+		if !errs.IsNotFound(err) {
+			return
+		}
+
+		// the pages of the newsletters archive are not stored with the other pages
+		var archivePath string
+		archivePath, err = service.findArchivePath(ctx, service.db, website.ID)
+		if err != nil {
+			return
+		}
+
+		page, err = service.findArchivePage(ctx, website, archivePath, *input.Slug)
+		if err != nil {
+			return
+		}
 	}
 
 	if page.Status != content.PageStatusPublished {
@@ -54,11 +69,16 @@ func (service *SiteService) GetPage(ctx context.Context, input site.GetPageInput
 	}
 	service.eventsService.TrackPageView(ctx, trackEventInput)
 
+	locked, err := service.isPageLocked(ctx, page, service.contactsService.CurrentContact(ctx))
+	if err != nil {
+		return
+	}
+
 	// handle caching
 	// ThemeHash is not needed as it's an API call. There is no theme/frontend involved.
-	// Nothing in the returned response depends on the data of the contact, so we don't need to check
-	// if contact is present or not.
-	etag := computePageEtag(&page, website.ModifiedAt, nil, httpCtx.Client.CountryCode, nil)
+	// Nothing in the returned response depends on the data of the contact except whether the page
+	// is locked for members only, so we don't need to include the contact in the etag.
+	etag := computePageEtag(&page, website.ModifiedAt, nil, httpCtx.Client.CountryCode, nil, locked)
 	if httpCtx.Request.IfNoneMatch != nil && *httpCtx.Request.IfNoneMatch == etag {
 		httpCtx.Response.CacheHit = &httpctx.CacheHit{
 			CacheControl: cacheControl,
@@ -85,7 +105,7 @@ func (service *SiteService) GetPage(ctx context.Context, input site.GetPageInput
 	httpCtx.Response.Headers.Set(httpx.HeaderCacheControl, cacheControl)
 	httpCtx.Response.Headers.Set(httpx.HeaderETag, strconv.Quote(etag))
 
-	ret = service.convertPage(ctx, website, page, tags, snippets)
+	ret = service.convertPage(ctx, website, page, tags, snippets, locked)
 	service.pagesCache.Set(etag, ret, memorycache.DefaultTTL)
 
 	return ret, nil
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/site"
 	"markdown.ninja/pkg/services/store"
@@ -30,7 +32,29 @@ func (service *SiteService) GetProduct(ctx context.Context, input site.GetProduc
 		return
 	}
 
+	access, err := service.storeService.FindContactProductAccess(ctx, service.db, contact.ID, product.ID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			err = store.ErrProductNotFound
+		}
+		return
+	}
+
 	ret = service.convertProduct(website, product)
 
+	err = service.hydrateCourseLessons(ctx, contact.ID, &ret, access, product.Content)
+	if err != nil {
+		return
+	}
+
+	httpCtx := httpctx.FromCtx(ctx)
+	price := store.ResolveProductPrice(website.Currency, httpCtx.Client.CountryCode, product)
+	ret.Price = &price
+
+	err = service.hydrateProductBundleItems(ctx, &ret)
+	if err != nil {
+		return
+	}
+
 	return ret, nil
 }
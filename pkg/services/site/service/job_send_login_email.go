@@ -1,7 +1,6 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"html/template"
@@ -11,20 +10,24 @@ import (
 	"github.com/skerkour/stdx-go/email"
 	"github.com/skerkour/stdx-go/log/slogx"
 	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/events"
 	"markdown.ninja/pkg/services/site"
-	"markdown.ninja/pkg/services/site/templates"
 )
 
 func (service *SiteService) JobSendLoginEmail(ctx context.Context, input site.JobSendLoginEmail) (err error) {
 	logger := slogx.FromCtx(ctx)
-	var htmlContent bytes.Buffer
 
 	emailConfig, err := service.emailsService.FindWebsiteConfiguration(ctx, service.db, input.WebsiteID)
 	if err != nil {
 		return
 	}
 
+	website, err := service.websitesService.FindWebsiteByID(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
 	var from mail.Address
 	if emailConfig.DomainVerified {
 		from = mail.Address{
@@ -32,10 +35,6 @@ func (service *SiteService) JobSendLoginEmail(ctx context.Context, input site.Jo
 			Address: emailConfig.FromAddress,
 		}
 	} else {
-		website, err := service.websitesService.FindWebsiteByID(ctx, service.db, input.WebsiteID)
-		if err != nil {
-			return err
-		}
 		from = service.emailsService.GetDefaultFromAddressForWebsite(website)
 	}
 
@@ -45,20 +44,15 @@ func (service *SiteService) JobSendLoginEmail(ctx context.Context, input site.Jo
 	}
 	subject := fmt.Sprintf("Your Login code: %s", input.Code)
 	loginLink := service.generateLoginLink(input.WebsiteDomain, input.SessionID, input.Code)
-	textContent := fmt.Sprintf(`
-%s
-
-or
 
-%s`, input.Code, loginLink)
-
-	emailData := templates.LoginEmailData{
-		Code: template.HTML(input.Code),
-		Link: template.URL(loginLink),
+	emailData := emails.EmailTemplateData{
+		Subject: subject,
+		Code:    input.Code,
+		Link:    template.URL(loginLink),
 	}
-	err = service.loginEmailTemplate.Execute(&htmlContent, emailData)
+	renderedEmail, err := service.emailsService.RenderWebsiteEmail(ctx, service.db, website, emails.EmailTemplateTypeLogin, emailData)
 	if err != nil {
-		errMessage := "site.JobSendLoginEmail: Executing email template"
+		errMessage := "site.JobSendLoginEmail: Rendering email"
 		logger.Error(errMessage, slogx.Err(err))
 		err = errs.Internal(errMessage, err)
 		return
@@ -68,8 +62,8 @@ or
 		From:    from,
 		To:      []mail.Address{to},
 		Subject: subject,
-		HTML:    htmlContent.Bytes(),
-		Text:    []byte(textContent),
+		HTML:    []byte(renderedEmail.Html),
+		Text:    []byte(renderedEmail.Text),
 	}
 	err = service.mailer.SendTransactionnal(ctx, message)
 	if err != nil {
@@ -1,7 +1,6 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"html/template"
@@ -11,20 +10,24 @@ import (
 	"github.com/skerkour/stdx-go/email"
 	"github.com/skerkour/stdx-go/log/slogx"
 	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/events"
 	"markdown.ninja/pkg/services/site"
-	"markdown.ninja/pkg/services/site/templates"
 )
 
 func (service *SiteService) JobSendSubscribeEmail(ctx context.Context, input site.JobSendSubscribeEmail) (err error) {
 	logger := slogx.FromCtx(ctx)
-	var htmlContent bytes.Buffer
 
 	emailConfig, err := service.emailsService.FindWebsiteConfiguration(ctx, service.db, input.WebsiteID)
 	if err != nil {
 		return
 	}
 
+	website, err := service.websitesService.FindWebsiteByID(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
 	var from mail.Address
 	if emailConfig.DomainVerified {
 		from = mail.Address{
@@ -32,10 +35,6 @@ func (service *SiteService) JobSendSubscribeEmail(ctx context.Context, input sit
 			Address: emailConfig.FromAddress,
 		}
 	} else {
-		website, err := service.websitesService.FindWebsiteByID(ctx, service.db, input.WebsiteID)
-		if err != nil {
-			return err
-		}
 		from = service.emailsService.GetDefaultFromAddressForWebsite(website)
 	}
 
@@ -45,18 +44,15 @@ func (service *SiteService) JobSendSubscribeEmail(ctx context.Context, input sit
 	}
 	subject := fmt.Sprintf("Your confirmation code: %s", input.Code)
 	subscribeLink := service.generateSubscribeLink(input.WebsiteDomain, input.ContactID, input.Code)
-	textContent := fmt.Sprintf(`
-%s
-
-or use the following link: %s`, input.Code, subscribeLink)
 
-	emailData := templates.SubscribeEmailData{
-		Code: template.HTML(input.Code),
-		Link: template.URL(subscribeLink),
+	emailData := emails.EmailTemplateData{
+		Subject: subject,
+		Code:    input.Code,
+		Link:    template.URL(subscribeLink),
 	}
-	err = service.subscribeEmailTemplate.Execute(&htmlContent, emailData)
+	renderedEmail, err := service.emailsService.RenderWebsiteEmail(ctx, service.db, website, emails.EmailTemplateTypeSubscribe, emailData)
 	if err != nil {
-		errMessage := "site.JobSendSubscribeEmail: Executing email template"
+		errMessage := "site.JobSendSubscribeEmail: Rendering email"
 		logger.Error(errMessage, slogx.Err(err))
 		err = errs.Internal(errMessage, err)
 		return
@@ -66,8 +62,8 @@ or use the following link: %s`, input.Code, subscribeLink)
 		From:    from,
 		To:      []mail.Address{to},
 		Subject: subject,
-		HTML:    htmlContent.Bytes(),
-		Text:    []byte(textContent),
+		HTML:    []byte(renderedEmail.Html),
+		Text:    []byte(renderedEmail.Text),
 	}
 	err = service.mailer.SendTransactionnal(ctx, message)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/site"
+	"markdown.ninja/pkg/services/store"
 )
 
 func (service *SiteService) ListMyOrders(ctx context.Context, input kernel.EmptyInput) (ret kernel.PaginatedResult[site.Order], err error) {
@@ -19,7 +20,34 @@ func (service *SiteService) ListMyOrders(ctx context.Context, input kernel.Empty
 		return
 	}
 
+	licenseKeys, err := service.storeService.FindLicenseKeysForContact(ctx, service.db, contact.ID)
+	if err != nil {
+		return
+	}
+
+	invoices, err := service.storeService.FindInvoicesForContact(ctx, service.db, contact.ID)
+	if err != nil {
+		return
+	}
+
 	ret.Data = service.convertOrders(orders)
+	for i := range ret.Data {
+		for _, licenseKey := range licenseKeys {
+			if licenseKey.OrderID != nil && licenseKey.OrderID.Equal(ret.Data[i].ID) {
+				ret.Data[i].LicenseKeys = append(ret.Data[i].LicenseKeys, service.convertLicenseKey(licenseKey))
+			}
+		}
+		for _, invoice := range invoices {
+			if invoice.OrderID.Equal(ret.Data[i].ID) {
+				orderInvoice := service.convertOrderInvoice(invoice)
+				ret.Data[i].Invoices = append(ret.Data[i].Invoices, orderInvoice)
+				// the invoice issued by the website supersedes the one of the payment provider
+				if invoice.Type == store.InvoiceTypeInvoice {
+					ret.Data[i].InvoiceUrl = &orderInvoice.Url
+				}
+			}
+		}
+	}
 
 	return
 }
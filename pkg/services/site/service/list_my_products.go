@@ -5,6 +5,7 @@ import (
 
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/site"
+	"markdown.ninja/pkg/services/store"
 )
 
 func (service *SiteService) ListMyProducts(ctx context.Context, input kernel.EmptyInput) (ret kernel.PaginatedResult[site.Product], err error) {
@@ -25,5 +26,21 @@ func (service *SiteService) ListMyProducts(ctx context.Context, input kernel.Emp
 	}
 
 	ret.Data = service.convertProducts(website, products)
+	for i := range ret.Data {
+		err = service.hydrateProductBundleItems(ctx, &ret.Data[i])
+		if err != nil {
+			return ret, err
+		}
+
+		if ret.Data[i].Type == store.ProductTypeCourse {
+			var progress store.CourseProgress
+			progress, err = service.storeService.GetCourseProgress(ctx, service.db, contact.ID, ret.Data[i].ID)
+			if err != nil {
+				return ret, err
+			}
+			ret.Data[i].Progress = &progress
+		}
+	}
+
 	return ret, nil
 }
@@ -34,7 +34,8 @@ func (service *SiteService) Login(ctx context.Context, input site.LoginInput) (r
 		return
 	}
 
-	if !service.rateLimiter.IsAllowed("SiteService.Login", website.ID.Bytes(), httpCtx.Client.IP.AsSlice(), time.Hour, 30) {
+	if !service.rateLimiter.IsAllowed(ctx, "SiteService.Login", website.ID.Bytes(), httpCtx.Client.IP.AsSlice(), time.Hour,
+		uint64(website.RateLimits.WithDefaults().Login)) {
 		err = errs.TooManyRequests()
 		return
 	}
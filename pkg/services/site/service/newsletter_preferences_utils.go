@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/contacts"
+	"markdown.ninja/pkg/services/content"
+	"markdown.ninja/pkg/services/events"
+	"markdown.ninja/pkg/services/site"
+	"markdown.ninja/pkg/services/websites"
+)
+
+// fillNewsletterPreferences sets the fields of the preferences which are not stored with the contact
+func (service *SiteService) fillNewsletterPreferences(ctx context.Context, contact contacts.Contact, preferences *site.NewsletterPreferences) (err error) {
+	preferences.NewsletterDigests = service.newsletterDigestsEnabled(ctx, contact.WebsiteID)
+
+	tagIDs, err := service.contactsService.FindContactNewsletterTagIDs(ctx, service.db, contact.ID)
+	if err != nil {
+		return
+	}
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	tags, err := service.contentService.FindTags(ctx, service.db, contact.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	preferences.NewsletterTags = make([]string, 0, len(tagIDs))
+	for _, tag := range tags {
+		for _, tagID := range tagIDs {
+			if tag.ID.Equal(tagID) {
+				preferences.NewsletterTags = append(preferences.NewsletterTags, tag.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// findNewsletterTagIDs returns the IDs of the tags of the website with the given names, or nil if
+// tagNames is nil
+func (service *SiteService) findNewsletterTagIDs(ctx context.Context, websiteID guid.GUID, tagNames *[]string) (ret *[]guid.GUID, err error) {
+	if tagNames == nil {
+		return nil, nil
+	}
+
+	tagIDs := make([]guid.GUID, 0, len(*tagNames))
+	if len(*tagNames) == 0 {
+		return &tagIDs, nil
+	}
+
+	tags, err := service.contentService.FindTags(ctx, service.db, websiteID)
+	if err != nil {
+		return
+	}
+
+	tagsByName := make(map[string]guid.GUID, len(tags))
+	for _, tag := range tags {
+		tagsByName[tag.Name] = tag.ID
+	}
+
+	for _, tagName := range *tagNames {
+		tagID, tagExists := tagsByName[tagName]
+		if !tagExists {
+			return nil, content.ErrTagNotFound
+		}
+		tagIDs = append(tagIDs, tagID)
+	}
+
+	return &tagIDs, nil
+}
+
+// findContactByUnsubscribeToken returns the contact of the unsubscribe link, which must be a contact of
+// the website
+func (service *SiteService) findContactByUnsubscribeToken(ctx context.Context, website websites.Website, token string) (contact contacts.Contact, err error) {
+	contactID, err := service.contactsService.ParseAndVerifyUnsubscribeToken(token)
+	if err != nil {
+		return
+	}
+
+	// the contact may have logged in with another account
+	actor := service.contactsService.CurrentContact(ctx)
+	if actor != nil && !actor.ID.Equal(contactID) {
+		err = contacts.ErrUnsubscribeLinkIsNotValid
+		return
+	}
+
+	contact, err = service.contactsService.FindContact(ctx, service.db, contactID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			err = contacts.ErrUnsubscribeLinkIsNotValid
+		}
+		return
+	}
+
+	if !website.ID.Equal(contact.WebsiteID) {
+		err = contacts.ErrUnsubscribeLinkIsNotValid
+		return
+	}
+
+	return
+}
+
+// unsubscribeContact unsubscribes the contact from the newsletter, and thus from all the topics
+func (service *SiteService) unsubscribeContact(ctx context.Context, contact *contacts.Contact) (err error) {
+	if contact.SubscribedToNewsletterAt == nil {
+		return
+	}
+
+	updateContactInput := contacts.UpdateContactInput{
+		ID:                     contact.ID,
+		SubscribedToNewsletter: new(false),
+	}
+	err = service.contactsService.UpdateContactInternal(ctx, service.db, contact, updateContactInput)
+	if err != nil {
+		return
+	}
+
+	trackEventInput := events.TrackUnsubscribedFromNewsletterInput{
+		WebsiteID: contact.WebsiteID,
+	}
+	service.eventsService.TrackUnsubscribedFromNewsletter(ctx, trackEventInput)
+
+	return
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/site"
+	"markdown.ninja/pkg/services/store"
+)
+
+// hydrateProductBundleItems fills the list of the products included in a bundle, so customers can
+// see what they get and navigate to the included products.
+func (service *SiteService) hydrateProductBundleItems(ctx context.Context, product *site.Product) (err error) {
+	if product.Type != store.ProductTypeBundle {
+		return nil
+	}
+
+	items, err := service.storeService.FindProductsInBundle(ctx, service.db, product.ID)
+	if err != nil {
+		return
+	}
+
+	product.BundleItems = make([]site.ProductBundleItem, len(items))
+	for i, item := range items {
+		product.BundleItems[i] = site.ProductBundleItem{
+			ID:   item.ID,
+			Name: item.Name,
+			Type: item.Type,
+		}
+	}
+
+	return nil
+}
+
+// hydrateCourseLessons marks the lessons of a course completed by the contact, and locks the drip
+// content that is not available yet by removing its body.
+func (service *SiteService) hydrateCourseLessons(ctx context.Context, contactID guid.GUID, product *site.Product, access store.ContactProductAccess, pages []store.ProductPage) (err error) {
+	if product.Type != store.ProductTypeCourse {
+		return nil
+	}
+
+	completions, err := service.storeService.FindProductPageCompletionsForContact(ctx, service.db, contactID, product.ID)
+	if err != nil {
+		return
+	}
+
+	completedPages := make(map[guid.GUID]bool, len(completions))
+	for _, completion := range completions {
+		completedPages[completion.ProductPageID] = true
+	}
+
+	now := time.Now().UTC()
+	for i := range product.Content {
+		page := &pages[i]
+		product.Content[i].Completed = completedPages[page.ID]
+		if page.DripDays != 0 {
+			availableAt := page.AvailableAt(access.CreatedAt)
+			product.Content[i].AvailableAt = &availableAt
+		}
+		if page.IsLocked(access.CreatedAt, now) {
+			product.Content[i].Locked = true
+			product.Content[i].Body = ""
+		}
+	}
+	product.Progress = &store.CourseProgress{
+		CompletedLessons: int64(len(completions)),
+		TotalLessons:     int64(len(pages)),
+	}
+
+	return nil
+}
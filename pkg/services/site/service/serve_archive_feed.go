@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/skerkour/stdx-go/feeds"
+	"github.com/skerkour/stdx-go/httpx"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/memorycache"
+	"github.com/skerkour/stdx-go/timex"
+	"markdown.ninja/pkg/server/cachecontrol"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/content"
+	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/websites"
+)
+
+// serveArchiveFeed serves the RSS feed of the public archive of the newsletters
+func (service *SiteService) serveArchiveFeed(ctx context.Context, res http.ResponseWriter, website websites.Website,
+	archivePath, hostname, url string) {
+	host := service.httpConfig.WebsitesBaseUrl.Scheme + "://" + website.PrimaryDomain + service.httpConfig.WebsitesPort
+	cacheControl := cachecontrol.WebsiteFeed
+	httpCtx := httpctx.FromCtx(ctx)
+	modifiedAt := website.ModifiedAt.Truncate(time.Second)
+	logger := slogx.FromCtx(ctx)
+
+	newsletters, err := service.emailsService.FindArchivedNewsletters(ctx, service.db, website.ID, 50)
+	if err != nil {
+		service.serveInternalError(ctx, res, err, hostname, url)
+		return
+	}
+
+	// handle caching
+	if len(newsletters) != 0 {
+		modifiedAt = timex.Max(*newsletters[0].SentAt, modifiedAt).Truncate(time.Second)
+	}
+
+	etag := generateFeedEtag(&website, modifiedAt)
+	res.Header().Set(httpx.HeaderCacheControl, cacheControl)
+	res.Header().Set(httpx.HeaderETag, strconv.Quote(etag))
+	res.Header().Set(httpx.HeaderContentType, httpx.MediaTypeXml)
+
+	if httpCtx.Request.IfNoneMatch != nil && *httpCtx.Request.IfNoneMatch == etag {
+		res.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// the archive path is part of the key as it may change
+	cacheKey := "archive_" + archivePath + "_" + etag
+	if cachedFeed := service.feedsCache.Get(cacheKey); cachedFeed != nil {
+		logger.Debug("site.serveArchiveFeed: memory cache hit")
+		decompressedCachedData, err := service.cacheZstdDecompressor.DecodeAll(cachedFeed.Value(), nil)
+		if err != nil {
+			err = fmt.Errorf("site.serveArchiveFeed: uncompressing cached data: %w", err)
+			service.serveInternalError(ctx, res, err, hostname, url)
+			return
+		}
+
+		res.Header().Set(httpx.HeaderContentLength, strconv.FormatInt(int64(len(decompressedCachedData)), 10))
+		res.WriteHeader(http.StatusOK)
+		res.Write([]byte(decompressedCachedData))
+		return
+	}
+
+	feed := &feeds.Feed{
+		Title:       website.Name + " - Newsletter archive",
+		Link:        &feeds.Link{Href: host + archivePath},
+		Description: website.Description,
+		Created:     website.CreatedAt.Truncate(time.Hour),
+		Language:    website.Language,
+	}
+	feed.Items = make([]*feeds.Item, len(newsletters))
+
+	for i, newsletter := range newsletters {
+		webVersion := emails.NewsletterWebVersion(newsletter.BodyMarkdown)
+		newsletterUrl := host + archivePath + "/" + newsletter.ID.String()
+		feed.Items[i] = &feeds.Item{
+			Id:          newsletterUrl,
+			Title:       newsletter.Subject,
+			Link:        &feeds.Link{Href: newsletterUrl},
+			Description: service.contentService.RenderMarkdown(website, content.PageTeaser(webVersion), nil, false),
+			Created:     newsletter.SentAt.UTC(),
+		}
+	}
+
+	feedContent, err := feed.ToRss()
+	if err != nil {
+		err = fmt.Errorf("site.serveArchiveFeed: error encoding feed to RSS: %w", err)
+		service.serveInternalError(ctx, res, err, hostname, url)
+		return
+	}
+
+	compressedContent := service.cacheZstdCompressor.EncodeAll(feedContent, make([]byte, 0, len(feedContent)/4))
+	service.feedsCache.Set(cacheKey, compressedContent, memorycache.DefaultTTL)
+
+	res.Header().Set(httpx.HeaderContentLength, strconv.FormatInt(int64(len(feedContent)), 10))
+	res.WriteHeader(http.StatusOK)
+	res.Write(feedContent)
+}
@@ -141,6 +141,26 @@ func (service *SiteService) ServeContent(res http.ResponseWriter, req *http.Requ
 			}
 		}
 
+		archivePath, err := service.findArchivePath(ctx, service.db, website.ID)
+		if err != nil {
+			service.serveInternalError(ctx, res, err, hostname, path)
+			return
+		}
+		if archivePath != "" && path == archivePath+"/feed.xml" {
+			service.serveArchiveFeed(ctx, res, website, archivePath, hostname, path)
+			return
+		}
+		archivePage, err := service.findArchivePage(ctx, website, archivePath, path)
+		if err == nil {
+			service.eventsService.TrackPageView(ctx, trackPageEventInput)
+			service.servePage(ctx, res, website, archivePage, hostname, path, http.StatusOK)
+			return
+		} else if !errs.IsNotFound(err) {
+			service.serveInternalError(ctx, res, err, hostname, path)
+			return
+		}
+		err = nil
+
 		// switch if faster than if / else if chains
 		// https://stackoverflow.com/questions/29566229/go-switch-string-efficiency
 		switch path {
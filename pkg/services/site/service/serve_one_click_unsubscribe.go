@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/skerkour/stdx-go/httpx"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/server/cachecontrol"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/contacts"
+)
+
+const oneClickUnsubscribeMaxBodySize = 4096
+
+// ServeOneClickUnsubscribe handles the one-click unsubscriptions defined by RFC 8058: email clients POST
+// "List-Unsubscribe=One-Click" to the link of the List-Unsubscribe header of the emails, without
+// any cookie nor interaction with the contact.
+// https://www.rfc-editor.org/rfc/rfc8058
+func (service *SiteService) ServeOneClickUnsubscribe(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	httpCtx := httpctx.FromCtx(ctx)
+	hostname := httpCtx.Hostname
+
+	website, err := service.websitesService.FindWebsiteByDomain(ctx, service.db, hostname)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			service.serveSiteNotFoundError(ctx, res)
+			return
+		}
+		service.serveInternalError(ctx, res, err, hostname, req.URL.Path)
+		return
+	}
+
+	req.Body = http.MaxBytesReader(res, req.Body, oneClickUnsubscribeMaxBodySize)
+	err = req.ParseForm()
+	if err != nil || strings.TrimSpace(req.PostForm.Get("List-Unsubscribe")) != "One-Click" {
+		service.serveError(ctx, res, []byte("Bad Request\n"), http.StatusBadRequest)
+		return
+	}
+
+	contact, err := service.findContactByUnsubscribeToken(ctx, website, req.URL.Query().Get("token"))
+	if err != nil {
+		if errors.Is(err, contacts.ErrUnsubscribeLinkIsNotValid) {
+			service.serveError(ctx, res, []byte(err.Error()+"\n"), http.StatusBadRequest)
+			return
+		}
+		service.serveInternalError(ctx, res, err, hostname, req.URL.Path)
+		return
+	}
+
+	err = service.unsubscribeContact(ctx, &contact)
+	if err != nil {
+		service.serveInternalError(ctx, res, err, hostname, req.URL.Path)
+		return
+	}
+
+	res.Header().Set(httpx.HeaderCacheControl, cachecontrol.NoCache)
+	res.WriteHeader(http.StatusOK)
+}
@@ -34,7 +34,13 @@ func (service *SiteService) servePage(ctx context.Context, res http.ResponseWrit
 		return
 	}
 
-	etag := computePageEtag(&page, website.ModifiedAt, service.themes[website.Theme].Hash, httpCtx.Client.CountryCode, contact)
+	locked, err := service.isPageLocked(ctx, page, contact)
+	if err != nil {
+		service.serveInternalError(ctx, res, err, hostname, url)
+		return
+	}
+
+	etag := computePageEtag(&page, website.ModifiedAt, service.themes[website.Theme].Hash, httpCtx.Client.CountryCode, contact, locked)
 	if statusCode == http.StatusOK &&
 		httpCtx.Request.IfNoneMatch != nil && *httpCtx.Request.IfNoneMatch == etag {
 		res.Header().Set(httpx.HeaderCacheControl, cacheControl)
@@ -70,7 +76,7 @@ func (service *SiteService) servePage(ctx context.Context, res http.ResponseWrit
 		return
 	}
 
-	sitePage := service.convertPage(ctx, website, page, tags, snippets)
+	sitePage := service.convertPage(ctx, website, page, tags, snippets, locked)
 
 	contentBuffer := bytes.NewBuffer(make([]byte, 0, 50_000))
 	template := service.themes[website.Theme].IndexTemplate
@@ -118,11 +124,30 @@ func (service *SiteService) servePage(ctx context.Context, res http.ResponseWrit
 	res.Write(contentBytes)
 }
 
-// the etag for a page is computed from its ID, the hash of its content, the last time the website has been modified
-// and the hash of the theme files, so if any of these things has changed, the etag value will change.
+// isPageLocked returns true if the page is members-only and the contact is not an active member
+func (service *SiteService) isPageLocked(ctx context.Context, page content.Page, contact *contacts.Contact) (bool, error) {
+	if page.Visibility != content.PageVisibilityMembers {
+		return false, nil
+	}
+
+	if contact == nil {
+		return true, nil
+	}
+
+	isMember, err := service.storeService.HasActiveMembership(ctx, service.db, contact.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return !isMember, nil
+}
+
+// the etag for a page is computed from its ID, the hash of its content, the last time the website has been modified,
+// the hash of the theme files and whether the page is locked for members only, so if any of these things has changed,
+// the etag value will change.
 // Page MUST NOT be null. If page is null, then, to avoid segfaulting, a random ETAG will be returned
 // which defeats the purpose of generating an etag.
-func computePageEtag(page *content.Page, siteModifiedAt time.Time, themeHash []byte, country string, contact *contacts.Contact) (etag string) {
+func computePageEtag(page *content.Page, siteModifiedAt time.Time, themeHash []byte, country string, contact *contacts.Contact, locked bool) (etag string) {
 	var hash [32]byte
 
 	if page == nil {
@@ -141,6 +166,7 @@ func computePageEtag(page *content.Page, siteModifiedAt time.Time, themeHash []b
 	if contact != nil {
 		hasher.Write(contact.ID.Bytes())
 	}
+	binary.Write(hasher, binary.LittleEndian, locked)
 	hasher.Sum(hash[:0])
 
 	return base64.RawURLEncoding.EncodeToString(hash[:])
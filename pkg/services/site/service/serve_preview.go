@@ -68,7 +68,8 @@ func (service *SiteService) ServePreview(res http.ResponseWriter, req *http.Requ
 		return
 	}
 
-	sitePage := service.convertPage(ctx, website, page, tags, snippets)
+	// previews are only available to the staff of the website, so pages are never locked
+	sitePage := service.convertPage(ctx, website, page, tags, snippets, false)
 
 	templateData, err := service.convertPageTemplateData(website, &sitePage, tags, contact, httpCtx.Client.CountryCode)
 	if err != nil {
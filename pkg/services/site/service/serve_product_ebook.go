@@ -0,0 +1,51 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/httpx"
+	"markdown.ninja/pkg/server/apiutil"
+	"markdown.ninja/pkg/server/cachecontrol"
+	"markdown.ninja/pkg/services/kernel"
+	"markdown.ninja/pkg/services/store"
+)
+
+// ServeProductEbook serves the ebook (EPUB or print-ready HTML) of a book to the contacts who have access
+// to the product.
+func (service *SiteService) ServeProductEbook(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	contact := service.contactsService.CurrentContact(ctx)
+	if contact == nil {
+		apiutil.SendError(ctx, res, kernel.ErrAuthenticationRequired)
+		return
+	}
+
+	productID, err := guid.Parse(chi.URLParam(req, "product_id"))
+	if err != nil {
+		apiutil.SendError(ctx, res, store.ErrProductNotFound)
+		return
+	}
+
+	// access to the product is checked by the store service
+	ebook, err := service.storeService.GetProductEbook(ctx, store.GetProductEbookInput{
+		ProductID: productID,
+		Format:    store.ProductEbookFormat(chi.URLParam(req, "format")),
+	})
+	if err != nil {
+		apiutil.SendError(ctx, res, err)
+		return
+	}
+	defer ebook.Data.Close()
+
+	res.Header().Set(httpx.HeaderCacheControl, cachecontrol.NoCache)
+	res.Header().Set(httpx.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s", strconv.Quote(ebook.Filename)))
+	res.Header().Set(httpx.HeaderContentType, ebook.MediaType)
+	res.Header().Set(httpx.HeaderContentLength, strconv.FormatInt(ebook.Size, 10))
+	res.WriteHeader(http.StatusOK)
+	io.Copy(res, ebook.Data)
+}
@@ -20,6 +20,7 @@ import (
 	"markdown.ninja/pkg/server/cachecontrol"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/content"
+	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/websites"
 )
 
@@ -44,7 +45,24 @@ func (service *SiteService) serveSitemap(ctx context.Context, res http.ResponseW
 		modifiedAt = timex.Max(lastPost.ModifiedAt(), modifiedAt).Truncate(time.Second)
 	}
 
-	etag := generateSitemapEtag(&website, modifiedAt)
+	archivePath, err := service.findArchivePath(ctx, service.db, website.ID)
+	if err != nil {
+		service.serveInternalError(ctx, res, err, hostname, url)
+		return
+	}
+	archivedNewsletters := []emails.Newsletter{}
+	if archivePath != "" {
+		archivedNewsletters, err = service.emailsService.FindArchivedNewsletters(ctx, service.db, website.ID, emails.ArchiveMaxNewsletters)
+		if err != nil {
+			service.serveInternalError(ctx, res, err, hostname, url)
+			return
+		}
+		if len(archivedNewsletters) != 0 {
+			modifiedAt = timex.Max(*archivedNewsletters[0].SentAt, modifiedAt).Truncate(time.Second)
+		}
+	}
+
+	etag := generateSitemapEtag(&website, archivePath, modifiedAt)
 	res.Header().Set(httpx.HeaderCacheControl, cacheControl)
 	res.Header().Set(httpx.HeaderETag, strconv.Quote(etag))
 
@@ -101,6 +119,19 @@ func (service *SiteService) serveSitemap(ctx context.Context, res http.ResponseW
 		})
 	}
 
+	if archivePath != "" {
+		sitemapFile.Add(sitemap.URL{
+			Loc:     host + archivePath,
+			LastMod: new(modifiedAt.UTC().Truncate(time.Minute)),
+		})
+		for _, newsletter := range archivedNewsletters {
+			sitemapFile.Add(sitemap.URL{
+				Loc:     host + archivePath + "/" + newsletter.ID.String(),
+				LastMod: new(newsletter.UpdatedAt.UTC().Truncate(time.Minute)),
+			})
+		}
+	}
+
 	sitemapXML, err := sitemapFile.String()
 	if err != nil {
 		service.serveInternalError(ctx, res, err, hostname, url)
@@ -116,12 +147,13 @@ func (service *SiteService) serveSitemap(ctx context.Context, res http.ResponseW
 	res.Write([]byte(sitemapXML))
 }
 
-func generateSitemapEtag(website *websites.Website, modifiedAt time.Time) string {
+func generateSitemapEtag(website *websites.Website, archivePath string, modifiedAt time.Time) string {
 	var hash [32]byte
 
 	hasher := blake3.New()
 	binary.Write(hasher, binary.LittleEndian, modifiedAt.Unix())
 	hasher.Write(website.ID[:])
+	hasher.Write([]byte(archivePath))
 	hasher.Sum(hash[:0])
 
 	return base64.RawURLEncoding.EncodeToString(hash[:])
@@ -15,6 +15,8 @@ import (
 	"github.com/skerkour/stdx-go/queue"
 	"github.com/zeebo/blake3"
 	"markdown.ninja/cmd/mdninja-server/config"
+	"markdown.ninja/pingoo-go"
+	"markdown.ninja/pkg/jwt"
 	"markdown.ninja/pkg/mailer"
 	"markdown.ninja/pkg/ratelimit"
 	"markdown.ninja/pkg/services/contacts"
@@ -43,10 +45,9 @@ type SiteService struct {
 	emailsService   emails.Service
 	storeService    store.Service
 
-	snippetsRegexp         *regexp.Regexp
-	loginEmailTemplate     *template.Template
-	subscribeEmailTemplate *template.Template
-	httpConfig             config.Http
+	snippetsRegexp            *regexp.Regexp
+	firewallChallengeTemplate *template.Template
+	httpConfig                config.Http
 	// sitesRootDomain string
 	defaultIcons map[int]defaultWebsiteIcon
 
@@ -62,7 +63,9 @@ type SiteService struct {
 	cacheZstdCompressor   *zstd.Encoder
 	cacheZstdDecompressor *zstd.Decoder
 
-	rateLimiter *ratelimit.Limiter
+	rateLimiter  ratelimit.Limiter
+	jwtProvider  *jwt.Provider
+	pingooClient *pingoo.Client
 
 	themes map[string]parsedTheme
 }
@@ -75,7 +78,8 @@ type defaultWebsiteIcon struct {
 func NewSiteService(conf config.Config, db db.DB, queue queue.Queue, mailer mailer.Mailer, logger *slog.Logger,
 	kernel kernel.PrivateService, websitesService websites.Service, contentService content.Service,
 	eventsService events.Service, contactsService contacts.Service,
-	emailsService emails.Service, storeService store.Service) (service *SiteService, err error) {
+	emailsService emails.Service, storeService store.Service, rateLimiter ratelimit.Limiter,
+	jwtProvider *jwt.Provider, pingooClient *pingoo.Client) (service *SiteService, err error) {
 
 	snippetsRegexp := regexp.MustCompile("{{<.*>}}")
 
@@ -84,15 +88,9 @@ func NewSiteService(conf config.Config, db db.DB, queue queue.Queue, mailer mail
 		return
 	}
 
-	loginEmailTemplate, err := template.New("site.loginEmailTemplate").Parse(templates.LoginEmailTemplate)
+	firewallChallengeTemplate, err := template.New("site.firewallChallengeTemplate").Parse(templates.FirewallChallengeTemplate)
 	if err != nil {
-		err = fmt.Errorf("site.NewService: Parsing loginEmailTemplate: %w", err)
-		return
-	}
-
-	subscribeEmailTemplate, err := template.New("site.subscribeEmailTemplate").Parse(templates.SubscribeEmailTemplate)
-	if err != nil {
-		err = fmt.Errorf("site.NewService: Parsing subscribeEmailTemplate: %w", err)
+		err = fmt.Errorf("site.NewService: Parsing firewallChallengeTemplate: %w", err)
 		return
 	}
 
@@ -166,13 +164,12 @@ func NewSiteService(conf config.Config, db db.DB, queue queue.Queue, mailer mail
 		emailsService:   emailsService,
 		storeService:    storeService,
 
-		snippetsRegexp:         snippetsRegexp,
-		loginEmailTemplate:     loginEmailTemplate,
-		subscribeEmailTemplate: subscribeEmailTemplate,
-		httpConfig:             conf.HTTP,
-		defaultIcons:           defaultIcons,
-		cacheZstdCompressor:    cacheZstdCompressor,
-		cacheZstdDecompressor:  cacheZstdDecompressor,
+		snippetsRegexp:            snippetsRegexp,
+		firewallChallengeTemplate: firewallChallengeTemplate,
+		httpConfig:                conf.HTTP,
+		defaultIcons:              defaultIcons,
+		cacheZstdCompressor:       cacheZstdCompressor,
+		cacheZstdDecompressor:     cacheZstdDecompressor,
 
 		pagesHtmlCache: pagesHtmlCache,
 		assetsCache:    assetsCache,
@@ -180,7 +177,9 @@ func NewSiteService(conf config.Config, db db.DB, queue queue.Queue, mailer mail
 		feedsCache:     feedsCache,
 		sitemapsCache:  sitemapsCache,
 
-		rateLimiter: ratelimit.New(),
+		rateLimiter:  rateLimiter,
+		jwtProvider:  jwtProvider,
+		pingooClient: pingooClient,
 
 		themes: themes,
 	}
@@ -44,7 +44,8 @@ func (service *SiteService) Subscribe(ctx context.Context, input site.SubscribeI
 		return
 	}
 
-	if !service.rateLimiter.IsAllowed("SiteService.Subscribe", website.ID.Bytes(), httpCtx.Client.IP.AsSlice(), time.Hour, 30) {
+	if !service.rateLimiter.IsAllowed(ctx, "SiteService.Subscribe", website.ID.Bytes(), httpCtx.Client.IP.AsSlice(), time.Hour,
+		uint64(website.RateLimits.WithDefaults().Subscribe)) {
 		err = errs.TooManyRequests()
 		return
 	}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/services/kernel"
+	"markdown.ninja/pkg/services/site"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *SiteService) UpdateLessonProgress(ctx context.Context, input site.UpdateLessonProgressInput) (err error) {
+	contact := service.contactsService.CurrentContact(ctx)
+	if contact == nil {
+		err = kernel.ErrAuthenticationRequired
+		return
+	}
+
+	err = service.storeService.UpdateLessonProgress(ctx, service.db, store.UpdateLessonProgressInput{
+		ContactID:     contact.ID,
+		ProductPageID: input.ProductPageID,
+		Completed:     input.Completed,
+	})
+	if err != nil {
+		return
+	}
+
+	return nil
+}
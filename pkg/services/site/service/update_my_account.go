@@ -9,6 +9,7 @@ import (
 	"markdown.ninja/pkg/errs"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/contacts"
+	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/events"
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/site"
@@ -22,7 +23,7 @@ func (service *SiteService) UpdateMyAccount(ctx context.Context, input site.Upda
 	}
 
 	unsubscribedFromNewsletter := false
-	subscribedToNewsletter := true
+	subscribedToNewsletter := false
 	httpCtx := httpctx.FromCtx(ctx)
 	domain := httpCtx.Hostname
 	sendVerifyEmailEmail := false
@@ -68,11 +69,20 @@ func (service *SiteService) UpdateMyAccount(ctx context.Context, input site.Upda
 		}
 	}
 
+	newsletterTagIDs, err := service.findNewsletterTagIDs(ctx, website.ID, input.NewsletterTags)
+	if err != nil {
+		return
+	}
+
 	updateContactInput := contacts.UpdateContactInput{
-		ID:                     contact.ID,
-		Name:                   input.Name,
-		SubscribedToNewsletter: input.SubscribedToNewsletter,
-		BillingAddress:         input.BillingAddress,
+		ID:                         contact.ID,
+		Name:                       input.Name,
+		SubscribedToNewsletter:     input.SubscribedToNewsletter,
+		NewsletterDelivery:         input.NewsletterDelivery,
+		ReceiveNewsletters:         input.ReceiveNewsletters,
+		SubscribedToProductUpdates: input.SubscribedToProductUpdates,
+		NewsletterTagIDs:           newsletterTagIDs,
+		BillingAddress:             input.BillingAddress,
 	}
 	err = service.contactsService.UpdateContactInternal(ctx, service.db, contact, updateContactInput)
 	if err != nil {
@@ -86,6 +96,22 @@ func (service *SiteService) UpdateMyAccount(ctx context.Context, input site.Upda
 	// }
 
 	retContact = service.convertContact(*contact)
+	err = service.fillNewsletterPreferences(ctx, *contact, &retContact.NewsletterPreferences)
+	if err != nil {
+		return
+	}
+
+	if subscribedToNewsletter {
+		triggerErr := service.emailsService.TriggerSequences(ctx, service.db, emails.TriggerSequencesInput{
+			WebsiteID: website.ID,
+			ContactID: contact.ID,
+			Trigger:   emails.SequenceTriggerSubscribedToNewsletter,
+		})
+		if triggerErr != nil {
+			logger := slogx.FromCtx(ctx)
+			logger.Error("site.UpdateMyAccount: triggering email sequences", slogx.Err(triggerErr))
+		}
+	}
 
 	if sendVerifyEmailEmail {
 		job := queue.NewJobInput{
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/contacts"
+	"markdown.ninja/pkg/services/site"
+)
+
+// UpdateNewsletterPreferences updates the topics received by the contact of the unsubscribe link. The
+// contact unsubscribes from the newsletter with Unsubscribe.
+func (service *SiteService) UpdateNewsletterPreferences(ctx context.Context, input site.UpdateNewsletterPreferencesInput) (ret site.NewsletterPreferences, err error) {
+	httpCtx := httpctx.FromCtx(ctx)
+
+	website, err := service.websitesService.FindWebsiteByDomain(ctx, service.db, httpCtx.Hostname)
+	if err != nil {
+		return
+	}
+
+	contact, err := service.findContactByUnsubscribeToken(ctx, website, input.Token)
+	if err != nil {
+		return
+	}
+
+	newsletterTagIDs, err := service.findNewsletterTagIDs(ctx, website.ID, input.NewsletterTags)
+	if err != nil {
+		return
+	}
+
+	updateContactInput := contacts.UpdateContactInput{
+		ID:                         contact.ID,
+		NewsletterDelivery:         input.NewsletterDelivery,
+		ReceiveNewsletters:         input.ReceiveNewsletters,
+		SubscribedToProductUpdates: input.SubscribedToProductUpdates,
+		NewsletterTagIDs:           newsletterTagIDs,
+	}
+	err = service.contactsService.UpdateContactInternal(ctx, service.db, &contact, updateContactInput)
+	if err != nil {
+		return
+	}
+
+	ret = service.convertNewsletterPreferences(contact)
+	err = service.fillNewsletterPreferences(ctx, contact, &ret)
+	if err != nil {
+		return
+	}
+
+	return ret, nil
+}
@@ -5,14 +5,8 @@ import (
 	"testing"
 )
 
-func TestLoginEmailTemplate(t *testing.T) {
-	if strings.TrimSpace(LoginEmailTemplate) == "" {
-		t.Error("LoginEmailTemplate is empty")
-	}
-}
-
-func TestSubscribeEmailTemplate(t *testing.T) {
-	if strings.TrimSpace(SubscribeEmailTemplate) == "" {
-		t.Error("SubscribeEmailTemplate is empty")
+func TestFirewallChallengeTemplate(t *testing.T) {
+	if strings.TrimSpace(FirewallChallengeTemplate) == "" {
+		t.Error("FirewallChallengeTemplate is empty")
 	}
 }
@@ -2,9 +2,11 @@ package store
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/skerkour/stdx-go/guid"
 	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/websites"
 )
 
 var (
@@ -23,14 +25,91 @@ var (
 	ErrProductIsNotAvailable                  = func(productName string) error {
 		return errs.InvalidArgument(fmt.Sprintf("%s is currently not available", productName))
 	}
+	ErrProductIsOutOfStock = func(productName string) error {
+		return errs.InvalidArgument(fmt.Sprintf("%s is out of stock", productName))
+	}
 	ErrProductAccessNotFound       = errs.NotFound("Product access not found")
 	ErrCantDeleteProductWithOrders = errs.InvalidArgument("A product can't be deleted once orders have been placed.")
 
+	// Prices
+	ErrProductPricesAreNotValid             = errs.InvalidArgument("Prices must be in a supported currency other than the currency of the website.")
+	ErrPayWhatYouWantMinimumPriceCantBeZero = errs.InvalidArgument("The minimum price of a pay-what-you-want product can't be 0.")
+	ErrProductIsNotPayWhatYouWant           = func(productName string) error {
+		return errs.InvalidArgument(fmt.Sprintf("You can't choose the price of %s", productName))
+	}
+	ErrCustomPriceIsTooLow = func(productName string, minimumPrice int64, currency websites.Currency) error {
+		return errs.InvalidArgument(fmt.Sprintf("The minimum price of %s is %d %s", productName, minimumPrice, currency))
+	}
+
+	// Memberships
+	ErrMembershipNotFound                  = errs.NotFound("Membership not found.")
+	ErrBillingIntervalIsNotValid           = errs.InvalidArgument("Billing interval is not valid (must be month or year)")
+	ErrBillingIntervalIsOnlyForMemberships = errs.InvalidArgument("Billing interval is only available for memberships.")
+	ErrMembershipMustBeOrderedAlone        = errs.InvalidArgument("A membership must be ordered alone.")
+	ErrProductIsNotAMembership             = errs.InvalidArgument("Product is not a membership.")
+	ErrAlreadyAMember                      = errs.InvalidArgument("You already are a member.")
+	ErrContactIsAlreadyAMember             = errs.InvalidArgument("Contact already is a member.")
+	ErrMembershipIsAlreadyCanceled         = errs.InvalidArgument("Membership is already canceled.")
+
+	// Bundles
+	ErrBundleItemsAreNotValid         = errs.InvalidArgument(fmt.Sprintf("A bundle must include between 1 and %d products.", BundleItemsMaxCount))
+	ErrBundleItemsAreOnlyForBundles   = errs.InvalidArgument("Only bundles can include other products.")
+	ErrProductCantBeIncludedInABundle = errs.InvalidArgument("Bundles can't include memberships or other bundles.")
+
+	// License keys
+	ErrLicenseKeyNotFound                    = errs.NotFound("License key not found.")
+	ErrLicenseKeysAreOnlyForDigitalDownloads = errs.InvalidArgument("License keys are only available for digital downloads.")
+	ErrLicenseKeysModeIsNotValid             = errs.InvalidArgument("License keys mode is not valid (must be none, generated or pool)")
+	ErrLicenseKeyActivationsLimitIsNotValid  = errs.InvalidArgument(fmt.Sprintf("Activations limit is not valid (must be between 0 and %d)", LicenseKeyActivationsLimitMax))
+	ErrLicenseKeysPoolImportIsNotValid       = errs.InvalidArgument(fmt.Sprintf("Between 1 and %d keys can be added at once.", LicenseKeysPoolImportMaxCount))
+	ErrLicenseKeyIsNotValid                  = errs.InvalidArgument(fmt.Sprintf("License keys must not be empty and at most %d characters long.", LicenseKeyMaxLength))
+	ErrLicenseKeyAlreadyExists               = func(key string) error {
+		return errs.InvalidArgument(fmt.Sprintf("License key %s already exists.", key))
+	}
+	ErrProductIsNotUsingALicenseKeysPool = errs.InvalidArgument("Product is not using a pool of license keys.")
+	ErrLicenseKeyIsRevoked               = errs.PermissionDenied("License key has been revoked.")
+	ErrLicenseKeyIsAlreadyRevoked        = errs.InvalidArgument("License key is already revoked.")
+	ErrLicenseKeyInstanceIsNotValid      = errs.InvalidArgument(fmt.Sprintf("Instance must not be empty and at most %d characters long.", LicenseKeyInstanceMaxLength))
+	ErrLicenseKeyActivationsLimitReached = errs.PermissionDenied("The maximum number of activations for this license key has been reached.")
+
+	// Ebooks
+	ErrProductEbookNotFound           = errs.NotFound("Ebook not found. It may still be generating, please try again in a few minutes.")
+	ErrProductEbookFormatIsNotValid   = errs.InvalidArgument("Ebook format is not valid")
+	ErrEbooksAreOnlyAvailableForBooks = errs.InvalidArgument("Ebooks are only available for books.")
+
 	// Orders
 	ErrOrderNotFound = func(orderID guid.GUID) error {
 		return errs.NotFound(fmt.Sprintf("Order %s not found", orderID.String()))
 	}
-	ErrOrderIsNotCompleted = errs.NotFound("Order is not completed. Please make sure that the payment was successful or contact support if the problem persists.")
+	ErrOrderIsNotCompleted     = errs.NotFound("Order is not completed. Please make sure that the payment was successful or contact support if the problem persists.")
+	ErrOrderIsAlreadyCompleted = errs.InvalidArgument("Order is already completed.")
+
+	// Gifts
+	ErrGiftMessageIsNotValid           = errs.InvalidArgument(fmt.Sprintf("Gift message is too long (max: %d characters)", GiftMessageMaxLength))
+	ErrGiftRecipientIsTheBuyer         = errs.InvalidArgument("You can't offer a gift to yourself.")
+	ErrMembershipsCantBeGifted         = errs.InvalidArgument("Memberships can't be offered as gifts.")
+	ErrGiftMessageRequiresRecipient    = errs.InvalidArgument("A gift message requires a gift recipient.")
+	ErrProductAccessTransferIsNotValid = errs.InvalidArgument("Access can't be transferred to the same contact.")
+
+	// Abandoned checkouts
+	ErrAbandonedCheckoutSettingsNotFound  = errs.NotFound("Abandoned checkout settings not found.")
+	ErrAbandonedCheckoutDelayIsNotValid   = errs.InvalidArgument(fmt.Sprintf("Delay is not valid (must be between %d and %d hours)", AbandonedCheckoutDelayHoursMin, AbandonedCheckoutDelayHoursMax))
+	ErrAbandonedCheckoutSubjectIsNotValid = errs.InvalidArgument(fmt.Sprintf("Subject must not be empty and at most %d characters long.", AbandonedCheckoutSubjectMaxLength))
+	ErrAbandonedCheckoutBodyIsTooLong     = errs.InvalidArgument(fmt.Sprintf("Email content is too long (max: %d characters)", AbandonedCheckoutBodyMaxLength))
+
+	// Invoices
+	ErrInvoiceNotFound                  = errs.NotFound("Invoice not found.")
+	ErrInvoicingSettingsNotFound        = errs.NotFound("Invoicing settings not found.")
+	ErrInvoiceFormatIsNotValid          = errs.InvalidArgument("Invoice format is not valid (must be pdf or html)")
+	ErrInvoiceSellerNameIsRequired      = errs.InvalidArgument("The name of the seller is required to generate invoices.")
+	ErrInvoiceSellerNameIsNotValid      = errs.InvalidArgument(fmt.Sprintf("Seller name must be a single line of at most %d characters.", InvoiceSellerNameMaxLength))
+	ErrInvoiceSellerAddressIsTooLong    = errs.InvalidArgument(fmt.Sprintf("Seller address is too long (max: %d characters)", InvoiceSellerAddressMaxLength))
+	ErrInvoiceSellerVatNumberIsNotValid = errs.InvalidArgument(fmt.Sprintf("VAT number must be a single line of at most %d characters.", InvoiceSellerVatNumberMaxLength))
+	ErrInvoiceVatRateIsNotValid         = errs.InvalidArgument("VAT rate is not valid (must be between 0% and 100%)")
+	ErrInvoiceNotesAreTooLong           = errs.InvalidArgument(fmt.Sprintf("Notes are too long (max: %d characters)", InvoiceNotesMaxLength))
+	ErrInvoiceNumberPrefixIsNotValid    = errs.InvalidArgument(fmt.Sprintf("Number prefixes must be at most %d characters long and contain only letters, digits, - and _", InvoiceNumberPrefixMaxLength))
+	ErrAccountingExportFormatIsNotValid = errs.InvalidArgument("Export format is not valid (must be csv or json)")
+	ErrAccountingExportPeriodIsNotValid = errs.InvalidArgument("The end of the period must be after its start, and the period can't exceed 1 year.")
 
 	// Refunds
 	ErrRefundReasonNotValid                = errs.InvalidArgument(fmt.Sprintf("Refund reason is not valid. Valid values are: %s", ValidRefundReasons.ToSlice()))
@@ -52,17 +131,27 @@ var (
 	ErrProductShouldHaveAtLeastOnePage    = errs.InvalidArgument("Products should have at least 1 page")
 	ErrPageContentIsTooLong               = errs.InvalidArgument("Page content is too long")
 	ErrProductPageTitleIsNotValid         = errs.InvalidArgument("Page title is not valid")
+	ErrProductPageDripDaysIsNotValid      = errs.InvalidArgument(fmt.Sprintf("Drip delay is not valid (must be between 0 and %d days)", ProductPageDripDaysMax))
+	ErrLessonIsLocked                     = func(availableAt time.Time) error {
+		return errs.PermissionDenied(fmt.Sprintf("This lesson is available from %s", availableAt.Format(time.DateOnly)))
+	}
 
 	// Coupons
-	ErrCouponNotFound              = errs.NotFound("Coupon not found.")
-	ErrCouponCodeIsTooShort        = errs.InvalidArgument(fmt.Sprintf("Code is too short (min: %d characters)", CouponCodeMinLength))
-	ErrCouponCodeIsTooLong         = errs.InvalidArgument(fmt.Sprintf("Code is too long (max: %d characters)", CouponCodeMaxLength))
-	ErrCouponCodeIsNotValid        = errs.InvalidArgument("Code is not valid [A-Z-]")
-	ErrCouponCodeMustBeUpperCase   = errs.InvalidArgument("Code must be upper case")
-	ErrCouponDescriptionIsTooLong  = errs.InvalidArgument(fmt.Sprintf("Description is too long (max: %d characters)", CouponDescriptionMaxLength))
-	ErrCouponDescriptionIsNotValid = errs.InvalidArgument("Description is not valid")
-	ErrCouponDiscountIsNotValid    = errs.InvalidArgument("Discount is not valid (must be between 1 and 100)")
-	ErrCouponCodeAlreadyExists     = func(code string) error {
+	ErrCouponNotFound               = errs.NotFound("Coupon not found.")
+	ErrCouponCodeIsTooShort         = errs.InvalidArgument(fmt.Sprintf("Code is too short (min: %d characters)", CouponCodeMinLength))
+	ErrCouponCodeIsTooLong          = errs.InvalidArgument(fmt.Sprintf("Code is too long (max: %d characters)", CouponCodeMaxLength))
+	ErrCouponCodeIsNotValid         = errs.InvalidArgument("Code is not valid [A-Z-]")
+	ErrCouponCodeMustBeUpperCase    = errs.InvalidArgument("Code must be upper case")
+	ErrCouponDescriptionIsTooLong   = errs.InvalidArgument(fmt.Sprintf("Description is too long (max: %d characters)", CouponDescriptionMaxLength))
+	ErrCouponDescriptionIsNotValid  = errs.InvalidArgument("Description is not valid")
+	ErrCouponDiscountIsNotValid     = errs.InvalidArgument("Discount is not valid (must be between 1 and 99)")
+	ErrCouponUsesLimitIsNotValid    = errs.InvalidArgument("Uses limit is not valid (must be positive, 0 means unlimited)")
+	ErrCouponIsNotValid             = errs.InvalidArgument("Coupon is not valid.")
+	ErrCouponHasExpired             = errs.InvalidArgument("Coupon has expired.")
+	ErrCouponUsesLimitReached       = errs.InvalidArgument("Coupon has reached its usage limit.")
+	ErrCouponDoesNotApplyToProducts = errs.InvalidArgument("Coupon does not apply to any of the selected products.")
+	ErrOrderTotalCantBeZero         = errs.InvalidArgument("The total amount of the order can't be 0.")
+	ErrCouponCodeAlreadyExists      = func(code string) error {
 		return errs.InvalidArgument(fmt.Sprintf("A Coupon with the code \"%s\" already exists", code))
 	}
 )
@@ -10,6 +10,16 @@ func (JobSendOrderConfirmationEmail) JobType() string {
 	return "store.send_order_confirmation_email"
 }
 
+// JobSendGiftNotificationEmail notifies the recipient of a gift that they have been given access to
+// the products of the order.
+type JobSendGiftNotificationEmail struct {
+	OrderID guid.GUID `json:"order_id"`
+}
+
+func (JobSendGiftNotificationEmail) JobType() string {
+	return "store.send_gift_notification_email"
+}
+
 type JobCreateStripeRefund struct {
 	RefundID guid.GUID `json:"refund_id"`
 }
@@ -25,3 +35,35 @@ type JobSyncRefundWithStripe struct {
 func (JobSyncRefundWithStripe) JobType() string {
 	return "store.sync_refund_with_stripe"
 }
+
+type JobGenerateProductEbooks struct {
+	ProductID guid.GUID `json:"product_id"`
+}
+
+func (JobGenerateProductEbooks) JobType() string {
+	return "store.generate_product_ebooks"
+}
+
+type JobSendAbandonedCheckoutEmail struct {
+	OrderID guid.GUID `json:"order_id"`
+}
+
+func (JobSendAbandonedCheckoutEmail) JobType() string {
+	return "store.send_abandoned_checkout_email"
+}
+
+type JobGenerateInvoice struct {
+	OrderID guid.GUID `json:"order_id"`
+}
+
+func (JobGenerateInvoice) JobType() string {
+	return "store.generate_invoice"
+}
+
+type JobGenerateCreditNote struct {
+	RefundID guid.GUID `json:"refund_id"`
+}
+
+func (JobGenerateCreditNote) JobType() string {
+	return "store.generate_credit_note"
+}
@@ -1,10 +1,13 @@
 package store
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"regexp"
+	"slices"
 	"time"
 
 	"github.com/skerkour/stdx-go/guid"
@@ -20,6 +23,48 @@ const (
 	ProductDescriptionMaxLength = 420
 	ProductPriceMax             = math.MaxInt32
 
+	BundleItemsMaxCount = 50
+
+	// ProductPageDripDaysMax is the maximum delay before a page of a course is unlocked (~10 years)
+	ProductPageDripDaysMax = 3650
+
+	LicenseKeyMaxLength = 256
+	// LicenseKeysPoolImportMaxCount is the maximum number of keys that can be added to the pool at once
+	LicenseKeysPoolImportMaxCount = 5000
+	LicenseKeyActivationsLimitMax = 100_000
+	LicenseKeyInstanceMaxLength   = 256
+	// generated license keys look like XXXXX-XXXXX-XXXXX-XXXXX-XXXXX (~125 bits of entropy)
+	LicenseKeyAlphabet     = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	LicenseKeyGroupsCount  = 5
+	LicenseKeyGroupsLength = 5
+
+	// checkout sessions expire after 24 hours, so recovery emails must be sent before that
+	AbandonedCheckoutDelayHoursMin       = 1
+	AbandonedCheckoutDelayHoursMax       = 23
+	AbandonedCheckoutMaxAge              = 24 * time.Hour
+	AbandonedCheckoutSubjectMaxLength    = 200
+	AbandonedCheckoutBodyMaxLength       = 20_000
+	AbandonedCheckoutsTaskBatchSize      = 1000
+	DefaultAbandonedCheckoutDelayHours   = 2
+	DefaultAbandonedCheckoutSubject      = "You left something in your cart"
+	DefaultAbandonedCheckoutBodyMarkdown = "Hi,\n\nYou started an order but didn't complete it. Your cart is still waiting for you, just click on the button below to complete your purchase."
+	// the links of the recovery emails lead to the checkout page of the website once the checkout session
+	// has expired, so they remain valid longer than the checkout sessions
+	ResumeOrderLinkTimeout = 30 * 24 * time.Hour
+
+	InvoiceSellerNameMaxLength      = 200
+	InvoiceSellerAddressMaxLength   = 1000
+	InvoiceSellerVatNumberMaxLength = 50
+	InvoiceNotesMaxLength           = 2000
+	InvoiceNumberPrefixMaxLength    = 20
+	InvoicesListLimit               = 100
+	DefaultInvoiceNumberPrefix      = "INV-"
+	DefaultCreditNoteNumberPrefix   = "CN-"
+	// AccountingExportMaxRange is the maximum duration between the start and the end of an accounting export
+	AccountingExportMaxRange = 366 * 24 * time.Hour
+
+	GiftMessageMaxLength = 1000
+
 	CouponDescriptionMaxLength = 512
 	CouponCodeMinLength        = 2
 	CouponCodeMaxLength        = 42
@@ -27,6 +72,9 @@ const (
 
 var (
 	CouponCodeRegexp = regexp.MustCompile("^[-A-Z0-9]+$")
+	// InvoiceNumberPrefixRegexp restricts the prefixes of invoice numbers to characters that are safe in
+	// filenames and in accounting softwares
+	InvoiceNumberPrefixRegexp = regexp.MustCompile("^[-_A-Za-z0-9]*$")
 )
 
 type ProductType int64
@@ -35,8 +83,11 @@ const (
 	ProductTypeBook ProductType = iota
 	ProductTypeCourse
 	ProductTypeDigitalDownload
-	// ProductTypeBundle
-	// ProductTypeSubscription
+	// ProductTypeMembership is a recurring product, billed every BillingInterval, that gives access
+	// to the members-only pages and newsletters of the website while the membership is active.
+	ProductTypeMembership
+	// ProductTypeBundle is sold at its own price and gives access to all the products it includes.
+	ProductTypeBundle
 )
 
 // MarshalText implements encoding.TextMarshaler.
@@ -48,6 +99,10 @@ func (productType ProductType) MarshalText() (ret []byte, err error) {
 		ret = []byte("course")
 	case ProductTypeDigitalDownload:
 		ret = []byte("download")
+	case ProductTypeMembership:
+		ret = []byte("membership")
+	case ProductTypeBundle:
+		ret = []byte("bundle")
 	default:
 		err = fmt.Errorf("Unknown ProductType: %d", productType)
 	}
@@ -68,12 +123,44 @@ func (productType *ProductType) UnmarshalText(data []byte) (err error) {
 		*productType = ProductTypeCourse
 	case "download":
 		*productType = ProductTypeDigitalDownload
+	case "membership":
+		*productType = ProductTypeMembership
+	case "bundle":
+		*productType = ProductTypeBundle
 	default:
 		err = fmt.Errorf("Unknown ProductType: %s", string(data))
 	}
 	return nil
 }
 
+type BillingInterval string
+
+const (
+	BillingIntervalMonth BillingInterval = "month"
+	BillingIntervalYear  BillingInterval = "year"
+)
+
+// LicenseKeysMode is how license keys are issued to the customers of a product
+type LicenseKeysMode string
+
+const (
+	LicenseKeysModeNone LicenseKeysMode = "none"
+	// LicenseKeysModeGenerated issues a random key for each purchase
+	LicenseKeysModeGenerated LicenseKeysMode = "generated"
+	// LicenseKeysModePool issues keys from a pool of keys uploaded by the staff of the website
+	LicenseKeysModePool LicenseKeysMode = "pool"
+)
+
+type LicenseKeyStatus string
+
+const (
+	// LicenseKeyStatusAvailable is the status of the keys of a pool that are not issued yet
+	LicenseKeyStatusAvailable LicenseKeyStatus = "available"
+	LicenseKeyStatusIssued    LicenseKeyStatus = "issued"
+	// LicenseKeyStatusRevoked keys (e.g. after a refund) can no longer be activated or verified
+	LicenseKeyStatusRevoked LicenseKeyStatus = "revoked"
+)
+
 type ProductStatus int64
 
 const (
@@ -167,6 +254,44 @@ func (orderStatus *OrderStatus) UnmarshalText(data []byte) (err error) {
 	return nil
 }
 
+// MembershipStatus mirrors the status of the subscription backing the membership (see
+// payments.SubscriptionStatus).
+// See https://docs.stripe.com/api/subscriptions/object#subscription_object-status
+type MembershipStatus string
+
+const (
+	MembershipStatusActive            MembershipStatus = "active"
+	MembershipStatusTrialing          MembershipStatus = "trialing"
+	MembershipStatusPastDue           MembershipStatus = "past_due"
+	MembershipStatusUnpaid            MembershipStatus = "unpaid"
+	MembershipStatusPaused            MembershipStatus = "paused"
+	MembershipStatusIncomplete        MembershipStatus = "incomplete"
+	MembershipStatusIncompleteExpired MembershipStatus = "incomplete_expired"
+	MembershipStatusCanceled          MembershipStatus = "canceled"
+)
+
+// ActiveMembershipStatuses are the statuses giving access to members-only content.
+// past_due memberships stay active while the payment provider retries the payment.
+var ActiveMembershipStatuses = []MembershipStatus{
+	MembershipStatusActive,
+	MembershipStatusTrialing,
+	MembershipStatusPastDue,
+}
+
+type ProductEbookFormat string
+
+const (
+	ProductEbookFormatEpub ProductEbookFormat = "epub"
+	// ProductEbookFormatHtml is a single, self-contained and print-ready HTML document that can be saved as
+	// PDF from any web browser.
+	ProductEbookFormatHtml ProductEbookFormat = "html"
+)
+
+var ProductEbookFormats = []ProductEbookFormat{
+	ProductEbookFormatEpub,
+	ProductEbookFormatHtml,
+}
+
 type RefundStatus string
 
 const (
@@ -219,11 +344,47 @@ type Product struct {
 	Type        ProductType   `db:"type" json:"type"`
 	Price       int64         `db:"price" json:"price"`
 	Status      ProductStatus `db:"status" json:"status"`
+	// if true, the ebooks downloaded by customers are watermarked with their email address
+	EbookWatermark bool `db:"ebook_watermark" json:"ebook_watermark"`
+	// only for memberships
+	BillingInterval *BillingInterval `db:"billing_interval" json:"billing_interval"`
+	// Prices are the explicit prices of the product in other currencies than the currency of the
+	// website. The price in the currency of the website is always Price.
+	Prices ProductPrices `db:"prices" json:"prices"`
+	// if true, the prices are minimum prices and buyers choose how much they want to pay
+	PayWhatYouWant bool `db:"pay_what_you_want" json:"pay_what_you_want"`
+	// only for digital downloads
+	LicenseKeys LicenseKeysMode `db:"license_keys" json:"license_keys"`
+	// maximum number of activations of each license key. 0 means unlimited
+	LicenseKeyActivationsLimit int64 `db:"license_key_activations_limit" json:"license_key_activations_limit"`
 
 	WebsiteID guid.GUID `db:"website_id" json:"-"`
 
 	Content []ProductPage   `json:"content"`
 	Assets  []content.Asset `json:"assets"`
+	Ebooks  []ProductEbook  `json:"ebooks"`
+	// only for bundles: the products included in the bundle
+	BundleItems []guid.GUID `json:"bundle_items"`
+}
+
+type ProductPrices map[websites.Currency]int64
+
+func (prices *ProductPrices) Scan(val any) error {
+	switch v := val.(type) {
+	case []byte:
+		return json.Unmarshal(v, prices)
+	case string:
+		return json.Unmarshal([]byte(v), prices)
+	default:
+		return fmt.Errorf("ProductPrices.Scan: Unsupported type: %T", v)
+	}
+}
+
+func (prices ProductPrices) Value() (driver.Value, error) {
+	if prices == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(prices)
 }
 
 type ProductPage struct {
@@ -237,16 +398,164 @@ type ProductPage struct {
 	// BLAKE3 hash of the bodyMarkdown
 	Hash         kernel.BytesHex `db:"hash" json:"hash"`
 	BodyMarkdown string          `db:"body_markdown" json:"body_markdown"`
+	// DripDays is the number of days after the purchase before the page is unlocked (drip content).
+	// 0 means that the page is available immediately.
+	DripDays int64 `db:"drip_days" json:"drip_days"`
 
 	ProductID guid.GUID `db:"product_id" json:"-"`
 }
 
+// AvailableAt returns the date at which the page is unlocked for a contact who was given access to
+// the product at accessGrantedAt (see ContactProductAccess.CreatedAt).
+func (page *ProductPage) AvailableAt(accessGrantedAt time.Time) time.Time {
+	return accessGrantedAt.AddDate(0, 0, int(page.DripDays))
+}
+
+// IsLocked returns true if the page is not unlocked yet for a contact who was given access to the
+// product at accessGrantedAt.
+func (page *ProductPage) IsLocked(accessGrantedAt, now time.Time) bool {
+	return page.DripDays != 0 && now.Before(page.AvailableAt(accessGrantedAt))
+}
+
+// ProductPageCompletion records that a contact completed a lesson (page) of a course
+type ProductPageCompletion struct {
+	CompletedAt time.Time `db:"completed_at"`
+
+	ContactID     guid.GUID `db:"contact_id"`
+	ProductPageID guid.GUID `db:"product_page_id"`
+	ProductID     guid.GUID `db:"product_id"`
+}
+
+// CourseProgress is the progress of a contact in a course
+type CourseProgress struct {
+	CompletedLessons int64 `json:"completed_lessons"`
+	TotalLessons     int64 `json:"total_lessons"`
+}
+
+// CourseAnalytics is the completion of the lessons of a course by its students
+type CourseAnalytics struct {
+	// Students is the number of contacts who have access to the course
+	Students int64             `json:"students"`
+	Lessons  []LessonAnalytics `json:"lessons"`
+}
+
+type LessonAnalytics struct {
+	ProductPageID guid.GUID `db:"product_page_id" json:"product_page_id"`
+	Position      int64     `db:"position" json:"position"`
+	Title         string    `db:"title" json:"title"`
+	DripDays      int64     `db:"drip_days" json:"drip_days"`
+	Completions   int64     `db:"completions" json:"completions"`
+}
+
+// ProductEbook is a downloadable version of a book, generated from its pages and assets.
+type ProductEbook struct {
+	ID        guid.GUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	Format ProductEbookFormat `db:"format" json:"format"`
+	// BLAKE3 hash of the content (pages and assets) used to generate the ebook
+	ContentHash kernel.BytesHex `db:"content_hash" json:"content_hash"`
+	Size        int64           `db:"size" json:"size"`
+
+	ProductID guid.GUID `db:"product_id" json:"-"`
+}
+
+// LicenseKey is issued to a customer when they purchase a product with license keys, so the apps of
+// the seller can validate it with the public verify / activate API.
+type LicenseKey struct {
+	ID        guid.GUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	Key       string           `db:"key" json:"key"`
+	Status    LicenseKeyStatus `db:"status" json:"status"`
+	IssuedAt  *time.Time       `db:"issued_at" json:"issued_at"`
+	RevokedAt *time.Time       `db:"revoked_at" json:"revoked_at"`
+
+	WebsiteID guid.GUID `db:"website_id" json:"-"`
+	ProductID guid.GUID `db:"product_id" json:"product_id"`
+	// OrderID and ContactID are null until the key is issued
+	OrderID   *guid.GUID `db:"order_id" json:"order_id"`
+	ContactID *guid.GUID `db:"contact_id" json:"contact_id"`
+
+	// only filled by some queries
+	ProductName string `db:"product_name" json:"product_name,omitempty"`
+	Activations int64  `db:"activations" json:"activations"`
+}
+
+// PendingLicenseKey is a license key that could not be issued when the order was completed because
+// the pool of keys of the product was empty. It is issued when keys are added to the pool.
+type PendingLicenseKey struct {
+	ID        guid.GUID `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+
+	ProductID guid.GUID `db:"product_id"`
+	OrderID   guid.GUID `db:"order_id"`
+	ContactID guid.GUID `db:"contact_id"`
+}
+
+type LicenseKeyActivation struct {
+	ID        guid.GUID `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+
+	// Instance identifies the activated machine / installation. It is chosen by the application.
+	Instance string `db:"instance"`
+
+	LicenseKeyID guid.GUID `db:"license_key_id"`
+}
+
+type BundleProductRelation struct {
+	BundleID  guid.GUID `db:"bundle_id"`
+	ProductID guid.GUID `db:"product_id"`
+}
+
 type ContactProductAccess struct {
 	CreatedAt time.Time `db:"created_at"`
 	ContactID guid.GUID `db:"contact_id"`
 	ProductID guid.GUID `db:"product_id"`
 }
 
+// OrderGrantedProduct records the access to a product given by a completed order, so that the access
+// can be revoked if the order is refunded even if the bundles of the order have been edited since.
+type OrderGrantedProduct struct {
+	ID        guid.GUID `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+
+	// BundleID is the bundle of the order that included the product, if any
+	BundleID  *guid.GUID `db:"bundle_id"`
+	OrderID   guid.GUID  `db:"order_id"`
+	ProductID guid.GUID  `db:"product_id"`
+	ContactID guid.GUID  `db:"contact_id"`
+}
+
+// Membership gives a contact access to the members-only content of a website.
+// Memberships are either backed by a subscription of the payment provider, or granted manually by the
+// staff of the website, in which case StripeSubscriptionID is null.
+type Membership struct {
+	ID        guid.GUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	Status MembershipStatus `db:"status" json:"status"`
+	// CurrentPeriodEnd is null for memberships granted manually
+	CurrentPeriodEnd     *time.Time `db:"current_period_end" json:"current_period_end"`
+	CancelAtPeriodEnd    bool       `db:"cancel_at_period_end" json:"cancel_at_period_end"`
+	CanceledAt           *time.Time `db:"canceled_at" json:"canceled_at"`
+	StripeSubscriptionID *string    `db:"stripe_subscription_id" json:"stripe_subscription_id"`
+
+	WebsiteID guid.GUID `db:"website_id" json:"-"`
+	ContactID guid.GUID `db:"contact_id" json:"contact_id"`
+	ProductID guid.GUID `db:"product_id" json:"product_id"`
+
+	ProductName string `db:"-" json:"product_name"`
+}
+
+// IsActive returns true if the member should have access to members-only content.
+func (membership *Membership) IsActive() bool {
+	return slices.Contains(ActiveMembershipStatuses, membership.Status)
+}
+
 type Order struct {
 	ID        guid.GUID `db:"id" json:"id"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
@@ -258,17 +567,32 @@ type Order struct {
 	Status      OrderStatus       `db:"status" json:"status"`
 	CompletedAt *time.Time        `db:"completed_at" json:"completed_at"`
 	CanceledAt  *time.Time        `db:"canceled_at" json:"canceled_at"`
+	// DiscountAmount is the amount deducted from the order by the coupon, if any. It is already
+	// deducted from TotalAmount.
+	DiscountAmount int64      `db:"discount_amount" json:"discount_amount"`
+	CouponID       *guid.GUID `db:"coupon_id" json:"coupon_id"`
 
 	Email   string `db:"email" json:"email"`
 	Country string `db:"country" json:"country"`
 	// customer-provided additional information to show on the invoice e.g. company name, address, tax ID...
 	AdditionalInvoiceInformation string `db:"additional_invoice_information" json:"additional_invoice_information"`
 
+	// IDs of the entities of the payments.Provider. The fields are prefixed with Stripe for
+	// historical reasons.
 	StripeCheckoutSessionID string  `db:"stripe_checkout_session_id" json:"stripe_checkout_session_id"`
 	StripPaymentItentID     *string `db:"stripe_payment_intent_id" json:"stripe_payment_intent_id"`
 	StripeInvoiceID         *string `db:"stripe_invoice_id" json:"stripe_invoice_id"`
 	StripeInvoiceUrl        *string `db:"stripe_invoice_url" json:"stripe_invoice_url"`
 
+	// RecoveryEmailSentAt is set when the abandoned checkout recovery email has been sent for this order
+	RecoveryEmailSentAt *time.Time `db:"recovery_email_sent_at" json:"recovery_email_sent_at"`
+
+	// Gifts: when GiftRecipientEmail is set, the products are given to the recipient instead of the
+	// buyer. GiftRecipientContactID is set when the order is completed.
+	GiftRecipientEmail     *string    `db:"gift_recipient_email" json:"gift_recipient_email"`
+	GiftMessage            string     `db:"gift_message" json:"gift_message"`
+	GiftRecipientContactID *guid.GUID `db:"gift_recipient_contact_id" json:"gift_recipient_contact_id"`
+
 	WebsiteID guid.GUID `db:"website_id" json:"-"`
 	ContactID guid.GUID `db:"contact_id" json:"contact_id"`
 
@@ -301,15 +625,142 @@ type OrderLineItem struct {
 	ProductID            guid.GUID `db:"product_id" json:"product_id"`
 }
 
+// AbandonedCheckoutSettings configures the recovery email sent to the customers who placed an
+// order but never completed the payment.
+type AbandonedCheckoutSettings struct {
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	Enabled      bool   `db:"enabled" json:"enabled"`
+	DelayHours   int64  `db:"delay_hours" json:"delay_hours"`
+	Subject      string `db:"subject" json:"subject"`
+	BodyMarkdown string `db:"body_markdown" json:"body_markdown"`
+
+	WebsiteID guid.GUID `db:"website_id" json:"-"`
+}
+
+type InvoiceType string
+
+const (
+	InvoiceTypeInvoice InvoiceType = "invoice"
+	// InvoiceTypeCreditNote is issued for a succeeded refund, and cancels all or part of an invoice
+	InvoiceTypeCreditNote InvoiceType = "credit_note"
+)
+
+type InvoiceFormat string
+
+const (
+	InvoiceFormatPdf  InvoiceFormat = "pdf"
+	InvoiceFormatHtml InvoiceFormat = "html"
+)
+
+var InvoiceFormats = []InvoiceFormat{InvoiceFormatPdf, InvoiceFormatHtml}
+
+type AccountingExportFormat string
+
+const (
+	AccountingExportFormatCsv  AccountingExportFormat = "csv"
+	AccountingExportFormatJson AccountingExportFormat = "json"
+)
+
+// InvoicingSettings are the details of the seller printed on the invoices and credit notes generated
+// by the store, and the counters used to number them sequentially.
+type InvoicingSettings struct {
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	Enabled         bool   `db:"enabled" json:"enabled"`
+	SellerName      string `db:"seller_name" json:"seller_name"`
+	SellerAddress   string `db:"seller_address" json:"seller_address"`
+	SellerVatNumber string `db:"seller_vat_number" json:"seller_vat_number"`
+	// VatRate is in basis points (e.g. 2000 = 20%). Prices are VAT included.
+	VatRate int64 `db:"vat_rate" json:"vat_rate"`
+	// Notes are printed at the bottom of the invoices (e.g. legal mentions)
+	Notes                  string `db:"notes" json:"notes"`
+	InvoiceNumberPrefix    string `db:"invoice_number_prefix" json:"invoice_number_prefix"`
+	CreditNoteNumberPrefix string `db:"credit_note_number_prefix" json:"credit_note_number_prefix"`
+	NextInvoiceNumber      int64  `db:"next_invoice_number" json:"next_invoice_number"`
+	NextCreditNoteNumber   int64  `db:"next_credit_note_number" json:"next_credit_note_number"`
+
+	WebsiteID guid.GUID `db:"website_id" json:"-"`
+}
+
+// Invoice is an invoice or a credit note generated by the store. The details of the seller and of the
+// buyer are copied when the invoice is issued so that it never changes afterwards.
+// Amounts are in cents and always positive, including for credit notes.
+type Invoice struct {
+	ID        guid.GUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	Type InvoiceType `db:"type" json:"type"`
+	// Sequence is the position of the invoice in the sequence of the invoices (or credit notes) of the website
+	Sequence    int64             `db:"sequence" json:"sequence"`
+	Number      string            `db:"number" json:"number"`
+	Currency    websites.Currency `db:"currency" json:"currency"`
+	LineItems   InvoiceLineItems  `db:"line_items" json:"line_items"`
+	VatRate     int64             `db:"vat_rate" json:"vat_rate"`
+	NetAmount   int64             `db:"net_amount" json:"net_amount"`
+	VatAmount   int64             `db:"vat_amount" json:"vat_amount"`
+	TotalAmount int64             `db:"total_amount" json:"total_amount"`
+	// CreditedInvoiceNumber is the number of the invoice canceled by a credit note
+	CreditedInvoiceNumber string `db:"credited_invoice_number" json:"credited_invoice_number"`
+
+	SellerName                   string `db:"seller_name" json:"seller_name"`
+	SellerAddress                string `db:"seller_address" json:"seller_address"`
+	SellerVatNumber              string `db:"seller_vat_number" json:"seller_vat_number"`
+	BuyerEmail                   string `db:"buyer_email" json:"buyer_email"`
+	BuyerCountry                 string `db:"buyer_country" json:"buyer_country"`
+	AdditionalInvoiceInformation string `db:"additional_invoice_information" json:"additional_invoice_information"`
+	Notes                        string `db:"notes" json:"notes"`
+
+	PdfSize  int64 `db:"pdf_size" json:"-"`
+	HtmlSize int64 `db:"html_size" json:"-"`
+
+	WebsiteID guid.GUID  `db:"website_id" json:"-"`
+	OrderID   guid.GUID  `db:"order_id" json:"order_id"`
+	RefundID  *guid.GUID `db:"refund_id" json:"refund_id"`
+}
+
+type InvoiceLineItem struct {
+	Description string `json:"description"`
+	Quantity    int64  `json:"quantity"`
+	UnitAmount  int64  `json:"unit_amount"`
+	Amount      int64  `json:"amount"`
+}
+
+type InvoiceLineItems []InvoiceLineItem
+
+func (lineItems *InvoiceLineItems) Scan(val any) error {
+	switch v := val.(type) {
+	case []byte:
+		return json.Unmarshal(v, lineItems)
+	case string:
+		return json.Unmarshal([]byte(v), lineItems)
+	default:
+		return fmt.Errorf("InvoiceLineItems.Scan: Unsupported type: %T", v)
+	}
+}
+
+func (lineItems InvoiceLineItems) Value() (driver.Value, error) {
+	if lineItems == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(lineItems)
+}
+
 type Coupon struct {
 	ID        guid.GUID `db:"id" json:"id"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 
-	Code        string     `db:"code" json:"code"`
-	ExpiresAt   *time.Time `db:"expires_at" json:"expires_at"`
-	Discount    int64      `db:"discount" json:"discount"`
-	UsesLimit   int64      `db:"uses_limit" json:"uses_limit"`
+	Code      string     `db:"code" json:"code"`
+	ExpiresAt *time.Time `db:"expires_at" json:"expires_at"`
+	Discount  int64      `db:"discount" json:"discount"`
+	// UsesLimit is the maximum number of orders the coupon can be applied to. 0 means unlimited.
+	UsesLimit int64 `db:"uses_limit" json:"uses_limit"`
+	// Uses is the number of placed orders using the coupon. Canceled orders release their use.
+	Uses        int64      `db:"uses" json:"uses"`
 	ArchivedAt  *time.Time `db:"archived_at" json:"-"`
 	Description string     `db:"description" json:"description"`
 
@@ -390,6 +841,13 @@ type CreateProductInput struct {
 	Description string      `json:"description"`
 	Type        ProductType `json:"type"`
 	Price       int64       `json:"price"`
+	// required for memberships
+	BillingInterval *BillingInterval `json:"billing_interval"`
+	// required for bundles
+	BundleItems []guid.GUID `json:"bundle_items"`
+	// prices in other currencies than the currency of the website
+	Prices         ProductPrices `json:"prices"`
+	PayWhatYouWant bool          `json:"pay_what_you_want"`
 }
 
 type GetProductInput struct {
@@ -406,6 +864,18 @@ type UpdateProductInput struct {
 	Description *string        `json:"description"`
 	Status      *ProductStatus `json:"status"`
 	Price       *int64         `json:"price"`
+	// only for books
+	EbookWatermark *bool `json:"ebook_watermark"`
+	// only for memberships. Existing members keep their current billing interval.
+	BillingInterval *BillingInterval `json:"billing_interval"`
+	// only for bundles. Customers who already bought the bundle don't get access to new items.
+	BundleItems []guid.GUID `json:"bundle_items"`
+	// replaces all the prices in other currencies than the currency of the website
+	Prices         ProductPrices `json:"prices"`
+	PayWhatYouWant *bool         `json:"pay_what_you_want"`
+	// only for digital downloads. Keys already issued are not affected.
+	LicenseKeys                *LicenseKeysMode `json:"license_keys"`
+	LicenseKeyActivationsLimit *int64           `json:"license_key_activations_limit"`
 }
 
 type CreateCouponInput struct {
@@ -413,6 +883,7 @@ type CreateCouponInput struct {
 	Code        string      `json:"code"`
 	ExpiresAt   *time.Time  `json:"expires_at"`
 	Discount    int64       `json:"discount"`
+	UsesLimit   int64       `json:"uses_limit"`
 	Description string      `json:"description"`
 	Products    []guid.GUID `json:"products"`
 }
@@ -430,6 +901,7 @@ type UpdateCouponInput struct {
 	Code        *string     `json:"code"`
 	ExpiresAt   *time.Time  `json:"expires_at"`
 	Discount    *int64      `json:"discount"`
+	UsesLimit   *int64      `json:"uses_limit"`
 	Description *string     `json:"description"`
 	Archived    *bool       `json:"archived"`
 	Products    []guid.GUID `json:"products"`
@@ -439,12 +911,14 @@ type CreateProductPageInput struct {
 	ProductID    guid.GUID `json:"product_id"`
 	Title        string    `json:"title"`
 	BodyMarkdown string    `json:"body_markdown"`
+	DripDays     int64     `json:"drip_days"`
 }
 
 type UpdateProductPageInput struct {
 	ID           guid.GUID `json:"id"`
 	Title        *string   `json:"title"`
 	BodyMarkdown *string   `json:"body_markdown"`
+	DripDays     *int64    `json:"drip_days"`
 }
 
 type DeleteProductPageInput struct {
@@ -456,6 +930,18 @@ type ReorderProductPagesInput struct {
 	Pages     []guid.GUID
 }
 
+type GetProductEbookInput struct {
+	ProductID guid.GUID          `json:"product_id"`
+	Format    ProductEbookFormat `json:"format"`
+}
+
+type GetProductEbookOutput struct {
+	Filename  string
+	MediaType string
+	Size      int64
+	Data      io.ReadCloser
+}
+
 type GetBookChapterInput struct {
 	ID guid.GUID `json:"id"`
 }
@@ -464,6 +950,16 @@ type GetProductPageInput struct {
 	ID guid.GUID `json:"id"`
 }
 
+type GetCourseAnalyticsInput struct {
+	ProductID guid.GUID `json:"product_id"`
+}
+
+type UpdateLessonProgressInput struct {
+	ContactID     guid.GUID
+	ProductPageID guid.GUID
+	Completed     bool
+}
+
 type DeleteBookVersionInput struct {
 	ID guid.GUID `json:"id"`
 }
@@ -478,6 +974,14 @@ type RemoveAccessToProductInput struct {
 	ProductID guid.GUID `json:"product_id"`
 }
 
+// TransferProductAccessInput moves the access to a product (and to the products included if it's a
+// bundle) from a contact to another one, creating the recipient contact if needed.
+type TransferProductAccessInput struct {
+	ProductID guid.GUID `json:"product_id"`
+	FromEmail string    `json:"from_email"`
+	ToEmail   string    `json:"to_email"`
+}
+
 type ListOrdersInput struct {
 	WebsiteID guid.GUID  `json:"website_id"`
 	Query     string     `json:"query"`
@@ -490,12 +994,48 @@ type PlaceOrderInput struct {
 	Email                        *string     `json:"email"`
 	SubscribeToNewsletter        bool        `json:"subscribe_to_newsletter"`
 	AdditionalInvoiceInformation *string     `json:"additional_invoice_information"`
+	// Code of the coupon to apply to the order
+	Coupon *string `json:"coupon"`
+	// Amounts chosen by the buyer for pay-what-you-want products, in the currency of the order.
+	// Products without a chosen amount are sold at their minimum price.
+	CustomPrices map[guid.GUID]int64 `json:"custom_prices"`
+	// Email of the recipient when the order is a gift
+	GiftRecipientEmail *string `json:"gift_recipient_email"`
+	GiftMessage        *string `json:"gift_message"`
 }
 
 type PlaceOrderOutput struct {
 	StripeCheckoutUrl string `json:"stripe_checkout_url"`
 }
 
+type PreviewOrderInput struct {
+	Products     []guid.GUID         `json:"products"`
+	Coupon       *string             `json:"coupon"`
+	CustomPrices map[guid.GUID]int64 `json:"custom_prices"`
+}
+
+// PreviewOrderOutput is the price of an order before it is placed
+type PreviewOrderOutput struct {
+	Currency       websites.Currency `json:"currency"`
+	SubtotalAmount int64             `json:"subtotal_amount"`
+	DiscountAmount int64             `json:"discount_amount"`
+	TotalAmount    int64             `json:"total_amount"`
+	// Coupon is the applied coupon code, if any
+	Coupon    *string           `json:"coupon"`
+	LineItems []PreviewLineItem `json:"line_items"`
+}
+
+type PreviewLineItem struct {
+	ProductID guid.GUID `json:"product_id"`
+	Name      string    `json:"name"`
+	// Price is the unit price of the product, not including any discounts.
+	Price          int64 `json:"price"`
+	DiscountAmount int64 `json:"discount_amount"`
+	PayWhatYouWant bool  `json:"pay_what_you_want"`
+	// MinimumPrice is the minimum unit price for pay-what-you-want products
+	MinimumPrice int64 `json:"minimum_price"`
+}
+
 type CompleteOrderInput struct {
 	OrderID guid.GUID `json:"order_id"`
 }
@@ -504,6 +1044,79 @@ type CancelOrderInput struct {
 	OrderID guid.GUID `json:"order_id"`
 }
 
+type ResumeOrderInput struct {
+	OrderID guid.GUID `json:"order_id"`
+	// Token is the token of the link of the recovery email. It's not required if the customer is authenticated
+	Token *string `json:"token"`
+}
+
+type ResumeOrderOutput struct {
+	// CheckoutUrl is either the URL of the checkout session of the order if it's still open, or the
+	// path of the checkout page of the website with the products of the order otherwise.
+	CheckoutUrl string `json:"checkout_url"`
+}
+
+type GetAbandonedCheckoutSettingsInput struct {
+	WebsiteID guid.GUID `json:"website_id"`
+}
+
+type UpdateAbandonedCheckoutSettingsInput struct {
+	WebsiteID    guid.GUID `json:"website_id"`
+	Enabled      *bool     `json:"enabled"`
+	DelayHours   *int64    `json:"delay_hours"`
+	Subject      *string   `json:"subject"`
+	BodyMarkdown *string   `json:"body_markdown"`
+}
+
+type GetInvoicingSettingsInput struct {
+	WebsiteID guid.GUID `json:"website_id"`
+}
+
+type UpdateInvoicingSettingsInput struct {
+	WebsiteID              guid.GUID `json:"website_id"`
+	Enabled                *bool     `json:"enabled"`
+	SellerName             *string   `json:"seller_name"`
+	SellerAddress          *string   `json:"seller_address"`
+	SellerVatNumber        *string   `json:"seller_vat_number"`
+	VatRate                *int64    `json:"vat_rate"`
+	Notes                  *string   `json:"notes"`
+	InvoiceNumberPrefix    *string   `json:"invoice_number_prefix"`
+	CreditNoteNumberPrefix *string   `json:"credit_note_number_prefix"`
+}
+
+type ListInvoicesInput struct {
+	WebsiteID guid.GUID `json:"website_id"`
+	// OrderID optionally restricts the list to the invoice and credit notes of an order
+	OrderID *guid.GUID `json:"order_id"`
+}
+
+type GetInvoiceFileInput struct {
+	ID     guid.GUID     `json:"id"`
+	Format InvoiceFormat `json:"format"`
+}
+
+type GetInvoiceFileOutput struct {
+	Filename  string
+	MediaType string
+	Size      int64
+	Data      io.ReadCloser
+}
+
+type ExportAccountingInput struct {
+	WebsiteID guid.GUID              `json:"website_id"`
+	From      time.Time              `json:"from"`
+	To        time.Time              `json:"to"`
+	Format    AccountingExportFormat `json:"format"`
+}
+
+// ExportAccountingOutput contains the completed orders, the refunds and the invoices (including credit
+// notes) of the period, each one encoded in the requested format.
+type ExportAccountingOutput struct {
+	Orders   string `json:"orders"`
+	Refunds  string `json:"refunds"`
+	Invoices string `json:"invoices"`
+}
+
 type GetOrderInput struct {
 	ID guid.GUID `json:"id"`
 }
@@ -522,3 +1135,59 @@ type CreateRefundInput struct {
 	Notes   string       `json:"notes"`
 	Amount  int64        `json:"amount"`
 }
+
+type ListMembershipsInput struct {
+	WebsiteID guid.GUID `json:"website_id"`
+}
+
+type GrantMembershipInput struct {
+	ContactID guid.GUID `json:"contact_id"`
+	ProductID guid.GUID `json:"product_id"`
+}
+
+type CancelMembershipInput struct {
+	ID guid.GUID `json:"id"`
+}
+
+type ListLicenseKeysInput struct {
+	ProductID guid.GUID `json:"product_id"`
+}
+
+type AddLicenseKeysToPoolInput struct {
+	ProductID guid.GUID `json:"product_id"`
+	Keys      []string  `json:"keys"`
+}
+
+type RevokeLicenseKeyInput struct {
+	ID guid.GUID `json:"id"`
+}
+
+type VerifyLicenseKeyInput struct {
+	ProductID guid.GUID `json:"product_id"`
+	Key       string    `json:"key"`
+	// optional: if provided, Activated indicates whether this instance is activated
+	Instance *string `json:"instance"`
+}
+
+type ActivateLicenseKeyInput struct {
+	ProductID guid.GUID `json:"product_id"`
+	Key       string    `json:"key"`
+	Instance  string    `json:"instance"`
+}
+
+type DeactivateLicenseKeyInput struct {
+	ProductID guid.GUID `json:"product_id"`
+	Key       string    `json:"key"`
+	Instance  string    `json:"instance"`
+}
+
+// LicenseKeyVerification is returned by the public license keys API
+type LicenseKeyVerification struct {
+	Valid  bool             `json:"valid"`
+	Status LicenseKeyStatus `json:"status"`
+	// Activated is true if the instance of the request is activated
+	Activated   bool  `json:"activated"`
+	Activations int64 `json:"activations"`
+	// 0 means unlimited
+	ActivationsLimit int64 `json:"activations_limit"`
+}
@@ -0,0 +1,30 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProductPageIsLocked(t *testing.T) {
+	accessGrantedAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		dripDays int64
+		now      time.Time
+		expected bool
+	}{
+		{0, accessGrantedAt, false},
+		{7, accessGrantedAt, true},
+		{7, accessGrantedAt.AddDate(0, 0, 6), true},
+		{7, accessGrantedAt.AddDate(0, 0, 7), false},
+		{7, accessGrantedAt.AddDate(1, 0, 0), false},
+	}
+
+	for _, test := range tests {
+		page := ProductPage{DripDays: test.dripDays}
+		locked := page.IsLocked(accessGrantedAt, test.now)
+		if locked != test.expected {
+			t.Errorf("drip_days: %d, now: %s: expected locked = %t, got %t", test.dripDays, test.now, test.expected, locked)
+		}
+	}
+}
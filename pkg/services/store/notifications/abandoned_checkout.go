@@ -0,0 +1,35 @@
+package notifications
+
+import (
+	_ "embed"
+	"html/template"
+)
+
+type AbandonedCheckoutEmailData struct {
+	// Content is the HTML of the email customized by the website
+	Content         template.HTML
+	Products        []string
+	ResumeURL       template.URL
+	UnsubscribeLink template.URL
+}
+
+//go:embed abandoned_checkout.html
+var AbandonedCheckoutEmailTemplate string
+
+// <mjml>
+//   <mj-body>
+//     <mj-section>
+//       <mj-column>
+//         <mj-text font-size="18px" color="#424242" font-family="helvetica" line-height="1.5">{{ .Content }}</mj-text>
+//         <mj-text font-size="18px" color="#424242" font-family="helvetica" line-height="1.5">
+//           {{ range .Products }}
+//           {{ . }}<br />
+//           {{ end }}
+//         </mj-text>
+//         <mj-button background-color="#424242" font-size="18px" font-family="helvetica" href="{{ .ResumeURL }}">Complete my order</mj-button>
+//         <mj-divider border-color="#dddddd" border-width="1px" padding-top="30px"></mj-divider>
+//         <mj-text align="center" font-size="14px" color="#757575" font-family="helvetica"><a href="{{ .UnsubscribeLink }}" style="color: #757575;">Unsubscribe</a></mj-text>
+//       </mj-column>
+//     </mj-section>
+//   </mj-body>
+// </mjml>
@@ -0,0 +1,41 @@
+package notifications
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestAbandonedCheckoutEmailTemplate(t *testing.T) {
+	tmpl, err := template.New("AbandonedCheckoutEmailTemplate").Parse(AbandonedCheckoutEmailTemplate)
+	if err != nil {
+		t.Fatalf("parsing template: %v", err)
+	}
+
+	var output bytes.Buffer
+	data := AbandonedCheckoutEmailData{
+		Content:         template.HTML("<p>Your cart is waiting</p>"),
+		Products:        []string{"<b>Course</b>"},
+		ResumeURL:       template.URL("https://example.com/checkout/123/resume"),
+		UnsubscribeLink: template.URL("https://example.com/unsubscribe?token=abc"),
+	}
+	err = tmpl.Execute(&output, data)
+	if err != nil {
+		t.Fatalf("executing template: %v", err)
+	}
+
+	html := output.String()
+	if !strings.Contains(html, "<p>Your cart is waiting</p>") {
+		t.Error("content is missing or escaped")
+	}
+	if !strings.Contains(html, "&lt;b&gt;Course&lt;/b&gt;") {
+		t.Error("product names are not escaped")
+	}
+	if !strings.Contains(html, `href="https://example.com/checkout/123/resume"`) {
+		t.Error("resume link is missing")
+	}
+	if !strings.Contains(html, `href="https://example.com/unsubscribe?token=abc"`) {
+		t.Error("unsubscribe link is missing")
+	}
+}
@@ -0,0 +1,51 @@
+package notifications
+
+import (
+	_ "embed"
+	"html/template"
+)
+
+type GiftNotificationEmailData struct {
+	// From is the name (or the email address) of the buyer of the gift
+	From        string
+	Message     string
+	Products    []string
+	AccountURL  template.URL
+	LicenseKeys []GiftNotificationEmailLicenseKey
+}
+
+type GiftNotificationEmailLicenseKey struct {
+	ProductName string
+	Key         string
+}
+
+//go:embed gift_notification.html
+var GiftNotificationEmailTemplate string
+
+// <mjml>
+//   <mj-body>
+//     <mj-section>
+//       <mj-column>
+//         <mj-text align="center" font-size="22px" color="#424242" font-family="helvetica" font-weight="700">{{ .From }} sent you a gift</mj-text>
+//         <mj-divider border-color="#dddddd" border-width="2px"></mj-divider>
+//         {{ if .Message }}
+//         <mj-text font-size="20px" color="#424242" font-family="helvetica" align="center" padding-top="30px" line-height="1.5" font-style="italic">{{ .Message }}</mj-text>
+//         {{ end }}
+//         <mj-text font-size="20px" color="#424242" font-family="helvetica" align="center" padding-top="30px" line-height="1.5">You now have access to:<br />
+//           {{ range .Products }}
+//           {{ . }}<br />
+//           {{ end }}
+//         </mj-text>
+//         <mj-text font-size="20px" color="#424242" font-family="helvetica" align="center" padding-top="30px">Log in to your account with this email address to access your products: <br />
+//           <a href="{{ .AccountURL }}">{{ .AccountURL }}</a> </mj-text>
+//         {{ if .LicenseKeys }}
+//         <mj-text font-size="20px" color="#424242" font-family="helvetica" align="center" padding-top="30px" line-height="1.5">Your license keys:<br />
+//           {{ range .LicenseKeys }}
+//           {{ .ProductName }}: <code>{{ .Key }}</code><br />
+//           {{ end }}
+//         </mj-text>
+//         {{ end }}
+//       </mj-column>
+//     </mj-section>
+//   </mj-body>
+// </mjml>
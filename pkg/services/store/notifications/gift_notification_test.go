@@ -0,0 +1,44 @@
+package notifications
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestGiftNotificationEmailTemplate(t *testing.T) {
+	tmpl, err := template.New("GiftNotificationEmailTemplate").Parse(GiftNotificationEmailTemplate)
+	if err != nil {
+		t.Fatalf("parsing template: %v", err)
+	}
+
+	var output bytes.Buffer
+	data := GiftNotificationEmailData{
+		From:       "Alice",
+		Message:    "<b>Happy birthday!</b>",
+		Products:   []string{"Course"},
+		AccountURL: template.URL("https://example.com/account"),
+		LicenseKeys: []GiftNotificationEmailLicenseKey{
+			{ProductName: "Software", Key: "ABCD-1234"},
+		},
+	}
+	err = tmpl.Execute(&output, data)
+	if err != nil {
+		t.Fatalf("executing template: %v", err)
+	}
+
+	html := output.String()
+	if !strings.Contains(html, "Alice sent you a gift") {
+		t.Error("sender is missing")
+	}
+	if !strings.Contains(html, "&lt;b&gt;Happy birthday!&lt;/b&gt;") {
+		t.Error("message is missing or not escaped")
+	}
+	if !strings.Contains(html, `href="https://example.com/account"`) {
+		t.Error("account link is missing")
+	}
+	if !strings.Contains(html, "ABCD-1234") {
+		t.Error("license key is missing")
+	}
+}
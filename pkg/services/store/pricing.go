@@ -0,0 +1,59 @@
+package store
+
+import (
+	"markdown.ninja/pkg/services/websites"
+)
+
+// ProductPrice is the price of a product for a visitor
+type ProductPrice struct {
+	// Amount is the minimum amount for pay-what-you-want products
+	Amount         int64             `json:"amount"`
+	Currency       websites.Currency `json:"currency"`
+	PayWhatYouWant bool              `json:"pay_what_you_want"`
+}
+
+// PriceIn returns the price of the product in the given currency, and false if the product has no
+// explicit price in this currency.
+func (product *Product) PriceIn(websiteCurrency, currency websites.Currency) (price int64, ok bool) {
+	if currency == websiteCurrency {
+		return product.Price, true
+	}
+
+	price, ok = product.Prices[currency]
+	return
+}
+
+// ResolveCurrency returns the currency in which the products are sold to a visitor from the given
+// country:
+//  1. the currency preferred in the visitor's country (see websites.CurrenciesByCountry), if all
+//     the products have an explicit price in this currency,
+//  2. otherwise the currency of the website.
+//
+// Prices are never converted between currencies.
+func ResolveCurrency(websiteCurrency websites.Currency, countryCode string, products []Product) websites.Currency {
+	countryCurrency, ok := websites.CurrenciesByCountry[countryCode]
+	if !ok || countryCurrency == websiteCurrency {
+		return websiteCurrency
+	}
+
+	for _, product := range products {
+		if _, hasPrice := product.PriceIn(websiteCurrency, countryCurrency); !hasPrice {
+			return websiteCurrency
+		}
+	}
+
+	return countryCurrency
+}
+
+// ResolveProductPrice returns the price of the product for a visitor from the given country.
+// See ResolveCurrency for how the currency is chosen.
+func ResolveProductPrice(websiteCurrency websites.Currency, countryCode string, product Product) ProductPrice {
+	currency := ResolveCurrency(websiteCurrency, countryCode, []Product{product})
+	amount, _ := product.PriceIn(websiteCurrency, currency)
+
+	return ProductPrice{
+		Amount:         amount,
+		Currency:       currency,
+		PayWhatYouWant: product.PayWhatYouWant,
+	}
+}
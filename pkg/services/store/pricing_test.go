@@ -0,0 +1,43 @@
+package store
+
+import (
+	"testing"
+
+	"markdown.ninja/pkg/services/websites"
+)
+
+func TestResolveCurrency(t *testing.T) {
+	book := Product{Name: "Book", Price: 25, Prices: ProductPrices{websites.CurrencyUSD: 29}}
+	course := Product{Name: "Course", Price: 99}
+
+	tests := []struct {
+		countryCode string
+		products    []Product
+		expected    websites.Currency
+	}{
+		{"US", []Product{book}, websites.CurrencyUSD},
+		// the course has no explicit USD price: the whole order is in the currency of the website
+		{"US", []Product{book, course}, websites.CurrencyEUR},
+		{"FR", []Product{book}, websites.CurrencyEUR},
+		{"CH", []Product{book}, websites.CurrencyEUR},
+		{"", []Product{book}, websites.CurrencyEUR},
+	}
+
+	for _, test := range tests {
+		currency := ResolveCurrency(websites.CurrencyEUR, test.countryCode, test.products)
+		if currency != test.expected {
+			t.Errorf("country %q: expected %s, got %s", test.countryCode, test.expected, currency)
+		}
+	}
+
+	price := ResolveProductPrice(websites.CurrencyEUR, "US", book)
+	if price.Amount != 29 || price.Currency != websites.CurrencyUSD {
+		t.Errorf("book price in the US: got %d %s", price.Amount, price.Currency)
+	}
+
+	// prices are never converted
+	price = ResolveProductPrice(websites.CurrencyEUR, "US", course)
+	if price.Amount != 99 || price.Currency != websites.CurrencyEUR {
+		t.Errorf("course price in the US: got %d %s", price.Amount, price.Currency)
+	}
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (repo *StoreRepository) UpsertAbandonedCheckoutSettings(ctx context.Context, db db.Queryer, settings store.AbandonedCheckoutSettings) (err error) {
+	const query = `INSERT INTO abandoned_checkouts_settings
+			(created_at, updated_at, enabled, delay_hours, subject, body_markdown, website_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (website_id) DO UPDATE
+			SET updated_at = $2, enabled = $3, delay_hours = $4, subject = $5, body_markdown = $6`
+
+	_, err = db.Exec(ctx, query, settings.CreatedAt, settings.UpdatedAt, settings.Enabled, settings.DelayHours,
+		settings.Subject, settings.BodyMarkdown, settings.WebsiteID)
+	if err != nil {
+		err = fmt.Errorf("store.UpsertAbandonedCheckoutSettings: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindAbandonedCheckoutSettings(ctx context.Context, db db.Queryer, websiteID guid.GUID) (settings store.AbandonedCheckoutSettings, err error) {
+	const query = "SELECT * FROM abandoned_checkouts_settings WHERE website_id = $1"
+
+	err = db.Get(ctx, &settings, query, websiteID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.ErrAbandonedCheckoutSettingsNotFound
+		} else {
+			err = fmt.Errorf("store.FindAbandonedCheckoutSettings: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+// FindAbandonedOrders returns the pending orders of the websites with abandoned checkout recovery
+// enabled that are older than the delay configured by the website and for which no recovery email
+// has been sent yet.
+// Orders of contacts who are not subscribed to the newsletter, who are blocked or who completed
+// another order since are ignored.
+func (repo *StoreRepository) FindAbandonedOrders(ctx context.Context, db db.Queryer, now time.Time, limit int64) (orders []store.Order, err error) {
+	orders = make([]store.Order, 0)
+	const query = `SELECT orders.* FROM orders
+			INNER JOIN abandoned_checkouts_settings
+				ON abandoned_checkouts_settings.website_id = orders.website_id
+			INNER JOIN contacts ON contacts.id = orders.contact_id
+		WHERE orders.status = $1 AND orders.recovery_email_sent_at IS NULL
+			AND abandoned_checkouts_settings.enabled = true
+			AND orders.created_at <= $2::TIMESTAMPTZ - make_interval(hours => abandoned_checkouts_settings.delay_hours::INT)
+			AND orders.created_at >= $3
+			AND contacts.subscribed_to_newsletter_at IS NOT NULL AND contacts.blocked_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM orders AS completed_orders
+				WHERE completed_orders.contact_id = orders.contact_id AND completed_orders.status = $4
+					AND completed_orders.created_at > orders.created_at
+			)
+		ORDER BY orders.created_at
+		LIMIT $5
+	`
+
+	err = db.Select(ctx, &orders, query, store.OrderStatusPending, now, now.Add(-store.AbandonedCheckoutMaxAge),
+		store.OrderStatusCompleted, limit)
+	if err != nil {
+		err = fmt.Errorf("store.FindAbandonedOrders: %w", err)
+		return
+	}
+
+	return
+}
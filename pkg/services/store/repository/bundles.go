@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (repo *StoreRepository) CreateBundleProductRelation(ctx context.Context, db db.Queryer, relation store.BundleProductRelation) (err error) {
+	const query = `INSERT INTO bundles_products
+				(bundle_id, product_id)
+			VALUES ($1, $2)`
+
+	_, err = db.Exec(ctx, query, relation.BundleID, relation.ProductID)
+	if err != nil {
+		err = fmt.Errorf("store.CreateBundleProductRelation: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) DeleteBundleProductRelationsForBundle(ctx context.Context, db db.Queryer, bundleID guid.GUID) (err error) {
+	const query = `DELETE FROM bundles_products WHERE bundle_id = $1`
+
+	_, err = db.Exec(ctx, query, bundleID)
+	if err != nil {
+		err = fmt.Errorf("store.DeleteBundleProductRelationsForBundle: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindProductsInBundle(ctx context.Context, db db.Queryer, bundleID guid.GUID) (ret []store.Product, err error) {
+	ret = make([]store.Product, 0)
+	const query = `SELECT * FROM products WHERE id = ANY (
+		SELECT product_id FROM bundles_products WHERE bundle_id = $1
+	)
+	ORDER BY name`
+
+	err = db.Select(ctx, &ret, query, bundleID)
+	if err != nil {
+		err = fmt.Errorf("store.FindProductsInBundle: %w", err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+// CreateProductPageCompletion does nothing if the contact already completed the page
+func (repo *StoreRepository) CreateProductPageCompletion(ctx context.Context, db db.Queryer, completion store.ProductPageCompletion) (err error) {
+	const query = `INSERT INTO product_pages_completions
+			(completed_at, contact_id, product_page_id, product_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (contact_id, product_page_id) DO NOTHING`
+
+	_, err = db.Exec(ctx, query, completion.CompletedAt, completion.ContactID, completion.ProductPageID,
+		completion.ProductID)
+	if err != nil {
+		err = fmt.Errorf("store.CreateProductPageCompletion: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) DeleteProductPageCompletion(ctx context.Context, db db.Queryer, contactID, productPageID guid.GUID) (err error) {
+	const query = `DELETE FROM product_pages_completions WHERE contact_id = $1 AND product_page_id = $2`
+
+	_, err = db.Exec(ctx, query, contactID, productPageID)
+	if err != nil {
+		err = fmt.Errorf("store.DeleteProductPageCompletion: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindProductPageCompletionsForContact(ctx context.Context, db db.Queryer, contactID, productID guid.GUID) (completions []store.ProductPageCompletion, err error) {
+	completions = make([]store.ProductPageCompletion, 0)
+	const query = `SELECT * FROM product_pages_completions
+		WHERE contact_id = $1 AND product_id = $2
+	`
+
+	err = db.Select(ctx, &completions, query, contactID, productID)
+	if err != nil {
+		err = fmt.Errorf("store.FindProductPageCompletionsForContact: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) GetContactsWithAccessToProductCount(ctx context.Context, db db.Queryer, productID guid.GUID) (count int64, err error) {
+	const query = "SELECT COUNT(*) FROM contact_product_access WHERE product_id = $1"
+
+	err = db.Get(ctx, &count, query, productID)
+	if err != nil {
+		err = fmt.Errorf("store.GetContactsWithAccessToProductCount: %w", err)
+		return
+	}
+
+	return
+}
+
+// GetLessonsAnalyticsForProduct returns the number of completions of each page of the product, ordered
+// by position. Only the completions of contacts who still have access to the product are counted.
+func (repo *StoreRepository) GetLessonsAnalyticsForProduct(ctx context.Context, db db.Queryer, productID guid.GUID) (lessons []store.LessonAnalytics, err error) {
+	lessons = make([]store.LessonAnalytics, 0)
+	const query = `SELECT product_pages.id AS product_page_id, product_pages.position, product_pages.title,
+			product_pages.drip_days, COUNT(contact_product_access.contact_id) AS completions
+		FROM product_pages
+			LEFT JOIN product_pages_completions ON product_pages_completions.product_page_id = product_pages.id
+			LEFT JOIN contact_product_access ON contact_product_access.product_id = product_pages.product_id
+				AND contact_product_access.contact_id = product_pages_completions.contact_id
+		WHERE product_pages.product_id = $1
+		GROUP BY product_pages.id
+		ORDER BY product_pages.position
+	`
+
+	err = db.Select(ctx, &lessons, query, productID)
+	if err != nil {
+		err = fmt.Errorf("store.GetLessonsAnalyticsForProduct: %w", err)
+		return
+	}
+
+	return
+}
@@ -59,6 +59,22 @@ func (repo *StoreRepository) FindCouponByCode(ctx context.Context, db db.Queryer
 	return
 }
 
+func (repo *StoreRepository) FindCouponByCodeForWebsite(ctx context.Context, db db.Queryer, websiteID guid.GUID, code string) (coupon store.Coupon, err error) {
+	const query = "SELECT * FROM coupons WHERE website_id = $1 AND code = $2"
+
+	err = db.Get(ctx, &coupon, query, websiteID, code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.ErrCouponNotFound
+		} else {
+			err = fmt.Errorf("store.FindCouponByCodeForWebsite: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
 func (repo *StoreRepository) FindCouponsByWebsiteID(ctx context.Context, db db.Queryer, websiteID guid.GUID) (ret []store.Coupon, err error) {
 	ret = []store.Coupon{}
 	const query = `SELECT * FROM coupons
@@ -93,6 +109,41 @@ func (repo *StoreRepository) UpdateCoupon(ctx context.Context, db db.Queryer, co
 	return
 }
 
+// IncrementCouponUses atomically increments the uses of the coupon if its uses limit is not reached.
+// It returns false if the limit is reached.
+func (repo *StoreRepository) IncrementCouponUses(ctx context.Context, db db.Queryer, couponID guid.GUID) (incremented bool, err error) {
+	const query = `UPDATE coupons
+		SET uses = uses + 1
+		WHERE id = $1 AND (uses_limit = 0 OR uses < uses_limit)
+	`
+
+	res, err := db.Exec(ctx, query, couponID)
+	if err != nil {
+		err = fmt.Errorf("store.IncrementCouponUses: %w", err)
+		return
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("store.IncrementCouponUses: getting affected rows: %w", err)
+		return
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (repo *StoreRepository) DecrementCouponUses(ctx context.Context, db db.Queryer, couponID guid.GUID) (err error) {
+	const query = `UPDATE coupons SET uses = GREATEST(uses - 1, 0) WHERE id = $1`
+
+	_, err = db.Exec(ctx, query, couponID)
+	if err != nil {
+		err = fmt.Errorf("store.DecrementCouponUses: %w", err)
+		return
+	}
+
+	return
+}
+
 func (repo *StoreRepository) CreateCouponProductRelation(ctx context.Context, db db.Queryer, relation store.CouponProductRelation) (err error) {
 	const query = `INSERT INTO coupons_products
 				(coupon_id, product_id)
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (repo *StoreRepository) CreateProductEbook(ctx context.Context, db db.Queryer, ebook store.ProductEbook) (err error) {
+	const query = `INSERT INTO product_ebooks
+			(id, created_at, updated_at, format, content_hash, size, product_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err = db.Exec(ctx, query, ebook.ID, ebook.CreatedAt, ebook.UpdatedAt,
+		ebook.Format, ebook.ContentHash, ebook.Size,
+		ebook.ProductID)
+	if err != nil {
+		err = fmt.Errorf("store.CreateProductEbook: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) UpdateProductEbook(ctx context.Context, db db.Queryer, ebook store.ProductEbook) (err error) {
+	const query = `UPDATE product_ebooks
+		SET updated_at = $1, content_hash = $2, size = $3
+		WHERE id = $4
+`
+
+	_, err = db.Exec(ctx, query, ebook.UpdatedAt, ebook.ContentHash, ebook.Size,
+		ebook.ID)
+	if err != nil {
+		err = fmt.Errorf("store.UpdateProductEbook: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindProductEbook(ctx context.Context, db db.Queryer, productID guid.GUID, format store.ProductEbookFormat) (ebook store.ProductEbook, err error) {
+	const query = "SELECT * FROM product_ebooks WHERE product_id = $1 AND format = $2"
+
+	err = db.Get(ctx, &ebook, query, productID, format)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.ErrProductEbookNotFound
+		} else {
+			err = fmt.Errorf("store.FindProductEbook: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindProductEbooksForProduct(ctx context.Context, db db.Queryer, productID guid.GUID) (ret []store.ProductEbook, err error) {
+	ret = make([]store.ProductEbook, 0)
+	const query = `SELECT * FROM product_ebooks
+		WHERE product_id = $1
+		ORDER BY format
+	`
+
+	err = db.Select(ctx, &ret, query, productID)
+	if err != nil {
+		err = fmt.Errorf("store.FindProductEbooksForProduct: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindProductEbooksForProducts(ctx context.Context, db db.Queryer, productIDs []guid.GUID) (ret []store.ProductEbook, err error) {
+	ret = make([]store.ProductEbook, 0)
+	const query = `SELECT * FROM product_ebooks
+		WHERE product_id = ANY ($1)
+		ORDER BY format
+	`
+
+	if len(productIDs) == 0 {
+		return
+	}
+
+	err = db.Select(ctx, &ret, query, productIDs)
+	if err != nil {
+		err = fmt.Errorf("store.FindProductEbooksForProducts: %w", err)
+		return
+	}
+
+	return
+}
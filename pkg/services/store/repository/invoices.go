@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+// UpsertInvoicingSettings creates or updates the invoicing settings of a website. The counters used to
+// number the invoices are only set on creation: use IncrementInvoiceNumber to update them.
+func (repo *StoreRepository) UpsertInvoicingSettings(ctx context.Context, db db.Queryer, settings store.InvoicingSettings) (err error) {
+	const query = `INSERT INTO invoicing_settings
+			(created_at, updated_at, enabled, seller_name, seller_address, seller_vat_number, vat_rate, notes,
+				invoice_number_prefix, credit_note_number_prefix, next_invoice_number, next_credit_note_number,
+				website_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (website_id) DO UPDATE
+			SET updated_at = $2, enabled = $3, seller_name = $4, seller_address = $5, seller_vat_number = $6,
+				vat_rate = $7, notes = $8, invoice_number_prefix = $9, credit_note_number_prefix = $10`
+
+	_, err = db.Exec(ctx, query, settings.CreatedAt, settings.UpdatedAt, settings.Enabled, settings.SellerName,
+		settings.SellerAddress, settings.SellerVatNumber, settings.VatRate, settings.Notes,
+		settings.InvoiceNumberPrefix, settings.CreditNoteNumberPrefix, settings.NextInvoiceNumber,
+		settings.NextCreditNoteNumber, settings.WebsiteID)
+	if err != nil {
+		err = fmt.Errorf("store.UpsertInvoicingSettings: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindInvoicingSettings(ctx context.Context, db db.Queryer, websiteID guid.GUID) (settings store.InvoicingSettings, err error) {
+	const query = "SELECT * FROM invoicing_settings WHERE website_id = $1"
+
+	err = db.Get(ctx, &settings, query, websiteID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.ErrInvoicingSettingsNotFound
+		} else {
+			err = fmt.Errorf("store.FindInvoicingSettings: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+// IncrementInvoiceNumber atomically reserves the next number of the sequence of invoices (or credit notes)
+// of the website. It must be called in the same transaction as the creation of the invoice so that
+// the sequence has no gap.
+func (repo *StoreRepository) IncrementInvoiceNumber(ctx context.Context, db db.Queryer, websiteID guid.GUID, invoiceType store.InvoiceType) (sequence int64, err error) {
+	var query string
+	switch invoiceType {
+	case store.InvoiceTypeCreditNote:
+		query = `UPDATE invoicing_settings SET next_credit_note_number = next_credit_note_number + 1
+			WHERE website_id = $1
+			RETURNING next_credit_note_number - 1`
+	default:
+		query = `UPDATE invoicing_settings SET next_invoice_number = next_invoice_number + 1
+			WHERE website_id = $1
+			RETURNING next_invoice_number - 1`
+	}
+
+	err = db.Get(ctx, &sequence, query, websiteID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.ErrInvoicingSettingsNotFound
+		} else {
+			err = fmt.Errorf("store.IncrementInvoiceNumber: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) CreateInvoice(ctx context.Context, db db.Queryer, invoice store.Invoice) (err error) {
+	const query = `INSERT INTO invoices
+			(id, created_at, updated_at, type, sequence, number, currency, line_items, vat_rate, net_amount,
+				vat_amount, total_amount, credited_invoice_number, seller_name, seller_address, seller_vat_number,
+				buyer_email, buyer_country, additional_invoice_information, notes, pdf_size, html_size,
+				website_id, order_id, refund_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+			$21, $22, $23, $24, $25)`
+
+	_, err = db.Exec(ctx, query, invoice.ID, invoice.CreatedAt, invoice.UpdatedAt, invoice.Type, invoice.Sequence,
+		invoice.Number, invoice.Currency, invoice.LineItems, invoice.VatRate, invoice.NetAmount, invoice.VatAmount,
+		invoice.TotalAmount, invoice.CreditedInvoiceNumber, invoice.SellerName, invoice.SellerAddress,
+		invoice.SellerVatNumber, invoice.BuyerEmail, invoice.BuyerCountry, invoice.AdditionalInvoiceInformation,
+		invoice.Notes, invoice.PdfSize, invoice.HtmlSize, invoice.WebsiteID, invoice.OrderID, invoice.RefundID)
+	if err != nil {
+		err = fmt.Errorf("store.CreateInvoice: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindInvoiceByID(ctx context.Context, db db.Queryer, invoiceID guid.GUID) (invoice store.Invoice, err error) {
+	const query = "SELECT * FROM invoices WHERE id = $1"
+
+	err = db.Get(ctx, &invoice, query, invoiceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.ErrInvoiceNotFound
+		} else {
+			err = fmt.Errorf("store.FindInvoiceByID: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+// FindInvoiceForOrder returns the invoice of an order (not its credit notes)
+func (repo *StoreRepository) FindInvoiceForOrder(ctx context.Context, db db.Queryer, orderID guid.GUID) (invoice store.Invoice, err error) {
+	const query = "SELECT * FROM invoices WHERE order_id = $1 AND type = $2"
+
+	err = db.Get(ctx, &invoice, query, orderID, store.InvoiceTypeInvoice)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.ErrInvoiceNotFound
+		} else {
+			err = fmt.Errorf("store.FindInvoiceForOrder: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindCreditNoteForRefund(ctx context.Context, db db.Queryer, refundID guid.GUID) (invoice store.Invoice, err error) {
+	const query = "SELECT * FROM invoices WHERE refund_id = $1"
+
+	err = db.Get(ctx, &invoice, query, refundID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.ErrInvoiceNotFound
+		} else {
+			err = fmt.Errorf("store.FindCreditNoteForRefund: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindInvoicesForWebsite(ctx context.Context, db db.Queryer, websiteID guid.GUID, limit int64) (ret []store.Invoice, err error) {
+	ret = make([]store.Invoice, 0)
+	const query = `SELECT * FROM invoices
+		WHERE website_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	err = db.Select(ctx, &ret, query, websiteID, limit)
+	if err != nil {
+		err = fmt.Errorf("store.FindInvoicesForWebsite: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindInvoicesForOrder(ctx context.Context, db db.Queryer, orderID guid.GUID) (ret []store.Invoice, err error) {
+	ret = make([]store.Invoice, 0)
+	const query = `SELECT * FROM invoices
+		WHERE order_id = $1
+		ORDER BY created_at
+	`
+
+	err = db.Select(ctx, &ret, query, orderID)
+	if err != nil {
+		err = fmt.Errorf("store.FindInvoicesForOrder: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindInvoicesForContact(ctx context.Context, db db.Queryer, contactID guid.GUID) (ret []store.Invoice, err error) {
+	ret = make([]store.Invoice, 0)
+	const query = `SELECT invoices.* FROM invoices
+			INNER JOIN orders ON orders.id = invoices.order_id
+		WHERE orders.contact_id = $1
+		ORDER BY invoices.created_at
+	`
+
+	err = db.Select(ctx, &ret, query, contactID)
+	if err != nil {
+		err = fmt.Errorf("store.FindInvoicesForContact: %w", err)
+		return
+	}
+
+	return
+}
+
+// FindInvoicesForWebsiteBetween returns the invoices and credit notes issued by a website during a period
+func (repo *StoreRepository) FindInvoicesForWebsiteBetween(ctx context.Context, db db.Queryer, websiteID guid.GUID, from, to time.Time) (ret []store.Invoice, err error) {
+	ret = make([]store.Invoice, 0)
+	const query = `SELECT * FROM invoices
+		WHERE website_id = $1 AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at
+	`
+
+	err = db.Select(ctx, &ret, query, websiteID, from, to)
+	if err != nil {
+		err = fmt.Errorf("store.FindInvoicesForWebsiteBetween: %w", err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (repo *StoreRepository) CreateLicenseKey(ctx context.Context, db db.Queryer, licenseKey store.LicenseKey) (err error) {
+	const query = `INSERT INTO license_keys
+			(id, created_at, updated_at, key, status, issued_at, revoked_at, website_id, product_id, order_id,
+				contact_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err = db.Exec(ctx, query, licenseKey.ID, licenseKey.CreatedAt, licenseKey.UpdatedAt, licenseKey.Key,
+		licenseKey.Status, licenseKey.IssuedAt, licenseKey.RevokedAt, licenseKey.WebsiteID, licenseKey.ProductID,
+		licenseKey.OrderID, licenseKey.ContactID)
+	if err != nil {
+		err = fmt.Errorf("store.CreateLicenseKey: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) UpdateLicenseKey(ctx context.Context, db db.Queryer, licenseKey store.LicenseKey) (err error) {
+	const query = `UPDATE license_keys
+		SET updated_at = $1, status = $2, issued_at = $3, revoked_at = $4, order_id = $5, contact_id = $6
+		WHERE id = $7`
+
+	_, err = db.Exec(ctx, query, licenseKey.UpdatedAt, licenseKey.Status, licenseKey.IssuedAt,
+		licenseKey.RevokedAt, licenseKey.OrderID, licenseKey.ContactID, licenseKey.ID)
+	if err != nil {
+		err = fmt.Errorf("store.UpdateLicenseKey: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindLicenseKeyByID(ctx context.Context, db db.Queryer, licenseKeyID guid.GUID) (licenseKey store.LicenseKey, err error) {
+	const query = "SELECT * FROM license_keys WHERE id = $1"
+
+	err = db.Get(ctx, &licenseKey, query, licenseKeyID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.ErrLicenseKeyNotFound
+		} else {
+			err = fmt.Errorf("store.FindLicenseKeyByID: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindLicenseKeyByProductAndKey(ctx context.Context, db db.Queryer, productID guid.GUID, key string, forUpdate bool) (licenseKey store.LicenseKey, err error) {
+	query := "SELECT * FROM license_keys WHERE product_id = $1 AND key = $2"
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	err = db.Get(ctx, &licenseKey, query, productID, key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.ErrLicenseKeyNotFound
+		} else {
+			err = fmt.Errorf("store.FindLicenseKeyByProductAndKey: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+// FindAvailableLicenseKeyForProduct returns the oldest key of the pool of the product that is not issued
+// yet and locks it. Keys locked by concurrent transactions are skipped.
+func (repo *StoreRepository) FindAvailableLicenseKeyForProduct(ctx context.Context, db db.Queryer, productID guid.GUID) (licenseKey store.LicenseKey, err error) {
+	const query = `SELECT * FROM license_keys
+		WHERE product_id = $1 AND status = $2
+		ORDER BY created_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`
+
+	err = db.Get(ctx, &licenseKey, query, productID, store.LicenseKeyStatusAvailable)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.ErrLicenseKeyNotFound
+		} else {
+			err = fmt.Errorf("store.FindAvailableLicenseKeyForProduct: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+// HasAvailableLicenseKeys returns true if the pool of keys of the product has keys that are not issued yet
+func (repo *StoreRepository) HasAvailableLicenseKeys(ctx context.Context, db db.Queryer, productID guid.GUID) (ret bool, err error) {
+	const query = `SELECT EXISTS (SELECT 1 FROM license_keys WHERE product_id = $1 AND status = $2)`
+
+	err = db.Get(ctx, &ret, query, productID, store.LicenseKeyStatusAvailable)
+	if err != nil {
+		err = fmt.Errorf("store.HasAvailableLicenseKeys: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindLicenseKeysForProduct(ctx context.Context, db db.Queryer, productID guid.GUID) (licenseKeys []store.LicenseKey, err error) {
+	licenseKeys = make([]store.LicenseKey, 0)
+	const query = `SELECT license_keys.*,
+			(SELECT COUNT(*) FROM license_key_activations
+				WHERE license_key_activations.license_key_id = license_keys.id) AS activations
+		FROM license_keys
+		WHERE product_id = $1
+		ORDER BY created_at DESC
+	`
+
+	err = db.Select(ctx, &licenseKeys, query, productID)
+	if err != nil {
+		err = fmt.Errorf("store.FindLicenseKeysForProduct: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindLicenseKeysForOrder(ctx context.Context, db db.Queryer, orderID guid.GUID) (licenseKeys []store.LicenseKey, err error) {
+	licenseKeys = make([]store.LicenseKey, 0)
+	const query = `SELECT license_keys.*, products.name AS product_name
+		FROM license_keys
+			INNER JOIN products ON products.id = license_keys.product_id
+		WHERE license_keys.order_id = $1
+		ORDER BY products.name
+	`
+
+	err = db.Select(ctx, &licenseKeys, query, orderID)
+	if err != nil {
+		err = fmt.Errorf("store.FindLicenseKeysForOrder: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindLicenseKeysForContact(ctx context.Context, db db.Queryer, contactID guid.GUID) (licenseKeys []store.LicenseKey, err error) {
+	licenseKeys = make([]store.LicenseKey, 0)
+	const query = `SELECT license_keys.*, products.name AS product_name
+		FROM license_keys
+			INNER JOIN products ON products.id = license_keys.product_id
+		WHERE license_keys.contact_id = $1
+		ORDER BY products.name
+	`
+
+	err = db.Select(ctx, &licenseKeys, query, contactID)
+	if err != nil {
+		err = fmt.Errorf("store.FindLicenseKeysForContact: %w", err)
+		return
+	}
+
+	return
+}
+
+// RevokeLicenseKeysForOrder revokes all the keys issued for the order
+func (repo *StoreRepository) RevokeLicenseKeysForOrder(ctx context.Context, db db.Queryer, orderID guid.GUID, now time.Time) (err error) {
+	const query = `UPDATE license_keys
+		SET updated_at = $1, status = $2, revoked_at = $1
+		WHERE order_id = $3 AND status = $4`
+
+	_, err = db.Exec(ctx, query, now, store.LicenseKeyStatusRevoked, orderID, store.LicenseKeyStatusIssued)
+	if err != nil {
+		err = fmt.Errorf("store.RevokeLicenseKeysForOrder: %w", err)
+		return
+	}
+
+	return
+}
+
+// CreateLicenseKeyActivation does nothing if the instance is already activated
+func (repo *StoreRepository) CreateLicenseKeyActivation(ctx context.Context, db db.Queryer, activation store.LicenseKeyActivation) (err error) {
+	const query = `INSERT INTO license_key_activations
+			(id, created_at, instance, license_key_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (license_key_id, instance) DO NOTHING`
+
+	_, err = db.Exec(ctx, query, activation.ID, activation.CreatedAt, activation.Instance,
+		activation.LicenseKeyID)
+	if err != nil {
+		err = fmt.Errorf("store.CreateLicenseKeyActivation: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) DeleteLicenseKeyActivation(ctx context.Context, db db.Queryer, licenseKeyID guid.GUID, instance string) (err error) {
+	const query = "DELETE FROM license_key_activations WHERE license_key_id = $1 AND instance = $2"
+
+	_, err = db.Exec(ctx, query, licenseKeyID, instance)
+	if err != nil {
+		err = fmt.Errorf("store.DeleteLicenseKeyActivation: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindLicenseKeyActivationsForLicenseKey(ctx context.Context, db db.Queryer, licenseKeyID guid.GUID) (activations []store.LicenseKeyActivation, err error) {
+	activations = make([]store.LicenseKeyActivation, 0)
+	const query = `SELECT * FROM license_key_activations
+		WHERE license_key_id = $1
+		ORDER BY created_at
+	`
+
+	err = db.Select(ctx, &activations, query, licenseKeyID)
+	if err != nil {
+		err = fmt.Errorf("store.FindLicenseKeyActivationsForLicenseKey: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) CreatePendingLicenseKey(ctx context.Context, db db.Queryer, pendingLicenseKey store.PendingLicenseKey) (err error) {
+	const query = `INSERT INTO pending_license_keys
+			(id, created_at, product_id, order_id, contact_id)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err = db.Exec(ctx, query, pendingLicenseKey.ID, pendingLicenseKey.CreatedAt, pendingLicenseKey.ProductID,
+		pendingLicenseKey.OrderID, pendingLicenseKey.ContactID)
+	if err != nil {
+		err = fmt.Errorf("store.CreatePendingLicenseKey: %w", err)
+		return
+	}
+
+	return
+}
+
+// FindPendingLicenseKeysForProduct returns the pending keys of the product, oldest first, and locks them
+func (repo *StoreRepository) FindPendingLicenseKeysForProduct(ctx context.Context, db db.Queryer, productID guid.GUID) (pendingLicenseKeys []store.PendingLicenseKey, err error) {
+	pendingLicenseKeys = make([]store.PendingLicenseKey, 0)
+	const query = `SELECT * FROM pending_license_keys
+		WHERE product_id = $1
+		ORDER BY created_at
+		FOR UPDATE`
+
+	err = db.Select(ctx, &pendingLicenseKeys, query, productID)
+	if err != nil {
+		err = fmt.Errorf("store.FindPendingLicenseKeysForProduct: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) DeletePendingLicenseKey(ctx context.Context, db db.Queryer, pendingLicenseKeyID guid.GUID) (err error) {
+	const query = "DELETE FROM pending_license_keys WHERE id = $1"
+
+	_, err = db.Exec(ctx, query, pendingLicenseKeyID)
+	if err != nil {
+		err = fmt.Errorf("store.DeletePendingLicenseKey: %w", err)
+		return
+	}
+
+	return
+}
+
+// DeletePendingLicenseKeysForOrder deletes the keys still pending for the order (e.g. after a refund)
+func (repo *StoreRepository) DeletePendingLicenseKeysForOrder(ctx context.Context, db db.Queryer, orderID guid.GUID) (err error) {
+	const query = "DELETE FROM pending_license_keys WHERE order_id = $1"
+
+	_, err = db.Exec(ctx, query, orderID)
+	if err != nil {
+		err = fmt.Errorf("store.DeletePendingLicenseKeysForOrder: %w", err)
+		return
+	}
+
+	return
+}
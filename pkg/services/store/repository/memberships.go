@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (repo *StoreRepository) CreateMembership(ctx context.Context, db db.Queryer, membership store.Membership) (err error) {
+	const query = `INSERT INTO memberships
+			(id, created_at, updated_at, status, current_period_end, cancel_at_period_end, canceled_at,
+				stripe_subscription_id, website_id, contact_id, product_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err = db.Exec(ctx, query, membership.ID, membership.CreatedAt, membership.UpdatedAt,
+		membership.Status, membership.CurrentPeriodEnd, membership.CancelAtPeriodEnd, membership.CanceledAt,
+		membership.StripeSubscriptionID, membership.WebsiteID, membership.ContactID, membership.ProductID)
+	if err != nil {
+		err = fmt.Errorf("store.CreateMembership: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) UpdateMembership(ctx context.Context, db db.Queryer, membership store.Membership) (err error) {
+	const query = `UPDATE memberships
+		SET updated_at = $1, status = $2, current_period_end = $3, cancel_at_period_end = $4, canceled_at = $5
+		WHERE id = $6`
+
+	_, err = db.Exec(ctx, query, membership.UpdatedAt, membership.Status, membership.CurrentPeriodEnd,
+		membership.CancelAtPeriodEnd, membership.CanceledAt,
+		membership.ID)
+	if err != nil {
+		err = fmt.Errorf("store.UpdateMembership: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindMembershipByID(ctx context.Context, db db.Queryer, membershipID guid.GUID, forUpdate bool) (membership store.Membership, err error) {
+	query := "SELECT * FROM memberships WHERE id = $1"
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	err = db.Get(ctx, &membership, query, membershipID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.ErrMembershipNotFound
+		} else {
+			err = fmt.Errorf("store.FindMembershipByID: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindMembershipByStripeSubscriptionID(ctx context.Context, db db.Queryer, stripeSubscriptionID string, forUpdate bool) (membership store.Membership, err error) {
+	query := "SELECT * FROM memberships WHERE stripe_subscription_id = $1"
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	err = db.Get(ctx, &membership, query, stripeSubscriptionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.ErrMembershipNotFound
+		} else {
+			err = fmt.Errorf("store.FindMembershipByStripeSubscriptionID: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindMembershipsForContact(ctx context.Context, db db.Queryer, contactID guid.GUID) (memberships []store.Membership, err error) {
+	memberships = make([]store.Membership, 0)
+	const query = `SELECT * FROM memberships
+		WHERE contact_id = $1
+		ORDER BY id DESC`
+
+	err = db.Select(ctx, &memberships, query, contactID)
+	if err != nil {
+		err = fmt.Errorf("store.FindMembershipsForContact: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindMembershipsForWebsite(ctx context.Context, db db.Queryer, websiteID guid.GUID) (memberships []store.Membership, err error) {
+	memberships = make([]store.Membership, 0)
+	const query = `SELECT * FROM memberships
+		WHERE website_id = $1
+		ORDER BY id DESC`
+
+	err = db.Select(ctx, &memberships, query, websiteID)
+	if err != nil {
+		err = fmt.Errorf("store.FindMembershipsForWebsite: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindActiveMembershipForContactAndProduct(ctx context.Context, db db.Queryer, contactID, productID guid.GUID) (membership store.Membership, err error) {
+	const query = `SELECT * FROM memberships
+		WHERE contact_id = $1 AND product_id = $2 AND status = ANY($3)
+		LIMIT 1`
+
+	err = db.Get(ctx, &membership, query, contactID, productID, store.ActiveMembershipStatuses)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.ErrMembershipNotFound
+		} else {
+			err = fmt.Errorf("store.FindActiveMembershipForContactAndProduct: %w", err)
+		}
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) HasActiveMembership(ctx context.Context, db db.Queryer, contactID guid.GUID) (hasActiveMembership bool, err error) {
+	const query = `SELECT EXISTS(
+		SELECT 1 FROM memberships WHERE contact_id = $1 AND status = ANY($2)
+	)`
+
+	err = db.Get(ctx, &hasActiveMembership, query, contactID, store.ActiveMembershipStatuses)
+	if err != nil {
+		err = fmt.Errorf("store.HasActiveMembership: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindActiveMembersContactIDs(ctx context.Context, db db.Queryer, websiteID guid.GUID) (contactIDs []guid.GUID, err error) {
+	contactIDs = make([]guid.GUID, 0)
+	const query = `SELECT DISTINCT contact_id FROM memberships
+		WHERE website_id = $1 AND status = ANY($2)`
+
+	err = db.Select(ctx, &contactIDs, query, websiteID, store.ActiveMembershipStatuses)
+	if err != nil {
+		err = fmt.Errorf("store.FindActiveMembersContactIDs: %w", err)
+		return
+	}
+
+	return
+}
@@ -15,14 +15,17 @@ func (repo *StoreRepository) CreateOrder(ctx context.Context, db db.Queryer, ord
 	const query = `INSERT INTO orders
 			(id, created_at, updated_at, total_amount, currency, notes, status, completed_at, canceled_at,
 				email, country, additional_invoice_information, stripe_checkout_session_id, stripe_payment_intent_id, stripe_invoice_id, stripe_invoice_url,
-				contact_id, website_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`
+				contact_id, website_id, discount_amount, coupon_id, recovery_email_sent_at,
+				gift_recipient_email, gift_message, gift_recipient_contact_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21,
+			$22, $23, $24)`
 
 	_, err = db.Exec(ctx, query, order.ID, order.CreatedAt, order.UpdatedAt, order.TotalAmount, order.Currency,
 		order.Notes, order.Status, order.CompletedAt, order.CanceledAt,
 		order.Email, order.Country, order.AdditionalInvoiceInformation,
 		order.StripeCheckoutSessionID, order.StripPaymentItentID, order.StripeInvoiceID, order.StripeInvoiceUrl,
-		order.ContactID, order.WebsiteID)
+		order.ContactID, order.WebsiteID, order.DiscountAmount, order.CouponID, order.RecoveryEmailSentAt,
+		order.GiftRecipientEmail, order.GiftMessage, order.GiftRecipientContactID)
 	if err != nil {
 		err = fmt.Errorf("store.CreateOrder: %w", err)
 		return
@@ -36,14 +39,14 @@ func (repo *StoreRepository) UpdateOrder(ctx context.Context, db db.Queryer, ord
 		SET updated_at = $1, notes = $2, status = $3, completed_at = $4, canceled_at = $5,
 			email = $6, country = $7, stripe_invoice_id = $8, stripe_payment_intent_id = $9,
 			stripe_checkout_session_id = $10, stripe_invoice_url = $11, total_amount = $12,
-			additional_invoice_information = $13
-		WHERE id = $14
+			additional_invoice_information = $13, recovery_email_sent_at = $14, gift_recipient_contact_id = $15
+		WHERE id = $16
 `
 
 	_, err = db.Exec(ctx, query, order.UpdatedAt, order.Notes, order.Status, order.CompletedAt, order.CanceledAt,
 		order.Email, order.Country, order.StripeInvoiceID,
 		order.StripPaymentItentID, order.StripeCheckoutSessionID, order.StripeInvoiceUrl, order.TotalAmount,
-		order.AdditionalInvoiceInformation,
+		order.AdditionalInvoiceInformation, order.RecoveryEmailSentAt, order.GiftRecipientContactID,
 		order.ID)
 	if err != nil {
 		err = fmt.Errorf("store.UpdateOrder: %w", err)
@@ -161,3 +164,20 @@ func (repo *StoreRepository) GetWebsiteRevenue(ctx context.Context, db db.Querye
 
 	return
 }
+
+// FindCompletedOrdersForWebsiteBetween returns the orders of a website completed during a period
+func (repo *StoreRepository) FindCompletedOrdersForWebsiteBetween(ctx context.Context, db db.Queryer, websiteID guid.GUID, from, to time.Time) (ret []store.Order, err error) {
+	ret = make([]store.Order, 0)
+	const query = `SELECT * FROM orders
+		WHERE website_id = $1 AND status = $2 AND completed_at >= $3 AND completed_at < $4
+		ORDER BY completed_at
+	`
+
+	err = db.Select(ctx, &ret, query, websiteID, store.OrderStatusCompleted, from, to)
+	if err != nil {
+		err = fmt.Errorf("store.FindCompletedOrdersForWebsiteBetween: %w", err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (repo *StoreRepository) CreateOrderGrantedProduct(ctx context.Context, db db.Queryer, grantedProduct store.OrderGrantedProduct) (err error) {
+	const query = `INSERT INTO orders_granted_products
+			(id, created_at, bundle_id, order_id, product_id, contact_id)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err = db.Exec(ctx, query, grantedProduct.ID, grantedProduct.CreatedAt, grantedProduct.BundleID,
+		grantedProduct.OrderID, grantedProduct.ProductID, grantedProduct.ContactID)
+	if err != nil {
+		err = fmt.Errorf("store.CreateOrderGrantedProduct: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) UpdateOrderGrantedProduct(ctx context.Context, db db.Queryer, grantedProduct store.OrderGrantedProduct) (err error) {
+	const query = `UPDATE orders_granted_products SET contact_id = $1 WHERE id = $2`
+
+	_, err = db.Exec(ctx, query, grantedProduct.ContactID, grantedProduct.ID)
+	if err != nil {
+		err = fmt.Errorf("store.UpdateOrderGrantedProduct: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindOrderGrantedProducts(ctx context.Context, db db.Queryer, orderID guid.GUID) (ret []store.OrderGrantedProduct, err error) {
+	ret = make([]store.OrderGrantedProduct, 0)
+	const query = `SELECT * FROM orders_granted_products WHERE order_id = $1`
+
+	err = db.Select(ctx, &ret, query, orderID)
+	if err != nil {
+		err = fmt.Errorf("store.FindOrderGrantedProducts: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) FindOrderGrantedProductsForContact(ctx context.Context, db db.Queryer, contactID guid.GUID) (ret []store.OrderGrantedProduct, err error) {
+	ret = make([]store.OrderGrantedProduct, 0)
+	const query = `SELECT * FROM orders_granted_products WHERE contact_id = $1`
+
+	err = db.Select(ctx, &ret, query, contactID)
+	if err != nil {
+		err = fmt.Errorf("store.FindOrderGrantedProductsForContact: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *StoreRepository) DeleteOrderGrantedProducts(ctx context.Context, db db.Queryer, orderID guid.GUID) (err error) {
+	const query = `DELETE FROM orders_granted_products WHERE order_id = $1`
+
+	_, err = db.Exec(ctx, query, orderID)
+	if err != nil {
+		err = fmt.Errorf("store.DeleteOrderGrantedProducts: %w", err)
+		return
+	}
+
+	return
+}
@@ -24,11 +24,11 @@ func (repo *StoreRepository) GetProductPagesCountForProduct(ctx context.Context,
 
 func (repo *StoreRepository) CreateProductPage(ctx context.Context, db db.Queryer, page store.ProductPage) (err error) {
 	const query = `INSERT INTO product_pages
-			(id, created_at, updated_at, position, title, size, hash, body_markdown, product_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+			(id, created_at, updated_at, position, title, size, hash, body_markdown, drip_days, product_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
 
 	_, err = db.Exec(ctx, query, page.ID, page.CreatedAt, page.UpdatedAt, page.Position,
-		page.Title, page.Size, page.Hash, page.BodyMarkdown,
+		page.Title, page.Size, page.Hash, page.BodyMarkdown, page.DripDays,
 		page.ProductID)
 	if err != nil {
 		err = fmt.Errorf("store.CreateProductPage: %w", err)
@@ -69,12 +69,12 @@ func (repo *StoreRepository) DeleteProductPage(ctx context.Context, db db.Querye
 func (repo *StoreRepository) UpdateProductPage(ctx context.Context, db db.Queryer, page store.ProductPage) (err error) {
 	const query = `UPDATE product_pages
 		SET updated_at = $1, position = $2, title = $3,
-			size = $4, hash = $5, body_markdown = $6
-		WHERE id = $7
+			size = $4, hash = $5, body_markdown = $6, drip_days = $7
+		WHERE id = $8
 `
 
 	_, err = db.Exec(ctx, query, page.UpdatedAt, page.Position, page.Title, page.Size,
-		page.Hash, page.BodyMarkdown,
+		page.Hash, page.BodyMarkdown, page.DripDays,
 		page.ID)
 	if err != nil {
 		err = fmt.Errorf("store.UpdateProductPage: %w", err)
@@ -12,12 +12,14 @@ import (
 
 func (repo *StoreRepository) CreateProduct(ctx context.Context, db db.Queryer, product store.Product) (err error) {
 	const query = `INSERT INTO products
-			(id, created_at, updated_at, name, description, type, status, price, website_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+			(id, created_at, updated_at, name, description, type, status, price, ebook_watermark,
+			billing_interval, prices, pay_what_you_want, license_keys, license_key_activations_limit, website_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
 
 	_, err = db.Exec(ctx, query, product.ID, product.CreatedAt, product.UpdatedAt,
 		product.Name, product.Description, product.Type, product.Status, product.Price,
-		product.WebsiteID)
+		product.EbookWatermark, product.BillingInterval, product.Prices, product.PayWhatYouWant,
+		product.LicenseKeys, product.LicenseKeyActivationsLimit, product.WebsiteID)
 	if err != nil {
 		err = fmt.Errorf("store.CreateProduct: %w", err)
 		return
@@ -28,12 +30,15 @@ func (repo *StoreRepository) CreateProduct(ctx context.Context, db db.Queryer, p
 
 func (repo *StoreRepository) UpdateProduct(ctx context.Context, db db.Queryer, product store.Product) (err error) {
 	const query = `UPDATE products
-		SET updated_at = $1, name = $2, description = $3, status = $4, price = $5
-		WHERE id = $6
+		SET updated_at = $1, name = $2, description = $3, status = $4, price = $5, ebook_watermark = $6,
+			billing_interval = $7, prices = $8, pay_what_you_want = $9, license_keys = $10,
+			license_key_activations_limit = $11
+		WHERE id = $12
 `
 
 	_, err = db.Exec(ctx, query, product.UpdatedAt, product.Name, product.Description,
-		product.Status, product.Price,
+		product.Status, product.Price, product.EbookWatermark, product.BillingInterval,
+		product.Prices, product.PayWhatYouWant, product.LicenseKeys, product.LicenseKeyActivationsLimit,
 		product.ID)
 	if err != nil {
 		err = fmt.Errorf("store.UpdateProduct: %w", err)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/guid"
@@ -28,7 +29,7 @@ func (repo *StoreRepository) CreateRefund(ctx context.Context, db db.Queryer, re
 }
 
 func (repo *StoreRepository) UpdateRefund(ctx context.Context, db db.Queryer, refund store.Refund) (err error) {
-	const query = `UPDATE products
+	const query = `UPDATE refunds
 		SET updated_at = $1, notes = $2, status = $3, reason = $4, failure_reason = $5, stripe_refund_id = $6
 		WHERE id = $7
 		`
@@ -107,3 +108,19 @@ func (repo *StoreRepository) FindPendingRefunds(ctx context.Context, db db.Query
 
 	return
 }
+
+func (repo *StoreRepository) FindRefundsForWebsiteBetween(ctx context.Context, db db.Queryer, websiteID guid.GUID, from, to time.Time) (ret []store.Refund, err error) {
+	ret = make([]store.Refund, 0)
+	const query = `SELECT * FROM refunds
+		WHERE website_id = $1 AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at
+	`
+
+	err = db.Select(ctx, &ret, query, websiteID, from, to)
+	if err != nil {
+		err = fmt.Errorf("store.FindRefundsForWebsiteBetween: %w", err)
+		return
+	}
+
+	return
+}
@@ -2,17 +2,18 @@ package store
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/guid"
-	"github.com/stripe/stripe-go/v81"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/services/kernel"
 )
 
 type Service interface {
-	// Stripe
-	HandleStripeEvent(ctx context.Context, stripeEvent stripe.Event) (err error)
+	// Payments
+	HandlePaymentEvent(ctx context.Context, event payments.Event) (err error)
 
 	// Products
 	CreateProduct(ctx context.Context, input CreateProductInput) (product Product, err error)
@@ -24,13 +25,20 @@ type Service interface {
 	GiveContactsAccessToProduct(ctx context.Context, input GiveContactsAccessToProductInput) (err error)
 	FindProductsForContact(ctx context.Context, db db.Queryer, contactID guid.GUID) (products []Product, err error)
 	FindProductsForWebsite(ctx context.Context, db db.Queryer, websiteID guid.GUID, limit int64) (products []Product, err error)
+	FindProductsInBundle(ctx context.Context, db db.Queryer, bundleID guid.GUID) (products []Product, err error)
 	// TODO
 	RemoveAccessToProduct(ctx context.Context, input RemoveAccessToProductInput) (err error)
+	TransferProductAccess(ctx context.Context, input TransferProductAccessInput) (err error)
 	FindProductWithContent(ctx context.Context, db db.Queryer, productID guid.GUID) (product Product, err error)
 	DeleteProduct(ctx context.Context, input DeleteProductInput) (err error)
+	GetProductEbook(ctx context.Context, input GetProductEbookInput) (ret GetProductEbookOutput, err error)
+	// ScheduleProductEbooksGeneration enqueues a job to (re)generate the ebooks of the product if it's a book
+	ScheduleProductEbooksGeneration(ctx context.Context, db db.Queryer, productID guid.GUID) (err error)
 
 	// Orders
 	PlaceOrder(ctx context.Context, input PlaceOrderInput) (ret PlaceOrderOutput, err error)
+	// PreviewOrder computes the price of an order, including the discount of a coupon, without placing it
+	PreviewOrder(ctx context.Context, input PreviewOrderInput) (ret PreviewOrderOutput, err error)
 	CompleteOrder(ctx context.Context, input CompleteOrderInput) (err error)
 	CancelOrder(ctx context.Context, input CancelOrderInput) (err error)
 	ListOrders(ctx context.Context, input ListOrdersInput) (ret kernel.PaginatedResult[OrderMetadata], err error)
@@ -38,6 +46,32 @@ type Service interface {
 	FindCompletedOrdersForContact(ctx context.Context, db db.Queryer, contactID guid.GUID) (orders []Order, err error)
 	GetWebsiteRevenue(ctx context.Context, db db.Queryer, websiteID guid.GUID, from, to time.Time) (revenue int64, err error)
 	GetOrder(ctx context.Context, input GetOrderInput) (order Order, err error)
+	// ResumeOrder is used by the link of the abandoned checkout recovery email
+	ResumeOrder(ctx context.Context, input ResumeOrderInput) (ret ResumeOrderOutput, err error)
+
+	// Abandoned checkouts
+	GetAbandonedCheckoutSettings(ctx context.Context, input GetAbandonedCheckoutSettingsInput) (settings AbandonedCheckoutSettings, err error)
+	UpdateAbandonedCheckoutSettings(ctx context.Context, input UpdateAbandonedCheckoutSettingsInput) (settings AbandonedCheckoutSettings, err error)
+
+	// Invoices
+	GetInvoicingSettings(ctx context.Context, input GetInvoicingSettingsInput) (settings InvoicingSettings, err error)
+	UpdateInvoicingSettings(ctx context.Context, input UpdateInvoicingSettingsInput) (settings InvoicingSettings, err error)
+	ListInvoices(ctx context.Context, input ListInvoicesInput) (ret kernel.PaginatedResult[Invoice], err error)
+	FindInvoicesForContact(ctx context.Context, db db.Queryer, contactID guid.GUID) (invoices []Invoice, err error)
+	// GetInvoiceFile returns the PDF or HTML document of an invoice to the staff of the website or to
+	// the contact who placed the order
+	GetInvoiceFile(ctx context.Context, input GetInvoiceFileInput) (ret GetInvoiceFileOutput, err error)
+	ServeInvoice(res http.ResponseWriter, req *http.Request)
+	ExportAccounting(ctx context.Context, input ExportAccountingInput) (ret ExportAccountingOutput, err error)
+
+	// Memberships
+	ListMemberships(ctx context.Context, input ListMembershipsInput) (ret kernel.PaginatedResult[Membership], err error)
+	GrantMembership(ctx context.Context, input GrantMembershipInput) (membership Membership, err error)
+	CancelMembership(ctx context.Context, input CancelMembershipInput) (membership Membership, err error)
+	FindMembershipsForContact(ctx context.Context, db db.Queryer, contactID guid.GUID) (memberships []Membership, err error)
+	// HasActiveMembership returns true if the contact has at least one active membership
+	HasActiveMembership(ctx context.Context, db db.Queryer, contactID guid.GUID) (hasActiveMembership bool, err error)
+	FindActiveMembersContactIDs(ctx context.Context, db db.Queryer, websiteID guid.GUID) (contactIDs []guid.GUID, err error)
 
 	// Refunds
 	ListRefunds(ctx context.Context, input ListRefundsInput) (ret kernel.PaginatedResult[Refund], err error)
@@ -49,6 +83,24 @@ type Service interface {
 	DeleteProductPage(ctx context.Context, input DeleteProductPageInput) (err error)
 	GetProductPage(ctx context.Context, input GetProductPageInput) (page ProductPage, err error)
 
+	// Courses
+	FindContactProductAccess(ctx context.Context, db db.Queryer, contactID, productID guid.GUID) (access ContactProductAccess, err error)
+	FindProductPageCompletionsForContact(ctx context.Context, db db.Queryer, contactID, productID guid.GUID) (completions []ProductPageCompletion, err error)
+	GetCourseProgress(ctx context.Context, db db.Queryer, contactID, productID guid.GUID) (progress CourseProgress, err error)
+	UpdateLessonProgress(ctx context.Context, db db.Queryer, input UpdateLessonProgressInput) (err error)
+	GetCourseAnalytics(ctx context.Context, input GetCourseAnalyticsInput) (analytics CourseAnalytics, err error)
+
+	// License keys
+	ListLicenseKeys(ctx context.Context, input ListLicenseKeysInput) (ret kernel.PaginatedResult[LicenseKey], err error)
+	AddLicenseKeysToPool(ctx context.Context, input AddLicenseKeysToPoolInput) (err error)
+	RevokeLicenseKey(ctx context.Context, input RevokeLicenseKeyInput) (licenseKey LicenseKey, err error)
+	FindLicenseKeysForContact(ctx context.Context, db db.Queryer, contactID guid.GUID) (licenseKeys []LicenseKey, err error)
+	// VerifyLicenseKey, ActivateLicenseKey and DeactivateLicenseKey are public and scoped to the website
+	// of the request
+	VerifyLicenseKey(ctx context.Context, input VerifyLicenseKeyInput) (verification LicenseKeyVerification, err error)
+	ActivateLicenseKey(ctx context.Context, input ActivateLicenseKeyInput) (verification LicenseKeyVerification, err error)
+	DeactivateLicenseKey(ctx context.Context, input DeactivateLicenseKeyInput) (verification LicenseKeyVerification, err error)
+
 	// Coupons
 	CreateCoupon(ctx context.Context, input CreateCouponInput) (coupon Coupon, err error)
 	GetCoupon(ctx context.Context, input GetCouponInput) (coupon Coupon, err error)
@@ -57,9 +109,15 @@ type Service interface {
 
 	// Jobs
 	JobSendOrderConfirmationEmail(ctx context.Context, input JobSendOrderConfirmationEmail) (err error)
+	JobSendGiftNotificationEmail(ctx context.Context, input JobSendGiftNotificationEmail) (err error)
 	JobCreateStripeRefund(ctx context.Context, input JobCreateStripeRefund) (err error)
 	JobSyncRefundWithStripe(ctx context.Context, input JobSyncRefundWithStripe) (err error)
+	JobGenerateProductEbooks(ctx context.Context, input JobGenerateProductEbooks) (err error)
+	JobSendAbandonedCheckoutEmail(ctx context.Context, input JobSendAbandonedCheckoutEmail) (err error)
+	JobGenerateInvoice(ctx context.Context, input JobGenerateInvoice) (err error)
+	JobGenerateCreditNote(ctx context.Context, input JobGenerateCreditNote) (err error)
 
 	// Tasks
 	TaskSyncRefundsWithStripe(ctx context.Context)
+	TaskSendAbandonedCheckoutEmails(ctx context.Context)
 }
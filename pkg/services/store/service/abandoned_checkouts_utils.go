@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/store"
+)
+
+// findAbandonedCheckoutSettings returns the default (disabled) settings if the website has never
+// configured abandoned checkout recovery.
+func (service *StoreService) findAbandonedCheckoutSettings(ctx context.Context, db db.Queryer, websiteID guid.GUID) (settings store.AbandonedCheckoutSettings, err error) {
+	settings, err = service.repo.FindAbandonedCheckoutSettings(ctx, db, websiteID)
+	if err != nil {
+		if !errs.IsNotFound(err) {
+			return
+		}
+
+		now := time.Now().UTC()
+		settings = store.AbandonedCheckoutSettings{
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			Enabled:      false,
+			DelayHours:   store.DefaultAbandonedCheckoutDelayHours,
+			Subject:      store.DefaultAbandonedCheckoutSubject,
+			BodyMarkdown: store.DefaultAbandonedCheckoutBodyMarkdown,
+			WebsiteID:    websiteID,
+		}
+		err = nil
+	}
+
+	return
+}
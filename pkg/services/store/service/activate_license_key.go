@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/store"
+)
+
+// ActivateLicenseKey activates a license key for an instance (machine, installation...) of an app.
+// Activating an instance that is already activated does not count against the activations limit.
+func (service *StoreService) ActivateLicenseKey(ctx context.Context, input store.ActivateLicenseKeyInput) (verification store.LicenseKeyVerification, err error) {
+	httpCtx := httpctx.FromCtx(ctx)
+
+	instance := strings.TrimSpace(input.Instance)
+	err = service.validateLicenseKeyInstance(instance)
+	if err != nil {
+		return
+	}
+
+	website, err := service.websitesService.FindWebsiteByDomain(ctx, service.db, httpCtx.Hostname)
+	if err != nil {
+		return
+	}
+
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		// the license key is locked to avoid exceeding the activations limit with concurrent requests
+		product, licenseKey, txErr := service.findLicenseKeyForWebsite(ctx, tx, website.ID, input.ProductID, input.Key, true)
+		if txErr != nil {
+			return txErr
+		}
+
+		if licenseKey.Status == store.LicenseKeyStatusRevoked {
+			return store.ErrLicenseKeyIsRevoked
+		}
+
+		verification, txErr = service.licenseKeyVerification(ctx, tx, product, licenseKey, &instance)
+		if txErr != nil {
+			return txErr
+		}
+		if verification.Activated {
+			return nil
+		}
+
+		if product.LicenseKeyActivationsLimit != 0 && verification.Activations >= product.LicenseKeyActivationsLimit {
+			return store.ErrLicenseKeyActivationsLimitReached
+		}
+
+		activation := store.LicenseKeyActivation{
+			ID:           guid.NewTimeBased(),
+			CreatedAt:    time.Now().UTC(),
+			Instance:     instance,
+			LicenseKeyID: licenseKey.ID,
+		}
+		txErr = service.repo.CreateLicenseKeyActivation(ctx, tx, activation)
+		if txErr != nil {
+			return txErr
+		}
+
+		verification.Activated = true
+		verification.Activations += 1
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	return
+}
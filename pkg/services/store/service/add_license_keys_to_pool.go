@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+// AddLicenseKeysToPool adds keys to the pool from which the license keys of the product are issued.
+// Empty lines are ignored, and the whole import fails if one of the keys already exists.
+// The new keys are first issued to the orders waiting for a key.
+func (service *StoreService) AddLicenseKeysToPool(ctx context.Context, input store.AddLicenseKeysToPoolInput) (err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	product, err := service.repo.FindProductByID(ctx, service.db, input.ProductID)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, product.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	if product.LicenseKeys != store.LicenseKeysModePool {
+		err = store.ErrProductIsNotUsingALicenseKeysPool
+		return
+	}
+
+	keys := make([]string, 0, len(input.Keys))
+	for _, key := range input.Keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		err = service.validateLicenseKey(key)
+		if err != nil {
+			return
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 || len(keys) > store.LicenseKeysPoolImportMaxCount {
+		err = store.ErrLicenseKeysPoolImportIsNotValid
+		return
+	}
+
+	now := time.Now().UTC()
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		for _, key := range keys {
+			licenseKey := store.LicenseKey{
+				ID:        guid.NewTimeBased(),
+				CreatedAt: now,
+				UpdatedAt: now,
+				Key:       key,
+				Status:    store.LicenseKeyStatusAvailable,
+				WebsiteID: product.WebsiteID,
+				ProductID: product.ID,
+			}
+			txErr = service.repo.CreateLicenseKey(ctx, tx, licenseKey)
+			if txErr != nil {
+				if db.IsErrAlreadyExists(txErr) {
+					return store.ErrLicenseKeyAlreadyExists(key)
+				}
+				return txErr
+			}
+		}
+
+		// the orders completed while the pool was empty are given the new keys first
+		return service.issuePendingLicenseKeys(ctx, tx, product, now)
+	})
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/store"
+)
+
+// hydrateBundleItems fills bundle.BundleItems with the IDs of the products included in the bundle
+func (service *StoreService) hydrateBundleItems(ctx context.Context, db db.Queryer, bundle *store.Product) (err error) {
+	if bundle.Type != store.ProductTypeBundle {
+		return nil
+	}
+
+	items, err := service.repo.FindProductsInBundle(ctx, db, bundle.ID)
+	if err != nil {
+		return
+	}
+
+	bundle.BundleItems = make([]guid.GUID, len(items))
+	for i := range items {
+		bundle.BundleItems[i] = items[i].ID
+	}
+
+	return
+}
+
+// validateBundleItems checks that all the items exist, belong to the same website as the bundle and
+// can be included in a bundle.
+func (service *StoreService) validateBundleItems(ctx context.Context, db db.Queryer, bundle store.Product, itemsIDs []guid.GUID) (err error) {
+	if len(itemsIDs) == 0 || len(itemsIDs) > store.BundleItemsMaxCount {
+		return store.ErrBundleItemsAreNotValid
+	}
+
+	for i, itemID := range itemsIDs {
+		if itemID.Equal(bundle.ID) || slices.ContainsFunc(itemsIDs[:i], itemID.Equal) {
+			return store.ErrBundleItemsAreNotValid
+		}
+
+		var item store.Product
+		item, err = service.repo.FindProductByID(ctx, db, itemID)
+		if err != nil {
+			return
+		}
+
+		if !item.WebsiteID.Equal(bundle.WebsiteID) {
+			return store.ErrProductNotFound
+		}
+
+		if item.Type == store.ProductTypeBundle || item.Type == store.ProductTypeMembership {
+			return store.ErrProductCantBeIncludedInABundle
+		}
+	}
+
+	return nil
+}
+
+// setBundleItems replaces the items of the bundle. Items must have been validated with validateBundleItems
+func (service *StoreService) setBundleItems(ctx context.Context, db db.Queryer, bundle *store.Product, itemsIDs []guid.GUID) (err error) {
+	err = service.repo.DeleteBundleProductRelationsForBundle(ctx, db, bundle.ID)
+	if err != nil {
+		return
+	}
+
+	for _, itemID := range itemsIDs {
+		relation := store.BundleProductRelation{
+			BundleID:  bundle.ID,
+			ProductID: itemID,
+		}
+		err = service.repo.CreateBundleProductRelation(ctx, db, relation)
+		if err != nil {
+			return
+		}
+	}
+
+	bundle.BundleItems = itemsIDs
+
+	return
+}
+
+// productsGivenAccessBy returns the IDs of the products a customer gets access to when purchasing
+// the given products: the products themselves and, for bundles, all the products they include.
+// Memberships are skipped as they don't give access to products.
+func (service *StoreService) productsGivenAccessBy(ctx context.Context, db db.Queryer, products []store.Product) (productsIDs []guid.GUID, err error) {
+	productsIDs = make([]guid.GUID, 0, len(products))
+
+	for _, product := range products {
+		if product.Type == store.ProductTypeMembership {
+			continue
+		}
+
+		productsIDs = append(productsIDs, product.ID)
+
+		if product.Type == store.ProductTypeBundle {
+			var items []store.Product
+			items, err = service.repo.FindProductsInBundle(ctx, db, product.ID)
+			if err != nil {
+				return
+			}
+
+			for _, item := range items {
+				productsIDs = append(productsIDs, item.ID)
+			}
+		}
+	}
+
+	return
+}
+
+// giveContactAccessToProduct creates the product-access relation if it doesn't already exist
+func (service *StoreService) giveContactAccessToProduct(ctx context.Context, tx db.Queryer, contactID, productID guid.GUID, now time.Time) (err error) {
+	_, err = service.repo.FindContactProductAccess(ctx, tx, contactID, productID)
+	if err == nil {
+		// if contact already has access to product we don't need to create product-access relation
+		return nil
+	} else if !errs.IsNotFound(err) {
+		return err
+	}
+
+	contactProductAccess := store.ContactProductAccess{
+		CreatedAt: now,
+		ContactID: contactID,
+		ProductID: productID,
+	}
+	err = service.repo.CreateContactProductAccess(ctx, tx, contactProductAccess)
+	if err != nil {
+		if db.IsErrAlreadyExists(err) {
+			// nothing has to be done if contact already has access to product
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+// grantedProductsForOrder returns the products that the order gives access to (see productsGivenAccessBy)
+// with the bundles that include them, to be saved with the order when it is completed.
+func (service *StoreService) grantedProductsForOrder(ctx context.Context, db db.Queryer, order store.Order, products []store.Product, now time.Time) (grantedProducts []store.OrderGrantedProduct, err error) {
+	grantedProducts = make([]store.OrderGrantedProduct, 0, len(products))
+	contactID := orderBeneficiaryContactID(order)
+
+	for _, product := range products {
+		if product.Type == store.ProductTypeMembership {
+			continue
+		}
+
+		grantedProducts = append(grantedProducts, store.OrderGrantedProduct{
+			ID:        guid.NewTimeBased(),
+			CreatedAt: now,
+			BundleID:  nil,
+			OrderID:   order.ID,
+			ProductID: product.ID,
+			ContactID: contactID,
+		})
+
+		if product.Type == store.ProductTypeBundle {
+			var items []store.Product
+			items, err = service.repo.FindProductsInBundle(ctx, db, product.ID)
+			if err != nil {
+				return
+			}
+
+			for _, item := range items {
+				grantedProducts = append(grantedProducts, store.OrderGrantedProduct{
+					ID:        guid.NewTimeBased(),
+					CreatedAt: now,
+					BundleID:  &product.ID,
+					OrderID:   order.ID,
+					ProductID: item.ID,
+					ContactID: contactID,
+				})
+			}
+		}
+	}
+
+	return
+}
+
+// grantedProductsIDs returns the IDs of the granted products, without duplicates
+func grantedProductsIDs(grantedProducts []store.OrderGrantedProduct) []guid.GUID {
+	productsIDs := make([]guid.GUID, 0, len(grantedProducts))
+	for _, grantedProduct := range grantedProducts {
+		if !slices.ContainsFunc(productsIDs, grantedProduct.ProductID.Equal) {
+			productsIDs = append(productsIDs, grantedProduct.ProductID)
+		}
+	}
+	return productsIDs
+}
+
+// accessesToRevokeForRefundedOrder returns the accesses given by the products granted by a refunded order,
+// except the ones that the contacts still have through another order. contactsGrantedProducts are all the
+// products granted to the contacts of orderGrantedProducts.
+func accessesToRevokeForRefundedOrder(orderID guid.GUID, orderGrantedProducts, contactsGrantedProducts []store.OrderGrantedProduct) []store.ContactProductAccess {
+	accesses := make([]store.ContactProductAccess, 0, len(orderGrantedProducts))
+
+	for _, grantedProduct := range orderGrantedProducts {
+		grantedByAnotherOrder := slices.ContainsFunc(contactsGrantedProducts, func(other store.OrderGrantedProduct) bool {
+			return !other.OrderID.Equal(orderID) && other.ContactID.Equal(grantedProduct.ContactID) &&
+				other.ProductID.Equal(grantedProduct.ProductID)
+		})
+		alreadyRevoked := slices.ContainsFunc(accesses, func(access store.ContactProductAccess) bool {
+			return access.ContactID.Equal(grantedProduct.ContactID) && access.ProductID.Equal(grantedProduct.ProductID)
+		})
+		if grantedByAnotherOrder || alreadyRevoked {
+			continue
+		}
+
+		accesses = append(accesses, store.ContactProductAccess{
+			ContactID: grantedProduct.ContactID,
+			ProductID: grantedProduct.ProductID,
+		})
+	}
+
+	return accesses
+}
+
+// grantedProductsToTransfer returns the products granted to a contact by orders that are transferred with
+// the given product: for bundles, the bundle and the items it included when it was ordered, otherwise the
+// product, including when it was granted by a bundle.
+func grantedProductsToTransfer(product store.Product, contactGrantedProducts []store.OrderGrantedProduct) []store.OrderGrantedProduct {
+	grantedProducts := make([]store.OrderGrantedProduct, 0)
+
+	for _, grantedProduct := range contactGrantedProducts {
+		if product.Type == store.ProductTypeBundle {
+			if (grantedProduct.BundleID == nil && grantedProduct.ProductID.Equal(product.ID)) ||
+				(grantedProduct.BundleID != nil && grantedProduct.BundleID.Equal(product.ID)) {
+				grantedProducts = append(grantedProducts, grantedProduct)
+			}
+		} else if grantedProduct.ProductID.Equal(product.ID) {
+			grantedProducts = append(grantedProducts, grantedProduct)
+		}
+	}
+
+	return grantedProducts
+}
+
+// transferGrantedProducts returns copies of the granted products given to the contact toContactID, so
+// that refunding their orders revokes the access of the contact who now holds it.
+func transferGrantedProducts(grantedProducts []store.OrderGrantedProduct, toContactID guid.GUID) []store.OrderGrantedProduct {
+	transferredProducts := make([]store.OrderGrantedProduct, len(grantedProducts))
+	for i, grantedProduct := range grantedProducts {
+		grantedProduct.ContactID = toContactID
+		transferredProducts[i] = grantedProduct
+	}
+	return transferredProducts
+}
+
+// productsToTransfer returns the IDs of the products whose access is transferred with the given product:
+// the product itself and, for bundles, the items granted with the bundle (see grantedProductsToTransfer),
+// or the current items of the bundle if the access was given manually.
+func (service *StoreService) productsToTransfer(ctx context.Context, db db.Queryer, product store.Product, grantedProducts []store.OrderGrantedProduct) (productsIDs []guid.GUID, err error) {
+	if product.Type != store.ProductTypeBundle {
+		return []guid.GUID{product.ID}, nil
+	}
+
+	if len(grantedProducts) == 0 {
+		return service.productsGivenAccessBy(ctx, db, []store.Product{product})
+	}
+
+	return grantedProductsIDs(grantedProducts), nil
+}
+
+// removeAccessForRefundedOrder removes the access to the products (and the items of the bundles)
+// granted by a refunded order, except for the products that the contact still has access to
+// through another completed and non-refunded order. The access is removed from the contact who currently
+// holds it: the buyer, the recipient of a gift or the contact the access was transferred to.
+// The products granted when the order was completed are used, as the bundles may have been edited since.
+func (service *StoreService) removeAccessForRefundedOrder(ctx context.Context, tx db.Queryer, order store.Order) (err error) {
+	orderGrantedProducts, err := service.repo.FindOrderGrantedProducts(ctx, tx, order.ID)
+	if err != nil {
+		return
+	}
+
+	contactsGrantedProducts := make([]store.OrderGrantedProduct, 0)
+	contactsIDs := make([]guid.GUID, 0, 1)
+	for _, grantedProduct := range orderGrantedProducts {
+		if slices.ContainsFunc(contactsIDs, grantedProduct.ContactID.Equal) {
+			continue
+		}
+		contactsIDs = append(contactsIDs, grantedProduct.ContactID)
+
+		var contactGrantedProducts []store.OrderGrantedProduct
+		contactGrantedProducts, err = service.repo.FindOrderGrantedProductsForContact(ctx, tx, grantedProduct.ContactID)
+		if err != nil {
+			return
+		}
+		contactsGrantedProducts = append(contactsGrantedProducts, contactGrantedProducts...)
+	}
+
+	for _, access := range accessesToRevokeForRefundedOrder(order.ID, orderGrantedProducts, contactsGrantedProducts) {
+		err = service.repo.DeleteAccessToProduct(ctx, tx, access)
+		if err != nil {
+			return
+		}
+	}
+
+	// a refunded order no longer gives access to its products
+	err = service.repo.DeleteOrderGrantedProducts(ctx, tx, order.ID)
+	if err != nil {
+		return
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func TestAccessesToRevokeForRefundedOrder(t *testing.T) {
+	contactID := guid.NewTimeBased()
+	bundleID := guid.NewTimeBased()
+	// the bundle included book and course when it was ordered, course was removed from the bundle since
+	bookID := guid.NewTimeBased()
+	courseID := guid.NewTimeBased()
+	refundedOrderID := guid.NewTimeBased()
+	otherOrderID := guid.NewTimeBased()
+
+	orderGrantedProducts := []store.OrderGrantedProduct{
+		{OrderID: refundedOrderID, ProductID: bundleID, ContactID: contactID},
+		{OrderID: refundedOrderID, ProductID: bookID, BundleID: &bundleID, ContactID: contactID},
+		{OrderID: refundedOrderID, ProductID: courseID, BundleID: &bundleID, ContactID: contactID},
+	}
+	// the book was also bought alone
+	contactGrantedProducts := append([]store.OrderGrantedProduct{
+		{OrderID: otherOrderID, ProductID: bookID, ContactID: contactID},
+	}, orderGrantedProducts...)
+
+	accesses := accessesToRevokeForRefundedOrder(refundedOrderID, orderGrantedProducts, contactGrantedProducts)
+	if len(accesses) != 2 {
+		t.Fatalf("expected 2 accesses to revoke, got %d", len(accesses))
+	}
+	if !accesses[0].ProductID.Equal(bundleID) || !accesses[1].ProductID.Equal(courseID) {
+		t.Errorf("expected the accesses to the bundle and the course to be revoked, got: %v and %v",
+			accesses[0].ProductID, accesses[1].ProductID)
+	}
+	for _, access := range accesses {
+		if !access.ContactID.Equal(contactID) {
+			t.Errorf("expected the access of the contact to be revoked, got: %v", access.ContactID)
+		}
+	}
+}
+
+func TestRefundAfterTransfer(t *testing.T) {
+	buyerID := guid.NewTimeBased()
+	newHolderID := guid.NewTimeBased()
+	bundle := store.Product{ID: guid.NewTimeBased(), Type: store.ProductTypeBundle}
+	bookID := guid.NewTimeBased()
+	orderID := guid.NewTimeBased()
+
+	orderGrantedProducts := []store.OrderGrantedProduct{
+		{ID: guid.NewTimeBased(), OrderID: orderID, ProductID: bundle.ID, ContactID: buyerID},
+		{ID: guid.NewTimeBased(), OrderID: orderID, ProductID: bookID, BundleID: &bundle.ID, ContactID: buyerID},
+	}
+
+	// the access to the bundle is transferred to another contact
+	transferredProducts := transferGrantedProducts(grantedProductsToTransfer(bundle, orderGrantedProducts), newHolderID)
+	if len(transferredProducts) != 2 {
+		t.Fatalf("expected the bundle and the book to be transferred, got %d products", len(transferredProducts))
+	}
+
+	// then the order is refunded
+	accesses := accessesToRevokeForRefundedOrder(orderID, transferredProducts, transferredProducts)
+	if len(accesses) != 2 {
+		t.Fatalf("expected 2 accesses to revoke, got %d", len(accesses))
+	}
+	for _, access := range accesses {
+		if !access.ContactID.Equal(newHolderID) {
+			t.Errorf("expected the access of the new holder to be revoked, got: %v", access.ContactID)
+		}
+	}
+}
+
+func TestProductsToTransfer(t *testing.T) {
+	var service StoreService
+	contactID := guid.NewTimeBased()
+	bundle := store.Product{ID: guid.NewTimeBased(), Type: store.ProductTypeBundle}
+	bookID := guid.NewTimeBased()
+	courseID := guid.NewTimeBased()
+	orderID := guid.NewTimeBased()
+
+	grantedProducts := []store.OrderGrantedProduct{
+		{OrderID: orderID, ProductID: bundle.ID, ContactID: contactID},
+		{OrderID: orderID, ProductID: bookID, BundleID: &bundle.ID, ContactID: contactID},
+		// the course was bought alone in the same order
+		{OrderID: orderID, ProductID: courseID, ContactID: contactID},
+	}
+
+	productsIDs, err := service.productsToTransfer(t.Context(), nil, bundle, grantedProductsToTransfer(bundle, grantedProducts))
+	if err != nil {
+		t.Fatalf("getting products to transfer: %v", err)
+	}
+	if len(productsIDs) != 2 || !productsIDs[0].Equal(bundle.ID) || !productsIDs[1].Equal(bookID) {
+		t.Errorf("expected the bundle and the book to be transferred, got: %v", productsIDs)
+	}
+}
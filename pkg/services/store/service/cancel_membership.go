@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/retry"
+	"markdown.ninja/pkg/payments"
+	"markdown.ninja/pkg/services/store"
+)
+
+// CancelMembership immediately cancels a membership, and its subscription if any.
+func (service *StoreService) CancelMembership(ctx context.Context, input store.CancelMembershipInput) (membership store.Membership, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	membership, err = service.repo.FindMembershipByID(ctx, service.db, input.ID, false)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, membership.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	if membership.Status == store.MembershipStatusCanceled {
+		err = store.ErrMembershipIsAlreadyCanceled
+		return
+	}
+
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		membership, txErr = service.repo.FindMembershipByID(ctx, tx, input.ID, true)
+		if txErr != nil {
+			return txErr
+		}
+
+		now := time.Now().UTC()
+
+		if membership.StripeSubscriptionID != nil {
+			var subscription payments.Subscription
+			txErr = retry.Do(func() (retryErr error) {
+				subscription, retryErr = service.paymentProvider.CancelSubscription(ctx, *membership.StripeSubscriptionID)
+				return retryErr
+			}, retry.Context(ctx), retry.Attempts(3), retry.Delay(50*time.Millisecond))
+			if txErr != nil {
+				return fmt.Errorf("store.CancelMembership: error canceling subscription [%s]: %w", *membership.StripeSubscriptionID, txErr)
+			}
+
+			applySubscriptionToMembership(&membership, subscription, now)
+		} else {
+			membership.UpdatedAt = now
+			membership.Status = store.MembershipStatusCanceled
+			membership.CanceledAt = &now
+		}
+
+		return service.repo.UpdateMembership(ctx, tx, membership)
+	})
+	if err != nil {
+		return
+	}
+
+	memberships := []store.Membership{membership}
+	err = service.hydrateMemberships(ctx, service.db, memberships)
+	if err != nil {
+		return
+	}
+	membership = memberships[0]
+
+	return
+}
@@ -46,6 +46,11 @@ func (service *StoreService) CancelOrder(ctx context.Context, input store.Cancel
 			return txErr
 		}
 
+		txErr = service.releaseCouponUse(ctx, tx, order)
+		if txErr != nil {
+			return txErr
+		}
+
 		service.eventsService.TrackOrderCanceled(ctx, events.TrackOrderCanceledInput{
 			OrderID:   order.ID,
 			WebsiteID: order.WebsiteID,
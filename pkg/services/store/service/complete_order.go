@@ -7,16 +7,14 @@ import (
 
 	"log/slog"
 
-	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/guid"
 	"github.com/skerkour/stdx-go/log/slogx"
 	"github.com/skerkour/stdx-go/queue"
 	"github.com/skerkour/stdx-go/retry"
-	"github.com/stripe/stripe-go/v81"
-	"github.com/stripe/stripe-go/v81/checkout/session"
-	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/contacts"
+	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/events"
 	"markdown.ninja/pkg/services/store"
 )
@@ -70,40 +68,30 @@ func (service *StoreService) completeOrder(ctx context.Context, orderID guid.GUI
 		contactSubscribedToNewsletter = true
 	}
 
-	var stripeCheckoutSession *stripe.CheckoutSession
-	getStripeCheckoutSessionParam := stripe.CheckoutSessionParams{}
-	getStripeCheckoutSessionParam.AddExpand("payment_intent")
-	getStripeCheckoutSessionParam.AddExpand("payment_intent.invoice")
-	getStripeCheckoutSessionParam.AddExpand("customer")
-	getStripeCheckoutSessionParam.AddExpand("line_items")
-	getStripeCheckoutSessionParam.AddExpand("line_items.data.price.product")
-
+	var checkoutSession payments.CheckoutSession
 	err = retry.Do(func() (retryErr error) {
-		stripeCheckoutSession, retryErr = session.Get(
-			order.StripeCheckoutSessionID,
-			&getStripeCheckoutSessionParam,
-		)
+		checkoutSession, retryErr = service.paymentProvider.GetCheckoutSession(ctx, order.StripeCheckoutSessionID)
 		return retryErr
 	}, retry.Context(ctx), retry.Attempts(3), retry.Delay(20*time.Millisecond))
 	if err != nil {
-		return fmt.Errorf("store.completeOrder: error getting stripe checkout session: %w", err)
+		return fmt.Errorf("store.completeOrder: error getting checkout session: %w", err)
 	}
 
 	if order.Status == store.OrderStatusCompleted {
-		// if order is already completed but stripe invoice is not saved yet
+		// if order is already completed but invoice is not saved yet
 		if order.StripeInvoiceID == nil || order.StripeInvoiceUrl == nil ||
 			(order.StripeInvoiceUrl != nil && *order.StripeInvoiceUrl == "") {
-			if stripeCheckoutSession.PaymentIntent != nil && stripeCheckoutSession.PaymentIntent.Invoice != nil {
+			if invoice := checkoutSession.Invoice; invoice != nil {
 				order.UpdatedAt = now
-				order.StripeInvoiceID = &stripeCheckoutSession.PaymentIntent.Invoice.ID
-				order.StripeInvoiceUrl = &stripeCheckoutSession.PaymentIntent.Invoice.HostedInvoiceURL
+				order.StripeInvoiceID = &invoice.ID
+				order.StripeInvoiceUrl = &invoice.URL
 				err = service.repo.UpdateOrder(ctx, tx, order)
 				if err != nil {
 					return err
 				}
 				err = tx.Commit()
 				if err != nil {
-					return fmt.Errorf("store.completeOrder: Comitting DB transaction (StripeInvoice) for order [%s]: %w", orderID.String(), err)
+					return fmt.Errorf("store.completeOrder: Comitting DB transaction (Invoice) for order [%s]: %w", orderID.String(), err)
 				}
 			}
 		}
@@ -112,8 +100,8 @@ func (service *StoreService) completeOrder(ctx context.Context, orderID guid.GUI
 		return nil
 	}
 
-	if stripeCheckoutSession.Status == stripe.CheckoutSessionStatusExpired ||
-		(stripeCheckoutSession.PaymentIntent != nil && stripeCheckoutSession.PaymentIntent.Status == stripe.PaymentIntentStatusCanceled) {
+	if checkoutSession.Status == payments.CheckoutSessionStatusExpired ||
+		(checkoutSession.Payment != nil && checkoutSession.Payment.Status == payments.PaymentStatusCanceled) {
 
 		if order.Status == store.OrderStatusCanceled {
 			// do nothing
@@ -126,9 +114,9 @@ func (service *StoreService) completeOrder(ctx context.Context, orderID guid.GUI
 		if order.CanceledAt == nil {
 			order.CanceledAt = &now
 		}
-		if stripeCheckoutSession.PaymentIntent != nil {
-			order.StripPaymentItentID = &stripeCheckoutSession.PaymentIntent.ID
-			order.TotalAmount = stripeCheckoutSession.PaymentIntent.Amount
+		if checkoutSession.Payment != nil {
+			order.StripPaymentItentID = &checkoutSession.Payment.ID
+			order.TotalAmount = checkoutSession.Payment.Amount
 		}
 
 		err = service.repo.UpdateOrder(ctx, tx, order)
@@ -136,6 +124,11 @@ func (service *StoreService) completeOrder(ctx context.Context, orderID guid.GUI
 			return fmt.Errorf("store.completeOrder: updating order (CheckoutSessionStatusExpired): %w", err)
 		}
 
+		err = service.releaseCouponUse(ctx, tx, order)
+		if err != nil {
+			return fmt.Errorf("store.completeOrder: releasing coupon use (CheckoutSessionStatusExpired): %w", err)
+		}
+
 		err = tx.Commit()
 		if err != nil {
 			return fmt.Errorf("store.completeOrder: Comitting DB transaction (CheckoutSessionStatusExpired) for order [%s]: %w", orderID.String(), err)
@@ -150,55 +143,60 @@ func (service *StoreService) completeOrder(ctx context.Context, orderID guid.GUI
 		return nil
 	}
 
-	var stripeCustomerID *string
-	if stripeCheckoutSession.Customer != nil {
-		stripeCustomerID = &stripeCheckoutSession.Customer.ID
-	}
-
-	if stripeCheckoutSession.CustomerDetails != nil && stripeCheckoutSession.CustomerDetails.Address != nil &&
-		stripeCheckoutSession.CustomerDetails.Address.Country != "" {
-		country = stripeCheckoutSession.CustomerDetails.Address.Country
+	if checkoutSession.CustomerCountry != "" {
+		country = checkoutSession.CustomerCountry
 	} else {
-		if stripeCheckoutSession.CustomerDetails == nil {
-			logger.Warn("store.completeOrder: stripeCheckoutSession.CustomerDetails is null")
-		} else if stripeCheckoutSession.CustomerDetails != nil && stripeCheckoutSession.CustomerDetails.Address == nil {
-			logger.Warn("store.completeOrder: stripeCheckoutSession.CustomerDetails.Address is null")
-		}
+		logger.Warn("store.completeOrder: checkoutSession.CustomerCountry is empty")
 	}
 
 	checkoutSessionLogArgs := []any{}
-	if stripeCheckoutSession.PaymentIntent != nil {
-		checkoutSessionLogArgs = append(checkoutSessionLogArgs, slog.Group("payment_intent",
-			slog.String("id", stripeCheckoutSession.PaymentIntent.ID),
-			slog.String("status", string(stripeCheckoutSession.PaymentIntent.Status)),
-			slog.Bool("invoice_is_present", stripeCheckoutSession.PaymentIntent.Invoice != nil),
-			slog.Bool("customer_is_present", stripeCheckoutSession.Customer != nil),
+	if checkoutSession.Payment != nil {
+		checkoutSessionLogArgs = append(checkoutSessionLogArgs, slog.Group("payment",
+			slog.String("id", checkoutSession.Payment.ID),
+			slog.String("status", string(checkoutSession.Payment.Status)),
+			slog.Bool("invoice_is_present", checkoutSession.Invoice != nil),
+			slog.Bool("customer_is_present", checkoutSession.CustomerID != nil),
 		))
 	} else {
-		checkoutSessionLogArgs = append(checkoutSessionLogArgs, slog.Any("payment_intent", nil))
+		checkoutSessionLogArgs = append(checkoutSessionLogArgs, slog.Any("payment", nil))
 	}
 
-	logger.Debug("store.completeOrder: successfully fetched stripe.CheckoutSession", checkoutSessionLogArgs...)
+	logger.Debug("store.completeOrder: successfully fetched checkout session", checkoutSessionLogArgs...)
 
-	if stripeCheckoutSession.PaymentIntent == nil {
-		return nil
-	}
+	if checkoutSession.Mode == payments.CheckoutSessionModeSubscription {
+		// memberships: there is no payment in subscription mode
+		if checkoutSession.Subscription == nil {
+			return nil
+		}
 
-	if stripeCheckoutSession.PaymentIntent.Status != stripe.PaymentIntentStatusSucceeded {
-		// return store.ErrOrderIsNotCompleted
-		logger.Error(fmt.Sprintf("store.completeOrder: invalid Stripe payment intent status: %s. expected: succeeded", stripeCheckoutSession.PaymentIntent.Status))
-		return nil
+		if !checkoutSession.Paid {
+			logger.Error("store.completeOrder: checkout session is not paid")
+			return nil
+		}
+
+		order.TotalAmount = checkoutSession.AmountTotal / 100
+	} else {
+		if checkoutSession.Payment == nil {
+			return nil
+		}
+
+		if checkoutSession.Payment.Status != payments.PaymentStatusSucceeded {
+			// return store.ErrOrderIsNotCompleted
+			logger.Error(fmt.Sprintf("store.completeOrder: invalid payment status: %s. expected: succeeded", checkoutSession.Payment.Status))
+			return nil
+		}
+
+		order.StripPaymentItentID = &checkoutSession.Payment.ID
+		order.TotalAmount = checkoutSession.Payment.Amount / 100
 	}
 
 	order.UpdatedAt = now
-	order.StripPaymentItentID = &stripeCheckoutSession.PaymentIntent.ID
-	order.TotalAmount = stripeCheckoutSession.PaymentIntent.Amount / 100
 	order.Country = country
 	order.CompletedAt = &now
 	order.Status = store.OrderStatusCompleted
-	if stripeCheckoutSession.PaymentIntent.Invoice != nil {
-		order.StripeInvoiceID = &stripeCheckoutSession.PaymentIntent.Invoice.ID
-		order.StripeInvoiceUrl = &stripeCheckoutSession.PaymentIntent.Invoice.HostedInvoiceURL
+	if invoice := checkoutSession.Invoice; invoice != nil {
+		order.StripeInvoiceID = &invoice.ID
+		order.StripeInvoiceUrl = &invoice.URL
 	}
 
 	products, err := service.repo.FindProductsForOrder(ctx, tx, order.ID)
@@ -212,8 +210,8 @@ func (service *StoreService) completeOrder(ctx context.Context, orderID guid.GUI
 	}
 
 	orderedProductsQuantity := make(map[string]int64, len(products))
-	for _, lineItem := range stripeCheckoutSession.LineItems.Data {
-		orderedProductsQuantity[lineItem.Price.Product.Metadata["markdown_ninja_product_id"]] = lineItem.Quantity
+	for _, lineItem := range checkoutSession.LineItems {
+		orderedProductsQuantity[lineItem.Metadata["markdown_ninja_product_id"]] = lineItem.Quantity
 	}
 
 	orderLineItems, err := service.repo.FindOrderLineItems(ctx, tx, order.ID)
@@ -239,47 +237,98 @@ func (service *StoreService) completeOrder(ctx context.Context, orderID guid.GUI
 		ID:               contact.ID,
 		Verified:         new(true),
 		Country:          &country,
-		StripeCustomerID: stripeCustomerID,
+		StripeCustomerID: checkoutSession.CustomerID,
 	}
 	err = service.contactsService.UpdateContactInternal(ctx, tx, &contact, updateContactInput)
 	if err != nil {
 		return err
 	}
 
+	// gifts: the products are given to the recipient, whose contact is created if needed
+	if order.GiftRecipientEmail != nil && order.GiftRecipientContactID == nil {
+		var recipient contacts.Contact
+		recipient, err = service.findOrCreateVerifiedContact(ctx, tx, order.WebsiteID, *order.GiftRecipientEmail)
+		if err != nil {
+			return err
+		}
+		order.GiftRecipientContactID = &recipient.ID
+	}
+
 	err = service.repo.UpdateOrder(ctx, tx, order)
 	if err != nil {
 		return err
 	}
 
-	// give the customer access to the purchased products
+	// give the customer (or the recipient of the gift) access to the purchased products
 	for _, product := range products {
-		_, err = service.repo.FindContactProductAccess(ctx, tx, order.ContactID, product.ID)
-		if err == nil {
-			// if contact already has access to product we don't need to create product-access relation
-			continue
-		} else {
-			if !errs.IsNotFound(err) {
-				return err
+		if product.Type == store.ProductTypeMembership {
+			// memberships give access to members-only content as long as the subscription is active
+			if checkoutSession.Subscription != nil {
+				_, err = service.syncMembershipWithSubscription(ctx, tx, order.WebsiteID, order.ContactID,
+					product.ID, *checkoutSession.Subscription)
+				if err != nil {
+					return err
+				}
 			}
-			err = nil
 		}
+	}
 
-		contactProductAccess := store.ContactProductAccess{
-			CreatedAt: now,
-			ContactID: order.ContactID,
-			ProductID: product.ID,
+	// bundles give access to all the products they include. The granted products are saved with the
+	// order as the bundles may be edited after the order.
+	grantedProducts, err := service.grantedProductsForOrder(ctx, tx, order, products, now)
+	if err != nil {
+		return err
+	}
+	for _, grantedProduct := range grantedProducts {
+		err = service.repo.CreateOrderGrantedProduct(ctx, tx, grantedProduct)
+		if err != nil {
+			return err
 		}
-		err = service.repo.CreateContactProductAccess(ctx, tx, contactProductAccess)
+	}
+
+	productsToGiveAccessTo := grantedProductsIDs(grantedProducts)
+	for _, productID := range productsToGiveAccessTo {
+		err = service.giveContactAccessToProduct(ctx, tx, orderBeneficiaryContactID(order), productID, now)
 		if err != nil {
-			if db.IsErrAlreadyExists(err) {
-				// nothing has to be done if contact already has access to product
-				err = nil
-			} else {
-				return err
-			}
+			return err
 		}
 	}
 
+	err = service.issueLicenseKeys(ctx, tx, order, productsToGiveAccessTo, now)
+	if err != nil {
+		return err
+	}
+
+	err = service.emailsService.TriggerSequences(ctx, tx, emails.TriggerSequencesInput{
+		WebsiteID:  order.WebsiteID,
+		ContactID:  orderBeneficiaryContactID(order),
+		Trigger:    emails.SequenceTriggerProductPurchased,
+		ProductIDs: productsToGiveAccessTo,
+	})
+	if err != nil {
+		return err
+	}
+	if contactSubscribedToNewsletter {
+		err = service.emailsService.TriggerSequences(ctx, tx, emails.TriggerSequencesInput{
+			WebsiteID: order.WebsiteID,
+			ContactID: order.ContactID,
+			Trigger:   emails.SequenceTriggerSubscribedToNewsletter,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// invoices are issued in the background as their documents need to be rendered and uploaded
+	err = service.queue.Push(ctx, tx, queue.NewJobInput{
+		Data: store.JobGenerateInvoice{
+			OrderID: order.ID,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("store.completeOrder: pushing JobGenerateInvoice to queue: %w", err)
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return fmt.Errorf("store.completeOrder: Comitting DB transaction for order [%s]: %w", orderID.String(), err)
@@ -298,6 +347,21 @@ func (service *StoreService) completeOrder(ctx context.Context, orderID guid.GUI
 			logger.Error("store.completeOrder: error pushing job JobSendOrderConfirmationEmail to queue", slogx.Err(errQueue))
 			return
 		}
+
+		if order.GiftRecipientContactID != nil {
+			job = queue.NewJobInput{
+				Data: store.JobSendGiftNotificationEmail{
+					OrderID: order.ID,
+				},
+				RetryDelay: new(int64(120)),
+				RetryMax:   new(int64(10)),
+			}
+			errQueue = service.queue.Push(context.Background(), nil, job)
+			if errQueue != nil {
+				logger.Error("store.completeOrder: error pushing job JobSendGiftNotificationEmail to queue", slogx.Err(errQueue))
+				return
+			}
+		}
 	}()
 
 	service.eventsService.TrackOrderCompleted(ctx, events.TrackOrderCompletedInput{
@@ -306,6 +370,14 @@ func (service *StoreService) completeOrder(ctx context.Context, orderID guid.GUI
 		TotalAmount: order.TotalAmount,
 	})
 
+	if order.RecoveryEmailSentAt != nil {
+		service.eventsService.TrackOrderRecovered(ctx, events.TrackOrderRecoveredInput{
+			OrderID:     order.ID,
+			WebsiteID:   order.WebsiteID,
+			TotalAmount: order.TotalAmount,
+		})
+	}
+
 	if contactSubscribedToNewsletter {
 		service.eventsService.TrackSubscribedToNewsletter(ctx, events.TrackSubscribedToNewsletterInput{
 			WebsiteID: order.WebsiteID,
@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/errs"
 	"markdown.ninja/pkg/services/store"
 )
 
@@ -97,3 +101,93 @@ func (service *StoreService) associateProductsToCoupon(ctx context.Context, db d
 
 	return
 }
+
+// findCouponForOrder finds a coupon by its code and checks that it can be used. The uses limit is
+// enforced atomically when the order is placed (see StoreRepository.IncrementCouponUses).
+func (service *StoreService) findCouponForOrder(ctx context.Context, db db.Queryer, websiteID guid.GUID, code string) (coupon store.Coupon, err error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if service.validateCouponCode(code) != nil {
+		err = store.ErrCouponIsNotValid
+		return
+	}
+
+	coupon, err = service.repo.FindCouponByCodeForWebsite(ctx, db, websiteID, code)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			err = store.ErrCouponIsNotValid
+		}
+		return
+	}
+
+	if coupon.ArchivedAt != nil {
+		err = store.ErrCouponIsNotValid
+		return
+	}
+
+	if coupon.ExpiresAt != nil && coupon.ExpiresAt.Before(time.Now().UTC()) {
+		err = store.ErrCouponHasExpired
+		return
+	}
+
+	if coupon.UsesLimit != 0 && coupon.Uses >= coupon.UsesLimit {
+		err = store.ErrCouponUsesLimitReached
+		return
+	}
+
+	err = service.hydrateCoupon(ctx, db, &coupon)
+	return
+}
+
+// orderPricing is the price of a set of products, after applying an optional coupon
+type orderPricing struct {
+	lineItems []pricedLineItem
+	subtotal  int64
+	discount  int64
+	total     int64
+}
+
+type pricedLineItem struct {
+	product store.Product
+	// discount is deducted from the unit price of the product
+	discount int64
+}
+
+// priceOrder computes the price of the products after applying the coupon, if any.
+// The coupon only applies to the products it is restricted to, and discounts are rounded to the
+// nearest unit of currency but never cover the whole price of a product, as orders can't be free.
+func priceOrder(products []store.Product, coupon *store.Coupon) (pricing orderPricing, err error) {
+	pricing.lineItems = make([]pricedLineItem, len(products))
+	couponApplied := false
+
+	for i, product := range products {
+		var discount int64
+		if coupon != nil && slices.ContainsFunc(coupon.Products, product.ID.Equal) {
+			discount = min((product.Price*coupon.Discount+50)/100, max(product.Price-1, 0))
+			couponApplied = true
+		}
+
+		pricing.lineItems[i] = pricedLineItem{
+			product:  product,
+			discount: discount,
+		}
+		pricing.subtotal += product.Price
+		pricing.discount += discount
+	}
+
+	if coupon != nil && !couponApplied {
+		err = store.ErrCouponDoesNotApplyToProducts
+		return
+	}
+
+	pricing.total = pricing.subtotal - pricing.discount
+	return
+}
+
+// releaseCouponUse releases the use of the coupon reserved by an order when the order is canceled
+func (service *StoreService) releaseCouponUse(ctx context.Context, db db.Queryer, order store.Order) (err error) {
+	if order.CouponID == nil {
+		return nil
+	}
+
+	return service.repo.DecrementCouponUses(ctx, db, *order.CouponID)
+}
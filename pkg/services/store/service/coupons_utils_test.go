@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func TestPriceOrder(t *testing.T) {
+	book := store.Product{ID: guid.NewTimeBased(), Name: "Book", Price: 25}
+	course := store.Product{ID: guid.NewTimeBased(), Name: "Course", Price: 99}
+
+	pricing, err := priceOrder([]store.Product{book, course}, nil)
+	if err != nil {
+		t.Fatalf("pricing order without coupon: %v", err)
+	}
+	if pricing.subtotal != 124 || pricing.discount != 0 || pricing.total != 124 {
+		t.Errorf("without coupon: got subtotal=%d discount=%d total=%d", pricing.subtotal, pricing.discount, pricing.total)
+	}
+
+	// the coupon only applies to the course: 15% of 99 = 14.85, rounded to 15
+	coupon := store.Coupon{Discount: 15, Products: []guid.GUID{course.ID}}
+	pricing, err = priceOrder([]store.Product{book, course}, &coupon)
+	if err != nil {
+		t.Fatalf("pricing order with coupon: %v", err)
+	}
+	if pricing.subtotal != 124 || pricing.discount != 15 || pricing.total != 109 {
+		t.Errorf("with coupon: got subtotal=%d discount=%d total=%d", pricing.subtotal, pricing.discount, pricing.total)
+	}
+	if pricing.lineItems[0].discount != 0 || pricing.lineItems[1].discount != 15 {
+		t.Errorf("with coupon: got line items discounts %d and %d", pricing.lineItems[0].discount, pricing.lineItems[1].discount)
+	}
+
+	_, err = priceOrder([]store.Product{book}, &coupon)
+	if !errors.Is(err, store.ErrCouponDoesNotApplyToProducts) {
+		t.Errorf("coupon not applying to products: expected ErrCouponDoesNotApplyToProducts, got: %v", err)
+	}
+
+	// 99% of 1 = 0.99 would be rounded to 1, which would make the product free
+	cheap := store.Product{ID: guid.NewTimeBased(), Name: "Cheap", Price: 1}
+	coupon = store.Coupon{Discount: 99, Products: []guid.GUID{book.ID, cheap.ID}}
+	pricing, err = priceOrder([]store.Product{book, cheap}, &coupon)
+	if err != nil {
+		t.Fatalf("pricing order with 99%% coupon: %v", err)
+	}
+	if pricing.lineItems[0].discount != 24 || pricing.lineItems[1].discount != 0 || pricing.total != 2 {
+		t.Errorf("with 99%% coupon: got line items discounts %d and %d and total=%d",
+			pricing.lineItems[0].discount, pricing.lineItems[1].discount, pricing.total)
+	}
+
+	// coupons created before 100% discounts were rejected
+	coupon = store.Coupon{Discount: 100, Products: []guid.GUID{book.ID}}
+	pricing, err = priceOrder([]store.Product{book}, &coupon)
+	if err != nil {
+		t.Fatalf("pricing order with 100%% coupon: %v", err)
+	}
+	if pricing.total != 1 {
+		t.Errorf("with 100%% coupon: expected total 1, got %d", pricing.total)
+	}
+}
@@ -79,6 +79,11 @@ func (service *StoreService) CreateCoupon(ctx context.Context, input store.Creat
 		return
 	}
 
+	err = service.validateCouponUsesLimit(input.UsesLimit)
+	if err != nil {
+		return
+	}
+
 	if input.Products == nil {
 		err = store.ErrProductNotFound
 		return
@@ -101,8 +106,9 @@ func (service *StoreService) CreateCoupon(ctx context.Context, input store.Creat
 		Code:        code,
 		ExpiresAt:   expiresAt,
 		Discount:    discount,
-		UsesLimit:   0,
-		ArchivedAt:  expiresAt,
+		UsesLimit:   input.UsesLimit,
+		Uses:        0,
+		ArchivedAt:  nil,
 		Description: description,
 		WebsiteID:   input.WebsiteID,
 	}
@@ -58,6 +58,36 @@ func (service *StoreService) CreateProduct(ctx context.Context, input store.Crea
 		return
 	}
 
+	website, err := service.websitesService.FindWebsiteByID(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	prices := input.Prices
+	if prices == nil {
+		prices = store.ProductPrices{}
+	}
+	err = service.validateProductPrices(website.Currency, prices)
+	if err != nil {
+		return
+	}
+
+	var billingInterval *store.BillingInterval
+	if productType == store.ProductTypeMembership {
+		if input.BillingInterval == nil {
+			err = store.ErrBillingIntervalIsNotValid
+			return
+		}
+		err = service.validateBillingInterval(*input.BillingInterval)
+		if err != nil {
+			return
+		}
+		billingInterval = input.BillingInterval
+	} else if input.BillingInterval != nil {
+		err = store.ErrBillingIntervalIsOnlyForMemberships
+		return
+	}
+
 	product = store.Product{
 		ID:          guid.NewTimeBased(),
 		CreatedAt:   now,
@@ -68,6 +98,26 @@ func (service *StoreService) CreateProduct(ctx context.Context, input store.Crea
 		Status:      store.ProductStatusDraft,
 		Price:       price,
 		WebsiteID:   input.WebsiteID,
+
+		BillingInterval: billingInterval,
+		Prices:          prices,
+		PayWhatYouWant:  input.PayWhatYouWant,
+		LicenseKeys:     store.LicenseKeysModeNone,
+	}
+
+	err = service.validatePayWhatYouWantPrices(product)
+	if err != nil {
+		return
+	}
+
+	if productType == store.ProductTypeBundle {
+		err = service.validateBundleItems(ctx, service.db, product, input.BundleItems)
+		if err != nil {
+			return
+		}
+	} else if len(input.BundleItems) != 0 {
+		err = store.ErrBundleItemsAreOnlyForBundles
+		return
 	}
 
 	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
@@ -81,6 +131,13 @@ func (service *StoreService) CreateProduct(ctx context.Context, input store.Crea
 			return txErr
 		}
 
+		if product.Type == store.ProductTypeBundle {
+			txErr = service.setBundleItems(ctx, tx, &product, input.BundleItems)
+			if txErr != nil {
+				return txErr
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -71,6 +71,11 @@ func (service *StoreService) CreateProductPage(ctx context.Context, input store.
 		return
 	}
 
+	err = service.validateProductPageDripDays(input.DripDays)
+	if err != nil {
+		return
+	}
+
 	position, err := service.repo.GetProductPagesCountForProduct(ctx, service.db, product.ID)
 	if err != nil {
 		return
@@ -85,6 +90,7 @@ func (service *StoreService) CreateProductPage(ctx context.Context, input store.
 		Size:         size,
 		Hash:         bodyHash[:],
 		BodyMarkdown: bodyMarkdown,
+		DripDays:     input.DripDays,
 		ProductID:    product.ID,
 	}
 	err = service.repo.CreateProductPage(ctx, service.db, page)
@@ -92,5 +98,7 @@ func (service *StoreService) CreateProductPage(ctx context.Context, input store.
 		return
 	}
 
+	service.scheduleProductEbooksGeneration(ctx, product)
+
 	return
 }
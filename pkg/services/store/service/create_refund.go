@@ -50,7 +50,7 @@ func (service *StoreService) CreateRefund(ctx context.Context, input store.Creat
 	}
 
 	// As of now, we allow only 1 refund per order
-	if len(previousRefundsForOrder) == 0 {
+	if len(previousRefundsForOrder) != 0 {
 		err = store.ErrOrderAlreadyRefunded
 		return
 	}
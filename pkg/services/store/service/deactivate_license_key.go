@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/skerkour/stdx-go/db"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/store"
+)
+
+// DeactivateLicenseKey frees an activation of a license key, e.g. when a customer moves to a new machine
+func (service *StoreService) DeactivateLicenseKey(ctx context.Context, input store.DeactivateLicenseKeyInput) (verification store.LicenseKeyVerification, err error) {
+	httpCtx := httpctx.FromCtx(ctx)
+
+	instance := strings.TrimSpace(input.Instance)
+	err = service.validateLicenseKeyInstance(instance)
+	if err != nil {
+		return
+	}
+
+	website, err := service.websitesService.FindWebsiteByDomain(ctx, service.db, httpCtx.Hostname)
+	if err != nil {
+		return
+	}
+
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		product, licenseKey, txErr := service.findLicenseKeyForWebsite(ctx, tx, website.ID, input.ProductID, input.Key, true)
+		if txErr != nil {
+			return txErr
+		}
+
+		txErr = service.repo.DeleteLicenseKeyActivation(ctx, tx, licenseKey.ID, instance)
+		if txErr != nil {
+			return txErr
+		}
+
+		verification, txErr = service.licenseKeyVerification(ctx, tx, product, licenseKey, &instance)
+		return txErr
+	})
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -69,5 +69,7 @@ func (service *StoreService) DeleteProductPage(ctx context.Context, input store.
 		return
 	}
 
+	service.scheduleProductEbooksGeneration(ctx, product)
+
 	return
 }
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/queue"
+	"github.com/zeebo/blake3"
+	"markdown.ninja/pkg/services/content"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) ScheduleProductEbooksGeneration(ctx context.Context, db db.Queryer, productID guid.GUID) (err error) {
+	product, err := service.repo.FindProductByID(ctx, db, productID)
+	if err != nil {
+		return
+	}
+
+	service.scheduleProductEbooksGeneration(ctx, product)
+	return nil
+}
+
+// scheduleProductEbooksGeneration enqueues a job to regenerate the ebooks of the product if it's a book.
+// Errors are only logged as ebooks are always regenerated on the next change.
+func (service *StoreService) scheduleProductEbooksGeneration(ctx context.Context, product store.Product) {
+	logger := slogx.FromCtx(ctx)
+
+	if product.Type != store.ProductTypeBook {
+		return
+	}
+
+	job := queue.NewJobInput{
+		Data: store.JobGenerateProductEbooks{
+			ProductID: product.ID,
+		},
+		RetryDelay: new(int64(60)),
+		RetryMax:   new(int64(5)),
+	}
+	err := service.queue.Push(ctx, nil, job)
+	if err != nil {
+		logger.Error("store.scheduleProductEbooksGeneration: error pushing job to queue",
+			slog.String("product.id", product.ID.String()), slogx.Err(err))
+	}
+}
+
+func (service *StoreService) getProductEbookStorageKey(websiteID guid.GUID, ebook store.ProductEbook) string {
+	return filepath.Join(content.WebsitesStorageBasePath, websiteID.String(), "products", ebook.ProductID.String(),
+		"ebooks", fmt.Sprintf("%s.%s", ebook.ContentHash.String(), ebook.Format))
+}
+
+// computeProductEbookContentHash returns a hash of all the data used to generate the ebooks of a product,
+// so we can skip the generation when nothing has changed.
+func computeProductEbookContentHash(websiteName string, product store.Product, pages []store.ProductPage, assets []content.Asset) []byte {
+	var hash [32]byte
+	hasher := blake3.New()
+
+	writeString := func(input string) {
+		var length [8]byte
+		binary.LittleEndian.PutUint64(length[:], uint64(len(input)))
+		hasher.Write(length[:])
+		hasher.Write([]byte(input))
+	}
+
+	writeString(websiteName)
+	writeString(product.Name)
+	writeString(product.Description)
+	for _, page := range pages {
+		writeString(page.ID.String())
+		writeString(page.Title)
+		hasher.Write(page.Hash)
+	}
+	for _, asset := range assets {
+		writeString(asset.ID.String())
+		writeString(asset.Name)
+		hasher.Write(asset.Hash)
+	}
+
+	hasher.Sum(hash[:0])
+	return hash[:]
+}
+
+func (service *StoreService) hydrateProductsEbooks(ctx context.Context, db db.Queryer, products []store.Product) (err error) {
+	bookIDs := make([]guid.GUID, 0, len(products))
+	for _, product := range products {
+		if product.Type == store.ProductTypeBook {
+			bookIDs = append(bookIDs, product.ID)
+		}
+	}
+
+	ebooks, err := service.repo.FindProductEbooksForProducts(ctx, db, bookIDs)
+	if err != nil {
+		return
+	}
+
+	for i := range products {
+		products[i].Ebooks = make([]store.ProductEbook, 0)
+		for _, ebook := range ebooks {
+			if ebook.ProductID.Equal(products[i].ID) {
+				products[i].Ebooks = append(products[i].Ebooks, ebook)
+			}
+		}
+	}
+
+	return nil
+}
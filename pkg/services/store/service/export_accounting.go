@@ -0,0 +1,170 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/skerkour/stdx-go/log/slogx"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/invoicing"
+	"markdown.ninja/pkg/services/store"
+)
+
+// ExportAccounting exports the completed orders, the refunds and the invoices of a website for a period,
+// for accounting purposes. In the CSV format, the amounts of refunds and credit notes are negative.
+func (service *StoreService) ExportAccounting(ctx context.Context, input store.ExportAccountingInput) (ret store.ExportAccountingOutput, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+	logger := slogx.FromCtx(ctx)
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	if input.Format != store.AccountingExportFormatCsv && input.Format != store.AccountingExportFormatJson {
+		err = store.ErrAccountingExportFormatIsNotValid
+		return
+	}
+
+	if !input.To.After(input.From) || input.To.Sub(input.From) > store.AccountingExportMaxRange {
+		err = store.ErrAccountingExportPeriodIsNotValid
+		return
+	}
+
+	orders, err := service.repo.FindCompletedOrdersForWebsiteBetween(ctx, service.db, input.WebsiteID, input.From, input.To)
+	if err != nil {
+		return
+	}
+
+	refunds, err := service.repo.FindRefundsForWebsiteBetween(ctx, service.db, input.WebsiteID, input.From, input.To)
+	if err != nil {
+		return
+	}
+
+	invoices, err := service.repo.FindInvoicesForWebsiteBetween(ctx, service.db, input.WebsiteID, input.From, input.To)
+	if err != nil {
+		return
+	}
+
+	if input.Format == store.AccountingExportFormatJson {
+		ret.Orders, err = encodeAccountingJson(orders)
+		if err == nil {
+			ret.Refunds, err = encodeAccountingJson(refunds)
+		}
+		if err == nil {
+			ret.Invoices, err = encodeAccountingJson(invoices)
+		}
+		if err != nil {
+			errMessage := "store.ExportAccounting: encoding JSON"
+			logger.Error(errMessage, slogx.Err(err))
+			err = errs.Internal(errMessage, err)
+			return
+		}
+
+		return
+	}
+
+	ordersRecords := make([][]string, 0, len(orders)+1)
+	ordersRecords = append(ordersRecords, []string{"id", "created_at", "completed_at", "email", "country",
+		"additional_invoice_information", "currency", "discount_amount", "total_amount"})
+	for _, order := range orders {
+		var completedAt string
+		if order.CompletedAt != nil {
+			completedAt = order.CompletedAt.UTC().Format(time.RFC3339)
+		}
+		ordersRecords = append(ordersRecords, []string{
+			order.ID.String(),
+			order.CreatedAt.UTC().Format(time.RFC3339),
+			completedAt,
+			order.Email,
+			order.Country,
+			order.AdditionalInvoiceInformation,
+			string(order.Currency),
+			invoicing.FormatAmount(order.DiscountAmount * 100),
+			invoicing.FormatAmount(order.TotalAmount * 100),
+		})
+	}
+
+	refundsRecords := make([][]string, 0, len(refunds)+1)
+	refundsRecords = append(refundsRecords, []string{"id", "created_at", "order_id", "status", "reason",
+		"currency", "amount"})
+	for _, refund := range refunds {
+		refundsRecords = append(refundsRecords, []string{
+			refund.ID.String(),
+			refund.CreatedAt.UTC().Format(time.RFC3339),
+			refund.OrderID.String(),
+			string(refund.Status),
+			string(refund.Reason),
+			string(refund.Currency),
+			invoicing.FormatAmount(-refund.Amount * 100),
+		})
+	}
+
+	invoicesRecords := make([][]string, 0, len(invoices)+1)
+	invoicesRecords = append(invoicesRecords, []string{"number", "type", "date", "credited_invoice_number",
+		"order_id", "buyer_email", "buyer_country", "currency", "net_amount", "vat_rate", "vat_amount", "total_amount"})
+	for _, invoice := range invoices {
+		sign := int64(1)
+		if invoice.Type == store.InvoiceTypeCreditNote {
+			sign = -1
+		}
+		invoicesRecords = append(invoicesRecords, []string{
+			invoice.Number,
+			string(invoice.Type),
+			invoice.CreatedAt.UTC().Format(time.DateOnly),
+			invoice.CreditedInvoiceNumber,
+			invoice.OrderID.String(),
+			invoice.BuyerEmail,
+			invoice.BuyerCountry,
+			string(invoice.Currency),
+			invoicing.FormatAmount(sign * invoice.NetAmount),
+			strconv.FormatFloat(float64(invoice.VatRate)/100, 'f', -1, 64),
+			invoicing.FormatAmount(sign * invoice.VatAmount),
+			invoicing.FormatAmount(sign * invoice.TotalAmount),
+		})
+	}
+
+	ret.Orders, err = encodeAccountingCsv(ordersRecords)
+	if err == nil {
+		ret.Refunds, err = encodeAccountingCsv(refundsRecords)
+	}
+	if err == nil {
+		ret.Invoices, err = encodeAccountingCsv(invoicesRecords)
+	}
+	if err != nil {
+		errMessage := "store.ExportAccounting: writing CSV"
+		logger.Error(errMessage, slogx.Err(err))
+		err = errs.Internal(errMessage, err)
+		return
+	}
+
+	return
+}
+
+func encodeAccountingCsv(records [][]string) (string, error) {
+	var csvBuffer bytes.Buffer
+	csvWriter := csv.NewWriter(&csvBuffer)
+
+	err := csvWriter.WriteAll(records)
+	if err != nil {
+		return "", err
+	}
+
+	return csvBuffer.String(), nil
+}
+
+func encodeAccountingJson(data any) (string, error) {
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonData), nil
+}
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+)
+
+func (service *StoreService) FindActiveMembersContactIDs(ctx context.Context, db db.Queryer, websiteID guid.GUID) (contactIDs []guid.GUID, err error) {
+	return service.repo.FindActiveMembersContactIDs(ctx, db, websiteID)
+}
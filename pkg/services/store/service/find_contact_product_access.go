@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) FindContactProductAccess(ctx context.Context, db db.Queryer, contactID, productID guid.GUID) (access store.ContactProductAccess, err error) {
+	return service.repo.FindContactProductAccess(ctx, db, contactID, productID)
+}
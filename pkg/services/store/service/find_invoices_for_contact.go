@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) FindInvoicesForContact(ctx context.Context, db db.Queryer, contactID guid.GUID) (invoices []store.Invoice, err error) {
+	invoices, err = service.repo.FindInvoicesForContact(ctx, db, contactID)
+	return
+}
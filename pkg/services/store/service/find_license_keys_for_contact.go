@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) FindLicenseKeysForContact(ctx context.Context, db db.Queryer, contactID guid.GUID) (licenseKeys []store.LicenseKey, err error) {
+	return service.repo.FindLicenseKeysForContact(ctx, db, contactID)
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) FindMembershipsForContact(ctx context.Context, db db.Queryer, contactID guid.GUID) (memberships []store.Membership, err error) {
+	memberships, err = service.repo.FindMembershipsForContact(ctx, db, contactID)
+	if err != nil {
+		return
+	}
+
+	err = service.hydrateMemberships(ctx, db, memberships)
+	return
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) FindProductPageCompletionsForContact(ctx context.Context, db db.Queryer, contactID, productID guid.GUID) (completions []store.ProductPageCompletion, err error) {
+	return service.repo.FindProductPageCompletionsForContact(ctx, db, contactID, productID)
+}
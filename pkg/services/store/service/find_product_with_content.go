@@ -19,5 +19,10 @@ func (service *StoreService) FindProductWithContent(ctx context.Context, db db.Q
 		return
 	}
 
+	product.Ebooks, err = service.repo.FindProductEbooksForProduct(ctx, db, product.ID)
+	if err != nil {
+		return
+	}
+
 	return
 }
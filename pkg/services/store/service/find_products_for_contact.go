@@ -10,5 +10,10 @@ import (
 
 func (service *StoreService) FindProductsForContact(ctx context.Context, db db.Queryer, contactID guid.GUID) (products []store.Product, err error) {
 	products, err = service.repo.FindProductsForContact(ctx, db, contactID)
+	if err != nil {
+		return
+	}
+
+	err = service.hydrateProductsEbooks(ctx, db, products)
 	return
 }
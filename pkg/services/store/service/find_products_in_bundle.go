@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) FindProductsInBundle(ctx context.Context, db db.Queryer, bundleID guid.GUID) (products []store.Product, err error) {
+	products, err = service.repo.FindProductsInBundle(ctx, db, bundleID)
+	return
+}
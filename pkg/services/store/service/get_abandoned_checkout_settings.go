@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) GetAbandonedCheckoutSettings(ctx context.Context, input store.GetAbandonedCheckoutSettingsInput) (settings store.AbandonedCheckoutSettings, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	settings, err = service.findAbandonedCheckoutSettings(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) GetCourseAnalytics(ctx context.Context, input store.GetCourseAnalyticsInput) (analytics store.CourseAnalytics, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	product, err := service.repo.FindProductByID(ctx, service.db, input.ProductID)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, product.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	if product.Type != store.ProductTypeCourse {
+		err = store.ErrProductIsNotACourse
+		return
+	}
+
+	analytics.Students, err = service.repo.GetContactsWithAccessToProductCount(ctx, service.db, product.ID)
+	if err != nil {
+		return
+	}
+
+	analytics.Lessons, err = service.repo.GetLessonsAnalyticsForProduct(ctx, service.db, product.ID)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) GetCourseProgress(ctx context.Context, db db.Queryer, contactID, productID guid.GUID) (progress store.CourseProgress, err error) {
+	progress.TotalLessons, err = service.repo.GetProductPagesCountForProduct(ctx, db, productID)
+	if err != nil {
+		return
+	}
+
+	completions, err := service.repo.FindProductPageCompletionsForContact(ctx, db, contactID, productID)
+	if err != nil {
+		return
+	}
+	progress.CompletedLessons = int64(len(completions))
+
+	return
+}
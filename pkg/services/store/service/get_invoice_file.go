@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/skerkour/stdx-go/retry"
+	"github.com/skerkour/stdx-go/uuid"
+	"markdown.ninja/pkg/invoicing"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) GetInvoiceFile(ctx context.Context, input store.GetInvoiceFileInput) (ret store.GetInvoiceFileOutput, err error) {
+	if !slices.Contains(store.InvoiceFormats, input.Format) {
+		err = store.ErrInvoiceFormatIsNotValid
+		return
+	}
+
+	invoice, err := service.repo.FindInvoiceByID(ctx, service.db, input.ID)
+	if err != nil {
+		return
+	}
+
+	currentContact := service.contactsService.CurrentContact(ctx)
+	if currentContact != nil {
+		// contacts can only download the invoices of their own orders
+		var order store.Order
+		order, err = service.repo.FindOrderByID(ctx, service.db, invoice.OrderID, false)
+		if err != nil {
+			return
+		}
+
+		if !order.ContactID.Equal(currentContact.ID) {
+			err = store.ErrInvoiceNotFound
+			return
+		}
+	} else {
+		var currentUserID uuid.UUID
+		currentUserID, err = service.kernel.CurrentUserID(ctx)
+		if err != nil {
+			return
+		}
+
+		err = service.websitesService.CheckUserIsStaff(ctx, service.db, currentUserID, invoice.WebsiteID)
+		if err != nil {
+			return
+		}
+	}
+
+	storageKey := service.getInvoiceStorageKey(invoice, input.Format)
+	var data io.ReadCloser
+	err = retry.Do(func() (retryErr error) {
+		data, retryErr = service.storage.GetObject(ctx, storageKey, nil)
+		if retryErr != nil && data != nil {
+			// if there is an error, we close the object stream to avoid leaks
+			data.Close()
+		}
+		return retryErr
+	}, retry.Context(ctx), retry.Attempts(4), retry.Delay(15*time.Millisecond), retry.MaxDelay(100*time.Millisecond))
+	if err != nil {
+		err = fmt.Errorf("store.GetInvoiceFile: getting invoice from storage: %w", err)
+		return
+	}
+
+	ret = store.GetInvoiceFileOutput{
+		Filename: invoice.Number + "." + string(input.Format),
+		Data:     data,
+	}
+	switch input.Format {
+	case store.InvoiceFormatPdf:
+		ret.MediaType = invoicing.MediaTypePdf
+		ret.Size = invoice.PdfSize
+	case store.InvoiceFormatHtml:
+		ret.MediaType = invoicing.MediaTypeHtml
+		ret.Size = invoice.HtmlSize
+	}
+
+	return ret, nil
+}
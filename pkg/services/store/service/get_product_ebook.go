@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/skerkour/stdx-go/retry"
+	"markdown.ninja/pkg/ebook"
+	"markdown.ninja/pkg/services/store"
+)
+
+// GetProductEbook returns the cached ebook of a book. If the product has watermarking enabled, the ebook
+// is watermarked with the email address of the contact downloading it.
+func (service *StoreService) GetProductEbook(ctx context.Context, input store.GetProductEbookInput) (ret store.GetProductEbookOutput, err error) {
+	if !slices.Contains(store.ProductEbookFormats, input.Format) {
+		err = store.ErrProductEbookFormatIsNotValid
+		return
+	}
+
+	err = service.CheckProductAccess(ctx, service.db, input.ProductID)
+	if err != nil {
+		return
+	}
+
+	product, err := service.repo.FindProductByID(ctx, service.db, input.ProductID)
+	if err != nil {
+		return
+	}
+
+	if product.Type != store.ProductTypeBook {
+		err = store.ErrEbooksAreOnlyAvailableForBooks
+		return
+	}
+
+	productEbook, err := service.repo.FindProductEbook(ctx, service.db, product.ID, input.Format)
+	if err != nil {
+		return
+	}
+
+	storageKey := service.getProductEbookStorageKey(product.WebsiteID, productEbook)
+	var ebookData io.ReadCloser
+	err = retry.Do(func() (retryErr error) {
+		ebookData, retryErr = service.storage.GetObject(ctx, storageKey, nil)
+		if retryErr != nil && ebookData != nil {
+			// if there is an error, we close the object stream to avoid leaks
+			ebookData.Close()
+		}
+		return retryErr
+	}, retry.Context(ctx), retry.Attempts(4), retry.Delay(15*time.Millisecond), retry.MaxDelay(100*time.Millisecond))
+	if err != nil {
+		err = fmt.Errorf("store.GetProductEbook: getting ebook from storage: %w", err)
+		return
+	}
+
+	ret = store.GetProductEbookOutput{
+		Filename: product.Name + "." + string(productEbook.Format),
+		Size:     productEbook.Size,
+		Data:     ebookData,
+	}
+	switch productEbook.Format {
+	case store.ProductEbookFormatEpub:
+		ret.MediaType = ebook.MediaTypeEpub
+	case store.ProductEbookFormatHtml:
+		ret.MediaType = ebook.MediaTypeHtml
+	}
+
+	contact := service.contactsService.CurrentContact(ctx)
+	if !product.EbookWatermark || contact == nil {
+		return ret, nil
+	}
+
+	defer ebookData.Close()
+	originalEbook, err := io.ReadAll(ebookData)
+	if err != nil {
+		err = fmt.Errorf("store.GetProductEbook: reading ebook: %w", err)
+		return
+	}
+
+	var watermarkedEbook bytes.Buffer
+	watermark := fmt.Sprintf("This copy belongs to %s", contact.Email)
+	switch productEbook.Format {
+	case store.ProductEbookFormatEpub:
+		err = ebook.WatermarkEpub(&watermarkedEbook, bytes.NewReader(originalEbook), int64(len(originalEbook)), watermark)
+	case store.ProductEbookFormatHtml:
+		err = ebook.WatermarkHtml(&watermarkedEbook, originalEbook, watermark)
+	}
+	if err != nil {
+		err = fmt.Errorf("store.GetProductEbook: watermarking ebook: %w", err)
+		return
+	}
+
+	ret.Size = int64(watermarkedEbook.Len())
+	ret.Data = io.NopCloser(&watermarkedEbook)
+	return ret, nil
+}
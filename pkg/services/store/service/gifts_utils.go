@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/skerkour/stdx-go/countries"
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/contacts"
+	"markdown.ninja/pkg/services/store"
+)
+
+// orderBeneficiaryContactID returns the ID of the contact who is given the products of the order:
+// the recipient for gifts, the buyer otherwise.
+func orderBeneficiaryContactID(order store.Order) guid.GUID {
+	if order.GiftRecipientContactID != nil {
+		return *order.GiftRecipientContactID
+	}
+	return order.ContactID
+}
+
+// validateGift validates and normalizes the gift recipient and message of an order. giftRecipientEmail is
+// nil if the order is not a gift.
+func (service *StoreService) validateGift(ctx context.Context, input store.PlaceOrderInput, buyer contacts.Contact, isMembership bool) (giftRecipientEmail *string, giftMessage string, err error) {
+	if input.GiftRecipientEmail != nil && strings.TrimSpace(*input.GiftRecipientEmail) != "" {
+		recipientEmail := strings.TrimSpace(strings.ToLower(*input.GiftRecipientEmail))
+		err = service.kernel.ValidateEmail(ctx, recipientEmail, false)
+		if err != nil {
+			return
+		}
+
+		if recipientEmail == strings.ToLower(buyer.Email) {
+			err = store.ErrGiftRecipientIsTheBuyer
+			return
+		}
+
+		// memberships are tied to the subscription of the buyer
+		if isMembership {
+			err = store.ErrMembershipsCantBeGifted
+			return
+		}
+
+		giftRecipientEmail = &recipientEmail
+	}
+
+	if input.GiftMessage != nil {
+		giftMessage = strings.TrimSpace(*input.GiftMessage)
+		if giftMessage != "" {
+			if giftRecipientEmail == nil {
+				err = store.ErrGiftMessageRequiresRecipient
+				return
+			}
+
+			err = validateGiftMessage(giftMessage)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// findOrCreateVerifiedContact returns the contact with the given email, creating it if it doesn't exist
+// and marking it as verified otherwise, as contacts given access to products are always verified.
+func (service *StoreService) findOrCreateVerifiedContact(ctx context.Context, tx db.Queryer, websiteID guid.GUID, email string) (contact contacts.Contact, err error) {
+	contact, err = service.contactsService.FindContactByEmail(ctx, tx, websiteID, email)
+	if err != nil {
+		if !errs.IsNotFound(err) {
+			return
+		}
+
+		createContactInput := contacts.CreateContactInternalInput{
+			Email:     email,
+			Name:      "",
+			Verified:  true,
+			Country:   countries.CodeUnknown,
+			WebsiteID: websiteID,
+		}
+		return service.contactsService.CreateContactInternal(ctx, tx, createContactInput)
+	}
+
+	if !contact.Verified {
+		updateContactInput := contacts.UpdateContactInput{
+			ID:       contact.ID,
+			Verified: new(true),
+		}
+		err = service.contactsService.UpdateContactInternal(ctx, tx, &contact, updateContactInput)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func TestOrderBeneficiaryContactID(t *testing.T) {
+	buyerID := guid.NewTimeBased()
+	recipientID := guid.NewTimeBased()
+
+	order := store.Order{ContactID: buyerID}
+	if !orderBeneficiaryContactID(order).Equal(buyerID) {
+		t.Error("the buyer should be the beneficiary of an order which is not a gift")
+	}
+
+	// the recipient is only known once the order is completed
+	order.GiftRecipientEmail = new("recipient@example.com")
+	if !orderBeneficiaryContactID(order).Equal(buyerID) {
+		t.Error("the buyer should be the beneficiary until the recipient contact is set")
+	}
+
+	order.GiftRecipientContactID = &recipientID
+	if !orderBeneficiaryContactID(order).Equal(recipientID) {
+		t.Error("the recipient should be the beneficiary of a gift")
+	}
+}
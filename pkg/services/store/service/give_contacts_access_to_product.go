@@ -7,7 +7,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/skerkour/stdx-go/countries"
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/iterx"
 	"github.com/skerkour/stdx-go/slicesx"
@@ -37,7 +36,7 @@ func (service *StoreService) GiveContactsAccessToProduct(ctx context.Context, in
 		return strings.TrimSpace(email)
 	}))
 
-	validateEmailsRes, err := service.pingoo.LookupEmails(ctx, pingoo.LookupEmailsInput{
+	validateEmailsRes, err := service.kernel.LookupEmails(ctx, pingoo.LookupEmailsInput{
 		Emails: emails,
 	})
 	if err != nil {
@@ -52,6 +51,12 @@ func (service *StoreService) GiveContactsAccessToProduct(ctx context.Context, in
 		}
 	}
 
+	// bundles give access to all the products they include
+	productsToGiveAccessTo, err := service.productsGivenAccessBy(ctx, service.db, []store.Product{product})
+	if err != nil {
+		return
+	}
+
 	now := time.Now().UTC()
 
 	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
@@ -67,57 +72,16 @@ func (service *StoreService) GiveContactsAccessToProduct(ctx context.Context, in
 			}
 
 			var contact contacts.Contact
-			contact, txErr = service.contactsService.FindContactByEmail(ctx, tx, product.WebsiteID, email)
+			contact, txErr = service.findOrCreateVerifiedContact(ctx, tx, product.WebsiteID, email)
 			if txErr != nil {
-				if !errs.IsNotFound(txErr) {
-					return txErr
-				}
-
-				// if contact is not found we create it
-				createContactInput := contacts.CreateContactInternalInput{
-					Email:     email,
-					Name:      "",
-					Verified:  true,
-					Country:   countries.CodeUnknown,
-					WebsiteID: product.WebsiteID,
-				}
-				contact, txErr = service.contactsService.CreateContactInternal(ctx, tx, createContactInput)
-				if txErr != nil {
-					return txErr
-				}
-			} else {
-				// if contact is found but not verified, mark as verified
-				if !contact.Verified {
-					updateContactInput := contacts.UpdateContactInput{
-						ID:       contact.ID,
-						Verified: new(true),
-					}
-					txErr = service.contactsService.UpdateContactInternal(ctx, tx, &contact, updateContactInput)
-					if txErr != nil {
-						return txErr
-					}
-				}
+				return txErr
 			}
 
-			_, txErr = service.repo.FindContactProductAccess(ctx, tx, contact.ID, product.ID)
-			if txErr == nil {
-				// if contact already has access to product we do nothing
-				continue
-			} else {
-				if !errs.IsNotFound(txErr) {
+			for _, productID := range productsToGiveAccessTo {
+				txErr = service.giveContactAccessToProduct(ctx, tx, contact.ID, productID, now)
+				if txErr != nil {
 					return txErr
 				}
-				txErr = nil
-			}
-
-			contactProductAccess := store.ContactProductAccess{
-				CreatedAt: now,
-				ContactID: contact.ID,
-				ProductID: product.ID,
-			}
-			txErr = service.repo.CreateContactProductAccess(ctx, tx, contactProductAccess)
-			if txErr != nil {
-				return txErr
 			}
 		}
 
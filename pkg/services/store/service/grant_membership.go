@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/contacts"
+	"markdown.ninja/pkg/services/store"
+)
+
+// GrantMembership gives a contact a membership without going through Stripe, e.g. for
+// complimentary memberships. The membership stays active until it is canceled.
+func (service *StoreService) GrantMembership(ctx context.Context, input store.GrantMembershipInput) (membership store.Membership, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	product, err := service.repo.FindProductByID(ctx, service.db, input.ProductID)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, product.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	if product.Type != store.ProductTypeMembership {
+		err = store.ErrProductIsNotAMembership
+		return
+	}
+
+	contact, err := service.contactsService.FindContact(ctx, service.db, input.ContactID)
+	if err != nil {
+		return
+	}
+
+	if !contact.WebsiteID.Equal(product.WebsiteID) {
+		err = contacts.ErrContactNotFound
+		return
+	}
+
+	_, err = service.repo.FindActiveMembershipForContactAndProduct(ctx, service.db, contact.ID, product.ID)
+	if err == nil {
+		err = store.ErrContactIsAlreadyAMember
+		return
+	} else if !errs.IsNotFound(err) {
+		return
+	}
+
+	now := time.Now().UTC()
+	membership = store.Membership{
+		ID:                   guid.NewTimeBased(),
+		CreatedAt:            now,
+		UpdatedAt:            now,
+		Status:               store.MembershipStatusActive,
+		CurrentPeriodEnd:     nil,
+		CancelAtPeriodEnd:    false,
+		CanceledAt:           nil,
+		StripeSubscriptionID: nil,
+		WebsiteID:            product.WebsiteID,
+		ContactID:            contact.ID,
+		ProductID:            product.ID,
+		ProductName:          product.Name,
+	}
+	err = service.repo.CreateMembership(ctx, service.db, membership)
+	if err != nil {
+		return
+	}
+
+	return
+}
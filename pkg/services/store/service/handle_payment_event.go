@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/retry"
+	"markdown.ninja/pkg/payments"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) HandlePaymentEvent(ctx context.Context, event payments.Event) error {
+	logger := slogx.FromCtx(ctx).With(
+		slog.Group("payment_event",
+			slog.String("id", event.ID),
+			slog.String("type", string(event.Type)),
+		),
+	)
+	ctx = slogx.ToCtx(ctx, logger)
+
+	logger.Debug("store: payment event received")
+
+	switch event.Type {
+	case payments.EventTypeCheckoutSessionCompleted, payments.EventTypeCheckoutSessionExpired:
+		if event.CheckoutSession == nil {
+			logger.Error("store.HandlePaymentEvent: checkout_session is null")
+			return nil
+		}
+		return service.handlePaymentEventCheckoutSession(ctx, *event.CheckoutSession)
+	case payments.EventTypeInvoicePaid:
+		if event.Invoice == nil {
+			logger.Error("store.HandlePaymentEvent: invoice is null")
+			return nil
+		}
+		return service.handlePaymentEventInvoice(ctx, *event.Invoice)
+	case payments.EventTypeSubscriptionCreated,
+		payments.EventTypeSubscriptionUpdated,
+		payments.EventTypeSubscriptionDeleted:
+		if event.Subscription == nil {
+			logger.Error("store.HandlePaymentEvent: subscription is null")
+			return nil
+		}
+		return service.handlePaymentEventSubscription(ctx, *event.Subscription)
+	}
+
+	return nil
+}
+
+func (service *StoreService) handlePaymentEventCheckoutSession(ctx context.Context, checkoutSession payments.CheckoutSession) error {
+	logger := slogx.FromCtx(ctx).With(
+		slog.Group("checkout_session",
+			slog.String("id", checkoutSession.ID),
+			slog.String("status", string(checkoutSession.Status)),
+		),
+	)
+	ctx = slogx.ToCtx(ctx, logger)
+
+	if _, isOrganizationEvent := checkoutSession.Metadata["markdown_ninja_organization_id"]; isOrganizationEvent {
+		// for now only log it to avoid infinite calls betewen the two services
+		logger.Error("store.handlePaymentEventCheckoutSession: received an organization event")
+		return nil
+	}
+
+	websiteIDStr := checkoutSession.Metadata["markdown_ninja_website_id"]
+	if websiteIDStr == "" {
+		logger.Error("store.handlePaymentEventCheckoutSession: checkout_session.metadata.markdown_ninja_website_id is empty")
+		return nil
+	}
+	websiteID, err := guid.Parse(websiteIDStr)
+	if err != nil {
+		return fmt.Errorf("store.handlePaymentEventCheckoutSession: error parsing checkout_session.metadata.markdown_ninja_website_id[%s]: %w", websiteIDStr, err)
+	}
+
+	orderIDStr := checkoutSession.Metadata["markdown_ninja_order_id"]
+	if orderIDStr == "" {
+		logger.Error("store.handlePaymentEventCheckoutSession: checkout_session.metadata.markdown_ninja_order_id is empty")
+		return nil
+	}
+
+	orderID, err := guid.Parse(orderIDStr)
+	if err != nil {
+		return fmt.Errorf("store.handlePaymentEventCheckoutSession: error parsing checkout_session.metadata.markdown_ninja_order_id[%s]: %w", orderIDStr, err)
+	}
+
+	return service.completeOrder(ctx, orderID, websiteID)
+}
+
+func (service *StoreService) handlePaymentEventInvoice(ctx context.Context, invoice payments.Invoice) error {
+	logger := slogx.FromCtx(ctx).With(
+		slog.Group("invoice",
+			slog.String("id", invoice.ID),
+		),
+	)
+	ctx = slogx.ToCtx(ctx, logger)
+
+	if _, isOrganizationEvent := invoice.Metadata["markdown_ninja_organization_id"]; isOrganizationEvent {
+		// for now only log it to avoid infinite calls betewen the two services
+		logger.Error("store.handlePaymentEventInvoice: received an organization event")
+		return nil
+	}
+
+	if invoice.PaymentID == nil {
+		logger.Error("store.handlePaymentEventInvoice: invoice.payment_id is null")
+		return nil
+	}
+
+	var payment payments.Payment
+	err := retry.Do(func() (retryErr error) {
+		payment, retryErr = service.paymentProvider.GetPayment(ctx, *invoice.PaymentID)
+		return retryErr
+	}, retry.Context(ctx), retry.Attempts(3), retry.Delay(20*time.Millisecond))
+	if err != nil {
+		return fmt.Errorf("store.handlePaymentEventInvoice: error getting payment for invoice [%s]: %w", invoice.ID, err)
+	}
+
+	orderIdStr := payment.Metadata["markdown_ninja_order_id"]
+	if orderIdStr == "" {
+		return nil
+	}
+
+	orderID, err := guid.Parse(orderIdStr)
+	if err != nil {
+		return fmt.Errorf("store.handlePaymentEventInvoice: error parsing payment.metadata[markdown_ninja_order_id] (%s) for invoice [%s]: %w", orderIdStr, invoice.ID, err)
+	}
+
+	websiteIDStr := payment.Metadata["markdown_ninja_website_id"]
+	if websiteIDStr == "" {
+		return nil
+	}
+
+	websiteID, err := guid.Parse(websiteIDStr)
+	if err != nil {
+		return fmt.Errorf("store.handlePaymentEventInvoice: error parsing payment.metadata[markdown_ninja_website_id] (%s) for invoice [%s]: %w", orderIdStr, invoice.ID, err)
+	}
+
+	return service.completeOrder(ctx, orderID, websiteID)
+}
+
+// handlePaymentEventSubscription keeps memberships in sync with their subscription: renewals,
+// failed payments, cancellations...
+func (service *StoreService) handlePaymentEventSubscription(ctx context.Context, subscription payments.Subscription) error {
+	logger := slogx.FromCtx(ctx).With(
+		slog.Group("subscription",
+			slog.String("id", subscription.ID),
+			slog.String("status", string(subscription.Status)),
+		),
+	)
+	ctx = slogx.ToCtx(ctx, logger)
+
+	if _, isOrganizationEvent := subscription.Metadata["markdown_ninja_organization_id"]; isOrganizationEvent {
+		// for now only log it to avoid infinite calls betewen the two services
+		logger.Error("store.handlePaymentEventSubscription: received an organization event")
+		return nil
+	}
+
+	var ids [3]guid.GUID
+	var err error
+	for i, key := range []string{"markdown_ninja_website_id", "markdown_ninja_contact_id", "markdown_ninja_product_id"} {
+		idStr := subscription.Metadata[key]
+		if idStr == "" {
+			logger.Error("store.handlePaymentEventSubscription: subscription.metadata." + key + " is empty")
+			return nil
+		}
+		ids[i], err = guid.Parse(idStr)
+		if err != nil {
+			return fmt.Errorf("store.handlePaymentEventSubscription: error parsing subscription.metadata.%s[%s]: %w", key, idStr, err)
+		}
+	}
+	websiteID, contactID, productID := ids[0], ids[1], ids[2]
+
+	product, err := service.repo.FindProductByID(ctx, service.db, productID)
+	if err != nil {
+		return err
+	}
+
+	if !product.WebsiteID.Equal(websiteID) || product.Type != store.ProductTypeMembership {
+		logger.Error("store.handlePaymentEventSubscription: product is not a membership of the website",
+			slog.String("product.id", productID.String()))
+		return nil
+	}
+
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		_, txErr = service.syncMembershipWithSubscription(ctx, tx, websiteID, contactID, productID, subscription)
+		return txErr
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
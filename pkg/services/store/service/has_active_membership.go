@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+)
+
+func (service *StoreService) HasActiveMembership(ctx context.Context, db db.Queryer, contactID guid.GUID) (hasActiveMembership bool, err error) {
+	return service.repo.HasActiveMembership(ctx, db, contactID)
+}
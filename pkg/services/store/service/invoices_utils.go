@@ -0,0 +1,202 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/retry"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/invoicing"
+	"markdown.ninja/pkg/services/content"
+	"markdown.ninja/pkg/services/store"
+	"markdown.ninja/pkg/storage"
+)
+
+// findInvoicingSettings returns the invoicing settings of the website, or the default (disabled)
+// settings if they have never been configured.
+func (service *StoreService) findInvoicingSettings(ctx context.Context, db db.Queryer, websiteID guid.GUID) (settings store.InvoicingSettings, err error) {
+	settings, err = service.repo.FindInvoicingSettings(ctx, db, websiteID)
+	if err != nil {
+		if !errs.IsNotFound(err) {
+			return
+		}
+
+		now := time.Now().UTC()
+		settings = store.InvoicingSettings{
+			CreatedAt:              now,
+			UpdatedAt:              now,
+			Enabled:                false,
+			SellerName:             "",
+			SellerAddress:          "",
+			SellerVatNumber:        "",
+			VatRate:                0,
+			Notes:                  "",
+			InvoiceNumberPrefix:    store.DefaultInvoiceNumberPrefix,
+			CreditNoteNumberPrefix: store.DefaultCreditNoteNumberPrefix,
+			NextInvoiceNumber:      1,
+			NextCreditNoteNumber:   1,
+			WebsiteID:              websiteID,
+		}
+		err = nil
+	}
+
+	return
+}
+
+func (service *StoreService) getInvoiceStorageKey(invoice store.Invoice, format store.InvoiceFormat) string {
+	return filepath.Join(content.WebsitesStorageBasePath, invoice.WebsiteID.String(), "invoices",
+		fmt.Sprintf("%s.%s", invoice.ID.String(), format))
+}
+
+// issueInvoice numbers the invoice (or credit note) with the next number of the sequence of the website,
+// renders its documents, uploads them to the storage and saves the invoice.
+// It must be called in a transaction so that the sequence of numbers has no gap.
+func (service *StoreService) issueInvoice(ctx context.Context, tx db.Queryer, settings store.InvoicingSettings, invoice *store.Invoice) (err error) {
+	invoice.Sequence, err = service.repo.IncrementInvoiceNumber(ctx, tx, invoice.WebsiteID, invoice.Type)
+	if err != nil {
+		return
+	}
+
+	prefix := settings.InvoiceNumberPrefix
+	if invoice.Type == store.InvoiceTypeCreditNote {
+		prefix = settings.CreditNoteNumberPrefix
+	}
+	invoice.Number = invoicing.FormatNumber(prefix, invoice.Sequence)
+
+	document := convertInvoiceToDocument(*invoice)
+	documents := make(map[store.InvoiceFormat][]byte, len(store.InvoiceFormats))
+	documents[store.InvoiceFormatPdf], err = document.Pdf()
+	if err != nil {
+		return fmt.Errorf("store.issueInvoice: %w", err)
+	}
+	documents[store.InvoiceFormatHtml], err = document.Html()
+	if err != nil {
+		return fmt.Errorf("store.issueInvoice: %w", err)
+	}
+
+	for format, data := range documents {
+		// used for S3 data-integrity checks
+		dataSha256 := sha256.Sum256(data)
+		putObjectOptions := &storage.PutObjectOptions{
+			HashSha256: dataSha256[:],
+		}
+		storageKey := service.getInvoiceStorageKey(*invoice, format)
+		err = retry.Do(func() (retryErr error) {
+			return service.storage.PutObject(ctx, storageKey, int64(len(data)), bytes.NewReader(data), putObjectOptions)
+		}, retry.Context(ctx), retry.Attempts(3), retry.Delay(50*time.Millisecond))
+		if err != nil {
+			return fmt.Errorf("store.issueInvoice: uploading %s document to storage: %w", format, err)
+		}
+	}
+	invoice.PdfSize = int64(len(documents[store.InvoiceFormatPdf]))
+	invoice.HtmlSize = int64(len(documents[store.InvoiceFormatHtml]))
+
+	err = service.repo.CreateInvoice(ctx, tx, *invoice)
+	if err != nil {
+		return
+	}
+
+	return nil
+}
+
+func convertInvoiceToDocument(invoice store.Invoice) invoicing.Document {
+	document := invoicing.Document{
+		Type:      invoicing.TypeInvoice,
+		Number:    invoice.Number,
+		Date:      invoice.CreatedAt,
+		Reference: invoice.CreditedInvoiceNumber,
+		Currency:  string(invoice.Currency),
+		Seller: invoicing.Party{
+			Name:      invoice.SellerName,
+			Address:   invoice.SellerAddress,
+			VatNumber: invoice.SellerVatNumber,
+		},
+		Buyer: invoicing.Party{
+			Email:                 invoice.BuyerEmail,
+			Country:               invoice.BuyerCountry,
+			AdditionalInformation: invoice.AdditionalInvoiceInformation,
+		},
+		Lines:       make([]invoicing.Line, len(invoice.LineItems)),
+		VatRate:     invoice.VatRate,
+		NetAmount:   invoice.NetAmount,
+		VatAmount:   invoice.VatAmount,
+		TotalAmount: invoice.TotalAmount,
+		Notes:       invoice.Notes,
+	}
+	if invoice.Type == store.InvoiceTypeCreditNote {
+		document.Type = invoicing.TypeCreditNote
+	}
+
+	for i, lineItem := range invoice.LineItems {
+		document.Lines[i] = invoicing.Line{
+			Description: lineItem.Description,
+			Quantity:    lineItem.Quantity,
+			UnitAmount:  lineItem.UnitAmount,
+			Amount:      lineItem.Amount,
+		}
+	}
+
+	return document
+}
+
+// invoiceLineItemsForOrder returns the line items of the invoice of an order, with amounts in cents.
+// The price of the products may differ from the amount actually paid (e.g. pay what you want
+// prices or prices in another currency), so the line items are prorated to always add up to
+// the total amount of the order.
+func invoiceLineItemsForOrder(order store.Order, orderLineItems []store.OrderLineItem) store.InvoiceLineItems {
+	total := order.TotalAmount * 100
+	if len(orderLineItems) == 0 {
+		return store.InvoiceLineItems{
+			{Description: "Order " + order.ID.String(), Quantity: 1, UnitAmount: total, Amount: total},
+		}
+	}
+
+	lineItems := make(store.InvoiceLineItems, len(orderLineItems), len(orderLineItems)+1)
+	var sum int64
+	for i, orderLineItem := range orderLineItems {
+		quantity := max(orderLineItem.Quantity, 1)
+		unitAmount := orderLineItem.OriginalProductPrice * 100
+		lineItems[i] = store.InvoiceLineItem{
+			Description: orderLineItem.ProductName,
+			Quantity:    quantity,
+			UnitAmount:  unitAmount,
+			Amount:      unitAmount * quantity,
+		}
+		sum += lineItems[i].Amount
+	}
+
+	discount := order.DiscountAmount * 100
+	if sum == total {
+		return lineItems
+	} else if discount > 0 && sum-discount == total {
+		return append(lineItems, store.InvoiceLineItem{
+			Description: "Discount",
+			Quantity:    1,
+			UnitAmount:  -discount,
+			Amount:      -discount,
+		})
+	}
+
+	remaining := total
+	for i := range lineItems {
+		amount := remaining
+		if i != len(lineItems)-1 {
+			if sum > 0 {
+				amount = lineItems[i].Amount * total / sum
+			} else {
+				amount = 0
+			}
+		}
+		remaining -= amount
+		lineItems[i].Amount = amount
+		lineItems[i].UnitAmount = amount / lineItems[i].Quantity
+	}
+
+	return lineItems
+}
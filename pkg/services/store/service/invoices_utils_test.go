@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+)
+
+func TestInvoiceLineItemsForOrder(t *testing.T) {
+	book := store.OrderLineItem{ProductName: "Book", OriginalProductPrice: 25, Quantity: 1}
+	course := store.OrderLineItem{ProductName: "Course", OriginalProductPrice: 99, Quantity: 1}
+
+	lineItems := invoiceLineItemsForOrder(store.Order{TotalAmount: 124}, []store.OrderLineItem{book, course})
+	if len(lineItems) != 2 || lineItems[0].Amount != 2500 || lineItems[1].Amount != 9900 {
+		t.Errorf("exact total: got %+v", lineItems)
+	}
+
+	lineItems = invoiceLineItemsForOrder(store.Order{TotalAmount: 109, DiscountAmount: 15}, []store.OrderLineItem{book, course})
+	if len(lineItems) != 3 || lineItems[2].Description != "Discount" || lineItems[2].Amount != -1500 {
+		t.Errorf("discount: got %+v", lineItems)
+	}
+
+	// pay what you want: the line items are prorated and must add up to the total
+	lineItems = invoiceLineItemsForOrder(store.Order{TotalAmount: 100}, []store.OrderLineItem{book, course})
+	if len(lineItems) != 2 || lineItems[0].Amount+lineItems[1].Amount != 10000 {
+		t.Errorf("prorated: got %+v", lineItems)
+	}
+	if lineItems[0].Amount != 2016 {
+		t.Errorf("prorated: expected first line item amount to be 2016, got %d", lineItems[0].Amount)
+	}
+
+	orderID := guid.NewTimeBased()
+	lineItems = invoiceLineItemsForOrder(store.Order{ID: orderID, TotalAmount: 30}, nil)
+	if len(lineItems) != 1 || lineItems[0].Amount != 3000 || lineItems[0].Description != "Order "+orderID.String() {
+		t.Errorf("no line items: got %+v", lineItems)
+	}
+}
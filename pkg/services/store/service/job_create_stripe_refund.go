@@ -6,8 +6,7 @@ import (
 	"time"
 
 	"github.com/skerkour/stdx-go/retry"
-	"github.com/stripe/stripe-go/v81"
-	striperefund "github.com/stripe/stripe-go/v81/refund"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/services/store"
 )
 
@@ -22,27 +21,30 @@ func (service *StoreService) JobCreateStripeRefund(ctx context.Context, input st
 		return
 	}
 
+	if order.StripPaymentItentID == nil {
+		err = fmt.Errorf("store.JobCreateStripeRefund: order [%s] has no payment", order.ID.String())
+		return err
+	}
+
 	// TODO: correct conversion
-	stripeRefundReason := string(refund.Reason)
-	stripeRefundCurrendy := string(refund.Currency)
-	stripeRefundParams := &stripe.RefundParams{
-		Amount:        &refund.Amount,
-		Currency:      &stripeRefundCurrendy,
-		Reason:        &stripeRefundReason,
-		PaymentIntent: order.StripPaymentItentID,
+	createRefundInput := payments.CreateRefundInput{
+		PaymentID: *order.StripPaymentItentID,
+		Amount:    refund.Amount,
+		Currency:  string(refund.Currency),
+		Reason:    string(refund.Reason),
 		Metadata: map[string]string{
 			"markdown_ninja_website_id": refund.WebsiteID.String(),
 			"markdown_ninja_order_id":   refund.OrderID.String(),
 		},
 	}
-	stripeRefund, err := striperefund.New(stripeRefundParams)
+	paymentRefund, err := service.paymentProvider.CreateRefund(ctx, createRefundInput)
 	if err != nil {
-		err = fmt.Errorf("store.JobCreateStripeRefund: error creating Stripe refund (%s): %w", refund.ID.String(), err)
+		err = fmt.Errorf("store.JobCreateStripeRefund: error creating refund (%s): %w", refund.ID.String(), err)
 		return err
 	}
 
 	refund.UpdatedAt = time.Now().UTC()
-	refund.StripeRefundID = &stripeRefund.ID
+	refund.StripeRefundID = &paymentRefund.ID
 	// we retry to be sure that the refund is created
 	err = retry.Do(func() error {
 		return service.repo.UpdateRefund(ctx, service.db, refund)
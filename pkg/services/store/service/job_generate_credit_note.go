@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/invoicing"
+	"markdown.ninja/pkg/services/store"
+)
+
+// JobGenerateCreditNote issues the credit note of a succeeded refund. Credit notes are only issued for
+// the orders that have an invoice, even if invoicing has been disabled since.
+func (service *StoreService) JobGenerateCreditNote(ctx context.Context, input store.JobGenerateCreditNote) (err error) {
+	tx, err := service.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("store.JobGenerateCreditNote: Starting DB transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	refund, err := service.repo.FindRefundByID(ctx, tx, input.RefundID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if refund.Status != store.RefundStatusSucceeded {
+		return nil
+	}
+
+	// the order is locked so that concurrent jobs can't issue 2 credit notes for the same refund
+	_, err = service.repo.FindOrderByID(ctx, tx, refund.OrderID, true)
+	if err != nil {
+		return err
+	}
+
+	_, err = service.repo.FindCreditNoteForRefund(ctx, tx, refund.ID)
+	if err == nil {
+		// the credit note has already been issued
+		return nil
+	} else if !errs.IsNotFound(err) {
+		return err
+	}
+
+	orderInvoice, err := service.repo.FindInvoiceForOrder(ctx, tx, refund.OrderID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	settings, err := service.findInvoicingSettings(ctx, tx, refund.WebsiteID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	amount := refund.Amount * 100
+	creditNote := store.Invoice{
+		ID:        guid.NewTimeBased(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Type:      store.InvoiceTypeCreditNote,
+		Currency:  refund.Currency,
+		LineItems: store.InvoiceLineItems{
+			{
+				Description: "Refund of invoice " + orderInvoice.Number,
+				Quantity:    1,
+				UnitAmount:  amount,
+				Amount:      amount,
+			},
+		},
+		// the VAT rate of the credited invoice applies
+		VatRate:                      orderInvoice.VatRate,
+		TotalAmount:                  amount,
+		CreditedInvoiceNumber:        orderInvoice.Number,
+		SellerName:                   settings.SellerName,
+		SellerAddress:                settings.SellerAddress,
+		SellerVatNumber:              settings.SellerVatNumber,
+		BuyerEmail:                   orderInvoice.BuyerEmail,
+		BuyerCountry:                 orderInvoice.BuyerCountry,
+		AdditionalInvoiceInformation: orderInvoice.AdditionalInvoiceInformation,
+		Notes:                        settings.Notes,
+		WebsiteID:                    refund.WebsiteID,
+		OrderID:                      refund.OrderID,
+		RefundID:                     &refund.ID,
+	}
+	creditNote.NetAmount, creditNote.VatAmount = invoicing.SplitVat(creditNote.TotalAmount, creditNote.VatRate)
+
+	err = service.issueInvoice(ctx, tx, settings, &creditNote)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("store.JobGenerateCreditNote: Comitting DB transaction: %w", err)
+	}
+
+	return nil
+}
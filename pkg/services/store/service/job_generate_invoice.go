@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/invoicing"
+	"markdown.ninja/pkg/services/store"
+)
+
+// JobGenerateInvoice issues the invoice of a completed order if invoicing is enabled for the website.
+// An order has at most one invoice.
+func (service *StoreService) JobGenerateInvoice(ctx context.Context, input store.JobGenerateInvoice) (err error) {
+	tx, err := service.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("store.JobGenerateInvoice: Starting DB transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// the order is locked so that concurrent jobs can't issue 2 invoices for the same order
+	order, err := service.repo.FindOrderByID(ctx, tx, input.OrderID, true)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if order.Status != store.OrderStatusCompleted {
+		return nil
+	}
+
+	_, err = service.repo.FindInvoiceForOrder(ctx, tx, order.ID)
+	if err == nil {
+		// the invoice has already been issued
+		return nil
+	} else if !errs.IsNotFound(err) {
+		return err
+	}
+
+	settings, err := service.findInvoicingSettings(ctx, tx, order.WebsiteID)
+	if err != nil {
+		return err
+	}
+
+	if !settings.Enabled {
+		return nil
+	}
+
+	orderLineItems, err := service.repo.FindOrderLineItems(ctx, tx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	invoice := store.Invoice{
+		ID:                           guid.NewTimeBased(),
+		CreatedAt:                    now,
+		UpdatedAt:                    now,
+		Type:                         store.InvoiceTypeInvoice,
+		Currency:                     order.Currency,
+		LineItems:                    invoiceLineItemsForOrder(order, orderLineItems),
+		VatRate:                      settings.VatRate,
+		TotalAmount:                  order.TotalAmount * 100,
+		CreditedInvoiceNumber:        "",
+		SellerName:                   settings.SellerName,
+		SellerAddress:                settings.SellerAddress,
+		SellerVatNumber:              settings.SellerVatNumber,
+		BuyerEmail:                   order.Email,
+		BuyerCountry:                 order.Country,
+		AdditionalInvoiceInformation: order.AdditionalInvoiceInformation,
+		Notes:                        settings.Notes,
+		WebsiteID:                    order.WebsiteID,
+		OrderID:                      order.ID,
+		RefundID:                     nil,
+	}
+	invoice.NetAmount, invoice.VatAmount = invoicing.SplitVat(invoice.TotalAmount, invoice.VatRate)
+
+	err = service.issueInvoice(ctx, tx, settings, &invoice)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("store.JobGenerateInvoice: Comitting DB transaction: %w", err)
+	}
+
+	return nil
+}
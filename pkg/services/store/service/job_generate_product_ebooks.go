@@ -0,0 +1,186 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/retry"
+	"markdown.ninja/pkg/ebook"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/content"
+	"markdown.ninja/pkg/services/store"
+	"markdown.ninja/pkg/storage"
+)
+
+func (service *StoreService) JobGenerateProductEbooks(ctx context.Context, input store.JobGenerateProductEbooks) (err error) {
+	logger := slogx.FromCtx(ctx)
+
+	product, err := service.repo.FindProductByID(ctx, service.db, input.ProductID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			// product has been deleted in the meantime
+			return nil
+		}
+		return err
+	}
+
+	if product.Type != store.ProductTypeBook {
+		return nil
+	}
+
+	website, err := service.websitesService.FindWebsiteByID(ctx, service.db, product.WebsiteID)
+	if err != nil {
+		return err
+	}
+
+	pages, err := service.repo.FindProductPagesForProduct(ctx, service.db, product.ID)
+	if err != nil {
+		return err
+	}
+
+	assets, err := service.contentService.FindProductAssets(ctx, service.db, product.ID)
+	if err != nil {
+		return err
+	}
+
+	existingEbooks, err := service.repo.FindProductEbooksForProduct(ctx, service.db, product.ID)
+	if err != nil {
+		return err
+	}
+	existingEbooksByFormat := make(map[store.ProductEbookFormat]store.ProductEbook, len(existingEbooks))
+	for _, existingEbook := range existingEbooks {
+		existingEbooksByFormat[existingEbook.Format] = existingEbook
+	}
+
+	contentHash := computeProductEbookContentHash(website.Name, product, pages, assets)
+
+	formatsToGenerate := make([]store.ProductEbookFormat, 0, len(store.ProductEbookFormats))
+	for _, format := range store.ProductEbookFormats {
+		if existingEbook, exists := existingEbooksByFormat[format]; exists && bytes.Equal(existingEbook.ContentHash, contentHash) {
+			continue
+		}
+		formatsToGenerate = append(formatsToGenerate, format)
+	}
+
+	if len(formatsToGenerate) == 0 {
+		return nil
+	}
+
+	websiteBaseUrl := service.httpConfig.WebsitesBaseUrl.Scheme + "://" + website.PrimaryDomain + service.httpConfig.WebsitesPort
+
+	book := ebook.Book{
+		ID:          product.ID.String(),
+		Title:       product.Name,
+		Description: product.Description,
+		Author:      website.Name,
+		Language:    website.Language,
+		ModifiedAt:  product.UpdatedAt,
+		Chapters:    make([]ebook.Chapter, 0, len(pages)),
+		Images:      make([]ebook.Image, 0, len(assets)),
+	}
+
+	for _, page := range pages {
+		book.Chapters = append(book.Chapters, ebook.Chapter{
+			Title: page.Title,
+			Html:  service.contentService.RenderMarkdown(website, page.BodyMarkdown, nil, false),
+		})
+		if page.UpdatedAt.After(book.ModifiedAt) {
+			book.ModifiedAt = page.UpdatedAt
+		}
+	}
+
+	for _, asset := range assets {
+		if asset.Type != content.AssetTypeImage {
+			continue
+		}
+
+		imageUrl := "/assets?id=" + asset.ID.String()
+		book.Images = append(book.Images, ebook.Image{
+			Urls:      []string{imageUrl, websiteBaseUrl + imageUrl},
+			Filename:  asset.ID.String() + filepath.Ext(asset.Name),
+			MediaType: asset.MediaType,
+			Open: func() (io.ReadCloser, error) {
+				return service.contentService.GetAssetData(ctx, asset, nil)
+			},
+		})
+	}
+
+	compiledBook, err := ebook.Build(book)
+	if err != nil {
+		return fmt.Errorf("store.JobGenerateProductEbooks: building ebook for product [%s]: %w", product.ID.String(), err)
+	}
+
+	for _, format := range formatsToGenerate {
+		var ebookBuffer bytes.Buffer
+
+		switch format {
+		case store.ProductEbookFormatEpub:
+			err = compiledBook.WriteEpub(&ebookBuffer)
+		case store.ProductEbookFormatHtml:
+			err = compiledBook.WriteHtml(&ebookBuffer)
+		default:
+			err = fmt.Errorf("unknown ebook format: %s", format)
+		}
+		if err != nil {
+			return fmt.Errorf("store.JobGenerateProductEbooks: generating %s ebook for product [%s]: %w",
+				format, product.ID.String(), err)
+		}
+
+		now := time.Now().UTC()
+		ebookData := ebookBuffer.Bytes()
+		productEbook, previousEbookExists := existingEbooksByFormat[format]
+		previousEbook := productEbook
+		if !previousEbookExists {
+			productEbook = store.ProductEbook{
+				ID:        guid.NewTimeBased(),
+				CreatedAt: now,
+				Format:    format,
+				ProductID: product.ID,
+			}
+		}
+		productEbook.UpdatedAt = now
+		productEbook.ContentHash = contentHash
+		productEbook.Size = int64(len(ebookData))
+
+		// used for S3 data-integrity checks
+		ebookSha256 := sha256.Sum256(ebookData)
+		putObjectOptions := &storage.PutObjectOptions{
+			HashSha256: ebookSha256[:],
+		}
+		storageKey := service.getProductEbookStorageKey(website.ID, productEbook)
+		err = retry.Do(func() (retryErr error) {
+			return service.storage.PutObject(ctx, storageKey, productEbook.Size, bytes.NewReader(ebookData), putObjectOptions)
+		}, retry.Context(ctx), retry.Attempts(3), retry.Delay(50*time.Millisecond))
+		if err != nil {
+			return fmt.Errorf("store.JobGenerateProductEbooks: uploading %s ebook to storage: %w", format, err)
+		}
+
+		if previousEbookExists {
+			err = service.repo.UpdateProductEbook(ctx, service.db, productEbook)
+		} else {
+			err = service.repo.CreateProductEbook(ctx, service.db, productEbook)
+		}
+		if err != nil {
+			return err
+		}
+
+		if previousEbookExists {
+			previousStorageKey := service.getProductEbookStorageKey(website.ID, previousEbook)
+			deleteErr := service.storage.DeleteObject(ctx, previousStorageKey)
+			if deleteErr != nil {
+				logger.Warn("store.JobGenerateProductEbooks: error deleting previous ebook from storage",
+					slogx.Err(deleteErr), slog.String("storage_key", previousStorageKey))
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/mail"
+	"time"
+
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/queue"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/markdown"
+	"markdown.ninja/pkg/services/emails"
+	"markdown.ninja/pkg/services/store"
+	"markdown.ninja/pkg/services/store/notifications"
+)
+
+// JobSendAbandonedCheckoutEmail sends the recovery email of a pending order. The email is sent at most
+// once per order, and only to contacts subscribed to the newsletter that are not blocked.
+func (service *StoreService) JobSendAbandonedCheckoutEmail(ctx context.Context, input store.JobSendAbandonedCheckoutEmail) (err error) {
+	logger := slogx.FromCtx(ctx).With(slog.String("order.id", input.OrderID.String()))
+	now := time.Now().UTC()
+
+	tx, err := service.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("store.JobSendAbandonedCheckoutEmail: Starting DB transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	order, err := service.repo.FindOrderByID(ctx, tx, input.OrderID, true)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	// the order may have been completed or canceled since the job has been enqueued
+	if order.Status != store.OrderStatusPending || order.RecoveryEmailSentAt != nil {
+		return nil
+	}
+
+	settings, err := service.findAbandonedCheckoutSettings(ctx, tx, order.WebsiteID)
+	if err != nil {
+		return err
+	}
+
+	if !settings.Enabled {
+		return nil
+	}
+
+	contact, err := service.contactsService.FindContact(ctx, tx, order.ContactID)
+	if err != nil {
+		return err
+	}
+
+	// respect the consent of the contact: unsubscribed contacts (including suppressed addresses) and
+	// blocked contacts never receive recovery emails
+	if contact.SubscribedToNewsletterAt == nil || contact.BlockedAt != nil {
+		return nil
+	}
+
+	website, err := service.websitesService.FindWebsiteByID(ctx, tx, order.WebsiteID)
+	if err != nil {
+		return err
+	}
+
+	emailConfig, err := service.emailsService.FindWebsiteConfiguration(ctx, tx, order.WebsiteID)
+	if err != nil {
+		return err
+	}
+
+	var from mail.Address
+	if emailConfig.DomainVerified {
+		from = mail.Address{
+			Name:    emailConfig.FromName,
+			Address: emailConfig.FromAddress,
+		}
+	} else {
+		from = service.emailsService.GetDefaultFromAddressForWebsite(website)
+	}
+
+	lineItems, err := service.repo.FindOrderLineItems(ctx, tx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	unsubscribeLink, err := service.contactsService.GenerateUnsubscribeLink(website.PrimaryDomain, contact.ID)
+	if err != nil {
+		return fmt.Errorf("store.JobSendAbandonedCheckoutEmail: generating unsubscribe link: %w", err)
+	}
+
+	contentHtml, err := markdown.ToHtmlEmail(
+		service.httpConfig.WebsitesBaseUrl.Scheme+"://"+website.PrimaryDomain+service.websitesPort,
+		settings.BodyMarkdown,
+	)
+	if err != nil {
+		return fmt.Errorf("store.JobSendAbandonedCheckoutEmail: converting markdown to HTML: %w", err)
+	}
+
+	resumeUrl, err := service.generateResumeOrderUrl(website.PrimaryDomain, order.ID)
+	if err != nil {
+		return err
+	}
+
+	emailData := notifications.AbandonedCheckoutEmailData{
+		Content:         template.HTML(contentHtml),
+		Products:        make([]string, len(lineItems)),
+		ResumeURL:       template.URL(resumeUrl),
+		UnsubscribeLink: template.URL(unsubscribeLink),
+	}
+	for i, lineItem := range lineItems {
+		emailData.Products[i] = lineItem.ProductName
+	}
+
+	var htmlContent bytes.Buffer
+	err = service.abandonedCheckoutEmailTemplate.Execute(&htmlContent, emailData)
+	if err != nil {
+		errMessage := "store.JobSendAbandonedCheckoutEmail: Executing email template"
+		logger.Error(errMessage, slogx.Err(err))
+		return errs.Internal(errMessage, err)
+	}
+
+	sendEmailJob := queue.NewJobInput{
+		Data: emails.JobSendEmail{
+			Type:        emails.EmailTypeBroadcast,
+			FromAddress: from.Address,
+			FromName:    from.Name,
+			ToAddress:   contact.Email,
+			ToName:      contact.Name,
+			Subject:     settings.Subject,
+			BodyHtml:    htmlContent.String(),
+			Headers: map[string][]string{
+				"List-Unsubscribe": {"<" + unsubscribeLink + ">"},
+			},
+			WebsiteID:      &website.ID,
+			ContactID:      &contact.ID,
+			NewsletterID:   nil,
+			OrganizationID: nil,
+		},
+	}
+	err = service.queue.Push(ctx, tx, sendEmailJob)
+	if err != nil {
+		return fmt.Errorf("store.JobSendAbandonedCheckoutEmail: pushing JobSendEmail to queue: %w", err)
+	}
+
+	order.RecoveryEmailSentAt = &now
+	order.UpdatedAt = now
+	err = service.repo.UpdateOrder(ctx, tx, order)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("store.JobSendAbandonedCheckoutEmail: Comitting DB transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/mail"
+
+	"github.com/skerkour/stdx-go/email"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/events"
+	"markdown.ninja/pkg/services/store"
+	"markdown.ninja/pkg/services/store/notifications"
+)
+
+func (service *StoreService) JobSendGiftNotificationEmail(ctx context.Context, input store.JobSendGiftNotificationEmail) (err error) {
+	logger := slogx.FromCtx(ctx)
+	var htmlContent bytes.Buffer
+
+	order, err := service.repo.FindOrderByID(ctx, service.db, input.OrderID, false)
+	if err != nil {
+		return
+	}
+
+	if order.Status != store.OrderStatusCompleted || order.GiftRecipientContactID == nil {
+		logger.Warn("store.JobSendGiftNotificationEmail: order is not a completed gift",
+			slog.String("order.id", order.ID.String()))
+		return nil
+	}
+
+	emailConfig, err := service.emailsService.FindWebsiteConfiguration(ctx, service.db, order.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	website, err := service.websitesService.FindWebsiteByID(ctx, service.db, order.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	var from mail.Address
+	if emailConfig.DomainVerified {
+		from = mail.Address{
+			Name:    emailConfig.FromName,
+			Address: emailConfig.FromAddress,
+		}
+	} else {
+		from = service.emailsService.GetDefaultFromAddressForWebsite(website)
+	}
+
+	buyer, err := service.contactsService.FindContact(ctx, service.db, order.ContactID)
+	if err != nil {
+		return
+	}
+
+	recipient, err := service.contactsService.FindContact(ctx, service.db, *order.GiftRecipientContactID)
+	if err != nil {
+		return
+	}
+
+	orderLineItems, err := service.repo.FindOrderLineItems(ctx, service.db, order.ID)
+	if err != nil {
+		return
+	}
+
+	licenseKeys, err := service.repo.FindLicenseKeysForOrder(ctx, service.db, order.ID)
+	if err != nil {
+		return
+	}
+
+	buyerName := buyer.Name
+	if buyerName == "" {
+		buyerName = buyer.Email
+	}
+
+	to := mail.Address{
+		Name:    recipient.Name,
+		Address: recipient.Email,
+	}
+	subject := fmt.Sprintf("%s sent you a gift", buyerName)
+	hostname := website.PrimaryDomain + service.httpConfig.WebsitesPort
+	accountUrl := fmt.Sprintf("%s://%s%s/account", service.httpConfig.WebsitesBaseUrl.Scheme, hostname, service.websitesPort)
+	emailData := notifications.GiftNotificationEmailData{
+		From:        buyerName,
+		Message:     order.GiftMessage,
+		Products:    make([]string, 0, len(orderLineItems)),
+		AccountURL:  template.URL(accountUrl),
+		LicenseKeys: make([]notifications.GiftNotificationEmailLicenseKey, 0, len(licenseKeys)),
+	}
+	for _, lineItem := range orderLineItems {
+		emailData.Products = append(emailData.Products, lineItem.ProductName)
+	}
+	for _, licenseKey := range licenseKeys {
+		if licenseKey.Status != store.LicenseKeyStatusIssued {
+			continue
+		}
+		emailData.LicenseKeys = append(emailData.LicenseKeys, notifications.GiftNotificationEmailLicenseKey{
+			ProductName: licenseKey.ProductName,
+			Key:         licenseKey.Key,
+		})
+	}
+	err = service.giftNotificationEmailTemplate.Execute(&htmlContent, emailData)
+	if err != nil {
+		errMessage := "store.JobSendGiftNotificationEmail: Executing email template"
+		logger.Error(errMessage, slogx.Err(err))
+		err = errs.Internal(errMessage, err)
+		return
+	}
+
+	message := email.Email{
+		From:    from,
+		To:      []mail.Address{to},
+		Subject: subject,
+		HTML:    htmlContent.Bytes(),
+		Text:    []byte(subject),
+	}
+	err = service.mailer.SendTransactionnal(ctx, message)
+	if err != nil {
+		errMessage := "store.JobSendGiftNotificationEmail: Sending email"
+		logger.Error(errMessage, slogx.Err(err), slog.String("email", to.String()))
+		err = errs.Internal(errMessage, err)
+		return
+	}
+
+	trackEventInput := events.TrackEmailSentInput{
+		FromAddress: from.Address,
+		ToAddress:   to.Address,
+		WebsiteID:   website.ID,
+	}
+	service.eventsService.TrackEmailSent(ctx, trackEventInput)
+
+	return
+}
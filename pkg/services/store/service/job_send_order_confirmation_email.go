@@ -1,7 +1,6 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"html/template"
@@ -11,14 +10,13 @@ import (
 	"github.com/skerkour/stdx-go/email"
 	"github.com/skerkour/stdx-go/log/slogx"
 	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/emails"
 	"markdown.ninja/pkg/services/events"
 	"markdown.ninja/pkg/services/store"
-	"markdown.ninja/pkg/services/store/notifications"
 )
 
 func (service *StoreService) JobSendOrderConfirmationEmail(ctx context.Context, input store.JobSendOrderConfirmationEmail) (err error) {
 	logger := slogx.FromCtx(ctx)
-	var htmlContent bytes.Buffer
 
 	order, err := service.repo.FindOrderByID(ctx, service.db, input.OrderID, false)
 	if err != nil {
@@ -50,6 +48,11 @@ func (service *StoreService) JobSendOrderConfirmationEmail(ctx context.Context,
 		return
 	}
 
+	licenseKeys, err := service.repo.FindLicenseKeysForOrder(ctx, service.db, order.ID)
+	if err != nil {
+		return
+	}
+
 	to := mail.Address{
 		Name:    contact.Name,
 		Address: contact.Email,
@@ -57,13 +60,29 @@ func (service *StoreService) JobSendOrderConfirmationEmail(ctx context.Context,
 	subject := fmt.Sprintf("Order #%s confirmed", order.ID.String())
 	hostname := website.PrimaryDomain + service.httpConfig.WebsitesPort
 	accountUrl := fmt.Sprintf("%s://%s%s/account", service.httpConfig.WebsitesBaseUrl.Scheme, hostname, service.websitesPort)
-	emailData := notifications.OrderConfirmationEmailData{
-		AccountURL: template.URL(accountUrl),
-		OrderID:    order.ID.String(),
+	emailData := emails.EmailTemplateData{
+		Subject:     subject,
+		AccountURL:  template.URL(accountUrl),
+		OrderID:     order.ID.String(),
+		LicenseKeys: make([]emails.EmailTemplateLicenseKey, 0, len(licenseKeys)),
+	}
+	if order.GiftRecipientEmail != nil {
+		// the license keys of gifts are sent to the recipient
+		emailData.GiftRecipientEmail = *order.GiftRecipientEmail
+		licenseKeys = nil
+	}
+	for _, licenseKey := range licenseKeys {
+		if licenseKey.Status != store.LicenseKeyStatusIssued {
+			continue
+		}
+		emailData.LicenseKeys = append(emailData.LicenseKeys, emails.EmailTemplateLicenseKey{
+			ProductName: licenseKey.ProductName,
+			Key:         licenseKey.Key,
+		})
 	}
-	err = service.orderConfirmationEmailTemplate.Execute(&htmlContent, emailData)
+	renderedEmail, err := service.emailsService.RenderWebsiteEmail(ctx, service.db, website, emails.EmailTemplateTypeOrderConfirmation, emailData)
 	if err != nil {
-		errMessage := "store.JobSendOrderConfirmationEmail: Executing email template"
+		errMessage := "store.JobSendOrderConfirmationEmail: Rendering email"
 		logger.Error(errMessage, slogx.Err(err))
 		err = errs.Internal(errMessage, err)
 		return
@@ -73,8 +92,8 @@ func (service *StoreService) JobSendOrderConfirmationEmail(ctx context.Context,
 		From:    from,
 		To:      []mail.Address{to},
 		Subject: subject,
-		HTML:    htmlContent.Bytes(),
-		Text:    []byte(subject),
+		HTML:    []byte(renderedEmail.Html),
+		Text:    []byte(renderedEmail.Text),
 	}
 	err = service.mailer.SendTransactionnal(ctx, message)
 	if err != nil {
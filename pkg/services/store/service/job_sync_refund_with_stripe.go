@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/stripe/stripe-go/v81"
-	striperefund "github.com/stripe/stripe-go/v81/refund"
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/queue"
 	"markdown.ninja/pkg/services/store"
 )
 
@@ -16,28 +16,68 @@ func (service *StoreService) JobSyncRefundWithStripe(ctx context.Context, input
 		return
 	}
 
-	// Stripe refund not created yet
+	// refund not created yet by the payment provider
 	if refund.StripeRefundID == nil {
 		return
 	}
 
-	stripeRefundParams := &stripe.RefundParams{}
-	stripeRefund, err := striperefund.Get(*refund.StripeRefundID, stripeRefundParams)
+	paymentRefund, err := service.paymentProvider.GetRefund(ctx, *refund.StripeRefundID)
 	if err != nil {
-		err = fmt.Errorf("store.JobSyncRefundWithStripe: error fetching Stripe refund (%s): %w", refund.ID.String(), err)
+		err = fmt.Errorf("store.JobSyncRefundWithStripe: error fetching refund (%s): %w", refund.ID.String(), err)
 		return
 	}
 
-	refundStatus := store.RefundStatus(stripeRefund.Status)
+	refundStatus := store.RefundStatus(paymentRefund.Status)
 	if refundStatus != refund.Status {
 		refund.UpdatedAt = time.Now().UTC()
 		refund.Status = refundStatus
-		// TODO: it's weird, the field should be nullable, but it's not in the Go object
-		if stripeRefund.FailureReason != "" {
-			failureReason := store.RefundFailureReason(stripeRefund.FailureReason)
+		if paymentRefund.FailureReason != "" {
+			failureReason := store.RefundFailureReason(paymentRefund.FailureReason)
 			refund.FailureReason = &failureReason
 		}
-		err = service.repo.UpdateRefund(ctx, service.db, refund)
+
+		err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+			txErr = service.repo.UpdateRefund(ctx, tx, refund)
+			if txErr != nil {
+				return txErr
+			}
+
+			// a refunded order no longer gives access to its products (including the items of bundles)
+			// and its license keys are revoked
+			if refund.Status == store.RefundStatusSucceeded {
+				var order store.Order
+				order, txErr = service.repo.FindOrderByID(ctx, tx, refund.OrderID, true)
+				if txErr != nil {
+					return txErr
+				}
+
+				txErr = service.removeAccessForRefundedOrder(ctx, tx, order)
+				if txErr != nil {
+					return txErr
+				}
+
+				txErr = service.repo.RevokeLicenseKeysForOrder(ctx, tx, order.ID, refund.UpdatedAt)
+				if txErr != nil {
+					return txErr
+				}
+
+				txErr = service.repo.DeletePendingLicenseKeysForOrder(ctx, tx, order.ID)
+				if txErr != nil {
+					return txErr
+				}
+
+				txErr = service.queue.Push(ctx, tx, queue.NewJobInput{
+					Data: store.JobGenerateCreditNote{
+						RefundID: refund.ID,
+					},
+				})
+				if txErr != nil {
+					return fmt.Errorf("store.JobSyncRefundWithStripe: pushing JobGenerateCreditNote to queue: %w", txErr)
+				}
+			}
+
+			return nil
+		})
 		if err != nil {
 			return
 		}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/crypto"
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/queue"
+	"github.com/skerkour/stdx-go/randutil"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/store"
+)
+
+func generateLicenseKey() string {
+	randomGenerator := crypto.NewRandomGenerator()
+	groups := make([]string, store.LicenseKeyGroupsCount)
+	for i := range groups {
+		groups[i] = string(randutil.RandAlphabet(randomGenerator, []byte(store.LicenseKeyAlphabet), store.LicenseKeyGroupsLength))
+	}
+	return strings.Join(groups, "-")
+}
+
+// issueLicenseKeys issues a license key for each of the purchased products that use license keys.
+// If the pool of keys of a product is empty, the order is still completed as the customer has already
+// paid, and the key is issued when keys are added to the pool (see issuePendingLicenseKeys).
+func (service *StoreService) issueLicenseKeys(ctx context.Context, tx db.Queryer, order store.Order, productIDs []guid.GUID, now time.Time) (err error) {
+	logger := slogx.FromCtx(ctx)
+	contactID := orderBeneficiaryContactID(order)
+
+	for _, productID := range productIDs {
+		var product store.Product
+		product, err = service.repo.FindProductByID(ctx, tx, productID)
+		if err != nil {
+			return
+		}
+
+		var licenseKey store.LicenseKey
+		switch product.LicenseKeys {
+		case store.LicenseKeysModeGenerated:
+			licenseKey = store.LicenseKey{
+				ID:        guid.NewTimeBased(),
+				CreatedAt: now,
+				Key:       generateLicenseKey(),
+				WebsiteID: product.WebsiteID,
+				ProductID: product.ID,
+			}
+		case store.LicenseKeysModePool:
+			licenseKey, err = service.repo.FindAvailableLicenseKeyForProduct(ctx, tx, product.ID)
+			if err != nil {
+				if errs.IsNotFound(err) {
+					logger.Warn("store.issueLicenseKeys: the pool of license keys is empty, the key is pending",
+						slog.String("product.id", product.ID.String()), slog.String("order.id", order.ID.String()))
+					err = service.repo.CreatePendingLicenseKey(ctx, tx, store.PendingLicenseKey{
+						ID:        guid.NewTimeBased(),
+						CreatedAt: now,
+						ProductID: product.ID,
+						OrderID:   order.ID,
+						ContactID: contactID,
+					})
+					if err != nil {
+						return
+					}
+					continue
+				}
+				return
+			}
+		default:
+			continue
+		}
+
+		licenseKey.UpdatedAt = now
+		licenseKey.Status = store.LicenseKeyStatusIssued
+		licenseKey.IssuedAt = &now
+		licenseKey.OrderID = &order.ID
+		licenseKey.ContactID = &contactID
+
+		if product.LicenseKeys == store.LicenseKeysModeGenerated {
+			err = service.repo.CreateLicenseKey(ctx, tx, licenseKey)
+		} else {
+			err = service.repo.UpdateLicenseKey(ctx, tx, licenseKey)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	return nil
+}
+
+// issuePendingLicenseKeys issues the keys of the pool of the product to the orders that were completed
+// while the pool was empty, oldest first, and sends the keys to the customers (or the recipients of the
+// gifts) by sending again the confirmation of their order.
+func (service *StoreService) issuePendingLicenseKeys(ctx context.Context, tx db.Queryer, product store.Product, now time.Time) (err error) {
+	pendingLicenseKeys, err := service.repo.FindPendingLicenseKeysForProduct(ctx, tx, product.ID)
+	if err != nil {
+		return
+	}
+
+	for _, pendingLicenseKey := range pendingLicenseKeys {
+		var licenseKey store.LicenseKey
+		licenseKey, err = service.repo.FindAvailableLicenseKeyForProduct(ctx, tx, product.ID)
+		if err != nil {
+			if errs.IsNotFound(err) {
+				// the other keys stay pending until more keys are added
+				return nil
+			}
+			return
+		}
+
+		licenseKey.UpdatedAt = now
+		licenseKey.Status = store.LicenseKeyStatusIssued
+		licenseKey.IssuedAt = &now
+		licenseKey.OrderID = &pendingLicenseKey.OrderID
+		licenseKey.ContactID = &pendingLicenseKey.ContactID
+		err = service.repo.UpdateLicenseKey(ctx, tx, licenseKey)
+		if err != nil {
+			return
+		}
+
+		err = service.repo.DeletePendingLicenseKey(ctx, tx, pendingLicenseKey.ID)
+		if err != nil {
+			return
+		}
+
+		var order store.Order
+		order, err = service.repo.FindOrderByID(ctx, tx, pendingLicenseKey.OrderID, false)
+		if err != nil {
+			return
+		}
+
+		var job queue.NewJobInput
+		if order.GiftRecipientContactID != nil {
+			job.Data = store.JobSendGiftNotificationEmail{OrderID: order.ID}
+		} else {
+			job.Data = store.JobSendOrderConfirmationEmail{OrderID: order.ID}
+		}
+		err = service.queue.Push(ctx, tx, job)
+		if err != nil {
+			err = fmt.Errorf("store.issuePendingLicenseKeys: pushing job to queue: %w", err)
+			return
+		}
+	}
+
+	return nil
+}
+
+// checkLicenseKeysAreAvailable returns store.ErrProductIsOutOfStock if one of the products, or of the items
+// of the bundles, issues license keys from a pool which is empty.
+func (service *StoreService) checkLicenseKeysAreAvailable(ctx context.Context, db db.Queryer, products []store.Product) (err error) {
+	for _, product := range products {
+		productsToCheck := []store.Product{product}
+		if product.Type == store.ProductTypeBundle {
+			var items []store.Product
+			items, err = service.repo.FindProductsInBundle(ctx, db, product.ID)
+			if err != nil {
+				return
+			}
+			productsToCheck = append(productsToCheck, items...)
+		}
+
+		for _, productToCheck := range productsToCheck {
+			if productToCheck.LicenseKeys != store.LicenseKeysModePool {
+				continue
+			}
+
+			var available bool
+			available, err = service.repo.HasAvailableLicenseKeys(ctx, db, productToCheck.ID)
+			if err != nil {
+				return
+			}
+			if !available {
+				return store.ErrProductIsOutOfStock(productToCheck.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// findLicenseKeyForWebsite finds a license key of a product of the website of the current request. Revoked
+// keys are returned, and unknown keys are reported as store.ErrLicenseKeyNotFound.
+func (service *StoreService) findLicenseKeyForWebsite(ctx context.Context, db db.Queryer, websiteID, productID guid.GUID, key string, forUpdate bool) (product store.Product, licenseKey store.LicenseKey, err error) {
+	key = strings.TrimSpace(key)
+	if key == "" || len(key) > store.LicenseKeyMaxLength {
+		err = store.ErrLicenseKeyNotFound
+		return
+	}
+
+	product, err = service.repo.FindProductByID(ctx, db, productID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			err = store.ErrLicenseKeyNotFound
+		}
+		return
+	}
+
+	if !product.WebsiteID.Equal(websiteID) {
+		err = store.ErrLicenseKeyNotFound
+		return
+	}
+
+	licenseKey, err = service.repo.FindLicenseKeyByProductAndKey(ctx, db, product.ID, key, forUpdate)
+	if err != nil {
+		return
+	}
+
+	// keys of the pool that are not issued yet are unknown to the public API
+	if licenseKey.Status == store.LicenseKeyStatusAvailable {
+		err = store.ErrLicenseKeyNotFound
+		return
+	}
+
+	return
+}
+
+func (service *StoreService) licenseKeyVerification(ctx context.Context, db db.Queryer, product store.Product, licenseKey store.LicenseKey, instance *string) (verification store.LicenseKeyVerification, err error) {
+	activations, err := service.repo.FindLicenseKeyActivationsForLicenseKey(ctx, db, licenseKey.ID)
+	if err != nil {
+		return
+	}
+
+	verification = store.LicenseKeyVerification{
+		Valid:            licenseKey.Status == store.LicenseKeyStatusIssued,
+		Status:           licenseKey.Status,
+		Activated:        false,
+		Activations:      int64(len(activations)),
+		ActivationsLimit: product.LicenseKeyActivationsLimit,
+	}
+	if instance != nil {
+		for _, activation := range activations {
+			if activation.Instance == *instance {
+				verification.Activated = true
+				break
+			}
+		}
+	}
+
+	return
+}
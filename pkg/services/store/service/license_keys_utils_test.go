@@ -0,0 +1,22 @@
+package service
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGenerateLicenseKey(t *testing.T) {
+	licenseKeyRegexp := regexp.MustCompile(`^[A-HJ-NP-Z2-9]{5}(-[A-HJ-NP-Z2-9]{5}){4}$`)
+	generatedKeys := make(map[string]bool)
+
+	for range 1000 {
+		key := generateLicenseKey()
+		if !licenseKeyRegexp.MatchString(key) {
+			t.Errorf("license key %s has not the expected format", key)
+		}
+		if generatedKeys[key] {
+			t.Errorf("license key %s has been generated twice", key)
+		}
+		generatedKeys[key] = true
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/services/kernel"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) ListInvoices(ctx context.Context, input store.ListInvoicesInput) (ret kernel.PaginatedResult[store.Invoice], err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	if input.OrderID != nil {
+		var order store.Order
+		order, err = service.repo.FindOrderByID(ctx, service.db, *input.OrderID, false)
+		if err != nil {
+			return
+		}
+
+		if !order.WebsiteID.Equal(input.WebsiteID) {
+			err = store.ErrOrderNotFound(*input.OrderID)
+			return
+		}
+
+		ret.Data, err = service.repo.FindInvoicesForOrder(ctx, service.db, order.ID)
+		return
+	}
+
+	ret.Data, err = service.repo.FindInvoicesForWebsite(ctx, service.db, input.WebsiteID, store.InvoicesListLimit)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/services/kernel"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) ListLicenseKeys(ctx context.Context, input store.ListLicenseKeysInput) (ret kernel.PaginatedResult[store.LicenseKey], err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	product, err := service.repo.FindProductByID(ctx, service.db, input.ProductID)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, product.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	ret.Data, err = service.repo.FindLicenseKeysForProduct(ctx, service.db, product.ID)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/services/kernel"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) ListMemberships(ctx context.Context, input store.ListMembershipsInput) (ret kernel.PaginatedResult[store.Membership], err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	ret.Data, err = service.repo.FindMembershipsForWebsite(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	err = service.hydrateMemberships(ctx, service.db, ret.Data)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/payments"
+	"markdown.ninja/pkg/services/store"
+)
+
+// membershipProductForOrder returns the membership product of the order, if any.
+// As memberships are billed through a subscription, they can't be ordered with other products.
+func membershipProductForOrder(products []store.Product) (membershipProduct *store.Product, err error) {
+	for i := range products {
+		if products[i].Type == store.ProductTypeMembership {
+			membershipProduct = &products[i]
+			break
+		}
+	}
+
+	if membershipProduct != nil && len(products) != 1 {
+		return nil, store.ErrMembershipMustBeOrderedAlone
+	}
+
+	return membershipProduct, nil
+}
+
+// syncMembershipWithSubscription creates or updates the membership backed by the given subscription
+// of the payment provider.
+func (service *StoreService) syncMembershipWithSubscription(ctx context.Context, db db.Queryer,
+	websiteID, contactID, productID guid.GUID, subscription payments.Subscription) (membership store.Membership, err error) {
+	now := time.Now().UTC()
+
+	membership, err = service.repo.FindMembershipByStripeSubscriptionID(ctx, db, subscription.ID, true)
+	if err != nil {
+		if !errs.IsNotFound(err) {
+			return
+		}
+		err = nil
+
+		membership = store.Membership{
+			ID:                   guid.NewTimeBased(),
+			CreatedAt:            now,
+			StripeSubscriptionID: &subscription.ID,
+			WebsiteID:            websiteID,
+			ContactID:            contactID,
+			ProductID:            productID,
+		}
+		applySubscriptionToMembership(&membership, subscription, now)
+		err = service.repo.CreateMembership(ctx, db, membership)
+		return
+	}
+
+	applySubscriptionToMembership(&membership, subscription, now)
+	err = service.repo.UpdateMembership(ctx, db, membership)
+	return
+}
+
+func applySubscriptionToMembership(membership *store.Membership, subscription payments.Subscription, now time.Time) {
+	membership.UpdatedAt = now
+	membership.Status = store.MembershipStatus(subscription.Status)
+	membership.CancelAtPeriodEnd = subscription.CancelAtPeriodEnd
+	membership.CurrentPeriodEnd = subscription.CurrentPeriodEnd
+	membership.CanceledAt = subscription.CanceledAt
+}
+
+// hydrateMemberships fills the name of the products of the memberships
+// All the memberships must belong to the same website.
+func (service *StoreService) hydrateMemberships(ctx context.Context, db db.Queryer, memberships []store.Membership) (err error) {
+	if len(memberships) == 0 {
+		return nil
+	}
+
+	websiteID := memberships[0].WebsiteID
+
+	productIDs := make([]guid.GUID, 0, len(memberships))
+	for _, membership := range memberships {
+		productIDs = append(productIDs, membership.ProductID)
+	}
+
+	products, err := service.repo.FindWebsiteProductsIn(ctx, db, websiteID, productIDs)
+	if err != nil {
+		return err
+	}
+
+	productNames := make(map[guid.GUID]string, len(products))
+	for _, product := range products {
+		productNames[product.ID] = product.Name
+	}
+
+	for i := range memberships {
+		memberships[i].ProductName = productNames[memberships[i].ProductID]
+	}
+
+	return nil
+}
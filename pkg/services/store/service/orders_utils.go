@@ -1,12 +1,97 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"net/url"
+	"slices"
+	"time"
 
 	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/slicesx"
+	"markdown.ninja/pkg/jwt"
 	"markdown.ninja/pkg/services/store"
+	"markdown.ninja/pkg/services/websites"
 )
 
+const jwtActionResumeOrder = "resume_order"
+
+type jwtClaimsResumeOrder struct {
+	Action  string    `json:"action"`
+	OrderID guid.GUID `json:"order_id"`
+}
+
+// findProductsForOrder finds the active products of the website that are being ordered
+func (service *StoreService) findProductsForOrder(ctx context.Context, websiteID guid.GUID, productIDs []guid.GUID) (products []store.Product, err error) {
+	productIDs = slicesx.Unique(productIDs)
+
+	if len(productIDs) == 0 {
+		err = store.ErrAtLeastOneProductIsRequiredForCheckout
+		return
+	}
+
+	products, err = service.repo.FindWebsiteProductsIn(ctx, service.db, websiteID, productIDs)
+	if err != nil {
+		return
+	}
+
+	if len(products) != len(productIDs) {
+		err = store.ErrProductNotFound
+		return
+	}
+
+	for _, product := range products {
+		if product.Status != store.ProductStatusActive {
+			err = store.ErrProductIsNotAvailable(product.Name)
+			return
+		}
+	}
+
+	return
+}
+
+// resolveOrderPrices resolves the currency of the order (see store.ResolveCurrency) and returns a
+// copy of the products where Price is the unit price paid by the buyer in this currency: the
+// chosen amount for pay-what-you-want products, if any, and the (minimum) price otherwise.
+func resolveOrderPrices(websiteCurrency websites.Currency, countryCode string, products []store.Product,
+	customPrices map[guid.GUID]int64) (currency websites.Currency, pricedProducts []store.Product, err error) {
+	currency = store.ResolveCurrency(websiteCurrency, countryCode, products)
+	pricedProducts = make([]store.Product, len(products))
+
+	for productID := range customPrices {
+		if !slices.ContainsFunc(products, func(product store.Product) bool { return product.ID.Equal(productID) }) {
+			err = store.ErrProductNotFound
+			return
+		}
+	}
+
+	for i, product := range products {
+		minimumPrice, _ := product.PriceIn(websiteCurrency, currency)
+		price := minimumPrice
+
+		if customPrice, hasCustomPrice := customPrices[product.ID]; hasCustomPrice {
+			if !product.PayWhatYouWant {
+				err = store.ErrProductIsNotPayWhatYouWant(product.Name)
+				return
+			}
+			if customPrice < minimumPrice {
+				err = store.ErrCustomPriceIsTooLow(product.Name, minimumPrice, currency)
+				return
+			}
+			if customPrice > store.ProductPriceMax {
+				err = store.ErrProductPriceIsTooHigh
+				return
+			}
+			price = customPrice
+		}
+
+		product.Price = price
+		pricedProducts[i] = product
+	}
+
+	return
+}
+
 func (service *StoreService) generateCompleteOrderUrl(domain string, orderID guid.GUID) string {
 	hostname := domain + service.websitesPort
 	return fmt.Sprintf("%s://%s/checkout/%s/complete",
@@ -19,6 +104,43 @@ func (service *StoreService) generateCancelOrderUrl(domain string, orderID guid.
 		service.httpConfig.WebsitesBaseUrl.Scheme, hostname, orderID.String())
 }
 
+// generateResumeOrderUrl returns the link of the recovery email of the order, signed so that only the
+// recipient of the email can resume the order (see ResumeOrder).
+func (service *StoreService) generateResumeOrderUrl(domain string, orderID guid.GUID) (resumeUrl string, err error) {
+	jwtClaims := jwtClaimsResumeOrder{
+		Action:  jwtActionResumeOrder,
+		OrderID: orderID,
+	}
+	expiresAt := time.Now().UTC().Add(store.ResumeOrderLinkTimeout)
+	token, err := service.jwtProvider.NewSignedToken(jwtClaims, &jwt.TokenOptions{
+		ExpirationTime: &expiresAt,
+	})
+	if err != nil {
+		err = fmt.Errorf("store: generating resume order token: %w", err)
+		return
+	}
+
+	query := url.Values{}
+	query.Set("token", token)
+
+	hostname := domain + service.websitesPort
+	resumeUrl = fmt.Sprintf("%s://%s/checkout/%s/resume?%s",
+		service.httpConfig.WebsitesBaseUrl.Scheme, hostname, orderID.String(), query.Encode())
+	return
+}
+
+// verifyResumeOrderToken returns true if the token is a valid token for the given order
+func (service *StoreService) verifyResumeOrderToken(token string, orderID guid.GUID) bool {
+	var jwtClaims jwtClaimsResumeOrder
+
+	err := service.jwtProvider.ParseAndVerifyToken(token, &jwtClaims)
+	if err != nil {
+		return false
+	}
+
+	return jwtClaims.Action == jwtActionResumeOrder && jwtClaims.OrderID.Equal(orderID)
+}
+
 func convertOrderToMetadata(order store.Order) store.OrderMetadata {
 	return store.OrderMetadata{
 		ID:          order.ID,
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/store"
+	"markdown.ninja/pkg/services/websites"
+)
+
+func TestResolveOrderPrices(t *testing.T) {
+	book := store.Product{ID: guid.NewTimeBased(), Name: "Book", Price: 10, PayWhatYouWant: true,
+		Prices: store.ProductPrices{websites.CurrencyUSD: 12}}
+	course := store.Product{ID: guid.NewTimeBased(), Name: "Course", Price: 99}
+
+	currency, products, err := resolveOrderPrices(websites.CurrencyEUR, "US", []store.Product{book}, nil)
+	if err != nil {
+		t.Fatalf("resolving prices without custom price: %v", err)
+	}
+	if currency != websites.CurrencyUSD || products[0].Price != 12 {
+		t.Errorf("without custom price: got %d %s", products[0].Price, currency)
+	}
+
+	_, products, err = resolveOrderPrices(websites.CurrencyEUR, "US", []store.Product{book},
+		map[guid.GUID]int64{book.ID: 20})
+	if err != nil {
+		t.Fatalf("resolving prices with custom price: %v", err)
+	}
+	if products[0].Price != 20 {
+		t.Errorf("with custom price: expected 20, got %d", products[0].Price)
+	}
+
+	_, _, err = resolveOrderPrices(websites.CurrencyEUR, "US", []store.Product{book},
+		map[guid.GUID]int64{book.ID: 11})
+	if err == nil {
+		t.Errorf("custom price below the minimum price: expected an error")
+	}
+
+	_, _, err = resolveOrderPrices(websites.CurrencyEUR, "FR", []store.Product{book, course},
+		map[guid.GUID]int64{course.ID: 150})
+	if err == nil {
+		t.Errorf("custom price for a fixed price product: expected an error")
+	}
+
+	// the original products are not modified
+	if book.Price != 10 {
+		t.Errorf("book price has been modified: %d", book.Price)
+	}
+}
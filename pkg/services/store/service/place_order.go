@@ -3,22 +3,20 @@ package service
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"maps"
 	"strings"
 	"time"
 
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/guid"
 	"github.com/skerkour/stdx-go/log/slogx"
-	"github.com/skerkour/stdx-go/slicesx"
-	"github.com/stripe/stripe-go/v81"
-	"github.com/stripe/stripe-go/v81/checkout/session"
-	stripecustomer "github.com/stripe/stripe-go/v81/customer"
 	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/contacts"
 	"markdown.ninja/pkg/services/events"
 	"markdown.ninja/pkg/services/store"
-	"markdown.ninja/pkg/services/websites"
 )
 
 func (service *StoreService) PlaceOrder(ctx context.Context, input store.PlaceOrderInput) (output store.PlaceOrderOutput, err error) {
@@ -32,7 +30,8 @@ func (service *StoreService) PlaceOrder(ctx context.Context, input store.PlaceOr
 		return
 	}
 
-	if !service.rateLimiter.IsAllowed("StoreService.PlaceOrder", website.ID.Bytes(), httpCtx.Client.IP.AsSlice(), time.Hour, 30) {
+	if !service.rateLimiter.IsAllowed(ctx, "StoreService.PlaceOrder", website.ID.Bytes(), httpCtx.Client.IP.AsSlice(), time.Hour,
+		uint64(website.RateLimits.WithDefaults().PlaceOrder)) {
 		err = errs.TooManyRequests()
 		return
 	}
@@ -62,76 +61,114 @@ func (service *StoreService) PlaceOrder(ctx context.Context, input store.PlaceOr
 		customer = &contact
 	}
 
-	productIDs := slicesx.Unique(input.Products)
+	orderedProducts, err := service.findProductsForOrder(ctx, website.ID, input.Products)
+	if err != nil {
+		return
+	}
 
-	if len(productIDs) == 0 {
-		err = store.ErrAtLeastOneProductIsRequiredForCheckout
+	membershipProduct, err := membershipProductForOrder(orderedProducts)
+	if err != nil {
 		return
 	}
 
-	orderedProducts, err := service.repo.FindWebsiteProductsIn(ctx, service.db, website.ID, productIDs)
+	err = service.checkLicenseKeysAreAvailable(ctx, service.db, orderedProducts)
 	if err != nil {
 		return
 	}
 
-	if len(orderedProducts) != len(productIDs) {
-		err = store.ErrProductNotFound
+	giftRecipientEmail, giftMessage, err := service.validateGift(ctx, input, *customer, membershipProduct != nil)
+	if err != nil {
 		return
 	}
 
-	var totalAmount int64
-	for _, product := range orderedProducts {
-		if product.Status != store.ProductStatusActive {
-			err = store.ErrProductNotFound
+	if membershipProduct != nil {
+		_, err = service.repo.FindActiveMembershipForContactAndProduct(ctx, service.db, customer.ID, membershipProduct.ID)
+		if err == nil {
+			err = store.ErrAlreadyAMember
+			return
+		} else if !errs.IsNotFound(err) {
 			return
 		}
+		err = nil
+	}
 
-		totalAmount += product.Price
+	currency, orderedProducts, err := resolveOrderPrices(website.Currency, httpCtx.Client.CountryCode, orderedProducts,
+		input.CustomPrices)
+	if err != nil {
+		return
 	}
 
-	orderID := guid.NewTimeBased()
+	var coupon *store.Coupon
+	if input.Coupon != nil && strings.TrimSpace(*input.Coupon) != "" {
+		var existingCoupon store.Coupon
+		existingCoupon, err = service.findCouponForOrder(ctx, service.db, website.ID, *input.Coupon)
+		if err != nil {
+			return
+		}
+		coupon = &existingCoupon
+	}
 
-	currency := website.Currency
-	if httpCtx.Client.CountryCode == "US" {
-		currency = websites.CurrencyUSD
+	pricing, err := priceOrder(orderedProducts, coupon)
+	if err != nil {
+		return
 	}
 
-	stripeItems := make([]*stripe.CheckoutSessionLineItemParams, len(orderedProducts))
-	for i, product := range orderedProducts {
-		if product.Status != store.ProductStatusActive {
-			err = store.ErrProductIsNotAvailable(product.Name)
+	if pricing.total == 0 {
+		err = store.ErrOrderTotalCantBeZero
+		return
+	}
+
+	orderID := guid.NewTimeBased()
+
+	var couponID *guid.GUID
+	if coupon != nil {
+		// the use of the coupon is reserved before creating the checkout session so the uses limit
+		// is never exceeded, and released if the order can't be placed or is canceled.
+		var couponReserved bool
+		couponReserved, err = service.repo.IncrementCouponUses(ctx, service.db, coupon.ID)
+		if err != nil {
 			return
 		}
-		stripeItems[i] = &stripe.CheckoutSessionLineItemParams{
-			PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
-				// Stripe uses lowercase codes for currencies. See stripe.CurrencyUSD for example.
-				Currency: stripe.String(strings.ToLower(string(currency))),
-				ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
-					Name: stripe.String(product.Name),
-					Metadata: map[string]string{
-						"markdown_ninja_product_id": product.ID.String(),
-						"markdown_ninja_website_id": website.ID.String(),
-						"markdown_ninja_order_id":   orderID.String(),
-						"markdown_ninja_contact_id": customer.ID.String(),
-					},
-				},
-				UnitAmount: stripe.Int64(product.Price * 100),
-			},
-			Quantity: stripe.Int64(1),
-			AdjustableQuantity: &stripe.CheckoutSessionLineItemAdjustableQuantityParams{
-				Enabled: stripe.Bool(true),
-				Minimum: stripe.Int64(1),
+		if !couponReserved {
+			err = store.ErrCouponUsesLimitReached
+			return
+		}
+		couponID = &coupon.ID
+
+		defer func() {
+			if err != nil {
+				errRelease := service.repo.DecrementCouponUses(context.Background(), service.db, coupon.ID)
+				if errRelease != nil {
+					logger.Error("store.PlaceOrder: releasing coupon use", slogx.Err(errRelease),
+						slog.String("coupon.id", coupon.ID.String()))
+				}
+			}
+		}()
+	}
+
+	checkoutLineItems := make([]payments.CheckoutSessionLineItem, len(pricing.lineItems))
+	for i, lineItem := range pricing.lineItems {
+		product := lineItem.product
+		checkoutLineItems[i] = payments.CheckoutSessionLineItem{
+			Name:               product.Name,
+			UnitAmount:         (product.Price - lineItem.discount) * 100,
+			Quantity:           1,
+			AdjustableQuantity: true,
+			BillingInterval:    nil,
+			Metadata: map[string]string{
+				"markdown_ninja_product_id": product.ID.String(),
+				"markdown_ninja_website_id": website.ID.String(),
+				"markdown_ninja_order_id":   orderID.String(),
+				"markdown_ninja_contact_id": customer.ID.String(),
 			},
 		}
+		if product.Type == store.ProductTypeMembership {
+			checkoutLineItems[i].BillingInterval = new(string(*product.BillingInterval))
+			checkoutLineItems[i].AdjustableQuantity = false
+		}
 	}
 
-	var customerEmail *string
-	var customerCreation *string
-	var updateStripeCustomer *stripe.CheckoutSessionCustomerUpdateParams
-	var stripeCustomerID *string
-	var invoiceData *stripe.CheckoutSessionInvoiceCreationInvoiceDataParams
 	var additionalInvoiceInformation string
-
 	if input.AdditionalInvoiceInformation != nil {
 		additionalInvoiceInformation = strings.TrimSpace(*input.AdditionalInvoiceInformation)
 		if additionalInvoiceInformation != "" {
@@ -139,90 +176,64 @@ func (service *StoreService) PlaceOrder(ctx context.Context, input store.PlaceOr
 			if err != nil {
 				return
 			}
-			invoiceData = &stripe.CheckoutSessionInvoiceCreationInvoiceDataParams{
-				Description: stripe.String(additionalInvoiceInformation),
-			}
 		}
 	}
 
-	// metdata for the different stripe entities to help customer support solving issues
-	stripeMetadata := map[string]string{
+	// metdata for the different entities of the payment provider to help customer support solving issues
+	paymentMetadata := map[string]string{
 		"markdown_ninja_website_id": website.ID.String(),
 		"markdown_ninja_order_id":   orderID.String(),
 		"markdown_ninja_contact_id": customer.ID.String(),
 		"country":                   httpCtx.Client.CountryCode,
 	}
+	if coupon != nil {
+		paymentMetadata["coupon"] = coupon.Code
+	}
+
+	createCheckoutSessionInput := payments.CreateCheckoutSessionInput{
+		Mode:               payments.CheckoutSessionModePayment,
+		Currency:           string(currency),
+		LineItems:          checkoutLineItems,
+		CustomerID:         nil,
+		CustomerEmail:      customer.Email,
+		InvoiceDescription: additionalInvoiceInformation,
+		SuccessURL:         service.generateCompleteOrderUrl(website.PrimaryDomain, orderID),
+		CancelURL:          service.generateCancelOrderUrl(website.PrimaryDomain, orderID),
+		Metadata:           paymentMetadata,
+	}
 
 	// TODO: if an account already exists for this email, make the user authenticate before redirecting
 	// to the payment page
 	if contactIsAuthenticated {
-		updateStripeCustomer = &stripe.CheckoutSessionCustomerUpdateParams{
-			Name:    stripe.String("auto"),
-			Address: stripe.String(string(stripe.CheckoutSessionBillingAddressCollectionAuto)),
-		}
-
 		if customer.StripeCustomerID != nil {
-			stripeCustomerID = customer.StripeCustomerID
+			createCheckoutSessionInput.CustomerID = customer.StripeCustomerID
 		} else {
-
-			// create customer
-			var stripeCustomer *stripe.Customer
-			newStripeCustomerParams := &stripe.CustomerParams{
-				Email:    &customer.Email,
-				Metadata: stripeMetadata,
-			}
-			stripeCustomer, err = stripecustomer.New(newStripeCustomerParams)
+			var paymentCustomer payments.Customer
+			paymentCustomer, err = service.paymentProvider.CreateCustomer(ctx, payments.CreateCustomerInput{
+				Email:    customer.Email,
+				Metadata: paymentMetadata,
+			})
 			if err != nil {
-				err = fmt.Errorf("store.PlaceOrder: error creating Stripe customer: %w", err)
+				err = fmt.Errorf("store.PlaceOrder: error creating customer: %w", err)
 				return
 			}
-			stripeCustomerID = &stripeCustomer.ID
+			createCheckoutSessionInput.CustomerID = &paymentCustomer.ID
 		}
-	} else {
-		customerEmail = &customer.Email
-		customerCreation = stripe.String(string(stripe.CheckoutSessionCustomerCreationIfRequired))
 	}
 
-	createStripeCheckoutSessionParams := &stripe.CheckoutSessionParams{
-		CustomerEmail:    customerEmail,
-		Customer:         stripeCustomerID,
-		CustomerCreation: customerCreation,
-		CustomerUpdate:   updateStripeCustomer,
-		// BillingAddressCollection: stripe.String(string(stripe.CheckoutSessionBillingAddressCollection)),
-		// PaymentMethodTypes: []*string{
-		// 	stripe.String(string(stripe.PaymentMethodTypeCard)),
-		// 	// stripe.String(string(stripe.PaymentMethodTypePaypal)),
-		// 	// stripe.String(string(stripe.PaymentMethodCardWalletTypeApplePay)),
-		// 	// stripe.String(string(stripe.PaymentMethodCardWalletTypeGooglePay)),
-
-		// 	// iDeal only workd for EUR payments
-		// 	// stripe.String(string(stripe.PaymentMethodTypeIDEAL)),
-		// 	// stripe.String(string(stripe.PaymentMethodTypeLink)),
-		// },
-
-		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
-			Metadata: stripeMetadata,
-		},
-		LineItems:  stripeItems,
-		Mode:       stripe.String(string(stripe.CheckoutSessionModePayment)),
-		SuccessURL: stripe.String(service.generateCompleteOrderUrl(website.PrimaryDomain, orderID)),
-		CancelURL:  stripe.String(service.generateCancelOrderUrl(website.PrimaryDomain, orderID)),
-		AutomaticTax: &stripe.CheckoutSessionAutomaticTaxParams{
-			Enabled: stripe.Bool(false),
-		},
-		InvoiceCreation: &stripe.CheckoutSessionInvoiceCreationParams{
-			Enabled:     stripe.Bool(true),
-			InvoiceData: invoiceData,
-		},
-		// CustomerUpdate: &stripe.CheckoutSessionCustomerUpdateParams{
-		// 	Address: stripe.String("auto"),
-		// },
-		Metadata: stripeMetadata,
+	if membershipProduct != nil {
+		// memberships are billed through a subscription, and the metadata of the subscription is used
+		// to keep the membership in sync when the subscription is renewed, updated or canceled.
+		subscriptionMetadata := maps.Clone(paymentMetadata)
+		subscriptionMetadata["markdown_ninja_product_id"] = membershipProduct.ID.String()
+
+		createCheckoutSessionInput.Mode = payments.CheckoutSessionModeSubscription
+		createCheckoutSessionInput.SubscriptionMetadata = subscriptionMetadata
 	}
-	createStripeCheckoutSessionParams.AddExpand("payment_intent")
-	stripeCheckoutSession, err := session.New(createStripeCheckoutSessionParams)
+
+	checkoutSession, err := service.paymentProvider.CreateCheckoutSession(ctx, createCheckoutSessionInput)
 	if err != nil {
-		errMessage := "store.CreateStripeCheckoutSession: creating stripe checkout session"
+		errMessage := "store.PlaceOrder: creating checkout session"
 		logger.Error(errMessage, slogx.Err(err))
 		err = errs.Internal(errMessage, err)
 		return
@@ -233,7 +244,9 @@ func (service *StoreService) PlaceOrder(ctx context.Context, input store.PlaceOr
 		ID:                           orderID,
 		CreatedAt:                    now,
 		UpdatedAt:                    now,
-		TotalAmount:                  totalAmount,
+		TotalAmount:                  pricing.total,
+		DiscountAmount:               pricing.discount,
+		CouponID:                     couponID,
 		Currency:                     currency,
 		Notes:                        "",
 		Status:                       store.OrderStatusPending,
@@ -242,10 +255,13 @@ func (service *StoreService) PlaceOrder(ctx context.Context, input store.PlaceOr
 		Email:                        customer.Email,
 		Country:                      httpCtx.Client.CountryCode,
 		AdditionalInvoiceInformation: additionalInvoiceInformation,
-		StripeCheckoutSessionID:      stripeCheckoutSession.ID,
+		StripeCheckoutSessionID:      checkoutSession.ID,
 		StripPaymentItentID:          nil,
 		StripeInvoiceID:              nil,
 		StripeInvoiceUrl:             nil,
+		GiftRecipientEmail:           giftRecipientEmail,
+		GiftMessage:                  giftMessage,
+		GiftRecipientContactID:       nil,
 		WebsiteID:                    website.ID,
 		ContactID:                    customer.ID,
 	}
@@ -256,7 +272,7 @@ func (service *StoreService) PlaceOrder(ctx context.Context, input store.PlaceOr
 		}
 
 		for _, product := range orderedProducts {
-			// TODO: we need a way to adjust quantities before creating the stripe checkout session
+			// TODO: we need a way to adjust quantities before creating the checkout session
 			lineItem := store.OrderLineItem{
 				ProductName:          product.Name,
 				OriginalProductPrice: product.Price,
@@ -283,7 +299,7 @@ func (service *StoreService) PlaceOrder(ctx context.Context, input store.PlaceOr
 		Country:   httpCtx.Client.CountryCode,
 	})
 
-	output.StripeCheckoutUrl = stripeCheckoutSession.URL
+	output.StripeCheckoutUrl = checkoutSession.URL
 
 	return
 }
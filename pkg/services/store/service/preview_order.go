@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) PreviewOrder(ctx context.Context, input store.PreviewOrderInput) (output store.PreviewOrderOutput, err error) {
+	httpCtx := httpctx.FromCtx(ctx)
+
+	website, err := service.websitesService.FindWebsiteByDomain(ctx, service.db, httpCtx.Hostname)
+	if err != nil {
+		return
+	}
+
+	products, err := service.findProductsForOrder(ctx, website.ID, input.Products)
+	if err != nil {
+		return
+	}
+
+	currency, pricedProducts, err := resolveOrderPrices(website.Currency, httpCtx.Client.CountryCode, products,
+		input.CustomPrices)
+	if err != nil {
+		return
+	}
+
+	var coupon *store.Coupon
+	if input.Coupon != nil && strings.TrimSpace(*input.Coupon) != "" {
+		var existingCoupon store.Coupon
+		existingCoupon, err = service.findCouponForOrder(ctx, service.db, website.ID, *input.Coupon)
+		if err != nil {
+			return
+		}
+		coupon = &existingCoupon
+	}
+
+	pricing, err := priceOrder(pricedProducts, coupon)
+	if err != nil {
+		return
+	}
+
+	output = store.PreviewOrderOutput{
+		Currency:       currency,
+		SubtotalAmount: pricing.subtotal,
+		DiscountAmount: pricing.discount,
+		TotalAmount:    pricing.total,
+		Coupon:         nil,
+		LineItems:      make([]store.PreviewLineItem, len(pricing.lineItems)),
+	}
+	if coupon != nil {
+		output.Coupon = &coupon.Code
+	}
+	for i, lineItem := range pricing.lineItems {
+		minimumPrice, _ := products[i].PriceIn(website.Currency, currency)
+		output.LineItems[i] = store.PreviewLineItem{
+			ProductID:      lineItem.product.ID,
+			Name:           lineItem.product.Name,
+			Price:          lineItem.product.Price,
+			DiscountAmount: lineItem.discount,
+			PayWhatYouWant: lineItem.product.PayWhatYouWant,
+			MinimumPrice:   minimumPrice,
+		}
+	}
+
+	return
+}
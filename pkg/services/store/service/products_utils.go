@@ -18,5 +18,15 @@ func (service *StoreService) hydrateProduct(ctx context.Context, db db.Queryer,
 		return err
 	}
 
+	product.Ebooks, err = service.repo.FindProductEbooksForProduct(ctx, db, product.ID)
+	if err != nil {
+		return err
+	}
+
+	err = service.hydrateBundleItems(ctx, db, product)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/skerkour/stdx-go/retry"
+	"markdown.ninja/pkg/payments"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/store"
+)
+
+// ResumeOrder returns the URL of the checkout session of the order if it's still open. Otherwise
+// (e.g. the checkout session has expired) it returns the path of the checkout page of the website
+// pre-filled with the products of the order so the customer can place a new order.
+// Only the customer, or the recipient of the recovery email of the order, can resume the order.
+func (service *StoreService) ResumeOrder(ctx context.Context, input store.ResumeOrderInput) (ret store.ResumeOrderOutput, err error) {
+	httpCtx := httpctx.FromCtx(ctx)
+
+	website, err := service.websitesService.FindWebsiteByDomain(ctx, service.db, httpCtx.Hostname)
+	if err != nil {
+		return
+	}
+
+	order, err := service.repo.FindOrderByID(ctx, service.db, input.OrderID, false)
+	if err != nil {
+		return
+	}
+
+	if !order.WebsiteID.Equal(website.ID) {
+		err = store.ErrOrderNotFound(input.OrderID)
+		return
+	}
+
+	// the checkout session is pre-filled with the email of the customer, so only the customer or the
+	// recipient of the recovery email can resume the order
+	contact := service.contactsService.CurrentContact(ctx)
+	authorized := contact != nil && contact.ID.Equal(order.ContactID)
+	if !authorized && input.Token != nil {
+		authorized = service.verifyResumeOrderToken(*input.Token, order.ID)
+	}
+	if !authorized {
+		err = store.ErrOrderNotFound(input.OrderID)
+		return
+	}
+
+	if order.Status == store.OrderStatusCompleted {
+		err = store.ErrOrderIsAlreadyCompleted
+		return
+	}
+
+	if order.Status == store.OrderStatusPending {
+		var checkoutSession payments.CheckoutSession
+		err = retry.Do(func() (retryErr error) {
+			checkoutSession, retryErr = service.paymentProvider.GetCheckoutSession(ctx, order.StripeCheckoutSessionID)
+			return retryErr
+		}, retry.Context(ctx), retry.Attempts(3), retry.Delay(20*time.Millisecond))
+		if err != nil {
+			err = fmt.Errorf("store.ResumeOrder: error getting checkout session: %w", err)
+			return
+		}
+
+		if checkoutSession.Status == payments.CheckoutSessionStatusOpen && checkoutSession.URL != "" {
+			ret.CheckoutUrl = checkoutSession.URL
+			return
+		}
+	}
+
+	lineItems, err := service.repo.FindOrderLineItems(ctx, service.db, order.ID)
+	if err != nil {
+		return
+	}
+
+	products := make([]string, len(lineItems))
+	for i, lineItem := range lineItems {
+		products[i] = lineItem.ProductID.String()
+	}
+
+	query := url.Values{}
+	query.Set("products", strings.Join(products, ","))
+	if order.CouponID != nil {
+		coupon, errCoupon := service.repo.FindCouponByID(ctx, service.db, *order.CouponID)
+		if errCoupon == nil {
+			query.Set("coupon", coupon.Code)
+		}
+	}
+	ret.CheckoutUrl = "/checkout?" + query.Encode()
+
+	return
+}
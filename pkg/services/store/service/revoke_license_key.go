@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) RevokeLicenseKey(ctx context.Context, input store.RevokeLicenseKeyInput) (licenseKey store.LicenseKey, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	licenseKey, err = service.repo.FindLicenseKeyByID(ctx, service.db, input.ID)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, licenseKey.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	if licenseKey.Status == store.LicenseKeyStatusRevoked {
+		err = store.ErrLicenseKeyIsAlreadyRevoked
+		return
+	}
+
+	now := time.Now().UTC()
+	licenseKey.UpdatedAt = now
+	licenseKey.Status = store.LicenseKeyStatusRevoked
+	licenseKey.RevokedAt = &now
+	err = service.repo.UpdateLicenseKey(ctx, service.db, licenseKey)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/httpx"
+	"markdown.ninja/pkg/server/apiutil"
+	"markdown.ninja/pkg/server/cachecontrol"
+	"markdown.ninja/pkg/services/store"
+)
+
+// ServeInvoice serves the PDF or HTML document of an invoice. It's used both by the admin API for the
+// staff of the website and by the websites for the contacts who placed the order.
+func (service *StoreService) ServeInvoice(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	invoiceID, err := guid.Parse(chi.URLParam(req, "invoice_id"))
+	if err != nil {
+		apiutil.SendError(ctx, res, store.ErrInvoiceNotFound)
+		return
+	}
+
+	// access to the invoice is checked by GetInvoiceFile
+	invoiceFile, err := service.GetInvoiceFile(ctx, store.GetInvoiceFileInput{
+		ID:     invoiceID,
+		Format: store.InvoiceFormat(chi.URLParam(req, "format")),
+	})
+	if err != nil {
+		apiutil.SendError(ctx, res, err)
+		return
+	}
+	defer invoiceFile.Data.Close()
+
+	res.Header().Set(httpx.HeaderCacheControl, cachecontrol.NoCache)
+	res.Header().Set(httpx.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s", strconv.Quote(invoiceFile.Filename)))
+	res.Header().Set(httpx.HeaderContentType, invoiceFile.MediaType)
+	res.Header().Set(httpx.HeaderContentLength, strconv.FormatInt(invoiceFile.Size, 10))
+	res.WriteHeader(http.StatusOK)
+	io.Copy(res, invoiceFile.Data)
+}
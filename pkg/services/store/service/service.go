@@ -2,13 +2,14 @@ package service
 
 import (
 	"fmt"
-	"text/template"
+	htmltemplate "html/template"
 
 	"github.com/skerkour/stdx-go/db"
 	"github.com/skerkour/stdx-go/queue"
 	"markdown.ninja/cmd/mdninja-server/config"
-	"markdown.ninja/pingoo-go"
+	"markdown.ninja/pkg/jwt"
 	"markdown.ninja/pkg/mailer"
+	"markdown.ninja/pkg/payments"
 	"markdown.ninja/pkg/ratelimit"
 	"markdown.ninja/pkg/services/contacts"
 	"markdown.ninja/pkg/services/content"
@@ -19,13 +20,15 @@ import (
 	"markdown.ninja/pkg/services/store/notifications"
 	"markdown.ninja/pkg/services/store/repository"
 	"markdown.ninja/pkg/services/websites"
+	"markdown.ninja/pkg/storage"
 )
 
 type StoreService struct {
 	repo   repository.StoreRepository
 	db     db.DB
 	queue  queue.Queue
-	mailer mailer.Mailer
+	mailer  mailer.Mailer
+	storage storage.Storage
 
 	websitesService      websites.Service
 	kernel               kernel.PrivateService
@@ -34,22 +37,31 @@ type StoreService struct {
 	eventsService        events.Service
 	emailsService        emails.Service
 	organizationsService organizations.Service
-	pingoo               *pingoo.Client
+	paymentProvider      payments.Provider
 
 	httpConfig                     config.Http
 	websitesPort                   string
-	orderConfirmationEmailTemplate *template.Template
-	rateLimiter                    *ratelimit.Limiter
+	abandonedCheckoutEmailTemplate *htmltemplate.Template
+	giftNotificationEmailTemplate  *htmltemplate.Template
+	rateLimiter                    ratelimit.Limiter
+	jwtProvider                    *jwt.Provider
 }
 
-func NewStoreService(db db.DB, queue queue.Queue, conf config.Config, mailer mailer.Mailer, kernel kernel.PrivateService, websitesService websites.Service,
+func NewStoreService(db db.DB, queue queue.Queue, conf config.Config, mailer mailer.Mailer, storage storage.Storage, kernel kernel.PrivateService, websitesService websites.Service,
 	contentService content.Service, contactsService contacts.Service, eventsService events.Service,
-	emailsService emails.Service, organizationsService organizations.Service, pingoo *pingoo.Client) (service *StoreService, err error) {
+	emailsService emails.Service, organizationsService organizations.Service,
+	rateLimiter ratelimit.Limiter, paymentProvider payments.Provider, jwtProvider *jwt.Provider) (service *StoreService, err error) {
 	repo := repository.NewStoreRepository()
 
-	orderConfirmationEmailTemplate, err := template.New("store.OrderConfirmationEmailTemplate").Parse(notifications.OrderConfirmationEmailTemplate)
+	abandonedCheckoutEmailTemplate, err := htmltemplate.New("store.AbandonedCheckoutEmailTemplate").Parse(notifications.AbandonedCheckoutEmailTemplate)
 	if err != nil {
-		err = fmt.Errorf("store.NewService: Parsing orderConfirmationEmailTemplate: %w", err)
+		err = fmt.Errorf("store.NewService: Parsing abandonedCheckoutEmailTemplate: %w", err)
+		return
+	}
+
+	giftNotificationEmailTemplate, err := htmltemplate.New("store.GiftNotificationEmailTemplate").Parse(notifications.GiftNotificationEmailTemplate)
+	if err != nil {
+		err = fmt.Errorf("store.NewService: Parsing giftNotificationEmailTemplate: %w", err)
 		return
 	}
 
@@ -57,7 +69,8 @@ func NewStoreService(db db.DB, queue queue.Queue, conf config.Config, mailer mai
 		repo:   repo,
 		db:     db,
 		queue:  queue,
-		mailer: mailer,
+		mailer:  mailer,
+		storage: storage,
 
 		kernel:               kernel,
 		websitesService:      websitesService,
@@ -66,12 +79,14 @@ func NewStoreService(db db.DB, queue queue.Queue, conf config.Config, mailer mai
 		eventsService:        eventsService,
 		emailsService:        emailsService,
 		organizationsService: organizationsService,
-		pingoo:               pingoo,
+		paymentProvider:      paymentProvider,
 
 		httpConfig:                     conf.HTTP,
 		websitesPort:                   conf.HTTP.WebsitesPort,
-		orderConfirmationEmailTemplate: orderConfirmationEmailTemplate,
-		rateLimiter:                    ratelimit.New(),
+		abandonedCheckoutEmailTemplate: abandonedCheckoutEmailTemplate,
+		giftNotificationEmailTemplate:  giftNotificationEmailTemplate,
+		rateLimiter:                    rateLimiter,
+		jwtProvider:                    jwtProvider,
 	}
 	return
 }
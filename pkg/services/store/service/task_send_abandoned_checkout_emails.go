@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/queue"
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) TaskSendAbandonedCheckoutEmails(ctx context.Context) {
+	logger := slogx.FromCtx(ctx)
+	now := time.Now().UTC()
+
+	abandonedOrders, err := service.repo.FindAbandonedOrders(ctx, service.db, now, store.AbandonedCheckoutsTaskBatchSize)
+	if err != nil {
+		logger.Error("store.TaskSendAbandonedCheckoutEmails: finding abandoned orders", slogx.Err(err))
+		return
+	}
+
+	if len(abandonedOrders) == 0 {
+		return
+	}
+
+	jobs := make([]queue.NewJobInput, len(abandonedOrders))
+	for i, order := range abandonedOrders {
+		jobs[i] = queue.NewJobInput{
+			Data: store.JobSendAbandonedCheckoutEmail{
+				OrderID: order.ID,
+			},
+		}
+	}
+
+	err = service.queue.PushMany(ctx, nil, jobs)
+	if err != nil {
+		logger.Error("store.TaskSendAbandonedCheckoutEmails: pushing jobs to queue", slogx.Err(err))
+		return
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/skerkour/stdx-go/db"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/store"
+)
+
+// TransferProductAccess moves the access to a product from a contact to another one. If the product is
+// a bundle, the access to the products it included when it was ordered is also transferred. The date of the access is kept
+// so the drip content of courses is not reset.
+func (service *StoreService) TransferProductAccess(ctx context.Context, input store.TransferProductAccessInput) (err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	product, err := service.repo.FindProductByID(ctx, service.db, input.ProductID)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, product.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	fromEmail := strings.ToLower(strings.TrimSpace(input.FromEmail))
+	toEmail := strings.ToLower(strings.TrimSpace(input.ToEmail))
+	if fromEmail == toEmail {
+		err = store.ErrProductAccessTransferIsNotValid
+		return
+	}
+
+	err = service.kernel.ValidateEmail(ctx, toEmail, false)
+	if err != nil {
+		return
+	}
+
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		fromContact, txErr := service.contactsService.FindContactByEmail(ctx, tx, product.WebsiteID, fromEmail)
+		if txErr != nil {
+			return txErr
+		}
+
+		// the contact must have access to the product itself, not only to some items of the bundle
+		_, txErr = service.repo.FindContactProductAccess(ctx, tx, fromContact.ID, product.ID)
+		if txErr != nil {
+			return txErr
+		}
+
+		toContact, txErr := service.findOrCreateVerifiedContact(ctx, tx, product.WebsiteID, toEmail)
+		if txErr != nil {
+			return txErr
+		}
+
+		fromContactGrantedProducts, txErr := service.repo.FindOrderGrantedProductsForContact(ctx, tx, fromContact.ID)
+		if txErr != nil {
+			return txErr
+		}
+
+		grantedProductsToTransfer := grantedProductsToTransfer(product, fromContactGrantedProducts)
+		productsToTransfer, txErr := service.productsToTransfer(ctx, tx, product, grantedProductsToTransfer)
+		if txErr != nil {
+			return txErr
+		}
+
+		// the orders now give access to the new contact, so that a refund revokes the access of the new contact
+		for _, grantedProduct := range transferGrantedProducts(grantedProductsToTransfer, toContact.ID) {
+			txErr = service.repo.UpdateOrderGrantedProduct(ctx, tx, grantedProduct)
+			if txErr != nil {
+				return txErr
+			}
+		}
+
+		for _, productID := range productsToTransfer {
+			var productAccess store.ContactProductAccess
+			productAccess, txErr = service.repo.FindContactProductAccess(ctx, tx, fromContact.ID, productID)
+			if txErr != nil {
+				if errs.IsNotFound(txErr) {
+					continue
+				}
+				return txErr
+			}
+
+			// the contact keeps the access to the products granted by the other orders
+			stillGranted := slices.ContainsFunc(fromContactGrantedProducts, func(grantedProduct store.OrderGrantedProduct) bool {
+				return grantedProduct.ProductID.Equal(productID) &&
+					!slices.ContainsFunc(grantedProductsToTransfer, func(transferred store.OrderGrantedProduct) bool {
+						return transferred.ID.Equal(grantedProduct.ID)
+					})
+			})
+			if !stillGranted {
+				txErr = service.repo.DeleteAccessToProduct(ctx, tx, productAccess)
+				if txErr != nil {
+					return txErr
+				}
+			}
+
+			txErr = service.giveContactAccessToProduct(ctx, tx, toContact.ID, productID, productAccess.CreatedAt)
+			if txErr != nil {
+				return txErr
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) UpdateAbandonedCheckoutSettings(ctx context.Context, input store.UpdateAbandonedCheckoutSettingsInput) (settings store.AbandonedCheckoutSettings, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	settings, err = service.findAbandonedCheckoutSettings(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	if input.Enabled != nil {
+		settings.Enabled = *input.Enabled
+	}
+
+	if input.DelayHours != nil {
+		err = service.validateAbandonedCheckoutDelayHours(*input.DelayHours)
+		if err != nil {
+			return
+		}
+		settings.DelayHours = *input.DelayHours
+	}
+
+	if input.Subject != nil {
+		subject := strings.TrimSpace(*input.Subject)
+		err = service.validateAbandonedCheckoutSubject(subject)
+		if err != nil {
+			return
+		}
+		settings.Subject = subject
+	}
+
+	if input.BodyMarkdown != nil {
+		bodyMarkdown := strings.TrimSpace(*input.BodyMarkdown)
+		err = service.validateAbandonedCheckoutBodyMarkdown(bodyMarkdown)
+		if err != nil {
+			return
+		}
+		settings.BodyMarkdown = bodyMarkdown
+	}
+
+	settings.UpdatedAt = time.Now().UTC()
+	err = service.repo.UpsertAbandonedCheckoutSettings(ctx, service.db, settings)
+	if err != nil {
+		return
+	}
+
+	return
+}
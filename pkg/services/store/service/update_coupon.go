@@ -71,6 +71,14 @@ func (service *StoreService) UpdateCoupon(ctx context.Context, input store.Updat
 		coupon.Discount = discount
 	}
 
+	if input.UsesLimit != nil {
+		err = service.validateCouponUsesLimit(*input.UsesLimit)
+		if err != nil {
+			return
+		}
+		coupon.UsesLimit = *input.UsesLimit
+	}
+
 	if input.ExpiresAt != nil {
 		expiresAt := *input.ExpiresAt
 		err = service.validateCouponExpiryDate(expiresAt)
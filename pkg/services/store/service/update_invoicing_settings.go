@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"markdown.ninja/pkg/services/store"
+)
+
+func (service *StoreService) UpdateInvoicingSettings(ctx context.Context, input store.UpdateInvoicingSettingsInput) (settings store.InvoicingSettings, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err != nil {
+		return
+	}
+
+	err = service.websitesService.CheckUserIsStaff(ctx, service.db, actorID, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	settings, err = service.findInvoicingSettings(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	if input.Enabled != nil {
+		settings.Enabled = *input.Enabled
+	}
+
+	if input.SellerName != nil {
+		sellerName := strings.TrimSpace(*input.SellerName)
+		err = service.validateInvoiceSellerName(sellerName)
+		if err != nil {
+			return
+		}
+		settings.SellerName = sellerName
+	}
+
+	if input.SellerAddress != nil {
+		sellerAddress := strings.TrimSpace(*input.SellerAddress)
+		err = service.validateInvoiceSellerAddress(sellerAddress)
+		if err != nil {
+			return
+		}
+		settings.SellerAddress = sellerAddress
+	}
+
+	if input.SellerVatNumber != nil {
+		vatNumber := strings.TrimSpace(*input.SellerVatNumber)
+		err = service.validateInvoiceSellerVatNumber(vatNumber)
+		if err != nil {
+			return
+		}
+		settings.SellerVatNumber = vatNumber
+	}
+
+	if input.VatRate != nil {
+		err = service.validateInvoiceVatRate(*input.VatRate)
+		if err != nil {
+			return
+		}
+		settings.VatRate = *input.VatRate
+	}
+
+	if input.Notes != nil {
+		notes := strings.TrimSpace(*input.Notes)
+		err = service.validateInvoiceNotes(notes)
+		if err != nil {
+			return
+		}
+		settings.Notes = notes
+	}
+
+	if input.InvoiceNumberPrefix != nil {
+		prefix := strings.TrimSpace(*input.InvoiceNumberPrefix)
+		err = service.validateInvoiceNumberPrefix(prefix)
+		if err != nil {
+			return
+		}
+		settings.InvoiceNumberPrefix = prefix
+	}
+
+	if input.CreditNoteNumberPrefix != nil {
+		prefix := strings.TrimSpace(*input.CreditNoteNumberPrefix)
+		err = service.validateInvoiceNumberPrefix(prefix)
+		if err != nil {
+			return
+		}
+		settings.CreditNoteNumberPrefix = prefix
+	}
+
+	// invoices can't be issued without the details of the seller
+	if settings.Enabled && settings.SellerName == "" {
+		err = store.ErrInvoiceSellerNameIsRequired
+		return
+	}
+
+	settings.UpdatedAt = time.Now().UTC()
+	err = service.repo.UpsertInvoicingSettings(ctx, service.db, settings)
+	if err != nil {
+		return
+	}
+
+	// the counters are not updated by UpsertInvoicingSettings
+	settings, err = service.repo.FindInvoicingSettings(ctx, service.db, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/store"
+)
+
+// UpdateLessonProgress marks a lesson (page) of a course as completed or not completed for a contact.
+// Locked lessons (drip content) can't be completed.
+func (service *StoreService) UpdateLessonProgress(ctx context.Context, db db.Queryer, input store.UpdateLessonProgressInput) (err error) {
+	page, err := service.repo.FindProductPageByID(ctx, db, input.ProductPageID)
+	if err != nil {
+		return
+	}
+
+	product, err := service.repo.FindProductByID(ctx, db, page.ProductID)
+	if err != nil {
+		return
+	}
+
+	access, err := service.repo.FindContactProductAccess(ctx, db, input.ContactID, product.ID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			err = store.ErrProductPageNotFound
+		}
+		return
+	}
+
+	if product.Type != store.ProductTypeCourse {
+		err = store.ErrProductIsNotACourse
+		return
+	}
+
+	if !input.Completed {
+		err = service.repo.DeleteProductPageCompletion(ctx, db, input.ContactID, page.ID)
+		return
+	}
+
+	now := time.Now().UTC()
+	if page.IsLocked(access.CreatedAt, now) {
+		err = store.ErrLessonIsLocked(page.AvailableAt(access.CreatedAt))
+		return
+	}
+
+	completion := store.ProductPageCompletion{
+		CompletedAt:   now,
+		ContactID:     input.ContactID,
+		ProductPageID: page.ID,
+		ProductID:     product.ID,
+	}
+	err = service.repo.CreateProductPageCompletion(ctx, db, completion)
+	if err != nil {
+		return
+	}
+
+	return
+}
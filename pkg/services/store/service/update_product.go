@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/skerkour/stdx-go/db"
 	"markdown.ninja/pkg/server/httpctx"
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/store"
@@ -76,16 +77,111 @@ func (service *StoreService) UpdateProduct(ctx context.Context, input store.Upda
 		product.Price = price
 	}
 
+	if input.Prices != nil {
+		var website websites.Website
+		website, err = service.websitesService.FindWebsiteByID(ctx, service.db, product.WebsiteID)
+		if err != nil {
+			return
+		}
+
+		err = service.validateProductPrices(website.Currency, input.Prices)
+		if err != nil {
+			return
+		}
+		product.Prices = input.Prices
+	}
+
+	if input.PayWhatYouWant != nil {
+		product.PayWhatYouWant = *input.PayWhatYouWant
+	}
+
+	err = service.validatePayWhatYouWantPrices(product)
+	if err != nil {
+		return
+	}
+
 	if input.Status != nil {
 		product.Status = *input.Status
 	}
 
+	if input.EbookWatermark != nil {
+		if product.Type != store.ProductTypeBook {
+			err = store.ErrEbooksAreOnlyAvailableForBooks
+			return
+		}
+		product.EbookWatermark = *input.EbookWatermark
+	}
+
+	if input.BillingInterval != nil {
+		if product.Type != store.ProductTypeMembership {
+			err = store.ErrBillingIntervalIsOnlyForMemberships
+			return
+		}
+		err = service.validateBillingInterval(*input.BillingInterval)
+		if err != nil {
+			return
+		}
+		product.BillingInterval = input.BillingInterval
+	}
+
+	if input.LicenseKeys != nil {
+		if product.Type != store.ProductTypeDigitalDownload {
+			err = store.ErrLicenseKeysAreOnlyForDigitalDownloads
+			return
+		}
+		err = service.validateLicenseKeysMode(*input.LicenseKeys)
+		if err != nil {
+			return
+		}
+		product.LicenseKeys = *input.LicenseKeys
+	}
+
+	if input.LicenseKeyActivationsLimit != nil {
+		if product.Type != store.ProductTypeDigitalDownload {
+			err = store.ErrLicenseKeysAreOnlyForDigitalDownloads
+			return
+		}
+		err = service.validateLicenseKeyActivationsLimit(*input.LicenseKeyActivationsLimit)
+		if err != nil {
+			return
+		}
+		product.LicenseKeyActivationsLimit = *input.LicenseKeyActivationsLimit
+	}
+
+	if input.BundleItems != nil {
+		if product.Type != store.ProductTypeBundle {
+			err = store.ErrBundleItemsAreOnlyForBundles
+			return
+		}
+		err = service.validateBundleItems(ctx, service.db, product, input.BundleItems)
+		if err != nil {
+			return
+		}
+	}
+
 	product.UpdatedAt = now
-	err = service.repo.UpdateProduct(ctx, service.db, product)
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		txErr = service.repo.UpdateProduct(ctx, tx, product)
+		if txErr != nil {
+			return txErr
+		}
+
+		if input.BundleItems != nil {
+			txErr = service.setBundleItems(ctx, tx, &product, input.BundleItems)
+			if txErr != nil {
+				return txErr
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
 		return
 	}
 
+	// the name and description of the product are used in the ebooks
+	service.scheduleProductEbooksGeneration(ctx, product)
+
 	err = service.hydrateProduct(ctx, service.db, &product)
 	if err != nil {
 		return
@@ -53,10 +53,20 @@ func (service *StoreService) UpdateProductPage(ctx context.Context, input store.
 		page.Hash = bodyHash[:]
 	}
 
+	if input.DripDays != nil {
+		page.DripDays = *input.DripDays
+		err = service.validateProductPageDripDays(page.DripDays)
+		if err != nil {
+			return
+		}
+	}
+
 	err = service.repo.UpdateProductPage(ctx, service.db, page)
 	if err != nil {
 		return
 	}
 
+	service.scheduleProductEbooksGeneration(ctx, product)
+
 	return
 }
@@ -7,7 +7,9 @@ import (
 	"unicode/utf8"
 
 	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/invoicing"
 	"markdown.ninja/pkg/services/store"
+	"markdown.ninja/pkg/services/websites"
 )
 
 func validateAdditionalInvoiceInformation(additionalInformation string) error {
@@ -49,13 +51,22 @@ func (service *StoreService) validateProductDescription(description string) erro
 
 func (service *StoreService) validateProductType(productType store.ProductType) error {
 	if productType != store.ProductTypeBook && productType != store.ProductTypeCourse &&
-		productType != store.ProductTypeDigitalDownload {
+		productType != store.ProductTypeDigitalDownload && productType != store.ProductTypeMembership &&
+		productType != store.ProductTypeBundle {
 		return store.ErrProductTypeIsNotValid
 	}
 
 	return nil
 }
 
+func (service *StoreService) validateBillingInterval(billingInterval store.BillingInterval) error {
+	if billingInterval != store.BillingIntervalMonth && billingInterval != store.BillingIntervalYear {
+		return store.ErrBillingIntervalIsNotValid
+	}
+
+	return nil
+}
+
 func (service *StoreService) validateProductPrice(price int64) error {
 	if price < 0 {
 		return store.ErrProductPriceCantBeNegative
@@ -68,6 +79,41 @@ func (service *StoreService) validateProductPrice(price int64) error {
 	return nil
 }
 
+func (service *StoreService) validateProductPrices(websiteCurrency websites.Currency, prices store.ProductPrices) error {
+	for currency, price := range prices {
+		if currency == websiteCurrency || !websites.AllCurrencies.Contains(currency) {
+			return store.ErrProductPricesAreNotValid
+		}
+
+		err := service.validateProductPrice(price)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validatePayWhatYouWantPrices rejects a minimum price of 0, in any currency, for pay-what-you-want
+// products as orders can't be completed without a payment.
+func (service *StoreService) validatePayWhatYouWantPrices(product store.Product) error {
+	if !product.PayWhatYouWant {
+		return nil
+	}
+
+	if product.Price == 0 {
+		return store.ErrPayWhatYouWantMinimumPriceCantBeZero
+	}
+
+	for _, price := range product.Prices {
+		if price == 0 {
+			return store.ErrPayWhatYouWantMinimumPriceCantBeZero
+		}
+	}
+
+	return nil
+}
+
 func (service *StoreService) validateCouponCode(code string) error {
 	if len(code) < store.CouponCodeMinLength {
 		return store.ErrCouponCodeIsTooShort
@@ -104,14 +150,23 @@ func (service *StoreService) validateCouponDescription(description string) error
 	return nil
 }
 
+// validateCouponDiscount rejects 100% discounts as orders can't be completed without a payment.
 func (service *StoreService) validateCouponDiscount(discount int64) error {
-	if discount < 1 || discount > 100 {
+	if discount < 1 || discount > 99 {
 		return store.ErrCouponDiscountIsNotValid
 	}
 
 	return nil
 }
 
+func (service *StoreService) validateCouponUsesLimit(usesLimit int64) error {
+	if usesLimit < 0 {
+		return store.ErrCouponUsesLimitIsNotValid
+	}
+
+	return nil
+}
+
 func (service *StoreService) validateCouponExpiryDate(expiryDate time.Time) error {
 	now := time.Now().UTC()
 
@@ -144,6 +199,151 @@ func (service *StoreService) validateProductPageContent(content string) error {
 	return nil
 }
 
+func (service *StoreService) validateProductPageDripDays(dripDays int64) error {
+	if dripDays < 0 || dripDays > store.ProductPageDripDaysMax {
+		return store.ErrProductPageDripDaysIsNotValid
+	}
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+// License keys
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (service *StoreService) validateLicenseKeysMode(mode store.LicenseKeysMode) error {
+	switch mode {
+	case store.LicenseKeysModeNone, store.LicenseKeysModeGenerated, store.LicenseKeysModePool:
+		return nil
+	default:
+		return store.ErrLicenseKeysModeIsNotValid
+	}
+}
+
+func (service *StoreService) validateLicenseKeyActivationsLimit(limit int64) error {
+	if limit < 0 || limit > store.LicenseKeyActivationsLimitMax {
+		return store.ErrLicenseKeyActivationsLimitIsNotValid
+	}
+
+	return nil
+}
+
+func (service *StoreService) validateLicenseKey(key string) error {
+	if key == "" || len(key) > store.LicenseKeyMaxLength || !utf8.ValidString(key) {
+		return store.ErrLicenseKeyIsNotValid
+	}
+
+	return nil
+}
+
+func (service *StoreService) validateLicenseKeyInstance(instance string) error {
+	if instance == "" || len(instance) > store.LicenseKeyInstanceMaxLength || !utf8.ValidString(instance) {
+		return store.ErrLicenseKeyInstanceIsNotValid
+	}
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+// Abandoned checkouts
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (service *StoreService) validateAbandonedCheckoutDelayHours(delayHours int64) error {
+	if delayHours < store.AbandonedCheckoutDelayHoursMin || delayHours > store.AbandonedCheckoutDelayHoursMax {
+		return store.ErrAbandonedCheckoutDelayIsNotValid
+	}
+
+	return nil
+}
+
+func (service *StoreService) validateAbandonedCheckoutSubject(subject string) error {
+	if subject == "" || len(subject) > store.AbandonedCheckoutSubjectMaxLength || !utf8.ValidString(subject) ||
+		strings.ContainsAny(subject, "\r\n") {
+		return store.ErrAbandonedCheckoutSubjectIsNotValid
+	}
+
+	return nil
+}
+
+func (service *StoreService) validateAbandonedCheckoutBodyMarkdown(bodyMarkdown string) error {
+	if len(bodyMarkdown) > store.AbandonedCheckoutBodyMaxLength {
+		return store.ErrAbandonedCheckoutBodyIsTooLong
+	}
+
+	err := service.contentService.ValidatePageBodyMarkdown(bodyMarkdown)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+// Invoices
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (service *StoreService) validateInvoiceSellerName(sellerName string) error {
+	if len(sellerName) > store.InvoiceSellerNameMaxLength || !utf8.ValidString(sellerName) ||
+		strings.ContainsAny(sellerName, "\r\n") {
+		return store.ErrInvoiceSellerNameIsNotValid
+	}
+
+	return nil
+}
+
+func (service *StoreService) validateInvoiceSellerAddress(sellerAddress string) error {
+	if len(sellerAddress) > store.InvoiceSellerAddressMaxLength || !utf8.ValidString(sellerAddress) {
+		return store.ErrInvoiceSellerAddressIsTooLong
+	}
+
+	return nil
+}
+
+func (service *StoreService) validateInvoiceSellerVatNumber(vatNumber string) error {
+	if len(vatNumber) > store.InvoiceSellerVatNumberMaxLength || !utf8.ValidString(vatNumber) ||
+		strings.ContainsAny(vatNumber, "\r\n") {
+		return store.ErrInvoiceSellerVatNumberIsNotValid
+	}
+
+	return nil
+}
+
+func (service *StoreService) validateInvoiceVatRate(vatRate int64) error {
+	if vatRate < 0 || vatRate > invoicing.VatRateBasisPoints {
+		return store.ErrInvoiceVatRateIsNotValid
+	}
+
+	return nil
+}
+
+func (service *StoreService) validateInvoiceNotes(notes string) error {
+	if len(notes) > store.InvoiceNotesMaxLength || !utf8.ValidString(notes) {
+		return store.ErrInvoiceNotesAreTooLong
+	}
+
+	return nil
+}
+
+func (service *StoreService) validateInvoiceNumberPrefix(prefix string) error {
+	if len(prefix) > store.InvoiceNumberPrefixMaxLength || !store.InvoiceNumberPrefixRegexp.MatchString(prefix) {
+		return store.ErrInvoiceNumberPrefixIsNotValid
+	}
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+// Gifts
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func validateGiftMessage(message string) error {
+	if len(message) > store.GiftMessageMaxLength || !utf8.ValidString(message) {
+		return store.ErrGiftMessageIsNotValid
+	}
+
+	return nil
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 // Refunds
 ////////////////////////////////////////////////////////////////////////////////////////////////////
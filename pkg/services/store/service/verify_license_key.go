@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/store"
+)
+
+// VerifyLicenseKey is a public endpoint used by the apps of the sellers to validate license keys.
+// Unknown keys are reported as not valid instead of returning an error.
+func (service *StoreService) VerifyLicenseKey(ctx context.Context, input store.VerifyLicenseKeyInput) (verification store.LicenseKeyVerification, err error) {
+	httpCtx := httpctx.FromCtx(ctx)
+
+	website, err := service.websitesService.FindWebsiteByDomain(ctx, service.db, httpCtx.Hostname)
+	if err != nil {
+		return
+	}
+
+	product, licenseKey, err := service.findLicenseKeyForWebsite(ctx, service.db, website.ID, input.ProductID, input.Key, false)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			err = nil
+			verification = store.LicenseKeyVerification{Valid: false}
+		}
+		return
+	}
+
+	return service.licenseKeyVerification(ctx, service.db, product, licenseKey, input.Instance)
+}
@@ -32,6 +32,11 @@ var (
 	ErrCantDeleteWebsiteWithProducts = errs.InvalidArgument("Please delete your products before deleting the website")
 	ErrRobotsTxtIsTooLong            = errs.InvalidArgument("Your robots.txt file is too long")
 	ErrRobotsTxtIsNotValid           = errs.InvalidArgument("Your robots.txt file is not valid")
+	ErrTooManyFirewallRules          = errs.InvalidArgument(fmt.Sprintf("A website can't have more than %d firewall rules", FirewallMaxRules))
+	ErrFirewallRuleNameIsNotValid    = errs.InvalidArgument(fmt.Sprintf("Firewall rule name must be between 1 and %d characters", FirewallRuleNameMaxLength))
+	ErrFirewallRuleNotFound          = errs.NotFound("Firewall rule not found")
+	ErrFirewallChallengeIsNotValid   = errs.InvalidArgument("Challenge is not valid. Please reload the page and try again.")
+	ErrRateLimitIsNotValid           = errs.InvalidArgument(fmt.Sprintf("Rate limits must be between 0 and %d", RateLimitMax))
 	ErrWebsiteIconIsNotValid         = errs.InvalidArgument("Icon is not valid. The image must be a square PNG file with a minimum resolution of 256x256 pixels.")
 	ErrAdIsNotValid                  = errs.InvalidArgument("Ad is not valid")
 	ErrAnnouncementIsNotValid        = errs.InvalidArgument("Announcement is not valid")
@@ -10,6 +10,7 @@ import (
 
 	"github.com/skerkour/stdx-go/guid"
 	"github.com/skerkour/stdx-go/set"
+	"markdown.ninja/pingoo-go/rules"
 	"markdown.ninja/pkg/services/kernel"
 )
 
@@ -36,6 +37,22 @@ const (
 
 	RobotsTxtMaxLength = 1500
 
+	// Default per-IP address limits of the site API. See WebsiteRateLimits
+	DefaultRateLimitApi        = 300
+	DefaultRateLimitLogin      = 30
+	DefaultRateLimitSubscribe  = 30
+	DefaultRateLimitPlaceOrder = 30
+	RateLimitMax               = 100_000
+
+	FirewallMaxRules              = 50
+	FirewallRuleNameMaxLength     = 100
+	FirewallChallengePath         = MarkdownNinjaPathPrefix + "/api/firewall/challenge"
+	FirewallClearanceCookie       = "__mdninja_firewall_clearance"
+	FirewallClearanceCookieMaxAge = 24 * time.Hour
+	FirewallChallengeTimeout      = 10 * time.Minute
+	// Number of leading zero bits required for the proof of work of challenges
+	FirewallChallengeDifficulty = 16
+
 	TemplateBase     = "base.html"
 	TemplatePosts    = "posts.html"
 	TemplatePage     = "page.html"
@@ -47,6 +64,7 @@ const (
 
 	MarkdownNinjaPathPrefix = "/__markdown_ninja"
 	PreviewPrefix           = MarkdownNinjaPathPrefix + "/preview/"
+	ProductsPrefix          = MarkdownNinjaPathPrefix + "/products/"
 
 	DefaultWebsiteLanguage = "en"
 
@@ -94,6 +112,33 @@ var AllCurrencies = set.NewFromSlice([]Currency{
 	CurrencyEUR,
 })
 
+// CurrenciesByCountry are the currencies preferred by the visitors from a given country.
+// Visitors from other countries are shown prices in the currency of the website.
+var CurrenciesByCountry = map[string]Currency{
+	"US": CurrencyUSD,
+	// Euro area
+	"AT": CurrencyEUR,
+	"BE": CurrencyEUR,
+	"CY": CurrencyEUR,
+	"DE": CurrencyEUR,
+	"EE": CurrencyEUR,
+	"ES": CurrencyEUR,
+	"FI": CurrencyEUR,
+	"FR": CurrencyEUR,
+	"GR": CurrencyEUR,
+	"HR": CurrencyEUR,
+	"IE": CurrencyEUR,
+	"IT": CurrencyEUR,
+	"LT": CurrencyEUR,
+	"LU": CurrencyEUR,
+	"LV": CurrencyEUR,
+	"MT": CurrencyEUR,
+	"NL": CurrencyEUR,
+	"PT": CurrencyEUR,
+	"SI": CurrencyEUR,
+	"SK": CurrencyEUR,
+}
+
 var DefaultColors = ThemeColors{
 	Background: "#ffffff",
 	Text:       "#000000",
@@ -133,13 +178,14 @@ type Website struct {
 	Currency      Currency          `db:"currency" json:"currency"`
 	CustomIcon    bool              `db:"custom_icon" json:"custom_icon"`
 	// The BLAKE3 hash of the originally uploaded image
-	CustomIconHash kernel.BytesHex `db:"custom_icon_hash" json:"custom_icon_hash"`
-	Colors         ThemeColors     `db:"colors" json:"colors"`
-	Theme          string          `db:"theme" json:"theme"`
-	Announcement   *string         `db:"announcement" json:"announcement"`
-	Ad             *string         `db:"ad" json:"ad"`
-	Logo           *string         `db:"logo" json:"logo"`
-	PoweredBy      bool            `db:"powered_by" json:"powered_by"`
+	CustomIconHash kernel.BytesHex   `db:"custom_icon_hash" json:"custom_icon_hash"`
+	Colors         ThemeColors       `db:"colors" json:"colors"`
+	Theme          string            `db:"theme" json:"theme"`
+	Announcement   *string           `db:"announcement" json:"announcement"`
+	Ad             *string           `db:"ad" json:"ad"`
+	Logo           *string           `db:"logo" json:"logo"`
+	PoweredBy      bool              `db:"powered_by" json:"powered_by"`
+	RateLimits     WebsiteRateLimits `db:"rate_limits" json:"rate_limits"`
 
 	OrganizationID guid.GUID `db:"organization_id" json:"organization_id"`
 
@@ -207,6 +253,51 @@ func (colors *ThemeColors) Value() (driver.Value, error) {
 	return json.Marshal(colors)
 }
 
+// WebsiteRateLimits are the per-IP address limits of the site API.
+// A value of 0 means that the default limit is used.
+type WebsiteRateLimits struct {
+	// Requests per minute, for all the endpoints of the site API
+	Api int64 `json:"api"`
+	// Requests per hour
+	Login      int64 `json:"login"`
+	Subscribe  int64 `json:"subscribe"`
+	PlaceOrder int64 `json:"place_order"`
+}
+
+// WithDefaults returns the limits with the zero values replaced by the default limits
+func (limits WebsiteRateLimits) WithDefaults() WebsiteRateLimits {
+	if limits.Api == 0 {
+		limits.Api = DefaultRateLimitApi
+	}
+	if limits.Login == 0 {
+		limits.Login = DefaultRateLimitLogin
+	}
+	if limits.Subscribe == 0 {
+		limits.Subscribe = DefaultRateLimitSubscribe
+	}
+	if limits.PlaceOrder == 0 {
+		limits.PlaceOrder = DefaultRateLimitPlaceOrder
+	}
+	return limits
+}
+
+func (limits *WebsiteRateLimits) Scan(val any) error {
+	switch v := val.(type) {
+	case []byte:
+		json.Unmarshal(v, limits)
+		return nil
+	case string:
+		json.Unmarshal([]byte(v), limits)
+		return nil
+	default:
+		return fmt.Errorf("WebsiteRateLimits.Scan: Unsupported type: %T", v)
+	}
+}
+
+func (limits *WebsiteRateLimits) Value() (driver.Value, error) {
+	return json.Marshal(limits)
+}
+
 // supported pattern -> To
 // /old -> /new
 // /:year/:month/:post -> /:month/:year/:post
@@ -236,6 +327,69 @@ type Redirect struct {
 	WebsiteID guid.GUID `db:"website_id" json:"-"`
 }
 
+// FirewallRule is a declarative rule evaluated for each request to the website.
+// Rules are evaluated by ascending Position.
+type FirewallRule struct {
+	ID        guid.GUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	Position   int64                  `db:"position" json:"-"`
+	Name       string                 `db:"name" json:"name"`
+	Enabled    bool                   `db:"enabled" json:"enabled"`
+	Conditions FirewallRuleConditions `db:"conditions" json:"conditions"`
+	Action     FirewallRuleAction     `db:"action" json:"action"`
+	// Number of times the action of the rule has been applied
+	Hits      int64      `db:"hits" json:"hits"`
+	LastHitAt *time.Time `db:"last_hit_at" json:"last_hit_at"`
+
+	WebsiteID guid.GUID `db:"website_id" json:"-"`
+}
+
+type FirewallRuleConditions rules.Conditions
+
+func (conditions *FirewallRuleConditions) Scan(val any) error {
+	switch v := val.(type) {
+	case []byte:
+		return json.Unmarshal(v, conditions)
+	case string:
+		return json.Unmarshal([]byte(v), conditions)
+	default:
+		return fmt.Errorf("FirewallRuleConditions.Scan: Unsupported type: %T", v)
+	}
+}
+
+func (conditions *FirewallRuleConditions) Value() (driver.Value, error) {
+	return json.Marshal(conditions)
+}
+
+type FirewallRuleAction rules.ActionDefinition
+
+func (action *FirewallRuleAction) Scan(val any) error {
+	switch v := val.(type) {
+	case []byte:
+		return json.Unmarshal(v, action)
+	case string:
+		return json.Unmarshal([]byte(v), action)
+	default:
+		return fmt.Errorf("FirewallRuleAction.Scan: Unsupported type: %T", v)
+	}
+}
+
+func (action *FirewallRuleAction) Value() (driver.Value, error) {
+	return json.Marshal(action)
+}
+
+// Firewall contains the compiled enabled rules of a website, in order of evaluation
+type Firewall struct {
+	Rules []CompiledFirewallRule
+}
+
+type CompiledFirewallRule struct {
+	ID guid.GUID
+	rules.CompiledRule
+}
+
 type Domain struct {
 	ID        guid.GUID `db:"id" json:"id"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
@@ -285,6 +439,7 @@ type UpdateWebsiteInput struct {
 	Announcement    *string            `json:"announcement"`
 	Logo            *string            `json:"logo"`
 	PoweredBy       *bool              `json:"powered_by"`
+	RateLimits      *WebsiteRateLimits `json:"rate_limits"`
 }
 
 type DeleteWebsiteInput struct {
@@ -315,6 +470,29 @@ type RedirectInput struct {
 	// Status  int
 }
 
+type SaveFirewallRulesInput struct {
+	WebsiteID guid.GUID           `json:"website_id"`
+	Rules     []FirewallRuleInput `json:"rules"`
+}
+
+type FirewallRuleInput struct {
+	// ID of an existing rule to update. Counters of existing rules are preserved.
+	ID         *guid.GUID             `json:"id"`
+	Name       string                 `json:"name"`
+	Enabled    bool                   `json:"enabled"`
+	Conditions rules.Conditions       `json:"conditions"`
+	Action     rules.ActionDefinition `json:"action"`
+}
+
+type ListFirewallRulesInput struct {
+	WebsiteID guid.GUID `json:"website_id"`
+}
+
+type CompleteFirewallChallengeInput struct {
+	Challenge string `json:"challenge"`
+	Nonce     string `json:"nonce"`
+}
+
 // type ParsedTheme struct {
 // 	Name      string
 // 	Templates map[string]*template.Template
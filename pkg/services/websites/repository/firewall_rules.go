@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pkg/services/websites"
+)
+
+func (repo *WebsitesRepository) CreateFirewallRule(ctx context.Context, db db.Queryer, rule websites.FirewallRule) (err error) {
+	const query = `INSERT INTO firewall_rules
+			(id, created_at, updated_at, position, name, enabled, conditions, action, hits, last_hit_at, website_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err = db.Exec(ctx, query, rule.ID, rule.CreatedAt, rule.UpdatedAt,
+		rule.Position, rule.Name, rule.Enabled, rule.Conditions, rule.Action, rule.Hits, rule.LastHitAt,
+		rule.WebsiteID)
+	if err != nil {
+		err = fmt.Errorf("websites.CreateFirewallRule: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *WebsitesRepository) UpdateFirewallRule(ctx context.Context, db db.Queryer, rule websites.FirewallRule) (err error) {
+	const query = `UPDATE firewall_rules
+		SET updated_at = $1, position = $2, name = $3, enabled = $4, conditions = $5, action = $6
+		WHERE id = $7`
+
+	_, err = db.Exec(ctx, query, rule.UpdatedAt, rule.Position, rule.Name, rule.Enabled, rule.Conditions, rule.Action,
+		rule.ID)
+	if err != nil {
+		err = fmt.Errorf("websites.UpdateFirewallRule: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *WebsitesRepository) FindFirewallRulesForWebsite(ctx context.Context, db db.Queryer, websiteID guid.GUID) (rules []websites.FirewallRule, err error) {
+	rules = []websites.FirewallRule{}
+	const query = `SELECT * FROM firewall_rules
+		WHERE website_id = $1
+		ORDER BY position`
+
+	err = db.Select(ctx, &rules, query, websiteID)
+	if err != nil {
+		err = fmt.Errorf("websites.FindFirewallRulesForWebsite: %w", err)
+		return
+	}
+
+	return
+}
+
+func (repo *WebsitesRepository) DeleteFirewallRule(ctx context.Context, db db.Queryer, ruleID guid.GUID) (err error) {
+	const query = `DELETE FROM firewall_rules WHERE id = $1`
+
+	_, err = db.Exec(ctx, query, ruleID)
+	if err != nil {
+		err = fmt.Errorf("websites.DeleteFirewallRule: %w", err)
+		return
+	}
+
+	return
+}
+
+// IncrementFirewallRuleHits adds hits to the counter of a rule. It's a no-op if the rule has been deleted.
+func (repo *WebsitesRepository) IncrementFirewallRuleHits(ctx context.Context, db db.Queryer, ruleID guid.GUID, hits int64, lastHitAt time.Time) (err error) {
+	const query = `UPDATE firewall_rules
+		SET hits = hits + $1, last_hit_at = GREATEST(last_hit_at, $2)
+		WHERE id = $3`
+
+	_, err = db.Exec(ctx, query, hits, lastHitAt, ruleID)
+	if err != nil {
+		err = fmt.Errorf("websites.IncrementFirewallRuleHits: %w", err)
+		return
+	}
+
+	return
+}
@@ -16,17 +16,17 @@ func (repo *WebsitesRepository) CreateWebsite(ctx context.Context, db db.Queryer
 			(id, created_at, updated_at, modified_at, blocked_at, blocked_reason,
 				name, slug, header, footer, navigation, language, primary_domain,
 				description, robots_txt, currency, custom_icon, custom_icon_hash, colors,
-				theme, announcement, ad, logo, powered_by,
+				theme, announcement, ad, logo, powered_by, rate_limits,
 				organization_id)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19,
-			$20, $21, $22, $23, $24, $25)`
+			$20, $21, $22, $23, $24, $25, $26)`
 
 	_, err = db.Exec(ctx, query, website.ID, website.CreatedAt, website.UpdatedAt, website.ModifiedAt,
 		website.BlockedAt, website.BlockedReason, website.Name, website.Slug, website.Header, website.Footer,
 		website.Navigation, website.Language, website.PrimaryDomain,
 		website.Description, website.RobotsTxt, website.Currency, website.CustomIcon, website.CustomIconHash,
 		website.Colors, website.Theme, website.Announcement, website.Ad, website.Logo, website.PoweredBy,
-		website.OrganizationID)
+		website.RateLimits, website.OrganizationID)
 	if err != nil {
 		err = fmt.Errorf("websites.CreateWebsite: %w", err)
 		return
@@ -41,14 +41,14 @@ func (repo *WebsitesRepository) UpdateWebsite(ctx context.Context, db db.Queryer
 			slug = $6, header = $7, footer = $8, navigation = $9, language = $10,
 			primary_domain = $11, description = $12, robots_txt = $13, currency = $14,
 			custom_icon = $15, custom_icon_hash = $16, colors = $17, theme = $18,
-			announcement = $19, ad = $20, logo = $21, powered_by = $22
-		WHERE id = $23`
+			announcement = $19, ad = $20, logo = $21, powered_by = $22, rate_limits = $23
+		WHERE id = $24`
 
 	_, err = db.Exec(ctx, query, website.UpdatedAt, website.ModifiedAt, website.BlockedAt, website.BlockedReason, website.Name,
 		website.Slug, website.Header, website.Footer, website.Navigation, website.Language,
 		website.PrimaryDomain, website.Description, website.RobotsTxt, website.Currency,
 		website.CustomIcon, website.CustomIconHash, website.Colors, website.Theme, website.Announcement,
-		website.Ad, website.Logo, website.PoweredBy,
+		website.Ad, website.Logo, website.PoweredBy, website.RateLimits,
 		website.ID)
 	if err != nil {
 		err = fmt.Errorf("websites.UpdateWebsite: %w", err)
@@ -37,6 +37,15 @@ type Service interface {
 	FindRedirects(ctx context.Context, db db.Queryer, websiteID guid.GUID) (redirects []Redirect, err error)
 	MatchRedirect(ctx context.Context, domain, path string, redirects []Redirect) *Redirect
 
+	// Firewall
+	SaveFirewallRules(ctx context.Context, input SaveFirewallRulesInput) (rules []FirewallRule, err error)
+	ListFirewallRules(ctx context.Context, input ListFirewallRulesInput) (ret kernel.PaginatedResult[FirewallRule], err error)
+	// FindFirewall returns the compiled firewall of the website. Firewalls are cached until the website is modified.
+	FindFirewall(ctx context.Context, website Website) (firewall *Firewall, err error)
+	// RecordFirewallRuleHit increments the counter of a rule. Counters are buffered in memory and
+	// periodically saved to the database.
+	RecordFirewallRuleHit(ruleID guid.GUID)
+
 	// Domains
 	AddDomain(ctx context.Context, input AddDomainInput) (domain Domain, err error)
 	RemoveDomain(ctx context.Context, input RemoveDomainInput) (err error)
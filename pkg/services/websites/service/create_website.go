@@ -93,6 +93,7 @@ func (service *WebsitesService) CreateWebsite(ctx context.Context, input website
 			Announcement:   nil,
 			Ad:             nil,
 			PoweredBy:      true,
+			RateLimits:     websites.WebsiteRateLimits{},
 
 			OrganizationID: input.OrganizationID,
 		}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/skerkour/stdx-go/log/slogx"
+	"markdown.ninja/pingoo-go/rules"
+	"markdown.ninja/pkg/services/websites"
+)
+
+func (service *WebsitesService) FindFirewall(ctx context.Context, website websites.Website) (firewall *websites.Firewall, err error) {
+	// website.ModifiedAt is updated each time the rules are saved, so stale entries are never used
+	cacheKey := website.ID.String() + "-" + strconv.FormatInt(website.ModifiedAt.UnixNano(), 10)
+	if cachedFirewall := service.firewallsCache.Get(cacheKey); cachedFirewall != nil {
+		return cachedFirewall.Value(), nil
+	}
+
+	firewallRules, err := service.repo.FindFirewallRulesForWebsite(ctx, service.db, website.ID)
+	if err != nil {
+		return
+	}
+
+	firewall = &websites.Firewall{
+		Rules: make([]websites.CompiledFirewallRule, 0, len(firewallRules)),
+	}
+	for _, rule := range firewallRules {
+		if !rule.Enabled {
+			continue
+		}
+
+		compiledRule, compileErr := rules.Compile(rules.Definition{
+			Conditions: rules.Conditions(rule.Conditions),
+			Action:     rules.ActionDefinition(rule.Action),
+		})
+		if compileErr != nil {
+			// rules are validated when saved, so it should never happen
+			slogx.FromCtx(ctx).Error("websites.FindFirewall: error compiling firewall rule", slogx.Err(compileErr),
+				slog.String("rule.id", rule.ID.String()))
+			continue
+		}
+
+		firewall.Rules = append(firewall.Rules, websites.CompiledFirewallRule{
+			ID:           rule.ID,
+			CompiledRule: compiledRule,
+		})
+	}
+
+	service.firewallsCache.Set(cacheKey, firewall, time.Hour)
+
+	return firewall, nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/skerkour/stdx-go/guid"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"markdown.ninja/pkg/server/httpctx"
+	"markdown.ninja/pkg/services/kernel"
+	"markdown.ninja/pkg/services/websites"
+)
+
+const firewallHitsFlushInterval = 30 * time.Second
+
+type firewallHitsBuffer struct {
+	mutex sync.Mutex
+	hits  map[guid.GUID]firewallRuleHits
+}
+
+type firewallRuleHits struct {
+	count     int64
+	lastHitAt time.Time
+}
+
+func newFirewallHitsBuffer() *firewallHitsBuffer {
+	return &firewallHitsBuffer{
+		mutex: sync.Mutex{},
+		hits:  make(map[guid.GUID]firewallRuleHits),
+	}
+}
+
+func (service *WebsitesService) RecordFirewallRuleHit(ruleID guid.GUID) {
+	service.firewallHits.mutex.Lock()
+	ruleHits := service.firewallHits.hits[ruleID]
+	ruleHits.count += 1
+	ruleHits.lastHitAt = time.Now().UTC()
+	service.firewallHits.hits[ruleID] = ruleHits
+	service.firewallHits.mutex.Unlock()
+}
+
+func (service *WebsitesService) flushFirewallHitsInBackground(ctx context.Context, logger *slog.Logger) {
+	for {
+		select {
+		case <-ctx.Done():
+			// use a new context to save the last hits during shutdown
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			service.flushFirewallHits(flushCtx, logger)
+			cancel()
+			return
+		case <-time.After(firewallHitsFlushInterval):
+		}
+
+		service.flushFirewallHits(ctx, logger)
+	}
+}
+
+func (service *WebsitesService) flushFirewallHits(ctx context.Context, logger *slog.Logger) {
+	service.firewallHits.mutex.Lock()
+	hits := service.firewallHits.hits
+	service.firewallHits.hits = make(map[guid.GUID]firewallRuleHits, len(hits))
+	service.firewallHits.mutex.Unlock()
+
+	for ruleID, ruleHits := range hits {
+		err := service.repo.IncrementFirewallRuleHits(ctx, service.db, ruleID, ruleHits.count, ruleHits.lastHitAt)
+		if err != nil {
+			logger.Error("websites: error saving firewall rule hits", slogx.Err(err), slog.String("rule.id", ruleID.String()))
+		}
+	}
+}
+
+// findWebsiteForFirewallAction finds the website and checks that the current user is a staff
+// of its organization, or that the current API key belongs to its organization.
+func (service *WebsitesService) findWebsiteForFirewallAction(ctx context.Context, websiteID guid.GUID) (website websites.Website, err error) {
+	actorID, err := service.kernel.CurrentUserID(ctx)
+	if err == nil {
+		website, err = service.repo.FindWebsiteByID(ctx, service.db, websiteID, false)
+		if err != nil {
+			return
+		}
+
+		_, err = service.organizationsService.CheckUserIsStaff(ctx, service.db, actorID, website.OrganizationID)
+		if err != nil {
+			return
+		}
+	} else {
+		httpCtx := httpctx.FromCtx(ctx)
+		if httpCtx.ApiKey == nil {
+			err = kernel.ErrPermissionDenied
+			return
+		}
+
+		website, err = service.repo.FindWebsiteByID(ctx, service.db, websiteID, false)
+		if err != nil {
+			return
+		}
+
+		_, err = service.organizationsService.CheckCurrentApiKey(ctx, website.OrganizationID)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+
+	"markdown.ninja/pkg/services/kernel"
+	"markdown.ninja/pkg/services/websites"
+)
+
+func (service *WebsitesService) ListFirewallRules(ctx context.Context, input websites.ListFirewallRulesInput) (ret kernel.PaginatedResult[websites.FirewallRule], err error) {
+	website, err := service.findWebsiteForFirewallAction(ctx, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	firewallRules, err := service.repo.FindFirewallRulesForWebsite(ctx, service.db, website.ID)
+	if err != nil {
+		return
+	}
+
+	ret = kernel.PaginatedResult[websites.FirewallRule]{
+		Data: firewallRules,
+	}
+	return
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/guid"
+	"markdown.ninja/pingoo-go/rules"
+	"markdown.ninja/pkg/errs"
+	"markdown.ninja/pkg/services/websites"
+)
+
+// SaveFirewallRules replaces the firewall rules of a website. Rules are evaluated in the order of input.Rules.
+func (service *WebsitesService) SaveFirewallRules(ctx context.Context, input websites.SaveFirewallRulesInput) (firewallRules []websites.FirewallRule, err error) {
+	website, err := service.findWebsiteForFirewallAction(ctx, input.WebsiteID)
+	if err != nil {
+		return
+	}
+
+	if len(input.Rules) > websites.FirewallMaxRules {
+		err = websites.ErrTooManyFirewallRules
+		return
+	}
+
+	now := time.Now().UTC()
+	newRules := make([]websites.FirewallRule, 0, len(input.Rules))
+
+	for i, ruleInput := range input.Rules {
+		name := strings.TrimSpace(ruleInput.Name)
+		if name == "" || utf8.RuneCountInString(name) > websites.FirewallRuleNameMaxLength || !utf8.ValidString(name) {
+			err = websites.ErrFirewallRuleNameIsNotValid
+			return
+		}
+
+		definition := rules.Definition{
+			Conditions: ruleInput.Conditions,
+			Action:     ruleInput.Action,
+		}
+		err = definition.Clean()
+		if err == nil {
+			_, err = rules.Compile(definition)
+		}
+		if err != nil {
+			err = errs.InvalidArgument(fmt.Sprintf("Firewall rule #%d (%s): %s", i+1, name, err.Error()))
+			return
+		}
+
+		rule := websites.FirewallRule{
+			ID:         guid.Empty,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+			Position:   int64(i),
+			Name:       name,
+			Enabled:    ruleInput.Enabled,
+			Conditions: websites.FirewallRuleConditions(definition.Conditions),
+			Action:     websites.FirewallRuleAction(definition.Action),
+			Hits:       0,
+			LastHitAt:  nil,
+			WebsiteID:  website.ID,
+		}
+		if ruleInput.ID != nil {
+			rule.ID = *ruleInput.ID
+		}
+		newRules = append(newRules, rule)
+	}
+
+	err = service.db.Transaction(ctx, func(tx db.Tx) (txErr error) {
+		existingRules, txErr := service.repo.FindFirewallRulesForWebsite(ctx, tx, website.ID)
+		if txErr != nil {
+			return txErr
+		}
+
+		rulesToDelete := make(map[guid.GUID]bool, len(existingRules))
+		for _, existingRule := range existingRules {
+			rulesToDelete[existingRule.ID] = true
+		}
+
+		for _, rule := range newRules {
+			if _, isExistingRule := rulesToDelete[rule.ID]; isExistingRule {
+				delete(rulesToDelete, rule.ID)
+				txErr = service.repo.UpdateFirewallRule(ctx, tx, rule)
+			} else {
+				rule.ID = guid.NewTimeBased()
+				txErr = service.repo.CreateFirewallRule(ctx, tx, rule)
+			}
+			if txErr != nil {
+				return txErr
+			}
+		}
+
+		for ruleID := range rulesToDelete {
+			txErr = service.repo.DeleteFirewallRule(ctx, tx, ruleID)
+			if txErr != nil {
+				return txErr
+			}
+		}
+
+		// invalidate the cached firewalls
+		txErr = service.repo.UpdateWebsiteModifiedAt(ctx, tx, website.ID, now)
+		if txErr != nil {
+			return txErr
+		}
+
+		firewallRules, txErr = service.repo.FindFirewallRulesForWebsite(ctx, tx, website.ID)
+		return txErr
+	})
+	if err != nil {
+		return
+	}
+
+	return
+}
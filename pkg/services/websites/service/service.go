@@ -1,7 +1,12 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	"github.com/skerkour/stdx-go/db"
+	"github.com/skerkour/stdx-go/log/slogx"
+	"github.com/skerkour/stdx-go/memorycache"
 	"github.com/skerkour/stdx-go/queue"
 	"markdown.ninja/cmd/mdninja-server/config"
 	"markdown.ninja/pkg/mailer"
@@ -12,6 +17,7 @@ import (
 	"markdown.ninja/pkg/services/kernel"
 	"markdown.ninja/pkg/services/organizations"
 	"markdown.ninja/pkg/services/store"
+	"markdown.ninja/pkg/services/websites"
 	"markdown.ninja/pkg/services/websites/repository"
 	"markdown.ninja/pkg/storage"
 )
@@ -33,9 +39,13 @@ type WebsitesService struct {
 	organizationsService organizations.Service
 
 	websitesRootDomain string
+
+	// compiled firewalls, by website ID and modified_at
+	firewallsCache *memorycache.Cache[string, *websites.Firewall]
+	firewallHits   *firewallHitsBuffer
 }
 
-func NewWebsitesService(conf config.Config, db db.DB, queue queue.Queue, mailer mailer.Mailer,
+func NewWebsitesService(ctx context.Context, conf config.Config, db db.DB, queue queue.Queue, mailer mailer.Mailer,
 	storage storage.Storage,
 	kernel kernel.PrivateService, emailsService emails.Service, contentService content.Service,
 	eventsService events.Service, organizationsService organizations.Service) (service *WebsitesService, err error) {
@@ -58,7 +68,16 @@ func NewWebsitesService(conf config.Config, db db.DB, queue queue.Queue, mailer
 		organizationsService: organizationsService,
 
 		websitesRootDomain: conf.HTTP.WebsitesRootDomain,
+
+		firewallsCache: memorycache.New(
+			memorycache.WithTTL[string, *websites.Firewall](time.Hour),
+			memorycache.WithCapacity[string, *websites.Firewall](1000),
+		),
+		firewallHits: newFirewallHitsBuffer(),
 	}
+
+	go service.flushFirewallHitsInBackground(ctx, slogx.FromCtx(ctx))
+
 	return
 }
 
@@ -252,6 +252,14 @@ func (service *WebsitesService) UpdateWebsite(ctx context.Context, input website
 		website.PoweredBy = *input.PoweredBy
 	}
 
+	if input.RateLimits != nil {
+		err = validateRateLimits(*input.RateLimits)
+		if err != nil {
+			return
+		}
+		website.RateLimits = *input.RateLimits
+	}
+
 	err = service.organizationsService.CheckBillingGatedAction(ctx, service.db, website.OrganizationID, organizations.BillingGatedActionUpdateWebsite{
 		PoweredBy: website.PoweredBy,
 		Ad:        website.Ad,
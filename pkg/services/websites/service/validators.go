@@ -267,3 +267,13 @@ func validateWebsiteNavigationItem(item *websites.WebsiteNavigationItem, depth u
 
 	return nil
 }
+
+func validateRateLimits(limits websites.WebsiteRateLimits) error {
+	for _, limit := range []int64{limits.Api, limits.Login, limits.Subscribe, limits.PlaceOrder} {
+		if limit < 0 || limit > websites.RateLimitMax {
+			return websites.ErrRateLimitIsNotValid
+		}
+	}
+
+	return nil
+}
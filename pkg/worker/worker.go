@@ -36,6 +36,7 @@ func Start(ctx context.Context, logger *slog.Logger, concurrencyMax uint32, queu
 	}
 
 	// kernel
+	workerpool.AddHandler(workerPool, kernelService.JobDeleteExpiredAuthData)
 
 	// organizations
 	workerpool.AddHandler(workerPool, organizationsService.JobSendStaffInvitations)
@@ -47,13 +48,20 @@ func Start(ctx context.Context, logger *slog.Logger, concurrencyMax uint32, queu
 	// emails
 	workerpool.AddHandler(workerPool, emailsService.JobDeleteWebsiteConfigurationData)
 	workerpool.AddHandler(workerPool, emailsService.JobSendNewsletter)
+	workerpool.AddHandler(workerPool, emailsService.JobSendSequenceEmail)
+	workerpool.AddHandler(workerPool, emailsService.JobSendNewsletterDigest)
 	workerpool.AddHandler(workerPool, emailsService.JobSendPostAsNewsletter)
 	workerpool.AddHandler(workerPool, emailsService.JobSendEmail)
 
 	// store
 	workerpool.AddHandler(workerPool, storeService.JobSendOrderConfirmationEmail)
+	workerpool.AddHandler(workerPool, storeService.JobSendGiftNotificationEmail)
 	workerpool.AddHandler(workerPool, storeService.JobCreateStripeRefund)
 	workerpool.AddHandler(workerPool, storeService.JobSyncRefundWithStripe)
+	workerpool.AddHandler(workerPool, storeService.JobGenerateProductEbooks)
+	workerpool.AddHandler(workerPool, storeService.JobSendAbandonedCheckoutEmail)
+	workerpool.AddHandler(workerPool, storeService.JobGenerateInvoice)
+	workerpool.AddHandler(workerPool, storeService.JobGenerateCreditNote)
 
 	// content
 	workerpool.AddHandler(workerPool, contentService.JobDeleteAssetData)
@@ -10,14 +10,19 @@ export const Routes = {
   logout: '/logout',
   subscribe: '/subscribe',
   unsubscribe: '/unsubscribe',
+  newsletterPreferences: '/newsletter_preferences',
+  updateNewsletterPreferences: '/update_newsletter_preferences',
   completeSubscription: '/complete_subscription',
   updateMyAccount: '/update_my_account',
   deleteMyAccount: '/delete_my_account',
   verifyEmail: '/verify_email',
   placeOrder: '/place_order',
+  previewOrder: '/preview_order',
   completeOrder: '/complete_order',
   cancelorder: '/cancel_order',
+  resumeOrder: '/resume_order',
   myOrders: '/my_orders',
   myProducts: '/my_products',
   product: '/product',
+  updateLessonProgress: '/update_lesson_progress',
 }
@@ -228,6 +228,14 @@ export async function unsubscribe(input: model.UnsubscribeInput) {
   await post(Routes.unsubscribe, input);
 }
 
+export async function fetchNewsletterPreferences(input: model.GetNewsletterPreferencesInput): Promise<model.NewsletterPreferences> {
+  return await get(Routes.newsletterPreferences, input);
+}
+
+export async function updateNewsletterPreferences(input: model.UpdateNewsletterPreferencesInput): Promise<model.NewsletterPreferences> {
+  return await post(Routes.updateNewsletterPreferences, input);
+}
+
 export async function logout() {
   const $store = useStore();
   await post(Routes.logout, {});
@@ -280,6 +288,11 @@ export async function placeOrder(input: model.PlaceOrderInput): Promise<model.Pl
   return res;
 }
 
+export async function previewOrder(input: model.PreviewOrderInput): Promise<model.PreviewOrderOutput> {
+  const res: model.PreviewOrderOutput = await post(Routes.previewOrder, input);
+  return res;
+}
+
 export async function completeOrder(input: model.CompleteOrderInput) {
   await post(Routes.completeOrder, input);
 }
@@ -288,6 +301,11 @@ export async function cancelOrder(input: model.CancelOrderInput) {
   await post(Routes.cancelorder, input);
 }
 
+export async function resumeOrder(input: model.ResumeOrderInput): Promise<model.ResumeOrderOutput> {
+  const res: model.ResumeOrderOutput = await post(Routes.resumeOrder, input);
+  return res;
+}
+
 export async function listMyOrders(): Promise<model.PaginatedResult<model.Order>> {
   const orders: model.PaginatedResult<model.Order> = await get(Routes.myOrders);
   return orders;
@@ -301,6 +319,10 @@ export async function getProduct(input: model.GetProductInput): Promise<model.Pr
   return await get(Routes.product, input);
 }
 
+export async function updateLessonProgress(input: model.UpdateLessonProgressInput) {
+  await post(Routes.updateLessonProgress, input);
+}
+
 export async function deleteMyAccount() {
   const $store = useStore();
   await post(Routes.deleteMyAccount, {});
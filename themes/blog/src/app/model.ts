@@ -15,6 +15,8 @@ export enum ProductType {
   Book = "book",
   Course = "course",
   Download = "download",
+  Membership = "membership",
+  Bundle = "bundle",
 };
 
 export enum BlockType {
@@ -42,10 +44,33 @@ export type Address = {
 }
 
 
-export type Contact = {
+export type NewsletterDelivery = 'posts' | 'digest' | 'none';
+
+export type NewsletterPreferences = {
+  subscribed_to_newsletter: boolean;
+  receive_newsletters: boolean;
+  subscribed_to_product_updates: boolean;
+  newsletter_delivery: NewsletterDelivery;
+  // the names of the tags of the posts to receive. Empty to receive all the posts
+  newsletter_tags: string[];
+  newsletter_digests: boolean;
+}
+
+export type Contact = NewsletterPreferences & {
   name: string;
   email: string;
-  subscribed_to_newsletter: boolean;
+}
+
+export type GetNewsletterPreferencesInput = {
+  token: string;
+}
+
+export type UpdateNewsletterPreferencesInput = {
+  token: string;
+  receive_newsletters?: boolean;
+  subscribed_to_product_updates?: boolean;
+  newsletter_delivery?: NewsletterDelivery;
+  newsletter_tags?: string[];
 }
 
 export type Website = {
@@ -85,10 +110,12 @@ export type PageMetadata = {
   description: string;
   body_hash: string;
   metadata_hash: string;
+  visibility: string;
 };
 
 export type Page = PageMetadata & {
   body: string;
+  locked: boolean;
   tags: Tag[];
 }
 
@@ -123,6 +150,22 @@ export type Order = {
   currency: string;
   status: OrderStatus;
   invoice_url?: string;
+  gift_recipient_email: string | null;
+  license_keys: LicenseKey[];
+  invoices: OrderInvoice[];
+}
+
+export type OrderInvoice = {
+  type: string;
+  number: string;
+  url: string;
+}
+
+export type LicenseKey = {
+  key: string;
+  status: string;
+  product_id: string;
+  product_name: string;
 }
 
 export type Product = {
@@ -133,6 +176,34 @@ export type Product = {
   description: string;
 
   content: ProductPage[] | null;
+  ebooks: ProductEbook[];
+  bundle_items: ProductBundleItem[] | null;
+  price: ProductPrice | null;
+  // only for courses
+  progress: CourseProgress | null;
+}
+
+export type CourseProgress = {
+  completed_lessons: number;
+  total_lessons: number;
+}
+
+export type ProductPrice = {
+  amount: number;
+  currency: string;
+  pay_what_you_want: boolean;
+}
+
+export type ProductBundleItem = {
+  id: string;
+  name: string;
+  type: ProductType;
+}
+
+export type ProductEbook = {
+  format: 'epub' | 'html';
+  url: string;
+  size: number;
 }
 
 export type ProductPage = {
@@ -140,7 +211,11 @@ export type ProductPage = {
 
   position: number;
   title: string;
+  // empty if the page is locked
   body: string;
+  completed: boolean;
+  locked: boolean;
+  available_at: string | null;
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -188,6 +263,10 @@ export type CompleteSubscriptionInput = {
 export type UpdateMyAccountInput = {
   name?: string;
   subscribed_to_newsletter?: boolean;
+  newsletter_delivery?: NewsletterDelivery;
+  receive_newsletters?: boolean;
+  subscribed_to_product_updates?: boolean;
+  newsletter_tags?: string[];
   email?: string;
 }
 
@@ -204,6 +283,36 @@ export type PlaceOrderInput = {
   email?: string;
   subscribe_to_newsletter: boolean;
   additional_invoice_information?: string;
+  coupon?: string;
+  // amounts chosen for pay-what-you-want products, by product ID
+  custom_prices?: Record<string, number>;
+  // email of the recipient when the order is a gift
+  gift_recipient_email?: string;
+  gift_message?: string;
+}
+
+export type PreviewOrderInput = {
+  products: string[];
+  coupon?: string;
+  custom_prices?: Record<string, number>;
+}
+
+export type PreviewOrderOutput = {
+  currency: string;
+  subtotal_amount: number;
+  discount_amount: number;
+  total_amount: number;
+  coupon: string | null;
+  line_items: PreviewLineItem[];
+}
+
+export type PreviewLineItem = {
+  product_id: string;
+  name: string;
+  price: number;
+  discount_amount: number;
+  pay_what_you_want: boolean;
+  minimum_price: number;
 }
 
 export type CompleteOrderInput = {
@@ -214,6 +323,20 @@ export type CancelOrderInput = {
   order_id: string;
 }
 
+export type ResumeOrderInput = {
+  order_id: string;
+  token?: string;
+}
+
+export type ResumeOrderOutput = {
+  checkout_url: string;
+}
+
 export type GetProductInput = {
   id: string;
 }
+
+export type UpdateLessonProgressInput = {
+  product_page_id: string;
+  completed: boolean;
+}
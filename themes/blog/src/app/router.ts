@@ -10,6 +10,7 @@ const Unsubscribe = () =>  import('@/ui/pages/unsubscribe.vue');
 const Checkout = () =>  import('@/ui/pages/checkout/checkout.vue');
 const CompleteCheckout = () =>  import('@/ui/pages/checkout/complete.vue');
 const CancelCheckout = () =>  import('@/ui/pages/checkout/cancel.vue');
+const ResumeCheckout = () =>  import('@/ui/pages/checkout/resume.vue');
 const Login = () => import('@/ui/pages/login.vue');
 
 const Account = () => import('@/ui/pages/account/account.vue');
@@ -28,6 +29,7 @@ export function newRouter(): Router {
       { path: '/checkout', component: Checkout },
       { path: '/checkout/:order_id/complete', component: CompleteCheckout },
       { path: '/checkout/:order_id/cancel', component: CancelCheckout },
+      { path: '/checkout/:order_id/resume', component: ResumeCheckout },
       { path: '/login', component: Login },
       { path: '/account/login', redirect: '/login' },
 
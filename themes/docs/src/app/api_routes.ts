@@ -15,9 +15,12 @@ export const Routes = {
   deleteMyAccount: '/delete_my_account',
   verifyEmail: '/verify_email',
   placeOrder: '/place_order',
+  previewOrder: '/preview_order',
   completeOrder: '/complete_order',
   cancelorder: '/cancel_order',
+  resumeOrder: '/resume_order',
   myOrders: '/my_orders',
   myProducts: '/my_products',
   product: '/product',
+  updateLessonProgress: '/update_lesson_progress',
 }
@@ -127,7 +127,15 @@ export class MdninjaService {
     const pingoo = usePingoo();
 
     try {
-      if (pingoo.isAuthenticated()) {
+      if ($store.authProvider === model.AuthProvider.Local) {
+        const me = await this.fetchMe();
+        if (me) {
+          $store.setOrganizations(await this.fetchOrganizationsForUser());
+          $store.setUserId(me.id);
+          $store.setIsAdmin(me.is_admin);
+          $store.setUserEmail(me.email);
+        }
+      } else if (pingoo.isAuthenticated()) {
         const [accessTokenClaims, organizations] = await Promise.all([
           pingoo.getAccessTokenClaims(),
           this.fetchOrganizationsForUser(),
@@ -151,11 +159,85 @@ export class MdninjaService {
   }
 
   async logout() {
-    const pingoo = usePingoo();
-    pingoo.logout();
+    const $store = useStore();
+    if ($store.authProvider === model.AuthProvider.Local) {
+      await post(Routes.logout, {});
+    } else {
+      const pingoo = usePingoo();
+      pingoo.logout();
+    }
     window.location.href = '/';
   }
 
+  //////////////////////////////////////////////////////////////////////////////////////////////////
+  // Auth (local identity provider)
+  //////////////////////////////////////////////////////////////////////////////////////////////////
+
+  // fetchMe returns null if the user is not authenticated
+  async fetchMe(): Promise<model.User | null> {
+    try {
+      const res: model.User = await post(Routes.me, {});
+      return res;
+    } catch {
+      return null;
+    }
+  }
+
+  async signup(input: model.SignupInput): Promise<model.SignupOutput> {
+    const res: model.SignupOutput = await post(Routes.signup, input, { authenticated: false });
+    return res;
+  }
+
+  async completeSignup(input: model.CompleteSignupInput): Promise<model.User> {
+    const res: model.User = await post(Routes.completeSignup, input, { authenticated: false });
+    return res;
+  }
+
+  async login(input: model.LoginInput): Promise<model.LoginOutput> {
+    const res: model.LoginOutput = await post(Routes.login, input, { authenticated: false });
+    return res;
+  }
+
+  async completeLogin(input: model.CompleteLoginInput): Promise<model.LoginOutput> {
+    const res: model.LoginOutput = await post(Routes.completeLogin, input, { authenticated: false });
+    return res;
+  }
+
+  async complete2faChallenge(input: model.Complete2faChallengeInput): Promise<model.LoginOutput> {
+    const res: model.LoginOutput = await post(Routes.complete2faChallenge, input, { authenticated: false });
+    return res;
+  }
+
+  oidcLoginUrl(): string {
+    return `${API_BASE_URL}${Routes.oidcLogin}`;
+  }
+
+  async updateMyPassword(input: model.UpdateMyPasswordInput): Promise<void> {
+    await post(Routes.updateMyPassword, input);
+  }
+
+  async setupTwoFa(): Promise<model.SetupTwoFaOutput> {
+    const res: model.SetupTwoFaOutput = await post(Routes.setup2fa, {});
+    return res;
+  }
+
+  async enableTwoFa(input: model.EnableTwoFaInput): Promise<void> {
+    await post(Routes.enable2fa, input);
+  }
+
+  async disableTwoFa(input: model.DisableTwoFaInput): Promise<void> {
+    await post(Routes.disable2fa, input);
+  }
+
+  async listMySessions(): Promise<model.PaginatedResult<model.UserSession>> {
+    const res: model.PaginatedResult<model.UserSession> = await post(Routes.mySessions, {});
+    return res;
+  }
+
+  async revokeSession(input: model.RevokeSessionInput): Promise<void> {
+    await post(Routes.revokeSession, input);
+  }
+
   //////////////////////////////////////////////////////////////////////////////////////////////////
   // Analytics
   //////////////////////////////////////////////////////////////////////////////////////////////////
@@ -228,6 +310,24 @@ export class MdninjaService {
     return res;
   }
 
+  async listMemberships(input: model.ListMembershipsInput): Promise<model.PaginatedResult<model.Membership>> {
+    const res: model.PaginatedResult<model.Membership> = await post(Routes.memberships, input);
+
+    return res;
+  }
+
+  async grantMembership(input: model.GrantMembershipInput): Promise<model.Membership> {
+    const res: model.Membership = await post(Routes.grantMembership, input);
+
+    return res;
+  }
+
+  async cancelMembership(input: model.CancelMembershipInput): Promise<model.Membership> {
+    const res: model.Membership = await post(Routes.cancelMembership, input);
+
+    return res;
+  }
+
   //////////////////////////////////////////////////////////////////////////////////////////////////
   // Content
   //////////////////////////////////////////////////////////////////////////////////////////////////
@@ -452,6 +552,85 @@ export class MdninjaService {
     return await post(Routes.sendNewsletter, input);
   }
 
+  async pauseNewsletter(input: model.PauseNewsletterInput): Promise<model.Newsletter> {
+    return await post(Routes.pauseNewsletter, input);
+  }
+
+  async resumeNewsletter(input: model.ResumeNewsletterInput): Promise<model.Newsletter> {
+    return await post(Routes.resumeNewsletter, input);
+  }
+
+  async fetchNewsletterAnalytics(newsletterId: string): Promise<model.NewsletterAnalytics> {
+    const input: model.GetNewsletterAnalyticsInput = {
+      id: newsletterId,
+    };
+    const res: model.NewsletterAnalytics = await post(Routes.newsletterAnalytics, input);
+
+    return res;
+  }
+
+  async fetchSequences(websiteId: string): Promise<model.Sequence[]> {
+    const input: model.GetSequencesInput = {
+      website_id: websiteId,
+    };
+    const res: model.Sequence[] = await post(Routes.emailSequences, input);
+
+    return res;
+  }
+
+  async fetchSequence(sequenceId: string): Promise<model.Sequence> {
+    const input: model.GetSequenceInput = {
+      id: sequenceId,
+    };
+    const res: model.Sequence = await post(Routes.emailSequence, input);
+
+    return res;
+  }
+
+  async createSequence(input: model.CreateSequenceInput): Promise<model.Sequence> {
+    const res: model.Sequence = await post(Routes.createEmailSequence, input);
+
+    return res;
+  }
+
+  async updateSequence(input: model.UpdateSequenceInput): Promise<model.Sequence> {
+    const res: model.Sequence = await post(Routes.updateEmailSequence, input);
+
+    return res;
+  }
+
+  async deleteSequence(sequenceId: string) {
+    const input: model.DeleteSequenceInput = {
+      id: sequenceId,
+    };
+    await post(Routes.deleteEmailSequence, input);
+  }
+
+  async fetchEmailTemplates(websiteId: string): Promise<model.EmailTemplate[]> {
+    const input: model.GetEmailTemplatesInput = {
+      website_id: websiteId,
+    };
+    const res: model.EmailTemplate[] = await post(Routes.emailTemplates, input);
+
+    return res;
+  }
+
+  async updateEmailTemplate(input: model.UpdateEmailTemplateInput): Promise<model.EmailTemplate> {
+    const res: model.EmailTemplate = await post(Routes.updateEmailTemplate, input);
+
+    return res;
+  }
+
+  async previewEmailTemplate(input: model.PreviewEmailTemplateInput): Promise<model.RenderedEmail> {
+    const res: model.RenderedEmail = await post(Routes.previewEmailTemplate, input);
+
+    return res;
+  }
+
+  async sendTestEmailTemplate(input: model.SendTestEmailTemplateInput) {
+    await post(Routes.sendTestEmailTemplate, input);
+  }
+
   //////////////////////////////////////////////////////////////////////////////////////////////////
   // Kernel
   //////////////////////////////////////////////////////////////////////////////////////////////////
@@ -612,6 +791,27 @@ export class MdninjaService {
     return res;
   }
 
+  async listLicenseKeys(productId: string): Promise<model.PaginatedResult<model.LicenseKey>> {
+    const input: model.ListLicenseKeysInput = {
+      product_id: productId,
+    };
+    const res: model.PaginatedResult<model.LicenseKey> = await post(Routes.licenseKeys, input);
+    return res;
+  }
+
+  async addLicenseKeysToPool(input: model.AddLicenseKeysToPoolInput) {
+    await post(Routes.addLicenseKeysToPool, input);
+  }
+
+  async revokeLicenseKey(licenseKeyId: string): Promise<model.LicenseKey> {
+    const input: model.RevokeLicenseKeyInput = {
+      id: licenseKeyId,
+    };
+    const res: model.LicenseKey = await post(Routes.revokeLicenseKey, input);
+
+    return res;
+  }
+
   async listProducts(websiteId: string): Promise<model.PaginatedResult<model.Product>> {
     const input: model.ListProductsInput = {
       website_id: websiteId,
@@ -680,6 +880,15 @@ export class MdninjaService {
     return res;
   }
 
+  async getCourseAnalytics(productId: string): Promise<model.CourseAnalytics> {
+    const input: model.GetCourseAnalyticsInput = {
+      product_id: productId,
+    };
+    const res: model.CourseAnalytics = await post(Routes.courseAnalytics, input);
+
+    return res;
+  }
+
   async giveContactAccessToProducts(input: model.GiveContactsAccessToProductInput) {
     await post(Routes.giveContactsAccessToProduct, input);
   }
@@ -688,6 +897,10 @@ export class MdninjaService {
     await post(Routes.removeAccessToproduct, input);
   }
 
+  async transferProductAccess(input: model.TransferProductAccessInput) {
+    await post(Routes.transferProductAccess, input);
+  }
+
   async listOrders(input: model.ListOrdersInput): Promise<model.PaginatedResult<model.OrderMetadata>> {
     const res: model.PaginatedResult<model.Order> = await post(Routes.orders, input);
 
@@ -703,6 +916,48 @@ export class MdninjaService {
     return res;
   }
 
+  async getAbandonedCheckoutSettings(websiteId: string): Promise<model.AbandonedCheckoutSettings> {
+    const input: model.GetAbandonedCheckoutSettingsInput = {
+      website_id: websiteId,
+    };
+    const res: model.AbandonedCheckoutSettings = await post(Routes.abandonedCheckoutSettings, input);
+
+    return res;
+  }
+
+  async updateAbandonedCheckoutSettings(input: model.UpdateAbandonedCheckoutSettingsInput): Promise<model.AbandonedCheckoutSettings> {
+    const res: model.AbandonedCheckoutSettings = await post(Routes.updateAbandonedCheckoutSettings, input);
+
+    return res;
+  }
+
+  async getInvoicingSettings(websiteId: string): Promise<model.InvoicingSettings> {
+    const input: model.GetInvoicingSettingsInput = {
+      website_id: websiteId,
+    };
+    const res: model.InvoicingSettings = await post(Routes.invoicingSettings, input);
+
+    return res;
+  }
+
+  async updateInvoicingSettings(input: model.UpdateInvoicingSettingsInput): Promise<model.InvoicingSettings> {
+    const res: model.InvoicingSettings = await post(Routes.updateInvoicingSettings, input);
+
+    return res;
+  }
+
+  async listInvoices(input: model.ListInvoicesInput): Promise<model.PaginatedResult<model.Invoice>> {
+    const res: model.PaginatedResult<model.Invoice> = await post(Routes.invoices, input);
+
+    return res;
+  }
+
+  async exportAccounting(input: model.ExportAccountingInput): Promise<model.ExportAccountingOutput> {
+    const res: model.ExportAccountingOutput = await post(Routes.exportAccounting, input);
+
+    return res;
+  }
+
   async listRefunds(websiteId: string): Promise<model.PaginatedResult<model.Refund>> {
     const input: model.ListRefundsInput = {
       website_id: websiteId,
@@ -791,6 +1046,16 @@ export class MdninjaService {
     return res;
   }
 
+  async saveFirewallRules(input: model.SaveFirewallRulesInput): Promise<model.FirewallRule[]> {
+    const res: model.FirewallRule[] = await post(Routes.saveFirewallRules, input);
+    return res;
+  }
+
+  async listFirewallRules(input: model.ListFirewallRulesInput): Promise<model.PaginatedResult<model.FirewallRule>> {
+    const res: model.PaginatedResult<model.FirewallRule> = await post(Routes.firewallRules, input);
+    return res;
+  }
+
   async listAllWebsites(input: model.ListWebsitesInput): Promise<model.PaginatedResult<model.Website>> {
     const res: model.PaginatedResult<model.Website> = await post(Routes.allWebsites, input);
     return res;
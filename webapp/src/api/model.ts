@@ -21,6 +21,24 @@ export type AnalyticsData = {
   browsers: Counter[];
   oses: Counter[];
   new_subscribers: number;
+  newsletters: NewsletterEmailsStats[];
+}
+
+export type NewsletterEmailsStats = {
+  newsletter_id: string;
+  emails_sent: number;
+  bounces: number;
+  complaints: number;
+  bounce_rate: number;
+  complaint_rate: number;
+  delivered: number;
+  unique_opens: number;
+  unique_clicks: number;
+}
+
+export type NewsletterAnalytics = NewsletterEmailsStats & {
+  clicks: number;
+  top_links: Counter[];
 }
 
 export type GetAnalyticsDataInput = {
@@ -49,12 +67,30 @@ export type Contact = {
   email: string;
   country: string;
   subscribed_to_newsletter_at: string | null;
+  subscribed_to_product_updates_at: string | null;
+  receive_newsletters: boolean;
+  newsletter_delivery: NewsletterDelivery;
   blocked_at: string | null;
 
   stripe_customer_id: string | null;
 
   products: Product[] | null;
   orders: Order[] | null;
+  memberships: Membership[] | null;
+  email_feedback: ContactEmailFeedback[] | null;
+}
+
+export type NewsletterDelivery = 'posts' | 'digest' | 'none';
+
+export type ContactEmailFeedback = {
+  id: string;
+  created_at: string;
+  source: string;
+  type: 'bounce' | 'complaint';
+  bounce_type: '' | 'permanent' | 'transient';
+  email: string;
+  diagnostic: string;
+  newsletter_id: string | null;
 }
 
 export type CreateContactInput = {
@@ -68,6 +104,9 @@ export type UpdateContactInput = {
   email?: string;
   name?: string;
   subscribed_to_newsletter?: boolean;
+  newsletter_delivery?: NewsletterDelivery;
+  receive_newsletters?: boolean;
+  subscribed_to_product_updates?: boolean;
 }
 
 export type DeleteContactInput = {
@@ -112,6 +151,19 @@ export type UnblockContactInput = {
   id: string;
 }
 
+export type ListMembershipsInput = {
+  website_id: string;
+}
+
+export type GrantMembershipInput = {
+  contact_id: string;
+  product_id: string;
+}
+
+export type CancelMembershipInput = {
+  id: string;
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 // Content
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -121,6 +173,11 @@ export enum PageType {
   Post = "post",
 };
 
+export enum PageVisibility {
+  Public = "public",
+  Members = "members",
+};
+
 export enum PageStatus {
   Published = "published",
   Draft = "draft",
@@ -167,6 +224,7 @@ export interface PageMetadata {
   language: string;
   send_as_newsletter: boolean;
   newsletter_sent_at: string | null;
+  visibility: PageVisibility;
 };
 
 export type Asset = {
@@ -248,6 +306,7 @@ export type CreatePageInput = {
   draft: boolean;
   body_markdown: string;
   send_as_newsletter: boolean;
+  visibility: PageVisibility;
 }
 
 export type UpdatePageInput = {
@@ -261,6 +320,7 @@ export type UpdatePageInput = {
   draft: boolean;
   body_markdown?: string;
   send_as_newsletter: boolean;
+  visibility: PageVisibility;
 }
 
 export type DeletePageInput = {
@@ -325,10 +385,57 @@ export interface NewsletterMetadata {
   hash: string;
   sent_at: string | null;
   last_test_sent_at: string | null;
+  members_only: boolean;
+  product_updates: boolean;
+  digest: boolean;
+  ab_test: ABTest | null;
+  sending_paused_at: string | null;
 }
 
 export interface Newsletter extends NewsletterMetadata {
   body_markdown: string;
+  ab_test_results?: ABTestVariantResult[];
+  progress?: NewsletterProgress;
+}
+
+export type NewsletterProgress = {
+  sent: number;
+  remaining: number;
+  failed: number;
+}
+
+export type ABTestMetric = 'clicks' | 'opens';
+
+export type ABTest = {
+  variants: ABTestVariant[];
+  sample_percent: number;
+  // in seconds
+  duration: number;
+  winning_metric: ABTestMetric;
+  winner: number | null;
+}
+
+export type ABTestVariant = {
+  subject: string;
+  recipients: number;
+}
+
+export type ABTestVariantResult = {
+  subject: string;
+  recipients: number;
+  unique_opens: number;
+  unique_clicks: number;
+  open_rate: number;
+  click_rate: number;
+  winner: boolean;
+}
+
+export type ABTestInput = {
+  subjects: string[];
+  sample_percent: number;
+  // in seconds
+  duration: number;
+  winning_metric: ABTestMetric;
 }
 
 export type EmailConfiguration = {
@@ -336,7 +443,23 @@ export type EmailConfiguration = {
   from_address: string;
   domain_verified: string;
   dns_records: EmailDnsRecord[];
-}
+  domain_verified_at: string | null;
+  // emails per second
+  send_rate: number;
+  track_clicks: boolean;
+  track_opens: boolean;
+  track_contacts: boolean;
+  digest_frequency: DigestFrequency;
+  digest_subject: string;
+  digest_template: string;
+  digest_include_excerpts: boolean;
+  digest_next_send_at: string | null;
+  archive_path: string;
+  footer_text: string;
+  legal_address: string;
+}
+
+export type DigestFrequency = 'disabled' | 'weekly' | 'monthly';
 
 export type EmailDnsRecord = {
   host: string,
@@ -352,11 +475,18 @@ export type GetNewsletterInput = {
   id: string;
 }
 
+export type GetNewsletterAnalyticsInput = {
+  id: string;
+}
+
 export type CreateNewsletterInput = {
   website_id: string;
   subject: string;
   scheduled_for?: string;
   body_markdown: string;
+  members_only: boolean;
+  product_updates: boolean;
+  ab_test?: ABTestInput;
 }
 
 export type UpdateNewsletterInput = {
@@ -364,16 +494,144 @@ export type UpdateNewsletterInput = {
   subject: string;
   scheduled_for?: string;
   body_markdown?: string;
+  members_only?: boolean;
+  product_updates?: boolean;
+  ab_test?: ABTestInput;
 }
 
 export type DeleteNewsletterInput = {
   id: string;
 }
 
+export type SequenceTrigger = 'subscribed_to_newsletter' | 'product_purchased';
+
+export type Sequence = {
+  id: string;
+  created_at: string;
+  updated_at: string;
+  name: string;
+  trigger: SequenceTrigger;
+  trigger_product_id: string | null;
+  exit_on_purchase: boolean;
+  exit_product_id: string | null;
+  paused_at: string | null;
+  steps: SequenceStep[];
+  stats?: SequenceStats;
+}
+
+export type SequenceStep = {
+  id: string;
+  // delay in seconds after the previous step
+  delay: number;
+  subject: string;
+  body_markdown: string;
+}
+
+export type SequenceStats = {
+  active_contacts: number;
+  completed_contacts: number;
+  exited_contacts: number;
+  steps: SequenceStepStats[];
+}
+
+export type SequenceStepStats = {
+  step_id: string;
+  emails_sent: number;
+}
+
+export type SequenceStepInput = {
+  id?: string;
+  delay: number;
+  subject: string;
+  body_markdown: string;
+}
+
+export type CreateSequenceInput = {
+  website_id: string;
+  name: string;
+  trigger: SequenceTrigger;
+  trigger_product_id: string | null;
+  exit_on_purchase: boolean;
+  exit_product_id: string | null;
+  steps: SequenceStepInput[];
+}
+
+export type UpdateSequenceInput = {
+  id: string;
+  name?: string;
+  trigger_product_id: string | null;
+  exit_on_purchase?: boolean;
+  exit_product_id: string | null;
+  paused?: boolean;
+  steps?: SequenceStepInput[];
+}
+
+export type GetSequenceInput = {
+  id: string;
+}
+
+export type GetSequencesInput = {
+  website_id: string;
+}
+
+export type DeleteSequenceInput = {
+  id: string;
+}
+
 export type UpdateEmailConfigurationInput = {
   website_id: string;
   from_name: string;
   from_address: string;
+  track_clicks?: boolean;
+  track_opens?: boolean;
+  track_contacts?: boolean;
+  digest_frequency?: DigestFrequency;
+  digest_subject?: string;
+  digest_template?: string;
+  digest_include_excerpts?: boolean;
+  archive_path?: string;
+  footer_text?: string;
+  legal_address?: string;
+  send_rate?: number;
+}
+
+export type EmailTemplateType = 'newsletter' | 'login' | 'subscribe' | 'verify_email' | 'order_confirmation';
+
+export type EmailTemplate = {
+  type: EmailTemplateType;
+  body: string;
+  customized: boolean;
+  default_body: string;
+  required_variables: string[];
+}
+
+export type RenderedEmail = {
+  subject: string;
+  html: string;
+  text: string;
+}
+
+export type GetEmailTemplatesInput = {
+  website_id: string;
+}
+
+export type UpdateEmailTemplateInput = {
+  website_id: string;
+  type: EmailTemplateType;
+  // an empty body restores the default template
+  body: string;
+}
+
+export type PreviewEmailTemplateInput = {
+  website_id: string;
+  type: EmailTemplateType;
+  body: string;
+}
+
+export type SendTestEmailTemplateInput = {
+  website_id: string;
+  type: EmailTemplateType;
+  body: string;
 }
 
 export type GetEmailConfigurationInput = {
@@ -389,6 +647,14 @@ export type SendNewsletterInput = {
   test?: boolean;
 }
 
+export type PauseNewsletterInput = {
+  id: string;
+}
+
+export type ResumeNewsletterInput = {
+  id: string;
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 // Kernel
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -443,9 +709,104 @@ export type InitData = {
   pricing: PricingPlan[],
   // challenge_site_key: string | null;
   pingoo: PingooInitData,
+  auth: AuthInitData,
   websites_base_url: string;
 }
 
+export enum AuthProvider {
+  Pingoo = 'pingoo',
+  Local = 'local',
+}
+
+export type AuthInitData = {
+  provider: AuthProvider;
+  allow_signups: boolean;
+  oidc: { name: string } | null;
+}
+
+export type User = {
+  id: string;
+  created_at: string;
+  updated_at: string;
+  name: string;
+  email: string;
+  is_admin: boolean;
+  two_fa_enabled: boolean;
+}
+
+export enum AuthStep {
+  Completed = 'completed',
+  EmailCode = 'email_code',
+  TwoFa = 'two_fa',
+}
+
+export type SignupInput = {
+  name: string;
+  email: string;
+  password: string | null;
+}
+
+export type SignupOutput = {
+  pending_user_id: string;
+}
+
+export type CompleteSignupInput = {
+  pending_user_id: string;
+  code: string;
+}
+
+export type LoginInput = {
+  email: string;
+  password: string | null;
+}
+
+export type LoginOutput = {
+  session_id: string;
+  step: AuthStep;
+}
+
+export type CompleteLoginInput = {
+  session_id: string;
+  code: string;
+}
+
+export type Complete2faChallengeInput = {
+  session_id: string;
+  code: string;
+}
+
+export type UpdateMyPasswordInput = {
+  current_password: string | null;
+  new_password: string;
+}
+
+export type SetupTwoFaOutput = {
+  secret: string;
+  // base64 encoded JPEG image
+  qr_code: string;
+}
+
+export type EnableTwoFaInput = {
+  code: string;
+}
+
+export type DisableTwoFaInput = {
+  code: string;
+}
+
+export type UserSession = {
+  id: string;
+  created_at: string;
+  ip: string;
+  user_agent: string;
+  country_code: string;
+  current: boolean;
+}
+
+export type RevokeSessionInput = {
+  id: string;
+}
+
 export type PingooInitData = {
   endpoint: string,
   app_id: string;
@@ -474,6 +835,8 @@ export type Organization = {
   plan: string;
   billing_information: BillingInformation;
   extra_slots: number;
+  // emails per second, for all the websites of the organization
+  emails_send_rate: number;
   stripe_customer: boolean;
   payment_due: boolean;
 
@@ -540,6 +903,7 @@ export type UpdateOrganizationInput = {
 
   plan?: string;
   extra_slots?: number;
+  emails_send_rate?: number;
 }
 
 export type GetOrganizationInput = {
@@ -655,6 +1019,36 @@ export enum ProductType {
   Book = "book",
   Course = "course",
   Download = "download",
+  Membership = "membership",
+  Bundle = "bundle",
+};
+
+export enum BillingInterval {
+  Month = "month",
+  Year = "year",
+};
+
+export enum LicenseKeysMode {
+  None = "none",
+  Generated = "generated",
+  Pool = "pool",
+};
+
+export enum LicenseKeyStatus {
+  Available = "available",
+  Issued = "issued",
+  Revoked = "revoked",
+};
+
+export enum MembershipStatus {
+  Active = "active",
+  Trialing = "trialing",
+  PastDue = "past_due",
+  Unpaid = "unpaid",
+  Paused = "paused",
+  Incomplete = "incomplete",
+  IncompleteExpired = "incomplete_expired",
+  Canceled = "canceled",
 };
 
 export enum ProductStatus {
@@ -686,9 +1080,63 @@ export type Product = {
   type: ProductType;
   status: ProductStatus;
   price: number;
+  ebook_watermark: boolean;
+  billing_interval: BillingInterval | null;
 
   content: ProductPage[] | null;
   assets: Asset[] | null;
+  ebooks: ProductEbook[] | null;
+  bundle_items: string[] | null;
+  prices: Record<string, number>;
+  pay_what_you_want: boolean;
+  license_keys: LicenseKeysMode;
+  license_key_activations_limit: number;
+}
+
+export type LicenseKey = {
+  id: string;
+  created_at: string;
+  updated_at: string;
+
+  key: string;
+  status: LicenseKeyStatus;
+  issued_at: string | null;
+  revoked_at: string | null;
+  product_id: string;
+  order_id: string | null;
+  contact_id: string | null;
+  activations: number;
+}
+
+export type Membership = {
+  id: string;
+  created_at: string;
+  updated_at: string;
+
+  status: MembershipStatus;
+  current_period_end: string | null;
+  cancel_at_period_end: boolean;
+  canceled_at: string | null;
+  stripe_subscription_id: string | null;
+
+  contact_id: string;
+  product_id: string;
+  product_name: string;
+}
+
+export type ProductEbook = {
+  id: string;
+  created_at: string;
+  updated_at: string;
+
+  format: ProductEbookFormat;
+  content_hash: string;
+  size: number;
+}
+
+export enum ProductEbookFormat {
+  Epub = "epub",
+  Html = "html",
 }
 
 export type Order = {
@@ -702,10 +1150,16 @@ export type Order = {
   country: string;
   email: string;
   additional_invoice_information: string;
+  discount_amount: number;
+  coupon_id: string | null;
   stripe_checkout_session_id: string;
   stripe_payment_intent_id?: string;
   stripe_invoice_id?: string;
   stripe_invoice_url?: string;
+  recovery_email_sent_at: string | null;
+  gift_recipient_email: string | null;
+  gift_message: string;
+  gift_recipient_contact_id: string | null;
 
   line_items?: OrderLineItem[];
   contact_id: string;
@@ -743,7 +1197,9 @@ export type Coupon = {
   code: string;
   expires_at: string | null;
   discount: number;
+  // 0 means unlimited
   uses_limit: number;
+  uses: number;
   archived: boolean;
   description: string;
   products: string[];
@@ -761,6 +1217,20 @@ export type ProductPage = {
   size: number;
   hash: string;
   body_markdown: string;
+  drip_days: number;
+};
+
+export type CourseAnalytics = {
+  students: number;
+  lessons: LessonAnalytics[];
+};
+
+export type LessonAnalytics = {
+  product_page_id: string;
+  position: number;
+  title: string;
+  drip_days: number;
+  completions: number;
 };
 
 export type Refund = {
@@ -786,6 +1256,10 @@ export type CreateProductInput = {
   description: string;
   type: ProductType;
   price: number;
+  billing_interval?: BillingInterval;
+  bundle_items?: string[];
+  prices?: Record<string, number>;
+  pay_what_you_want: boolean;
 }
 
 export type GetProductInput = {
@@ -813,6 +1287,26 @@ export type UpdateProductInput = {
   description?: string;
   status?: ProductStatus;
   price?: number;
+  ebook_watermark?: boolean;
+  billing_interval?: BillingInterval;
+  bundle_items?: string[];
+  prices?: Record<string, number>;
+  pay_what_you_want?: boolean;
+  license_keys?: LicenseKeysMode;
+  license_key_activations_limit?: number;
+}
+
+export type ListLicenseKeysInput = {
+  product_id: string;
+}
+
+export type AddLicenseKeysToPoolInput = {
+  product_id: string;
+  keys: string[];
+}
+
+export type RevokeLicenseKeyInput = {
+  id: string;
 }
 
 export type CreateCouponInput = {
@@ -821,6 +1315,7 @@ export type CreateCouponInput = {
   description: string;
   expires_at?: string;
   discount: number;
+  uses_limit: number;
   products: string[];
 }
 
@@ -838,6 +1333,7 @@ export type UpdateCouponInput = {
   description?: string;
   expires_at?: string;
   discount?: number;
+  uses_limit?: number;
   archived?: boolean;
   products?: string[];
 }
@@ -847,12 +1343,14 @@ export type CreateProductPageInput = {
   product_id: string;
   title: string;
   body_markdown: string;
+  drip_days?: number;
 };
 
 export type UpdateProductPageInput = {
   id: string;
   title?: string;
   body_markdown?: string;
+  drip_days?: number;
 };
 
 
@@ -864,6 +1362,10 @@ export type GetProductPageInput = {
   id: string;
 };
 
+export type GetCourseAnalyticsInput = {
+  product_id: string;
+};
+
 export type GiveContactsAccessToProductInput = {
   product_id: string;
   emails: string[];
@@ -874,10 +1376,135 @@ export type RemoveAccessToproduct = {
   emails: string[];
 }
 
+export type TransferProductAccessInput = {
+  product_id: string;
+  from_email: string;
+  to_email: string;
+}
+
 export type GetOrderInput = {
   id: string;
 }
 
+export type AbandonedCheckoutSettings = {
+  created_at: string;
+  updated_at: string;
+
+  enabled: boolean;
+  delay_hours: number;
+  subject: string;
+  body_markdown: string;
+}
+
+export type GetAbandonedCheckoutSettingsInput = {
+  website_id: string;
+}
+
+export type UpdateAbandonedCheckoutSettingsInput = {
+  website_id: string;
+  enabled?: boolean;
+  delay_hours?: number;
+  subject?: string;
+  body_markdown?: string;
+}
+
+export type InvoicingSettings = {
+  created_at: string;
+  updated_at: string;
+
+  enabled: boolean;
+  seller_name: string;
+  seller_address: string;
+  seller_vat_number: string;
+  // in basis points: 2000 = 20%
+  vat_rate: number;
+  notes: string;
+  invoice_number_prefix: string;
+  credit_note_number_prefix: string;
+  next_invoice_number: number;
+  next_credit_note_number: number;
+}
+
+export type GetInvoicingSettingsInput = {
+  website_id: string;
+}
+
+export type UpdateInvoicingSettingsInput = {
+  website_id: string;
+  enabled?: boolean;
+  seller_name?: string;
+  seller_address?: string;
+  seller_vat_number?: string;
+  vat_rate?: number;
+  notes?: string;
+  invoice_number_prefix?: string;
+  credit_note_number_prefix?: string;
+}
+
+export enum InvoiceType {
+  Invoice = 'invoice',
+  CreditNote = 'credit_note',
+}
+
+export type Invoice = {
+  id: string;
+  created_at: string;
+  updated_at: string;
+
+  type: InvoiceType;
+  sequence: number;
+  number: string;
+  currency: string;
+  line_items: InvoiceLineItem[];
+  // amounts are in cents
+  vat_rate: number;
+  net_amount: number;
+  vat_amount: number;
+  total_amount: number;
+  credited_invoice_number: string;
+
+  seller_name: string;
+  seller_address: string;
+  seller_vat_number: string;
+  buyer_email: string;
+  buyer_country: string;
+  additional_invoice_information: string;
+  notes: string;
+
+  order_id: string;
+  refund_id: string | null;
+}
+
+export type InvoiceLineItem = {
+  description: string;
+  quantity: number;
+  unit_amount: number;
+  amount: number;
+}
+
+export type ListInvoicesInput = {
+  website_id: string;
+  order_id?: string;
+}
+
+export enum AccountingExportFormat {
+  Csv = 'csv',
+  Json = 'json',
+}
+
+export type ExportAccountingInput = {
+  website_id: string;
+  from: string;
+  to: string;
+  format: AccountingExportFormat;
+}
+
+export type ExportAccountingOutput = {
+  orders: string;
+  refunds: string;
+  invoices: string;
+}
+
 export type ListRefundsInput = {
   website_id: string;
 }
@@ -920,6 +1547,7 @@ export type Website = {
   announcement: string | null;
   logo: string | null;
   powered_by: boolean,
+  rate_limits: WebsiteRateLimits;
 
   domains: Domain[] | null;
   redirects: Redirect[] | null;
@@ -997,6 +1625,72 @@ export type RedirectInput = {
   // status: number;
 }
 
+export type FirewallRule = {
+  id: string;
+  created_at: string;
+  updated_at: string;
+  name: string;
+  enabled: boolean;
+  conditions: FirewallRuleConditions;
+  action: FirewallRuleAction;
+  hits: number;
+  last_hit_at: string | null;
+}
+
+export type FirewallRuleConditions = {
+  paths?: string[];
+  methods?: string[];
+  countries?: string[];
+  asns?: number[];
+  user_agents?: string[];
+  bots?: FirewallBotVerification[];
+  query?: FirewallQueryCondition[];
+}
+
+export enum FirewallBotVerification {
+  Verified = 'verified',
+  Unverified = 'unverified',
+  None = 'none',
+}
+
+export type FirewallQueryCondition = {
+  name: string;
+  value: string;
+}
+
+export enum FirewallActionType {
+  Block = 'block',
+  Challenge = 'challenge',
+  RateLimit = 'rate_limit',
+  Redirect = 'redirect',
+  SetHeader = 'set_header',
+}
+
+export type FirewallRuleAction = {
+  type: FirewallActionType;
+  headers?: { name: string, value: string }[];
+  redirect_to?: string;
+  redirect_status?: number;
+  rate_limit?: { requests: number, period_seconds: number };
+}
+
+export type SaveFirewallRulesInput = {
+  website_id: string;
+  rules: FirewallRuleInput[];
+}
+
+export type FirewallRuleInput = {
+  id: string | null;
+  name: string;
+  enabled: boolean;
+  conditions: FirewallRuleConditions;
+  action: FirewallRuleAction;
+}
+
+export type ListFirewallRulesInput = {
+  website_id: string;
+}
+
 
 export type CreateWebsiteInput = {
   name: string;
@@ -1024,6 +1718,17 @@ export type UpdateWebsiteInput = {
   announcement?: string;
   logo?: string;
   powered_by?: boolean,
+  rate_limits?: WebsiteRateLimits;
+}
+
+// Per-IP address limits of the site API. 0 means that the default limit is used.
+export type WebsiteRateLimits = {
+  // requests per minute
+  api: number;
+  // requests per hour
+  login: number;
+  subscribe: number;
+  place_order: number;
 }
 
 export type DeleteWebsiteInput = {
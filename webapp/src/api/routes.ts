@@ -13,6 +13,7 @@ export const Routes = {
 
   // users
   init: '/init',
+  me: '/me',
   deleteUser: '/delete_user',
   user: '/user',
   users: '/users',
@@ -21,6 +22,20 @@ export const Routes = {
   // sessions
   revokeSession: '/revoke_session',
   sessions: '/sessions',
+  mySessions: '/my_sessions',
+
+  // auth (local identity provider)
+  signup: '/signup',
+  completeSignup: '/complete_signup',
+  login: '/login',
+  completeLogin: '/complete_login',
+  complete2faChallenge: '/complete_2fa_challenge',
+  logout: '/logout',
+  oidcLogin: '/oidc/login',
+  updateMyPassword: '/update_my_password',
+  setup2fa: '/setup_2fa',
+  enable2fa: '/enable_2fa',
+  disable2fa: '/disable_2fa',
 
   // organizations
   organizations: '/organizations/list',
@@ -61,6 +76,8 @@ export const Routes = {
   website: '/website',
   updateWebsite: '/update_website',
   saveRedirects: '/save_redirects',
+  saveFirewallRules: '/save_firewall_rules',
+  firewallRules: '/firewall_rules',
   allWebsites: '/all_websites',
   websiteUpdateIcon: '/websites/update_icon',
   websitesAdminStatistics: '/websites/admin-statistics',
@@ -112,6 +129,9 @@ export const Routes = {
   exportContactsForProduct: '/export_contacts_for_product',
   blockContact: '/block_contact',
   unblockContact: '/unblock_contact',
+  memberships: '/memberships',
+  grantMembership: '/grant_membership',
+  cancelMembership: '/cancel_membership',
 
   // labels
   createLabel: '/create_label',
@@ -135,6 +155,22 @@ export const Routes = {
   updateNewsletter: '/update_newsletter',
   deleteNewsletter: '/delete_newsletter',
   sendNewsletter: '/send_newsletter',
+  pauseNewsletter: '/pause_newsletter',
+  resumeNewsletter: '/resume_newsletter',
+  newsletterAnalytics: '/newsletter_analytics',
+
+  // email sequences
+  emailSequences: '/email_sequences',
+  emailSequence: '/email_sequence',
+  createEmailSequence: '/create_email_sequence',
+  updateEmailSequence: '/update_email_sequence',
+  deleteEmailSequence: '/delete_email_sequence',
+
+  // email templates
+  emailTemplates: '/email_templates',
+  updateEmailTemplate: '/update_email_template',
+  previewEmailTemplate: '/preview_email_template',
+  sendTestEmailTemplate: '/send_test_email_template',
 
   //////////////////////////////////////////////////////////////////////////////////////////////////
   // Products
@@ -148,11 +184,22 @@ export const Routes = {
   giveContactsAccessToProduct: '/give_contacts_access_to_product',
   deleteProduct: '/delete_product',
   removeAccessToproduct: '/remove_access_to_product',
+  transferProductAccess: '/transfer_product_access',
 
   // orders
   orders: '/orders',
   order: '/order',
 
+  // abandoned checkouts
+  abandonedCheckoutSettings: '/abandoned_checkout_settings',
+  updateAbandonedCheckoutSettings: '/update_abandoned_checkout_settings',
+
+  // invoices
+  invoicingSettings: '/invoicing_settings',
+  updateInvoicingSettings: '/update_invoicing_settings',
+  invoices: '/invoices',
+  exportAccounting: '/export_accounting',
+
   // refunds
   refunds: '/refunds',
   createRefund: '/create_refund',
@@ -162,6 +209,12 @@ export const Routes = {
   updateProductPage: '/update_product_page',
   deleteProductPage: '/delete_product_page',
   productPage: '/product_page',
+  courseAnalytics: '/course_analytics',
+
+  // license keys
+  licenseKeys: '/license_keys',
+  addLicenseKeysToPool: '/add_license_keys_to_pool',
+  revokeLicenseKey: '/revoke_license_key',
 
   // coupons
   coupons: '/coupons',
@@ -38,6 +38,7 @@ import WebsiteSnippets from '@/ui/pages/websites/website/settings/snippets.vue';
 import WebsiteTags from '@/ui/pages/websites/website/settings/tags.vue';
 import WebsiteAssets from '@/ui/pages/websites/website/assets.vue';
 import WebsiteRedirects from '@/ui/pages/websites/website/settings/redirects.vue';
+import WebsiteFirewall from '@/ui/pages/websites/website/settings/firewall.vue';
 import WebsiteNavigation from '@/ui/pages/websites/website/settings/navigation.vue';
 
 // Contacts
@@ -49,6 +50,9 @@ import WebsiteContact from '@/ui/pages/websites/website/contacts/contact.vue';
 import WebsiteNewsletters from '@/ui/pages/websites/website/newsletters/newsletters.vue';
 import WebsiteNewsletter from '@/ui/pages/websites/website/newsletters/newsletter.vue';
 import WebsiteNewNewsletter from '@/ui/pages/websites/website/newsletters/new.vue';
+import WebsiteSequences from '@/ui/pages/websites/website/sequences/sequences.vue';
+import WebsiteSequence from '@/ui/pages/websites/website/sequences/sequence.vue';
+import WebsiteNewSequence from '@/ui/pages/websites/website/sequences/new.vue';
 
 // Store
 import WebsiteProducts from '@/ui/pages/websites/website/products/products.vue';
@@ -60,12 +64,16 @@ import WebsiteProductpage from '@/ui/pages/websites/website/products/product/pag
 import WebsiteOrders from '@/ui/pages/websites/website/orders/orders.vue';
 import WebsiteOrder from '@/ui/pages/websites/website/orders/order.vue';
 import WebsiteRefunds from '@/ui/pages/websites/website/refunds/refunds.vue';
+import WebsiteAbandonedCheckouts from '@/ui/pages/websites/website/orders/abandoned_checkouts.vue';
+import WebsiteInvoices from '@/ui/pages/websites/website/orders/invoices.vue';
+import WebsiteInvoicing from '@/ui/pages/websites/website/orders/invoicing.vue';
 
 // Website Settings
 import WebsiteSettings from '@/ui/pages/websites/website/settings/settings.vue';
 import WebsiteSettingsCode from '@/ui/pages/websites/website/settings/code.vue';
 import WebsiteSettingsDomains from '@/ui/pages/websites/website/settings/domains.vue';
 import WebsiteSettingsEmails from '@/ui/pages/websites/website/settings/emails.vue';
+import WebsiteSettingsEmailTemplates from '@/ui/pages/websites/website/settings/email_templates.vue';
 import WebsiteSettingsDesign from '@/ui/pages/websites/website/settings/design.vue';
 
 // Admin
@@ -127,6 +135,7 @@ export function newRouter(config: Config): Router {
       { path: '/websites/:website_id/tags', component: WebsiteTags },
       { path: '/websites/:website_id/assets', component: WebsiteAssets },
       { path: '/websites/:website_id/redirects', component: WebsiteRedirects },
+      { path: '/websites/:website_id/settings/firewall', component: WebsiteFirewall },
       { path: '/websites/:website_id/navigation', component: WebsiteNavigation },
 
       // Contacts
@@ -138,6 +147,9 @@ export function newRouter(config: Config): Router {
       { path: '/websites/:website_id/newsletters', component: WebsiteNewsletters },
       { path: '/websites/:website_id/newsletters/new', component: WebsiteNewNewsletter },
       { path: '/websites/:website_id/newsletters/:newsletter_id', component: WebsiteNewsletter },
+      { path: '/websites/:website_id/sequences', component: WebsiteSequences },
+      { path: '/websites/:website_id/sequences/new', component: WebsiteNewSequence },
+      { path: '/websites/:website_id/sequences/:sequence_id', component: WebsiteSequence },
 
       // Store
       { path: '/websites/:website_id/coupons', component: WebsiteCoupons },
@@ -149,12 +161,16 @@ export function newRouter(config: Config): Router {
       { path: '/websites/:website_id/orders', component: WebsiteOrders },
       { path: '/websites/:website_id/orders/:order_id', component: WebsiteOrder },
       { path: '/websites/:website_id/refunds', component: WebsiteRefunds },
+      { path: '/websites/:website_id/abandoned_checkouts', component: WebsiteAbandonedCheckouts },
+      { path: '/websites/:website_id/invoices', component: WebsiteInvoices },
+      { path: '/websites/:website_id/invoicing', component: WebsiteInvoicing },
 
       // Website Settings
       { path: '/websites/:website_id/settings', component: WebsiteSettings },
       { path: '/websites/:website_id/settings/code', component: WebsiteSettingsCode },
       { path: '/websites/:website_id/settings/domains', component: WebsiteSettingsDomains },
       { path: '/websites/:website_id/settings/emails', component: WebsiteSettingsEmails },
+      { path: '/websites/:website_id/settings/email_templates', component: WebsiteSettingsEmailTemplates },
       { path: '/websites/:website_id/settings/design', component: WebsiteSettingsDesign },
 
       // Admin
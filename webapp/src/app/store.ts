@@ -1,4 +1,4 @@
-import type { InitData, Organization, PricingPlan, Website } from '@/api/model';
+import { AuthProvider, type InitData, type Organization, type PricingPlan, type Website } from '@/api/model';
 import { defineStore } from 'pinia'
 import { ref } from 'vue';
 
@@ -17,6 +17,9 @@ export interface AppState {
 
   pingooEndpoint: string,
   pingooAppId: string,
+  authProvider: AuthProvider,
+  allowSignups: boolean,
+  oidcName: string | null,
   websitesBaseUrl: string,
 }
 
@@ -41,6 +44,9 @@ export const useStore = defineStore('store', {
       this.pricing = initData.pricing;
       this.pingooAppId = initData.pingoo.app_id;
       this.pingooEndpoint = initData.pingoo.endpoint;
+      this.authProvider = initData.auth.provider;
+      this.allowSignups = initData.auth.allow_signups;
+      this.oidcName = initData.auth.oidc?.name ?? null;
       this.websitesBaseUrl = initData.websites_base_url;
 
       const privateState = usePrivateState();
@@ -113,6 +119,9 @@ function defaultAppState(): AppState {
     contact_email: '',
     pingooAppId: '',
     pingooEndpoint: '',
+    authProvider: AuthProvider.Pingoo,
+    allowSignups: false,
+    oidcName: null,
     websitesBaseUrl: '',
   };
 }